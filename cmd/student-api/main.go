@@ -9,6 +9,7 @@ import (
 	"student-management-system/internal/config"
 	handler "student-management-system/internal/handler"
 	repo "student-management-system/internal/repository"
+	"student-management-system/internal/repository/migrations"
 	"student-management-system/pkg/errors"
 	"student-management-system/pkg/logger"
 	"student-management-system/pkg/ratelimit"
@@ -47,6 +48,22 @@ func main() {
 	defer repo.CloseDB()
 	logger.Info("数据库连接已建立")
 
+	// 处理 migrate 子命令: student-api migrate up|down [n]|status
+	if len(os.Args) > 1 && os.Args[1] == "migrate" {
+		if err := runMigrateCommand(os.Args[2:]); err != nil {
+			logger.WithError(err).Fatal("数据库迁移失败")
+		}
+		return
+	}
+
+	// 处理 password-report 子命令: 列出仍使用历史MD5密码的账号
+	if len(os.Args) > 1 && os.Args[1] == "password-report" {
+		if err := runPasswordReportCommand(cfg); err != nil {
+			logger.WithError(err).Fatal("生成密码报告失败")
+		}
+		return
+	}
+
 	// 初始化Redis连接
 	logger.Info("正在初始化Redis连接...")
 	err = repo.InitRedis()
@@ -55,11 +72,18 @@ func main() {
 	}
 	defer repo.CloseRedis()
 
-	// 创建数据库表
-	logger.Info("正在创建数据库表...")
-	err = repo.CreateTables()
-	if err != nil {
-		logger.WithError(err).Fatal("创建数据库表失败")
+	// 执行数据库迁移
+	if cfg.Database.AutoMigrate {
+		logger.Info("正在执行数据库迁移...")
+		migrator, err := migrations.NewMigrator(repo.DB)
+		if err != nil {
+			logger.WithError(err).Fatal("加载数据库迁移失败")
+		}
+		applied, err := migrator.Up(context.Background())
+		if err != nil {
+			logger.WithError(err).Fatal("数据库迁移失败")
+		}
+		logger.WithFields(logger.Fields{"applied": applied}).Info("数据库迁移完成")
 	}
 
 	// 初始化限流器
@@ -0,0 +1,54 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"sort"
+	"text/tabwriter"
+
+	"student-management-system/internal/config"
+	repo "student-management-system/internal/repository"
+	"student-management-system/internal/service"
+	"student-management-system/pkg/logger"
+)
+
+// runPasswordReportCommand 执行 password-report 子命令，列出仍在使用历史MD5密码的账号
+func runPasswordReportCommand(cfg *config.Config) error {
+	passwordManager, err := service.NewPasswordManager(cfg.Password)
+	if err != nil {
+		return err
+	}
+
+	loggerInstance := logger.GetLogger().Logger
+	adminService := service.NewAdminService(repo.NewAdminRepository(repo.DB, loggerInstance), passwordManager, loggerInstance)
+
+	report, err := adminService.PasswordHashReport()
+	if err != nil {
+		return err
+	}
+
+	algorithms := make([]string, 0, len(report.Counts))
+	for algorithm := range report.Counts {
+		algorithms = append(algorithms, algorithm)
+	}
+	sort.Strings(algorithms)
+
+	fmt.Printf("首选算法: %s，账号总数: %d\n", report.PreferredAlgorithm, report.Total)
+	for _, algorithm := range algorithms {
+		fmt.Printf("  %-10s %d\n", algorithm, report.Counts[algorithm])
+	}
+
+	if len(report.LegacyAccounts) == 0 {
+		fmt.Println("没有使用历史MD5密码的账号")
+		return nil
+	}
+
+	fmt.Printf("\n以下 %d 个账号仍使用历史密码哈希，将在下次登录成功后自动升级:\n", len(report.LegacyAccounts))
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "ID\tACCOUNT\tNAME\tROLE\tALGORITHM\tUPDATED AT")
+	for _, account := range report.LegacyAccounts {
+		fmt.Fprintf(w, "%d\t%s\t%s\t%s\t%s\t%s\n", account.ID, account.Account, account.Name,
+			account.Role, account.Algorithm, account.UpdatedAt.Format("2006-01-02 15:04:05"))
+	}
+	return w.Flush()
+}
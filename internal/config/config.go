@@ -12,14 +12,23 @@ import (
 
 // Config 应用配置结构
 type Config struct {
-	App       AppConfig       `mapstructure:"app"`
-	Database  DatabaseConfig  `mapstructure:"database"`
-	Log       LogConfig       `mapstructure:"log"`
-	JWT       JWTConfig       `mapstructure:"jwt"`
-	Redis     RedisConfig     `mapstructure:"redis"`
-	Server    ServerConfig    `mapstructure:"server"`
-	CORS      CORSConfig      `mapstructure:"cors"`
-	RateLimit RateLimitConfig `mapstructure:"rateLimit"`
+	App        AppConfig        `mapstructure:"app"`
+	Database   DatabaseConfig   `mapstructure:"database"`
+	Log        LogConfig        `mapstructure:"log"`
+	JWT        JWTConfig        `mapstructure:"jwt"`
+	Redis      RedisConfig      `mapstructure:"redis"`
+	Server     ServerConfig     `mapstructure:"server"`
+	CORS       CORSConfig       `mapstructure:"cors"`
+	RateLimit  RateLimitConfig  `mapstructure:"rateLimit"`
+	Password   PasswordConfig   `mapstructure:"password"`
+	TwoFactor  TwoFactorConfig  `mapstructure:"two_factor"`
+	Export     ExportConfig     `mapstructure:"export"`
+	Transcript TranscriptConfig `mapstructure:"transcript"`
+	GradeScale GradeScaleConfig `mapstructure:"grade_scale"`
+	Timetable  TimetableConfig  `mapstructure:"timetable"`
+	Calendar   CalendarConfig   `mapstructure:"calendar"`
+	Attendance AttendanceConfig `mapstructure:"attendance"`
+	Leave      LeaveConfig      `mapstructure:"leave"`
 }
 
 // AppConfig 应用配置
@@ -43,6 +52,7 @@ type DatabaseConfig struct {
 	MaxIdleConns    int           `mapstructure:"max_idle_conns"`
 	MaxOpenConns    int           `mapstructure:"max_open_conns"`
 	ConnMaxLifetime time.Duration `mapstructure:"conn_max_lifetime"`
+	AutoMigrate     bool          `mapstructure:"auto_migrate"` // 启动时自动执行未完成的迁移
 }
 
 // LogConfig 日志配置
@@ -59,9 +69,138 @@ type LogConfig struct {
 
 // JWTConfig JWT配置
 type JWTConfig struct {
-	Secret    string        `mapstructure:"secret"`
-	ExpiresIn time.Duration `mapstructure:"expires_in"`
-	Issuer    string        `mapstructure:"issuer"`
+	Secret           string        `mapstructure:"secret"`
+	ExpiresIn        time.Duration `mapstructure:"expires_in"`         // 访问token有效期，建议较短
+	RefreshExpiresIn time.Duration `mapstructure:"refresh_expires_in"` // 刷新token（会话）有效期，每次刷新后顺延
+	Issuer           string        `mapstructure:"issuer"`
+
+	// 配置 keys 后使用 RS256/EdDSA 签名，否则使用 secret 以 HS256 签名
+	SigningKeyID string         `mapstructure:"signing_kid"` // 当前签名密钥，为空则使用第一个带私钥的密钥
+	Keys         []JWTKeyConfig `mapstructure:"keys"`        // 全部验证密钥，轮换期间新旧密钥同时保留
+}
+
+// JWTKeyConfig JWT密钥文件配置
+type JWTKeyConfig struct {
+	KID            string `mapstructure:"kid"`
+	Algorithm      string `mapstructure:"algorithm"`        // RS256 或 EdDSA
+	PrivateKeyFile string `mapstructure:"private_key_file"` // PEM 私钥
+	PublicKeyFile  string `mapstructure:"public_key_file"`  // PEM 公钥，已停用签名的旧密钥只需公钥
+}
+
+// TwoFactorConfig 两步验证配置
+type TwoFactorConfig struct {
+	Issuer        string        `mapstructure:"issuer"`         // 认证器App中显示的发行方
+	RequiredRoles []string      `mapstructure:"required_roles"` // 强制启用两步验证的角色，如 admin、teacher
+	ChallengeTTL  time.Duration `mapstructure:"challenge_ttl"`  // 登录第二步的有效期
+}
+
+// ExportConfig 数据导出配置
+type ExportConfig struct {
+	Dir            string        `mapstructure:"dir"`             // 后台导出文件的存放目录
+	AsyncThreshold int           `mapstructure:"async_threshold"` // 超过该行数时自动转为后台导出任务
+	JobTTL         time.Duration `mapstructure:"job_ttl"`         // 后台导出任务及文件的保留时间
+}
+
+// TranscriptConfig 成绩单配置
+type TranscriptConfig struct {
+	SchoolName   string `mapstructure:"school_name"`    // 成绩单抬头的学校名称
+	SchoolNameEN string `mapstructure:"school_name_en"` // 学校英文名称
+	Address      string `mapstructure:"address"`        // 学校地址
+	Phone        string `mapstructure:"phone"`          // 联系电话
+	Issuer       string `mapstructure:"issuer"`         // 签发部门
+	TemplateDir  string `mapstructure:"template_dir"`   // 自定义模板目录，存在同名模板时覆盖内置模板
+	VerifyURL    string `mapstructure:"verify_url"`     // 印在成绩单上的验证地址前缀，后接验证码
+}
+
+// GradeScaleConfig 绩点制配置
+type GradeScaleConfig struct {
+	Default string                 `mapstructure:"default"` // 默认绩点制，专业未单独设置时使用
+	Scales  []GradeScaleDefinition `mapstructure:"scales"`  // 配置文件中定义的绩点制，不能通过接口修改
+}
+
+// GradeScaleDefinition 配置文件中定义的绩点制
+type GradeScaleDefinition struct {
+	Name        string                `mapstructure:"name"`
+	DisplayName string                `mapstructure:"display_name"`
+	Description string                `mapstructure:"description"`
+	Formula     string                `mapstructure:"formula"`   // bands、linear 或 pku
+	MaxPoint    float64               `mapstructure:"max_point"` // 满绩点
+	Bands       []GradeBandDefinition `mapstructure:"bands"`
+}
+
+// GradeBandDefinition 绩点制的等级分数段
+type GradeBandDefinition struct {
+	MinScore   float64  `mapstructure:"min_score"`
+	Letter     string   `mapstructure:"letter"`
+	GradePoint *float64 `mapstructure:"grade_point"` // 留空表示不计入平均绩点
+	Passed     bool     `mapstructure:"passed"`
+}
+
+// TimetableConfig 课表配置
+type TimetableConfig struct {
+	DaysPerWeek int                `mapstructure:"days_per_week"` // 每周上课天数，自动排课只在这些天内安排
+	Periods     []PeriodDefinition `mapstructure:"periods"`       // 每天的节次，按顺序为第1节、第2节……
+}
+
+// PeriodDefinition 节次的上下课时间
+type PeriodDefinition struct {
+	Start string `mapstructure:"start"` // 上课时间，如 08:00
+	End   string `mapstructure:"end"`   // 下课时间，如 08:45
+}
+
+// CalendarConfig 日历订阅配置
+type CalendarConfig struct {
+	Timezone        string        `mapstructure:"timezone"`         // 课表和考试时间所在的时区，如 Asia/Shanghai
+	FeedURL         string        `mapstructure:"feed_url"`         // 订阅地址前缀，后接订阅令牌
+	RefreshInterval time.Duration `mapstructure:"refresh_interval"` // 建议日历客户端刷新订阅的间隔
+}
+
+// AttendanceConfig 考勤配置。缺勤率 = 缺勤课次 / 已考勤课次，缺勤课次为旷课次数加迟到折算的次数，
+// count_leave 为 true 时事假和病假也计入缺勤
+type AttendanceConfig struct {
+	LatesPerAbsence int     `mapstructure:"lates_per_absence"` // 迟到几次折算为一次缺勤，0 表示迟到不计入缺勤
+	CountLeave      bool    `mapstructure:"count_leave"`       // 事假和病假是否计入缺勤
+	MinSessions     int     `mapstructure:"min_sessions"`      // 已考勤课次达到该数量后才进行预警和取消考试资格
+	AtRiskRate      float64 `mapstructure:"at_risk_rate"`      // 缺勤率达到该值时预警
+	ExamBlockRate   float64 `mapstructure:"exam_block_rate"`   // 缺勤率达到该值时取消该科目期末考试资格
+}
+
+// LeaveConfig 请假配置
+type LeaveConfig struct {
+	MaxDays        int                        `mapstructure:"max_days"`        // 单次请假的最长天数
+	ApprovalChains []LeaveApprovalChainConfig `mapstructure:"approval_chains"` // 按请假天数确定的审批链
+}
+
+// LeaveApprovalChainConfig 请假审批链：请假天数不超过 max_days 时依次由 approvers 审批，max_days 为 0 表示不限天数
+type LeaveApprovalChainConfig struct {
+	MaxDays   int      `mapstructure:"max_days"`
+	Approvers []string `mapstructure:"approvers"` // homeroom 班主任，admin 管理员
+}
+
+// PasswordConfig 密码哈希与密码策略配置
+type PasswordConfig struct {
+	Algorithm  string               `mapstructure:"algorithm"` // argon2id 或 bcrypt，历史MD5密码登录后自动升级
+	BcryptCost int                  `mapstructure:"bcrypt_cost"`
+	Argon2     Argon2Config         `mapstructure:"argon2"`
+	Policy     PasswordPolicyConfig `mapstructure:"policy"`
+}
+
+// Argon2Config argon2id 参数
+type Argon2Config struct {
+	Memory      uint32 `mapstructure:"memory"` // 单位KiB
+	Iterations  uint32 `mapstructure:"iterations"`
+	Parallelism uint8  `mapstructure:"parallelism"`
+}
+
+// PasswordPolicyConfig 密码策略配置，创建和修改账号密码时生效
+type PasswordPolicyConfig struct {
+	MinLength       int  `mapstructure:"min_length"`
+	MaxLength       int  `mapstructure:"max_length"`
+	RequireUpper    bool `mapstructure:"require_upper"`
+	RequireLower    bool `mapstructure:"require_lower"`
+	RequireDigit    bool `mapstructure:"require_digit"`
+	RequireSpecial  bool `mapstructure:"require_special"`
+	DisallowAccount bool `mapstructure:"disallow_account"`
 }
 
 // RedisConfig Redis配置
@@ -162,6 +301,7 @@ func setDefaults() {
 	viper.SetDefault("database.max_idle_conns", 10)
 	viper.SetDefault("database.max_open_conns", 100)
 	viper.SetDefault("database.conn_max_lifetime", 3600)
+	viper.SetDefault("database.auto_migrate", true)
 
 	// Log defaults
 	viper.SetDefault("log.level", "info")
@@ -176,8 +316,79 @@ func setDefaults() {
 	// JWT defaults
 	viper.SetDefault("jwt.secret", "your-secret-key")
 	viper.SetDefault("jwt.expires_in", 86400)
+	viper.SetDefault("jwt.refresh_expires_in", "168h")
 	viper.SetDefault("jwt.issuer", "student-management-system")
 
+	// Password defaults
+	viper.SetDefault("password.algorithm", "argon2id")
+	viper.SetDefault("password.bcrypt_cost", 12)
+	viper.SetDefault("password.argon2.memory", 65536)
+	viper.SetDefault("password.argon2.iterations", 3)
+	viper.SetDefault("password.argon2.parallelism", 2)
+	viper.SetDefault("password.policy.min_length", 8)
+	viper.SetDefault("password.policy.max_length", 100)
+	viper.SetDefault("password.policy.require_upper", false)
+	viper.SetDefault("password.policy.require_lower", true)
+	viper.SetDefault("password.policy.require_digit", true)
+	viper.SetDefault("password.policy.require_special", false)
+	viper.SetDefault("password.policy.disallow_account", true)
+
+	// Two-factor defaults
+	viper.SetDefault("two_factor.issuer", "student-management-system")
+	viper.SetDefault("two_factor.required_roles", []string{})
+	viper.SetDefault("two_factor.challenge_ttl", "5m")
+
+	// Export defaults
+	viper.SetDefault("export.dir", "exports")
+	viper.SetDefault("export.async_threshold", 50000)
+	viper.SetDefault("export.job_ttl", "24h")
+
+	// Transcript defaults
+	viper.SetDefault("transcript.school_name", "学生管理系统")
+	viper.SetDefault("transcript.school_name_en", "")
+	viper.SetDefault("transcript.address", "")
+	viper.SetDefault("transcript.phone", "")
+	viper.SetDefault("transcript.issuer", "教务处")
+	viper.SetDefault("transcript.template_dir", "")
+	viper.SetDefault("transcript.verify_url", "http://localhost:8080/api/v1/transcripts/verify/")
+
+	// Grade scale defaults
+	viper.SetDefault("grade_scale.default", "standard4")
+
+	// Timetable defaults
+	viper.SetDefault("timetable.days_per_week", 5)
+	viper.SetDefault("timetable.periods", []map[string]string{
+		{"start": "08:00", "end": "08:45"},
+		{"start": "08:55", "end": "09:40"},
+		{"start": "10:00", "end": "10:45"},
+		{"start": "10:55", "end": "11:40"},
+		{"start": "14:00", "end": "14:45"},
+		{"start": "14:55", "end": "15:40"},
+		{"start": "16:00", "end": "16:45"},
+		{"start": "16:55", "end": "17:40"},
+		{"start": "19:00", "end": "19:45"},
+		{"start": "19:55", "end": "20:40"},
+	})
+
+	// Calendar defaults
+	viper.SetDefault("calendar.timezone", "Asia/Shanghai")
+	viper.SetDefault("calendar.feed_url", "http://localhost:8080/api/v1/calendar/ics/")
+	viper.SetDefault("calendar.refresh_interval", "6h")
+
+	// Attendance defaults
+	viper.SetDefault("attendance.lates_per_absence", 3)
+	viper.SetDefault("attendance.count_leave", false)
+	viper.SetDefault("attendance.min_sessions", 4)
+	viper.SetDefault("attendance.at_risk_rate", 0.2)
+	viper.SetDefault("attendance.exam_block_rate", 1.0/3)
+
+	// Leave defaults
+	viper.SetDefault("leave.max_days", 30)
+	viper.SetDefault("leave.approval_chains", []map[string]interface{}{
+		{"max_days": 3, "approvers": []string{"homeroom"}},
+		{"max_days": 0, "approvers": []string{"homeroom", "admin"}},
+	})
+
 	// Redis defaults
 	viper.SetDefault("redis.host", "localhost")
 	viper.SetDefault("redis.port", 6379)
@@ -276,6 +487,9 @@ func expandEnvVars(config *Config) error {
 	if secret := os.Getenv("JWT_SECRET"); secret != "" {
 		config.JWT.Secret = secret
 	}
+	if kid := os.Getenv("JWT_SIGNING_KID"); kid != "" {
+		config.JWT.SigningKeyID = kid
+	}
 
 	// Redis配置
 	if host := os.Getenv("REDIS_HOST"); host != "" {
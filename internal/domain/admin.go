@@ -13,21 +13,57 @@ type Admin struct {
 	Name      string    `json:"name" db:"name" validate:"required,min=1,max=50,nohtml,nosql"`       // 用户姓名
 	Phone     string    `json:"phone" db:"phone" validate:"omitempty,len=11,numeric"`               // 手机号
 	Email     string    `json:"email" db:"email" validate:"omitempty,email,max=100"`                // 邮箱
+	Role      string    `json:"role" db:"role"`                                                     // 角色
+	TeacherID *int      `json:"teacher_id,omitempty" db:"teacher_id"`                               // 教师账号关联的教师
+	StudentID *int      `json:"student_id,omitempty" db:"student_id"`                               // 学生账号关联的学生
 	CreatedAt time.Time `json:"created_at" db:"created_at"`
 	UpdatedAt time.Time `json:"updated_at" db:"updated_at"`
+
+	// 家长账号关联的子女
+	StudentIDs []int `json:"student_ids,omitempty" db:"-"`
+
+	// 两步验证，绑定未确认前 TOTPEnabled 为 false
+	TOTPSecret  string `json:"-" db:"totp_secret"`
+	TOTPEnabled bool   `json:"totp_enabled" db:"totp_enabled"`
+}
+
+// ToInfo 转换为不含密码的管理员信息
+func (a *Admin) ToInfo() AdminInfo {
+	return AdminInfo{
+		ID:         a.ID,
+		Account:    a.Account,
+		Name:       a.Name,
+		Phone:      a.Phone,
+		Email:      a.Email,
+		Role:       a.Role,
+		TeacherID:  a.TeacherID,
+		StudentID:  a.StudentID,
+		StudentIDs: a.StudentIDs,
+
+		TwoFactorEnabled: a.TOTPEnabled,
+	}
 }
 
 // LoginRequest 登录请求结构体
 type LoginRequest struct {
 	Account  string `json:"account" validate:"required,min=3,max=50,nohtml,nosql" example:"admin"`
 	Password string `json:"password" validate:"required,min=6,max=100" example:"123456"`
+	Device   string `json:"device" validate:"omitempty,max=100,nohtml" example:"Chrome on Windows"` // 可选，用于会话列表展示
 }
 
 // LoginResponse 登录响应结构体
 type LoginResponse struct {
-	Token     string    `json:"token" example:"eyJhbGciOiJIUzI1NiIsInR5cCI6IkpXVCJ9..."`
-	ExpiresAt time.Time `json:"expires_at" example:"2024-01-01T12:00:00Z"`
-	Admin     AdminInfo `json:"admin"`
+	Token            string    `json:"token" example:"eyJhbGciOiJIUzI1NiIsInR5cCI6IkpXVCJ9..."`
+	ExpiresAt        time.Time `json:"expires_at" example:"2024-01-01T12:00:00Z"`
+	RefreshToken     string    `json:"refresh_token" example:"3q2-7wEAAAB..."`
+	RefreshExpiresAt time.Time `json:"refresh_expires_at" example:"2024-01-08T12:00:00Z"`
+	Admin            AdminInfo `json:"admin"`
+	RecoveryCodes    []string  `json:"recovery_codes,omitempty"` // 登录时完成两步验证绑定才返回，只显示一次
+}
+
+// RefreshTokenRequest 刷新token请求结构体
+type RefreshTokenRequest struct {
+	RefreshToken string `json:"refresh_token" validate:"required"`
 }
 
 // AdminInfo 管理员信息（不包含密码）
@@ -37,14 +73,44 @@ type AdminInfo struct {
 	Name    string `json:"name" example:"管理员"`
 	Phone   string `json:"phone" example:"13800138000"`
 	Email   string `json:"email" example:"admin@example.com"`
+	Role    string `json:"role" example:"admin"`
+
+	TeacherID  *int  `json:"teacher_id,omitempty" example:"1"`
+	StudentID  *int  `json:"student_id,omitempty" example:"1"`
+	StudentIDs []int `json:"student_ids,omitempty"`
+
+	TwoFactorEnabled bool `json:"two_factor_enabled" example:"false"`
 }
 
 // JWTClaims JWT声明结构体
 type JWTClaims struct {
-	AdminID int    `json:"admin_id"`
-	Account string `json:"account"`
-	Exp     int64  `json:"exp"`
-	Iat     int64  `json:"iat"`
+	AdminID    int    `json:"admin_id"`
+	Account    string `json:"account"`
+	Role       string `json:"role"`
+	TeacherID  int    `json:"teacher_id,omitempty"`  // 教师账号对应的教师ID
+	StudentIDs []int  `json:"student_ids,omitempty"` // 学生本人或家长子女的学生ID
+	JTI        string `json:"jti"`                   // 会话ID
+	Exp        int64  `json:"exp"`
+	Iat        int64  `json:"iat"`
+	Iss        string `json:"iss,omitempty"`
+}
+
+// IsStudentScoped 是否只能访问本人（子女）的数据
+func (c *JWTClaims) IsStudentScoped() bool {
+	return c.Role == RoleStudent || c.Role == RoleParent
+}
+
+// CanAccessStudent 判断是否可以访问指定学生的数据
+func (c *JWTClaims) CanAccessStudent(studentID int) bool {
+	if !c.IsStudentScoped() {
+		return true
+	}
+	for _, id := range c.StudentIDs {
+		if id == studentID {
+			return true
+		}
+	}
+	return false
 }
 
 // Valid 验证JWT声明是否有效
@@ -62,6 +128,11 @@ type CreateAdminRequest struct {
 	Name     string `json:"name" validate:"required,min=1,max=50,nohtml,nosql" example:"张三"`
 	Phone    string `json:"phone" validate:"omitempty,len=11,numeric" example:"13800138000"`
 	Email    string `json:"email" validate:"omitempty,email,max=100" example:"admin@example.com"`
+
+	Role       string `json:"role" validate:"omitempty,oneof=admin teacher student parent" example:"teacher"` // 默认为 admin
+	TeacherID  int    `json:"teacher_id" validate:"omitempty,min=1" example:"1"`                              // 教师账号必填
+	StudentID  int    `json:"student_id" validate:"omitempty,min=1" example:"1"`                              // 学生账号必填
+	StudentIDs []int  `json:"student_ids" validate:"omitempty,max=10,dive,min=1"`                             // 家长账号必填
 }
 
 // UpdateAdminRequest 更新管理员请求结构体
@@ -71,6 +142,11 @@ type UpdateAdminRequest struct {
 	Name     string `json:"name" validate:"required,min=1,max=50,nohtml,nosql" example:"张三"`
 	Phone    string `json:"phone" validate:"omitempty,len=11,numeric" example:"13800138000"`
 	Email    string `json:"email" validate:"omitempty,email,max=100" example:"admin@example.com"`
+
+	Role       string `json:"role" validate:"omitempty,oneof=admin teacher student parent" example:"teacher"` // 不填则不更新
+	TeacherID  int    `json:"teacher_id" validate:"omitempty,min=1" example:"1"`
+	StudentID  int    `json:"student_id" validate:"omitempty,min=1" example:"1"`
+	StudentIDs []int  `json:"student_ids" validate:"omitempty,max=10,dive,min=1"`
 }
 
 // AdminListRequest 管理员列表请求结构体
@@ -88,3 +164,21 @@ type AdminListResponse struct {
 	Size  int         `json:"size" example:"10"`
 	Data  []AdminInfo `json:"data"`
 }
+
+// PasswordHashReport 密码哈希算法统计报告
+type PasswordHashReport struct {
+	PreferredAlgorithm string                  `json:"preferred_algorithm"`
+	Total              int                     `json:"total"`
+	Counts             map[string]int          `json:"counts"`          // 各算法的账号数量
+	LegacyAccounts     []LegacyPasswordAccount `json:"legacy_accounts"` // 仍使用MD5或无法识别哈希的账号
+}
+
+// LegacyPasswordAccount 仍使用历史密码哈希的账号
+type LegacyPasswordAccount struct {
+	ID        int       `json:"id"`
+	Account   string    `json:"account"`
+	Name      string    `json:"name"`
+	Role      string    `json:"role"`
+	Algorithm string    `json:"algorithm"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
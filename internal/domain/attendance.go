@@ -0,0 +1,181 @@
+package domain
+
+import "time"
+
+// 出勤状态
+const (
+	AttendancePresent = "present" // 出勤
+	AttendanceLate    = "late"    // 迟到
+	AttendanceAbsent  = "absent"  // 旷课
+	AttendanceExcused = "excused" // 事假
+	AttendanceSick    = "sick"    // 病假
+)
+
+// AttendanceStatusNames 出勤状态的中文名称
+var AttendanceStatusNames = map[string]string{
+	AttendancePresent: "出勤",
+	AttendanceLate:    "迟到",
+	AttendanceAbsent:  "旷课",
+	AttendanceExcused: "事假",
+	AttendanceSick:    "病假",
+}
+
+// 考勤预警类型
+const (
+	AttendanceFlagAtRisk      = "at_risk"      // 缺勤预警
+	AttendanceFlagExamBlocked = "exam_blocked" // 取消期末考试资格
+)
+
+// AttendanceCounts 各出勤状态的记录数。汇总时为各状态的课次数，一次课中为各状态的人数
+type AttendanceCounts struct {
+	Total   int `json:"total"`
+	Present int `json:"present"`
+	Late    int `json:"late"`
+	Absent  int `json:"absent"`
+	Excused int `json:"excused"`
+	Sick    int `json:"sick"`
+}
+
+// Add 计入一次考勤
+func (c *AttendanceCounts) Add(status string) {
+	c.Total++
+	switch status {
+	case AttendancePresent:
+		c.Present++
+	case AttendanceLate:
+		c.Late++
+	case AttendanceAbsent:
+		c.Absent++
+	case AttendanceExcused:
+		c.Excused++
+	case AttendanceSick:
+		c.Sick++
+	}
+}
+
+// Merge 累加另一组课次数
+func (c *AttendanceCounts) Merge(other AttendanceCounts) {
+	c.Total += other.Total
+	c.Present += other.Present
+	c.Late += other.Late
+	c.Absent += other.Absent
+	c.Excused += other.Excused
+	c.Sick += other.Sick
+}
+
+// AttendanceSession 考勤课次：开课在某天的一次课
+type AttendanceSession struct {
+	ID          int       `json:"id" db:"id"`
+	OfferingID  int       `json:"offering_id" db:"offering_id"`
+	SlotID      *int      `json:"slot_id" db:"slot_id"`   // 对应的课表时段
+	ClassID     *int      `json:"class_id" db:"class_id"` // 上课的行政班
+	SessionDate time.Time `json:"session_date" db:"session_date"`
+	StartPeriod int       `json:"start_period" db:"start_period"`
+	EndPeriod   int       `json:"end_period" db:"end_period"`
+	RecordedBy  *int      `json:"recorded_by,omitempty" db:"recorded_by"`
+	CreatedAt   time.Time `json:"created_at" db:"created_at"`
+	UpdatedAt   time.Time `json:"updated_at" db:"updated_at"`
+
+	// 扩展字段（用于关联查询）
+	Semester    string              `json:"semester" db:"-"`
+	SubjectID   int                 `json:"subject_id" db:"-"`
+	SubjectName string              `json:"subject_name,omitempty" db:"-"`
+	Section     string              `json:"section,omitempty" db:"-"`
+	ClassName   string              `json:"class_name,omitempty" db:"-"`
+	Counts      AttendanceCounts    `json:"counts" db:"-"`
+	Records     []*AttendanceRecord `json:"records,omitempty" db:"-"`
+}
+
+// AttendanceRecord 学生在一次课的考勤记录
+type AttendanceRecord struct {
+	ID        int       `json:"id" db:"id"`
+	SessionID int       `json:"session_id" db:"session_id"`
+	StudentID int       `json:"student_id" db:"student_id"`
+	Status    string    `json:"status" db:"status"`
+	Note      string    `json:"note,omitempty" db:"note"`
+	CreatedAt time.Time `json:"created_at" db:"created_at"`
+	UpdatedAt time.Time `json:"updated_at" db:"updated_at"`
+
+	// 扩展字段（用于关联查询）
+	StudentNo   string `json:"student_no,omitempty" db:"-"` // 学号
+	StudentName string `json:"student_name,omitempty" db:"-"`
+}
+
+// AttendanceEntry 批量考勤中单个学生的出勤状态
+type AttendanceEntry struct {
+	StudentID int    `json:"student_id" validate:"required,min=1"`
+	Status    string `json:"status" validate:"required,oneof=present late absent excused sick"`
+	Note      string `json:"note" validate:"omitempty,max=200,nohtml,nosql"`
+}
+
+// RecordAttendanceRequest 批量记录一次课的考勤请求结构。
+// 按课表时段记录时节次和行政班取自课表，否则须指定开始节次；名单中未列出的学生记为 default_status
+type RecordAttendanceRequest struct {
+	OfferingID    int               `json:"offering_id" validate:"required,min=1"`
+	SessionDate   time.Time         `json:"session_date" validate:"required"`
+	SlotID        int               `json:"slot_id" validate:"omitempty,min=1"`
+	StartPeriod   int               `json:"start_period" validate:"omitempty,min=1"`
+	EndPeriod     int               `json:"end_period" validate:"omitempty,min=1"` // 默认与开始节次相同
+	DefaultStatus string            `json:"default_status" validate:"omitempty,oneof=present late absent excused sick"`
+	Records       []AttendanceEntry `json:"records" validate:"omitempty,max=500,dive"`
+}
+
+// AttendanceSessionListRequest 考勤课次列表请求结构
+type AttendanceSessionListRequest struct {
+	Page       int        `json:"page" form:"page" validate:"omitempty,min=1"`
+	Size       int        `json:"size" form:"size" validate:"omitempty,min=1,max=100"`
+	Semester   string     `json:"semester" form:"semester" validate:"omitempty,max=20,nohtml,nosql"`
+	OfferingID int        `json:"offering_id" form:"offering_id" validate:"omitempty,min=1"`
+	ClassID    int        `json:"class_id" form:"class_id" validate:"omitempty,min=1"`
+	DateFrom   *time.Time `json:"date_from" form:"date_from" time_format:"2006-01-02"`
+	DateTo     *time.Time `json:"date_to" form:"date_to" time_format:"2006-01-02"`
+}
+
+// AttendanceSummary 学生在一门开课的考勤汇总。
+// 缺勤课次 = 旷课 + 迟到折算的缺勤（+ 请假，取决于配置），缺勤率 = 缺勤课次 / 已考勤课次（total）
+type AttendanceSummary struct {
+	StudentID   int     `json:"student_id"`
+	StudentNo   string  `json:"student_no,omitempty"`
+	StudentName string  `json:"student_name,omitempty"`
+	OfferingID  int     `json:"offering_id"`
+	Semester    string  `json:"semester"`
+	SubjectID   int     `json:"subject_id"`
+	SubjectName string  `json:"subject_name,omitempty"`
+	Section     string  `json:"section,omitempty"`
+	AbsenceRate float64 `json:"absence_rate"`
+	Absences    int     `json:"absences"`
+	AtRisk      bool    `json:"at_risk"`      // 缺勤预警
+	ExamBlocked bool    `json:"exam_blocked"` // 已取消该科目期末考试资格
+
+	AttendanceCounts
+}
+
+// StudentAttendanceReport 学生学期考勤汇总
+type StudentAttendanceReport struct {
+	StudentID   int                  `json:"student_id"`
+	StudentNo   string               `json:"student_no"`
+	StudentName string               `json:"student_name"`
+	Semester    string               `json:"semester"`
+	Totals      AttendanceCounts     `json:"totals"`
+	AtRisk      int                  `json:"at_risk"`      // 预警的课程数
+	ExamBlocked int                  `json:"exam_blocked"` // 取消考试资格的课程数
+	Courses     []*AttendanceSummary `json:"courses"`
+}
+
+// AttendanceSummaryRequest 学期考勤汇总请求结构
+type AttendanceSummaryRequest struct {
+	Page       int    `json:"page" form:"page" validate:"omitempty,min=1"`
+	Size       int    `json:"size" form:"size" validate:"omitempty,min=1,max=100"`
+	Semester   string `json:"semester" form:"semester" validate:"omitempty,max=20,nohtml,nosql"` // 默认当前学期
+	OfferingID int    `json:"offering_id" form:"offering_id" validate:"omitempty,min=1"`
+	ClassID    int    `json:"class_id" form:"class_id" validate:"omitempty,min=1"` // 学生当前所在班级
+	Flag       string `json:"flag" form:"flag" validate:"omitempty,oneof=at_risk exam_blocked"`
+
+	// 学生汇总时由服务层设置
+	StudentID int `json:"-" form:"-"`
+}
+
+// StudentAttendanceRequest 学生学期考勤汇总请求结构
+type StudentAttendanceRequest struct {
+	Semester string `json:"semester" form:"semester" validate:"omitempty,max=20,nohtml,nosql"` // 默认当前学期
+}
@@ -0,0 +1,38 @@
+package domain
+
+import "time"
+
+// 日历订阅对象
+const (
+	CalendarOwnerTeacher = "teacher"
+	CalendarOwnerClass   = "class"
+	CalendarOwnerStudent = "student"
+)
+
+// CalendarFeed 日历订阅。订阅令牌只在创建时返回一次，之后只保存哈希
+type CalendarFeed struct {
+	ID         int        `json:"id" db:"id"`
+	OwnerType  string     `json:"owner_type" db:"owner_type"` // teacher、class 或 student
+	OwnerID    int        `json:"owner_id" db:"owner_id"`
+	Name       string     `json:"name,omitempty" db:"name"`
+	CreatedBy  int        `json:"created_by" db:"created_by"`
+	LastUsedAt *time.Time `json:"last_used_at,omitempty" db:"last_used_at"`
+	RevokedAt  *time.Time `json:"revoked_at,omitempty" db:"revoked_at"`
+	CreatedAt  time.Time  `json:"created_at" db:"created_at"`
+
+	TokenHash string `json:"-" db:"token_hash"`
+	Token     string `json:"token,omitempty" db:"-"` // 仅创建时返回
+	URL       string `json:"url,omitempty" db:"-"`   // 仅创建时返回
+}
+
+// IsActive 订阅是否有效
+func (f *CalendarFeed) IsActive() bool {
+	return f.RevokedAt == nil
+}
+
+// CreateCalendarFeedRequest 创建日历订阅请求结构
+type CreateCalendarFeedRequest struct {
+	OwnerType string `json:"owner_type" validate:"required,oneof=teacher class student"`
+	OwnerID   int    `json:"owner_id" validate:"required,min=1"`
+	Name      string `json:"name" validate:"omitempty,max=50,nohtml,nosql"` // 便于区分多个订阅，如"手机日历"
+}
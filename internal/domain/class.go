@@ -0,0 +1,84 @@
+package domain
+
+import (
+	"time"
+)
+
+// 班级状态
+const (
+	ClassStatusActive   = "active"   // 在读
+	ClassStatusArchived = "archived" // 已毕业或撤销
+)
+
+// Class 行政班数据模型
+type Class struct {
+	ID                int       `json:"id" db:"id"`
+	Name              string    `json:"name" db:"name" validate:"required,min=2,max=50,nohtml,nosql"`
+	GradeYear         int       `json:"grade_year" db:"grade_year" validate:"required,min=1900,max=2100"` // 年级（入学年份）
+	Major             string    `json:"major" db:"major" validate:"required,min=2,max=50,nohtml,nosql"`
+	HomeroomTeacherID *int      `json:"homeroom_teacher_id" db:"homeroom_teacher_id"` // 班主任
+	Status            string    `json:"status" db:"status" validate:"required,oneof=active archived"`
+	CreatedAt         time.Time `json:"created_at" db:"created_at"`
+	UpdatedAt         time.Time `json:"updated_at" db:"updated_at"`
+
+	// 扩展字段（用于关联查询）
+	HomeroomTeacherName string `json:"homeroom_teacher_name,omitempty" db:"-"`
+	StudentCount        int    `json:"student_count" db:"-"` // 当前在班学生数
+}
+
+// CreateClassRequest 创建班级请求结构
+type CreateClassRequest struct {
+	Name              string `json:"name" validate:"required,min=2,max=50,nohtml,nosql"`
+	GradeYear         int    `json:"grade_year" validate:"required,min=1900,max=2100"`
+	Major             string `json:"major" validate:"required,min=2,max=50,nohtml,nosql"`
+	HomeroomTeacherID *int   `json:"homeroom_teacher_id" validate:"omitempty,min=1"`
+	Status            string `json:"status" validate:"omitempty,oneof=active archived"`
+}
+
+// UpdateClassRequest 更新班级请求结构
+type UpdateClassRequest struct {
+	Name              string `json:"name" validate:"omitempty,min=2,max=50,nohtml,nosql"`
+	GradeYear         int    `json:"grade_year" validate:"omitempty,min=1900,max=2100"`
+	Major             string `json:"major" validate:"omitempty,min=2,max=50,nohtml,nosql"`
+	HomeroomTeacherID *int   `json:"homeroom_teacher_id" validate:"omitempty,min=0"` // 传 0 表示取消班主任
+	Status            string `json:"status" validate:"omitempty,oneof=active archived"`
+}
+
+// ClassListRequest 班级列表请求结构
+type ClassListRequest struct {
+	Page              int    `json:"page" form:"page" validate:"omitempty,min=1"`
+	Size              int    `json:"size" form:"size" validate:"omitempty,min=1,max=100"`
+	Name              string `json:"name" form:"name" validate:"omitempty,max=50,nohtml,nosql"`
+	GradeYear         int    `json:"grade_year" form:"grade_year" validate:"omitempty,min=1900,max=2100"`
+	Major             string `json:"major" form:"major" validate:"omitempty,max=50,nohtml,nosql"`
+	HomeroomTeacherID int    `json:"homeroom_teacher_id" form:"homeroom_teacher_id" validate:"omitempty,min=1"`
+	Status            string `json:"status" form:"status" validate:"omitempty,oneof=active archived"`
+}
+
+// ClassMembership 学生分班记录，LeftAt 为空表示当前所在班级
+type ClassMembership struct {
+	ID        int        `json:"id" db:"id"`
+	StudentID int        `json:"student_id" db:"student_id"`
+	ClassID   int        `json:"class_id" db:"class_id"`
+	JoinedAt  time.Time  `json:"joined_at" db:"joined_at"`
+	LeftAt    *time.Time `json:"left_at" db:"left_at"`
+	Reason    string     `json:"reason,omitempty" db:"reason"`
+	CreatedBy *int       `json:"created_by,omitempty" db:"created_by"`
+	CreatedAt time.Time  `json:"created_at" db:"created_at"`
+
+	// 扩展字段（用于关联查询）
+	ClassName string `json:"class_name,omitempty" db:"-"`
+	GradeYear int    `json:"grade_year,omitempty" db:"-"`
+}
+
+// AssignClassStudentsRequest 学生分班请求结构，已在其他班级的学生会转入本班
+type AssignClassStudentsRequest struct {
+	StudentIDs []int      `json:"student_ids" validate:"required,min=1,max=200,dive,min=1"`
+	JoinedAt   *time.Time `json:"joined_at"` // 默认当天
+	Reason     string     `json:"reason" validate:"omitempty,max=200,nohtml,nosql"`
+}
+
+// RemoveClassStudentRequest 学生离班请求参数
+type RemoveClassStudentRequest struct {
+	Reason string `json:"reason" form:"reason" validate:"omitempty,max=200,nohtml,nosql"`
+}
@@ -0,0 +1,112 @@
+package domain
+
+import (
+	"time"
+)
+
+// 开课状态
+const (
+	OfferingStatusOpen      = "open"      // 开放选课
+	OfferingStatusClosed    = "closed"    // 停止选课
+	OfferingStatusCancelled = "cancelled" // 已取消
+)
+
+// 选课状态
+const (
+	EnrollmentStatusEnrolled   = "enrolled"   // 已选上
+	EnrollmentStatusWaitlisted = "waitlisted" // 候补中
+	EnrollmentStatusDropped    = "dropped"    // 已退课
+)
+
+// CourseOffering 开课数据模型，即某学期由某位老师开设的科目教学班
+type CourseOffering struct {
+	ID               int        `json:"id" db:"id"`
+	SubjectID        int        `json:"subject_id" db:"subject_id" validate:"required,min=1"`
+	Semester         string     `json:"semester" db:"semester" validate:"required,min=5,max=20,nohtml,nosql"`
+	Section          string     `json:"section" db:"section" validate:"required,max=20,nohtml,nosql"` // 教学班号
+	TeacherID        *int       `json:"teacher_id" db:"teacher_id"`
+	Capacity         int        `json:"capacity" db:"capacity" validate:"required,min=1,max=1000"`
+	WaitlistCapacity int        `json:"waitlist_capacity" db:"waitlist_capacity" validate:"min=0,max=1000"` // 0 表示不开放候补
+	Schedule         string     `json:"schedule" db:"schedule" validate:"omitempty,max=200,nohtml,nosql"`   // 上课时间地点，如"周一 1-2节 A101"
+	AddDeadline      *time.Time `json:"add_deadline" db:"add_deadline"`                                     // 加选截止时间
+	DropDeadline     *time.Time `json:"drop_deadline" db:"drop_deadline"`                                   // 退选截止时间
+	Status           string     `json:"status" db:"status" validate:"required,oneof=open closed cancelled"`
+	CreatedAt        time.Time  `json:"created_at" db:"created_at"`
+	UpdatedAt        time.Time  `json:"updated_at" db:"updated_at"`
+
+	// 扩展字段（用于关联查询）
+	SubjectName   string `json:"subject_name,omitempty" db:"-"`
+	SubjectCode   string `json:"subject_code,omitempty" db:"-"`
+	TeacherName   string `json:"teacher_name,omitempty" db:"-"`
+	EnrolledCount int    `json:"enrolled_count" db:"-"` // 已选人数
+	WaitlistCount int    `json:"waitlist_count" db:"-"` // 候补人数
+}
+
+// CreateCourseOfferingRequest 创建开课请求结构
+type CreateCourseOfferingRequest struct {
+	SubjectID        int        `json:"subject_id" validate:"required,min=1"`
+	Semester         string     `json:"semester" validate:"required,min=5,max=20,nohtml,nosql"`
+	Section          string     `json:"section" validate:"omitempty,max=20,nohtml,nosql"` // 默认 01
+	TeacherID        *int       `json:"teacher_id" validate:"omitempty,min=1"`
+	Capacity         int        `json:"capacity" validate:"required,min=1,max=1000"`
+	WaitlistCapacity int        `json:"waitlist_capacity" validate:"omitempty,min=0,max=1000"`
+	Schedule         string     `json:"schedule" validate:"omitempty,max=200,nohtml,nosql"`
+	AddDeadline      *time.Time `json:"add_deadline"`
+	DropDeadline     *time.Time `json:"drop_deadline"`
+	Status           string     `json:"status" validate:"omitempty,oneof=open closed cancelled"`
+}
+
+// UpdateCourseOfferingRequest 更新开课请求结构
+type UpdateCourseOfferingRequest struct {
+	Section          string     `json:"section" validate:"omitempty,max=20,nohtml,nosql"`
+	TeacherID        *int       `json:"teacher_id" validate:"omitempty,min=0"` // 传 0 表示取消任课老师
+	Capacity         int        `json:"capacity" validate:"omitempty,min=1,max=1000"`
+	WaitlistCapacity *int       `json:"waitlist_capacity" validate:"omitempty,min=0,max=1000"`
+	Schedule         string     `json:"schedule" validate:"omitempty,max=200,nohtml,nosql"`
+	AddDeadline      *time.Time `json:"add_deadline"`
+	DropDeadline     *time.Time `json:"drop_deadline"`
+	Status           string     `json:"status" validate:"omitempty,oneof=open closed cancelled"`
+}
+
+// CourseOfferingListRequest 开课列表请求结构
+type CourseOfferingListRequest struct {
+	Page      int    `json:"page" form:"page" validate:"omitempty,min=1"`
+	Size      int    `json:"size" form:"size" validate:"omitempty,min=1,max=100"`
+	SubjectID int    `json:"subject_id" form:"subject_id" validate:"omitempty,min=1"`
+	Semester  string `json:"semester" form:"semester" validate:"omitempty,max=20,nohtml,nosql"`
+	TeacherID int    `json:"teacher_id" form:"teacher_id" validate:"omitempty,min=1"`
+	Status    string `json:"status" form:"status" validate:"omitempty,oneof=open closed cancelled"`
+}
+
+// Enrollment 选课记录
+type Enrollment struct {
+	ID          int        `json:"id" db:"id"`
+	OfferingID  int        `json:"offering_id" db:"offering_id"`
+	StudentID   int        `json:"student_id" db:"student_id"`
+	Status      string     `json:"status" db:"status"`
+	RequestedAt time.Time  `json:"requested_at" db:"requested_at"` // 提交选课时间，决定候补顺序
+	EnrolledAt  *time.Time `json:"enrolled_at" db:"enrolled_at"`
+	DroppedAt   *time.Time `json:"dropped_at" db:"dropped_at"`
+	CreatedBy   *int       `json:"created_by,omitempty" db:"created_by"`
+	UpdatedAt   time.Time  `json:"updated_at" db:"updated_at"`
+
+	// 扩展字段（用于关联查询）
+	StudentName      string `json:"student_name,omitempty" db:"-"`
+	StudentCode      string `json:"student_code,omitempty" db:"-"`
+	SubjectID        int    `json:"subject_id,omitempty" db:"-"`
+	SubjectName      string `json:"subject_name,omitempty" db:"-"`
+	Semester         string `json:"semester,omitempty" db:"-"`
+	Section          string `json:"section,omitempty" db:"-"`
+	WaitlistPosition int    `json:"waitlist_position,omitempty" db:"-"` // 候补排位，从 1 开始
+}
+
+// EnrollRequest 选课请求结构
+type EnrollRequest struct {
+	StudentID int `json:"student_id" validate:"required,min=1"`
+}
+
+// EnrollmentListRequest 选课名单请求结构
+type EnrollmentListRequest struct {
+	Status   string `json:"status" form:"status" validate:"omitempty,oneof=enrolled waitlisted dropped"`
+	Semester string `json:"semester" form:"semester" validate:"omitempty,max=20,nohtml,nosql"`
+}
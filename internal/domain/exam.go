@@ -0,0 +1,70 @@
+package domain
+
+import "time"
+
+// ExamTypeNames 考试类型的中文名称
+var ExamTypeNames = map[string]string{
+	ExamTypeQuiz:       "测验",
+	ExamTypeAssignment: "作业",
+	ExamTypeMidterm:    "期中考试",
+	ExamTypeFinal:      "期末考试",
+}
+
+// Exam 考试安排，考试类型与成绩的考试类型一致，日期和时间为学校所在时区的当地时间
+type Exam struct {
+	ID         int       `json:"id" db:"id"`
+	OfferingID int       `json:"offering_id" db:"offering_id"`
+	ExamType   string    `json:"exam_type" db:"exam_type"`
+	ExamDate   time.Time `json:"exam_date" db:"exam_date"`
+	StartTime  string    `json:"start_time" db:"start_time"` // 如 09:00
+	EndTime    string    `json:"end_time" db:"end_time"`     // 如 11:00
+	RoomID     *int      `json:"room_id" db:"room_id"`
+	Location   string    `json:"location,omitempty" db:"location"` // 不在教室考试时的地点说明
+	Note       string    `json:"note,omitempty" db:"note"`
+	CreatedBy  *int      `json:"created_by,omitempty" db:"created_by"`
+	CreatedAt  time.Time `json:"created_at" db:"created_at"`
+	UpdatedAt  time.Time `json:"updated_at" db:"updated_at"`
+
+	// 扩展字段（用于关联查询）
+	Semester    string `json:"semester" db:"-"`
+	SubjectID   int    `json:"subject_id" db:"-"`
+	SubjectCode string `json:"subject_code,omitempty" db:"-"`
+	SubjectName string `json:"subject_name,omitempty" db:"-"`
+	Section     string `json:"section,omitempty" db:"-"`
+	TeacherName string `json:"teacher_name,omitempty" db:"-"`
+	RoomCode    string `json:"room_code,omitempty" db:"-"`
+	RoomName    string `json:"room_name,omitempty" db:"-"`
+}
+
+// CreateExamRequest 创建考试安排请求结构
+type CreateExamRequest struct {
+	OfferingID int       `json:"offering_id" validate:"required,min=1"`
+	ExamType   string    `json:"exam_type" validate:"omitempty,oneof=quiz midterm final"` // 默认 final
+	ExamDate   time.Time `json:"exam_date" validate:"required"`
+	StartTime  string    `json:"start_time" validate:"required,len=5"` // HH:MM
+	EndTime    string    `json:"end_time" validate:"required,len=5"`
+	RoomID     *int      `json:"room_id" validate:"omitempty,min=1"`
+	Location   string    `json:"location" validate:"omitempty,max=100,nohtml,nosql"`
+	Note       string    `json:"note" validate:"omitempty,max=200,nohtml,nosql"`
+}
+
+// UpdateExamRequest 更新考试安排请求结构
+type UpdateExamRequest struct {
+	ExamType  string     `json:"exam_type" validate:"omitempty,oneof=quiz midterm final"`
+	ExamDate  *time.Time `json:"exam_date"`
+	StartTime string     `json:"start_time" validate:"omitempty,len=5"`
+	EndTime   string     `json:"end_time" validate:"omitempty,len=5"`
+	RoomID    *int       `json:"room_id" validate:"omitempty,min=0"` // 传 0 表示不使用教室
+	Location  *string    `json:"location" validate:"omitempty,max=100,nohtml,nosql"`
+	Note      *string    `json:"note" validate:"omitempty,max=200,nohtml,nosql"`
+}
+
+// ExamListRequest 考试安排列表请求结构
+type ExamListRequest struct {
+	Page       int    `json:"page" form:"page" validate:"omitempty,min=1"`
+	Size       int    `json:"size" form:"size" validate:"omitempty,min=1,max=100"`
+	Semester   string `json:"semester" form:"semester" validate:"omitempty,max=20,nohtml,nosql"`
+	OfferingID int    `json:"offering_id" form:"offering_id" validate:"omitempty,min=1"`
+	ExamType   string `json:"exam_type" form:"exam_type" validate:"omitempty,oneof=quiz midterm final"`
+	RoomID     int    `json:"room_id" form:"room_id" validate:"omitempty,min=1"`
+}
@@ -0,0 +1,41 @@
+package domain
+
+import "time"
+
+// 后台导出任务状态
+const (
+	ExportJobPending   = "pending"
+	ExportJobRunning   = "running"
+	ExportJobCompleted = "completed"
+	ExportJobFailed    = "failed"
+)
+
+// 导出表头语言
+const (
+	ExportHeadersKey = "key" // 使用字段名作为表头
+	ExportHeadersZH  = "zh"  // 使用中文列名作为表头
+)
+
+// ExportRequest 导出请求参数，列表筛选条件沿用对应列表接口的查询参数
+type ExportRequest struct {
+	Format  string `form:"format" validate:"omitempty,oneof=csv xlsx ndjson"` // 默认 csv
+	Columns string `form:"columns" validate:"omitempty,max=500"`              // 逗号分隔的字段名，默认全部列
+	Headers string `form:"headers" validate:"omitempty,oneof=key zh"`         // 表头语言，默认 key
+	Async   bool   `form:"async"`                                             // 强制使用后台任务导出
+}
+
+// ExportJob 后台导出任务
+type ExportJob struct {
+	ID          string     `json:"id" example:"5f2b8c0e9a7d4c1b8e3f6a2d1c0b9e8f"`
+	AdminID     int        `json:"-"`
+	Resource    string     `json:"resource" example:"students"`
+	Format      string     `json:"format" example:"xlsx"`
+	Status      string     `json:"status" example:"completed"`
+	Rows        int64      `json:"rows" example:"120000"`
+	Error       string     `json:"error,omitempty"`
+	FileName    string     `json:"file_name" example:"students_20240901_120000.xlsx"`
+	DownloadURL string     `json:"download_url,omitempty" example:"/api/v1/exports/5f2b8c0e9a7d4c1b8e3f6a2d1c0b9e8f/download"`
+	CreatedAt   time.Time  `json:"created_at"`
+	FinishedAt  *time.Time `json:"finished_at,omitempty"`
+	ExpiresAt   time.Time  `json:"expires_at"`
+}
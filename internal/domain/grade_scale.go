@@ -0,0 +1,164 @@
+package domain
+
+import (
+	"math"
+	"sort"
+	"time"
+)
+
+// 绩点计算方式
+const (
+	GradeFormulaBands  = "bands"  // 按分数段取绩点
+	GradeFormulaLinear = "linear" // 绩点 = (成绩-50)/10，不超过满绩点
+	GradeFormulaPKU    = "pku"    // 北大公式：绩点 = 4 - 3×(100-成绩)²/1600
+)
+
+// 绩点制来源
+const (
+	GradeScaleSourceDatabase = "database"
+	GradeScaleSourceConfig   = "config"
+)
+
+// DefaultGradeScaleName 未配置默认绩点制时使用的内置绩点制
+const DefaultGradeScaleName = "standard4"
+
+// GradeScale 绩点制，Bands 按 MinScore 从高到低排列
+type GradeScale struct {
+	ID          int         `json:"id,omitempty" db:"id"`
+	Name        string      `json:"name" db:"name"`
+	DisplayName string      `json:"display_name" db:"display_name"`
+	Description string      `json:"description,omitempty" db:"description"`
+	Formula     string      `json:"formula" db:"formula"`
+	MaxPoint    float64     `json:"max_point" db:"max_point"`
+	IsBuiltin   bool        `json:"is_builtin" db:"is_builtin"`
+	Source      string      `json:"source" db:"-"` // database 或 config
+	Bands       []GradeBand `json:"bands" db:"-"`
+	CreatedAt   *time.Time  `json:"created_at,omitempty" db:"created_at"`
+	UpdatedAt   *time.Time  `json:"updated_at,omitempty" db:"updated_at"`
+}
+
+// GradeBand 等级分数段，成绩不低于 MinScore 时取该段
+type GradeBand struct {
+	MinScore   float64  `json:"min_score" validate:"min=0,max=100"`
+	Letter     string   `json:"letter" validate:"required,max=5,nohtml,nosql"`
+	GradePoint *float64 `json:"grade_point" validate:"omitempty,min=0,max=9.99"` // 为空表示不计入平均绩点
+	Passed     bool     `json:"passed"`
+}
+
+// CreateGradeScaleRequest 创建绩点制请求结构
+type CreateGradeScaleRequest struct {
+	Name        string      `json:"name" validate:"required,min=2,max=30"` // 小写字母、数字和下划线，如 standard4
+	DisplayName string      `json:"display_name" validate:"required,max=50,nohtml,nosql"`
+	Description string      `json:"description" validate:"omitempty,max=200,nohtml,nosql"`
+	Formula     string      `json:"formula" validate:"omitempty,oneof=bands linear pku"`
+	MaxPoint    float64     `json:"max_point" validate:"omitempty,min=1,max=10"`
+	Bands       []GradeBand `json:"bands" validate:"required,min=1,max=20,dive"`
+}
+
+// UpdateGradeScaleRequest 更新绩点制请求结构，Bands 不为空时整体替换分数段
+type UpdateGradeScaleRequest struct {
+	DisplayName string      `json:"display_name" validate:"omitempty,max=50,nohtml,nosql"`
+	Description string      `json:"description" validate:"omitempty,max=200,nohtml,nosql"`
+	Formula     string      `json:"formula" validate:"omitempty,oneof=bands linear pku"`
+	MaxPoint    float64     `json:"max_point" validate:"omitempty,min=1,max=10"`
+	Bands       []GradeBand `json:"bands" validate:"omitempty,max=20,dive"`
+}
+
+// MajorGradeScale 专业使用的绩点制
+type MajorGradeScale struct {
+	Major     string    `json:"major"`
+	ScaleName string    `json:"scale_name"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// SetMajorGradeScaleRequest 设置专业绩点制请求结构
+type SetMajorGradeScaleRequest struct {
+	ScaleName string `json:"scale_name" validate:"required,max=30"`
+}
+
+// GradeResult 成绩按绩点制换算的结果
+type GradeResult struct {
+	Letter     string   `json:"letter"`
+	GradePoint *float64 `json:"grade_point"` // 不计入平均绩点时为空
+	Passed     bool     `json:"passed"`
+}
+
+// StandardGradeScale 内置标准4.0分制，数据库中缺少默认绩点制时使用
+func StandardGradeScale() *GradeScale {
+	point := func(v float64) *float64 { return &v }
+	return &GradeScale{
+		Name:        DefaultGradeScaleName,
+		DisplayName: "标准4.0分制",
+		Formula:     GradeFormulaBands,
+		MaxPoint:    4,
+		IsBuiltin:   true,
+		Bands: []GradeBand{
+			{MinScore: 90, Letter: "A", GradePoint: point(4), Passed: true},
+			{MinScore: 80, Letter: "B", GradePoint: point(3), Passed: true},
+			{MinScore: 70, Letter: "C", GradePoint: point(2), Passed: true},
+			{MinScore: 60, Letter: "D", GradePoint: point(1), Passed: true},
+			{MinScore: 0, Letter: "F", GradePoint: point(0), Passed: false},
+		},
+	}
+}
+
+// SortBands 将分数段按 MinScore 从高到低排列
+func (s *GradeScale) SortBands() {
+	sort.Slice(s.Bands, func(i, j int) bool {
+		return s.Bands[i].MinScore > s.Bands[j].MinScore
+	})
+}
+
+// Grade 将百分制成绩换算为等级和绩点
+func (s *GradeScale) Grade(score float64) GradeResult {
+	var band GradeBand
+	for _, b := range s.Bands {
+		if score >= b.MinScore {
+			band = b
+			break
+		}
+	}
+
+	result := GradeResult{Letter: band.Letter, Passed: band.Passed}
+	var point float64
+	switch s.Formula {
+	case GradeFormulaLinear:
+		if band.Passed {
+			point = math.Min(s.MaxPoint, (score-50)/10)
+		}
+	case GradeFormulaPKU:
+		if band.Passed {
+			point = 4 - 3*(100-score)*(100-score)/1600
+		}
+	default:
+		result.GradePoint = band.GradePoint
+		return result
+	}
+
+	point = math.Round(point*100) / 100
+	result.GradePoint = &point
+	return result
+}
+
+// GPAAccumulator 按学分加权累计平均绩点，没有绩点的课程（如通过/不通过制）不计入
+type GPAAccumulator struct {
+	points  float64
+	credits int
+}
+
+// Add 计入一门课程
+func (a *GPAAccumulator) Add(credits int, point *float64) {
+	if point == nil {
+		return
+	}
+	a.points += *point * float64(credits)
+	a.credits += credits
+}
+
+// GPA 学分加权平均绩点，保留两位小数
+func (a *GPAAccumulator) GPA() float64 {
+	if a.credits == 0 {
+		return 0
+	}
+	return math.Round(a.points/float64(a.credits)*100) / 100
+}
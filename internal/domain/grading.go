@@ -0,0 +1,177 @@
+package domain
+
+import (
+	"math"
+	"time"
+)
+
+// 考试类型
+const (
+	ExamTypeMidterm    = "midterm"
+	ExamTypeFinal      = "final"
+	ExamTypeQuiz       = "quiz"
+	ExamTypeAssignment = "assignment"
+)
+
+// ExamTypes 所有考试类型，按总评成绩中的展示顺序排列
+var ExamTypes = []string{ExamTypeQuiz, ExamTypeAssignment, ExamTypeMidterm, ExamTypeFinal}
+
+// 缺项处理方式
+const (
+	MissingPolicyRenormalize = "renormalize" // 按已录入成绩的权重重新归一计算
+	MissingPolicyZero        = "zero"        // 未录入的成绩按0分计
+)
+
+// 评分方案来源
+const (
+	GradingSchemeSourceOffering = "offering"
+	GradingSchemeSourceSubject  = "subject"
+	GradingSchemeSourceDefault  = "default"
+)
+
+// GradingScheme 评分方案，各考试类型权重为百分比，合计为100
+type GradingScheme struct {
+	ID               int       `json:"id,omitempty" db:"id"`
+	SubjectID        *int      `json:"subject_id,omitempty" db:"subject_id"`
+	OfferingID       *int      `json:"offering_id,omitempty" db:"offering_id"`
+	MidtermWeight    float64   `json:"midterm_weight" db:"midterm_weight"`
+	FinalWeight      float64   `json:"final_weight" db:"final_weight"`
+	QuizWeight       float64   `json:"quiz_weight" db:"quiz_weight"`
+	AssignmentWeight float64   `json:"assignment_weight" db:"assignment_weight"`
+	MissingPolicy    string    `json:"missing_policy" db:"missing_policy"`
+	Source           string    `json:"source" db:"-"` // offering、subject 或 default
+	CreatedAt        time.Time `json:"created_at,omitempty" db:"created_at"`
+	UpdatedAt        time.Time `json:"updated_at,omitempty" db:"updated_at"`
+}
+
+// GradingSchemeRequest 设置评分方案请求结构
+type GradingSchemeRequest struct {
+	MidtermWeight    float64 `json:"midterm_weight" validate:"min=0,max=100"`
+	FinalWeight      float64 `json:"final_weight" validate:"min=0,max=100"`
+	QuizWeight       float64 `json:"quiz_weight" validate:"min=0,max=100"`
+	AssignmentWeight float64 `json:"assignment_weight" validate:"min=0,max=100"`
+	MissingPolicy    string  `json:"missing_policy" validate:"omitempty,oneof=renormalize zero"`
+}
+
+// DefaultGradingScheme 未设置评分方案时使用的默认方案：总评成绩即期末成绩
+func DefaultGradingScheme() *GradingScheme {
+	return &GradingScheme{
+		FinalWeight:   100,
+		MissingPolicy: MissingPolicyRenormalize,
+		Source:        GradingSchemeSourceDefault,
+	}
+}
+
+// Weight 获取考试类型的权重
+func (g *GradingScheme) Weight(examType string) float64 {
+	switch examType {
+	case ExamTypeMidterm:
+		return g.MidtermWeight
+	case ExamTypeFinal:
+		return g.FinalWeight
+	case ExamTypeQuiz:
+		return g.QuizWeight
+	case ExamTypeAssignment:
+		return g.AssignmentWeight
+	}
+	return 0
+}
+
+// TotalWeight 各考试类型权重合计
+func (g *GradingScheme) TotalWeight() float64 {
+	return g.MidtermWeight + g.FinalWeight + g.QuizWeight + g.AssignmentWeight
+}
+
+// Components 列出方案中有权重或已录入成绩的考试类型
+func (g *GradingScheme) Components(scores map[string]float64) []GradeComponent {
+	components := []GradeComponent{}
+	for _, examType := range ExamTypes {
+		weight := g.Weight(examType)
+		score, ok := scores[examType]
+		if weight == 0 && !ok {
+			continue
+		}
+		component := GradeComponent{ExamType: examType, Weight: weight}
+		if ok {
+			value := score
+			component.Score = &value
+		}
+		components = append(components, component)
+	}
+	return components
+}
+
+// Apply 按方案计算总评成绩。缺项时按 MissingPolicy 处理：
+// renormalize 按已录入成绩的权重重新归一，zero 将缺项按0分计；
+// 已录入成绩的权重合计为0时没有总评成绩。
+func (g *GradingScheme) Apply(grade *FinalGrade, scores map[string]float64) {
+	grade.Components = g.Components(scores)
+	grade.CompositeScore = nil
+	grade.WeightCovered = 0
+
+	var weighted float64
+	for _, c := range grade.Components {
+		if c.Score == nil {
+			continue
+		}
+		weighted += c.Weight * *c.Score
+		grade.WeightCovered += c.Weight
+	}
+	grade.IsComplete = grade.WeightCovered >= g.TotalWeight()
+
+	if grade.WeightCovered == 0 {
+		return
+	}
+	divisor := grade.WeightCovered
+	if g.MissingPolicy == MissingPolicyZero {
+		divisor = g.TotalWeight()
+	}
+	composite := math.Round(weighted/divisor*100) / 100
+	grade.CompositeScore = &composite
+}
+
+// GradeComponent 总评成绩的组成部分
+type GradeComponent struct {
+	ExamType string   `json:"exam_type"`
+	Weight   float64  `json:"weight"`
+	Score    *float64 `json:"score"` // 未录入时为空
+}
+
+// FinalGrade 学生某学期某科目的总评成绩
+type FinalGrade struct {
+	ID             int              `json:"id"`
+	StudentID      int              `json:"student_id"`
+	SubjectID      int              `json:"subject_id"`
+	Semester       string           `json:"semester"`
+	OfferingID     int              `json:"offering_id,omitempty"`
+	SchemeID       *int             `json:"scheme_id"` // 为空表示使用默认方案
+	Components     []GradeComponent `json:"components"`
+	CompositeScore *float64         `json:"composite_score"`
+	WeightCovered  float64          `json:"weight_covered"` // 已录入成绩的权重合计
+	IsComplete     bool             `json:"is_complete"`    // 方案中的成绩是否已全部录入
+	ComputedAt     time.Time        `json:"computed_at"`
+
+	// 扩展字段（用于关联查询）
+	StudentName string `json:"student_name,omitempty"`
+	StudentCode string `json:"student_code,omitempty"`
+	SubjectName string `json:"subject_name,omitempty"`
+	SubjectCode string `json:"subject_code,omitempty"`
+	Credits     int    `json:"credits,omitempty"`
+}
+
+// FinalGradeKey 确定一条总评成绩的学生、科目和学期
+type FinalGradeKey struct {
+	StudentID int
+	SubjectID int
+	Semester  string
+}
+
+// FinalGradeListRequest 总评成绩查询条件
+type FinalGradeListRequest struct {
+	Semester string `json:"semester" form:"semester" validate:"omitempty,max=20,nohtml,nosql"`
+}
+
+// RecomputeResult 总评成绩重新计算结果
+type RecomputeResult struct {
+	Recomputed int `json:"recomputed"`
+}
@@ -0,0 +1,121 @@
+package domain
+
+import "time"
+
+// 请假类型
+const (
+	LeaveTypePersonal = "personal" // 事假
+	LeaveTypeSick     = "sick"     // 病假
+	LeaveTypeOther    = "other"    // 其他
+)
+
+// 请假申请状态
+const (
+	LeaveStatusPending   = "pending"   // 审批中
+	LeaveStatusApproved  = "approved"  // 已批准
+	LeaveStatusRejected  = "rejected"  // 已驳回
+	LeaveStatusCancelled = "cancelled" // 已撤销
+)
+
+// 请假审批人
+const (
+	LeaveApproverHomeroom = "homeroom" // 学生所在班级的班主任
+	LeaveApproverAdmin    = "admin"    // 管理员
+)
+
+// 请假审批意见
+const (
+	LeaveDecisionApproved = "approved"
+	LeaveDecisionRejected = "rejected"
+)
+
+// LeaveRequest 请假申请。请假天数含首尾两天，审批链在提交时按天数确定，current_step 为当前待审批的步骤
+type LeaveRequest struct {
+	ID              int        `json:"id"`
+	StudentID       int        `json:"student_id"`
+	LeaveType       string     `json:"leave_type"`
+	StartDate       time.Time  `json:"start_date"`
+	EndDate         time.Time  `json:"end_date"`
+	Days            int        `json:"days"`
+	Reason          string     `json:"reason"`
+	Attachments     []string   `json:"attachments"`
+	Status          string     `json:"status"`
+	ApprovalChain   []string   `json:"approval_chain"`
+	CurrentStep     int        `json:"current_step"`
+	SubmittedBy     *int       `json:"submitted_by"`
+	ExcusedSessions int        `json:"excused_sessions"` // 批准时改为请假的考勤课次数
+	CreatedAt       time.Time  `json:"created_at"`
+	UpdatedAt       time.Time  `json:"updated_at"`
+	DecidedAt       *time.Time `json:"decided_at"`
+
+	// 扩展字段（用于关联查询）
+	StudentNo         string           `json:"student_no,omitempty"` // 学号
+	StudentName       string           `json:"student_name,omitempty"`
+	ClassID           *int             `json:"class_id"` // 学生当前所在班级
+	ClassName         string           `json:"class_name,omitempty"`
+	HomeroomTeacherID *int             `json:"homeroom_teacher_id"`
+	Approvals         []*LeaveApproval `json:"approvals,omitempty"`
+}
+
+// PendingApprover 当前待审批步骤的审批人，申请已结束时返回空
+func (l *LeaveRequest) PendingApprover() string {
+	if l.Status != LeaveStatusPending || l.CurrentStep >= len(l.ApprovalChain) {
+		return ""
+	}
+	return l.ApprovalChain[l.CurrentStep]
+}
+
+// AttendanceStatus 请假对应的出勤状态：病假记为病假，其余记为事假
+func (l *LeaveRequest) AttendanceStatus() string {
+	if l.LeaveType == LeaveTypeSick {
+		return AttendanceSick
+	}
+	return AttendanceExcused
+}
+
+// LeaveApproval 请假审批记录
+type LeaveApproval struct {
+	ID        int       `json:"id"`
+	LeaveID   int       `json:"leave_id"`
+	Step      int       `json:"step"`
+	Approver  string    `json:"approver"` // 该步骤的审批人：homeroom 或 admin
+	Decision  string    `json:"decision"`
+	Comment   string    `json:"comment,omitempty"`
+	ActorID   *int      `json:"actor_id"`
+	ActorName string    `json:"actor_name,omitempty"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// CreateLeaveRequest 提交请假申请请求结构，附件为已上传文件（如病历、假条）的地址。
+// 学生只关联一名学生时可不填 student_id
+type CreateLeaveRequest struct {
+	StudentID   int       `json:"student_id" validate:"omitempty,min=1"`
+	LeaveType   string    `json:"leave_type" validate:"required,oneof=personal sick other"`
+	StartDate   time.Time `json:"start_date" validate:"required"`
+	EndDate     time.Time `json:"end_date" validate:"required"`
+	Reason      string    `json:"reason" validate:"required,min=2,max=1000,nohtml,nosql"`
+	Attachments []string  `json:"attachments" validate:"omitempty,max=5,dive,url,max=500"`
+}
+
+// LeaveDecisionRequest 请假审批请求结构，驳回时须填写原因
+type LeaveDecisionRequest struct {
+	Comment string `json:"comment" validate:"omitempty,max=500,nohtml,nosql"`
+}
+
+// LeaveListRequest 请假申请列表请求结构
+type LeaveListRequest struct {
+	Page      int        `json:"page" form:"page" validate:"omitempty,min=1"`
+	Size      int        `json:"size" form:"size" validate:"omitempty,min=1,max=100"`
+	Status    string     `json:"status" form:"status" validate:"omitempty,oneof=pending approved rejected cancelled"`
+	LeaveType string     `json:"leave_type" form:"leave_type" validate:"omitempty,oneof=personal sick other"`
+	StudentID int        `json:"student_id" form:"student_id" validate:"omitempty,min=1"`
+	ClassID   int        `json:"class_id" form:"class_id" validate:"omitempty,min=1"`
+	DateFrom  *time.Time `json:"date_from" form:"date_from" time_format:"2006-01-02"` // 与该日期范围有交集的请假
+	DateTo    *time.Time `json:"date_to" form:"date_to" time_format:"2006-01-02"`
+
+	// 由服务层根据登录身份设置：班主任只能查看本班学生的请假，学生和家长只能查看本人（子女）的请假
+	HomeroomTeacherID int    `json:"-" form:"-"`
+	StudentIDs        []int  `json:"-" form:"-"`
+	PendingApprover   string `json:"-" form:"-"` // 只查询当前待该审批人审批的申请
+	Queue             bool   `json:"-" form:"-"` // 待审批队列，按提交时间先后排列
+}
@@ -0,0 +1,129 @@
+package domain
+
+import (
+	"math"
+	"sort"
+	"time"
+)
+
+// 排名依据
+const (
+	RankingBySubject   = "subject"   // 单科课程成绩
+	RankingByComposite = "composite" // 各科课程成绩的平均分
+	RankingByGPA       = "gpa"       // 学期学分加权平均绩点
+)
+
+// 排名范围
+const (
+	RankingScopeClass = "class" // 班级
+	RankingScopeMajor = "major" // 专业，可再按年级限定
+	RankingScopeGrade = "grade" // 整个年级
+)
+
+// 名次计算方式
+const (
+	RankingMethodStandard = "standard" // 标准竞争排名，同分同名次，后续名次跳过（1224）
+	RankingMethodDense    = "dense"    // 密集排名，同分同名次，后续名次连续（1223）
+)
+
+// RankingRequest 成绩排名请求结构
+type RankingRequest struct {
+	Semester  string `json:"semester" form:"semester" validate:"required,max=20,nohtml,nosql"`
+	By        string `json:"by" form:"by" validate:"required,oneof=subject composite gpa"`
+	Scope     string `json:"scope" form:"scope" validate:"required,oneof=class major grade"`
+	ClassID   int    `json:"class_id" form:"class_id" validate:"omitempty,min=1"`                 // scope=class 时必填
+	Major     string `json:"major" form:"major" validate:"omitempty,max=50,nohtml,nosql"`         // scope=major 时必填
+	GradeYear int    `json:"grade_year" form:"grade_year" validate:"omitempty,min=1900,max=2100"` // scope=grade 时必填，scope=major 时可选
+	SubjectID int    `json:"subject_id" form:"subject_id" validate:"omitempty,min=1"`             // by=subject 时必填
+	Method    string `json:"method" form:"method" validate:"omitempty,oneof=standard dense"`
+	Scale     string `json:"scale" form:"scale" validate:"omitempty,max=30"`          // by=gpa 时使用的绩点制，默认按各学生专业设置
+	StudentID int    `json:"student_id" form:"student_id" validate:"omitempty,min=1"` // 只返回该学生的名次
+}
+
+// RankingEntry 排名中的一名学生
+type RankingEntry struct {
+	Rank        int      `json:"rank"`
+	Percentile  float64  `json:"percentile"` // 百分位：成绩低于该学生的人数加上同分人数的一半，占总人数的百分比
+	StudentID   int      `json:"student_id"`
+	StudentNo   string   `json:"student_no"`
+	StudentName string   `json:"student_name"`
+	Major       string   `json:"major"`
+	ClassID     *int     `json:"class_id"`
+	ClassName   string   `json:"class_name,omitempty"`
+	Value       float64  `json:"value"`           // 参与排名的数值：课程成绩、平均分或平均绩点
+	Total       *float64 `json:"total,omitempty"` // 各科总分，仅 by=composite
+	Courses     int      `json:"courses"`         // 计入的课程数
+	Credits     int      `json:"credits,omitempty"`
+	GradeScale  string   `json:"grade_scale,omitempty"` // 计算绩点使用的绩点制，仅 by=gpa
+}
+
+// RankingResult 成绩排名结果
+type RankingResult struct {
+	Semester    string         `json:"semester"`
+	By          string         `json:"by"`
+	Scope       string         `json:"scope"`
+	ScopeName   string         `json:"scope_name"`
+	Method      string         `json:"method"`
+	SubjectID   int            `json:"subject_id,omitempty"`
+	SubjectName string         `json:"subject_name,omitempty"`
+	Count       int            `json:"count"`     // 范围内参与排名的学生数
+	SelfOnly    bool           `json:"self_only"` // 为 true 时只返回指定学生的名次
+	Entries     []RankingEntry `json:"entries"`
+}
+
+// RankingFilter 排名范围的查询条件，由服务层根据请求生成
+type RankingFilter struct {
+	Semester      string
+	ReferenceDate time.Time // 按该日期所在班级确定学生的班级和年级
+	ClassID       int
+	Major         string
+	GradeYear     int
+	SubjectID     int
+}
+
+// RankingCourseScore 范围内学生的一门课程成绩，取总评成绩，没有总评成绩时取期末成绩
+type RankingCourseScore struct {
+	StudentID   int
+	StudentNo   string
+	StudentName string
+	Major       string
+	ClassID     *int
+	ClassName   string
+	SubjectID   int
+	SubjectName string
+	Credits     int
+	Score       float64
+}
+
+// AssignRanks 按 Value 从高到低排序并计算名次和百分位，同分时按学号排列
+func AssignRanks(entries []RankingEntry, method string) {
+	sort.SliceStable(entries, func(i, j int) bool {
+		if entries[i].Value != entries[j].Value {
+			return entries[i].Value > entries[j].Value
+		}
+		return entries[i].StudentNo < entries[j].StudentNo
+	})
+
+	n := len(entries)
+	rank := 0
+	for i := 0; i < n; {
+		// [i, j) 为同分的一组
+		j := i
+		for j < n && entries[j].Value == entries[i].Value {
+			j++
+		}
+
+		if method == RankingMethodDense {
+			rank++
+		} else {
+			rank = i + 1
+		}
+		below := n - j
+		percentile := math.Round((float64(below)+float64(j-i)/2)/float64(n)*10000) / 100
+		for k := i; k < j; k++ {
+			entries[k].Rank = rank
+			entries[k].Percentile = percentile
+		}
+		i = j
+	}
+}
@@ -0,0 +1,65 @@
+package domain
+
+// 内置角色
+const (
+	RoleAdmin   = "admin"
+	RoleTeacher = "teacher"
+	RoleStudent = "student"
+	RoleParent  = "parent"
+)
+
+// 权限代码，格式为 <资源>:<操作>
+const (
+	PermStudentsRead      = "students:read"
+	PermStudentsWrite     = "students:write"
+	PermTeachersRead      = "teachers:read"
+	PermTeachersWrite     = "teachers:write"
+	PermSubjectsRead      = "subjects:read"
+	PermSubjectsWrite     = "subjects:write"
+	PermScoresRead        = "scores:read"
+	PermScoresWrite       = "scores:write"
+	PermScoresApprove     = "scores:approve"
+	PermStatisticsRead    = "statistics:read"
+	PermAdminsRead        = "admins:read"
+	PermAdminsWrite       = "admins:write"
+	PermClassesRead       = "classes:read"
+	PermClassesWrite      = "classes:write"
+	PermOfferingsRead     = "offerings:read"
+	PermOfferingsWrite    = "offerings:write"
+	PermEnrollmentsWrite  = "enrollments:write"
+	PermTermsRead         = "terms:read"
+	PermTermsWrite        = "terms:write"
+	PermGradeScalesRead   = "grade_scales:read"
+	PermGradeScalesWrite  = "grade_scales:write"
+	PermAppealsSubmit     = "appeals:submit"
+	PermAppealsReview     = "appeals:review"
+	PermRankingsRead      = "rankings:read"
+	PermTimetableRead     = "timetable:read"
+	PermTimetableWrite    = "timetable:write"
+	PermExamsWrite        = "exams:write"
+	PermCalendarSubscribe = "calendar:subscribe"
+	PermAttendanceRead    = "attendance:read"
+	PermAttendanceWrite   = "attendance:write"
+	PermLeavesSubmit      = "leaves:submit"
+	PermLeavesApprove     = "leaves:approve"
+)
+
+// Role 角色模型
+type Role struct {
+	ID          int      `json:"id" db:"id"`
+	Name        string   `json:"name" db:"name"`
+	Description string   `json:"description" db:"description"`
+	Permissions []string `json:"permissions" db:"-"`
+}
+
+// Permission 权限模型
+type Permission struct {
+	ID          int    `json:"id" db:"id"`
+	Code        string `json:"code" db:"code"`
+	Description string `json:"description" db:"description"`
+}
+
+// UpdateRolePermissionsRequest 更新角色权限请求结构
+type UpdateRolePermissionsRequest struct {
+	Permissions []string `json:"permissions" validate:"required,dive,min=3,max=50"`
+}
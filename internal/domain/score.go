@@ -6,16 +6,18 @@ import (
 
 // Score 成绩数据模型
 type Score struct {
-	ID        int       `json:"id" db:"id"`
-	StudentID int       `json:"student_id" db:"student_id" validate:"required,min=1"`
-	SubjectID int       `json:"subject_id" db:"subject_id" validate:"required,min=1"`
-	TeacherID int       `json:"teacher_id" db:"teacher_id" validate:"required,min=1"`
-	Score     float64   `json:"score" db:"score" validate:"required,min=0,max=100"`
-	Semester  string    `json:"semester" db:"semester" validate:"required,min=5,max=20,nohtml,nosql"`
-	ExamType  string    `json:"exam_type" db:"exam_type" validate:"required,oneof=midterm final quiz assignment"`
-	Remarks   string    `json:"remarks" db:"remarks" validate:"omitempty,max=200,nohtml,nosql"`
-	CreatedAt time.Time `json:"created_at" db:"created_at"`
-	UpdatedAt time.Time `json:"updated_at" db:"updated_at"`
+	ID         int       `json:"id" db:"id"`
+	StudentID  int       `json:"student_id" db:"student_id" validate:"required,min=1"`
+	SubjectID  int       `json:"subject_id" db:"subject_id" validate:"required,min=1"`
+	TeacherID  int       `json:"teacher_id" db:"teacher_id" validate:"required,min=1"`
+	OfferingID int       `json:"offering_id" db:"offering_id"` // 所属开课
+	Score      float64   `json:"score" db:"score" validate:"required,min=0,max=100"`
+	Semester   string    `json:"semester" db:"semester" validate:"required,min=5,max=20,nohtml,nosql"`
+	ExamType   string    `json:"exam_type" db:"exam_type" validate:"required,oneof=midterm final quiz assignment"`
+	Remarks    string    `json:"remarks" db:"remarks" validate:"omitempty,max=200,nohtml,nosql"`
+	Status     string    `json:"status" db:"status"` // draft、submitted、approved、published 或 locked
+	CreatedAt  time.Time `json:"created_at" db:"created_at"`
+	UpdatedAt  time.Time `json:"updated_at" db:"updated_at"`
 
 	// 关联数据
 	Student *Student `json:"student,omitempty" db:"-"`
@@ -37,6 +39,8 @@ type CreateScoreRequest struct {
 	Semester  string  `json:"semester" validate:"required,min=5,max=20,nohtml,nosql"`
 	ExamType  string  `json:"exam_type" validate:"required,oneof=midterm final quiz assignment"`
 	Remarks   string  `json:"remarks" validate:"omitempty,max=200,nohtml,nosql"`
+	// 所属开课，不传时按学生在该科目该学期的选课记录确定
+	OfferingID int `json:"offering_id" validate:"omitempty,min=1"`
 }
 
 // UpdateScoreRequest 更新成绩请求结构
@@ -45,19 +49,28 @@ type UpdateScoreRequest struct {
 	Semester string  `json:"semester" validate:"omitempty,min=5,max=20,nohtml,nosql"`
 	ExamType string  `json:"exam_type" validate:"omitempty,oneof=midterm final quiz assignment"`
 	Remarks  string  `json:"remarks" validate:"omitempty,max=200,nohtml,nosql"`
+	Reason   string  `json:"reason" validate:"omitempty,max=500,nohtml,nosql"` // 修改原因，记入成绩变更记录
 }
 
 // ScoreListRequest 成绩列表请求结构
 type ScoreListRequest struct {
-	Page      int     `json:"page" form:"page" validate:"omitempty,min=1"`
-	Size      int     `json:"size" form:"size" validate:"omitempty,min=1,max=100"`
-	StudentID int     `json:"student_id" form:"student_id" validate:"omitempty,min=1"`
-	SubjectID int     `json:"subject_id" form:"subject_id" validate:"omitempty,min=1"`
-	TeacherID int     `json:"teacher_id" form:"teacher_id" validate:"omitempty,min=1"`
-	Semester  string  `json:"semester" form:"semester" validate:"omitempty,max=20,nohtml,nosql"`
-	ExamType  string  `json:"exam_type" form:"exam_type" validate:"omitempty,oneof=midterm final quiz assignment"`
-	MinScore  float64 `json:"min_score" form:"min_score" validate:"omitempty,min=0,max=100"`
-	MaxScore  float64 `json:"max_score" form:"max_score" validate:"omitempty,min=0,max=100"`
+	Page       int     `json:"page" form:"page" validate:"omitempty,min=1"`
+	Size       int     `json:"size" form:"size" validate:"omitempty,min=1,max=100"`
+	StudentID  int     `json:"student_id" form:"student_id" validate:"omitempty,min=1"`
+	SubjectID  int     `json:"subject_id" form:"subject_id" validate:"omitempty,min=1"`
+	TeacherID  int     `json:"teacher_id" form:"teacher_id" validate:"omitempty,min=1"`
+	OfferingID int     `json:"offering_id" form:"offering_id" validate:"omitempty,min=1"`
+	Semester   string  `json:"semester" form:"semester" validate:"omitempty,max=20,nohtml,nosql"`
+	ExamType   string  `json:"exam_type" form:"exam_type" validate:"omitempty,oneof=midterm final quiz assignment"`
+	MinScore   float64 `json:"min_score" form:"min_score" validate:"omitempty,min=0,max=100"`
+	MaxScore   float64 `json:"max_score" form:"max_score" validate:"omitempty,min=0,max=100"`
+	ClassID    int     `json:"class_id" form:"class_id" validate:"omitempty,min=1"`                 // 学生当前所在班级
+	GradeYear  int     `json:"grade_year" form:"grade_year" validate:"omitempty,min=1900,max=2100"` // 学生当前班级所属年级
+	Status     string  `json:"status" form:"status" validate:"omitempty,oneof=draft submitted approved published locked"`
+
+	// 学生/家长账号仅能查询的学生范围和已发布的成绩，由服务层根据登录身份设置
+	StudentIDs []int    `json:"-" form:"-"`
+	Statuses   []string `json:"-" form:"-"`
 }
 
 // ScoreListResponse 成绩列表响应结构
@@ -81,7 +94,11 @@ type StudentScoreReport struct {
 	Scores       []SubjectScoreDetail `json:"scores"`
 	TotalScore   float64              `json:"total_score"`
 	AverageScore float64              `json:"average_score"`
-	GPA          float64              `json:"gpa"`
+
+	// 按绩点制计算的学分加权平均绩点：GPA 为本学期，CumulativeGPA 为截至本学期的全部总评成绩
+	GradeScale    string  `json:"grade_scale"`
+	GPA           float64 `json:"gpa"`
+	CumulativeGPA float64 `json:"cumulative_gpa"`
 }
 
 // SubjectScoreDetail 科目成绩详情
@@ -92,10 +109,22 @@ type SubjectScoreDetail struct {
 	Credits     int     `json:"credits"`
 	Score       float64 `json:"score"`
 	ExamType    string  `json:"exam_type"`
+
+	// 总评成绩及其组成，按科目或开课的评分方案加权计算
+	Components     []GradeComponent `json:"components"`
+	CompositeScore *float64         `json:"composite_score"`
+
+	// 按绩点制换算的等级和绩点，有总评成绩时按总评成绩换算
+	Letter     string   `json:"letter"`
+	GradePoint *float64 `json:"grade_point"`
+	Passed     bool     `json:"passed"`
 }
 
-// ClassScoreStatistics 班级成绩统计
+// ClassScoreStatistics 班级成绩统计，按学生当前所在班级分组，未分班的学生归入 class_id 为 0 的一组
 type ClassScoreStatistics struct {
+	ClassID       int     `json:"class_id"`
+	ClassName     string  `json:"class_name"`
+	GradeYear     int     `json:"grade_year,omitempty"`
 	SubjectID     int     `json:"subject_id"`
 	SubjectName   string  `json:"subject_name"`
 	Semester      string  `json:"semester"`
@@ -111,6 +140,9 @@ type ClassScoreStatistics struct {
 // SubjectScoreStatistics 科目成绩统计
 type SubjectScoreStatistics struct {
 	SubjectID      int     `json:"subject_id"`
+	SubjectName    string  `json:"subject_name"`
+	Semester       string  `json:"semester,omitempty"`
+	ExamType       string  `json:"exam_type,omitempty"`
 	TotalCount     int     `json:"total_count"`
 	AverageScore   float64 `json:"average_score"`
 	MinScore       float64 `json:"min_score"`
@@ -121,3 +153,23 @@ type SubjectScoreStatistics struct {
 	PassCount      int     `json:"pass_count"`      // 60-69分
 	FailCount      int     `json:"fail_count"`      // <60分
 }
+
+// StudentScoreReportRequest 学生成绩报告请求结构
+type StudentScoreReportRequest struct {
+	Semester string `json:"semester" form:"semester" validate:"required,min=5,max=20,nohtml,nosql"`
+	ExamType string `json:"exam_type" form:"exam_type" validate:"omitempty,oneof=midterm final quiz assignment"`
+	Scale    string `json:"scale" form:"scale" validate:"omitempty,max=30"` // 绩点制名称，默认按学生专业设置
+
+	// 学生/家长账号只能看到已发布的成绩，由服务层根据登录身份设置
+	PublishedOnly bool `json:"-" form:"-"`
+}
+
+// ScoreStatisticsRequest 成绩统计请求结构
+type ScoreStatisticsRequest struct {
+	SubjectID int    `json:"subject_id" form:"subject_id" validate:"omitempty,min=1"`
+	Semester  string `json:"semester" form:"semester" validate:"omitempty,max=20,nohtml,nosql"`
+	ExamType  string `json:"exam_type" form:"exam_type" validate:"omitempty,oneof=midterm final quiz assignment"`
+	Major     string `json:"major" form:"major" validate:"omitempty,max=50,nohtml,nosql"`
+	ClassID   int    `json:"class_id" form:"class_id" validate:"omitempty,min=1"`
+	GradeYear int    `json:"grade_year" form:"grade_year" validate:"omitempty,min=1900,max=2100"`
+}
@@ -0,0 +1,74 @@
+package domain
+
+import "time"
+
+// 成绩复核状态
+const (
+	AppealStatusSubmitted   = "submitted"    // 已提交
+	AppealStatusUnderReview = "under_review" // 复核中
+	AppealStatusUpheld      = "upheld"       // 维持原成绩
+	AppealStatusAdjusted    = "adjusted"     // 已调整成绩
+)
+
+// OpenAppealStatuses 未结案的复核状态
+var OpenAppealStatuses = []string{AppealStatusSubmitted, AppealStatusUnderReview}
+
+// ScoreAppeal 成绩复核申请
+type ScoreAppeal struct {
+	ID                int        `json:"id"`
+	ScoreID           int        `json:"score_id"`
+	StudentID         int        `json:"student_id"`
+	Reason            string     `json:"reason"`
+	Attachments       []string   `json:"attachments"`
+	Status            string     `json:"status"`
+	OriginalScore     float64    `json:"original_score"`
+	AdjustedScore     *float64   `json:"adjusted_score"`
+	AssignedTeacherID *int       `json:"assigned_teacher_id"` // 负责复核的教师，为空时只能由管理员复核
+	SubmittedBy       *int       `json:"submitted_by"`
+	ReviewerID        *int       `json:"reviewer_id"`
+	Resolution        string     `json:"resolution,omitempty"`
+	CreatedAt         time.Time  `json:"created_at"`
+	UpdatedAt         time.Time  `json:"updated_at"`
+	ResolvedAt        *time.Time `json:"resolved_at"`
+
+	// 扩展字段（用于关联查询）
+	StudentName  string `json:"student_name,omitempty"`
+	SubjectID    int    `json:"subject_id"`
+	SubjectName  string `json:"subject_name,omitempty"`
+	Semester     string `json:"semester"`
+	ExamType     string `json:"exam_type"`
+	TeacherName  string `json:"teacher_name,omitempty"`
+	ReviewerName string `json:"reviewer_name,omitempty"`
+}
+
+// IsOpen 复核申请是否尚未结案
+func (a *ScoreAppeal) IsOpen() bool {
+	return a.Status == AppealStatusSubmitted || a.Status == AppealStatusUnderReview
+}
+
+// CreateScoreAppealRequest 提交成绩复核申请请求结构，附件为已上传文件的地址
+type CreateScoreAppealRequest struct {
+	Reason      string   `json:"reason" validate:"required,min=5,max=1000,nohtml,nosql"`
+	Attachments []string `json:"attachments" validate:"omitempty,max=5,dive,url,max=500"`
+}
+
+// ResolveScoreAppealRequest 复核结论请求结构：upheld 维持原成绩，adjusted 调整为 adjusted_score
+type ResolveScoreAppealRequest struct {
+	Result        string   `json:"result" validate:"required,oneof=upheld adjusted"`
+	AdjustedScore *float64 `json:"adjusted_score" validate:"omitempty,min=0,max=100"`
+	Resolution    string   `json:"resolution" validate:"required,min=2,max=1000,nohtml,nosql"`
+}
+
+// ScoreAppealListRequest 成绩复核申请列表请求结构
+type ScoreAppealListRequest struct {
+	Page      int    `json:"page" form:"page" validate:"omitempty,min=1"`
+	Size      int    `json:"size" form:"size" validate:"omitempty,min=1,max=100"`
+	Status    string `json:"status" form:"status" validate:"omitempty,oneof=submitted under_review upheld adjusted"`
+	StudentID int    `json:"student_id" form:"student_id" validate:"omitempty,min=1"`
+	SubjectID int    `json:"subject_id" form:"subject_id" validate:"omitempty,min=1"`
+
+	// 由服务层根据登录身份设置：教师只能查看分配给本人的申请，学生和家长只能查看本人（子女）的申请
+	AssignedTeacherID int      `json:"-" form:"-"`
+	StudentIDs        []int    `json:"-" form:"-"`
+	Statuses          []string `json:"-" form:"-"`
+}
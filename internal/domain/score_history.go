@@ -0,0 +1,53 @@
+package domain
+
+import "time"
+
+// 成绩变更类型
+const (
+	ScoreHistoryCreate      = "create"       // 录入
+	ScoreHistoryUpdate      = "update"       // 修改
+	ScoreHistoryDelete      = "delete"       // 删除
+	ScoreHistoryGradeChange = "grade_change" // 成绩更正申请审批通过
+	ScoreHistoryAppeal      = "appeal"       // 成绩复核调整
+)
+
+// ScoreHistory 成绩变更记录，只能追加，不能修改或删除
+type ScoreHistory struct {
+	ID        int       `json:"id"`
+	ScoreID   int       `json:"score_id"`
+	StudentID int       `json:"student_id"`
+	SubjectID int       `json:"subject_id"`
+	Semester  string    `json:"semester"`
+	ExamType  string    `json:"exam_type"`
+	Action    string    `json:"action"`
+	OldScore  *float64  `json:"old_score"` // 录入时为空
+	NewScore  *float64  `json:"new_score"` // 删除时为空
+	Details   string    `json:"details,omitempty"`
+	Reason    string    `json:"reason,omitempty"`
+	ActorID   *int      `json:"actor_id"`
+	ActorName string    `json:"actor_name,omitempty"`
+	IP        string    `json:"ip"`
+	CreatedAt time.Time `json:"created_at"`
+
+	// 扩展字段（用于关联查询）
+	StudentName string `json:"student_name,omitempty"`
+	SubjectName string `json:"subject_name,omitempty"`
+}
+
+// ScoreChangeMeta 变更成绩时记录的操作信息
+type ScoreChangeMeta struct {
+	IP     string
+	Reason string
+}
+
+// ScoreHistoryListRequest 成绩变更记录列表请求结构
+type ScoreHistoryListRequest struct {
+	Page      int    `json:"page" form:"page" validate:"omitempty,min=1"`
+	Size      int    `json:"size" form:"size" validate:"omitempty,min=1,max=100"`
+	SubjectID int    `json:"subject_id" form:"subject_id" validate:"omitempty,min=1"`
+	Semester  string `json:"semester" form:"semester" validate:"omitempty,max=20,nohtml,nosql"`
+
+	// 由服务层根据路径参数设置
+	ScoreID   int `json:"-" form:"-"`
+	StudentID int `json:"-" form:"-"`
+}
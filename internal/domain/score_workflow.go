@@ -0,0 +1,132 @@
+package domain
+
+import "time"
+
+// 成绩状态
+const (
+	ScoreStatusDraft     = "draft"     // 草稿，录入教师可以修改
+	ScoreStatusSubmitted = "submitted" // 已提交，等待审核
+	ScoreStatusApproved  = "approved"  // 已审核，等待发布
+	ScoreStatusPublished = "published" // 已发布，学生可见
+	ScoreStatusLocked    = "locked"    // 已锁定，只能通过成绩更正申请修改
+)
+
+// PublishedScoreStatuses 学生和家长可见的成绩状态
+var PublishedScoreStatuses = []string{ScoreStatusPublished, ScoreStatusLocked}
+
+// 开课成绩流转操作
+const (
+	ScoreActionSubmit  = "submit"  // 教师提交：草稿 -> 已提交
+	ScoreActionApprove = "approve" // 审核通过：已提交 -> 已审核
+	ScoreActionReject  = "reject"  // 退回：已提交、已审核 -> 草稿
+	ScoreActionPublish = "publish" // 发布：已审核 -> 已发布
+	ScoreActionLock    = "lock"    // 锁定：已发布 -> 已锁定
+)
+
+// ScoreTransition 开课成绩流转操作对应的状态变化
+type ScoreTransition struct {
+	From []string
+	To   string
+}
+
+// ScoreTransitions 各流转操作允许的起始状态和目标状态
+var ScoreTransitions = map[string]ScoreTransition{
+	ScoreActionSubmit:  {From: []string{ScoreStatusDraft}, To: ScoreStatusSubmitted},
+	ScoreActionApprove: {From: []string{ScoreStatusSubmitted}, To: ScoreStatusApproved},
+	ScoreActionReject:  {From: []string{ScoreStatusSubmitted, ScoreStatusApproved}, To: ScoreStatusDraft},
+	ScoreActionPublish: {From: []string{ScoreStatusApproved}, To: ScoreStatusPublished},
+	ScoreActionLock:    {From: []string{ScoreStatusPublished}, To: ScoreStatusLocked},
+}
+
+// IsPublishedScoreStatus 成绩状态是否对学生和家长可见
+func IsPublishedScoreStatus(status string) bool {
+	return status == ScoreStatusPublished || status == ScoreStatusLocked
+}
+
+// ScoreWorkflowRequest 开课成绩流转请求结构，退回时须填写原因
+type ScoreWorkflowRequest struct {
+	Comment string `json:"comment" validate:"omitempty,max=500,nohtml,nosql"`
+}
+
+// ScoreWorkflowResult 开课成绩流转结果
+type ScoreWorkflowResult struct {
+	OfferingID int    `json:"offering_id"`
+	Action     string `json:"action"`
+	Status     string `json:"status"`   // 流转后的状态
+	Affected   int    `json:"affected"` // 变更状态的成绩数量
+}
+
+// ScoreWorkflowEvent 开课成绩流转记录
+type ScoreWorkflowEvent struct {
+	ID         int       `json:"id"`
+	OfferingID int       `json:"offering_id"`
+	Action     string    `json:"action"`
+	FromStatus string    `json:"from_status"`
+	ToStatus   string    `json:"to_status"`
+	Affected   int       `json:"affected"`
+	Comment    string    `json:"comment,omitempty"`
+	ActorID    *int      `json:"actor_id"`
+	ActorName  string    `json:"actor_name,omitempty"`
+	CreatedAt  time.Time `json:"created_at"`
+}
+
+// OfferingScoreStatus 开课成绩的状态汇总及流转记录
+type OfferingScoreStatus struct {
+	OfferingID int                  `json:"offering_id"`
+	Counts     map[string]int       `json:"counts"` // 各状态的成绩数量
+	Total      int                  `json:"total"`
+	Events     []ScoreWorkflowEvent `json:"events"`
+}
+
+// 成绩更正申请状态
+const (
+	GradeChangeStatusPending  = "pending"
+	GradeChangeStatusApproved = "approved"
+	GradeChangeStatusRejected = "rejected"
+)
+
+// GradeChangeRequest 成绩更正申请，已发布或已锁定的成绩须经审批后才能修改
+type GradeChangeRequest struct {
+	ID            int        `json:"id"`
+	ScoreID       int        `json:"score_id"`
+	OldScore      float64    `json:"old_score"`
+	NewScore      float64    `json:"new_score"`
+	Reason        string     `json:"reason"`
+	Status        string     `json:"status"`
+	RequestedBy   *int       `json:"requested_by"`
+	ReviewedBy    *int       `json:"reviewed_by"`
+	ReviewComment string     `json:"review_comment,omitempty"`
+	CreatedAt     time.Time  `json:"created_at"`
+	ReviewedAt    *time.Time `json:"reviewed_at"`
+
+	// 扩展字段（用于关联查询）
+	StudentID   int    `json:"student_id"`
+	StudentName string `json:"student_name,omitempty"`
+	SubjectID   int    `json:"subject_id"`
+	SubjectName string `json:"subject_name,omitempty"`
+	OfferingID  int    `json:"offering_id,omitempty"`
+	Semester    string `json:"semester"`
+	ExamType    string `json:"exam_type"`
+}
+
+// CreateGradeChangeRequest 提交成绩更正申请请求结构
+type CreateGradeChangeRequest struct {
+	Score  float64 `json:"score" validate:"min=0,max=100"`
+	Reason string  `json:"reason" validate:"required,min=2,max=500,nohtml,nosql"`
+}
+
+// ReviewGradeChangeRequest 审批成绩更正申请请求结构，驳回时须填写意见
+type ReviewGradeChangeRequest struct {
+	Comment string `json:"comment" validate:"omitempty,max=500,nohtml,nosql"`
+}
+
+// GradeChangeListRequest 成绩更正申请列表请求结构
+type GradeChangeListRequest struct {
+	Page       int    `json:"page" form:"page" validate:"omitempty,min=1"`
+	Size       int    `json:"size" form:"size" validate:"omitempty,min=1,max=100"`
+	Status     string `json:"status" form:"status" validate:"omitempty,oneof=pending approved rejected"`
+	OfferingID int    `json:"offering_id" form:"offering_id" validate:"omitempty,min=1"`
+
+	// 教师只能查看本人提交的申请，由服务层根据登录身份设置
+	RequestedBy int `json:"-" form:"-"`
+}
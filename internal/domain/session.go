@@ -0,0 +1,27 @@
+package domain
+
+import "time"
+
+// Session 登录会话，每次登录签发的token对应一个会话
+type Session struct {
+	ID        string    `json:"id"` // 会话ID，即token中的jti
+	AdminID   int       `json:"admin_id"`
+	Device    string    `json:"device"`
+	IP        string    `json:"ip"`
+	UserAgent string    `json:"user_agent"`
+	CreatedAt time.Time `json:"created_at"`
+	ExpiresAt time.Time `json:"expires_at"`
+	Current   bool      `json:"current"` // 是否为发起请求的会话，不持久化
+}
+
+// SessionMeta 创建会话时记录的客户端信息
+type SessionMeta struct {
+	Device    string
+	IP        string
+	UserAgent string
+}
+
+// RevokeSessionsResponse 批量撤销会话响应
+type RevokeSessionsResponse struct {
+	Revoked int `json:"revoked" example:"2"`
+}
@@ -17,7 +17,8 @@ type Student struct {
 	Major          string     `json:"major" db:"major" validate:"required,min=2,max=50,nohtml,nosql"`
 	EnrollmentDate *time.Time `json:"enrollment_date" db:"enrollment_date"`
 	GraduationDate *time.Time `json:"graduation_date" db:"graduation_date"`
-	Status         string     `json:"status" db:"status" validate:"required,oneof=active inactive graduated"`
+	Status         string     `json:"status" db:"status" validate:"required,oneof=enrolled suspended resumed transferred_out withdrawn graduated expelled"`
+	ClassID        *int       `json:"class_id" db:"class_id"` // 当前所在班级，通过分班接口维护
 	CreatedAt      time.Time  `json:"created_at" db:"created_at"`
 	UpdatedAt      time.Time  `json:"updated_at" db:"updated_at"`
 }
@@ -34,7 +35,7 @@ type CreateStudentRequest struct {
 	Major          string     `json:"major" validate:"required,min=2,max=50,nohtml,nosql"`
 	EnrollmentDate *time.Time `json:"enrollment_date"`
 	GraduationDate *time.Time `json:"graduation_date"`
-	Status         string     `json:"status" validate:"omitempty,oneof=active inactive graduated"`
+	Status         string     `json:"status" validate:"omitempty,oneof=enrolled graduated"` // 初始学籍状态：在读，或补录历届毕业生时为毕业
 }
 
 // UpdateStudentRequest 更新学生请求结构
@@ -49,7 +50,13 @@ type UpdateStudentRequest struct {
 	Major          string     `json:"major" validate:"omitempty,min=2,max=50,nohtml,nosql"`
 	EnrollmentDate *time.Time `json:"enrollment_date"`
 	GraduationDate *time.Time `json:"graduation_date"`
-	Status         string     `json:"status" validate:"omitempty,oneof=active inactive graduated"`
+	Status         string     `json:"status" validate:"omitempty,oneof=enrolled suspended resumed transferred_out withdrawn graduated expelled"`
+}
+
+// StudentFilter 学生列表筛选条件
+type StudentFilter struct {
+	ClassID   int `json:"class_id" form:"class_id" validate:"omitempty,min=1"`
+	GradeYear int `json:"grade_year" form:"grade_year" validate:"omitempty,min=1900,max=2100"` // 按班级所属年级筛选
 }
 
 // BatchCreateStudentsRequest 批量创建学生请求结构
@@ -0,0 +1,37 @@
+package domain
+
+// 学生导入模式
+const (
+	StudentImportModeAtomic      = "atomic"       // 任意一行无效则全部不导入
+	StudentImportModeSkipInvalid = "skip_invalid" // 跳过无效行，只导入有效行
+)
+
+// StudentImportOptions 学生导入选项
+type StudentImportOptions struct {
+	Format  string            // csv 或 xlsx
+	Sheet   string            // XLSX 工作表名称，为空使用第一个工作表
+	Mapping map[string]string // 字段名到表头的映射，如 {"student_id": "学号"}，未指定的字段按默认表头识别
+	DryRun  bool              // 只校验不写入
+	Mode    string            // atomic 或 skip_invalid
+}
+
+// StudentImportRowError 单行导入错误
+type StudentImportRowError struct {
+	Row       int    `json:"row" example:"3"` // 表格中的行号（表头为第1行）
+	StudentID string `json:"student_id,omitempty" example:"2024010001"`
+	Field     string `json:"field,omitempty" example:"phone"`
+	Message   string `json:"message" example:"手机号格式错误"`
+}
+
+// StudentImportResult 学生导入结果
+type StudentImportResult struct {
+	DryRun    bool                    `json:"dry_run"`
+	Mode      string                  `json:"mode" example:"atomic"`
+	Total     int                     `json:"total" example:"1200"` // 数据行数（不含空行）
+	Valid     int                     `json:"valid" example:"1198"` // 通过校验的行数
+	Invalid   int                     `json:"invalid" example:"2"`  // 未通过校验的行数
+	Imported  int                     `json:"imported" example:"0"` // 实际写入的行数
+	Committed bool                    `json:"committed"`            // 是否已写入数据库
+	Columns   map[string]string       `json:"columns"`              // 实际使用的字段到表头映射
+	Errors    []StudentImportRowError `json:"errors"`
+}
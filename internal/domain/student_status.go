@@ -0,0 +1,140 @@
+package domain
+
+import "time"
+
+// 学籍状态
+const (
+	StudentStatusEnrolled       = "enrolled"        // 在读
+	StudentStatusSuspended      = "suspended"       // 休学
+	StudentStatusResumed        = "resumed"         // 复学
+	StudentStatusTransferredOut = "transferred_out" // 转出
+	StudentStatusWithdrawn      = "withdrawn"       // 退学
+	StudentStatusGraduated      = "graduated"       // 毕业
+	StudentStatusExpelled       = "expelled"        // 开除学籍
+)
+
+// StudentStatuses 全部学籍状态，按展示顺序排列
+var StudentStatuses = []string{
+	StudentStatusEnrolled,
+	StudentStatusSuspended,
+	StudentStatusResumed,
+	StudentStatusTransferredOut,
+	StudentStatusWithdrawn,
+	StudentStatusGraduated,
+	StudentStatusExpelled,
+}
+
+// StudentStatusNames 学籍状态的中文名称
+var StudentStatusNames = map[string]string{
+	StudentStatusEnrolled:       "在读",
+	StudentStatusSuspended:      "休学",
+	StudentStatusResumed:        "复学",
+	StudentStatusTransferredOut: "转出",
+	StudentStatusWithdrawn:      "退学",
+	StudentStatusGraduated:      "毕业",
+	StudentStatusExpelled:       "开除学籍",
+}
+
+// StudentStatusTransition 转入某一学籍状态的规则
+type StudentStatusTransition struct {
+	From            []string // 允许转入的起始状态
+	RequireDocument bool     // 是否须填写批准文件编号
+}
+
+// StudentStatusTransitions 各学籍状态允许的起始状态。在读只作为新建学生的初始状态，转出、退学、毕业和开除学籍为终态
+var StudentStatusTransitions = map[string]StudentStatusTransition{
+	StudentStatusSuspended: {
+		From:            []string{StudentStatusEnrolled, StudentStatusResumed},
+		RequireDocument: true,
+	},
+	StudentStatusResumed: {
+		From:            []string{StudentStatusSuspended},
+		RequireDocument: true,
+	},
+	StudentStatusTransferredOut: {
+		From:            []string{StudentStatusEnrolled, StudentStatusSuspended, StudentStatusResumed},
+		RequireDocument: true,
+	},
+	StudentStatusWithdrawn: {
+		From:            []string{StudentStatusEnrolled, StudentStatusSuspended, StudentStatusResumed},
+		RequireDocument: true,
+	},
+	StudentStatusGraduated: {
+		From: []string{StudentStatusEnrolled, StudentStatusResumed},
+	},
+	StudentStatusExpelled: {
+		From:            []string{StudentStatusEnrolled, StudentStatusSuspended, StudentStatusResumed},
+		RequireDocument: true,
+	},
+}
+
+// IsInitialStudentStatus 新建或导入学生时可以使用的学籍状态：在读，或补录历届毕业生时为毕业（须填写毕业日期）。
+// 其他状态须通过学籍异动办理，以便写入异动记录
+func IsInitialStudentStatus(status string) bool {
+	return status == StudentStatusEnrolled || status == StudentStatusGraduated
+}
+
+// IsActiveStudentStatus 学籍状态是否为在校（在读或复学），只有在校学生可以录入成绩
+func IsActiveStudentStatus(status string) bool {
+	return status == StudentStatusEnrolled || status == StudentStatusResumed
+}
+
+// CanTransitionStudentStatus 能否从 from 转为 to
+func CanTransitionStudentStatus(from, to string) bool {
+	transition, ok := StudentStatusTransitions[to]
+	if !ok {
+		return false
+	}
+	for _, status := range transition.From {
+		if status == from {
+			return true
+		}
+	}
+	return false
+}
+
+// AllowedStudentStatusTransitions 从当前状态可以转入的学籍状态
+func AllowedStudentStatusTransitions(from string) []string {
+	allowed := []string{}
+	for _, to := range StudentStatuses {
+		if CanTransitionStudentStatus(from, to) {
+			allowed = append(allowed, to)
+		}
+	}
+	return allowed
+}
+
+// StudentStatusChange 学籍异动记录，只追加不修改
+type StudentStatusChange struct {
+	ID            int       `json:"id"`
+	StudentID     int       `json:"student_id"`
+	FromStatus    string    `json:"from_status"`
+	ToStatus      string    `json:"to_status"`
+	Reason        string    `json:"reason"`
+	DocumentNo    string    `json:"document_no,omitempty"` // 批准文件编号
+	Attachments   []string  `json:"attachments"`
+	EffectiveDate time.Time `json:"effective_date"`
+	ActorID       *int      `json:"actor_id"`
+	ActorName     string    `json:"actor_name,omitempty"`
+	CreatedAt     time.Time `json:"created_at"`
+}
+
+// ChangeStudentStatusRequest 学籍异动请求结构，附件为已上传文件（如审批表、证明材料）的地址
+type ChangeStudentStatusRequest struct {
+	Status        string    `json:"status" validate:"required,oneof=suspended resumed transferred_out withdrawn graduated expelled"`
+	Reason        string    `json:"reason" validate:"required,min=2,max=500,nohtml,nosql"`
+	DocumentNo    string    `json:"document_no" validate:"omitempty,max=100,nohtml,nosql"`
+	Attachments   []string  `json:"attachments" validate:"omitempty,max=5,dive,url,max=500"`
+	EffectiveDate time.Time `json:"effective_date" validate:"required"`
+}
+
+// StudentStatusInfo 学生的学籍状态、可以转入的状态及异动记录
+type StudentStatusInfo struct {
+	StudentID          int                    `json:"student_id"`
+	StudentNo          string                 `json:"student_no"` // 学号
+	StudentName        string                 `json:"student_name"`
+	Status             string                 `json:"status"`
+	Active             bool                   `json:"active"` // 是否在校
+	AllowedTransitions []string               `json:"allowed_transitions"`
+	History            []*StudentStatusChange `json:"history"`
+}
@@ -0,0 +1,170 @@
+package domain
+
+import (
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// 学期状态，按 planning -> open -> grading -> closed 顺序推进
+const (
+	TermStatusPlanning = "planning" // 筹备中，可开课
+	TermStatusOpen     = "open"     // 教学中，可选课和录入平时成绩
+	TermStatusGrading  = "grading"  // 成绩录入期
+	TermStatusClosed   = "closed"   // 已结束，成绩冻结
+)
+
+// Term 学期数据模型，Code 为规范的学期代码，如 2024-2025-1
+type Term struct {
+	ID        int       `json:"id" db:"id"`
+	Code      string    `json:"code" db:"code"`
+	Name      string    `json:"name" db:"name"`
+	StartDate time.Time `json:"start_date" db:"start_date"`
+	EndDate   time.Time `json:"end_date" db:"end_date"`
+	IsCurrent bool      `json:"is_current" db:"is_current"`
+	Status    string    `json:"status" db:"status"`
+	CreatedAt time.Time `json:"created_at" db:"created_at"`
+	UpdatedAt time.Time `json:"updated_at" db:"updated_at"`
+}
+
+// CreateTermRequest 创建学期请求结构
+type CreateTermRequest struct {
+	Code      string    `json:"code" validate:"required,min=5,max=20,nohtml,nosql"` // 支持 2024-2025-1、2024秋 等写法，保存为规范代码
+	Name      string    `json:"name" validate:"omitempty,max=50,nohtml,nosql"`      // 默认按学期代码生成，如 2024-2025学年第一学期
+	StartDate time.Time `json:"start_date" validate:"required"`
+	EndDate   time.Time `json:"end_date" validate:"required"`
+	Status    string    `json:"status" validate:"omitempty,oneof=planning open grading"`
+}
+
+// UpdateTermRequest 更新学期请求结构
+type UpdateTermRequest struct {
+	Name      string     `json:"name" validate:"omitempty,max=50,nohtml,nosql"`
+	StartDate *time.Time `json:"start_date"`
+	EndDate   *time.Time `json:"end_date"`
+}
+
+// UpdateTermStatusRequest 学期状态变更请求结构
+type UpdateTermStatusRequest struct {
+	Status string `json:"status" validate:"required,oneof=planning open grading closed"`
+}
+
+// TermListRequest 学期列表请求结构
+type TermListRequest struct {
+	Page   int    `json:"page" form:"page" validate:"omitempty,min=1"`
+	Size   int    `json:"size" form:"size" validate:"omitempty,min=1,max=100"`
+	Status string `json:"status" form:"status" validate:"omitempty,oneof=planning open grading closed"`
+}
+
+// TermHoliday 学期内的节假日，日期范围内不上课
+type TermHoliday struct {
+	ID        int       `json:"id" db:"id"`
+	TermID    int       `json:"term_id" db:"term_id"`
+	Name      string    `json:"name" db:"name"`
+	StartDate time.Time `json:"start_date" db:"start_date"`
+	EndDate   time.Time `json:"end_date" db:"end_date"`
+	CreatedAt time.Time `json:"created_at" db:"created_at"`
+}
+
+// Covers 日期是否在节假日内
+func (h *TermHoliday) Covers(date time.Time) bool {
+	day := time.Date(date.Year(), date.Month(), date.Day(), 0, 0, 0, 0, time.UTC)
+	start := time.Date(h.StartDate.Year(), h.StartDate.Month(), h.StartDate.Day(), 0, 0, 0, 0, time.UTC)
+	end := time.Date(h.EndDate.Year(), h.EndDate.Month(), h.EndDate.Day(), 0, 0, 0, 0, time.UTC)
+	return !day.Before(start) && !day.After(end)
+}
+
+// CreateTermHolidayRequest 创建节假日请求结构
+type CreateTermHolidayRequest struct {
+	Name      string    `json:"name" validate:"required,max=50,nohtml,nosql"`
+	StartDate time.Time `json:"start_date" validate:"required"`
+	EndDate   time.Time `json:"end_date"` // 默认与开始日期相同
+}
+
+// termStatusOrder 学期状态的推进顺序
+var termStatusOrder = map[string]int{
+	TermStatusPlanning: 0,
+	TermStatusOpen:     1,
+	TermStatusGrading:  2,
+	TermStatusClosed:   3,
+}
+
+// CanTransitionTo 判断学期状态能否变更为目标状态：只能逐级推进，已结束的学期不能重新打开
+func (t *Term) CanTransitionTo(status string) bool {
+	from, ok := termStatusOrder[t.Status]
+	if !ok {
+		return false
+	}
+	to, ok := termStatusOrder[status]
+	return ok && to == from+1
+}
+
+// ScoresWritable 学期是否允许录入或修改成绩
+func (t *Term) ScoresWritable() bool {
+	return t.Status == TermStatusOpen || t.Status == TermStatusGrading
+}
+
+var (
+	termCanonicalPattern = regexp.MustCompile(`^\d{4}-\d{4}-[123]$`)
+	termLongPattern      = regexp.MustCompile(`^(\d{4})-(\d{4})学年第([一二三123])学期$`)
+	termShortPattern     = regexp.MustCompile(`^(\d{4})-([123])$`)
+	termSeasonPattern    = regexp.MustCompile(`^(\d{4})(春|秋|spring|fall|autumn)$`)
+	termSeasonYearLast   = regexp.MustCompile(`^(春|秋|spring|fall|autumn)(\d{4})$`)
+	termSuffixPattern    = regexp.MustCompile(`(季|学期)+$`)
+	termNumerals         = strings.NewReplacer("一", "1", "二", "2", "三", "3")
+)
+
+// NormalizeTermCode 将各种学期写法规范为 <学年起始年>-<学年结束年>-<学期序号>，无法识别时返回去除首尾空白的原值。
+// 规则与迁移 000008_terms 中的 normalize_term_code 保持一致，以下示例由 term_test.go 覆盖：
+//
+//	2024-2025学年第一学期 -> 2024-2025-1
+//	2024-1、2024-2        -> 2024-2025-1、2024-2025-2
+//	2024秋、2024 Fall     -> 2024-2025-1
+//	2024春、Spring 2024   -> 2023-2024-2
+func NormalizeTermCode(raw string) string {
+	s := strings.ToLower(strings.Join(strings.Fields(raw), ""))
+	if s == "" || termCanonicalPattern.MatchString(s) {
+		return s
+	}
+
+	if m := termLongPattern.FindStringSubmatch(s); m != nil {
+		return m[1] + "-" + m[2] + "-" + termNumerals.Replace(m[3])
+	}
+
+	if m := termShortPattern.FindStringSubmatch(s); m != nil {
+		year, _ := strconv.Atoi(m[1])
+		return m[1] + "-" + strconv.Itoa(year+1) + "-" + m[2]
+	}
+
+	s = termSuffixPattern.ReplaceAllString(s, "")
+	var yearText, season string
+	if m := termSeasonPattern.FindStringSubmatch(s); m != nil {
+		yearText, season = m[1], m[2]
+	} else if m := termSeasonYearLast.FindStringSubmatch(s); m != nil {
+		yearText, season = m[2], m[1]
+	}
+	if yearText != "" {
+		year, _ := strconv.Atoi(yearText)
+		if season == "春" || season == "spring" {
+			return strconv.Itoa(year-1) + "-" + yearText + "-2"
+		}
+		return yearText + "-" + strconv.Itoa(year+1) + "-1"
+	}
+
+	return strings.TrimSpace(raw)
+}
+
+// DefaultTermName 根据规范学期代码生成学期名称，如 2024-2025学年第一学期；非规范代码原样返回
+func DefaultTermName(code string) string {
+	if !termCanonicalPattern.MatchString(code) {
+		return code
+	}
+	parts := strings.Split(code, "-")
+	numeral := strings.NewReplacer("1", "一", "2", "二", "3", "三").Replace(parts[2])
+	return parts[0] + "-" + parts[1] + "学年第" + numeral + "学期"
+}
+
+// IsCanonicalTermCode 判断是否为规范学期代码
+func IsCanonicalTermCode(code string) bool {
+	return termCanonicalPattern.MatchString(code)
+}
@@ -0,0 +1,42 @@
+package domain
+
+import "testing"
+
+// TestNormalizeTermCode 覆盖 NormalizeTermCode 文档注释中的示例。迁移 000008_terms 中的 normalize_term_code
+// 注释列出了相同的示例，两处规则修改时须同步更新本用例（迁移中的函数对无法识别的写法返回 NULL，
+// 调用处以 COALESCE(normalize_term_code(semester), btrim(semester)) 回退为去除首尾空白的原值）
+func TestNormalizeTermCode(t *testing.T) {
+	tests := []struct {
+		raw  string
+		want string
+	}{
+		// 文档注释中的示例
+		{"2024-2025学年第一学期", "2024-2025-1"},
+		{"2024-1", "2024-2025-1"},
+		{"2024-2", "2024-2025-2"},
+		{"2024秋", "2024-2025-1"},
+		{"2024 Fall", "2024-2025-1"},
+		{"2024春", "2023-2024-2"},
+		{"Spring 2024", "2023-2024-2"},
+
+		// 其他写法
+		{"2024-2025-2", "2024-2025-2"},
+		{"2024-2025学年第3学期", "2024-2025-3"},
+		{"  2024 - 2025 学年 第二 学期 ", "2024-2025-2"},
+		{"2024秋季学期", "2024-2025-1"},
+		{"2024 Autumn", "2024-2025-1"},
+		{"FALL2024", "2024-2025-1"},
+		{"", ""},
+
+		// 无法识别时返回去除首尾空白的原值
+		{" 2024年下半年 ", "2024年下半年"},
+		{"2024-4", "2024-4"},
+		{"Summer 2024", "Summer 2024"},
+	}
+
+	for _, tt := range tests {
+		if got := NormalizeTermCode(tt.raw); got != tt.want {
+			t.Errorf("NormalizeTermCode(%q) = %q, want %q", tt.raw, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,241 @@
+package domain
+
+import (
+	"fmt"
+	"time"
+)
+
+// 教室类型
+const (
+	RoomTypeClassroom   = "classroom"    // 普通教室
+	RoomTypeLab         = "lab"          // 实验室
+	RoomTypeComputerLab = "computer_lab" // 机房
+	RoomTypeLectureHall = "lecture_hall" // 阶梯教室
+	RoomTypeGym         = "gym"          // 体育场馆
+	RoomTypeOther       = "other"        // 其他
+)
+
+// 教室状态
+const (
+	RoomStatusActive   = "active"   // 可用
+	RoomStatusInactive = "inactive" // 停用，不再参与排课
+)
+
+// 课表冲突类型
+const (
+	TimetableConflictTeacher = "teacher"
+	TimetableConflictRoom    = "room"
+	TimetableConflictClass   = "class"
+)
+
+// 课表所属对象
+const (
+	TimetableOwnerTeacher = "teacher"
+	TimetableOwnerClass   = "class"
+	TimetableOwnerStudent = "student"
+	TimetableOwnerRoom    = "room"
+)
+
+// WeekdayNames 星期的中文名称，下标为 1-7
+var WeekdayNames = [...]string{"", "周一", "周二", "周三", "周四", "周五", "周六", "周日"}
+
+// Room 教室数据模型
+type Room struct {
+	ID        int       `json:"id" db:"id"`
+	Code      string    `json:"code" db:"code"` // 教室编号，如 A101
+	Name      string    `json:"name" db:"name"`
+	Building  string    `json:"building,omitempty" db:"building"`
+	RoomType  string    `json:"room_type" db:"room_type"`
+	Capacity  int       `json:"capacity" db:"capacity"`
+	Status    string    `json:"status" db:"status"`
+	CreatedAt time.Time `json:"created_at" db:"created_at"`
+	UpdatedAt time.Time `json:"updated_at" db:"updated_at"`
+}
+
+// CreateRoomRequest 创建教室请求结构
+type CreateRoomRequest struct {
+	Code     string `json:"code" validate:"required,min=1,max=20,nohtml,nosql"`
+	Name     string `json:"name" validate:"omitempty,max=50,nohtml,nosql"` // 默认与编号相同
+	Building string `json:"building" validate:"omitempty,max=50,nohtml,nosql"`
+	RoomType string `json:"room_type" validate:"omitempty,oneof=classroom lab computer_lab lecture_hall gym other"`
+	Capacity int    `json:"capacity" validate:"required,min=1,max=2000"`
+	Status   string `json:"status" validate:"omitempty,oneof=active inactive"`
+}
+
+// UpdateRoomRequest 更新教室请求结构
+type UpdateRoomRequest struct {
+	Code     string  `json:"code" validate:"omitempty,min=1,max=20,nohtml,nosql"`
+	Name     string  `json:"name" validate:"omitempty,max=50,nohtml,nosql"`
+	Building *string `json:"building" validate:"omitempty,max=50,nohtml,nosql"`
+	RoomType string  `json:"room_type" validate:"omitempty,oneof=classroom lab computer_lab lecture_hall gym other"`
+	Capacity int     `json:"capacity" validate:"omitempty,min=1,max=2000"`
+	Status   string  `json:"status" validate:"omitempty,oneof=active inactive"`
+}
+
+// RoomListRequest 教室列表请求结构
+type RoomListRequest struct {
+	Page        int    `json:"page" form:"page" validate:"omitempty,min=1"`
+	Size        int    `json:"size" form:"size" validate:"omitempty,min=1,max=100"`
+	Building    string `json:"building" form:"building" validate:"omitempty,max=50,nohtml,nosql"`
+	RoomType    string `json:"room_type" form:"room_type" validate:"omitempty,oneof=classroom lab computer_lab lecture_hall gym other"`
+	Status      string `json:"status" form:"status" validate:"omitempty,oneof=active inactive"`
+	MinCapacity int    `json:"min_capacity" form:"min_capacity" validate:"omitempty,min=1"`
+}
+
+// Period 节次及上下课时间
+type Period struct {
+	Number int    `json:"number"`
+	Start  string `json:"start"` // 如 08:00
+	End    string `json:"end"`   // 如 08:45
+}
+
+// TimetableSlot 课表时段：开课每周固定某天的连续若干节，在某教室由某位老师上课
+type TimetableSlot struct {
+	ID          int       `json:"id" db:"id"`
+	OfferingID  int       `json:"offering_id" db:"offering_id"`
+	RoomID      int       `json:"room_id" db:"room_id"`
+	TeacherID   *int      `json:"teacher_id" db:"teacher_id"`
+	ClassID     *int      `json:"class_id" db:"class_id"` // 上课的行政班，为空表示只按选课名单上课
+	DayOfWeek   int       `json:"day_of_week" db:"day_of_week"`
+	StartPeriod int       `json:"start_period" db:"start_period"`
+	EndPeriod   int       `json:"end_period" db:"end_period"`
+	Note        string    `json:"note,omitempty" db:"note"`
+	CreatedBy   *int      `json:"created_by,omitempty" db:"created_by"`
+	CreatedAt   time.Time `json:"created_at" db:"created_at"`
+	UpdatedAt   time.Time `json:"updated_at" db:"updated_at"`
+
+	// 扩展字段（用于关联查询）
+	Semester    string `json:"semester" db:"-"`
+	SubjectID   int    `json:"subject_id" db:"-"`
+	SubjectCode string `json:"subject_code,omitempty" db:"-"`
+	SubjectName string `json:"subject_name,omitempty" db:"-"`
+	Section     string `json:"section,omitempty" db:"-"`
+	RoomCode    string `json:"room_code,omitempty" db:"-"`
+	RoomName    string `json:"room_name,omitempty" db:"-"`
+	TeacherName string `json:"teacher_name,omitempty" db:"-"`
+	ClassName   string `json:"class_name,omitempty" db:"-"`
+	StartTime   string `json:"start_time,omitempty" db:"-"` // 按节次配置换算的上课时间
+	EndTime     string `json:"end_time,omitempty" db:"-"`   // 按节次配置换算的下课时间
+}
+
+// Overlaps 两个时段是否在同一天且节次有重叠
+func (s *TimetableSlot) Overlaps(other *TimetableSlot) bool {
+	return s.DayOfWeek == other.DayOfWeek && s.StartPeriod <= other.EndPeriod && other.StartPeriod <= s.EndPeriod
+}
+
+// TimeLabel 时段的中文描述，如"周一 第1-2节"
+func (s *TimetableSlot) TimeLabel() string {
+	day := ""
+	if s.DayOfWeek >= 1 && s.DayOfWeek <= 7 {
+		day = WeekdayNames[s.DayOfWeek]
+	}
+	if s.StartPeriod == s.EndPeriod {
+		return fmt.Sprintf("%s 第%d节", day, s.StartPeriod)
+	}
+	return fmt.Sprintf("%s 第%d-%d节", day, s.StartPeriod, s.EndPeriod)
+}
+
+// CreateTimetableSlotRequest 创建课表时段请求结构
+type CreateTimetableSlotRequest struct {
+	OfferingID  int    `json:"offering_id" validate:"required,min=1"`
+	RoomID      int    `json:"room_id" validate:"required,min=1"`
+	TeacherID   *int   `json:"teacher_id" validate:"omitempty,min=1"` // 默认为开课的任课老师
+	ClassID     *int   `json:"class_id" validate:"omitempty,min=1"`
+	DayOfWeek   int    `json:"day_of_week" validate:"required,min=1,max=7"` // 1 为周一，7 为周日
+	StartPeriod int    `json:"start_period" validate:"required,min=1,max=20"`
+	EndPeriod   int    `json:"end_period" validate:"omitempty,min=1,max=20"` // 默认与开始节次相同
+	Note        string `json:"note" validate:"omitempty,max=200,nohtml,nosql"`
+}
+
+// UpdateTimetableSlotRequest 更新课表时段请求结构
+type UpdateTimetableSlotRequest struct {
+	RoomID      int     `json:"room_id" validate:"omitempty,min=1"`
+	TeacherID   *int    `json:"teacher_id" validate:"omitempty,min=0"` // 传 0 表示不指定教师
+	ClassID     *int    `json:"class_id" validate:"omitempty,min=0"`   // 传 0 表示不指定行政班
+	DayOfWeek   int     `json:"day_of_week" validate:"omitempty,min=1,max=7"`
+	StartPeriod int     `json:"start_period" validate:"omitempty,min=1,max=20"`
+	EndPeriod   int     `json:"end_period" validate:"omitempty,min=1,max=20"`
+	Note        *string `json:"note" validate:"omitempty,max=200,nohtml,nosql"`
+}
+
+// TimetableSlotListRequest 课表时段列表请求结构
+type TimetableSlotListRequest struct {
+	Page       int    `json:"page" form:"page" validate:"omitempty,min=1"`
+	Size       int    `json:"size" form:"size" validate:"omitempty,min=1,max=100"`
+	Semester   string `json:"semester" form:"semester" validate:"omitempty,max=20,nohtml,nosql"`
+	OfferingID int    `json:"offering_id" form:"offering_id" validate:"omitempty,min=1"`
+	RoomID     int    `json:"room_id" form:"room_id" validate:"omitempty,min=1"`
+	TeacherID  int    `json:"teacher_id" form:"teacher_id" validate:"omitempty,min=1"`
+	ClassID    int    `json:"class_id" form:"class_id" validate:"omitempty,min=1"`
+	DayOfWeek  int    `json:"day_of_week" form:"day_of_week" validate:"omitempty,min=1,max=7"`
+}
+
+// TimetableConflict 与已有课表时段的冲突
+type TimetableConflict struct {
+	Type string         `json:"type"` // teacher、room 或 class
+	Slot *TimetableSlot `json:"slot"`
+}
+
+// Message 冲突的中文描述
+func (c *TimetableConflict) Message() string {
+	subject := fmt.Sprintf("%s(%s)", c.Slot.SubjectName, c.Slot.Section)
+	switch c.Type {
+	case TimetableConflictTeacher:
+		return fmt.Sprintf("教师 %s 在 %s 已有课程 %s", c.Slot.TeacherName, c.Slot.TimeLabel(), subject)
+	case TimetableConflictRoom:
+		return fmt.Sprintf("教室 %s 在 %s 已安排课程 %s", c.Slot.RoomCode, c.Slot.TimeLabel(), subject)
+	default:
+		return fmt.Sprintf("班级 %s 在 %s 已有课程 %s", c.Slot.ClassName, c.Slot.TimeLabel(), subject)
+	}
+}
+
+// TimetableRequest 查看课表请求结构
+type TimetableRequest struct {
+	Semester string `json:"semester" form:"semester" validate:"omitempty,max=20,nohtml,nosql"` // 默认当前学期
+}
+
+// Timetable 教师、班级、学生或教室某学期的周课表
+type Timetable struct {
+	Semester  string          `json:"semester"`
+	OwnerType string          `json:"owner_type"` // teacher、class、student 或 room
+	OwnerID   int             `json:"owner_id"`
+	OwnerName string          `json:"owner_name"`
+	Periods   []Period        `json:"periods"`
+	Slots     []TimetableSlot `json:"slots"` // 按星期和节次排列
+}
+
+// AutoScheduleCourse 自动排课中一门开课的排课要求
+type AutoScheduleCourse struct {
+	OfferingID        int    `json:"offering_id" validate:"required,min=1"`
+	SessionsPerWeek   int    `json:"sessions_per_week" validate:"required,min=1,max=10"`   // 每周上课次数
+	PeriodsPerSession int    `json:"periods_per_session" validate:"omitempty,min=1,max=4"` // 每次连上节数，默认 2
+	ClassID           *int   `json:"class_id" validate:"omitempty,min=1"`
+	RoomID            *int   `json:"room_id" validate:"omitempty,min=1"`                                                     // 指定教室，为空时按容量和类型自动选择
+	RoomType          string `json:"room_type" validate:"omitempty,oneof=classroom lab computer_lab lecture_hall gym other"` // 默认使用普通教室或阶梯教室
+}
+
+// AutoScheduleRequest 自动排课请求结构。已有的课表时段保持不变，新时段避开与其冲突的时间
+type AutoScheduleRequest struct {
+	Semester       string               `json:"semester" validate:"required,max=20,nohtml,nosql"`
+	Courses        []AutoScheduleCourse `json:"courses" validate:"required,min=1,max=500,dive"`
+	Days           []int                `json:"days" validate:"omitempty,max=7,dive,min=1,max=7"`              // 可排课的星期，默认按配置的每周上课天数
+	BlockedPeriods []int                `json:"blocked_periods" validate:"omitempty,max=20,dive,min=1,max=20"` // 不排课的节次，如班会课
+	Save           bool                 `json:"save"`                                                          // 为 true 时保存排课结果，否则只返回草稿
+}
+
+// UnscheduledSession 自动排课未能安排的课次
+type UnscheduledSession struct {
+	OfferingID  int    `json:"offering_id"`
+	SubjectName string `json:"subject_name,omitempty"`
+	Section     string `json:"section,omitempty"`
+	Sessions    int    `json:"sessions"` // 未能安排的次数
+	Reason      string `json:"reason"`
+}
+
+// AutoScheduleResult 自动排课结果
+type AutoScheduleResult struct {
+	Semester    string               `json:"semester"`
+	Saved       bool                 `json:"saved"`
+	Slots       []TimetableSlot      `json:"slots"`       // 新安排的时段
+	Unscheduled []UnscheduledSession `json:"unscheduled"` // 未能安排的课次
+}
@@ -0,0 +1,110 @@
+package domain
+
+import "time"
+
+// 成绩单类型
+const (
+	TranscriptKindTranscript = "transcript"  // 历年成绩单
+	TranscriptKindReportCard = "report_card" // 学期成绩报告单
+)
+
+// TranscriptScore 学生的一条成绩及科目学分，用于生成成绩单
+type TranscriptScore struct {
+	Semester    string
+	SubjectID   int
+	SubjectCode string
+	SubjectName string
+	Credits     int
+	ExamType    string
+	Score       float64
+}
+
+// TranscriptCourse 成绩单中的一门课程
+type TranscriptCourse struct {
+	SubjectID   int      `json:"subject_id"`
+	SubjectCode string   `json:"subject_code"`
+	SubjectName string   `json:"subject_name"`
+	Credits     int      `json:"credits"`
+	Score       *float64 `json:"score"`       // 总评成绩，按评分方案加权计算，尚无总评成绩时为空
+	Letter      string   `json:"letter"`      // 等级
+	GradePoint  *float64 `json:"grade_point"` // 绩点，不计入平均绩点时为空
+	Passed      bool     `json:"passed"`
+	Final       *float64 `json:"final,omitempty"`
+	Midterm     *float64 `json:"midterm,omitempty"`
+	Quiz        *float64 `json:"quiz,omitempty"`
+	Assignment  *float64 `json:"assignment,omitempty"`
+}
+
+// TranscriptSemester 成绩单中的一个学期
+type TranscriptSemester struct {
+	Semester      string             `json:"semester"`
+	Courses       []TranscriptCourse `json:"courses"`
+	Credits       int                `json:"credits"`        // 修读学分
+	EarnedCredits int                `json:"earned_credits"` // 获得学分（及格课程）
+	AverageScore  float64            `json:"average_score"`
+	GPA           float64            `json:"gpa"` // 学分加权绩点
+}
+
+// TranscriptSchool 成绩单抬头中的学校信息
+type TranscriptSchool struct {
+	Name    string `json:"name"`
+	NameEN  string `json:"name_en"`
+	Address string `json:"address"`
+	Phone   string `json:"phone"`
+	Issuer  string `json:"issuer"` // 签发部门，如 教务处
+}
+
+// Transcript 成绩单（历年成绩单或学期成绩报告单）
+type Transcript struct {
+	Kind             string               `json:"kind"`
+	School           TranscriptSchool     `json:"school"`
+	Student          *Student             `json:"student"`
+	Semesters        []TranscriptSemester `json:"semesters"`
+	Semester         string               `json:"semester,omitempty"` // 学期成绩报告单对应的学期
+	TotalCredits     int                  `json:"total_credits"`
+	EarnedCredits    int                  `json:"earned_credits"`
+	CumulativeGPA    float64              `json:"cumulative_gpa"`
+	GradeScale       string               `json:"grade_scale"`      // 计算绩点使用的绩点制
+	GradeScaleName   string               `json:"grade_scale_name"` // 绩点制显示名称
+	VerificationCode string               `json:"verification_code"`
+	VerifyURL        string               `json:"verify_url"`
+	Checksum         string               `json:"checksum"`
+	IssuedAt         time.Time            `json:"issued_at"`
+}
+
+// TranscriptRequest 生成成绩单请求参数
+type TranscriptRequest struct {
+	Semester string `form:"semester" validate:"omitempty,min=5,max=20,nohtml,nosql"` // 学期成绩报告单必填
+	Scale    string `form:"scale" validate:"omitempty,max=30"`                       // 绩点制名称，默认按学生专业设置
+}
+
+// TranscriptVerification 成绩单签发记录，用于公开验证
+type TranscriptVerification struct {
+	ID            int       `json:"-"`
+	Code          string    `json:"code"`
+	Kind          string    `json:"kind"`
+	StudentID     int       `json:"-"`
+	Semester      string    `json:"semester,omitempty"`
+	TotalCredits  int       `json:"total_credits"`
+	CumulativeGPA float64   `json:"cumulative_gpa"`
+	GradeScale    string    `json:"grade_scale"`
+	Checksum      string    `json:"checksum"` // 成绩数据的SHA-256摘要
+	IssuedBy      *int      `json:"-"`
+	IssuedAt      time.Time `json:"issued_at"`
+}
+
+// TranscriptVerificationResult 成绩单验证结果
+type TranscriptVerificationResult struct {
+	Valid         bool      `json:"valid"`
+	Code          string    `json:"code" example:"7KQ3-M9TX-2HCP-W4RD"`
+	Kind          string    `json:"kind" example:"transcript"`
+	StudentNumber string    `json:"student_number" example:"2024010001"`
+	StudentName   string    `json:"student_name" example:"张三"`
+	Major         string    `json:"major" example:"计算机科学"`
+	Semester      string    `json:"semester,omitempty"`
+	TotalCredits  int       `json:"total_credits" example:"42"`
+	CumulativeGPA float64   `json:"cumulative_gpa" example:"3.45"`
+	GradeScale    string    `json:"grade_scale" example:"standard4"`
+	Checksum      string    `json:"checksum"`
+	IssuedAt      time.Time `json:"issued_at"`
+}
@@ -0,0 +1,48 @@
+package domain
+
+import "time"
+
+// TwoFactorChallenge 密码校验通过但需要两步验证时的登录响应
+type TwoFactorChallenge struct {
+	TwoFactorRequired  bool       `json:"two_factor_required" example:"true"`
+	ChallengeToken     string     `json:"challenge_token"` // 第二步登录时提交，短时间内有效
+	ExpiresAt          time.Time  `json:"expires_at" example:"2024-01-01T12:05:00Z"`
+	EnrollmentRequired bool       `json:"enrollment_required" example:"false"` // 角色强制要求两步验证但尚未绑定
+	Setup              *TOTPSetup `json:"setup,omitempty"`                     // 需要绑定时返回密钥
+}
+
+// TOTPSetup 绑定认证器App所需信息
+type TOTPSetup struct {
+	Secret string `json:"secret" example:"JBSWY3DPEHPK3PXPJBSWY3DPEHPK3PXP"`
+	URI    string `json:"otpauth_uri" example:"otpauth://totp/student-management-system:admin?secret=JBSWY3DPEHPK3PXP&issuer=student-management-system"`
+}
+
+// TwoFactorLoginRequest 两步验证登录请求，验证码和恢复码二选一
+type TwoFactorLoginRequest struct {
+	ChallengeToken string `json:"challenge_token" validate:"required"`
+	Code           string `json:"code" validate:"omitempty,len=6,numeric" example:"123456"`
+	RecoveryCode   string `json:"recovery_code" validate:"omitempty,max=20" example:"abcde-fghjk"`
+}
+
+// TwoFactorCodeRequest 提交认证器验证码
+type TwoFactorCodeRequest struct {
+	Code string `json:"code" validate:"required,len=6,numeric" example:"123456"`
+}
+
+// DisableTwoFactorRequest 关闭两步验证请求，需要同时提供密码和验证码
+type DisableTwoFactorRequest struct {
+	Password string `json:"password" validate:"required"`
+	Code     string `json:"code" validate:"required,len=6,numeric" example:"123456"`
+}
+
+// TwoFactorStatus 两步验证状态
+type TwoFactorStatus struct {
+	Enabled                bool `json:"enabled"`
+	Required               bool `json:"required"` // 当前角色是否强制要求两步验证
+	RecoveryCodesRemaining int  `json:"recovery_codes_remaining"`
+}
+
+// RecoveryCodesResponse 新生成的恢复码，只显示一次
+type RecoveryCodesResponse struct {
+	RecoveryCodes []string `json:"recovery_codes"`
+}
@@ -245,3 +245,123 @@ func (h *AdminHandler) ListAdmins(c *gin.Context) {
 		Data:    response,
 	})
 }
+
+// GetAdminSessions 获取指定账号的会话列表
+// @Summary 获取账号会话列表
+// @Description 获取指定账号在各设备上的有效会话
+// @Tags 管理员管理
+// @Produce json
+// @Param id path int true "管理员ID"
+// @Success 200 {object} Response{data=[]domain.Session} "获取成功"
+// @Failure 400 {object} Response "请求参数错误"
+// @Failure 404 {object} Response "管理员不存在"
+// @Router /api/admin/{id}/sessions [get]
+func (h *AdminHandler) GetAdminSessions(c *gin.Context) {
+	idStr := c.Param("id")
+	id, err := strconv.Atoi(idStr)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, Response{
+			Code:    http.StatusBadRequest,
+			Message: "无效的管理员ID",
+			Data:    nil,
+		})
+		return
+	}
+
+	sessions, err := h.adminService.ListAdminSessions(id)
+	if err != nil {
+		h.logger.WithError(err).Error("Failed to list admin sessions")
+		c.JSON(http.StatusNotFound, Response{
+			Code:    http.StatusNotFound,
+			Message: err.Error(),
+			Data:    nil,
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, Response{
+		Code:    http.StatusOK,
+		Message: "获取成功",
+		Data:    sessions,
+	})
+}
+
+// ForceLogout 强制账号登出
+// @Summary 强制登出
+// @Description 使指定账号在所有设备上的会话失效
+// @Tags 管理员管理
+// @Produce json
+// @Param id path int true "管理员ID"
+// @Success 200 {object} Response{data=domain.RevokeSessionsResponse} "登出成功"
+// @Failure 400 {object} Response "请求参数错误"
+// @Failure 500 {object} Response "服务器内部错误"
+// @Router /api/admin/{id}/logout [post]
+func (h *AdminHandler) ForceLogout(c *gin.Context) {
+	idStr := c.Param("id")
+	id, err := strconv.Atoi(idStr)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, Response{
+			Code:    http.StatusBadRequest,
+			Message: "无效的管理员ID",
+			Data:    nil,
+		})
+		return
+	}
+
+	revoked, err := h.adminService.ForceLogout(id)
+	if err != nil {
+		h.logger.WithError(err).Error("Failed to force logout admin")
+		c.JSON(http.StatusInternalServerError, Response{
+			Code:    http.StatusInternalServerError,
+			Message: err.Error(),
+			Data:    nil,
+		})
+		return
+	}
+
+	h.logger.WithField("admin_id", id).Info("Admin force logged out")
+	c.JSON(http.StatusOK, Response{
+		Code:    http.StatusOK,
+		Message: "已强制登出",
+		Data:    domain.RevokeSessionsResponse{Revoked: revoked},
+	})
+}
+
+// ResetTwoFactor 重置账号的两步验证
+// @Summary 重置两步验证
+// @Description 关闭指定账号的两步验证并删除恢复码，同时使其全部会话失效。用于认证器丢失的情况
+// @Tags 管理员管理
+// @Produce json
+// @Param id path int true "管理员ID"
+// @Success 200 {object} Response "重置成功"
+// @Failure 400 {object} Response "请求参数错误"
+// @Failure 500 {object} Response "服务器内部错误"
+// @Router /api/admin/{id}/2fa/reset [post]
+func (h *AdminHandler) ResetTwoFactor(c *gin.Context) {
+	idStr := c.Param("id")
+	id, err := strconv.Atoi(idStr)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, Response{
+			Code:    http.StatusBadRequest,
+			Message: "无效的管理员ID",
+			Data:    nil,
+		})
+		return
+	}
+
+	if err := h.adminService.ResetTwoFactor(id); err != nil {
+		h.logger.WithError(err).Error("Failed to reset admin two-factor")
+		c.JSON(http.StatusInternalServerError, Response{
+			Code:    http.StatusInternalServerError,
+			Message: err.Error(),
+			Data:    nil,
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, Response{
+		Code:    http.StatusOK,
+		Message: "两步验证已重置",
+		Data:    nil,
+	})
+}
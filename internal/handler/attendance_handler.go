@@ -0,0 +1,318 @@
+package handler
+
+import (
+	"net/http"
+	"strconv"
+
+	"student-management-system/internal/domain"
+	"student-management-system/internal/service"
+	"student-management-system/pkg/validator"
+
+	"github.com/gin-gonic/gin"
+)
+
+// AttendanceHandler 考勤处理器
+type AttendanceHandler struct {
+	attendanceService *service.AttendanceService
+	validator         *validator.CustomValidator
+}
+
+// NewAttendanceHandler 创建新的考勤处理器
+func NewAttendanceHandler(attendanceService *service.AttendanceService, validator *validator.CustomValidator) *AttendanceHandler {
+	return &AttendanceHandler{
+		attendanceService: attendanceService,
+		validator:         validator,
+	}
+}
+
+// RecordAttendance 批量记录一次课的考勤
+// @Summary 批量记录考勤
+// @Description 一次提交整个班级一次课的考勤。考勤名单为已选上该开课的学生和上课行政班的学生，records 中只需列出非默认状态的学生，其余学生记为 default_status（默认出勤）；当天有已批准请假的学生未列出或记为旷课时改记为请假。按课表时段记录时节次和行政班取自课表，否则须指定开始节次；同一课次重复提交时覆盖原有考勤。教师只能记录本人任教课程的考勤
+// @Tags attendance
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param attendance body domain.RecordAttendanceRequest true "考勤信息"
+// @Success 200 {object} Response{data=domain.AttendanceSession}
+// @Failure 400 {object} ErrorResponse "请求参数错误或学生不在考勤名单中"
+// @Failure 403 {object} ErrorResponse "无权记录"
+// @Failure 404 {object} ErrorResponse "开课不存在"
+// @Failure 409 {object} ErrorResponse "学期已结束"
+// @Router /api/v1/attendance/sessions [post]
+func (h *AttendanceHandler) RecordAttendance(c *gin.Context) {
+	actor, ok := currentActor(c)
+	if !ok {
+		return
+	}
+
+	var req domain.RecordAttendanceRequest
+	if !bindJSON(c, h.validator, &req) {
+		return
+	}
+
+	session, err := h.attendanceService.RecordAttendance(actor, req)
+	if err != nil {
+		c.JSON(statusFromError(err, http.StatusInternalServerError), ErrorResponse{
+			Error:   "Failed to record attendance",
+			Message: err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, Response{
+		Code:    200,
+		Message: "考勤记录成功",
+		Data:    session,
+	})
+}
+
+// GetSessions 获取考勤课次列表
+// @Summary 获取考勤课次列表
+// @Description 分页获取考勤课次及各出勤状态的人数，可按学期、开课、行政班和日期范围筛选，按日期倒序
+// @Tags attendance
+// @Produce json
+// @Security BearerAuth
+// @Param page query int false "页码" default(1)
+// @Param size query int false "每页数量" default(10)
+// @Param semester query string false "学期"
+// @Param offering_id query int false "开课ID"
+// @Param class_id query int false "班级ID"
+// @Param date_from query string false "开始日期，如 2024-09-01"
+// @Param date_to query string false "结束日期，如 2024-09-30"
+// @Success 200 {object} PaginatedResponse
+// @Failure 400 {object} ErrorResponse "请求参数错误"
+// @Failure 403 {object} ErrorResponse "无权查看"
+// @Router /api/v1/attendance/sessions [get]
+func (h *AttendanceHandler) GetSessions(c *gin.Context) {
+	actor, ok := currentActor(c)
+	if !ok {
+		return
+	}
+
+	var req domain.AttendanceSessionListRequest
+	if !bindQuery(c, h.validator, &req) {
+		return
+	}
+
+	sessions, total, err := h.attendanceService.ListSessions(actor, req)
+	if err != nil {
+		c.JSON(statusFromError(err, http.StatusInternalServerError), ErrorResponse{
+			Error:   "Failed to get attendance sessions",
+			Message: err.Error(),
+		})
+		return
+	}
+
+	if sessions == nil {
+		sessions = []*domain.AttendanceSession{}
+	}
+
+	page, size := req.Page, req.Size
+	if page <= 0 {
+		page = 1
+	}
+	if size <= 0 {
+		size = 10
+	}
+
+	c.JSON(http.StatusOK, PaginatedResponse{
+		Code:    200,
+		Message: "获取考勤课次列表成功",
+		Data:    sessions,
+		Total:   int(total),
+		Page:    page,
+		Size:    size,
+	})
+}
+
+// GetSession 获取考勤课次详情
+// @Summary 获取考勤课次详情
+// @Description 获取考勤课次及每名学生的出勤状态
+// @Tags attendance
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "考勤课次ID"
+// @Success 200 {object} Response{data=domain.AttendanceSession}
+// @Failure 400 {object} ErrorResponse "请求参数错误"
+// @Failure 403 {object} ErrorResponse "无权查看"
+// @Failure 404 {object} ErrorResponse "考勤课次不存在"
+// @Router /api/v1/attendance/sessions/{id} [get]
+func (h *AttendanceHandler) GetSession(c *gin.Context) {
+	actor, ok := currentActor(c)
+	if !ok {
+		return
+	}
+
+	id, ok := parseAttendanceSessionID(c)
+	if !ok {
+		return
+	}
+
+	session, err := h.attendanceService.GetSession(actor, id)
+	if err != nil {
+		c.JSON(statusFromError(err, http.StatusInternalServerError), ErrorResponse{
+			Error:   "Failed to get attendance session",
+			Message: err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, Response{
+		Code:    200,
+		Message: "获取考勤课次成功",
+		Data:    session,
+	})
+}
+
+// DeleteSession 删除考勤课次
+// @Summary 删除考勤课次
+// @Description 删除考勤课次及其考勤记录，已结束学期的考勤不能修改
+// @Tags attendance
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "考勤课次ID"
+// @Success 200 {object} Response
+// @Failure 403 {object} ErrorResponse "无权删除"
+// @Failure 404 {object} ErrorResponse "考勤课次不存在"
+// @Failure 409 {object} ErrorResponse "学期已结束"
+// @Router /api/v1/attendance/sessions/{id} [delete]
+func (h *AttendanceHandler) DeleteSession(c *gin.Context) {
+	actor, ok := currentActor(c)
+	if !ok {
+		return
+	}
+
+	id, ok := parseAttendanceSessionID(c)
+	if !ok {
+		return
+	}
+
+	if err := h.attendanceService.DeleteSession(actor, id); err != nil {
+		c.JSON(statusFromError(err, http.StatusInternalServerError), ErrorResponse{
+			Error:   "Failed to delete attendance session",
+			Message: err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, Response{
+		Code:    200,
+		Message: "考勤课次删除成功",
+	})
+}
+
+// GetSummary 获取学期考勤汇总
+// @Summary 获取学期考勤汇总
+// @Description 分页获取学期内每名学生在每门开课的考勤汇总，默认当前学期。缺勤课次为旷课次数加迟到折算的次数（按配置可计入请假），缺勤率达到配置的阈值时预警或取消该科目期末考试资格；flag 可只看预警或取消考试资格的学生
+// @Tags attendance
+// @Produce json
+// @Security BearerAuth
+// @Param page query int false "页码" default(1)
+// @Param size query int false "每页数量" default(10)
+// @Param semester query string false "学期"
+// @Param offering_id query int false "开课ID"
+// @Param class_id query int false "学生当前所在班级ID"
+// @Param flag query string false "只看预警或取消考试资格的学生" Enums(at_risk, exam_blocked)
+// @Success 200 {object} PaginatedResponse
+// @Failure 400 {object} ErrorResponse "请求参数错误"
+// @Failure 403 {object} ErrorResponse "无权查看"
+// @Router /api/v1/attendance/summary [get]
+func (h *AttendanceHandler) GetSummary(c *gin.Context) {
+	actor, ok := currentActor(c)
+	if !ok {
+		return
+	}
+
+	var req domain.AttendanceSummaryRequest
+	if !bindQuery(c, h.validator, &req) {
+		return
+	}
+
+	summaries, total, err := h.attendanceService.GetSummary(actor, req)
+	if err != nil {
+		c.JSON(statusFromError(err, http.StatusInternalServerError), ErrorResponse{
+			Error:   "Failed to get attendance summary",
+			Message: err.Error(),
+		})
+		return
+	}
+
+	page, size := req.Page, req.Size
+	if page <= 0 {
+		page = 1
+	}
+	if size <= 0 {
+		size = 10
+	}
+
+	c.JSON(http.StatusOK, PaginatedResponse{
+		Code:    200,
+		Message: "获取考勤汇总成功",
+		Data:    summaries,
+		Total:   int(total),
+		Page:    page,
+		Size:    size,
+	})
+}
+
+// GetStudentAttendance 获取学生学期考勤汇总
+// @Summary 获取学生学期考勤汇总
+// @Description 获取学生某学期各门课程的考勤汇总和合计，默认当前学期。学生和家长只能查看本人（子女）的考勤
+// @Tags attendance
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "学生ID"
+// @Param semester query string false "学期"
+// @Success 200 {object} Response{data=domain.StudentAttendanceReport}
+// @Failure 400 {object} ErrorResponse "请求参数错误"
+// @Failure 403 {object} ErrorResponse "无权查看"
+// @Failure 404 {object} ErrorResponse "学生不存在"
+// @Router /api/v1/students/{id}/attendance [get]
+func (h *AttendanceHandler) GetStudentAttendance(c *gin.Context) {
+	actor, ok := currentActor(c)
+	if !ok {
+		return
+	}
+
+	studentID, err := strconv.Atoi(c.Param("id"))
+	if err != nil || studentID <= 0 {
+		c.JSON(http.StatusBadRequest, ErrorResponse{
+			Error:   "Invalid ID format",
+			Message: "学生ID格式错误",
+		})
+		return
+	}
+
+	var req domain.StudentAttendanceRequest
+	if !bindQuery(c, h.validator, &req) {
+		return
+	}
+
+	report, err := h.attendanceService.GetStudentAttendance(actor, studentID, req)
+	if err != nil {
+		c.JSON(statusFromError(err, http.StatusInternalServerError), ErrorResponse{
+			Error:   "Failed to get student attendance",
+			Message: err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, Response{
+		Code:    200,
+		Message: "获取学生考勤成功",
+		Data:    report,
+	})
+}
+
+// parseAttendanceSessionID 解析路径中的考勤课次ID，失败时已写入响应
+func parseAttendanceSessionID(c *gin.Context) (int, bool) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil || id <= 0 {
+		c.JSON(http.StatusBadRequest, ErrorResponse{
+			Error:   "Invalid ID format",
+			Message: "考勤课次ID格式错误",
+		})
+		return 0, false
+	}
+	return id, true
+}
@@ -6,6 +6,7 @@ import (
 	"student-management-system/internal/domain"
 	"student-management-system/internal/service"
 	"student-management-system/pkg/errors"
+	"student-management-system/pkg/middleware"
 	"student-management-system/pkg/utils"
 
 	"github.com/gin-gonic/gin"
@@ -25,12 +26,13 @@ func NewAuthHandler(authService *service.AuthService) *AuthHandler {
 
 // Login 管理员登录
 // @Summary 管理员登录
-// @Description 使用用户名和密码进行管理员登录，返回JWT token
+// @Description 使用用户名和密码进行管理员登录，返回JWT token。账号启用了两步验证时返回两步验证挑战，需调用 /auth/login/2fa 完成登录
 // @Tags 认证
 // @Accept json
 // @Produce json
 // @Param login body domain.LoginRequest true "登录信息"
 // @Success 200 {object} domain.LoginResponse "登录成功"
+// @Success 202 {object} domain.TwoFactorChallenge "需要两步验证"
 // @Failure 400 {object} ErrorResponse "请求参数错误"
 // @Failure 401 {object} ErrorResponse "用户名或密码错误"
 // @Failure 500 {object} ErrorResponse "服务器内部错误"
@@ -45,8 +47,12 @@ func (h *AuthHandler) Login(c *gin.Context) {
 		return
 	}
 
-	// 执行登录
-	response, err := h.authService.Login(&req)
+	// 执行登录，记录客户端信息用于会话管理
+	meta := domain.SessionMeta{
+		IP:        c.ClientIP(),
+		UserAgent: c.Request.UserAgent(),
+	}
+	response, challenge, err := h.authService.Login(&req, meta)
 	if err != nil {
 		if err == errors.ErrInvalidCredentials {
 			c.JSON(http.StatusUnauthorized, ErrorResponse{
@@ -63,6 +69,49 @@ func (h *AuthHandler) Login(c *gin.Context) {
 		return
 	}
 
+	if challenge != nil {
+		c.JSON(http.StatusAccepted, challenge)
+		return
+	}
+
+	c.JSON(http.StatusOK, response)
+}
+
+// LoginTwoFactor 两步验证登录
+// @Summary 两步验证登录
+// @Description 提交登录返回的挑战token和认证器验证码（或一次性恢复码）完成登录。挑战要求绑定时只接受验证码，成功后响应中包含恢复码
+// @Tags 认证
+// @Accept json
+// @Produce json
+// @Param login body domain.TwoFactorLoginRequest true "两步验证信息"
+// @Success 200 {object} domain.LoginResponse "登录成功"
+// @Failure 400 {object} ErrorResponse "请求参数错误"
+// @Failure 401 {object} ErrorResponse "验证码错误或挑战已过期"
+// @Failure 500 {object} ErrorResponse "服务器内部错误"
+// @Router /api/v1/auth/login/2fa [post]
+func (h *AuthHandler) LoginTwoFactor(c *gin.Context) {
+	var req domain.TwoFactorLoginRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{
+			Error:   "Invalid request format",
+			Message: err.Error(),
+		})
+		return
+	}
+
+	meta := domain.SessionMeta{
+		IP:        c.ClientIP(),
+		UserAgent: c.Request.UserAgent(),
+	}
+	response, err := h.authService.LoginTwoFactor(&req, meta)
+	if err != nil {
+		c.JSON(statusFromError(err, http.StatusUnauthorized), ErrorResponse{
+			Error:   "Authentication failed",
+			Message: err.Error(),
+		})
+		return
+	}
+
 	c.JSON(http.StatusOK, response)
 }
 
@@ -101,42 +150,40 @@ func (h *AuthHandler) GetProfile(c *gin.Context) {
 	c.JSON(http.StatusOK, adminInfo)
 }
 
-// RefreshToken 刷新JWT token
+// RefreshToken 使用刷新token换取新的访问token
 // @Summary 刷新JWT token
-// @Description 使用当前有效的JWT token获取新的token
+// @Description 使用登录时返回的刷新token换取新的访问token和刷新token。刷新token只能使用一次，已使用过的刷新token再次提交会撤销整个会话
 // @Tags 认证
 // @Accept json
 // @Produce json
-// @Security BearerAuth
+// @Param refresh body domain.RefreshTokenRequest true "刷新token"
 // @Success 200 {object} domain.LoginResponse "新的token信息"
-// @Failure 400 {object} ErrorResponse "token不需要刷新"
-// @Failure 401 {object} ErrorResponse "未授权"
+// @Failure 400 {object} ErrorResponse "请求参数错误"
+// @Failure 401 {object} ErrorResponse "刷新token无效、已过期或已被使用"
 // @Failure 500 {object} ErrorResponse "服务器内部错误"
 // @Router /api/v1/auth/refresh [post]
 func (h *AuthHandler) RefreshToken(c *gin.Context) {
-	// 从上下文中获取JWT claims
-	claims, exists := c.Get("claims")
-	if !exists {
-		c.JSON(http.StatusUnauthorized, ErrorResponse{
-			Error:   "Unauthorized",
-			Message: "Invalid or missing token",
+	var req domain.RefreshTokenRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{
+			Error:   "Invalid request format",
+			Message: err.Error(),
 		})
 		return
 	}
 
-	jwtClaims, ok := claims.(*domain.JWTClaims)
-	if !ok {
-		c.JSON(http.StatusInternalServerError, ErrorResponse{
-			Error:   "Internal error",
-			Message: "Invalid token claims",
+	if req.RefreshToken == "" {
+		c.JSON(http.StatusBadRequest, ErrorResponse{
+			Error:   "Invalid request format",
+			Message: "refresh_token is required",
 		})
 		return
 	}
 
 	// 刷新token
-	response, err := h.authService.RefreshToken(jwtClaims)
+	response, err := h.authService.RefreshToken(req.RefreshToken)
 	if err != nil {
-		c.JSON(http.StatusBadRequest, ErrorResponse{
+		c.JSON(statusFromError(err, http.StatusInternalServerError), ErrorResponse{
 			Error:   "Refresh failed",
 			Message: err.Error(),
 		})
@@ -146,6 +193,18 @@ func (h *AuthHandler) RefreshToken(c *gin.Context) {
 	c.JSON(http.StatusOK, response)
 }
 
+// JWKS 获取JWT公钥集合
+// @Summary 获取JWT公钥集合
+// @Description 以JWKS格式返回当前全部验证公钥，其他服务可据此按token header中的kid验证签名。使用HS256共享密钥时返回空集合
+// @Tags 认证
+// @Produce json
+// @Success 200 {object} utils.JWKSet "公钥集合"
+// @Router /.well-known/jwks.json [get]
+func (h *AuthHandler) JWKS(c *gin.Context) {
+	c.Header("Cache-Control", "public, max-age=300")
+	c.JSON(http.StatusOK, h.authService.JWKS())
+}
+
 // ValidateToken 验证token（用于其他服务调用）
 // @Summary 验证JWT token
 // @Description 验证JWT token的有效性
@@ -185,7 +244,7 @@ func (h *AuthHandler) ValidateToken(c *gin.Context) {
 
 // Logout 用户登出
 // @Summary 用户登出
-// @Description 使当前用户的JWT token失效
+// @Description 使当前会话的JWT token失效，其他设备上的会话不受影响
 // @Tags 认证
 // @Accept json
 // @Produce json
@@ -214,8 +273,8 @@ func (h *AuthHandler) Logout(c *gin.Context) {
 		return
 	}
 
-	// 使token失效
-	err := h.authService.Logout(jwtClaims.AdminID)
+	// 使当前会话失效
+	err := h.authService.Logout(jwtClaims)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, ErrorResponse{
 			Error:   "Logout failed",
@@ -228,3 +287,102 @@ func (h *AuthHandler) Logout(c *gin.Context) {
 		"message": "Logout successful",
 	})
 }
+
+// ListSessions 获取当前账号的会话列表
+// @Summary 获取我的会话列表
+// @Description 列出当前账号在各设备上的有效会话，current 标记发起请求的会话
+// @Tags 认证
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {array} domain.Session "会话列表"
+// @Failure 401 {object} ErrorResponse "未授权"
+// @Failure 500 {object} ErrorResponse "服务器内部错误"
+// @Router /api/v1/auth/sessions [get]
+func (h *AuthHandler) ListSessions(c *gin.Context) {
+	claims, ok := middleware.GetCurrentAdmin(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, ErrorResponse{
+			Error:   "Unauthorized",
+			Message: "Invalid or missing token",
+		})
+		return
+	}
+
+	sessions, err := h.authService.ListSessions(claims)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, ErrorResponse{
+			Error:   "Failed to list sessions",
+			Message: err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, sessions)
+}
+
+// RevokeSession 撤销当前账号的指定会话
+// @Summary 撤销会话
+// @Description 使当前账号的指定会话失效
+// @Tags 认证
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "会话ID"
+// @Success 200 {object} map[string]string "撤销成功"
+// @Failure 401 {object} ErrorResponse "未授权"
+// @Failure 404 {object} ErrorResponse "会话不存在"
+// @Failure 500 {object} ErrorResponse "服务器内部错误"
+// @Router /api/v1/auth/sessions/{id} [delete]
+func (h *AuthHandler) RevokeSession(c *gin.Context) {
+	claims, ok := middleware.GetCurrentAdmin(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, ErrorResponse{
+			Error:   "Unauthorized",
+			Message: "Invalid or missing token",
+		})
+		return
+	}
+
+	if err := h.authService.RevokeSession(claims, c.Param("id")); err != nil {
+		c.JSON(statusFromError(err, http.StatusInternalServerError), ErrorResponse{
+			Error:   "Failed to revoke session",
+			Message: err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, map[string]string{
+		"message": "Session revoked",
+	})
+}
+
+// RevokeOtherSessions 撤销当前账号的其他会话
+// @Summary 撤销其他会话
+// @Description 保留当前会话，使当前账号在其他设备上的会话全部失效
+// @Tags 认证
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} domain.RevokeSessionsResponse "撤销的会话数量"
+// @Failure 401 {object} ErrorResponse "未授权"
+// @Failure 500 {object} ErrorResponse "服务器内部错误"
+// @Router /api/v1/auth/sessions [delete]
+func (h *AuthHandler) RevokeOtherSessions(c *gin.Context) {
+	claims, ok := middleware.GetCurrentAdmin(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, ErrorResponse{
+			Error:   "Unauthorized",
+			Message: "Invalid or missing token",
+		})
+		return
+	}
+
+	revoked, err := h.authService.RevokeOtherSessions(claims)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, ErrorResponse{
+			Error:   "Failed to revoke sessions",
+			Message: err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, domain.RevokeSessionsResponse{Revoked: revoked})
+}
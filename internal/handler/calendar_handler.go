@@ -0,0 +1,169 @@
+package handler
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+
+	"student-management-system/internal/domain"
+	"student-management-system/internal/service"
+	"student-management-system/pkg/validator"
+
+	"github.com/gin-gonic/gin"
+)
+
+// CalendarHandler 日历订阅处理器
+type CalendarHandler struct {
+	calendarService *service.CalendarService
+	validator       *validator.CustomValidator
+}
+
+// NewCalendarHandler 创建新的日历订阅处理器
+func NewCalendarHandler(calendarService *service.CalendarService, validator *validator.CustomValidator) *CalendarHandler {
+	return &CalendarHandler{
+		calendarService: calendarService,
+		validator:       validator,
+	}
+}
+
+// CreateFeed 创建日历订阅
+// @Summary 创建日历订阅
+// @Description 为教师、行政班或学生创建只读的 iCalendar 订阅地址，包含每周课程和考试安排。订阅令牌只在创建时返回一次，与登录token相互独立，可以随时撤销。教师只能订阅本人和班级的日历，学生和家长只能订阅本人（子女）的日历
+// @Tags calendar
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param feed body domain.CreateCalendarFeedRequest true "订阅对象"
+// @Success 201 {object} Response{data=domain.CalendarFeed}
+// @Failure 400 {object} ErrorResponse "请求参数错误"
+// @Failure 403 {object} ErrorResponse "无权订阅"
+// @Failure 404 {object} ErrorResponse "订阅对象不存在"
+// @Router /api/v1/calendar/feeds [post]
+func (h *CalendarHandler) CreateFeed(c *gin.Context) {
+	actor, ok := currentActor(c)
+	if !ok {
+		return
+	}
+
+	var req domain.CreateCalendarFeedRequest
+	if !bindJSON(c, h.validator, &req) {
+		return
+	}
+
+	feed, err := h.calendarService.CreateFeed(actor, req)
+	if err != nil {
+		c.JSON(statusFromError(err, http.StatusInternalServerError), ErrorResponse{
+			Error:   "Failed to create calendar feed",
+			Message: err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusCreated, Response{
+		Code:    201,
+		Message: "日历订阅创建成功，请妥善保存订阅地址",
+		Data:    feed,
+	})
+}
+
+// GetFeeds 获取我的日历订阅
+// @Summary 获取我的日历订阅
+// @Description 获取当前账号创建的日历订阅，包括已撤销的订阅。列表中不包含订阅令牌
+// @Tags calendar
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} Response{data=[]domain.CalendarFeed}
+// @Failure 401 {object} ErrorResponse "未认证"
+// @Router /api/v1/calendar/feeds [get]
+func (h *CalendarHandler) GetFeeds(c *gin.Context) {
+	actor, ok := currentActor(c)
+	if !ok {
+		return
+	}
+
+	feeds, err := h.calendarService.ListFeeds(actor)
+	if err != nil {
+		c.JSON(statusFromError(err, http.StatusInternalServerError), ErrorResponse{
+			Error:   "Failed to get calendar feeds",
+			Message: err.Error(),
+		})
+		return
+	}
+
+	if feeds == nil {
+		feeds = []*domain.CalendarFeed{}
+	}
+
+	c.JSON(http.StatusOK, Response{
+		Code:    200,
+		Message: "获取日历订阅成功",
+		Data:    feeds,
+	})
+}
+
+// RevokeFeed 撤销日历订阅
+// @Summary 撤销日历订阅
+// @Description 撤销本人创建的日历订阅，撤销后订阅地址立即失效。管理员可以撤销任何订阅
+// @Tags calendar
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "日历订阅ID"
+// @Success 200 {object} Response
+// @Failure 400 {object} ErrorResponse "请求参数错误"
+// @Failure 403 {object} ErrorResponse "无权撤销"
+// @Failure 404 {object} ErrorResponse "日历订阅不存在"
+// @Router /api/v1/calendar/feeds/{id} [delete]
+func (h *CalendarHandler) RevokeFeed(c *gin.Context) {
+	actor, ok := currentActor(c)
+	if !ok {
+		return
+	}
+
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil || id <= 0 {
+		c.JSON(http.StatusBadRequest, ErrorResponse{
+			Error:   "Invalid ID format",
+			Message: "日历订阅ID格式错误",
+		})
+		return
+	}
+
+	if err := h.calendarService.RevokeFeed(actor, id); err != nil {
+		c.JSON(statusFromError(err, http.StatusInternalServerError), ErrorResponse{
+			Error:   "Failed to revoke calendar feed",
+			Message: err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, Response{
+		Code:    200,
+		Message: "日历订阅已撤销",
+	})
+}
+
+// GetFeed 获取 iCalendar 订阅内容
+// @Summary 获取 iCalendar 订阅内容
+// @Description 日历客户端凭订阅地址中的令牌获取课程和考试日历，无需登录。每周课程使用 RRULE 重复规则，节假日作为 EXDATE 例外日期，考试为单次事件
+// @Tags calendar
+// @Produce text/calendar
+// @Param token path string true "订阅令牌，可带 .ics 后缀"
+// @Success 200 {string} string "iCalendar 内容"
+// @Failure 404 {object} ErrorResponse "订阅不存在或已撤销"
+// @Router /api/v1/calendar/ics/{token} [get]
+func (h *CalendarHandler) GetFeed(c *gin.Context) {
+	token := strings.TrimSuffix(c.Param("token"), ".ics")
+
+	data, err := h.calendarService.RenderFeed(token)
+	if err != nil {
+		c.JSON(statusFromError(err, http.StatusInternalServerError), ErrorResponse{
+			Error:   "Failed to get calendar feed",
+			Message: err.Error(),
+		})
+		return
+	}
+
+	c.Header("Content-Disposition", `inline; filename="calendar.ics"`)
+	c.Header("Cache-Control", "private, no-cache")
+	c.Data(http.StatusOK, "text/calendar; charset=utf-8", data)
+}
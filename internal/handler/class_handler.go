@@ -0,0 +1,472 @@
+package handler
+
+import (
+	"net/http"
+	"strconv"
+
+	"student-management-system/internal/domain"
+	"student-management-system/internal/service"
+	"student-management-system/pkg/middleware"
+	"student-management-system/pkg/validator"
+
+	"github.com/gin-gonic/gin"
+)
+
+// ClassHandler 班级处理器
+type ClassHandler struct {
+	classService *service.ClassService
+	scoreService service.ScoreService
+	validator    *validator.CustomValidator
+}
+
+// NewClassHandler 创建新的班级处理器
+func NewClassHandler(classService *service.ClassService, scoreService service.ScoreService, validator *validator.CustomValidator) *ClassHandler {
+	return &ClassHandler{
+		classService: classService,
+		scoreService: scoreService,
+		validator:    validator,
+	}
+}
+
+// CreateClass 创建班级
+// @Summary 创建班级
+// @Description 创建行政班，同一年级下班级名称不能重复
+// @Tags classes
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param class body domain.CreateClassRequest true "班级信息"
+// @Success 201 {object} Response{data=domain.Class}
+// @Failure 400 {object} ErrorResponse "请求参数错误"
+// @Failure 409 {object} ErrorResponse "班级名称重复"
+// @Failure 500 {object} ErrorResponse "服务器内部错误"
+// @Router /api/v1/classes [post]
+func (h *ClassHandler) CreateClass(c *gin.Context) {
+	var req domain.CreateClassRequest
+	if !bindJSON(c, h.validator, &req) {
+		return
+	}
+
+	class, err := h.classService.CreateClass(req)
+	if err != nil {
+		c.JSON(statusFromError(err, http.StatusInternalServerError), ErrorResponse{
+			Error:   "Failed to create class",
+			Message: err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusCreated, Response{
+		Code:    201,
+		Message: "班级创建成功",
+		Data:    class,
+	})
+}
+
+// GetClasses 获取班级列表
+// @Summary 获取班级列表
+// @Description 分页获取班级列表，可按名称、年级、专业、班主任和状态筛选
+// @Tags classes
+// @Produce json
+// @Security BearerAuth
+// @Param page query int false "页码" default(1)
+// @Param size query int false "每页数量" default(10)
+// @Param name query string false "班级名称（模糊匹配）"
+// @Param grade_year query int false "年级（入学年份）"
+// @Param major query string false "专业"
+// @Param homeroom_teacher_id query int false "班主任ID"
+// @Param status query string false "状态" Enums(active, archived)
+// @Success 200 {object} PaginatedResponse
+// @Failure 400 {object} ErrorResponse "请求参数错误"
+// @Failure 500 {object} ErrorResponse "服务器内部错误"
+// @Router /api/v1/classes [get]
+func (h *ClassHandler) GetClasses(c *gin.Context) {
+	var req domain.ClassListRequest
+	if !bindQuery(c, h.validator, &req) {
+		return
+	}
+
+	classes, total, err := h.classService.ListClasses(req)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, ErrorResponse{
+			Error:   "Failed to get classes",
+			Message: err.Error(),
+		})
+		return
+	}
+
+	if classes == nil {
+		classes = []*domain.Class{}
+	}
+
+	page, size := req.Page, req.Size
+	if page <= 0 {
+		page = 1
+	}
+	if size <= 0 {
+		size = 10
+	}
+
+	c.JSON(http.StatusOK, PaginatedResponse{
+		Code:    200,
+		Message: "获取班级列表成功",
+		Data:    classes,
+		Total:   int(total),
+		Page:    page,
+		Size:    size,
+	})
+}
+
+// GetClass 获取单个班级
+// @Summary 获取班级详情
+// @Description 根据ID获取班级信息，包括班主任和当前在班人数
+// @Tags classes
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "班级ID"
+// @Success 200 {object} Response{data=domain.Class}
+// @Failure 400 {object} ErrorResponse "请求参数错误"
+// @Failure 404 {object} ErrorResponse "班级不存在"
+// @Router /api/v1/classes/{id} [get]
+func (h *ClassHandler) GetClass(c *gin.Context) {
+	id, ok := parseClassID(c)
+	if !ok {
+		return
+	}
+
+	class, err := h.classService.GetClass(id)
+	if err != nil {
+		c.JSON(statusFromError(err, http.StatusInternalServerError), ErrorResponse{
+			Error:   "Failed to get class",
+			Message: err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, Response{
+		Code:    200,
+		Message: "获取班级成功",
+		Data:    class,
+	})
+}
+
+// UpdateClass 更新班级
+// @Summary 更新班级
+// @Description 更新班级信息，homeroom_teacher_id 传 0 表示取消班主任
+// @Tags classes
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "班级ID"
+// @Param class body domain.UpdateClassRequest true "更新的班级信息"
+// @Success 200 {object} Response{data=domain.Class}
+// @Failure 400 {object} ErrorResponse "请求参数错误"
+// @Failure 404 {object} ErrorResponse "班级不存在"
+// @Failure 409 {object} ErrorResponse "班级名称重复"
+// @Router /api/v1/classes/{id} [put]
+func (h *ClassHandler) UpdateClass(c *gin.Context) {
+	id, ok := parseClassID(c)
+	if !ok {
+		return
+	}
+
+	var req domain.UpdateClassRequest
+	if !bindJSON(c, h.validator, &req) {
+		return
+	}
+
+	class, err := h.classService.UpdateClass(id, req)
+	if err != nil {
+		c.JSON(statusFromError(err, http.StatusInternalServerError), ErrorResponse{
+			Error:   "Failed to update class",
+			Message: err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, Response{
+		Code:    200,
+		Message: "班级更新成功",
+		Data:    class,
+	})
+}
+
+// DeleteClass 删除班级
+// @Summary 删除班级
+// @Description 删除没有任何分班记录的班级，已有分班记录的班级请改为归档
+// @Tags classes
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "班级ID"
+// @Success 200 {object} Response
+// @Failure 404 {object} ErrorResponse "班级不存在"
+// @Failure 409 {object} ErrorResponse "班级存在分班记录"
+// @Router /api/v1/classes/{id} [delete]
+func (h *ClassHandler) DeleteClass(c *gin.Context) {
+	id, ok := parseClassID(c)
+	if !ok {
+		return
+	}
+
+	if err := h.classService.DeleteClass(id); err != nil {
+		c.JSON(statusFromError(err, http.StatusInternalServerError), ErrorResponse{
+			Error:   "Failed to delete class",
+			Message: err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, Response{
+		Code:    200,
+		Message: "班级删除成功",
+	})
+}
+
+// GetClassStudents 获取班级学生
+// @Summary 获取班级学生
+// @Description 获取班级当前的学生名单
+// @Tags classes
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "班级ID"
+// @Success 200 {object} Response{data=[]domain.Student}
+// @Failure 404 {object} ErrorResponse "班级不存在"
+// @Router /api/v1/classes/{id}/students [get]
+func (h *ClassHandler) GetClassStudents(c *gin.Context) {
+	id, ok := parseClassID(c)
+	if !ok {
+		return
+	}
+
+	students, err := h.classService.ListClassStudents(id)
+	if err != nil {
+		c.JSON(statusFromError(err, http.StatusInternalServerError), ErrorResponse{
+			Error:   "Failed to get class students",
+			Message: err.Error(),
+		})
+		return
+	}
+
+	if students == nil {
+		students = []*domain.Student{}
+	}
+
+	c.JSON(http.StatusOK, Response{
+		Code:    200,
+		Message: "获取成功",
+		Data:    students,
+	})
+}
+
+// AssignStudents 学生分班
+// @Summary 学生分班
+// @Description 将学生分入班级。已在其他班级的学生会转入本班，原班级记录结束并保留在分班历史中
+// @Tags classes
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "班级ID"
+// @Param request body domain.AssignClassStudentsRequest true "分班信息"
+// @Success 200 {object} Response{data=[]domain.Student} "班级当前的学生名单"
+// @Failure 400 {object} ErrorResponse "请求参数错误或学生不存在"
+// @Failure 404 {object} ErrorResponse "班级不存在"
+// @Failure 409 {object} ErrorResponse "班级已归档"
+// @Router /api/v1/classes/{id}/students [post]
+func (h *ClassHandler) AssignStudents(c *gin.Context) {
+	actor, ok := middleware.GetCurrentAdmin(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, ErrorResponse{
+			Error:   "Unauthorized",
+			Message: "Invalid or missing token",
+		})
+		return
+	}
+
+	id, ok := parseClassID(c)
+	if !ok {
+		return
+	}
+
+	var req domain.AssignClassStudentsRequest
+	if !bindJSON(c, h.validator, &req) {
+		return
+	}
+
+	students, err := h.classService.AssignStudents(actor, id, req)
+	if err != nil {
+		c.JSON(statusFromError(err, http.StatusInternalServerError), ErrorResponse{
+			Error:   "Failed to assign students",
+			Message: err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, Response{
+		Code:    200,
+		Message: "分班成功",
+		Data:    students,
+	})
+}
+
+// RemoveStudent 学生离班
+// @Summary 学生离班
+// @Description 将学生移出班级，分班记录保留在历史中
+// @Tags classes
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "班级ID"
+// @Param student_id path int true "学生ID"
+// @Param reason query string false "离班原因"
+// @Success 200 {object} Response
+// @Failure 404 {object} ErrorResponse "班级不存在或学生不在该班级"
+// @Router /api/v1/classes/{id}/students/{student_id} [delete]
+func (h *ClassHandler) RemoveStudent(c *gin.Context) {
+	id, ok := parseClassID(c)
+	if !ok {
+		return
+	}
+
+	studentID, err := strconv.Atoi(c.Param("student_id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{
+			Error:   "Invalid student ID",
+			Message: "学生ID必须是数字",
+		})
+		return
+	}
+
+	var req domain.RemoveClassStudentRequest
+	if !bindQuery(c, h.validator, &req) {
+		return
+	}
+
+	if err := h.classService.RemoveStudent(id, studentID, req.Reason); err != nil {
+		c.JSON(statusFromError(err, http.StatusInternalServerError), ErrorResponse{
+			Error:   "Failed to remove student",
+			Message: err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, Response{
+		Code:    200,
+		Message: "学生已移出班级",
+	})
+}
+
+// GetClassStatistics 获取班级成绩统计
+// @Summary 获取班级成绩统计
+// @Description 按科目、学期、考试类型统计班级学生的平均分、最高最低分、及格率和优秀率，学生按成绩所在学期结束时所在的班级计入
+// @Tags classes
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "班级ID"
+// @Param subject_id query int false "科目ID"
+// @Param semester query string false "学期"
+// @Param exam_type query string false "考试类型" Enums(midterm, final, quiz, assignment)
+// @Success 200 {object} Response{data=[]domain.ClassScoreStatistics}
+// @Failure 400 {object} ErrorResponse "请求参数错误"
+// @Failure 404 {object} ErrorResponse "班级不存在"
+// @Router /api/v1/classes/{id}/statistics [get]
+func (h *ClassHandler) GetClassStatistics(c *gin.Context) {
+	id, ok := parseClassID(c)
+	if !ok {
+		return
+	}
+
+	var req domain.ScoreStatisticsRequest
+	if !bindQuery(c, h.validator, &req) {
+		return
+	}
+	req.ClassID = id
+
+	if _, err := h.classService.GetClass(id); err != nil {
+		c.JSON(statusFromError(err, http.StatusInternalServerError), ErrorResponse{
+			Error:   "Failed to get class",
+			Message: err.Error(),
+		})
+		return
+	}
+
+	statistics, err := h.scoreService.GetClassStatistics(&req)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, ErrorResponse{
+			Error:   "Failed to get class statistics",
+			Message: err.Error(),
+		})
+		return
+	}
+
+	if statistics == nil {
+		statistics = []*domain.ClassScoreStatistics{}
+	}
+
+	c.JSON(http.StatusOK, Response{
+		Code:    200,
+		Message: "获取成功",
+		Data:    statistics,
+	})
+}
+
+// GetStudentClasses 获取学生分班历史
+// @Summary 获取学生分班历史
+// @Description 获取学生的分班记录，left_at 为空的记录为当前所在班级。学生和家长只能查看本人（子女）
+// @Tags students
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "学生ID"
+// @Success 200 {object} Response{data=[]domain.ClassMembership}
+// @Failure 400 {object} ErrorResponse "请求参数错误"
+// @Failure 403 {object} ErrorResponse "无权访问"
+// @Router /api/v1/students/{id}/classes [get]
+func (h *ClassHandler) GetStudentClasses(c *gin.Context) {
+	actor, ok := middleware.GetCurrentAdmin(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, ErrorResponse{
+			Error:   "Unauthorized",
+			Message: "Invalid or missing token",
+		})
+		return
+	}
+
+	studentID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{
+			Error:   "Invalid student ID",
+			Message: "学生ID必须是数字",
+		})
+		return
+	}
+
+	memberships, err := h.classService.ListStudentClasses(actor, studentID)
+	if err != nil {
+		c.JSON(statusFromError(err, http.StatusInternalServerError), ErrorResponse{
+			Error:   "Failed to get student classes",
+			Message: err.Error(),
+		})
+		return
+	}
+
+	if memberships == nil {
+		memberships = []*domain.ClassMembership{}
+	}
+
+	c.JSON(http.StatusOK, Response{
+		Code:    200,
+		Message: "获取成功",
+		Data:    memberships,
+	})
+}
+
+// parseClassID 解析路径中的班级ID，失败时已写入响应
+func parseClassID(c *gin.Context) (int, bool) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil || id <= 0 {
+		c.JSON(http.StatusBadRequest, ErrorResponse{
+			Error:   "Invalid ID format",
+			Message: "班级ID格式错误",
+		})
+		return 0, false
+	}
+	return id, true
+}
@@ -0,0 +1,448 @@
+package handler
+
+import (
+	"net/http"
+	"strconv"
+
+	"student-management-system/internal/domain"
+	"student-management-system/internal/service"
+	"student-management-system/pkg/middleware"
+	"student-management-system/pkg/validator"
+
+	"github.com/gin-gonic/gin"
+)
+
+// CourseOfferingHandler 开课与选课处理器
+type CourseOfferingHandler struct {
+	offeringService *service.CourseOfferingService
+	validator       *validator.CustomValidator
+}
+
+// NewCourseOfferingHandler 创建新的开课处理器
+func NewCourseOfferingHandler(offeringService *service.CourseOfferingService, validator *validator.CustomValidator) *CourseOfferingHandler {
+	return &CourseOfferingHandler{
+		offeringService: offeringService,
+		validator:       validator,
+	}
+}
+
+// CreateOffering 创建开课
+// @Summary 创建开课
+// @Description 为某学期的科目开设教学班，指定任课老师、容量、候补容量、上课安排和加退选截止时间
+// @Tags course-offerings
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param offering body domain.CreateCourseOfferingRequest true "开课信息"
+// @Success 201 {object} Response{data=domain.CourseOffering}
+// @Failure 400 {object} ErrorResponse "请求参数错误"
+// @Failure 409 {object} ErrorResponse "教学班号重复"
+// @Failure 500 {object} ErrorResponse "服务器内部错误"
+// @Router /api/v1/course-offerings [post]
+func (h *CourseOfferingHandler) CreateOffering(c *gin.Context) {
+	var req domain.CreateCourseOfferingRequest
+	if !bindJSON(c, h.validator, &req) {
+		return
+	}
+
+	offering, err := h.offeringService.CreateOffering(req)
+	if err != nil {
+		c.JSON(statusFromError(err, http.StatusInternalServerError), ErrorResponse{
+			Error:   "Failed to create course offering",
+			Message: err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusCreated, Response{
+		Code:    201,
+		Message: "开课创建成功",
+		Data:    offering,
+	})
+}
+
+// GetOfferings 获取开课列表
+// @Summary 获取开课列表
+// @Description 分页获取开课列表，可按科目、学期、任课老师和状态筛选
+// @Tags course-offerings
+// @Produce json
+// @Security BearerAuth
+// @Param page query int false "页码" default(1)
+// @Param size query int false "每页数量" default(10)
+// @Param subject_id query int false "科目ID"
+// @Param semester query string false "学期"
+// @Param teacher_id query int false "任课老师ID"
+// @Param status query string false "状态" Enums(open, closed, cancelled)
+// @Success 200 {object} PaginatedResponse
+// @Failure 400 {object} ErrorResponse "请求参数错误"
+// @Failure 500 {object} ErrorResponse "服务器内部错误"
+// @Router /api/v1/course-offerings [get]
+func (h *CourseOfferingHandler) GetOfferings(c *gin.Context) {
+	var req domain.CourseOfferingListRequest
+	if !bindQuery(c, h.validator, &req) {
+		return
+	}
+
+	offerings, total, err := h.offeringService.ListOfferings(req)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, ErrorResponse{
+			Error:   "Failed to get course offerings",
+			Message: err.Error(),
+		})
+		return
+	}
+
+	if offerings == nil {
+		offerings = []*domain.CourseOffering{}
+	}
+
+	page, size := req.Page, req.Size
+	if page <= 0 {
+		page = 1
+	}
+	if size <= 0 {
+		size = 10
+	}
+
+	c.JSON(http.StatusOK, PaginatedResponse{
+		Code:    200,
+		Message: "获取开课列表成功",
+		Data:    offerings,
+		Total:   int(total),
+		Page:    page,
+		Size:    size,
+	})
+}
+
+// GetOffering 获取单个开课
+// @Summary 获取开课详情
+// @Description 根据ID获取开课信息，包括已选和候补人数
+// @Tags course-offerings
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "开课ID"
+// @Success 200 {object} Response{data=domain.CourseOffering}
+// @Failure 400 {object} ErrorResponse "请求参数错误"
+// @Failure 404 {object} ErrorResponse "开课不存在"
+// @Router /api/v1/course-offerings/{id} [get]
+func (h *CourseOfferingHandler) GetOffering(c *gin.Context) {
+	id, ok := parseOfferingID(c)
+	if !ok {
+		return
+	}
+
+	offering, err := h.offeringService.GetOffering(id)
+	if err != nil {
+		c.JSON(statusFromError(err, http.StatusInternalServerError), ErrorResponse{
+			Error:   "Failed to get course offering",
+			Message: err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, Response{
+		Code:    200,
+		Message: "获取开课成功",
+		Data:    offering,
+	})
+}
+
+// UpdateOffering 更新开课
+// @Summary 更新开课
+// @Description 更新开课信息，teacher_id 传 0 表示取消任课老师。扩容后候补学生按顺序自动递补
+// @Tags course-offerings
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "开课ID"
+// @Param offering body domain.UpdateCourseOfferingRequest true "更新的开课信息"
+// @Success 200 {object} Response{data=domain.CourseOffering}
+// @Failure 400 {object} ErrorResponse "请求参数错误"
+// @Failure 404 {object} ErrorResponse "开课不存在"
+// @Failure 409 {object} ErrorResponse "教学班号重复或容量小于已选人数"
+// @Router /api/v1/course-offerings/{id} [put]
+func (h *CourseOfferingHandler) UpdateOffering(c *gin.Context) {
+	id, ok := parseOfferingID(c)
+	if !ok {
+		return
+	}
+
+	var req domain.UpdateCourseOfferingRequest
+	if !bindJSON(c, h.validator, &req) {
+		return
+	}
+
+	offering, err := h.offeringService.UpdateOffering(id, req)
+	if err != nil {
+		c.JSON(statusFromError(err, http.StatusInternalServerError), ErrorResponse{
+			Error:   "Failed to update course offering",
+			Message: err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, Response{
+		Code:    200,
+		Message: "开课更新成功",
+		Data:    offering,
+	})
+}
+
+// DeleteOffering 删除开课
+// @Summary 删除开课
+// @Description 删除没有选课记录和成绩的开课，否则请将状态改为 cancelled
+// @Tags course-offerings
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "开课ID"
+// @Success 200 {object} Response
+// @Failure 404 {object} ErrorResponse "开课不存在"
+// @Failure 409 {object} ErrorResponse "开课存在选课记录或成绩"
+// @Router /api/v1/course-offerings/{id} [delete]
+func (h *CourseOfferingHandler) DeleteOffering(c *gin.Context) {
+	id, ok := parseOfferingID(c)
+	if !ok {
+		return
+	}
+
+	if err := h.offeringService.DeleteOffering(id); err != nil {
+		c.JSON(statusFromError(err, http.StatusInternalServerError), ErrorResponse{
+			Error:   "Failed to delete course offering",
+			Message: err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, Response{
+		Code:    200,
+		Message: "开课删除成功",
+	})
+}
+
+// GetEnrollments 获取选课名单
+// @Summary 获取选课名单
+// @Description 获取开课的选课名单，依次为已选、候补（按排位）和已退课的学生。学生和家长不能查看
+// @Tags course-offerings
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "开课ID"
+// @Param status query string false "选课状态" Enums(enrolled, waitlisted, dropped)
+// @Success 200 {object} Response{data=[]domain.Enrollment}
+// @Failure 400 {object} ErrorResponse "请求参数错误"
+// @Failure 403 {object} ErrorResponse "无权访问"
+// @Failure 404 {object} ErrorResponse "开课不存在"
+// @Router /api/v1/course-offerings/{id}/enrollments [get]
+func (h *CourseOfferingHandler) GetEnrollments(c *gin.Context) {
+	actor, ok := currentActor(c)
+	if !ok {
+		return
+	}
+
+	id, ok := parseOfferingID(c)
+	if !ok {
+		return
+	}
+
+	var req domain.EnrollmentListRequest
+	if !bindQuery(c, h.validator, &req) {
+		return
+	}
+
+	enrollments, err := h.offeringService.ListEnrollments(actor, id, req)
+	if err != nil {
+		c.JSON(statusFromError(err, http.StatusInternalServerError), ErrorResponse{
+			Error:   "Failed to get enrollments",
+			Message: err.Error(),
+		})
+		return
+	}
+
+	if enrollments == nil {
+		enrollments = []*domain.Enrollment{}
+	}
+
+	c.JSON(http.StatusOK, Response{
+		Code:    200,
+		Message: "获取成功",
+		Data:    enrollments,
+	})
+}
+
+// Enroll 选课
+// @Summary 选课
+// @Description 为学生选课。课程已满时进入候补名单（status 为 waitlisted），候补名单也满时返回 409。
+// @Description 学生只能为本人选课，且须在开放选课期间和加选截止时间之前；管理员不受截止时间限制
+// @Tags course-offerings
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "开课ID"
+// @Param request body domain.EnrollRequest true "选课学生"
+// @Success 201 {object} Response{data=domain.Enrollment}
+// @Failure 400 {object} ErrorResponse "请求参数错误或学生不存在"
+// @Failure 403 {object} ErrorResponse "无权为该学生选课"
+// @Failure 404 {object} ErrorResponse "开课不存在"
+// @Failure 409 {object} ErrorResponse "已选、已过截止时间或课程已满"
+// @Router /api/v1/course-offerings/{id}/enrollments [post]
+func (h *CourseOfferingHandler) Enroll(c *gin.Context) {
+	actor, ok := currentActor(c)
+	if !ok {
+		return
+	}
+
+	id, ok := parseOfferingID(c)
+	if !ok {
+		return
+	}
+
+	var req domain.EnrollRequest
+	if !bindJSON(c, h.validator, &req) {
+		return
+	}
+
+	enrollment, err := h.offeringService.Enroll(actor, id, req.StudentID)
+	if err != nil {
+		c.JSON(statusFromError(err, http.StatusInternalServerError), ErrorResponse{
+			Error:   "Failed to enroll",
+			Message: err.Error(),
+		})
+		return
+	}
+
+	message := "选课成功"
+	if enrollment.Status == domain.EnrollmentStatusWaitlisted {
+		message = "课程已满，已加入候补名单"
+	}
+
+	c.JSON(http.StatusCreated, Response{
+		Code:    201,
+		Message: message,
+		Data:    enrollment,
+	})
+}
+
+// Drop 退课
+// @Summary 退课
+// @Description 学生退课或退出候补，空出的名额由候补学生按顺序递补。学生本人退课须在退选截止时间之前，已有成绩的选课不能退
+// @Tags course-offerings
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "开课ID"
+// @Param student_id path int true "学生ID"
+// @Success 200 {object} Response
+// @Failure 403 {object} ErrorResponse "无权为该学生退课"
+// @Failure 404 {object} ErrorResponse "开课不存在或学生未选该课程"
+// @Failure 409 {object} ErrorResponse "已过退选截止时间或已有成绩"
+// @Router /api/v1/course-offerings/{id}/enrollments/{student_id} [delete]
+func (h *CourseOfferingHandler) Drop(c *gin.Context) {
+	actor, ok := currentActor(c)
+	if !ok {
+		return
+	}
+
+	id, ok := parseOfferingID(c)
+	if !ok {
+		return
+	}
+
+	studentID, err := strconv.Atoi(c.Param("student_id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{
+			Error:   "Invalid student ID",
+			Message: "学生ID必须是数字",
+		})
+		return
+	}
+
+	if err := h.offeringService.Drop(actor, id, studentID); err != nil {
+		c.JSON(statusFromError(err, http.StatusInternalServerError), ErrorResponse{
+			Error:   "Failed to drop enrollment",
+			Message: err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, Response{
+		Code:    200,
+		Message: "退课成功",
+	})
+}
+
+// GetStudentEnrollments 获取学生选课记录
+// @Summary 获取学生选课记录
+// @Description 获取学生的选课记录，可按学期和选课状态筛选。学生和家长只能查看本人（子女）
+// @Tags students
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "学生ID"
+// @Param semester query string false "学期"
+// @Param status query string false "选课状态" Enums(enrolled, waitlisted, dropped)
+// @Success 200 {object} Response{data=[]domain.Enrollment}
+// @Failure 400 {object} ErrorResponse "请求参数错误"
+// @Failure 403 {object} ErrorResponse "无权访问"
+// @Router /api/v1/students/{id}/enrollments [get]
+func (h *CourseOfferingHandler) GetStudentEnrollments(c *gin.Context) {
+	actor, ok := currentActor(c)
+	if !ok {
+		return
+	}
+
+	studentID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{
+			Error:   "Invalid student ID",
+			Message: "学生ID必须是数字",
+		})
+		return
+	}
+
+	var req domain.EnrollmentListRequest
+	if !bindQuery(c, h.validator, &req) {
+		return
+	}
+
+	enrollments, err := h.offeringService.ListStudentEnrollments(actor, studentID, req)
+	if err != nil {
+		c.JSON(statusFromError(err, http.StatusInternalServerError), ErrorResponse{
+			Error:   "Failed to get student enrollments",
+			Message: err.Error(),
+		})
+		return
+	}
+
+	if enrollments == nil {
+		enrollments = []*domain.Enrollment{}
+	}
+
+	c.JSON(http.StatusOK, Response{
+		Code:    200,
+		Message: "获取成功",
+		Data:    enrollments,
+	})
+}
+
+// currentActor 获取当前登录账号，失败时已写入响应
+func currentActor(c *gin.Context) (*domain.JWTClaims, bool) {
+	actor, ok := middleware.GetCurrentAdmin(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, ErrorResponse{
+			Error:   "Unauthorized",
+			Message: "Invalid or missing token",
+		})
+		return nil, false
+	}
+	return actor, true
+}
+
+// parseOfferingID 解析路径中的开课ID，失败时已写入响应
+func parseOfferingID(c *gin.Context) (int, bool) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil || id <= 0 {
+		c.JSON(http.StatusBadRequest, ErrorResponse{
+			Error:   "Invalid ID format",
+			Message: "开课ID格式错误",
+		})
+		return 0, false
+	}
+	return id, true
+}
@@ -0,0 +1,242 @@
+package handler
+
+import (
+	"net/http"
+	"strconv"
+
+	"student-management-system/internal/domain"
+	"student-management-system/internal/service"
+	"student-management-system/pkg/validator"
+
+	"github.com/gin-gonic/gin"
+)
+
+// ExamHandler 考试安排处理器
+type ExamHandler struct {
+	examService *service.ExamService
+	validator   *validator.CustomValidator
+}
+
+// NewExamHandler 创建新的考试安排处理器
+func NewExamHandler(examService *service.ExamService, validator *validator.CustomValidator) *ExamHandler {
+	return &ExamHandler{
+		examService: examService,
+		validator:   validator,
+	}
+}
+
+// CreateExam 创建考试安排
+// @Summary 创建考试安排
+// @Description 为开课安排测验、期中或期末考试，考试类型默认为期末考试。指定考场时检查考场容量和同一考场的时间冲突；已取消的开课和已结束学期不能安排考试
+// @Tags exams
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param exam body domain.CreateExamRequest true "考试安排信息"
+// @Success 201 {object} Response{data=domain.Exam}
+// @Failure 400 {object} ErrorResponse "请求参数错误"
+// @Failure 404 {object} ErrorResponse "开课不存在"
+// @Failure 409 {object} ErrorResponse "考场冲突或学期已结束"
+// @Router /api/v1/exams [post]
+func (h *ExamHandler) CreateExam(c *gin.Context) {
+	actor, ok := currentActor(c)
+	if !ok {
+		return
+	}
+
+	var req domain.CreateExamRequest
+	if !bindJSON(c, h.validator, &req) {
+		return
+	}
+
+	exam, err := h.examService.CreateExam(actor, req)
+	if err != nil {
+		c.JSON(statusFromError(err, http.StatusInternalServerError), ErrorResponse{
+			Error:   "Failed to create exam",
+			Message: err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusCreated, Response{
+		Code:    201,
+		Message: "考试安排创建成功",
+		Data:    exam,
+	})
+}
+
+// GetExams 获取考试安排列表
+// @Summary 获取考试安排列表
+// @Description 分页获取考试安排，可按学期、开课、考试类型和考场筛选。学生和家长通过日历订阅查看本人（子女）的考试
+// @Tags exams
+// @Produce json
+// @Security BearerAuth
+// @Param page query int false "页码" default(1)
+// @Param size query int false "每页数量" default(10)
+// @Param semester query string false "学期"
+// @Param offering_id query int false "开课ID"
+// @Param exam_type query string false "考试类型" Enums(quiz, midterm, final)
+// @Param room_id query int false "教室ID"
+// @Success 200 {object} PaginatedResponse
+// @Failure 400 {object} ErrorResponse "请求参数错误"
+// @Failure 403 {object} ErrorResponse "无权查看"
+// @Router /api/v1/exams [get]
+func (h *ExamHandler) GetExams(c *gin.Context) {
+	actor, ok := currentActor(c)
+	if !ok {
+		return
+	}
+
+	var req domain.ExamListRequest
+	if !bindQuery(c, h.validator, &req) {
+		return
+	}
+
+	exams, total, err := h.examService.ListExams(actor, req)
+	if err != nil {
+		c.JSON(statusFromError(err, http.StatusInternalServerError), ErrorResponse{
+			Error:   "Failed to get exams",
+			Message: err.Error(),
+		})
+		return
+	}
+
+	if exams == nil {
+		exams = []*domain.Exam{}
+	}
+
+	page, size := req.Page, req.Size
+	if page <= 0 {
+		page = 1
+	}
+	if size <= 0 {
+		size = 10
+	}
+
+	c.JSON(http.StatusOK, PaginatedResponse{
+		Code:    200,
+		Message: "获取考试安排列表成功",
+		Data:    exams,
+		Total:   int(total),
+		Page:    page,
+		Size:    size,
+	})
+}
+
+// GetExam 获取单个考试安排
+// @Summary 获取考试安排详情
+// @Description 根据ID获取考试安排
+// @Tags exams
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "考试安排ID"
+// @Success 200 {object} Response{data=domain.Exam}
+// @Failure 400 {object} ErrorResponse "请求参数错误"
+// @Failure 404 {object} ErrorResponse "考试安排不存在"
+// @Router /api/v1/exams/{id} [get]
+func (h *ExamHandler) GetExam(c *gin.Context) {
+	id, ok := parseExamID(c)
+	if !ok {
+		return
+	}
+
+	exam, err := h.examService.GetExam(id)
+	if err != nil {
+		c.JSON(statusFromError(err, http.StatusInternalServerError), ErrorResponse{
+			Error:   "Failed to get exam",
+			Message: err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, Response{
+		Code:    200,
+		Message: "获取考试安排成功",
+		Data:    exam,
+	})
+}
+
+// UpdateExam 更新考试安排
+// @Summary 更新考试安排
+// @Description 调整考试的类型、时间和考场，调整后重新检查考场冲突。room_id 传 0 表示不使用教室
+// @Tags exams
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "考试安排ID"
+// @Param exam body domain.UpdateExamRequest true "更新的考试安排信息"
+// @Success 200 {object} Response{data=domain.Exam}
+// @Failure 400 {object} ErrorResponse "请求参数错误"
+// @Failure 404 {object} ErrorResponse "考试安排不存在"
+// @Failure 409 {object} ErrorResponse "考场冲突或学期已结束"
+// @Router /api/v1/exams/{id} [put]
+func (h *ExamHandler) UpdateExam(c *gin.Context) {
+	id, ok := parseExamID(c)
+	if !ok {
+		return
+	}
+
+	var req domain.UpdateExamRequest
+	if !bindJSON(c, h.validator, &req) {
+		return
+	}
+
+	exam, err := h.examService.UpdateExam(id, req)
+	if err != nil {
+		c.JSON(statusFromError(err, http.StatusInternalServerError), ErrorResponse{
+			Error:   "Failed to update exam",
+			Message: err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, Response{
+		Code:    200,
+		Message: "考试安排更新成功",
+		Data:    exam,
+	})
+}
+
+// DeleteExam 删除考试安排
+// @Summary 删除考试安排
+// @Description 删除考试安排，已结束学期的考试安排不能修改
+// @Tags exams
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "考试安排ID"
+// @Success 200 {object} Response
+// @Failure 404 {object} ErrorResponse "考试安排不存在"
+// @Failure 409 {object} ErrorResponse "学期已结束"
+// @Router /api/v1/exams/{id} [delete]
+func (h *ExamHandler) DeleteExam(c *gin.Context) {
+	id, ok := parseExamID(c)
+	if !ok {
+		return
+	}
+
+	if err := h.examService.DeleteExam(id); err != nil {
+		c.JSON(statusFromError(err, http.StatusInternalServerError), ErrorResponse{
+			Error:   "Failed to delete exam",
+			Message: err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, Response{
+		Code:    200,
+		Message: "考试安排删除成功",
+	})
+}
+
+// parseExamID 解析路径中的考试安排ID，失败时已写入响应
+func parseExamID(c *gin.Context) (int, bool) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil || id <= 0 {
+		c.JSON(http.StatusBadRequest, ErrorResponse{
+			Error:   "Invalid ID format",
+			Message: "考试安排ID格式错误",
+		})
+		return 0, false
+	}
+	return id, true
+}
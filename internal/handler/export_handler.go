@@ -0,0 +1,321 @@
+package handler
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+
+	"student-management-system/internal/domain"
+	"student-management-system/internal/service"
+	"student-management-system/pkg/logger"
+	"student-management-system/pkg/middleware"
+	"student-management-system/pkg/spreadsheet"
+	"student-management-system/pkg/validator"
+
+	"github.com/gin-gonic/gin"
+)
+
+// ExportHandler 数据导出处理器
+type ExportHandler struct {
+	exportService *service.ExportService
+	validator     *validator.CustomValidator
+}
+
+// NewExportHandler 创建新的数据导出处理器
+func NewExportHandler(exportService *service.ExportService, validator *validator.CustomValidator) *ExportHandler {
+	return &ExportHandler{
+		exportService: exportService,
+		validator:     validator,
+	}
+}
+
+// ExportStudents 导出学生
+// @Summary 导出学生
+// @Description 按与学生列表相同的筛选条件，以CSV、XLSX或NDJSON格式流式导出学生，学生和家长只导出本人（子女）。
+// @Description 数据量超过阈值或 async=true 时转为后台任务，返回202和下载链接。
+// @Tags students
+// @Produce text/csv,application/vnd.openxmlformats-officedocument.spreadsheetml.sheet,application/x-ndjson,json
+// @Security BearerAuth
+// @Param format query string false "导出格式" Enums(csv, xlsx, ndjson)
+// @Param columns query string false "逗号分隔的导出列，默认全部列"
+// @Param headers query string false "表头语言：key 使用字段名，zh 使用中文列名" Enums(key, zh)
+// @Param async query bool false "使用后台任务导出"
+// @Param class_id query int false "班级ID"
+// @Param grade_year query int false "年级（入学年份）"
+// @Success 200 {file} file "导出文件"
+// @Success 202 {object} Response{data=domain.ExportJob} "已创建后台导出任务"
+// @Failure 400 {object} ErrorResponse "请求参数错误"
+// @Failure 401 {object} ErrorResponse "未授权"
+// @Failure 500 {object} ErrorResponse "服务器内部错误"
+// @Router /api/v1/students/export [get]
+func (h *ExportHandler) ExportStudents(c *gin.Context) {
+	actor, req, ok := h.bindExport(c)
+	if !ok {
+		return
+	}
+
+	var filter domain.StudentFilter
+	if !bindQuery(c, h.validator, &filter) {
+		return
+	}
+
+	plan, err := h.exportService.PlanStudents(actor, filter, req)
+	h.export(c, actor, req, plan, err)
+}
+
+// ExportTeachers 导出老师
+// @Summary 导出老师
+// @Description 以CSV、XLSX或NDJSON格式流式导出老师。数据量超过阈值或 async=true 时转为后台任务，返回202和下载链接。
+// @Tags teachers
+// @Produce text/csv,application/vnd.openxmlformats-officedocument.spreadsheetml.sheet,application/x-ndjson,json
+// @Security BearerAuth
+// @Param format query string false "导出格式" Enums(csv, xlsx, ndjson)
+// @Param columns query string false "逗号分隔的导出列，默认全部列"
+// @Param headers query string false "表头语言：key 使用字段名，zh 使用中文列名" Enums(key, zh)
+// @Param async query bool false "使用后台任务导出"
+// @Success 200 {file} file "导出文件"
+// @Success 202 {object} Response{data=domain.ExportJob} "已创建后台导出任务"
+// @Failure 400 {object} ErrorResponse "请求参数错误"
+// @Failure 401 {object} ErrorResponse "未授权"
+// @Failure 500 {object} ErrorResponse "服务器内部错误"
+// @Router /api/v1/teachers/export [get]
+func (h *ExportHandler) ExportTeachers(c *gin.Context) {
+	actor, req, ok := h.bindExport(c)
+	if !ok {
+		return
+	}
+
+	plan, err := h.exportService.PlanTeachers(req)
+	h.export(c, actor, req, plan, err)
+}
+
+// ExportScores 导出成绩
+// @Summary 导出成绩
+// @Description 按与成绩列表相同的筛选条件，以CSV、XLSX或NDJSON格式流式导出成绩，学生和家长只导出本人（子女）的成绩。
+// @Description 数据量超过阈值或 async=true 时转为后台任务，返回202和下载链接。
+// @Tags scores
+// @Produce text/csv,application/vnd.openxmlformats-officedocument.spreadsheetml.sheet,application/x-ndjson,json
+// @Security BearerAuth
+// @Param student_id query int false "学生ID"
+// @Param subject_id query int false "科目ID"
+// @Param teacher_id query int false "老师ID"
+// @Param semester query string false "学期"
+// @Param exam_type query string false "考试类型"
+// @Param min_score query number false "最低分"
+// @Param max_score query number false "最高分"
+// @Param format query string false "导出格式" Enums(csv, xlsx, ndjson)
+// @Param columns query string false "逗号分隔的导出列，默认全部列"
+// @Param headers query string false "表头语言：key 使用字段名，zh 使用中文列名" Enums(key, zh)
+// @Param async query bool false "使用后台任务导出"
+// @Success 200 {file} file "导出文件"
+// @Success 202 {object} Response{data=domain.ExportJob} "已创建后台导出任务"
+// @Failure 400 {object} ErrorResponse "请求参数错误"
+// @Failure 401 {object} ErrorResponse "未授权"
+// @Failure 403 {object} ErrorResponse "无权访问"
+// @Failure 500 {object} ErrorResponse "服务器内部错误"
+// @Router /api/v1/scores/export [get]
+func (h *ExportHandler) ExportScores(c *gin.Context) {
+	actor, req, ok := h.bindExport(c)
+	if !ok {
+		return
+	}
+
+	var list domain.ScoreListRequest
+	if err := c.ShouldBindQuery(&list); err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{
+			Error:   "Invalid request format",
+			Message: "请求参数错误: " + err.Error(),
+		})
+		return
+	}
+	// 导出不分页
+	list.Page, list.Size = 0, 0
+
+	if err := h.validator.ValidateStruct(&list); err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{
+			Error:   "Validation failed",
+			Message: "数据验证失败: " + err.Error(),
+		})
+		return
+	}
+
+	plan, err := h.exportService.PlanScores(actor, list, req)
+	h.export(c, actor, req, plan, err)
+}
+
+// GetExportJob 获取后台导出任务
+// @Summary 获取后台导出任务
+// @Description 查询本人创建的后台导出任务状态，完成后通过 download_url 下载
+// @Tags exports
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "任务ID"
+// @Success 200 {object} Response{data=domain.ExportJob}
+// @Failure 401 {object} ErrorResponse "未授权"
+// @Failure 404 {object} ErrorResponse "任务不存在或已过期"
+// @Router /api/v1/exports/{id} [get]
+func (h *ExportHandler) GetExportJob(c *gin.Context) {
+	actor, ok := middleware.GetCurrentAdmin(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, ErrorResponse{
+			Error:   "Unauthorized",
+			Message: "Invalid or missing token",
+		})
+		return
+	}
+
+	job, err := h.exportService.GetJob(actor, c.Param("id"))
+	if err != nil {
+		c.JSON(statusFromError(err, http.StatusInternalServerError), ErrorResponse{
+			Error:   "Failed to get export job",
+			Message: err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, Response{
+		Code:    200,
+		Message: "获取成功",
+		Data:    job,
+	})
+}
+
+// DownloadExport 下载后台导出文件
+// @Summary 下载后台导出文件
+// @Description 下载本人创建的已完成的后台导出文件
+// @Tags exports
+// @Produce octet-stream
+// @Security BearerAuth
+// @Param id path string true "任务ID"
+// @Success 200 {file} file "导出文件"
+// @Failure 401 {object} ErrorResponse "未授权"
+// @Failure 404 {object} ErrorResponse "任务不存在或已过期"
+// @Failure 409 {object} ErrorResponse "任务尚未完成"
+// @Router /api/v1/exports/{id}/download [get]
+func (h *ExportHandler) DownloadExport(c *gin.Context) {
+	actor, ok := middleware.GetCurrentAdmin(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, ErrorResponse{
+			Error:   "Unauthorized",
+			Message: "Invalid or missing token",
+		})
+		return
+	}
+
+	file, job, err := h.exportService.OpenJobFile(actor, c.Param("id"))
+	if err != nil {
+		c.JSON(statusFromError(err, http.StatusInternalServerError), ErrorResponse{
+			Error:   "Failed to download export",
+			Message: err.Error(),
+		})
+		return
+	}
+	defer file.Close()
+
+	setAttachmentHeaders(c, job.Format, job.FileName)
+	if info, err := file.Stat(); err == nil {
+		c.Header("Content-Length", fmt.Sprint(info.Size()))
+	}
+	c.Status(http.StatusOK)
+	if _, err := io.Copy(c.Writer, file); err != nil {
+		logger.WithError(err).WithFields(map[string]interface{}{
+			"job_id": job.ID,
+		}).Warn("Failed to send export file")
+	}
+}
+
+// bindExport 获取当前账号并解析导出参数，失败时已写入响应
+func (h *ExportHandler) bindExport(c *gin.Context) (*domain.JWTClaims, *domain.ExportRequest, bool) {
+	actor, ok := middleware.GetCurrentAdmin(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, ErrorResponse{
+			Error:   "Unauthorized",
+			Message: "Invalid or missing token",
+		})
+		return nil, nil, false
+	}
+
+	var req domain.ExportRequest
+	if err := c.ShouldBindQuery(&req); err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{
+			Error:   "Invalid request format",
+			Message: "请求参数错误: " + err.Error(),
+		})
+		return nil, nil, false
+	}
+
+	if err := h.validator.ValidateStruct(&req); err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{
+			Error:   "Validation failed",
+			Message: "数据验证失败: " + err.Error(),
+		})
+		return nil, nil, false
+	}
+
+	return actor, &req, true
+}
+
+// export 根据数据量直接流式返回文件或创建后台导出任务
+func (h *ExportHandler) export(c *gin.Context, actor *domain.JWTClaims, req *domain.ExportRequest, plan *service.ExportPlan, err error) {
+	if err != nil {
+		c.JSON(statusFromError(err, http.StatusInternalServerError), ErrorResponse{
+			Error:   "Export failed",
+			Message: err.Error(),
+		})
+		return
+	}
+
+	async, err := h.exportService.ShouldRunAsync(plan, req)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, ErrorResponse{
+			Error:   "Export failed",
+			Message: err.Error(),
+		})
+		return
+	}
+
+	if async {
+		job, err := h.exportService.StartJob(actor, plan)
+		if err != nil {
+			c.JSON(statusFromError(err, http.StatusInternalServerError), ErrorResponse{
+				Error:   "Create export job failed",
+				Message: err.Error(),
+			})
+			return
+		}
+
+		c.Header("Location", "/api/v1/exports/"+job.ID)
+		c.JSON(http.StatusAccepted, Response{
+			Code:    202,
+			Message: "已创建后台导出任务，完成后可通过下载链接获取文件",
+			Data:    job,
+		})
+		return
+	}
+
+	setAttachmentHeaders(c, plan.Format, plan.FileName(time.Now()))
+	c.Status(http.StatusOK)
+
+	// 响应头已发出，出错时只能中断连接并记录日志
+	rows, err := h.exportService.Stream(plan, c.Writer)
+	fields := map[string]interface{}{
+		"admin_id": actor.AdminID,
+		"resource": plan.Resource,
+		"format":   plan.Format,
+		"rows":     rows,
+	}
+	if err != nil {
+		logger.WithError(err).WithFields(fields).Error("Export stream failed")
+		c.Abort()
+		return
+	}
+	logger.WithFields(fields).Info("Export completed")
+}
+
+// setAttachmentHeaders 设置下载文件的响应头
+func setAttachmentHeaders(c *gin.Context, format, fileName string) {
+	c.Header("Content-Type", spreadsheet.ContentType(format))
+	c.Header("Content-Disposition", fmt.Sprintf(`attachment; filename="%s"`, fileName))
+	c.Header("Cache-Control", "no-store")
+}
@@ -0,0 +1,258 @@
+package handler
+
+import (
+	"net/http"
+
+	"student-management-system/internal/domain"
+	"student-management-system/internal/service"
+	"student-management-system/pkg/validator"
+
+	"github.com/gin-gonic/gin"
+)
+
+// GradeScaleHandler 绩点制处理器
+type GradeScaleHandler struct {
+	gradeScaleService *service.GradeScaleService
+	validator         *validator.CustomValidator
+}
+
+// NewGradeScaleHandler 创建新的绩点制处理器
+func NewGradeScaleHandler(gradeScaleService *service.GradeScaleService, validator *validator.CustomValidator) *GradeScaleHandler {
+	return &GradeScaleHandler{
+		gradeScaleService: gradeScaleService,
+		validator:         validator,
+	}
+}
+
+// GetGradeScales 获取绩点制列表
+// @Summary 获取绩点制列表
+// @Description 获取配置文件和数据库中定义的全部绩点制及其等级分数段，source 字段标明来源
+// @Tags grade-scales
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} Response{data=[]domain.GradeScale}
+// @Failure 500 {object} ErrorResponse "服务器内部错误"
+// @Router /api/v1/grade-scales [get]
+func (h *GradeScaleHandler) GetGradeScales(c *gin.Context) {
+	scales, err := h.gradeScaleService.ListScales()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, ErrorResponse{
+			Error:   "Failed to get grade scales",
+			Message: err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, Response{
+		Code:    200,
+		Message: "获取绩点制列表成功",
+		Data:    scales,
+	})
+}
+
+// GetGradeScale 获取绩点制
+// @Summary 获取绩点制
+// @Description 根据名称获取绩点制及其等级分数段
+// @Tags grade-scales
+// @Produce json
+// @Security BearerAuth
+// @Param name path string true "绩点制名称"
+// @Success 200 {object} Response{data=domain.GradeScale}
+// @Failure 404 {object} ErrorResponse "绩点制不存在"
+// @Router /api/v1/grade-scales/{name} [get]
+func (h *GradeScaleHandler) GetGradeScale(c *gin.Context) {
+	scale, err := h.gradeScaleService.GetScale(c.Param("name"))
+	if err != nil {
+		c.JSON(statusFromError(err, http.StatusInternalServerError), ErrorResponse{
+			Error:   "Failed to get grade scale",
+			Message: err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, Response{
+		Code:    200,
+		Message: "获取绩点制成功",
+		Data:    scale,
+	})
+}
+
+// CreateGradeScale 创建绩点制
+// @Summary 创建绩点制
+// @Description 创建自定义绩点制。formula 为 bands 时绩点取分数段的 grade_point，linear、pku 按公式计算；分数段必须包含最低分为0的一段
+// @Tags grade-scales
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param scale body domain.CreateGradeScaleRequest true "绩点制"
+// @Success 201 {object} Response{data=domain.GradeScale}
+// @Failure 400 {object} ErrorResponse "请求参数错误"
+// @Failure 409 {object} ErrorResponse "绩点制已存在"
+// @Router /api/v1/grade-scales [post]
+func (h *GradeScaleHandler) CreateGradeScale(c *gin.Context) {
+	var req domain.CreateGradeScaleRequest
+	if !bindJSON(c, h.validator, &req) {
+		return
+	}
+
+	scale, err := h.gradeScaleService.CreateScale(req)
+	if err != nil {
+		c.JSON(statusFromError(err, http.StatusInternalServerError), ErrorResponse{
+			Error:   "Failed to create grade scale",
+			Message: err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusCreated, Response{
+		Code:    201,
+		Message: "绩点制创建成功",
+		Data:    scale,
+	})
+}
+
+// UpdateGradeScale 更新绩点制
+// @Summary 更新绩点制
+// @Description 更新自定义绩点制，bands 不为空时整体替换分数段。内置绩点制和配置文件中的绩点制不能修改
+// @Tags grade-scales
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param name path string true "绩点制名称"
+// @Param scale body domain.UpdateGradeScaleRequest true "绩点制"
+// @Success 200 {object} Response{data=domain.GradeScale}
+// @Failure 400 {object} ErrorResponse "请求参数错误"
+// @Failure 404 {object} ErrorResponse "绩点制不存在"
+// @Failure 409 {object} ErrorResponse "绩点制不能修改"
+// @Router /api/v1/grade-scales/{name} [put]
+func (h *GradeScaleHandler) UpdateGradeScale(c *gin.Context) {
+	var req domain.UpdateGradeScaleRequest
+	if !bindJSON(c, h.validator, &req) {
+		return
+	}
+
+	scale, err := h.gradeScaleService.UpdateScale(c.Param("name"), req)
+	if err != nil {
+		c.JSON(statusFromError(err, http.StatusInternalServerError), ErrorResponse{
+			Error:   "Failed to update grade scale",
+			Message: err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, Response{
+		Code:    200,
+		Message: "绩点制更新成功",
+		Data:    scale,
+	})
+}
+
+// DeleteGradeScale 删除绩点制
+// @Summary 删除绩点制
+// @Description 删除自定义绩点制。内置绩点制、配置文件中的绩点制、默认绩点制和仍被专业使用的绩点制不能删除
+// @Tags grade-scales
+// @Produce json
+// @Security BearerAuth
+// @Param name path string true "绩点制名称"
+// @Success 200 {object} Response
+// @Failure 404 {object} ErrorResponse "绩点制不存在"
+// @Failure 409 {object} ErrorResponse "绩点制不能删除"
+// @Router /api/v1/grade-scales/{name} [delete]
+func (h *GradeScaleHandler) DeleteGradeScale(c *gin.Context) {
+	if err := h.gradeScaleService.DeleteScale(c.Param("name")); err != nil {
+		c.JSON(statusFromError(err, http.StatusInternalServerError), ErrorResponse{
+			Error:   "Failed to delete grade scale",
+			Message: err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, Response{
+		Code:    200,
+		Message: "绩点制删除成功",
+	})
+}
+
+// GetMajorScales 获取各专业的绩点制设置
+// @Summary 获取各专业的绩点制设置
+// @Description 获取单独设置了绩点制的专业，未设置的专业使用默认绩点制
+// @Tags grade-scales
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} Response{data=[]domain.MajorGradeScale}
+// @Failure 500 {object} ErrorResponse "服务器内部错误"
+// @Router /api/v1/grade-scales/majors [get]
+func (h *GradeScaleHandler) GetMajorScales(c *gin.Context) {
+	majors, err := h.gradeScaleService.ListMajorScales()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, ErrorResponse{
+			Error:   "Failed to get major grade scales",
+			Message: err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, Response{
+		Code:    200,
+		Message: "获取专业绩点制成功",
+		Data:    majors,
+	})
+}
+
+// SetMajorScale 设置专业的绩点制
+// @Summary 设置专业的绩点制
+// @Description 设置专业使用的绩点制，该专业学生的成绩报告和成绩单默认按此绩点制计算
+// @Tags grade-scales
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param major path string true "专业名称"
+// @Param scale body domain.SetMajorGradeScaleRequest true "绩点制"
+// @Success 200 {object} Response
+// @Failure 400 {object} ErrorResponse "请求参数错误"
+// @Failure 404 {object} ErrorResponse "绩点制不存在"
+// @Router /api/v1/grade-scales/majors/{major} [put]
+func (h *GradeScaleHandler) SetMajorScale(c *gin.Context) {
+	var req domain.SetMajorGradeScaleRequest
+	if !bindJSON(c, h.validator, &req) {
+		return
+	}
+
+	if err := h.gradeScaleService.SetMajorScale(c.Param("major"), req); err != nil {
+		c.JSON(statusFromError(err, http.StatusInternalServerError), ErrorResponse{
+			Error:   "Failed to set major grade scale",
+			Message: err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, Response{
+		Code:    200,
+		Message: "专业绩点制设置成功",
+	})
+}
+
+// DeleteMajorScale 取消专业的绩点制设置
+// @Summary 取消专业的绩点制设置
+// @Description 取消后该专业恢复使用默认绩点制
+// @Tags grade-scales
+// @Produce json
+// @Security BearerAuth
+// @Param major path string true "专业名称"
+// @Success 200 {object} Response
+// @Failure 404 {object} ErrorResponse "专业未设置绩点制"
+// @Router /api/v1/grade-scales/majors/{major} [delete]
+func (h *GradeScaleHandler) DeleteMajorScale(c *gin.Context) {
+	if err := h.gradeScaleService.DeleteMajorScale(c.Param("major")); err != nil {
+		c.JSON(statusFromError(err, http.StatusInternalServerError), ErrorResponse{
+			Error:   "Failed to delete major grade scale",
+			Message: err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, Response{
+		Code:    200,
+		Message: "专业绩点制已取消",
+	})
+}
@@ -0,0 +1,374 @@
+package handler
+
+import (
+	"net/http"
+	"strconv"
+
+	"student-management-system/internal/domain"
+	"student-management-system/internal/service"
+	"student-management-system/pkg/validator"
+
+	"github.com/gin-gonic/gin"
+)
+
+// GradingHandler 评分方案与总评成绩处理器
+type GradingHandler struct {
+	gradingService *service.GradingService
+	validator      *validator.CustomValidator
+}
+
+// NewGradingHandler 创建新的评分处理器
+func NewGradingHandler(gradingService *service.GradingService, validator *validator.CustomValidator) *GradingHandler {
+	return &GradingHandler{
+		gradingService: gradingService,
+		validator:      validator,
+	}
+}
+
+// GetSubjectScheme 获取科目评分方案
+// @Summary 获取科目评分方案
+// @Description 获取科目的评分方案，未设置时返回默认方案（期末成绩占100%）
+// @Tags grading
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "科目ID"
+// @Success 200 {object} Response{data=domain.GradingScheme}
+// @Failure 400 {object} ErrorResponse "请求参数错误"
+// @Failure 404 {object} ErrorResponse "科目不存在"
+// @Router /api/v1/subjects/{id}/grading-scheme [get]
+func (h *GradingHandler) GetSubjectScheme(c *gin.Context) {
+	id, ok := parseSubjectID(c)
+	if !ok {
+		return
+	}
+
+	scheme, err := h.gradingService.GetSubjectScheme(id)
+	if err != nil {
+		c.JSON(statusFromError(err, http.StatusInternalServerError), ErrorResponse{
+			Error:   "Failed to get grading scheme",
+			Message: err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, Response{
+		Code:    200,
+		Message: "获取评分方案成功",
+		Data:    scheme,
+	})
+}
+
+// SetSubjectScheme 设置科目评分方案
+// @Summary 设置科目评分方案
+// @Description 设置科目的各考试类型权重（合计为100）和缺项处理方式，保存后重新计算未结束学期中该科目的总评成绩
+// @Tags grading
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "科目ID"
+// @Param scheme body domain.GradingSchemeRequest true "评分方案"
+// @Success 200 {object} Response{data=domain.GradingScheme}
+// @Failure 400 {object} ErrorResponse "请求参数错误或权重合计不为100"
+// @Failure 404 {object} ErrorResponse "科目不存在"
+// @Router /api/v1/subjects/{id}/grading-scheme [put]
+func (h *GradingHandler) SetSubjectScheme(c *gin.Context) {
+	id, ok := parseSubjectID(c)
+	if !ok {
+		return
+	}
+
+	var req domain.GradingSchemeRequest
+	if !bindJSON(c, h.validator, &req) {
+		return
+	}
+
+	scheme, err := h.gradingService.SetSubjectScheme(id, req)
+	if err != nil {
+		c.JSON(statusFromError(err, http.StatusInternalServerError), ErrorResponse{
+			Error:   "Failed to set grading scheme",
+			Message: err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, Response{
+		Code:    200,
+		Message: "评分方案设置成功",
+		Data:    scheme,
+	})
+}
+
+// DeleteSubjectScheme 删除科目评分方案
+// @Summary 删除科目评分方案
+// @Description 删除后该科目恢复使用默认方案，并重新计算未结束学期中该科目的总评成绩
+// @Tags grading
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "科目ID"
+// @Success 200 {object} Response
+// @Failure 404 {object} ErrorResponse "科目未设置评分方案"
+// @Router /api/v1/subjects/{id}/grading-scheme [delete]
+func (h *GradingHandler) DeleteSubjectScheme(c *gin.Context) {
+	id, ok := parseSubjectID(c)
+	if !ok {
+		return
+	}
+
+	if err := h.gradingService.DeleteSubjectScheme(id); err != nil {
+		c.JSON(statusFromError(err, http.StatusInternalServerError), ErrorResponse{
+			Error:   "Failed to delete grading scheme",
+			Message: err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, Response{
+		Code:    200,
+		Message: "评分方案删除成功",
+	})
+}
+
+// GetOfferingScheme 获取开课评分方案
+// @Summary 获取开课评分方案
+// @Description 获取开课实际使用的评分方案：开课方案优先，其次为科目方案，最后为默认方案，source 字段标明来源
+// @Tags grading
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "开课ID"
+// @Success 200 {object} Response{data=domain.GradingScheme}
+// @Failure 400 {object} ErrorResponse "请求参数错误"
+// @Failure 404 {object} ErrorResponse "开课不存在"
+// @Router /api/v1/course-offerings/{id}/grading-scheme [get]
+func (h *GradingHandler) GetOfferingScheme(c *gin.Context) {
+	id, ok := parseOfferingID(c)
+	if !ok {
+		return
+	}
+
+	scheme, err := h.gradingService.GetOfferingScheme(id)
+	if err != nil {
+		c.JSON(statusFromError(err, http.StatusInternalServerError), ErrorResponse{
+			Error:   "Failed to get grading scheme",
+			Message: err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, Response{
+		Code:    200,
+		Message: "获取评分方案成功",
+		Data:    scheme,
+	})
+}
+
+// SetOfferingScheme 设置开课评分方案
+// @Summary 设置开课评分方案
+// @Description 为开课单独设置评分方案，优先于科目方案，保存后重新计算该开课的总评成绩。已结束学期的开课不能修改
+// @Tags grading
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "开课ID"
+// @Param scheme body domain.GradingSchemeRequest true "评分方案"
+// @Success 200 {object} Response{data=domain.GradingScheme}
+// @Failure 400 {object} ErrorResponse "请求参数错误或权重合计不为100"
+// @Failure 404 {object} ErrorResponse "开课不存在"
+// @Failure 409 {object} ErrorResponse "学期已结束"
+// @Router /api/v1/course-offerings/{id}/grading-scheme [put]
+func (h *GradingHandler) SetOfferingScheme(c *gin.Context) {
+	id, ok := parseOfferingID(c)
+	if !ok {
+		return
+	}
+
+	var req domain.GradingSchemeRequest
+	if !bindJSON(c, h.validator, &req) {
+		return
+	}
+
+	scheme, err := h.gradingService.SetOfferingScheme(id, req)
+	if err != nil {
+		c.JSON(statusFromError(err, http.StatusInternalServerError), ErrorResponse{
+			Error:   "Failed to set grading scheme",
+			Message: err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, Response{
+		Code:    200,
+		Message: "评分方案设置成功",
+		Data:    scheme,
+	})
+}
+
+// DeleteOfferingScheme 删除开课评分方案
+// @Summary 删除开课评分方案
+// @Description 删除后该开课恢复使用科目方案或默认方案，并重新计算该开课的总评成绩
+// @Tags grading
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "开课ID"
+// @Success 200 {object} Response
+// @Failure 404 {object} ErrorResponse "开课未设置评分方案"
+// @Failure 409 {object} ErrorResponse "学期已结束"
+// @Router /api/v1/course-offerings/{id}/grading-scheme [delete]
+func (h *GradingHandler) DeleteOfferingScheme(c *gin.Context) {
+	id, ok := parseOfferingID(c)
+	if !ok {
+		return
+	}
+
+	if err := h.gradingService.DeleteOfferingScheme(id); err != nil {
+		c.JSON(statusFromError(err, http.StatusInternalServerError), ErrorResponse{
+			Error:   "Failed to delete grading scheme",
+			Message: err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, Response{
+		Code:    200,
+		Message: "评分方案删除成功",
+	})
+}
+
+// GetOfferingFinalGrades 获取开课总评成绩
+// @Summary 获取开课总评成绩
+// @Description 获取开课所有学生的总评成绩及各组成部分，学生和家长无权查看
+// @Tags grading
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "开课ID"
+// @Success 200 {object} Response{data=[]domain.FinalGrade}
+// @Failure 403 {object} ErrorResponse "无权查看"
+// @Failure 404 {object} ErrorResponse "开课不存在"
+// @Router /api/v1/course-offerings/{id}/final-grades [get]
+func (h *GradingHandler) GetOfferingFinalGrades(c *gin.Context) {
+	actor, ok := currentActor(c)
+	if !ok {
+		return
+	}
+
+	id, ok := parseOfferingID(c)
+	if !ok {
+		return
+	}
+
+	grades, err := h.gradingService.ListOfferingFinalGrades(actor, id)
+	if err != nil {
+		c.JSON(statusFromError(err, http.StatusInternalServerError), ErrorResponse{
+			Error:   "Failed to get final grades",
+			Message: err.Error(),
+		})
+		return
+	}
+
+	if grades == nil {
+		grades = []*domain.FinalGrade{}
+	}
+
+	c.JSON(http.StatusOK, Response{
+		Code:    200,
+		Message: "获取总评成绩成功",
+		Data:    grades,
+	})
+}
+
+// RecomputeOfferingFinalGrades 重新计算开课总评成绩
+// @Summary 重新计算开课总评成绩
+// @Description 按当前评分方案重新计算开课的全部总评成绩。成绩录入后会自动计算，此接口用于补算
+// @Tags grading
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "开课ID"
+// @Success 200 {object} Response{data=domain.RecomputeResult}
+// @Failure 404 {object} ErrorResponse "开课不存在"
+// @Failure 409 {object} ErrorResponse "学期已结束"
+// @Router /api/v1/course-offerings/{id}/final-grades/recompute [post]
+func (h *GradingHandler) RecomputeOfferingFinalGrades(c *gin.Context) {
+	id, ok := parseOfferingID(c)
+	if !ok {
+		return
+	}
+
+	result, err := h.gradingService.RecomputeOffering(id)
+	if err != nil {
+		c.JSON(statusFromError(err, http.StatusInternalServerError), ErrorResponse{
+			Error:   "Failed to recompute final grades",
+			Message: err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, Response{
+		Code:    200,
+		Message: "总评成绩重新计算完成",
+		Data:    result,
+	})
+}
+
+// GetStudentFinalGrades 获取学生总评成绩
+// @Summary 获取学生总评成绩
+// @Description 获取学生各科总评成绩及各组成部分，可按学期筛选。缺项时按评分方案的缺项处理方式计算，is_complete 标明成绩是否已全部录入
+// @Tags grading
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "学生ID"
+// @Param semester query string false "学期"
+// @Success 200 {object} Response{data=[]domain.FinalGrade}
+// @Failure 400 {object} ErrorResponse "请求参数错误"
+// @Failure 403 {object} ErrorResponse "无权查看"
+// @Router /api/v1/students/{id}/final-grades [get]
+func (h *GradingHandler) GetStudentFinalGrades(c *gin.Context) {
+	actor, ok := currentActor(c)
+	if !ok {
+		return
+	}
+
+	studentID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{
+			Error:   "Invalid student ID",
+			Message: "学生ID必须是数字",
+		})
+		return
+	}
+
+	var req domain.FinalGradeListRequest
+	if !bindQuery(c, h.validator, &req) {
+		return
+	}
+
+	grades, err := h.gradingService.ListStudentFinalGrades(actor, studentID, req)
+	if err != nil {
+		c.JSON(statusFromError(err, http.StatusInternalServerError), ErrorResponse{
+			Error:   "Failed to get final grades",
+			Message: err.Error(),
+		})
+		return
+	}
+
+	if grades == nil {
+		grades = []*domain.FinalGrade{}
+	}
+
+	c.JSON(http.StatusOK, Response{
+		Code:    200,
+		Message: "获取总评成绩成功",
+		Data:    grades,
+	})
+}
+
+// parseSubjectID 解析路径中的科目ID，失败时已写入响应
+func parseSubjectID(c *gin.Context) (int, bool) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil || id <= 0 {
+		c.JSON(http.StatusBadRequest, ErrorResponse{
+			Error:   "Invalid ID format",
+			Message: "科目ID格式错误",
+		})
+		return 0, false
+	}
+	return id, true
+}
@@ -0,0 +1,351 @@
+package handler
+
+import (
+	"net/http"
+	"strconv"
+
+	"student-management-system/internal/domain"
+	"student-management-system/internal/service"
+	"student-management-system/pkg/validator"
+
+	"github.com/gin-gonic/gin"
+)
+
+// LeaveHandler 请假处理器
+type LeaveHandler struct {
+	leaveService *service.LeaveService
+	validator    *validator.CustomValidator
+}
+
+// NewLeaveHandler 创建新的请假处理器
+func NewLeaveHandler(leaveService *service.LeaveService, validator *validator.CustomValidator) *LeaveHandler {
+	return &LeaveHandler{
+		leaveService: leaveService,
+		validator:    validator,
+	}
+}
+
+// SubmitLeave 提交请假申请
+// @Summary 提交请假申请
+// @Description 学生或家长为本人（子女）请假，填写日期范围、请假类型和事由，可附上已上传材料（病历、假条等）的地址。学生只关联一名学生时可不填 student_id。审批链按请假天数（含首尾两天）由配置确定，同一学生的请假日期不能与审批中或已批准的请假重叠
+// @Tags leaves
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param leave body domain.CreateLeaveRequest true "请假申请"
+// @Success 201 {object} Response{data=domain.LeaveRequest}
+// @Failure 400 {object} ErrorResponse "请求参数错误或请假天数超过上限"
+// @Failure 403 {object} ErrorResponse "无权申请"
+// @Failure 404 {object} ErrorResponse "学生不存在"
+// @Failure 409 {object} ErrorResponse "与已有请假重叠"
+// @Router /api/v1/leaves [post]
+func (h *LeaveHandler) SubmitLeave(c *gin.Context) {
+	actor, ok := currentActor(c)
+	if !ok {
+		return
+	}
+
+	var req domain.CreateLeaveRequest
+	if !bindJSON(c, h.validator, &req) {
+		return
+	}
+
+	leave, err := h.leaveService.SubmitLeave(actor, req)
+	if err != nil {
+		c.JSON(statusFromError(err, http.StatusInternalServerError), ErrorResponse{
+			Error:   "Failed to submit leave request",
+			Message: err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusCreated, Response{
+		Code:    201,
+		Message: "请假申请已提交",
+		Data:    leave,
+	})
+}
+
+// GetLeaves 获取请假申请列表
+// @Summary 获取请假申请列表
+// @Description 分页获取请假申请，按请假开始日期倒序，日期范围筛选与该范围有交集的请假。学生和家长只能查看本人（子女）的申请，教师只能查看本班（班主任）学生的申请
+// @Tags leaves
+// @Produce json
+// @Security BearerAuth
+// @Param page query int false "页码" default(1)
+// @Param size query int false "每页数量" default(10)
+// @Param status query string false "状态" Enums(pending, approved, rejected, cancelled)
+// @Param leave_type query string false "请假类型" Enums(personal, sick, other)
+// @Param student_id query int false "学生ID"
+// @Param class_id query int false "学生当前所在班级ID"
+// @Param date_from query string false "开始日期，如 2024-09-01"
+// @Param date_to query string false "结束日期，如 2024-09-30"
+// @Success 200 {object} PaginatedResponse{data=[]domain.LeaveRequest}
+// @Failure 400 {object} ErrorResponse "请求参数错误"
+// @Failure 403 {object} ErrorResponse "无权查看"
+// @Router /api/v1/leaves [get]
+func (h *LeaveHandler) GetLeaves(c *gin.Context) {
+	actor, ok := currentActor(c)
+	if !ok {
+		return
+	}
+
+	var req domain.LeaveListRequest
+	if !bindQuery(c, h.validator, &req) {
+		return
+	}
+
+	leaves, total, err := h.leaveService.ListLeaves(actor, req)
+	if err != nil {
+		c.JSON(statusFromError(err, http.StatusInternalServerError), ErrorResponse{
+			Error:   "Failed to get leave requests",
+			Message: err.Error(),
+		})
+		return
+	}
+
+	h.respondList(c, "获取请假申请列表成功", leaves, total, req)
+}
+
+// GetLeaveQueue 获取待本人审批的请假申请
+// @Summary 获取待本人审批的请假申请
+// @Description 获取审批中的申请，按提交时间先后排列。班主任只能看到本班学生待班主任审批的申请，管理员可以看到全部审批中的申请
+// @Tags leaves
+// @Produce json
+// @Security BearerAuth
+// @Param page query int false "页码" default(1)
+// @Param size query int false "每页数量" default(10)
+// @Param leave_type query string false "请假类型" Enums(personal, sick, other)
+// @Param student_id query int false "学生ID"
+// @Param class_id query int false "学生当前所在班级ID"
+// @Success 200 {object} PaginatedResponse{data=[]domain.LeaveRequest}
+// @Failure 400 {object} ErrorResponse "请求参数错误"
+// @Failure 403 {object} ErrorResponse "无权查看"
+// @Router /api/v1/leaves/queue [get]
+func (h *LeaveHandler) GetLeaveQueue(c *gin.Context) {
+	actor, ok := currentActor(c)
+	if !ok {
+		return
+	}
+
+	var req domain.LeaveListRequest
+	if !bindQuery(c, h.validator, &req) {
+		return
+	}
+
+	leaves, total, err := h.leaveService.ListQueue(actor, req)
+	if err != nil {
+		c.JSON(statusFromError(err, http.StatusInternalServerError), ErrorResponse{
+			Error:   "Failed to get leave queue",
+			Message: err.Error(),
+		})
+		return
+	}
+
+	h.respondList(c, "获取待审批请假申请成功", leaves, total, req)
+}
+
+// GetLeave 获取请假申请详情
+// @Summary 获取请假申请详情
+// @Description 获取请假申请及各步骤的审批记录
+// @Tags leaves
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "请假申请ID"
+// @Success 200 {object} Response{data=domain.LeaveRequest}
+// @Failure 400 {object} ErrorResponse "请求参数错误"
+// @Failure 403 {object} ErrorResponse "无权查看"
+// @Failure 404 {object} ErrorResponse "请假申请不存在"
+// @Router /api/v1/leaves/{id} [get]
+func (h *LeaveHandler) GetLeave(c *gin.Context) {
+	actor, ok := currentActor(c)
+	if !ok {
+		return
+	}
+
+	id, ok := parseLeaveID(c)
+	if !ok {
+		return
+	}
+
+	leave, err := h.leaveService.GetLeave(actor, id)
+	if err != nil {
+		c.JSON(statusFromError(err, http.StatusInternalServerError), ErrorResponse{
+			Error:   "Failed to get leave request",
+			Message: err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, Response{
+		Code:    200,
+		Message: "获取请假申请成功",
+		Data:    leave,
+	})
+}
+
+// ApproveLeave 审批通过
+// @Summary 审批通过请假申请
+// @Description 通过当前步骤的审批。班主任只能审批本班学生待班主任审批的步骤，管理员可以审批任何步骤。审批链最后一步通过后申请即获批准，请假期间已记录为旷课的考勤改为请假（病假记为病假，其余记为事假），之后记录的考勤也自动记为请假
+// @Tags leaves
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "请假申请ID"
+// @Param decision body domain.LeaveDecisionRequest false "审批意见"
+// @Success 200 {object} Response{data=domain.LeaveRequest}
+// @Failure 400 {object} ErrorResponse "请求参数错误"
+// @Failure 403 {object} ErrorResponse "无权审批"
+// @Failure 404 {object} ErrorResponse "请假申请不存在"
+// @Failure 409 {object} ErrorResponse "请假申请已处理"
+// @Router /api/v1/leaves/{id}/approve [post]
+func (h *LeaveHandler) ApproveLeave(c *gin.Context) {
+	actor, ok := currentActor(c)
+	if !ok {
+		return
+	}
+
+	id, ok := parseLeaveID(c)
+	if !ok {
+		return
+	}
+
+	var req domain.LeaveDecisionRequest
+	if !bindOptionalJSON(c, h.validator, &req) {
+		return
+	}
+
+	leave, err := h.leaveService.ApproveLeave(actor, id, req)
+	if err != nil {
+		c.JSON(statusFromError(err, http.StatusInternalServerError), ErrorResponse{
+			Error:   "Failed to approve leave request",
+			Message: err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, Response{
+		Code:    200,
+		Message: "审批通过",
+		Data:    leave,
+	})
+}
+
+// RejectLeave 驳回请假申请
+// @Summary 驳回请假申请
+// @Description 驳回审批中的请假申请，须填写原因。审批权限同审批通过
+// @Tags leaves
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "请假申请ID"
+// @Param decision body domain.LeaveDecisionRequest true "驳回原因"
+// @Success 200 {object} Response{data=domain.LeaveRequest}
+// @Failure 400 {object} ErrorResponse "请求参数错误或未填写原因"
+// @Failure 403 {object} ErrorResponse "无权审批"
+// @Failure 404 {object} ErrorResponse "请假申请不存在"
+// @Failure 409 {object} ErrorResponse "请假申请已处理"
+// @Router /api/v1/leaves/{id}/reject [post]
+func (h *LeaveHandler) RejectLeave(c *gin.Context) {
+	actor, ok := currentActor(c)
+	if !ok {
+		return
+	}
+
+	id, ok := parseLeaveID(c)
+	if !ok {
+		return
+	}
+
+	var req domain.LeaveDecisionRequest
+	if !bindJSON(c, h.validator, &req) {
+		return
+	}
+
+	leave, err := h.leaveService.RejectLeave(actor, id, req)
+	if err != nil {
+		c.JSON(statusFromError(err, http.StatusInternalServerError), ErrorResponse{
+			Error:   "Failed to reject leave request",
+			Message: err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, Response{
+		Code:    200,
+		Message: "请假申请已驳回",
+		Data:    leave,
+	})
+}
+
+// CancelLeave 撤销请假申请
+// @Summary 撤销请假申请
+// @Description 撤销本人（子女）的请假申请：审批中的申请可以随时撤销，已批准的申请只能在请假开始前撤销
+// @Tags leaves
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "请假申请ID"
+// @Success 200 {object} Response{data=domain.LeaveRequest}
+// @Failure 403 {object} ErrorResponse "无权撤销"
+// @Failure 404 {object} ErrorResponse "请假申请不存在"
+// @Failure 409 {object} ErrorResponse "请假已开始或申请已处理"
+// @Router /api/v1/leaves/{id}/cancel [post]
+func (h *LeaveHandler) CancelLeave(c *gin.Context) {
+	actor, ok := currentActor(c)
+	if !ok {
+		return
+	}
+
+	id, ok := parseLeaveID(c)
+	if !ok {
+		return
+	}
+
+	leave, err := h.leaveService.CancelLeave(actor, id)
+	if err != nil {
+		c.JSON(statusFromError(err, http.StatusInternalServerError), ErrorResponse{
+			Error:   "Failed to cancel leave request",
+			Message: err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, Response{
+		Code:    200,
+		Message: "请假申请已撤销",
+		Data:    leave,
+	})
+}
+
+// respondList 写入分页的请假申请列表响应
+func (h *LeaveHandler) respondList(c *gin.Context, message string, leaves []*domain.LeaveRequest, total int64, req domain.LeaveListRequest) {
+	page, size := req.Page, req.Size
+	if page <= 0 {
+		page = 1
+	}
+	if size <= 0 {
+		size = 10
+	}
+
+	c.JSON(http.StatusOK, PaginatedResponse{
+		Code:    200,
+		Message: message,
+		Data:    leaves,
+		Total:   int(total),
+		Page:    page,
+		Size:    size,
+	})
+}
+
+// parseLeaveID 解析路径中的请假申请ID，失败时已写入响应
+func parseLeaveID(c *gin.Context) (int, bool) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil || id <= 0 {
+		c.JSON(http.StatusBadRequest, ErrorResponse{
+			Error:   "Invalid ID format",
+			Message: "请假申请ID格式错误",
+		})
+		return 0, false
+	}
+	return id, true
+}
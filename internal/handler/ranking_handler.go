@@ -0,0 +1,124 @@
+package handler
+
+import (
+	"net/http"
+	"strconv"
+
+	"student-management-system/internal/domain"
+	"student-management-system/internal/service"
+	"student-management-system/pkg/validator"
+
+	"github.com/gin-gonic/gin"
+)
+
+// RankingHandler 成绩排名处理器
+type RankingHandler struct {
+	rankingService *service.RankingService
+	validator      *validator.CustomValidator
+}
+
+// NewRankingHandler 创建新的成绩排名处理器
+func NewRankingHandler(rankingService *service.RankingService, validator *validator.CustomValidator) *RankingHandler {
+	return &RankingHandler{
+		rankingService: rankingService,
+		validator:      validator,
+	}
+}
+
+// GetRankings 获取成绩排名
+// @Summary 获取成绩排名
+// @Description 按学期在班级、专业或年级范围内对单科成绩（subject）、各科平均分（composite）或学期平均绩点（gpa）排名，只统计已发布的成绩。课程成绩取总评成绩，没有总评成绩时取期末成绩。指定 student_id 时只返回该学生的名次；学生和家长只能查看本人（子女）的名次
+// @Tags rankings
+// @Produce json
+// @Security BearerAuth
+// @Param semester query string true "学期"
+// @Param by query string true "排名依据" Enums(subject, composite, gpa)
+// @Param scope query string true "排名范围" Enums(class, major, grade)
+// @Param class_id query int false "班级ID，scope=class 时必填"
+// @Param major query string false "专业，scope=major 时必填"
+// @Param grade_year query int false "年级（入学年份），scope=grade 时必填"
+// @Param subject_id query int false "科目ID，by=subject 时必填"
+// @Param method query string false "名次计算方式" Enums(standard, dense) default(standard)
+// @Param scale query string false "绩点制，默认按学生专业设置"
+// @Param student_id query int false "只返回该学生的名次"
+// @Success 200 {object} Response{data=domain.RankingResult}
+// @Failure 400 {object} ErrorResponse "请求参数错误"
+// @Failure 403 {object} ErrorResponse "无权查看"
+// @Failure 404 {object} ErrorResponse "班级不存在或学生没有可排名的成绩"
+// @Router /api/v1/rankings [get]
+func (h *RankingHandler) GetRankings(c *gin.Context) {
+	actor, ok := currentActor(c)
+	if !ok {
+		return
+	}
+
+	var req domain.RankingRequest
+	if !bindQuery(c, h.validator, &req) {
+		return
+	}
+
+	h.respond(c, actor, req)
+}
+
+// GetStudentRanking 获取学生的名次
+// @Summary 获取学生的名次
+// @Description 只返回该学生在指定范围内的名次、百分位和参与排名的人数，不包含其他学生的信息
+// @Tags rankings
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "学生ID"
+// @Param semester query string true "学期"
+// @Param by query string true "排名依据" Enums(subject, composite, gpa)
+// @Param scope query string true "排名范围" Enums(class, major, grade)
+// @Param class_id query int false "班级ID，scope=class 时必填"
+// @Param major query string false "专业，scope=major 时必填"
+// @Param grade_year query int false "年级（入学年份），scope=grade 时必填"
+// @Param subject_id query int false "科目ID，by=subject 时必填"
+// @Param method query string false "名次计算方式" Enums(standard, dense) default(standard)
+// @Param scale query string false "绩点制，默认按学生专业设置"
+// @Success 200 {object} Response{data=domain.RankingResult}
+// @Failure 400 {object} ErrorResponse "请求参数错误"
+// @Failure 403 {object} ErrorResponse "无权查看"
+// @Failure 404 {object} ErrorResponse "班级不存在或学生没有可排名的成绩"
+// @Router /api/v1/students/{id}/ranking [get]
+func (h *RankingHandler) GetStudentRanking(c *gin.Context) {
+	actor, ok := currentActor(c)
+	if !ok {
+		return
+	}
+
+	studentID, err := strconv.Atoi(c.Param("id"))
+	if err != nil || studentID <= 0 {
+		c.JSON(http.StatusBadRequest, ErrorResponse{
+			Error:   "Invalid ID format",
+			Message: "学生ID格式错误",
+		})
+		return
+	}
+
+	var req domain.RankingRequest
+	if !bindQuery(c, h.validator, &req) {
+		return
+	}
+	req.StudentID = studentID
+
+	h.respond(c, actor, req)
+}
+
+// respond 计算排名并写入响应
+func (h *RankingHandler) respond(c *gin.Context, actor *domain.JWTClaims, req domain.RankingRequest) {
+	result, err := h.rankingService.GetRanking(actor, req)
+	if err != nil {
+		c.JSON(statusFromError(err, http.StatusInternalServerError), ErrorResponse{
+			Error:   "Failed to get ranking",
+			Message: err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, Response{
+		Code:    200,
+		Message: "获取成绩排名成功",
+		Data:    result,
+	})
+}
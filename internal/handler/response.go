@@ -1,5 +1,15 @@
 package handler
 
+import (
+	stderrors "errors"
+	"net/http"
+
+	"student-management-system/pkg/errors"
+	"student-management-system/pkg/validator"
+
+	"github.com/gin-gonic/gin"
+)
+
 // Response 统一响应结构
 type Response struct {
 	Code    int         `json:"code"`
@@ -22,3 +32,56 @@ type ErrorResponse struct {
 	Error   string `json:"error"`
 	Message string `json:"message"`
 }
+
+// statusFromError 从应用错误中获取HTTP状态码，非应用错误时返回 fallback
+func statusFromError(err error, fallback int) int {
+	var appErr *errors.AppError
+	if stderrors.As(err, &appErr) && appErr.HTTPStatus != 0 {
+		return appErr.HTTPStatus
+	}
+	return fallback
+}
+
+// bindJSON 解析并校验请求体，失败时已写入响应
+func bindJSON(c *gin.Context, v *validator.CustomValidator, req interface{}) bool {
+	if err := c.ShouldBindJSON(req); err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{
+			Error:   "Invalid request format",
+			Message: "请求参数错误: " + err.Error(),
+		})
+		return false
+	}
+	return validateRequest(c, v, req)
+}
+
+// bindOptionalJSON 请求体可以为空，有内容时解析并校验
+func bindOptionalJSON(c *gin.Context, v *validator.CustomValidator, req interface{}) bool {
+	if c.Request.ContentLength == 0 {
+		return true
+	}
+	return bindJSON(c, v, req)
+}
+
+// bindQuery 解析并校验查询参数，失败时已写入响应
+func bindQuery(c *gin.Context, v *validator.CustomValidator, req interface{}) bool {
+	if err := c.ShouldBindQuery(req); err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{
+			Error:   "Invalid request format",
+			Message: "请求参数错误: " + err.Error(),
+		})
+		return false
+	}
+	return validateRequest(c, v, req)
+}
+
+// validateRequest 校验已解析的请求，失败时已写入响应
+func validateRequest(c *gin.Context, v *validator.CustomValidator, req interface{}) bool {
+	if err := v.ValidateStruct(req); err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{
+			Error:   "Validation failed",
+			Message: "数据验证失败: " + err.Error(),
+		})
+		return false
+	}
+	return true
+}
@@ -0,0 +1,119 @@
+package handler
+
+import (
+	"net/http"
+	"student-management-system/internal/domain"
+	"student-management-system/internal/service"
+	"student-management-system/pkg/validator"
+
+	"github.com/gin-gonic/gin"
+)
+
+// RoleHandler 角色权限处理器
+type RoleHandler struct {
+	rbacService *service.RBACService
+	validator   *validator.CustomValidator
+}
+
+// NewRoleHandler 创建新的角色权限处理器
+func NewRoleHandler(rbacService *service.RBACService, validator *validator.CustomValidator) *RoleHandler {
+	return &RoleHandler{
+		rbacService: rbacService,
+		validator:   validator,
+	}
+}
+
+// ListRoles 获取角色列表
+// @Summary 获取角色列表
+// @Description 获取所有角色及其拥有的权限
+// @Tags roles
+// @Produce json
+// @Success 200 {object} Response
+// @Failure 500 {object} ErrorResponse
+// @Router /api/v1/roles [get]
+func (h *RoleHandler) ListRoles(c *gin.Context) {
+	roles, err := h.rbacService.ListRoles()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, ErrorResponse{
+			Error:   "Failed to list roles",
+			Message: err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, Response{
+		Code:    200,
+		Message: "获取成功",
+		Data:    roles,
+	})
+}
+
+// ListPermissions 获取权限列表
+// @Summary 获取权限列表
+// @Description 获取系统中定义的所有权限
+// @Tags roles
+// @Produce json
+// @Success 200 {object} Response
+// @Failure 500 {object} ErrorResponse
+// @Router /api/v1/permissions [get]
+func (h *RoleHandler) ListPermissions(c *gin.Context) {
+	permissions, err := h.rbacService.ListPermissions()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, ErrorResponse{
+			Error:   "Failed to list permissions",
+			Message: err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, Response{
+		Code:    200,
+		Message: "获取成功",
+		Data:    permissions,
+	})
+}
+
+// UpdateRolePermissions 更新角色权限
+// @Summary 更新角色权限
+// @Description 用请求中的权限列表替换角色现有的全部权限
+// @Tags roles
+// @Accept json
+// @Produce json
+// @Param name path string true "角色名称"
+// @Param permissions body domain.UpdateRolePermissionsRequest true "权限列表"
+// @Success 200 {object} Response
+// @Failure 400 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /api/v1/roles/{name}/permissions [put]
+func (h *RoleHandler) UpdateRolePermissions(c *gin.Context) {
+	var req domain.UpdateRolePermissionsRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{
+			Error:   "Invalid request format",
+			Message: "请求参数错误: " + err.Error(),
+		})
+		return
+	}
+
+	if err := h.validator.ValidateStruct(&req); err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{
+			Error:   "Validation failed",
+			Message: "数据验证失败: " + err.Error(),
+		})
+		return
+	}
+
+	role := c.Param("name")
+	if err := h.rbacService.UpdateRolePermissions(role, &req); err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{
+			Error:   "Failed to update role permissions",
+			Message: err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, Response{
+		Code:    200,
+		Message: "角色权限更新成功",
+	})
+}
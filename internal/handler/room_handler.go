@@ -0,0 +1,232 @@
+package handler
+
+import (
+	"net/http"
+	"strconv"
+
+	"student-management-system/internal/domain"
+	"student-management-system/internal/service"
+	"student-management-system/pkg/validator"
+
+	"github.com/gin-gonic/gin"
+)
+
+// RoomHandler 教室处理器
+type RoomHandler struct {
+	roomService *service.RoomService
+	validator   *validator.CustomValidator
+}
+
+// NewRoomHandler 创建新的教室处理器
+func NewRoomHandler(roomService *service.RoomService, validator *validator.CustomValidator) *RoomHandler {
+	return &RoomHandler{
+		roomService: roomService,
+		validator:   validator,
+	}
+}
+
+// CreateRoom 创建教室
+// @Summary 创建教室
+// @Description 创建教室，教室编号不能重复。名称默认与编号相同，类型默认为普通教室
+// @Tags rooms
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param room body domain.CreateRoomRequest true "教室信息"
+// @Success 201 {object} Response{data=domain.Room}
+// @Failure 400 {object} ErrorResponse "请求参数错误"
+// @Failure 409 {object} ErrorResponse "教室编号重复"
+// @Failure 500 {object} ErrorResponse "服务器内部错误"
+// @Router /api/v1/rooms [post]
+func (h *RoomHandler) CreateRoom(c *gin.Context) {
+	var req domain.CreateRoomRequest
+	if !bindJSON(c, h.validator, &req) {
+		return
+	}
+
+	room, err := h.roomService.CreateRoom(req)
+	if err != nil {
+		c.JSON(statusFromError(err, http.StatusInternalServerError), ErrorResponse{
+			Error:   "Failed to create room",
+			Message: err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusCreated, Response{
+		Code:    201,
+		Message: "教室创建成功",
+		Data:    room,
+	})
+}
+
+// GetRooms 获取教室列表
+// @Summary 获取教室列表
+// @Description 分页获取教室列表，可按楼栋、类型、状态和最小容量筛选
+// @Tags rooms
+// @Produce json
+// @Security BearerAuth
+// @Param page query int false "页码" default(1)
+// @Param size query int false "每页数量" default(10)
+// @Param building query string false "楼栋"
+// @Param room_type query string false "教室类型" Enums(classroom, lab, computer_lab, lecture_hall, gym, other)
+// @Param status query string false "状态" Enums(active, inactive)
+// @Param min_capacity query int false "最小容量"
+// @Success 200 {object} PaginatedResponse
+// @Failure 400 {object} ErrorResponse "请求参数错误"
+// @Failure 500 {object} ErrorResponse "服务器内部错误"
+// @Router /api/v1/rooms [get]
+func (h *RoomHandler) GetRooms(c *gin.Context) {
+	var req domain.RoomListRequest
+	if !bindQuery(c, h.validator, &req) {
+		return
+	}
+
+	rooms, total, err := h.roomService.ListRooms(req)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, ErrorResponse{
+			Error:   "Failed to get rooms",
+			Message: err.Error(),
+		})
+		return
+	}
+
+	if rooms == nil {
+		rooms = []*domain.Room{}
+	}
+
+	page, size := req.Page, req.Size
+	if page <= 0 {
+		page = 1
+	}
+	if size <= 0 {
+		size = 10
+	}
+
+	c.JSON(http.StatusOK, PaginatedResponse{
+		Code:    200,
+		Message: "获取教室列表成功",
+		Data:    rooms,
+		Total:   int(total),
+		Page:    page,
+		Size:    size,
+	})
+}
+
+// GetRoom 获取单个教室
+// @Summary 获取教室详情
+// @Description 根据ID获取教室信息
+// @Tags rooms
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "教室ID"
+// @Success 200 {object} Response{data=domain.Room}
+// @Failure 400 {object} ErrorResponse "请求参数错误"
+// @Failure 404 {object} ErrorResponse "教室不存在"
+// @Router /api/v1/rooms/{id} [get]
+func (h *RoomHandler) GetRoom(c *gin.Context) {
+	id, ok := parseRoomID(c)
+	if !ok {
+		return
+	}
+
+	room, err := h.roomService.GetRoom(id)
+	if err != nil {
+		c.JSON(statusFromError(err, http.StatusInternalServerError), ErrorResponse{
+			Error:   "Failed to get room",
+			Message: err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, Response{
+		Code:    200,
+		Message: "获取教室成功",
+		Data:    room,
+	})
+}
+
+// UpdateRoom 更新教室
+// @Summary 更新教室
+// @Description 更新教室信息。停用或缩小容量不影响已排的课表，只对之后的排课生效
+// @Tags rooms
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "教室ID"
+// @Param room body domain.UpdateRoomRequest true "更新的教室信息"
+// @Success 200 {object} Response{data=domain.Room}
+// @Failure 400 {object} ErrorResponse "请求参数错误"
+// @Failure 404 {object} ErrorResponse "教室不存在"
+// @Failure 409 {object} ErrorResponse "教室编号重复"
+// @Router /api/v1/rooms/{id} [put]
+func (h *RoomHandler) UpdateRoom(c *gin.Context) {
+	id, ok := parseRoomID(c)
+	if !ok {
+		return
+	}
+
+	var req domain.UpdateRoomRequest
+	if !bindJSON(c, h.validator, &req) {
+		return
+	}
+
+	room, err := h.roomService.UpdateRoom(id, req)
+	if err != nil {
+		c.JSON(statusFromError(err, http.StatusInternalServerError), ErrorResponse{
+			Error:   "Failed to update room",
+			Message: err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, Response{
+		Code:    200,
+		Message: "教室更新成功",
+		Data:    room,
+	})
+}
+
+// DeleteRoom 删除教室
+// @Summary 删除教室
+// @Description 删除没有排课的教室，已排课的教室请改为停用
+// @Tags rooms
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "教室ID"
+// @Success 200 {object} Response
+// @Failure 404 {object} ErrorResponse "教室不存在"
+// @Failure 409 {object} ErrorResponse "教室已排课"
+// @Router /api/v1/rooms/{id} [delete]
+func (h *RoomHandler) DeleteRoom(c *gin.Context) {
+	id, ok := parseRoomID(c)
+	if !ok {
+		return
+	}
+
+	if err := h.roomService.DeleteRoom(id); err != nil {
+		c.JSON(statusFromError(err, http.StatusInternalServerError), ErrorResponse{
+			Error:   "Failed to delete room",
+			Message: err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, Response{
+		Code:    200,
+		Message: "教室删除成功",
+	})
+}
+
+// parseRoomID 解析路径中的教室ID，失败时已写入响应
+func parseRoomID(c *gin.Context) (int, bool) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil || id <= 0 {
+		c.JSON(http.StatusBadRequest, ErrorResponse{
+			Error:   "Invalid ID format",
+			Message: "教室ID格式错误",
+		})
+		return 0, false
+	}
+	return id, true
+}
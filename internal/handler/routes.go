@@ -2,10 +2,12 @@ package handler
 
 import (
 	"student-management-system/internal/config"
+	"student-management-system/internal/domain"
 	"student-management-system/internal/repository"
 	"student-management-system/internal/service"
 	"student-management-system/pkg/logger"
 	"student-management-system/pkg/middleware"
+	"student-management-system/pkg/utils"
 	"student-management-system/pkg/validator"
 
 	"github.com/gin-gonic/gin"
@@ -39,22 +41,120 @@ func SetupRoutes(cfg *config.Config) *gin.Engine {
 	// 创建Repository实例
 	adminRepo := repository.NewAdminRepository(repository.DB, loggerInstance)
 	scoreRepo := repository.NewScoreRepository(repository.DB)
+	scoreWorkflowRepo := repository.NewScoreWorkflowRepository(repository.DB)
+	scoreHistoryRepo := repository.NewScoreHistoryRepository(repository.DB)
+	scoreAppealRepo := repository.NewScoreAppealRepository(repository.DB)
+	roleRepo := repository.NewRoleRepository(repository.DB)
+	transcriptRepo := repository.NewTranscriptRepository(repository.DB)
+	classRepo := repository.NewClassRepository(repository.DB)
+	offeringRepo := repository.NewCourseOfferingRepository(repository.DB)
+	termRepo := repository.NewTermRepository(repository.DB)
+	gradingRepo := repository.NewGradingRepository(repository.DB)
+	gradeScaleRepo := repository.NewGradeScaleRepository(repository.DB)
+	rankingRepo := repository.NewRankingRepository(repository.DB)
+	studentRepo := repository.NewStudentRepository(repository.DB)
+	roomRepo := repository.NewRoomRepository(repository.DB)
+	timetableRepo := repository.NewTimetableRepository(repository.DB)
+	examRepo := repository.NewExamRepository(repository.DB)
+	calendarFeedRepo := repository.NewCalendarFeedRepository(repository.DB)
+	attendanceRepo := repository.NewAttendanceRepository(repository.DB)
+	leaveRepo := repository.NewLeaveRepository(repository.DB)
+	studentStatusRepo := repository.NewStudentStatusRepository(repository.DB)
+
+	// 创建密码管理器
+	passwordManager, err := service.NewPasswordManager(cfg.Password)
+	if err != nil {
+		logger.WithError(err).Fatal("初始化密码管理器失败")
+	}
+
+	// 加载JWT签名与验证密钥
+	keySet, err := service.NewJWTKeySet(cfg.JWT)
+	if err != nil {
+		logger.WithError(err).Fatal("加载JWT密钥失败")
+	}
+	utils.SetKeySet(keySet)
 
 	// 创建服务实例
-	authService := service.NewAuthService(cfg, adminRepo)
+	twoFactorService := service.NewTwoFactorService(cfg.TwoFactor, adminRepo, passwordManager)
+	authService := service.NewAuthService(cfg, adminRepo, passwordManager, twoFactorService)
 	studentService := service.NewStudentService()
+	studentImportService := service.NewStudentImportService(customValidator)
+	studentStatusService := service.NewStudentStatusService(studentStatusRepo, studentRepo)
 	teacherService := service.NewTeacherService()
 	subjectService := service.NewSubjectService()
-	scoreService := service.NewScoreService(scoreRepo)
-	adminService := service.NewAdminService(adminRepo, loggerInstance)
+	gradeScaleService, err := service.NewGradeScaleService(cfg.GradeScale, gradeScaleRepo)
+	if err != nil {
+		logger.WithError(err).Fatal("加载绩点制配置失败")
+	}
+	gradingService := service.NewGradingService(gradingRepo, offeringRepo, termRepo)
+	attendanceService, err := service.NewAttendanceService(cfg.Attendance, attendanceRepo, offeringRepo, timetableRepo, termRepo, studentRepo, leaveRepo)
+	if err != nil {
+		logger.WithError(err).Fatal("加载考勤配置失败")
+	}
+	leaveService, err := service.NewLeaveService(cfg.Leave, leaveRepo, studentRepo)
+	if err != nil {
+		logger.WithError(err).Fatal("加载请假审批配置失败")
+	}
+	scoreService := service.NewScoreService(scoreRepo, scoreWorkflowRepo, scoreHistoryRepo, offeringRepo, termRepo, gradingService, gradeScaleService, attendanceService, studentRepo)
+	scoreAppealService := service.NewScoreAppealService(scoreAppealRepo, scoreRepo, offeringRepo, termRepo, scoreService)
+	adminService := service.NewAdminService(adminRepo, passwordManager, loggerInstance)
+	rbacService := service.NewRBACService(roleRepo)
+	exportService := service.NewExportService(cfg.Export, studentService, teacherService, scoreService)
+	classService := service.NewClassService(classRepo)
+	offeringService := service.NewCourseOfferingService(offeringRepo, termRepo)
+	termService := service.NewTermService(termRepo)
+	rankingService := service.NewRankingService(rankingRepo, classRepo, termRepo, gradeScaleService)
+	transcriptService, err := service.NewTranscriptService(cfg.Transcript, transcriptRepo, studentRepo, gradingRepo, gradeScaleService)
+	if err != nil {
+		logger.WithError(err).Fatal("加载成绩单模板失败")
+	}
+	roomService := service.NewRoomService(roomRepo)
+	timetableService, err := service.NewTimetableService(cfg.Timetable, timetableRepo, roomRepo, offeringRepo, classRepo, studentRepo, termRepo)
+	if err != nil {
+		logger.WithError(err).Fatal("加载课表配置失败")
+	}
+	examService := service.NewExamService(examRepo, offeringRepo, roomRepo, termRepo)
+	calendarService, err := service.NewCalendarService(cfg.Calendar, timetableService.GetPeriods(), calendarFeedRepo, timetableRepo, examRepo, termRepo, classRepo, studentRepo, offeringRepo)
+	if err != nil {
+		logger.WithError(err).Fatal("加载日历订阅配置失败")
+	}
 
 	// 创建处理器实例
 	authHandler := NewAuthHandler(authService)
 	studentHandler := NewStudentHandler(studentService, customValidator)
+	studentImportHandler := NewStudentImportHandler(studentImportService)
 	teacherHandler := NewTeacherHandler(teacherService)
 	subjectHandler := NewSubjectHandler(subjectService, customValidator)
 	scoreHandler := NewScoreHandler(scoreService)
+	scoreWorkflowHandler := NewScoreWorkflowHandler(scoreService, customValidator)
+	scoreHistoryHandler := NewScoreHistoryHandler(scoreService, customValidator)
+	scoreAppealHandler := NewScoreAppealHandler(scoreAppealService, customValidator)
 	adminHandler := NewAdminHandler(adminService, loggerInstance)
+	roleHandler := NewRoleHandler(rbacService, customValidator)
+	twoFactorHandler := NewTwoFactorHandler(twoFactorService, customValidator)
+	exportHandler := NewExportHandler(exportService, customValidator)
+	transcriptHandler := NewTranscriptHandler(transcriptService, customValidator)
+	classHandler := NewClassHandler(classService, scoreService, customValidator)
+	offeringHandler := NewCourseOfferingHandler(offeringService, customValidator)
+	termHandler := NewTermHandler(termService, customValidator)
+	gradingHandler := NewGradingHandler(gradingService, customValidator)
+	gradeScaleHandler := NewGradeScaleHandler(gradeScaleService, customValidator)
+	rankingHandler := NewRankingHandler(rankingService, customValidator)
+	roomHandler := NewRoomHandler(roomService, customValidator)
+	timetableHandler := NewTimetableHandler(timetableService, customValidator)
+	examHandler := NewExamHandler(examService, customValidator)
+	calendarHandler := NewCalendarHandler(calendarService, customValidator)
+	attendanceHandler := NewAttendanceHandler(attendanceService, customValidator)
+	leaveHandler := NewLeaveHandler(leaveService, customValidator)
+	studentStatusHandler := NewStudentStatusHandler(studentStatusService, customValidator)
+
+	// 按权限代码生成权限校验中间件
+	perm := func(permission string) gin.HandlerFunc {
+		return middleware.RequirePermission(rbacService, permission)
+	}
+
+	// JWT公钥集合，供其他服务验证本系统签发的token
+	router.GET("/.well-known/jwks.json", authHandler.JWKS)
 
 	// API路由组
 	api := router.Group("/api/v1")
@@ -62,68 +162,275 @@ func SetupRoutes(cfg *config.Config) *gin.Engine {
 		// 认证相关路由（无需认证）
 		auth := api.Group("/auth")
 		{
-			auth.POST("/login", authHandler.Login)            // 管理员登录
-			auth.POST("/validate", authHandler.ValidateToken) // 验证token
+			auth.POST("/login", authHandler.Login)              // 管理员登录
+			auth.POST("/login/2fa", authHandler.LoginTwoFactor) // 两步验证登录
+			auth.POST("/validate", authHandler.ValidateToken)   // 验证token
+			auth.POST("/refresh", authHandler.RefreshToken)     // 使用刷新token换取新token
 		}
 
+		// 成绩单核验（无需认证，供用人单位、学校等第三方核验）
+		api.GET("/transcripts/verify/:code", transcriptHandler.VerifyTranscript)
+
+		// 日历订阅内容（凭订阅令牌访问，供日历客户端定期拉取）
+		api.GET("/calendar/ics/:token", calendarHandler.GetFeed)
+
 		// 需要认证的路由组
 		protected := api.Group("")
 		protected.Use(middleware.JWTAuth()) // 应用JWT认证中间件
 		{
 			// 认证用户信息路由
-			protected.GET("/auth/profile", authHandler.GetProfile)    // 获取当前管理员信息
-			protected.POST("/auth/refresh", authHandler.RefreshToken) // 刷新token
-			protected.POST("/auth/logout", authHandler.Logout)        // 用户登出
+			protected.GET("/auth/profile", authHandler.GetProfile) // 获取当前管理员信息
+			protected.POST("/auth/logout", authHandler.Logout)     // 用户登出
+
+			// 会话管理路由
+			protected.GET("/auth/sessions", authHandler.ListSessions)           // 获取我的会话列表
+			protected.DELETE("/auth/sessions", authHandler.RevokeOtherSessions) // 撤销其他会话
+			protected.DELETE("/auth/sessions/:id", authHandler.RevokeSession)   // 撤销指定会话
+
+			// 两步验证路由
+			protected.GET("/auth/2fa", twoFactorHandler.GetStatus)                               // 获取两步验证状态
+			protected.POST("/auth/2fa/enroll", twoFactorHandler.Enroll)                          // 生成认证器密钥
+			protected.POST("/auth/2fa/confirm", twoFactorHandler.Confirm)                        // 确认绑定并启用
+			protected.POST("/auth/2fa/disable", twoFactorHandler.Disable)                        // 关闭两步验证
+			protected.POST("/auth/2fa/recovery-codes", twoFactorHandler.RegenerateRecoveryCodes) // 重新生成恢复码
 
 			// 学生相关路由（需要认证）
 			students := protected.Group("/students")
 			{
-				students.POST("", studentHandler.CreateStudent)       // 创建学生
-				students.GET("", studentHandler.GetStudents)          // 获取学生列表
-				students.GET("/:id", studentHandler.GetStudent)       // 获取单个学生
-				students.PUT("/:id", studentHandler.UpdateStudent)    // 更新学生
-				students.DELETE("/:id", studentHandler.DeleteStudent) // 删除学生
+				students.POST("", perm(domain.PermStudentsWrite), studentHandler.CreateStudent)                             // 创建学生
+				students.GET("", perm(domain.PermStudentsRead), studentHandler.GetStudents)                                 // 获取学生列表
+				students.POST("/import", perm(domain.PermStudentsWrite), studentImportHandler.ImportStudents)               // 批量导入学生
+				students.GET("/export", perm(domain.PermStudentsRead), exportHandler.ExportStudents)                        // 导出学生
+				students.GET("/:id", perm(domain.PermStudentsRead), studentHandler.GetStudent)                              // 获取单个学生
+				students.PUT("/:id", perm(domain.PermStudentsWrite), studentHandler.UpdateStudent)                          // 更新学生
+				students.DELETE("/:id", perm(domain.PermStudentsWrite), studentHandler.DeleteStudent)                       // 删除学生
+				students.GET("/:id/transcript", perm(domain.PermScoresRead), transcriptHandler.GetTranscript)               // 生成历年成绩单
+				students.GET("/:id/report-card", perm(domain.PermScoresRead), transcriptHandler.GetReportCard)              // 生成学期成绩报告单
+				students.GET("/:id/classes", perm(domain.PermStudentsRead), classHandler.GetStudentClasses)                 // 获取学生分班历史
+				students.GET("/:id/enrollments", perm(domain.PermOfferingsRead), offeringHandler.GetStudentEnrollments)     // 获取学生选课记录
+				students.GET("/:id/final-grades", perm(domain.PermScoresRead), gradingHandler.GetStudentFinalGrades)        // 获取学生总评成绩
+				students.GET("/:id/score-history", perm(domain.PermScoresRead), scoreHistoryHandler.GetStudentScoreHistory) // 获取学生成绩变更记录
+				students.GET("/:id/ranking", perm(domain.PermRankingsRead), rankingHandler.GetStudentRanking)               // 获取学生的名次
+				students.GET("/:id/timetable", perm(domain.PermTimetableRead), timetableHandler.GetStudentTimetable)        // 获取学生课表
+				students.GET("/:id/attendance", perm(domain.PermAttendanceRead), attendanceHandler.GetStudentAttendance)    // 获取学生学期考勤汇总
+				students.GET("/:id/status", perm(domain.PermStudentsRead), studentStatusHandler.GetStudentStatus)           // 获取学籍状态及异动记录
+				students.POST("/:id/status", perm(domain.PermStudentsWrite), studentStatusHandler.ChangeStudentStatus)      // 变更学籍状态
+			}
+
+			// 班级相关路由（需要认证）
+			classes := protected.Group("/classes")
+			{
+				classes.POST("", perm(domain.PermClassesWrite), classHandler.CreateClass)                              // 创建班级
+				classes.GET("", perm(domain.PermClassesRead), classHandler.GetClasses)                                 // 获取班级列表
+				classes.GET("/:id", perm(domain.PermClassesRead), classHandler.GetClass)                               // 获取单个班级
+				classes.PUT("/:id", perm(domain.PermClassesWrite), classHandler.UpdateClass)                           // 更新班级
+				classes.DELETE("/:id", perm(domain.PermClassesWrite), classHandler.DeleteClass)                        // 删除班级
+				classes.GET("/:id/students", perm(domain.PermClassesRead), classHandler.GetClassStudents)              // 获取班级学生
+				classes.POST("/:id/students", perm(domain.PermClassesWrite), classHandler.AssignStudents)              // 学生分班
+				classes.DELETE("/:id/students/:student_id", perm(domain.PermClassesWrite), classHandler.RemoveStudent) // 学生离班
+				classes.GET("/:id/statistics", perm(domain.PermStatisticsRead), classHandler.GetClassStatistics)       // 班级成绩统计
+				classes.GET("/:id/timetable", perm(domain.PermTimetableRead), timetableHandler.GetClassTimetable)      // 获取班级课表
+			}
+
+			// 学期路由（需要认证）
+			terms := protected.Group("/terms")
+			{
+				terms.POST("", perm(domain.PermTermsWrite), termHandler.CreateTerm)                               // 创建学期
+				terms.GET("", perm(domain.PermTermsRead), termHandler.GetTerms)                                   // 获取学期列表
+				terms.GET("/current", perm(domain.PermTermsRead), termHandler.GetCurrentTerm)                     // 获取当前学期
+				terms.GET("/:id", perm(domain.PermTermsRead), termHandler.GetTerm)                                // 获取单个学期
+				terms.PUT("/:id", perm(domain.PermTermsWrite), termHandler.UpdateTerm)                            // 更新学期
+				terms.PUT("/:id/status", perm(domain.PermTermsWrite), termHandler.UpdateTermStatus)               // 变更学期状态
+				terms.PUT("/:id/current", perm(domain.PermTermsWrite), termHandler.SetCurrentTerm)                // 设为当前学期
+				terms.DELETE("/:id", perm(domain.PermTermsWrite), termHandler.DeleteTerm)                         // 删除学期
+				terms.GET("/:id/holidays", perm(domain.PermTermsRead), termHandler.GetHolidays)                   // 获取学期节假日
+				terms.POST("/:id/holidays", perm(domain.PermTermsWrite), termHandler.CreateHoliday)               // 创建学期节假日
+				terms.DELETE("/:id/holidays/:holiday_id", perm(domain.PermTermsWrite), termHandler.DeleteHoliday) // 删除学期节假日
+			}
+
+			// 绩点制路由（需要认证）
+			gradeScales := protected.Group("/grade-scales")
+			{
+				gradeScales.GET("", perm(domain.PermGradeScalesRead), gradeScaleHandler.GetGradeScales)                     // 获取绩点制列表
+				gradeScales.POST("", perm(domain.PermGradeScalesWrite), gradeScaleHandler.CreateGradeScale)                 // 创建绩点制
+				gradeScales.GET("/majors", perm(domain.PermGradeScalesRead), gradeScaleHandler.GetMajorScales)              // 获取各专业的绩点制
+				gradeScales.PUT("/majors/:major", perm(domain.PermGradeScalesWrite), gradeScaleHandler.SetMajorScale)       // 设置专业的绩点制
+				gradeScales.DELETE("/majors/:major", perm(domain.PermGradeScalesWrite), gradeScaleHandler.DeleteMajorScale) // 取消专业的绩点制
+				gradeScales.GET("/:name", perm(domain.PermGradeScalesRead), gradeScaleHandler.GetGradeScale)                // 获取绩点制
+				gradeScales.PUT("/:name", perm(domain.PermGradeScalesWrite), gradeScaleHandler.UpdateGradeScale)            // 更新绩点制
+				gradeScales.DELETE("/:name", perm(domain.PermGradeScalesWrite), gradeScaleHandler.DeleteGradeScale)         // 删除绩点制
+			}
+
+			// 开课与选课路由（需要认证）
+			offerings := protected.Group("/course-offerings")
+			{
+				offerings.POST("", perm(domain.PermOfferingsWrite), offeringHandler.CreateOffering)                                         // 创建开课
+				offerings.GET("", perm(domain.PermOfferingsRead), offeringHandler.GetOfferings)                                             // 获取开课列表
+				offerings.GET("/:id", perm(domain.PermOfferingsRead), offeringHandler.GetOffering)                                          // 获取单个开课
+				offerings.PUT("/:id", perm(domain.PermOfferingsWrite), offeringHandler.UpdateOffering)                                      // 更新开课
+				offerings.DELETE("/:id", perm(domain.PermOfferingsWrite), offeringHandler.DeleteOffering)                                   // 删除开课
+				offerings.GET("/:id/enrollments", perm(domain.PermOfferingsRead), offeringHandler.GetEnrollments)                           // 获取选课名单
+				offerings.POST("/:id/enrollments", perm(domain.PermEnrollmentsWrite), offeringHandler.Enroll)                               // 选课
+				offerings.DELETE("/:id/enrollments/:student_id", perm(domain.PermEnrollmentsWrite), offeringHandler.Drop)                   // 退课
+				offerings.GET("/:id/grading-scheme", perm(domain.PermOfferingsRead), gradingHandler.GetOfferingScheme)                      // 获取开课评分方案
+				offerings.PUT("/:id/grading-scheme", perm(domain.PermOfferingsWrite), gradingHandler.SetOfferingScheme)                     // 设置开课评分方案
+				offerings.DELETE("/:id/grading-scheme", perm(domain.PermOfferingsWrite), gradingHandler.DeleteOfferingScheme)               // 删除开课评分方案
+				offerings.GET("/:id/final-grades", perm(domain.PermScoresRead), gradingHandler.GetOfferingFinalGrades)                      // 获取开课总评成绩
+				offerings.POST("/:id/final-grades/recompute", perm(domain.PermOfferingsWrite), gradingHandler.RecomputeOfferingFinalGrades) // 重新计算总评成绩
+				offerings.GET("/:id/scores/status", perm(domain.PermScoresRead), scoreWorkflowHandler.GetOfferingScoreStatus)               // 获取开课成绩状态
+				offerings.POST("/:id/scores/submit", perm(domain.PermScoresWrite), scoreWorkflowHandler.SubmitScores)                       // 提交开课成绩
+				offerings.POST("/:id/scores/approve", perm(domain.PermScoresApprove), scoreWorkflowHandler.ApproveScores)                   // 审核通过开课成绩
+				offerings.POST("/:id/scores/reject", perm(domain.PermScoresApprove), scoreWorkflowHandler.RejectScores)                     // 退回开课成绩
+				offerings.POST("/:id/scores/publish", perm(domain.PermScoresApprove), scoreWorkflowHandler.PublishScores)                   // 发布开课成绩
+				offerings.POST("/:id/scores/lock", perm(domain.PermScoresApprove), scoreWorkflowHandler.LockScores)                         // 锁定开课成绩
+			}
+
+			// 教室路由（需要认证）
+			rooms := protected.Group("/rooms")
+			{
+				rooms.POST("", perm(domain.PermTimetableWrite), roomHandler.CreateRoom)                        // 创建教室
+				rooms.GET("", perm(domain.PermTimetableRead), roomHandler.GetRooms)                            // 获取教室列表
+				rooms.GET("/:id", perm(domain.PermTimetableRead), roomHandler.GetRoom)                         // 获取单个教室
+				rooms.PUT("/:id", perm(domain.PermTimetableWrite), roomHandler.UpdateRoom)                     // 更新教室
+				rooms.DELETE("/:id", perm(domain.PermTimetableWrite), roomHandler.DeleteRoom)                  // 删除教室
+				rooms.GET("/:id/timetable", perm(domain.PermTimetableRead), timetableHandler.GetRoomTimetable) // 获取教室课表
+			}
+
+			// 课表路由（需要认证）
+			timetable := protected.Group("/timetable")
+			{
+				timetable.GET("/periods", perm(domain.PermTimetableRead), timetableHandler.GetPeriods)           // 获取节次时间
+				timetable.POST("/slots", perm(domain.PermTimetableWrite), timetableHandler.CreateSlot)           // 创建课表时段
+				timetable.GET("/slots", perm(domain.PermTimetableRead), timetableHandler.GetSlots)               // 获取课表时段列表
+				timetable.GET("/slots/:id", perm(domain.PermTimetableRead), timetableHandler.GetSlot)            // 获取单个课表时段
+				timetable.PUT("/slots/:id", perm(domain.PermTimetableWrite), timetableHandler.UpdateSlot)        // 更新课表时段
+				timetable.DELETE("/slots/:id", perm(domain.PermTimetableWrite), timetableHandler.DeleteSlot)     // 删除课表时段
+				timetable.POST("/auto-schedule", perm(domain.PermTimetableWrite), timetableHandler.AutoSchedule) // 自动排课
+			}
+
+			// 考试安排路由（需要认证）
+			exams := protected.Group("/exams")
+			{
+				exams.POST("", perm(domain.PermExamsWrite), examHandler.CreateExam)       // 创建考试安排
+				exams.GET("", perm(domain.PermTimetableRead), examHandler.GetExams)       // 获取考试安排列表
+				exams.GET("/:id", perm(domain.PermTimetableRead), examHandler.GetExam)    // 获取单个考试安排
+				exams.PUT("/:id", perm(domain.PermExamsWrite), examHandler.UpdateExam)    // 更新考试安排
+				exams.DELETE("/:id", perm(domain.PermExamsWrite), examHandler.DeleteExam) // 删除考试安排
+			}
+
+			// 考勤路由（教师只能记录本人任教课程的考勤）
+			attendance := protected.Group("/attendance")
+			{
+				attendance.POST("/sessions", perm(domain.PermAttendanceWrite), attendanceHandler.RecordAttendance)    // 批量记录考勤
+				attendance.GET("/sessions", perm(domain.PermAttendanceRead), attendanceHandler.GetSessions)           // 获取考勤课次列表
+				attendance.GET("/sessions/:id", perm(domain.PermAttendanceRead), attendanceHandler.GetSession)        // 获取考勤课次详情
+				attendance.DELETE("/sessions/:id", perm(domain.PermAttendanceWrite), attendanceHandler.DeleteSession) // 删除考勤课次
+				attendance.GET("/summary", perm(domain.PermAttendanceRead), attendanceHandler.GetSummary)             // 获取学期考勤汇总
+			}
+
+			// 请假路由（学生和家长只能为本人（子女）请假，班主任只能审批本班学生的请假）
+			leaves := protected.Group("/leaves")
+			{
+				leaves.POST("", perm(domain.PermLeavesSubmit), leaveHandler.SubmitLeave)               // 提交请假申请
+				leaves.GET("", perm(domain.PermAttendanceRead), leaveHandler.GetLeaves)                // 获取请假申请列表
+				leaves.GET("/queue", perm(domain.PermLeavesApprove), leaveHandler.GetLeaveQueue)       // 获取待本人审批的请假申请
+				leaves.GET("/:id", perm(domain.PermAttendanceRead), leaveHandler.GetLeave)             // 获取请假申请详情
+				leaves.POST("/:id/approve", perm(domain.PermLeavesApprove), leaveHandler.ApproveLeave) // 审批通过
+				leaves.POST("/:id/reject", perm(domain.PermLeavesApprove), leaveHandler.RejectLeave)   // 驳回请假申请
+				leaves.POST("/:id/cancel", perm(domain.PermLeavesSubmit), leaveHandler.CancelLeave)    // 撤销请假申请
+			}
+
+			// 日历订阅路由（只能管理本人创建的订阅）
+			calendar := protected.Group("/calendar/feeds")
+			{
+				calendar.POST("", perm(domain.PermCalendarSubscribe), calendarHandler.CreateFeed)       // 创建日历订阅
+				calendar.GET("", perm(domain.PermCalendarSubscribe), calendarHandler.GetFeeds)          // 获取我的日历订阅
+				calendar.DELETE("/:id", perm(domain.PermCalendarSubscribe), calendarHandler.RevokeFeed) // 撤销日历订阅
 			}
 
 			// 老师相关路由（需要认证）
 			teachers := protected.Group("/teachers")
 			{
-				teachers.POST("", teacherHandler.CreateTeacher)       // 创建老师
-				teachers.GET("", teacherHandler.GetTeachers)          // 获取老师列表
-				teachers.GET("/:id", teacherHandler.GetTeacher)       // 获取单个老师
-				teachers.PUT("/:id", teacherHandler.UpdateTeacher)    // 更新老师
-				teachers.DELETE("/:id", teacherHandler.DeleteTeacher) // 删除老师
+				teachers.POST("", perm(domain.PermTeachersWrite), teacherHandler.CreateTeacher)                      // 创建老师
+				teachers.GET("", perm(domain.PermTeachersRead), teacherHandler.GetTeachers)                          // 获取老师列表
+				teachers.GET("/export", perm(domain.PermTeachersRead), exportHandler.ExportTeachers)                 // 导出老师
+				teachers.GET("/:id", perm(domain.PermTeachersRead), teacherHandler.GetTeacher)                       // 获取单个老师
+				teachers.PUT("/:id", perm(domain.PermTeachersWrite), teacherHandler.UpdateTeacher)                   // 更新老师
+				teachers.DELETE("/:id", perm(domain.PermTeachersWrite), teacherHandler.DeleteTeacher)                // 删除老师
+				teachers.GET("/:id/timetable", perm(domain.PermTimetableRead), timetableHandler.GetTeacherTimetable) // 获取老师课表
 			}
 
 			// 科目相关路由（需要认证）
 			subjects := protected.Group("/subjects")
 			{
-				subjects.POST("", subjectHandler.CreateSubject)       // 创建科目
-				subjects.GET("", subjectHandler.GetSubjects)          // 获取科目列表
-				subjects.GET("/:id", subjectHandler.GetSubject)       // 获取单个科目
-				subjects.PUT("/:id", subjectHandler.UpdateSubject)    // 更新科目
-				subjects.DELETE("/:id", subjectHandler.DeleteSubject) // 删除科目
+				subjects.POST("", perm(domain.PermSubjectsWrite), subjectHandler.CreateSubject)                            // 创建科目
+				subjects.GET("", perm(domain.PermSubjectsRead), subjectHandler.GetSubjects)                                // 获取科目列表
+				subjects.GET("/:id", perm(domain.PermSubjectsRead), subjectHandler.GetSubject)                             // 获取单个科目
+				subjects.PUT("/:id", perm(domain.PermSubjectsWrite), subjectHandler.UpdateSubject)                         // 更新科目
+				subjects.DELETE("/:id", perm(domain.PermSubjectsWrite), subjectHandler.DeleteSubject)                      // 删除科目
+				subjects.GET("/:id/grading-scheme", perm(domain.PermSubjectsRead), gradingHandler.GetSubjectScheme)        // 获取科目评分方案
+				subjects.PUT("/:id/grading-scheme", perm(domain.PermSubjectsWrite), gradingHandler.SetSubjectScheme)       // 设置科目评分方案
+				subjects.DELETE("/:id/grading-scheme", perm(domain.PermSubjectsWrite), gradingHandler.DeleteSubjectScheme) // 删除科目评分方案
 			}
 
 			// 成绩相关路由（需要认证）
 			scores := protected.Group("/scores")
 			{
-				scores.POST("", scoreHandler.CreateScore)       // 创建成绩
-				scores.GET("", scoreHandler.GetScores)          // 获取成绩列表
-				scores.GET("/:id", scoreHandler.GetScore)       // 获取单个成绩
-				scores.PUT("/:id", scoreHandler.UpdateScore)    // 更新成绩
-				scores.DELETE("/:id", scoreHandler.DeleteScore) // 删除成绩
+				scores.POST("", perm(domain.PermScoresWrite), scoreHandler.CreateScore)                      // 创建成绩
+				scores.GET("", perm(domain.PermScoresRead), scoreHandler.GetScores)                          // 获取成绩列表
+				scores.GET("/export", perm(domain.PermScoresRead), exportHandler.ExportScores)               // 导出成绩
+				scores.GET("/:id", perm(domain.PermScoresRead), scoreHandler.GetScore)                       // 获取单个成绩
+				scores.PUT("/:id", perm(domain.PermScoresWrite), scoreHandler.UpdateScore)                   // 更新成绩
+				scores.DELETE("/:id", perm(domain.PermScoresWrite), scoreHandler.DeleteScore)                // 删除成绩
+				scores.GET("/:id/history", perm(domain.PermScoresRead), scoreHistoryHandler.GetScoreHistory) // 获取成绩变更记录（只读，不提供删除接口）
+				scores.POST("/:id/appeals", perm(domain.PermAppealsSubmit), scoreAppealHandler.SubmitAppeal) // 提交成绩复核申请
+
+				// 成绩更正申请
+				scores.POST("/:id/change-requests", perm(domain.PermScoresWrite), scoreWorkflowHandler.RequestGradeChange)           // 提交成绩更正申请
+				scores.GET("/change-requests", perm(domain.PermScoresWrite), scoreWorkflowHandler.GetGradeChangeRequests)            // 获取成绩更正申请列表
+				scores.POST("/change-requests/:id/approve", perm(domain.PermScoresApprove), scoreWorkflowHandler.ApproveGradeChange) // 批准成绩更正申请
+				scores.POST("/change-requests/:id/reject", perm(domain.PermScoresApprove), scoreWorkflowHandler.RejectGradeChange)   // 驳回成绩更正申请
+
+				// 成绩报告与统计
+				scores.GET("/reports/students/:student_id", perm(domain.PermScoresRead), scoreHandler.GetStudentReport)            // 学生学期成绩报告
+				scores.GET("/statistics/subjects/:subject_id", perm(domain.PermStatisticsRead), scoreHandler.GetSubjectStatistics) // 科目成绩分段统计
+				scores.GET("/statistics/classes", perm(domain.PermStatisticsRead), scoreHandler.GetClassStatistics)                // 班级成绩统计
 			}
 
+			// 成绩复核路由（学生和家长只能查看本人（子女）的申请，教师只能处理分配给本人的申请）
+			appeals := protected.Group("/appeals")
+			{
+				appeals.GET("", perm(domain.PermScoresRead), scoreAppealHandler.GetAppeals)                    // 获取成绩复核申请列表
+				appeals.GET("/queue", perm(domain.PermAppealsReview), scoreAppealHandler.GetAppealQueue)       // 获取待处理的复核申请
+				appeals.GET("/:id", perm(domain.PermScoresRead), scoreAppealHandler.GetAppeal)                 // 获取成绩复核申请
+				appeals.POST("/:id/review", perm(domain.PermAppealsReview), scoreAppealHandler.StartReview)    // 受理成绩复核申请
+				appeals.POST("/:id/resolve", perm(domain.PermAppealsReview), scoreAppealHandler.ResolveAppeal) // 给出成绩复核结论
+			}
+
+			// 成绩排名路由（学生和家长只能查看本人（子女）的名次）
+			protected.GET("/rankings", perm(domain.PermRankingsRead), rankingHandler.GetRankings) // 获取成绩排名
+
 			// 管理员相关路由（需要认证）
 			admins := protected.Group("/admins")
 			{
-				admins.POST("", adminHandler.CreateAdmin)       // 创建管理员
-				admins.GET("", adminHandler.ListAdmins)         // 获取管理员列表
-				admins.GET("/:id", adminHandler.GetAdmin)       // 获取单个管理员
-				admins.PUT("/:id", adminHandler.UpdateAdmin)    // 更新管理员
-				admins.DELETE("/:id", adminHandler.DeleteAdmin) // 删除管理员
+				admins.POST("", perm(domain.PermAdminsWrite), adminHandler.CreateAdmin)                  // 创建管理员
+				admins.GET("", perm(domain.PermAdminsRead), adminHandler.ListAdmins)                     // 获取管理员列表
+				admins.GET("/:id", perm(domain.PermAdminsRead), adminHandler.GetAdmin)                   // 获取单个管理员
+				admins.PUT("/:id", perm(domain.PermAdminsWrite), adminHandler.UpdateAdmin)               // 更新管理员
+				admins.DELETE("/:id", perm(domain.PermAdminsWrite), adminHandler.DeleteAdmin)            // 删除管理员
+				admins.GET("/:id/sessions", perm(domain.PermAdminsRead), adminHandler.GetAdminSessions)  // 获取账号会话列表
+				admins.POST("/:id/logout", perm(domain.PermAdminsWrite), adminHandler.ForceLogout)       // 强制账号登出
+				admins.POST("/:id/2fa/reset", perm(domain.PermAdminsWrite), adminHandler.ResetTwoFactor) // 重置两步验证
 			}
+
+			// 后台导出任务路由（只能访问本人创建的任务）
+			protected.GET("/exports/:id", exportHandler.GetExportJob)            // 获取导出任务状态
+			protected.GET("/exports/:id/download", exportHandler.DownloadExport) // 下载导出文件
+
+			// 角色权限管理路由
+			protected.GET("/roles", perm(domain.PermAdminsRead), roleHandler.ListRoles)                                // 获取角色列表
+			protected.GET("/permissions", perm(domain.PermAdminsRead), roleHandler.ListPermissions)                    // 获取权限列表
+			protected.PUT("/roles/:name/permissions", perm(domain.PermAdminsWrite), roleHandler.UpdateRolePermissions) // 更新角色权限
 		}
 	}
 
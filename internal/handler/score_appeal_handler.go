@@ -0,0 +1,305 @@
+package handler
+
+import (
+	"net/http"
+	"strconv"
+
+	"student-management-system/internal/domain"
+	"student-management-system/internal/service"
+	"student-management-system/pkg/validator"
+
+	"github.com/gin-gonic/gin"
+)
+
+// ScoreAppealHandler 成绩复核处理器
+type ScoreAppealHandler struct {
+	appealService *service.ScoreAppealService
+	validator     *validator.CustomValidator
+}
+
+// NewScoreAppealHandler 创建新的成绩复核处理器
+func NewScoreAppealHandler(appealService *service.ScoreAppealService, validator *validator.CustomValidator) *ScoreAppealHandler {
+	return &ScoreAppealHandler{
+		appealService: appealService,
+		validator:     validator,
+	}
+}
+
+// SubmitAppeal 提交成绩复核申请
+// @Summary 提交成绩复核申请
+// @Description 学生或家长对本人（子女）已发布的成绩申请复核，需填写理由，可附上已上传材料的地址。同一成绩同时只能有一条未结案的申请
+// @Tags score-appeals
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "成绩ID"
+// @Param appeal body domain.CreateScoreAppealRequest true "复核申请"
+// @Success 201 {object} Response{data=domain.ScoreAppeal}
+// @Failure 400 {object} ErrorResponse "请求参数错误"
+// @Failure 403 {object} ErrorResponse "无权申请"
+// @Failure 404 {object} ErrorResponse "成绩不存在"
+// @Failure 409 {object} ErrorResponse "已有正在处理的申请或学期已结束"
+// @Router /api/v1/scores/{id}/appeals [post]
+func (h *ScoreAppealHandler) SubmitAppeal(c *gin.Context) {
+	actor, ok := currentActor(c)
+	if !ok {
+		return
+	}
+
+	id, ok := parseScoreID(c)
+	if !ok {
+		return
+	}
+
+	var req domain.CreateScoreAppealRequest
+	if !bindJSON(c, h.validator, &req) {
+		return
+	}
+
+	appeal, err := h.appealService.SubmitAppeal(actor, id, req)
+	if err != nil {
+		c.JSON(statusFromError(err, http.StatusInternalServerError), ErrorResponse{
+			Error:   "Failed to submit score appeal",
+			Message: err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusCreated, Response{
+		Code:    201,
+		Message: "成绩复核申请已提交",
+		Data:    appeal,
+	})
+}
+
+// GetAppeals 获取成绩复核申请列表
+// @Summary 获取成绩复核申请列表
+// @Description 学生和家长只能查看本人（子女）的申请，教师只能查看分配给本人的申请
+// @Tags score-appeals
+// @Produce json
+// @Security BearerAuth
+// @Param page query int false "页码" default(1)
+// @Param size query int false "每页数量" default(10)
+// @Param status query string false "状态" Enums(submitted, under_review, upheld, adjusted)
+// @Param student_id query int false "学生ID"
+// @Param subject_id query int false "科目ID"
+// @Success 200 {object} PaginatedResponse{data=[]domain.ScoreAppeal}
+// @Failure 400 {object} ErrorResponse "请求参数错误"
+// @Failure 403 {object} ErrorResponse "无权查看"
+// @Router /api/v1/appeals [get]
+func (h *ScoreAppealHandler) GetAppeals(c *gin.Context) {
+	actor, ok := currentActor(c)
+	if !ok {
+		return
+	}
+
+	var req domain.ScoreAppealListRequest
+	if !bindQuery(c, h.validator, &req) {
+		return
+	}
+
+	appeals, total, err := h.appealService.ListAppeals(actor, req)
+	if err != nil {
+		c.JSON(statusFromError(err, http.StatusInternalServerError), ErrorResponse{
+			Error:   "Failed to get score appeals",
+			Message: err.Error(),
+		})
+		return
+	}
+
+	h.respondList(c, "获取成绩复核申请列表成功", appeals, total, req)
+}
+
+// GetAppealQueue 获取待处理的成绩复核申请
+// @Summary 获取待处理的成绩复核申请
+// @Description 获取已提交和复核中的申请，按提交时间先后排列。教师只能看到分配给本人的申请，管理员可以看到全部申请
+// @Tags score-appeals
+// @Produce json
+// @Security BearerAuth
+// @Param page query int false "页码" default(1)
+// @Param size query int false "每页数量" default(10)
+// @Param student_id query int false "学生ID"
+// @Param subject_id query int false "科目ID"
+// @Success 200 {object} PaginatedResponse{data=[]domain.ScoreAppeal}
+// @Failure 400 {object} ErrorResponse "请求参数错误"
+// @Failure 403 {object} ErrorResponse "无权查看"
+// @Router /api/v1/appeals/queue [get]
+func (h *ScoreAppealHandler) GetAppealQueue(c *gin.Context) {
+	actor, ok := currentActor(c)
+	if !ok {
+		return
+	}
+
+	var req domain.ScoreAppealListRequest
+	if !bindQuery(c, h.validator, &req) {
+		return
+	}
+
+	appeals, total, err := h.appealService.ListQueue(actor, req)
+	if err != nil {
+		c.JSON(statusFromError(err, http.StatusInternalServerError), ErrorResponse{
+			Error:   "Failed to get score appeal queue",
+			Message: err.Error(),
+		})
+		return
+	}
+
+	h.respondList(c, "获取待处理复核申请成功", appeals, total, req)
+}
+
+// GetAppeal 获取成绩复核申请
+// @Summary 获取成绩复核申请
+// @Description 根据ID获取成绩复核申请详情
+// @Tags score-appeals
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "申请ID"
+// @Success 200 {object} Response{data=domain.ScoreAppeal}
+// @Failure 403 {object} ErrorResponse "无权查看"
+// @Failure 404 {object} ErrorResponse "申请不存在"
+// @Router /api/v1/appeals/{id} [get]
+func (h *ScoreAppealHandler) GetAppeal(c *gin.Context) {
+	actor, ok := currentActor(c)
+	if !ok {
+		return
+	}
+
+	id, ok := parseAppealID(c)
+	if !ok {
+		return
+	}
+
+	appeal, err := h.appealService.GetAppeal(actor, id)
+	if err != nil {
+		c.JSON(statusFromError(err, http.StatusInternalServerError), ErrorResponse{
+			Error:   "Failed to get score appeal",
+			Message: err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, Response{
+		Code:    200,
+		Message: "获取成绩复核申请成功",
+		Data:    appeal,
+	})
+}
+
+// StartReview 受理成绩复核申请
+// @Summary 受理成绩复核申请
+// @Description 将已提交的申请标记为复核中。管理员可以受理全部申请，教师只能受理分配给本人的申请
+// @Tags score-appeals
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "申请ID"
+// @Success 200 {object} Response{data=domain.ScoreAppeal}
+// @Failure 403 {object} ErrorResponse "无权受理"
+// @Failure 404 {object} ErrorResponse "申请不存在"
+// @Failure 409 {object} ErrorResponse "申请已受理或已结案"
+// @Router /api/v1/appeals/{id}/review [post]
+func (h *ScoreAppealHandler) StartReview(c *gin.Context) {
+	actor, ok := currentActor(c)
+	if !ok {
+		return
+	}
+
+	id, ok := parseAppealID(c)
+	if !ok {
+		return
+	}
+
+	appeal, err := h.appealService.StartReview(actor, id)
+	if err != nil {
+		c.JSON(statusFromError(err, http.StatusInternalServerError), ErrorResponse{
+			Error:   "Failed to start score appeal review",
+			Message: err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, Response{
+		Code:    200,
+		Message: "复核申请已受理",
+		Data:    appeal,
+	})
+}
+
+// ResolveAppeal 给出成绩复核结论
+// @Summary 给出成绩复核结论
+// @Description 对复核中的申请给出结论：upheld 维持原成绩，adjusted 调整为 adjusted_score。调整成绩通过成绩修改流程写入，并记入成绩变更记录
+// @Tags score-appeals
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "申请ID"
+// @Param resolution body domain.ResolveScoreAppealRequest true "复核结论"
+// @Success 200 {object} Response{data=domain.ScoreAppeal}
+// @Failure 400 {object} ErrorResponse "请求参数错误"
+// @Failure 403 {object} ErrorResponse "无权审理"
+// @Failure 404 {object} ErrorResponse "申请不存在"
+// @Failure 409 {object} ErrorResponse "申请尚未受理、已结案或学期已结束"
+// @Router /api/v1/appeals/{id}/resolve [post]
+func (h *ScoreAppealHandler) ResolveAppeal(c *gin.Context) {
+	actor, ok := currentActor(c)
+	if !ok {
+		return
+	}
+
+	id, ok := parseAppealID(c)
+	if !ok {
+		return
+	}
+
+	var req domain.ResolveScoreAppealRequest
+	if !bindJSON(c, h.validator, &req) {
+		return
+	}
+
+	appeal, err := h.appealService.ResolveAppeal(actor, id, req, domain.ScoreChangeMeta{IP: c.ClientIP()})
+	if err != nil {
+		c.JSON(statusFromError(err, http.StatusInternalServerError), ErrorResponse{
+			Error:   "Failed to resolve score appeal",
+			Message: err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, Response{
+		Code:    200,
+		Message: "复核结论已保存",
+		Data:    appeal,
+	})
+}
+
+// respondList 写入分页的复核申请列表响应
+func (h *ScoreAppealHandler) respondList(c *gin.Context, message string, appeals []*domain.ScoreAppeal, total int64, req domain.ScoreAppealListRequest) {
+	page, size := req.Page, req.Size
+	if page <= 0 {
+		page = 1
+	}
+	if size <= 0 {
+		size = 10
+	}
+
+	c.JSON(http.StatusOK, PaginatedResponse{
+		Code:    200,
+		Message: message,
+		Data:    appeals,
+		Total:   int(total),
+		Page:    page,
+		Size:    size,
+	})
+}
+
+// parseAppealID 解析路径中的复核申请ID，失败时已写入响应
+func parseAppealID(c *gin.Context) (int, bool) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil || id <= 0 {
+		c.JSON(http.StatusBadRequest, ErrorResponse{
+			Error:   "Invalid ID format",
+			Message: "申请ID格式错误",
+		})
+		return 0, false
+	}
+	return id, true
+}
@@ -5,6 +5,7 @@ import (
 	"strconv"
 	"student-management-system/internal/domain"
 	"student-management-system/internal/service"
+	"student-management-system/pkg/middleware"
 
 	"github.com/gin-gonic/gin"
 )
@@ -29,9 +30,10 @@ func (h *ScoreHandler) CreateScore(c *gin.Context) {
 		return
 	}
 
-	score, err := h.scoreService.CreateScore(&req)
+	actor, _ := middleware.GetCurrentAdmin(c)
+	score, err := h.scoreService.CreateScore(actor, &req, domain.ScoreChangeMeta{IP: c.ClientIP()})
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		c.JSON(statusFromError(err, http.StatusInternalServerError), gin.H{"error": err.Error()})
 		return
 	}
 
@@ -47,9 +49,10 @@ func (h *ScoreHandler) GetScore(c *gin.Context) {
 		return
 	}
 
-	score, err := h.scoreService.GetScoreByID(id)
+	actor, _ := middleware.GetCurrentAdmin(c)
+	score, err := h.scoreService.GetScoreByID(actor, id)
 	if err != nil {
-		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		c.JSON(statusFromError(err, http.StatusNotFound), gin.H{"error": err.Error()})
 		return
 	}
 
@@ -72,9 +75,10 @@ func (h *ScoreHandler) GetScores(c *gin.Context) {
 		req.Size = 10
 	}
 
-	scores, total, err := h.scoreService.ListScores(&req)
+	actor, _ := middleware.GetCurrentAdmin(c)
+	scores, total, err := h.scoreService.ListScores(actor, &req)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		c.JSON(statusFromError(err, http.StatusInternalServerError), gin.H{"error": err.Error()})
 		return
 	}
 
@@ -109,9 +113,10 @@ func (h *ScoreHandler) UpdateScore(c *gin.Context) {
 		return
 	}
 
-	score, err := h.scoreService.UpdateScore(id, &req)
+	actor, _ := middleware.GetCurrentAdmin(c)
+	score, err := h.scoreService.UpdateScore(actor, id, &req, domain.ScoreChangeMeta{IP: c.ClientIP(), Reason: req.Reason})
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		c.JSON(statusFromError(err, http.StatusInternalServerError), gin.H{"error": err.Error()})
 		return
 	}
 
@@ -127,11 +132,94 @@ func (h *ScoreHandler) DeleteScore(c *gin.Context) {
 		return
 	}
 
-	err = h.scoreService.DeleteScore(id)
+	// 删除原因通过查询参数传递，记入成绩变更记录
+	actor, _ := middleware.GetCurrentAdmin(c)
+	err = h.scoreService.DeleteScore(actor, id, domain.ScoreChangeMeta{IP: c.ClientIP(), Reason: c.Query("reason")})
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		c.JSON(statusFromError(err, http.StatusInternalServerError), gin.H{"error": err.Error()})
 		return
 	}
 
 	c.JSON(http.StatusOK, gin.H{"message": "Score deleted successfully"})
-}
\ No newline at end of file
+}
+
+// GetStudentReport 获取学生学期成绩报告
+func (h *ScoreHandler) GetStudentReport(c *gin.Context) {
+	studentID, err := strconv.Atoi(c.Param("student_id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid student ID"})
+		return
+	}
+
+	var req domain.StudentScoreReportRequest
+	if err := c.ShouldBindQuery(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	if req.Semester == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "semester is required"})
+		return
+	}
+
+	actor, _ := middleware.GetCurrentAdmin(c)
+	report, err := h.scoreService.GetStudentReport(actor, studentID, &req)
+	if err != nil {
+		if err.Error() == "student not found" {
+			c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(statusFromError(err, http.StatusInternalServerError), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"data": report})
+}
+
+// GetSubjectStatistics 获取科目成绩分段统计
+func (h *ScoreHandler) GetSubjectStatistics(c *gin.Context) {
+	subjectID, err := strconv.Atoi(c.Param("subject_id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid subject ID"})
+		return
+	}
+
+	var req domain.ScoreStatisticsRequest
+	if err := c.ShouldBindQuery(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	stats, err := h.scoreService.GetSubjectStatistics(subjectID, &req)
+	if err != nil {
+		if err.Error() == "subject not found" {
+			c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"data": stats})
+}
+
+// GetClassStatistics 获取班级成绩统计（及格率、优秀率）
+func (h *ScoreHandler) GetClassStatistics(c *gin.Context) {
+	var req domain.ScoreStatisticsRequest
+	if err := c.ShouldBindQuery(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	statistics, err := h.scoreService.GetClassStatistics(&req)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	if statistics == nil {
+		statistics = []*domain.ClassScoreStatistics{}
+	}
+
+	c.JSON(http.StatusOK, gin.H{"data": statistics})
+}
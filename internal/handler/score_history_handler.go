@@ -0,0 +1,130 @@
+package handler
+
+import (
+	"net/http"
+	"strconv"
+
+	"student-management-system/internal/domain"
+	"student-management-system/internal/service"
+	"student-management-system/pkg/validator"
+
+	"github.com/gin-gonic/gin"
+)
+
+// ScoreHistoryHandler 成绩变更记录处理器。变更记录只能查询，没有修改和删除接口
+type ScoreHistoryHandler struct {
+	scoreService service.ScoreService
+	validator    *validator.CustomValidator
+}
+
+// NewScoreHistoryHandler 创建新的成绩变更记录处理器
+func NewScoreHistoryHandler(scoreService service.ScoreService, validator *validator.CustomValidator) *ScoreHistoryHandler {
+	return &ScoreHistoryHandler{
+		scoreService: scoreService,
+		validator:    validator,
+	}
+}
+
+// GetScoreHistory 获取成绩变更记录
+// @Summary 获取成绩变更记录
+// @Description 获取成绩的录入、修改、删除和更正记录，包括原分数、新分数、操作人、原因和客户端IP。成绩删除后仍可查询；学生和家长无权查看，教师只能查看本人任教科目的记录
+// @Tags score-history
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "成绩ID"
+// @Param page query int false "页码" default(1)
+// @Param size query int false "每页数量" default(10)
+// @Success 200 {object} PaginatedResponse{data=[]domain.ScoreHistory}
+// @Failure 403 {object} ErrorResponse "无权查看"
+// @Failure 404 {object} ErrorResponse "成绩不存在或没有变更记录"
+// @Router /api/v1/scores/{id}/history [get]
+func (h *ScoreHistoryHandler) GetScoreHistory(c *gin.Context) {
+	actor, ok := currentActor(c)
+	if !ok {
+		return
+	}
+
+	id, ok := parseScoreID(c)
+	if !ok {
+		return
+	}
+
+	var req domain.ScoreHistoryListRequest
+	if !bindQuery(c, h.validator, &req) {
+		return
+	}
+
+	history, total, err := h.scoreService.GetScoreHistory(actor, id, &req)
+	if err != nil {
+		c.JSON(statusFromError(err, http.StatusInternalServerError), ErrorResponse{
+			Error:   "Failed to get score history",
+			Message: err.Error(),
+		})
+		return
+	}
+
+	h.respond(c, history, total, &req)
+}
+
+// GetStudentScoreHistory 获取学生成绩变更记录
+// @Summary 获取学生成绩变更记录
+// @Description 获取学生全部成绩的变更记录，可按科目和学期筛选。学生和家长无权查看，教师只能查看本人任教科目的记录
+// @Tags score-history
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "学生ID"
+// @Param page query int false "页码" default(1)
+// @Param size query int false "每页数量" default(10)
+// @Param subject_id query int false "科目ID"
+// @Param semester query string false "学期"
+// @Success 200 {object} PaginatedResponse{data=[]domain.ScoreHistory}
+// @Failure 400 {object} ErrorResponse "请求参数错误"
+// @Failure 403 {object} ErrorResponse "无权查看"
+// @Router /api/v1/students/{id}/score-history [get]
+func (h *ScoreHistoryHandler) GetStudentScoreHistory(c *gin.Context) {
+	actor, ok := currentActor(c)
+	if !ok {
+		return
+	}
+
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil || id <= 0 {
+		c.JSON(http.StatusBadRequest, ErrorResponse{
+			Error:   "Invalid ID format",
+			Message: "学生ID格式错误",
+		})
+		return
+	}
+
+	var req domain.ScoreHistoryListRequest
+	if !bindQuery(c, h.validator, &req) {
+		return
+	}
+
+	history, total, err := h.scoreService.GetStudentScoreHistory(actor, id, &req)
+	if err != nil {
+		c.JSON(statusFromError(err, http.StatusInternalServerError), ErrorResponse{
+			Error:   "Failed to get student score history",
+			Message: err.Error(),
+		})
+		return
+	}
+
+	h.respond(c, history, total, &req)
+}
+
+// respond 写入分页的变更记录响应
+func (h *ScoreHistoryHandler) respond(c *gin.Context, history []*domain.ScoreHistory, total int64, req *domain.ScoreHistoryListRequest) {
+	if history == nil {
+		history = []*domain.ScoreHistory{}
+	}
+
+	c.JSON(http.StatusOK, PaginatedResponse{
+		Code:    200,
+		Message: "获取成绩变更记录成功",
+		Data:    history,
+		Total:   int(total),
+		Page:    req.Page,
+		Size:    req.Size,
+	})
+}
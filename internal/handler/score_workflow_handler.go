@@ -0,0 +1,365 @@
+package handler
+
+import (
+	"net/http"
+	"strconv"
+
+	"student-management-system/internal/domain"
+	"student-management-system/internal/service"
+	"student-management-system/pkg/validator"
+
+	"github.com/gin-gonic/gin"
+)
+
+// ScoreWorkflowHandler 成绩发布流程处理器
+type ScoreWorkflowHandler struct {
+	scoreService service.ScoreService
+	validator    *validator.CustomValidator
+}
+
+// NewScoreWorkflowHandler 创建新的成绩发布流程处理器
+func NewScoreWorkflowHandler(scoreService service.ScoreService, validator *validator.CustomValidator) *ScoreWorkflowHandler {
+	return &ScoreWorkflowHandler{
+		scoreService: scoreService,
+		validator:    validator,
+	}
+}
+
+// SubmitScores 提交开课成绩
+// @Summary 提交开课成绩
+// @Description 任课教师将开课中全部草稿成绩提交审核，提交后不能直接修改
+// @Tags score-workflow
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "开课ID"
+// @Param request body domain.ScoreWorkflowRequest false "备注"
+// @Success 200 {object} Response{data=domain.ScoreWorkflowResult}
+// @Failure 403 {object} ErrorResponse "无权提交"
+// @Failure 404 {object} ErrorResponse "开课不存在"
+// @Failure 409 {object} ErrorResponse "没有可提交的成绩或学期已关闭"
+// @Router /api/v1/course-offerings/{id}/scores/submit [post]
+func (h *ScoreWorkflowHandler) SubmitScores(c *gin.Context) {
+	h.transition(c, domain.ScoreActionSubmit, "成绩提交成功")
+}
+
+// ApproveScores 审核通过开课成绩
+// @Summary 审核通过开课成绩
+// @Description 审核通过开课中全部已提交的成绩，提交人不能审核本人提交的成绩
+// @Tags score-workflow
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "开课ID"
+// @Param request body domain.ScoreWorkflowRequest false "审核意见"
+// @Success 200 {object} Response{data=domain.ScoreWorkflowResult}
+// @Failure 403 {object} ErrorResponse "不能审核本人提交的成绩"
+// @Failure 404 {object} ErrorResponse "开课不存在"
+// @Failure 409 {object} ErrorResponse "没有可审核的成绩"
+// @Router /api/v1/course-offerings/{id}/scores/approve [post]
+func (h *ScoreWorkflowHandler) ApproveScores(c *gin.Context) {
+	h.transition(c, domain.ScoreActionApprove, "成绩审核通过")
+}
+
+// RejectScores 退回开课成绩
+// @Summary 退回开课成绩
+// @Description 将开课中已提交或已审核的成绩退回为草稿，须填写退回原因
+// @Tags score-workflow
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "开课ID"
+// @Param request body domain.ScoreWorkflowRequest true "退回原因"
+// @Success 200 {object} Response{data=domain.ScoreWorkflowResult}
+// @Failure 400 {object} ErrorResponse "未填写退回原因"
+// @Failure 404 {object} ErrorResponse "开课不存在"
+// @Failure 409 {object} ErrorResponse "没有可退回的成绩"
+// @Router /api/v1/course-offerings/{id}/scores/reject [post]
+func (h *ScoreWorkflowHandler) RejectScores(c *gin.Context) {
+	h.transition(c, domain.ScoreActionReject, "成绩已退回")
+}
+
+// PublishScores 发布开课成绩
+// @Summary 发布开课成绩
+// @Description 发布开课中全部已审核的成绩，发布后学生和家长可见
+// @Tags score-workflow
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "开课ID"
+// @Param request body domain.ScoreWorkflowRequest false "备注"
+// @Success 200 {object} Response{data=domain.ScoreWorkflowResult}
+// @Failure 404 {object} ErrorResponse "开课不存在"
+// @Failure 409 {object} ErrorResponse "没有可发布的成绩"
+// @Router /api/v1/course-offerings/{id}/scores/publish [post]
+func (h *ScoreWorkflowHandler) PublishScores(c *gin.Context) {
+	h.transition(c, domain.ScoreActionPublish, "成绩发布成功")
+}
+
+// LockScores 锁定开课成绩
+// @Summary 锁定开课成绩
+// @Description 锁定开课中全部已发布的成绩，锁定后只能通过成绩更正申请修改
+// @Tags score-workflow
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "开课ID"
+// @Param request body domain.ScoreWorkflowRequest false "备注"
+// @Success 200 {object} Response{data=domain.ScoreWorkflowResult}
+// @Failure 404 {object} ErrorResponse "开课不存在"
+// @Failure 409 {object} ErrorResponse "没有可锁定的成绩"
+// @Router /api/v1/course-offerings/{id}/scores/lock [post]
+func (h *ScoreWorkflowHandler) LockScores(c *gin.Context) {
+	h.transition(c, domain.ScoreActionLock, "成绩锁定成功")
+}
+
+// GetOfferingScoreStatus 获取开课成绩状态
+// @Summary 获取开课成绩状态
+// @Description 获取开课中各状态的成绩数量及流转记录，学生和家长无权查看
+// @Tags score-workflow
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "开课ID"
+// @Success 200 {object} Response{data=domain.OfferingScoreStatus}
+// @Failure 403 {object} ErrorResponse "无权查看"
+// @Failure 404 {object} ErrorResponse "开课不存在"
+// @Router /api/v1/course-offerings/{id}/scores/status [get]
+func (h *ScoreWorkflowHandler) GetOfferingScoreStatus(c *gin.Context) {
+	actor, ok := currentActor(c)
+	if !ok {
+		return
+	}
+
+	id, ok := parseOfferingID(c)
+	if !ok {
+		return
+	}
+
+	status, err := h.scoreService.GetOfferingScoreStatus(actor, id)
+	if err != nil {
+		c.JSON(statusFromError(err, http.StatusInternalServerError), ErrorResponse{
+			Error:   "Failed to get offering score status",
+			Message: err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, Response{
+		Code:    200,
+		Message: "获取开课成绩状态成功",
+		Data:    status,
+	})
+}
+
+// RequestGradeChange 提交成绩更正申请
+// @Summary 提交成绩更正申请
+// @Description 已发布或已锁定的成绩须提交更正申请，经他人审批后才会修改。每个成绩同时只能有一个待审批的申请
+// @Tags score-workflow
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "成绩ID"
+// @Param request body domain.CreateGradeChangeRequest true "更正后的成绩及原因"
+// @Success 201 {object} Response{data=domain.GradeChangeRequest}
+// @Failure 400 {object} ErrorResponse "请求参数错误"
+// @Failure 403 {object} ErrorResponse "无权修改该科目成绩"
+// @Failure 409 {object} ErrorResponse "成绩未发布或已有待审批的申请"
+// @Router /api/v1/scores/{id}/change-requests [post]
+func (h *ScoreWorkflowHandler) RequestGradeChange(c *gin.Context) {
+	actor, ok := currentActor(c)
+	if !ok {
+		return
+	}
+
+	id, ok := parseScoreID(c)
+	if !ok {
+		return
+	}
+
+	var req domain.CreateGradeChangeRequest
+	if !bindJSON(c, h.validator, &req) {
+		return
+	}
+
+	change, err := h.scoreService.RequestGradeChange(actor, id, &req)
+	if err != nil {
+		c.JSON(statusFromError(err, http.StatusInternalServerError), ErrorResponse{
+			Error:   "Failed to request grade change",
+			Message: err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusCreated, Response{
+		Code:    201,
+		Message: "成绩更正申请已提交",
+		Data:    change,
+	})
+}
+
+// GetGradeChangeRequests 获取成绩更正申请列表
+// @Summary 获取成绩更正申请列表
+// @Description 获取成绩更正申请列表，教师只能查看本人提交的申请
+// @Tags score-workflow
+// @Produce json
+// @Security BearerAuth
+// @Param page query int false "页码" default(1)
+// @Param size query int false "每页数量" default(10)
+// @Param status query string false "申请状态" Enums(pending, approved, rejected)
+// @Param offering_id query int false "开课ID"
+// @Success 200 {object} PaginatedResponse{data=[]domain.GradeChangeRequest}
+// @Failure 400 {object} ErrorResponse "请求参数错误"
+// @Failure 403 {object} ErrorResponse "无权查看"
+// @Router /api/v1/scores/change-requests [get]
+func (h *ScoreWorkflowHandler) GetGradeChangeRequests(c *gin.Context) {
+	actor, ok := currentActor(c)
+	if !ok {
+		return
+	}
+
+	var req domain.GradeChangeListRequest
+	if !bindQuery(c, h.validator, &req) {
+		return
+	}
+
+	requests, total, err := h.scoreService.ListGradeChangeRequests(actor, &req)
+	if err != nil {
+		c.JSON(statusFromError(err, http.StatusInternalServerError), ErrorResponse{
+			Error:   "Failed to get grade change requests",
+			Message: err.Error(),
+		})
+		return
+	}
+
+	if requests == nil {
+		requests = []*domain.GradeChangeRequest{}
+	}
+
+	c.JSON(http.StatusOK, PaginatedResponse{
+		Code:    200,
+		Message: "获取成绩更正申请列表成功",
+		Data:    requests,
+		Total:   int(total),
+		Page:    req.Page,
+		Size:    req.Size,
+	})
+}
+
+// ApproveGradeChange 批准成绩更正申请
+// @Summary 批准成绩更正申请
+// @Description 批准后成绩修改为申请的分数并重新计算总评成绩，申请人不能审批本人的申请。成绩须仍为申请时的分数且为已发布或已锁定状态
+// @Tags score-workflow
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "申请ID"
+// @Param request body domain.ReviewGradeChangeRequest false "审批意见"
+// @Success 200 {object} Response{data=domain.GradeChangeRequest}
+// @Failure 403 {object} ErrorResponse "不能审批本人的申请"
+// @Failure 404 {object} ErrorResponse "申请不存在"
+// @Failure 409 {object} ErrorResponse "申请已审批、学期已关闭或成绩已在申请后被修改"
+// @Router /api/v1/scores/change-requests/{id}/approve [post]
+func (h *ScoreWorkflowHandler) ApproveGradeChange(c *gin.Context) {
+	h.review(c, true, "成绩更正申请已批准")
+}
+
+// RejectGradeChange 驳回成绩更正申请
+// @Summary 驳回成绩更正申请
+// @Description 驳回成绩更正申请，须填写审批意见
+// @Tags score-workflow
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "申请ID"
+// @Param request body domain.ReviewGradeChangeRequest true "审批意见"
+// @Success 200 {object} Response{data=domain.GradeChangeRequest}
+// @Failure 400 {object} ErrorResponse "未填写审批意见"
+// @Failure 404 {object} ErrorResponse "申请不存在"
+// @Failure 409 {object} ErrorResponse "申请已审批"
+// @Router /api/v1/scores/change-requests/{id}/reject [post]
+func (h *ScoreWorkflowHandler) RejectGradeChange(c *gin.Context) {
+	h.review(c, false, "成绩更正申请已驳回")
+}
+
+// transition 执行开课成绩流转操作
+func (h *ScoreWorkflowHandler) transition(c *gin.Context, action, message string) {
+	actor, ok := currentActor(c)
+	if !ok {
+		return
+	}
+
+	id, ok := parseOfferingID(c)
+	if !ok {
+		return
+	}
+
+	var req domain.ScoreWorkflowRequest
+	if !bindOptionalJSON(c, h.validator, &req) {
+		return
+	}
+
+	result, err := h.scoreService.TransitionOfferingScores(actor, id, action, &req)
+	if err != nil {
+		c.JSON(statusFromError(err, http.StatusInternalServerError), ErrorResponse{
+			Error:   "Failed to " + action + " scores",
+			Message: err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, Response{
+		Code:    200,
+		Message: message,
+		Data:    result,
+	})
+}
+
+// review 审批成绩更正申请
+func (h *ScoreWorkflowHandler) review(c *gin.Context, approve bool, message string) {
+	actor, ok := currentActor(c)
+	if !ok {
+		return
+	}
+
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil || id <= 0 {
+		c.JSON(http.StatusBadRequest, ErrorResponse{
+			Error:   "Invalid ID format",
+			Message: "申请ID格式错误",
+		})
+		return
+	}
+
+	var req domain.ReviewGradeChangeRequest
+	if !bindOptionalJSON(c, h.validator, &req) {
+		return
+	}
+
+	change, err := h.scoreService.ReviewGradeChange(actor, id, approve, &req, domain.ScoreChangeMeta{IP: c.ClientIP()})
+	if err != nil {
+		c.JSON(statusFromError(err, http.StatusInternalServerError), ErrorResponse{
+			Error:   "Failed to review grade change request",
+			Message: err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, Response{
+		Code:    200,
+		Message: message,
+		Data:    change,
+	})
+}
+
+// parseScoreID 解析路径中的成绩ID，失败时已写入响应
+func parseScoreID(c *gin.Context) (int, bool) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil || id <= 0 {
+		c.JSON(http.StatusBadRequest, ErrorResponse{
+			Error:   "Invalid ID format",
+			Message: "成绩ID格式错误",
+		})
+		return 0, false
+	}
+	return id, true
+}
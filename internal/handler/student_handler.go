@@ -5,6 +5,7 @@ import (
 	"strconv"
 	"student-management-system/internal/domain"
 	"student-management-system/internal/service"
+	"student-management-system/pkg/middleware"
 	"student-management-system/pkg/validator"
 
 	"github.com/gin-gonic/gin"
@@ -26,7 +27,7 @@ func NewStudentHandler(studentService *service.StudentService, validator *valida
 
 // CreateStudent 创建学生
 // @Summary 创建新学生
-// @Description 创建一个新的学生记录
+// @Description 创建一个新的学生记录。学籍状态默认为在读，补录历届毕业生时可设为毕业并须填写毕业日期，其他状态须通过学籍异动接口办理
 // @Tags students
 // @Accept json
 // @Produce json
@@ -63,7 +64,7 @@ func (h *StudentHandler) CreateStudent(c *gin.Context) {
 
 	student, err := h.studentService.CreateStudent(req)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, ErrorResponse{
+		c.JSON(statusFromError(err, http.StatusInternalServerError), ErrorResponse{
 			Error:   "Create student failed",
 			Message: "创建学生失败: " + err.Error(),
 		})
@@ -99,6 +100,15 @@ func (h *StudentHandler) GetStudent(c *gin.Context) {
 		return
 	}
 
+	// 学生和家长只能查看本人（子女）的信息
+	if actor, ok := middleware.GetCurrentAdmin(c); ok && !actor.CanAccessStudent(id) {
+		c.JSON(http.StatusForbidden, Response{
+			Code:    403,
+			Message: "无权查看该学生信息",
+		})
+		return
+	}
+
 	student, err := h.studentService.GetStudentByID(id)
 	if err != nil {
 		if err.Error() == "student not found" {
@@ -124,11 +134,13 @@ func (h *StudentHandler) GetStudent(c *gin.Context) {
 
 // GetStudents 获取学生列表
 // @Summary 获取学生列表
-// @Description 分页获取学生列表
+// @Description 分页获取学生列表，可按班级、年级筛选
 // @Tags students
 // @Produce json
 // @Param page query int false "页码" default(1)
 // @Param size query int false "每页数量" default(10)
+// @Param class_id query int false "班级ID"
+// @Param grade_year query int false "年级（入学年份）"
 // @Success 200 {object} PaginatedResponse
 // @Failure 400 {object} Response
 // @Failure 500 {object} Response
@@ -148,7 +160,34 @@ func (h *StudentHandler) GetStudents(c *gin.Context) {
 		size = 10
 	}
 
-	students, total, err := h.studentService.GetAllStudents(page, size)
+	// 学生和家长只返回本人（子女）的信息
+	if actor, ok := middleware.GetCurrentAdmin(c); ok && actor.IsStudentScoped() {
+		students, err := h.studentService.GetStudentsByIDs(actor.StudentIDs)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, Response{
+				Code:    500,
+				Message: "获取学生列表失败: " + err.Error(),
+			})
+			return
+		}
+
+		c.JSON(http.StatusOK, PaginatedResponse{
+			Code:    200,
+			Message: "获取成功",
+			Data:    students,
+			Total:   len(students),
+			Page:    1,
+			Size:    len(students),
+		})
+		return
+	}
+
+	var filter domain.StudentFilter
+	if !bindQuery(c, h.validator, &filter) {
+		return
+	}
+
+	students, total, err := h.studentService.GetAllStudents(page, size, filter)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, Response{
 			Code:    500,
@@ -234,8 +273,9 @@ func (h *StudentHandler) UpdateStudent(c *gin.Context) {
 				Message: "学生不存在",
 			})
 		} else {
-			c.JSON(http.StatusInternalServerError, Response{
-				Code:    500,
+			status := statusFromError(err, http.StatusInternalServerError)
+			c.JSON(status, Response{
+				Code:    status,
 				Message: "更新学生信息失败: " + err.Error(),
 			})
 		}
@@ -0,0 +1,144 @@
+package handler
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+
+	"student-management-system/internal/domain"
+	"student-management-system/internal/service"
+	"student-management-system/pkg/spreadsheet"
+
+	"github.com/gin-gonic/gin"
+)
+
+// maxStudentImportFileSize 导入文件大小上限（10MB）
+const maxStudentImportFileSize = 10 << 20
+
+// StudentImportHandler 学生批量导入处理器
+type StudentImportHandler struct {
+	importService *service.StudentImportService
+}
+
+// NewStudentImportHandler 创建新的学生批量导入处理器
+func NewStudentImportHandler(importService *service.StudentImportService) *StudentImportHandler {
+	return &StudentImportHandler{
+		importService: importService,
+	}
+}
+
+// ImportStudents 批量导入学生
+// @Summary 批量导入学生
+// @Description 上传CSV或XLSX文件批量导入学生，逐行校验并返回行级错误。
+// @Description dry_run=true 时只校验不写入；mode=atomic（默认）时存在任意无效行则全部不导入，mode=skip_invalid 时跳过无效行并在同一事务中导入其余行。
+// @Description 表头默认识别字段名或中文名（学号、姓名、年龄、性别、手机号、邮箱、地址、专业、入学日期、毕业日期、状态），可通过 mapping 指定自定义表头。
+// @Description 学籍状态只能为在读（默认）或毕业，毕业学生须填写毕业日期；休学、转出等状态须导入后通过学籍异动办理。
+// @Tags students
+// @Accept multipart/form-data
+// @Produce json
+// @Security BearerAuth
+// @Param file formData file true "CSV或XLSX文件"
+// @Param mapping formData string false "字段到表头的映射JSON，如 {\"student_id\":\"学生编号\"}"
+// @Param dry_run formData bool false "只校验不写入"
+// @Param mode formData string false "导入模式" Enums(atomic, skip_invalid)
+// @Param sheet formData string false "XLSX工作表名称，默认第一个工作表"
+// @Success 200 {object} Response{data=domain.StudentImportResult} "校验或导入完成"
+// @Failure 400 {object} ErrorResponse "文件或参数错误"
+// @Failure 413 {object} ErrorResponse "文件过大"
+// @Failure 422 {object} Response{data=domain.StudentImportResult} "存在无效行，未导入任何数据"
+// @Failure 500 {object} ErrorResponse "服务器内部错误"
+// @Router /api/v1/students/import [post]
+func (h *StudentImportHandler) ImportStudents(c *gin.Context) {
+	c.Request.Body = http.MaxBytesReader(c.Writer, c.Request.Body, maxStudentImportFileSize+1<<20)
+
+	fileHeader, err := c.FormFile("file")
+	if err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{
+			Error:   "Invalid request format",
+			Message: "请上传导入文件: " + err.Error(),
+		})
+		return
+	}
+	if fileHeader.Size > maxStudentImportFileSize {
+		c.JSON(http.StatusRequestEntityTooLarge, ErrorResponse{
+			Error:   "File too large",
+			Message: "导入文件不能超过10MB",
+		})
+		return
+	}
+
+	opts := domain.StudentImportOptions{
+		Sheet: c.PostForm("sheet"),
+		Mode:  c.PostForm("mode"),
+	}
+
+	opts.Format, err = spreadsheet.DetectFormat(fileHeader.Filename)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{
+			Error:   "Unsupported file type",
+			Message: "仅支持CSV和XLSX文件",
+		})
+		return
+	}
+
+	if v := c.PostForm("dry_run"); v != "" {
+		opts.DryRun, err = strconv.ParseBool(v)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, ErrorResponse{
+				Error:   "Invalid request format",
+				Message: "dry_run 参数错误",
+			})
+			return
+		}
+	}
+
+	if v := c.PostForm("mapping"); v != "" {
+		if err := json.Unmarshal([]byte(v), &opts.Mapping); err != nil {
+			c.JSON(http.StatusBadRequest, ErrorResponse{
+				Error:   "Invalid request format",
+				Message: "mapping 必须是字段到表头的JSON对象: " + err.Error(),
+			})
+			return
+		}
+	}
+
+	file, err := fileHeader.Open()
+	if err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{
+			Error:   "Invalid request format",
+			Message: "读取上传文件失败: " + err.Error(),
+		})
+		return
+	}
+	defer file.Close()
+
+	result, err := h.importService.Import(file, opts)
+	if err != nil {
+		c.JSON(statusFromError(err, http.StatusInternalServerError), ErrorResponse{
+			Error:   "Import students failed",
+			Message: err.Error(),
+		})
+		return
+	}
+
+	switch {
+	case result.DryRun:
+		c.JSON(http.StatusOK, Response{
+			Code:    200,
+			Message: "校验完成",
+			Data:    result,
+		})
+	case !result.Committed && result.Invalid > 0 && result.Mode == domain.StudentImportModeAtomic:
+		c.JSON(http.StatusUnprocessableEntity, Response{
+			Code:    422,
+			Message: "存在无效数据，未导入任何学生",
+			Data:    result,
+		})
+	default:
+		c.JSON(http.StatusOK, Response{
+			Code:    200,
+			Message: "导入完成",
+			Data:    result,
+		})
+	}
+}
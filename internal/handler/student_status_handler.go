@@ -0,0 +1,125 @@
+package handler
+
+import (
+	"net/http"
+	"strconv"
+
+	"student-management-system/internal/domain"
+	"student-management-system/internal/service"
+	"student-management-system/pkg/validator"
+
+	"github.com/gin-gonic/gin"
+)
+
+// StudentStatusHandler 学籍异动处理器
+type StudentStatusHandler struct {
+	statusService *service.StudentStatusService
+	validator     *validator.CustomValidator
+}
+
+// NewStudentStatusHandler 创建新的学籍异动处理器
+func NewStudentStatusHandler(statusService *service.StudentStatusService, validator *validator.CustomValidator) *StudentStatusHandler {
+	return &StudentStatusHandler{
+		statusService: statusService,
+		validator:     validator,
+	}
+}
+
+// ChangeStudentStatus 变更学籍状态
+// @Summary 变更学籍状态
+// @Description 办理休学、复学、转出、退学、毕业或开除学籍，须符合状态流转规则：休学只能从在读或复学转入，复学只能从休学转入，转出、退学和开除学籍可从在读、休学或复学转入，毕业只能从在读或复学转入；转出、退学、毕业和开除学籍为终态。须填写原因和生效日期，除毕业外须填写批准文件编号，可附上已上传材料的地址。生效日期不能晚于今天，也不能早于入学日期和上一次异动的生效日期，转为毕业时毕业日期同时设为生效日期。每次变更都写入学籍异动记录
+// @Tags students
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "学生ID"
+// @Param change body domain.ChangeStudentStatusRequest true "学籍异动"
+// @Success 200 {object} Response{data=domain.StudentStatusInfo}
+// @Failure 400 {object} ErrorResponse "请求参数错误、缺少批准文件编号或生效日期无效"
+// @Failure 403 {object} ErrorResponse "无权变更"
+// @Failure 404 {object} ErrorResponse "学生不存在"
+// @Failure 409 {object} ErrorResponse "不允许的状态流转或学籍状态已被修改"
+// @Router /api/v1/students/{id}/status [post]
+func (h *StudentStatusHandler) ChangeStudentStatus(c *gin.Context) {
+	actor, ok := currentActor(c)
+	if !ok {
+		return
+	}
+
+	studentID, ok := parseStatusStudentID(c)
+	if !ok {
+		return
+	}
+
+	var req domain.ChangeStudentStatusRequest
+	if !bindJSON(c, h.validator, &req) {
+		return
+	}
+
+	info, err := h.statusService.ChangeStatus(actor, studentID, req)
+	if err != nil {
+		c.JSON(statusFromError(err, http.StatusInternalServerError), ErrorResponse{
+			Error:   "Failed to change student status",
+			Message: err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, Response{
+		Code:    200,
+		Message: "学籍状态已变更",
+		Data:    info,
+	})
+}
+
+// GetStudentStatus 获取学籍状态及异动记录
+// @Summary 获取学籍状态及异动记录
+// @Description 获取学生当前的学籍状态、是否在校、可以转入的状态以及按生效日期排列的学籍异动记录。学生和家长只能查看本人（子女）的学籍
+// @Tags students
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "学生ID"
+// @Success 200 {object} Response{data=domain.StudentStatusInfo}
+// @Failure 400 {object} ErrorResponse "请求参数错误"
+// @Failure 403 {object} ErrorResponse "无权查看"
+// @Failure 404 {object} ErrorResponse "学生不存在"
+// @Router /api/v1/students/{id}/status [get]
+func (h *StudentStatusHandler) GetStudentStatus(c *gin.Context) {
+	actor, ok := currentActor(c)
+	if !ok {
+		return
+	}
+
+	studentID, ok := parseStatusStudentID(c)
+	if !ok {
+		return
+	}
+
+	info, err := h.statusService.GetStatus(actor, studentID)
+	if err != nil {
+		c.JSON(statusFromError(err, http.StatusInternalServerError), ErrorResponse{
+			Error:   "Failed to get student status",
+			Message: err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, Response{
+		Code:    200,
+		Message: "获取学籍状态成功",
+		Data:    info,
+	})
+}
+
+// parseStatusStudentID 解析路径中的学生ID，失败时已写入响应
+func parseStatusStudentID(c *gin.Context) (int, bool) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil || id <= 0 {
+		c.JSON(http.StatusBadRequest, ErrorResponse{
+			Error:   "Invalid ID format",
+			Message: "学生ID格式错误",
+		})
+		return 0, false
+	}
+	return id, true
+}
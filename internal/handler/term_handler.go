@@ -0,0 +1,443 @@
+package handler
+
+import (
+	"net/http"
+	"strconv"
+
+	"student-management-system/internal/domain"
+	"student-management-system/internal/service"
+	"student-management-system/pkg/validator"
+
+	"github.com/gin-gonic/gin"
+)
+
+// TermHandler 学期处理器
+type TermHandler struct {
+	termService *service.TermService
+	validator   *validator.CustomValidator
+}
+
+// NewTermHandler 创建新的学期处理器
+func NewTermHandler(termService *service.TermService, validator *validator.CustomValidator) *TermHandler {
+	return &TermHandler{
+		termService: termService,
+		validator:   validator,
+	}
+}
+
+// CreateTerm 创建学期
+// @Summary 创建学期
+// @Description 创建学期，学期代码支持 2024-2025-1、2024-2025学年第一学期、2024秋 等写法，统一保存为 2024-2025-1 格式
+// @Tags terms
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param term body domain.CreateTermRequest true "学期信息"
+// @Success 201 {object} Response{data=domain.Term}
+// @Failure 400 {object} ErrorResponse "请求参数错误"
+// @Failure 409 {object} ErrorResponse "学期已存在"
+// @Failure 500 {object} ErrorResponse "服务器内部错误"
+// @Router /api/v1/terms [post]
+func (h *TermHandler) CreateTerm(c *gin.Context) {
+	var req domain.CreateTermRequest
+	if !bindJSON(c, h.validator, &req) {
+		return
+	}
+
+	term, err := h.termService.CreateTerm(req)
+	if err != nil {
+		c.JSON(statusFromError(err, http.StatusInternalServerError), ErrorResponse{
+			Error:   "Failed to create term",
+			Message: err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusCreated, Response{
+		Code:    201,
+		Message: "学期创建成功",
+		Data:    term,
+	})
+}
+
+// GetTerms 获取学期列表
+// @Summary 获取学期列表
+// @Description 分页获取学期列表，按开始日期倒序，可按状态筛选
+// @Tags terms
+// @Produce json
+// @Security BearerAuth
+// @Param page query int false "页码" default(1)
+// @Param size query int false "每页数量" default(10)
+// @Param status query string false "状态" Enums(planning, open, grading, closed)
+// @Success 200 {object} PaginatedResponse
+// @Failure 400 {object} ErrorResponse "请求参数错误"
+// @Failure 500 {object} ErrorResponse "服务器内部错误"
+// @Router /api/v1/terms [get]
+func (h *TermHandler) GetTerms(c *gin.Context) {
+	var req domain.TermListRequest
+	if !bindQuery(c, h.validator, &req) {
+		return
+	}
+
+	terms, total, err := h.termService.ListTerms(req)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, ErrorResponse{
+			Error:   "Failed to get terms",
+			Message: err.Error(),
+		})
+		return
+	}
+
+	if terms == nil {
+		terms = []*domain.Term{}
+	}
+
+	page, size := req.Page, req.Size
+	if page <= 0 {
+		page = 1
+	}
+	if size <= 0 {
+		size = 10
+	}
+
+	c.JSON(http.StatusOK, PaginatedResponse{
+		Code:    200,
+		Message: "获取学期列表成功",
+		Data:    terms,
+		Total:   int(total),
+		Page:    page,
+		Size:    size,
+	})
+}
+
+// GetCurrentTerm 获取当前学期
+// @Summary 获取当前学期
+// @Tags terms
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} Response{data=domain.Term}
+// @Failure 404 {object} ErrorResponse "尚未设置当前学期"
+// @Router /api/v1/terms/current [get]
+func (h *TermHandler) GetCurrentTerm(c *gin.Context) {
+	term, err := h.termService.GetCurrentTerm()
+	if err != nil {
+		c.JSON(statusFromError(err, http.StatusInternalServerError), ErrorResponse{
+			Error:   "Failed to get current term",
+			Message: err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, Response{
+		Code:    200,
+		Message: "获取当前学期成功",
+		Data:    term,
+	})
+}
+
+// GetTerm 获取单个学期
+// @Summary 获取学期详情
+// @Tags terms
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "学期ID"
+// @Success 200 {object} Response{data=domain.Term}
+// @Failure 400 {object} ErrorResponse "请求参数错误"
+// @Failure 404 {object} ErrorResponse "学期不存在"
+// @Router /api/v1/terms/{id} [get]
+func (h *TermHandler) GetTerm(c *gin.Context) {
+	id, ok := parseTermID(c)
+	if !ok {
+		return
+	}
+
+	term, err := h.termService.GetTerm(id)
+	if err != nil {
+		c.JSON(statusFromError(err, http.StatusInternalServerError), ErrorResponse{
+			Error:   "Failed to get term",
+			Message: err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, Response{
+		Code:    200,
+		Message: "获取学期成功",
+		Data:    term,
+	})
+}
+
+// UpdateTerm 更新学期
+// @Summary 更新学期
+// @Description 更新学期名称和起止日期，学期代码不可修改
+// @Tags terms
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "学期ID"
+// @Param term body domain.UpdateTermRequest true "更新的学期信息"
+// @Success 200 {object} Response{data=domain.Term}
+// @Failure 400 {object} ErrorResponse "请求参数错误"
+// @Failure 404 {object} ErrorResponse "学期不存在"
+// @Router /api/v1/terms/{id} [put]
+func (h *TermHandler) UpdateTerm(c *gin.Context) {
+	id, ok := parseTermID(c)
+	if !ok {
+		return
+	}
+
+	var req domain.UpdateTermRequest
+	if !bindJSON(c, h.validator, &req) {
+		return
+	}
+
+	term, err := h.termService.UpdateTerm(id, req)
+	if err != nil {
+		c.JSON(statusFromError(err, http.StatusInternalServerError), ErrorResponse{
+			Error:   "Failed to update term",
+			Message: err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, Response{
+		Code:    200,
+		Message: "学期更新成功",
+		Data:    term,
+	})
+}
+
+// UpdateTermStatus 变更学期状态
+// @Summary 变更学期状态
+// @Description 学期状态按 planning -> open -> grading -> closed 逐级推进。学期结束后该学期成绩冻结，不能再录入、修改或删除
+// @Tags terms
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "学期ID"
+// @Param status body domain.UpdateTermStatusRequest true "目标状态"
+// @Success 200 {object} Response{data=domain.Term}
+// @Failure 400 {object} ErrorResponse "请求参数错误"
+// @Failure 404 {object} ErrorResponse "学期不存在"
+// @Failure 409 {object} ErrorResponse "不允许的状态变更"
+// @Router /api/v1/terms/{id}/status [put]
+func (h *TermHandler) UpdateTermStatus(c *gin.Context) {
+	id, ok := parseTermID(c)
+	if !ok {
+		return
+	}
+
+	var req domain.UpdateTermStatusRequest
+	if !bindJSON(c, h.validator, &req) {
+		return
+	}
+
+	term, err := h.termService.UpdateTermStatus(id, req)
+	if err != nil {
+		c.JSON(statusFromError(err, http.StatusInternalServerError), ErrorResponse{
+			Error:   "Failed to update term status",
+			Message: err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, Response{
+		Code:    200,
+		Message: "学期状态更新成功",
+		Data:    term,
+	})
+}
+
+// SetCurrentTerm 设为当前学期
+// @Summary 设为当前学期
+// @Tags terms
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "学期ID"
+// @Success 200 {object} Response{data=domain.Term}
+// @Failure 404 {object} ErrorResponse "学期不存在"
+// @Failure 409 {object} ErrorResponse "已结束的学期不能设为当前学期"
+// @Router /api/v1/terms/{id}/current [put]
+func (h *TermHandler) SetCurrentTerm(c *gin.Context) {
+	id, ok := parseTermID(c)
+	if !ok {
+		return
+	}
+
+	term, err := h.termService.SetCurrentTerm(id)
+	if err != nil {
+		c.JSON(statusFromError(err, http.StatusInternalServerError), ErrorResponse{
+			Error:   "Failed to set current term",
+			Message: err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, Response{
+		Code:    200,
+		Message: "当前学期设置成功",
+		Data:    term,
+	})
+}
+
+// DeleteTerm 删除学期
+// @Summary 删除学期
+// @Description 删除没有成绩和开课的学期
+// @Tags terms
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "学期ID"
+// @Success 200 {object} Response
+// @Failure 404 {object} ErrorResponse "学期不存在"
+// @Failure 409 {object} ErrorResponse "学期已有成绩或开课"
+// @Router /api/v1/terms/{id} [delete]
+func (h *TermHandler) DeleteTerm(c *gin.Context) {
+	id, ok := parseTermID(c)
+	if !ok {
+		return
+	}
+
+	if err := h.termService.DeleteTerm(id); err != nil {
+		c.JSON(statusFromError(err, http.StatusInternalServerError), ErrorResponse{
+			Error:   "Failed to delete term",
+			Message: err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, Response{
+		Code:    200,
+		Message: "学期删除成功",
+	})
+}
+
+// CreateHoliday 创建节假日
+// @Summary 创建学期节假日
+// @Description 为学期添加节假日，日期范围必须在学期内，结束日期默认与开始日期相同。节假日当天的课程在日历订阅中作为例外日期不再显示
+// @Tags terms
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "学期ID"
+// @Param holiday body domain.CreateTermHolidayRequest true "节假日信息"
+// @Success 201 {object} Response{data=domain.TermHoliday}
+// @Failure 400 {object} ErrorResponse "请求参数错误或日期不在学期内"
+// @Failure 404 {object} ErrorResponse "学期不存在"
+// @Router /api/v1/terms/{id}/holidays [post]
+func (h *TermHandler) CreateHoliday(c *gin.Context) {
+	id, ok := parseTermID(c)
+	if !ok {
+		return
+	}
+
+	var req domain.CreateTermHolidayRequest
+	if !bindJSON(c, h.validator, &req) {
+		return
+	}
+
+	holiday, err := h.termService.CreateHoliday(id, req)
+	if err != nil {
+		c.JSON(statusFromError(err, http.StatusInternalServerError), ErrorResponse{
+			Error:   "Failed to create holiday",
+			Message: err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusCreated, Response{
+		Code:    201,
+		Message: "节假日创建成功",
+		Data:    holiday,
+	})
+}
+
+// GetHolidays 获取学期节假日
+// @Summary 获取学期节假日
+// @Description 获取学期内的全部节假日，按开始日期排序
+// @Tags terms
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "学期ID"
+// @Success 200 {object} Response{data=[]domain.TermHoliday}
+// @Failure 400 {object} ErrorResponse "请求参数错误"
+// @Failure 404 {object} ErrorResponse "学期不存在"
+// @Router /api/v1/terms/{id}/holidays [get]
+func (h *TermHandler) GetHolidays(c *gin.Context) {
+	id, ok := parseTermID(c)
+	if !ok {
+		return
+	}
+
+	holidays, err := h.termService.ListHolidays(id)
+	if err != nil {
+		c.JSON(statusFromError(err, http.StatusInternalServerError), ErrorResponse{
+			Error:   "Failed to get holidays",
+			Message: err.Error(),
+		})
+		return
+	}
+
+	if holidays == nil {
+		holidays = []*domain.TermHoliday{}
+	}
+
+	c.JSON(http.StatusOK, Response{
+		Code:    200,
+		Message: "获取节假日成功",
+		Data:    holidays,
+	})
+}
+
+// DeleteHoliday 删除节假日
+// @Summary 删除学期节假日
+// @Description 删除学期内的节假日
+// @Tags terms
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "学期ID"
+// @Param holiday_id path int true "节假日ID"
+// @Success 200 {object} Response
+// @Failure 400 {object} ErrorResponse "请求参数错误"
+// @Failure 404 {object} ErrorResponse "节假日不存在"
+// @Router /api/v1/terms/{id}/holidays/{holiday_id} [delete]
+func (h *TermHandler) DeleteHoliday(c *gin.Context) {
+	id, ok := parseTermID(c)
+	if !ok {
+		return
+	}
+
+	holidayID, err := strconv.Atoi(c.Param("holiday_id"))
+	if err != nil || holidayID <= 0 {
+		c.JSON(http.StatusBadRequest, ErrorResponse{
+			Error:   "Invalid ID format",
+			Message: "节假日ID格式错误",
+		})
+		return
+	}
+
+	if err := h.termService.DeleteHoliday(id, holidayID); err != nil {
+		c.JSON(statusFromError(err, http.StatusInternalServerError), ErrorResponse{
+			Error:   "Failed to delete holiday",
+			Message: err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, Response{
+		Code:    200,
+		Message: "节假日删除成功",
+	})
+}
+
+// parseTermID 解析路径中的学期ID，失败时已写入响应
+func parseTermID(c *gin.Context) (int, bool) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil || id <= 0 {
+		c.JSON(http.StatusBadRequest, ErrorResponse{
+			Error:   "Invalid ID format",
+			Message: "学期ID格式错误",
+		})
+		return 0, false
+	}
+	return id, true
+}
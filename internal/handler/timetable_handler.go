@@ -0,0 +1,411 @@
+package handler
+
+import (
+	"net/http"
+	"strconv"
+
+	"student-management-system/internal/domain"
+	"student-management-system/internal/service"
+	"student-management-system/pkg/validator"
+
+	"github.com/gin-gonic/gin"
+)
+
+// TimetableHandler 课表处理器
+type TimetableHandler struct {
+	timetableService *service.TimetableService
+	validator        *validator.CustomValidator
+}
+
+// NewTimetableHandler 创建新的课表处理器
+func NewTimetableHandler(timetableService *service.TimetableService, validator *validator.CustomValidator) *TimetableHandler {
+	return &TimetableHandler{
+		timetableService: timetableService,
+		validator:        validator,
+	}
+}
+
+// GetPeriods 获取节次时间
+// @Summary 获取节次时间
+// @Description 获取每天的节次及上下课时间，由配置文件的 timetable.periods 决定
+// @Tags timetable
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} Response{data=[]domain.Period}
+// @Router /api/v1/timetable/periods [get]
+func (h *TimetableHandler) GetPeriods(c *gin.Context) {
+	c.JSON(http.StatusOK, Response{
+		Code:    200,
+		Message: "获取节次时间成功",
+		Data:    h.timetableService.GetPeriods(),
+	})
+}
+
+// CreateSlot 创建课表时段
+// @Summary 创建课表时段
+// @Description 为开课安排每周固定的上课时段。同一学期内教师、教室或行政班在同一时间已有课程时拒绝保存，教室容量不能小于开课容量
+// @Tags timetable
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param slot body domain.CreateTimetableSlotRequest true "课表时段信息"
+// @Success 201 {object} Response{data=domain.TimetableSlot}
+// @Failure 400 {object} ErrorResponse "请求参数错误"
+// @Failure 404 {object} ErrorResponse "开课不存在"
+// @Failure 409 {object} ErrorResponse "时间冲突或学期已结束"
+// @Failure 500 {object} ErrorResponse "服务器内部错误"
+// @Router /api/v1/timetable/slots [post]
+func (h *TimetableHandler) CreateSlot(c *gin.Context) {
+	actor, ok := currentActor(c)
+	if !ok {
+		return
+	}
+
+	var req domain.CreateTimetableSlotRequest
+	if !bindJSON(c, h.validator, &req) {
+		return
+	}
+
+	slot, err := h.timetableService.CreateSlot(actor, req)
+	if err != nil {
+		c.JSON(statusFromError(err, http.StatusInternalServerError), ErrorResponse{
+			Error:   "Failed to create timetable slot",
+			Message: err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusCreated, Response{
+		Code:    201,
+		Message: "课表时段创建成功",
+		Data:    slot,
+	})
+}
+
+// GetSlots 获取课表时段列表
+// @Summary 获取课表时段列表
+// @Description 分页获取课表时段，可按学期、开课、教室、教师、行政班和星期筛选
+// @Tags timetable
+// @Produce json
+// @Security BearerAuth
+// @Param page query int false "页码" default(1)
+// @Param size query int false "每页数量" default(10)
+// @Param semester query string false "学期"
+// @Param offering_id query int false "开课ID"
+// @Param room_id query int false "教室ID"
+// @Param teacher_id query int false "教师ID"
+// @Param class_id query int false "班级ID"
+// @Param day_of_week query int false "星期（1 为周一）"
+// @Success 200 {object} PaginatedResponse
+// @Failure 400 {object} ErrorResponse "请求参数错误"
+// @Failure 403 {object} ErrorResponse "无权查看"
+// @Router /api/v1/timetable/slots [get]
+func (h *TimetableHandler) GetSlots(c *gin.Context) {
+	actor, ok := currentActor(c)
+	if !ok {
+		return
+	}
+
+	var req domain.TimetableSlotListRequest
+	if !bindQuery(c, h.validator, &req) {
+		return
+	}
+
+	slots, total, err := h.timetableService.ListSlots(actor, req)
+	if err != nil {
+		c.JSON(statusFromError(err, http.StatusInternalServerError), ErrorResponse{
+			Error:   "Failed to get timetable slots",
+			Message: err.Error(),
+		})
+		return
+	}
+
+	if slots == nil {
+		slots = []*domain.TimetableSlot{}
+	}
+
+	page, size := req.Page, req.Size
+	if page <= 0 {
+		page = 1
+	}
+	if size <= 0 {
+		size = 10
+	}
+
+	c.JSON(http.StatusOK, PaginatedResponse{
+		Code:    200,
+		Message: "获取课表时段列表成功",
+		Data:    slots,
+		Total:   int(total),
+		Page:    page,
+		Size:    size,
+	})
+}
+
+// GetSlot 获取单个课表时段
+// @Summary 获取课表时段详情
+// @Description 根据ID获取课表时段
+// @Tags timetable
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "课表时段ID"
+// @Success 200 {object} Response{data=domain.TimetableSlot}
+// @Failure 400 {object} ErrorResponse "请求参数错误"
+// @Failure 404 {object} ErrorResponse "课表时段不存在"
+// @Router /api/v1/timetable/slots/{id} [get]
+func (h *TimetableHandler) GetSlot(c *gin.Context) {
+	id, ok := parseSlotID(c)
+	if !ok {
+		return
+	}
+
+	slot, err := h.timetableService.GetSlot(id)
+	if err != nil {
+		c.JSON(statusFromError(err, http.StatusInternalServerError), ErrorResponse{
+			Error:   "Failed to get timetable slot",
+			Message: err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, Response{
+		Code:    200,
+		Message: "获取课表时段成功",
+		Data:    slot,
+	})
+}
+
+// UpdateSlot 更新课表时段
+// @Summary 更新课表时段
+// @Description 调整课表时段的教室、教师、行政班或时间，调整后重新检查时间冲突。只修改开始节次时保持原有节数；teacher_id 或 class_id 传 0 表示不指定
+// @Tags timetable
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "课表时段ID"
+// @Param slot body domain.UpdateTimetableSlotRequest true "更新的课表时段信息"
+// @Success 200 {object} Response{data=domain.TimetableSlot}
+// @Failure 400 {object} ErrorResponse "请求参数错误"
+// @Failure 404 {object} ErrorResponse "课表时段不存在"
+// @Failure 409 {object} ErrorResponse "时间冲突或学期已结束"
+// @Router /api/v1/timetable/slots/{id} [put]
+func (h *TimetableHandler) UpdateSlot(c *gin.Context) {
+	id, ok := parseSlotID(c)
+	if !ok {
+		return
+	}
+
+	var req domain.UpdateTimetableSlotRequest
+	if !bindJSON(c, h.validator, &req) {
+		return
+	}
+
+	slot, err := h.timetableService.UpdateSlot(id, req)
+	if err != nil {
+		c.JSON(statusFromError(err, http.StatusInternalServerError), ErrorResponse{
+			Error:   "Failed to update timetable slot",
+			Message: err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, Response{
+		Code:    200,
+		Message: "课表时段更新成功",
+		Data:    slot,
+	})
+}
+
+// DeleteSlot 删除课表时段
+// @Summary 删除课表时段
+// @Description 删除课表时段，已结束学期的课表不能修改
+// @Tags timetable
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "课表时段ID"
+// @Success 200 {object} Response
+// @Failure 404 {object} ErrorResponse "课表时段不存在"
+// @Failure 409 {object} ErrorResponse "学期已结束"
+// @Router /api/v1/timetable/slots/{id} [delete]
+func (h *TimetableHandler) DeleteSlot(c *gin.Context) {
+	id, ok := parseSlotID(c)
+	if !ok {
+		return
+	}
+
+	if err := h.timetableService.DeleteSlot(id); err != nil {
+		c.JSON(statusFromError(err, http.StatusInternalServerError), ErrorResponse{
+			Error:   "Failed to delete timetable slot",
+			Message: err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, Response{
+		Code:    200,
+		Message: "课表时段删除成功",
+	})
+}
+
+// AutoSchedule 自动排课
+// @Summary 自动排课
+// @Description 在学期已有课表的基础上为指定开课生成教师、教室和行政班均不冲突的周课表草稿。已有时段保持不变；未指定教室时按容量和类型选择最小的可用教室；连上的课不跨越午休等长间隔。save 为 true 时保存排课结果，无法安排的课次在 unscheduled 中列出
+// @Tags timetable
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param request body domain.AutoScheduleRequest true "排课要求"
+// @Success 200 {object} Response{data=domain.AutoScheduleResult}
+// @Failure 400 {object} ErrorResponse "请求参数错误"
+// @Failure 404 {object} ErrorResponse "学期或开课不存在"
+// @Failure 409 {object} ErrorResponse "时间冲突或学期已结束"
+// @Router /api/v1/timetable/auto-schedule [post]
+func (h *TimetableHandler) AutoSchedule(c *gin.Context) {
+	actor, ok := currentActor(c)
+	if !ok {
+		return
+	}
+
+	var req domain.AutoScheduleRequest
+	if !bindJSON(c, h.validator, &req) {
+		return
+	}
+
+	result, err := h.timetableService.AutoSchedule(actor, req)
+	if err != nil {
+		c.JSON(statusFromError(err, http.StatusInternalServerError), ErrorResponse{
+			Error:   "Failed to auto schedule",
+			Message: err.Error(),
+		})
+		return
+	}
+
+	message := "排课草稿生成成功"
+	if result.Saved {
+		message = "自动排课成功"
+	}
+	c.JSON(http.StatusOK, Response{
+		Code:    200,
+		Message: message,
+		Data:    result,
+	})
+}
+
+// GetTeacherTimetable 获取教师课表
+// @Summary 获取教师课表
+// @Description 获取教师某学期的周课表，默认当前学期
+// @Tags timetable
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "教师ID"
+// @Param semester query string false "学期"
+// @Success 200 {object} Response{data=domain.Timetable}
+// @Failure 400 {object} ErrorResponse "请求参数错误"
+// @Failure 403 {object} ErrorResponse "无权查看"
+// @Failure 404 {object} ErrorResponse "教师不存在"
+// @Router /api/v1/teachers/{id}/timetable [get]
+func (h *TimetableHandler) GetTeacherTimetable(c *gin.Context) {
+	h.respondTimetable(c, "教师ID格式错误", h.timetableService.GetTeacherTimetable)
+}
+
+// GetClassTimetable 获取班级课表
+// @Summary 获取班级课表
+// @Description 获取行政班某学期的周课表，默认当前学期
+// @Tags timetable
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "班级ID"
+// @Param semester query string false "学期"
+// @Success 200 {object} Response{data=domain.Timetable}
+// @Failure 400 {object} ErrorResponse "请求参数错误"
+// @Failure 403 {object} ErrorResponse "无权查看"
+// @Failure 404 {object} ErrorResponse "班级不存在"
+// @Router /api/v1/classes/{id}/timetable [get]
+func (h *TimetableHandler) GetClassTimetable(c *gin.Context) {
+	h.respondTimetable(c, "班级ID格式错误", h.timetableService.GetClassTimetable)
+}
+
+// GetStudentTimetable 获取学生课表
+// @Summary 获取学生课表
+// @Description 获取学生某学期的周课表，包括已选上的开课和所在行政班的课程，默认当前学期。学生和家长只能查看本人（子女）的课表
+// @Tags timetable
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "学生ID"
+// @Param semester query string false "学期"
+// @Success 200 {object} Response{data=domain.Timetable}
+// @Failure 400 {object} ErrorResponse "请求参数错误"
+// @Failure 403 {object} ErrorResponse "无权查看"
+// @Failure 404 {object} ErrorResponse "学生不存在"
+// @Router /api/v1/students/{id}/timetable [get]
+func (h *TimetableHandler) GetStudentTimetable(c *gin.Context) {
+	h.respondTimetable(c, "学生ID格式错误", h.timetableService.GetStudentTimetable)
+}
+
+// GetRoomTimetable 获取教室课表
+// @Summary 获取教室课表
+// @Description 获取教室某学期的周课表（占用情况），默认当前学期
+// @Tags timetable
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "教室ID"
+// @Param semester query string false "学期"
+// @Success 200 {object} Response{data=domain.Timetable}
+// @Failure 400 {object} ErrorResponse "请求参数错误"
+// @Failure 403 {object} ErrorResponse "无权查看"
+// @Failure 404 {object} ErrorResponse "教室不存在"
+// @Router /api/v1/rooms/{id}/timetable [get]
+func (h *TimetableHandler) GetRoomTimetable(c *gin.Context) {
+	h.respondTimetable(c, "教室ID格式错误", h.timetableService.GetRoomTimetable)
+}
+
+// respondTimetable 解析路径ID和学期，查询周课表并写入响应
+func (h *TimetableHandler) respondTimetable(c *gin.Context, idMessage string,
+	get func(*domain.JWTClaims, int, domain.TimetableRequest) (*domain.Timetable, error)) {
+	actor, ok := currentActor(c)
+	if !ok {
+		return
+	}
+
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil || id <= 0 {
+		c.JSON(http.StatusBadRequest, ErrorResponse{
+			Error:   "Invalid ID format",
+			Message: idMessage,
+		})
+		return
+	}
+
+	var req domain.TimetableRequest
+	if !bindQuery(c, h.validator, &req) {
+		return
+	}
+
+	timetable, err := get(actor, id, req)
+	if err != nil {
+		c.JSON(statusFromError(err, http.StatusInternalServerError), ErrorResponse{
+			Error:   "Failed to get timetable",
+			Message: err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, Response{
+		Code:    200,
+		Message: "获取课表成功",
+		Data:    timetable,
+	})
+}
+
+// parseSlotID 解析路径中的课表时段ID，失败时已写入响应
+func parseSlotID(c *gin.Context) (int, bool) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil || id <= 0 {
+		c.JSON(http.StatusBadRequest, ErrorResponse{
+			Error:   "Invalid ID format",
+			Message: "课表时段ID格式错误",
+		})
+		return 0, false
+	}
+	return id, true
+}
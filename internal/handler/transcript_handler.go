@@ -0,0 +1,176 @@
+package handler
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"student-management-system/internal/domain"
+	"student-management-system/internal/service"
+	"student-management-system/pkg/middleware"
+	"student-management-system/pkg/validator"
+
+	"github.com/gin-gonic/gin"
+)
+
+// TranscriptHandler 成绩单处理器
+type TranscriptHandler struct {
+	transcriptService *service.TranscriptService
+	validator         *validator.CustomValidator
+}
+
+// NewTranscriptHandler 创建新的成绩单处理器
+func NewTranscriptHandler(transcriptService *service.TranscriptService, validator *validator.CustomValidator) *TranscriptHandler {
+	return &TranscriptHandler{
+		transcriptService: transcriptService,
+		validator:         validator,
+	}
+}
+
+// GetTranscript 生成学生历年成绩单
+// @Summary 生成学生历年成绩单
+// @Description 生成包含学校抬头、各学期课程学分、学期绩点和累计绩点的PDF成绩单，每份成绩单带有可公开核验的验证码。
+// @Description 学生和家长只能生成本人（子女）的成绩单。
+// @Tags students
+// @Produce application/pdf,json
+// @Security BearerAuth
+// @Param id path int true "学生ID"
+// @Param scale query string false "绩点制名称，默认按学生专业设置"
+// @Success 200 {file} file "PDF成绩单"
+// @Failure 400 {object} ErrorResponse "请求参数错误"
+// @Failure 401 {object} ErrorResponse "未授权"
+// @Failure 403 {object} ErrorResponse "无权访问"
+// @Failure 404 {object} ErrorResponse "学生不存在"
+// @Failure 500 {object} ErrorResponse "服务器内部错误"
+// @Router /api/v1/students/{id}/transcript [get]
+func (h *TranscriptHandler) GetTranscript(c *gin.Context) {
+	actor, studentID, ok := h.bindStudent(c)
+	if !ok {
+		return
+	}
+
+	var req domain.TranscriptRequest
+	if !h.bindRequest(c, &req) {
+		return
+	}
+
+	doc, err := h.transcriptService.GenerateTranscript(actor, studentID, req.Scale)
+	h.sendDocument(c, doc, err)
+}
+
+// GetReportCard 生成学生学期成绩报告单
+// @Summary 生成学生学期成绩报告单
+// @Description 生成指定学期的可打印PDF成绩报告单，列出测验、作业、期中和期末成绩以及学期和累计绩点。
+// @Tags students
+// @Produce application/pdf,json
+// @Security BearerAuth
+// @Param id path int true "学生ID"
+// @Param semester query string true "学期"
+// @Param scale query string false "绩点制名称，默认按学生专业设置"
+// @Success 200 {file} file "PDF成绩报告单"
+// @Failure 400 {object} ErrorResponse "请求参数错误"
+// @Failure 401 {object} ErrorResponse "未授权"
+// @Failure 403 {object} ErrorResponse "无权访问"
+// @Failure 404 {object} ErrorResponse "学生不存在"
+// @Failure 500 {object} ErrorResponse "服务器内部错误"
+// @Router /api/v1/students/{id}/report-card [get]
+func (h *TranscriptHandler) GetReportCard(c *gin.Context) {
+	actor, studentID, ok := h.bindStudent(c)
+	if !ok {
+		return
+	}
+
+	var req domain.TranscriptRequest
+	if !h.bindRequest(c, &req) {
+		return
+	}
+
+	doc, err := h.transcriptService.GenerateReportCard(actor, studentID, req.Semester, req.Scale)
+	h.sendDocument(c, doc, err)
+}
+
+// bindRequest 解析并校验成绩单查询参数，失败时已写入响应
+func (h *TranscriptHandler) bindRequest(c *gin.Context, req *domain.TranscriptRequest) bool {
+	if err := c.ShouldBindQuery(req); err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{
+			Error:   "Invalid request format",
+			Message: "请求参数错误: " + err.Error(),
+		})
+		return false
+	}
+
+	if err := h.validator.ValidateStruct(req); err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{
+			Error:   "Validation failed",
+			Message: "数据验证失败: " + err.Error(),
+		})
+		return false
+	}
+	return true
+}
+
+// VerifyTranscript 核验成绩单
+// @Summary 核验成绩单
+// @Description 根据成绩单上印制的验证码核验成绩单真伪，无需登录。返回的数据摘要应与成绩单上的摘要一致。
+// @Tags transcripts
+// @Produce json
+// @Param code path string true "验证码，如 7KQ3-M9TX-2HCP-W4RD"
+// @Success 200 {object} Response{data=domain.TranscriptVerificationResult}
+// @Failure 404 {object} ErrorResponse "验证码不存在"
+// @Failure 500 {object} ErrorResponse "服务器内部错误"
+// @Router /api/v1/transcripts/verify/{code} [get]
+func (h *TranscriptHandler) VerifyTranscript(c *gin.Context) {
+	result, err := h.transcriptService.Verify(c.Param("code"))
+	if err != nil {
+		c.JSON(statusFromError(err, http.StatusInternalServerError), ErrorResponse{
+			Error:   "Verification failed",
+			Message: err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, Response{
+		Code:    200,
+		Message: "成绩单验证通过",
+		Data:    result,
+	})
+}
+
+// bindStudent 获取当前账号并解析学生ID，失败时已写入响应
+func (h *TranscriptHandler) bindStudent(c *gin.Context) (*domain.JWTClaims, int, bool) {
+	actor, ok := middleware.GetCurrentAdmin(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, ErrorResponse{
+			Error:   "Unauthorized",
+			Message: "Invalid or missing token",
+		})
+		return nil, 0, false
+	}
+
+	studentID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{
+			Error:   "Invalid student ID",
+			Message: "学生ID必须是数字",
+		})
+		return nil, 0, false
+	}
+
+	return actor, studentID, true
+}
+
+// sendDocument 返回生成的PDF文件
+func (h *TranscriptHandler) sendDocument(c *gin.Context, doc *service.TranscriptDocument, err error) {
+	if err != nil {
+		c.JSON(statusFromError(err, http.StatusInternalServerError), ErrorResponse{
+			Error:   "Failed to generate transcript",
+			Message: err.Error(),
+		})
+		return
+	}
+
+	c.Header("Content-Disposition", fmt.Sprintf(`attachment; filename="%s"`, doc.FileName))
+	c.Header("Cache-Control", "no-store")
+	c.Header("X-Verification-Code", doc.Transcript.VerificationCode)
+	c.Data(http.StatusOK, "application/pdf", doc.Content)
+}
@@ -0,0 +1,253 @@
+package handler
+
+import (
+	"net/http"
+
+	"student-management-system/internal/domain"
+	"student-management-system/internal/service"
+	"student-management-system/pkg/middleware"
+	"student-management-system/pkg/validator"
+
+	"github.com/gin-gonic/gin"
+)
+
+// TwoFactorHandler 两步验证处理器
+type TwoFactorHandler struct {
+	twoFactorService *service.TwoFactorService
+	validator        *validator.CustomValidator
+}
+
+// NewTwoFactorHandler 创建新的两步验证处理器
+func NewTwoFactorHandler(twoFactorService *service.TwoFactorService, validator *validator.CustomValidator) *TwoFactorHandler {
+	return &TwoFactorHandler{
+		twoFactorService: twoFactorService,
+		validator:        validator,
+	}
+}
+
+// GetStatus 获取两步验证状态
+// @Summary 获取两步验证状态
+// @Description 获取当前账号是否启用两步验证、角色是否强制要求以及剩余恢复码数量
+// @Tags 认证
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} Response{data=domain.TwoFactorStatus}
+// @Failure 401 {object} ErrorResponse "未授权"
+// @Failure 500 {object} ErrorResponse "服务器内部错误"
+// @Router /api/v1/auth/2fa [get]
+func (h *TwoFactorHandler) GetStatus(c *gin.Context) {
+	claims, ok := middleware.GetCurrentAdmin(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, ErrorResponse{
+			Error:   "Unauthorized",
+			Message: "Invalid or missing token",
+		})
+		return
+	}
+
+	status, err := h.twoFactorService.GetStatus(claims)
+	if err != nil {
+		c.JSON(statusFromError(err, http.StatusInternalServerError), ErrorResponse{
+			Error:   "Failed to get two-factor status",
+			Message: err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, Response{
+		Code:    200,
+		Message: "获取成功",
+		Data:    status,
+	})
+}
+
+// Enroll 开始绑定认证器
+// @Summary 开始绑定认证器
+// @Description 生成新的TOTP密钥和otpauth URI，使用认证器App扫码后调用确认接口启用
+// @Tags 认证
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} Response{data=domain.TOTPSetup}
+// @Failure 401 {object} ErrorResponse "未授权"
+// @Failure 409 {object} ErrorResponse "已启用两步验证"
+// @Failure 500 {object} ErrorResponse "服务器内部错误"
+// @Router /api/v1/auth/2fa/enroll [post]
+func (h *TwoFactorHandler) Enroll(c *gin.Context) {
+	claims, ok := middleware.GetCurrentAdmin(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, ErrorResponse{
+			Error:   "Unauthorized",
+			Message: "Invalid or missing token",
+		})
+		return
+	}
+
+	setup, err := h.twoFactorService.Enroll(claims)
+	if err != nil {
+		c.JSON(statusFromError(err, http.StatusInternalServerError), ErrorResponse{
+			Error:   "Failed to enroll two-factor",
+			Message: err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, Response{
+		Code:    200,
+		Message: "请使用认证器App扫码后提交验证码确认",
+		Data:    setup,
+	})
+}
+
+// Confirm 确认绑定认证器
+// @Summary 确认绑定认证器
+// @Description 提交认证器显示的验证码，验证通过后启用两步验证并返回一次性恢复码（只显示一次）
+// @Tags 认证
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param request body domain.TwoFactorCodeRequest true "验证码"
+// @Success 200 {object} Response{data=domain.RecoveryCodesResponse}
+// @Failure 400 {object} ErrorResponse "验证码错误"
+// @Failure 401 {object} ErrorResponse "未授权"
+// @Failure 409 {object} ErrorResponse "已启用两步验证"
+// @Router /api/v1/auth/2fa/confirm [post]
+func (h *TwoFactorHandler) Confirm(c *gin.Context) {
+	claims, req, ok := h.bindCode(c)
+	if !ok {
+		return
+	}
+
+	codes, err := h.twoFactorService.Confirm(claims, req.Code)
+	if err != nil {
+		c.JSON(statusFromError(err, http.StatusInternalServerError), ErrorResponse{
+			Error:   "Failed to confirm two-factor",
+			Message: err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, Response{
+		Code:    200,
+		Message: "两步验证已启用，请妥善保存恢复码",
+		Data:    domain.RecoveryCodesResponse{RecoveryCodes: codes},
+	})
+}
+
+// Disable 关闭两步验证
+// @Summary 关闭两步验证
+// @Description 提供密码和当前验证码关闭两步验证。角色强制要求两步验证时不能关闭
+// @Tags 认证
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param request body domain.DisableTwoFactorRequest true "密码和验证码"
+// @Success 200 {object} Response
+// @Failure 400 {object} ErrorResponse "密码或验证码错误"
+// @Failure 401 {object} ErrorResponse "未授权"
+// @Failure 403 {object} ErrorResponse "角色要求两步验证"
+// @Router /api/v1/auth/2fa/disable [post]
+func (h *TwoFactorHandler) Disable(c *gin.Context) {
+	claims, ok := middleware.GetCurrentAdmin(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, ErrorResponse{
+			Error:   "Unauthorized",
+			Message: "Invalid or missing token",
+		})
+		return
+	}
+
+	var req domain.DisableTwoFactorRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{
+			Error:   "Invalid request format",
+			Message: "请求参数错误: " + err.Error(),
+		})
+		return
+	}
+
+	if err := h.validator.ValidateStruct(&req); err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{
+			Error:   "Validation failed",
+			Message: "数据验证失败: " + err.Error(),
+		})
+		return
+	}
+
+	if err := h.twoFactorService.Disable(claims, &req); err != nil {
+		c.JSON(statusFromError(err, http.StatusInternalServerError), ErrorResponse{
+			Error:   "Failed to disable two-factor",
+			Message: err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, Response{
+		Code:    200,
+		Message: "两步验证已关闭",
+	})
+}
+
+// RegenerateRecoveryCodes 重新生成恢复码
+// @Summary 重新生成恢复码
+// @Description 提交当前验证码重新生成一组恢复码，旧恢复码全部作废
+// @Tags 认证
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param request body domain.TwoFactorCodeRequest true "验证码"
+// @Success 200 {object} Response{data=domain.RecoveryCodesResponse}
+// @Failure 400 {object} ErrorResponse "验证码错误"
+// @Failure 401 {object} ErrorResponse "未授权"
+// @Router /api/v1/auth/2fa/recovery-codes [post]
+func (h *TwoFactorHandler) RegenerateRecoveryCodes(c *gin.Context) {
+	claims, req, ok := h.bindCode(c)
+	if !ok {
+		return
+	}
+
+	codes, err := h.twoFactorService.RegenerateRecoveryCodes(claims, req.Code)
+	if err != nil {
+		c.JSON(statusFromError(err, http.StatusInternalServerError), ErrorResponse{
+			Error:   "Failed to regenerate recovery codes",
+			Message: err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, Response{
+		Code:    200,
+		Message: "恢复码已重新生成，请妥善保存",
+		Data:    domain.RecoveryCodesResponse{RecoveryCodes: codes},
+	})
+}
+
+// bindCode 获取当前账号并解析验证码请求，失败时已写入响应
+func (h *TwoFactorHandler) bindCode(c *gin.Context) (*domain.JWTClaims, *domain.TwoFactorCodeRequest, bool) {
+	claims, ok := middleware.GetCurrentAdmin(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, ErrorResponse{
+			Error:   "Unauthorized",
+			Message: "Invalid or missing token",
+		})
+		return nil, nil, false
+	}
+
+	var req domain.TwoFactorCodeRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{
+			Error:   "Invalid request format",
+			Message: "请求参数错误: " + err.Error(),
+		})
+		return nil, nil, false
+	}
+
+	if err := h.validator.ValidateStruct(&req); err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{
+			Error:   "Validation failed",
+			Message: "数据验证失败: " + err.Error(),
+		})
+		return nil, nil, false
+	}
+
+	return claims, &req, true
+}
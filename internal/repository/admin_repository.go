@@ -7,9 +7,45 @@ import (
 
 	"student-management-system/internal/domain"
 
+	"github.com/lib/pq"
 	"github.com/sirupsen/logrus"
 )
 
+// adminColumns 管理员查询字段
+const adminColumns = `id, account, password, name, phone, email, role, teacher_id, student_id, created_at, updated_at, totp_secret, totp_enabled`
+
+// rowScanner 兼容 *sql.Row 和 *sql.Rows 的扫描接口
+type rowScanner interface {
+	Scan(dest ...interface{}) error
+}
+
+// scanAdmin 扫描管理员记录
+func scanAdmin(row rowScanner) (*domain.Admin, error) {
+	admin := &domain.Admin{}
+	var teacherID, studentID sql.NullInt64
+	var totpSecret sql.NullString
+	err := row.Scan(
+		&admin.ID, &admin.Account, &admin.Password, &admin.Name,
+		&admin.Phone, &admin.Email, &admin.Role, &teacherID, &studentID,
+		&admin.CreatedAt, &admin.UpdatedAt, &totpSecret, &admin.TOTPEnabled,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	if teacherID.Valid {
+		id := int(teacherID.Int64)
+		admin.TeacherID = &id
+	}
+	if studentID.Valid {
+		id := int(studentID.Int64)
+		admin.StudentID = &id
+	}
+	admin.TOTPSecret = totpSecret.String
+
+	return admin, nil
+}
+
 type AdminRepository struct {
 	db     *sql.DB
 	logger *logrus.Logger
@@ -25,20 +61,37 @@ func NewAdminRepository(db *sql.DB, logger *logrus.Logger) *AdminRepository {
 // CreateAdmin 创建管理员
 func (r *AdminRepository) CreateAdmin(admin *domain.Admin) error {
 	query := `
-		INSERT INTO admins (account, password, name, phone, email, created_at, updated_at)
-		VALUES ($1, $2, $3, $4, $5, $6, $7)
+		INSERT INTO admins (account, password, name, phone, email, role, teacher_id, student_id, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)
 		RETURNING id
 	`
 
+	tx, err := r.db.Begin()
+	if err != nil {
+		r.logger.WithError(err).Error("Failed to begin transaction for create admin")
+		return fmt.Errorf("failed to create admin: %v", err)
+	}
+	defer tx.Rollback()
+
 	now := time.Now()
-	err := r.db.QueryRow(query, admin.Account, admin.Password, admin.Name,
-		admin.Phone, admin.Email, now, now).Scan(&admin.ID)
+	err = tx.QueryRow(query, admin.Account, admin.Password, admin.Name,
+		admin.Phone, admin.Email, admin.Role, admin.TeacherID, admin.StudentID, now, now).Scan(&admin.ID)
 
 	if err != nil {
 		r.logger.WithError(err).Error("Failed to create admin")
 		return fmt.Errorf("failed to create admin: %v", err)
 	}
 
+	if err = setParentStudents(tx, admin.ID, admin.StudentIDs); err != nil {
+		r.logger.WithError(err).Error("Failed to link parent students")
+		return fmt.Errorf("failed to create admin: %v", err)
+	}
+
+	if err = tx.Commit(); err != nil {
+		r.logger.WithError(err).Error("Failed to commit create admin")
+		return fmt.Errorf("failed to create admin: %v", err)
+	}
+
 	admin.CreatedAt = now
 	admin.UpdatedAt = now
 
@@ -48,17 +101,9 @@ func (r *AdminRepository) CreateAdmin(admin *domain.Admin) error {
 
 // GetAdminByID 根据ID获取管理员
 func (r *AdminRepository) GetAdminByID(id int) (*domain.Admin, error) {
-	query := `
-		SELECT id, account, password, name, phone, email, created_at, updated_at
-		FROM admins
-		WHERE id = $1
-	`
+	query := `SELECT ` + adminColumns + ` FROM admins WHERE id = $1`
 
-	admin := &domain.Admin{}
-	err := r.db.QueryRow(query, id).Scan(
-		&admin.ID, &admin.Account, &admin.Password, &admin.Name,
-		&admin.Phone, &admin.Email, &admin.CreatedAt, &admin.UpdatedAt,
-	)
+	admin, err := scanAdmin(r.db.QueryRow(query, id))
 
 	if err != nil {
 		if err == sql.ErrNoRows {
@@ -68,22 +113,18 @@ func (r *AdminRepository) GetAdminByID(id int) (*domain.Admin, error) {
 		return nil, fmt.Errorf("failed to get admin: %v", err)
 	}
 
+	if err = r.loadParentStudents(admin); err != nil {
+		return nil, err
+	}
+
 	return admin, nil
 }
 
 // GetAdminByAccount 根据账号获取管理员
 func (r *AdminRepository) GetAdminByAccount(account string) (*domain.Admin, error) {
-	query := `
-		SELECT id, account, password, name, phone, email, created_at, updated_at
-		FROM admins
-		WHERE account = $1
-	`
+	query := `SELECT ` + adminColumns + ` FROM admins WHERE account = $1`
 
-	admin := &domain.Admin{}
-	err := r.db.QueryRow(query, account).Scan(
-		&admin.ID, &admin.Account, &admin.Password, &admin.Name,
-		&admin.Phone, &admin.Email, &admin.CreatedAt, &admin.UpdatedAt,
-	)
+	admin, err := scanAdmin(r.db.QueryRow(query, account))
 
 	if err != nil {
 		if err == sql.ErrNoRows {
@@ -93,6 +134,10 @@ func (r *AdminRepository) GetAdminByAccount(account string) (*domain.Admin, erro
 		return nil, fmt.Errorf("failed to get admin: %v", err)
 	}
 
+	if err = r.loadParentStudents(admin); err != nil {
+		return nil, err
+	}
+
 	return admin, nil
 }
 
@@ -100,13 +145,21 @@ func (r *AdminRepository) GetAdminByAccount(account string) (*domain.Admin, erro
 func (r *AdminRepository) UpdateAdmin(admin *domain.Admin) error {
 	query := `
 		UPDATE admins 
-		SET account = $1, password = $2, name = $3, phone = $4, email = $5, updated_at = $6
-		WHERE id = $7
+		SET account = $1, password = $2, name = $3, phone = $4, email = $5,
+		    role = $6, teacher_id = $7, student_id = $8, updated_at = $9
+		WHERE id = $10
 	`
 
+	tx, err := r.db.Begin()
+	if err != nil {
+		r.logger.WithError(err).Error("Failed to begin transaction for update admin")
+		return fmt.Errorf("failed to update admin: %v", err)
+	}
+	defer tx.Rollback()
+
 	now := time.Now()
-	result, err := r.db.Exec(query, admin.Account, admin.Password, admin.Name,
-		admin.Phone, admin.Email, now, admin.ID)
+	result, err := tx.Exec(query, admin.Account, admin.Password, admin.Name,
+		admin.Phone, admin.Email, admin.Role, admin.TeacherID, admin.StudentID, now, admin.ID)
 
 	if err != nil {
 		r.logger.WithError(err).Error("Failed to update admin")
@@ -122,6 +175,21 @@ func (r *AdminRepository) UpdateAdmin(admin *domain.Admin) error {
 		return fmt.Errorf("admin not found")
 	}
 
+	if _, err = tx.Exec(`DELETE FROM parent_students WHERE admin_id = $1`, admin.ID); err != nil {
+		r.logger.WithError(err).Error("Failed to clear parent students")
+		return fmt.Errorf("failed to update admin: %v", err)
+	}
+
+	if err = setParentStudents(tx, admin.ID, admin.StudentIDs); err != nil {
+		r.logger.WithError(err).Error("Failed to link parent students")
+		return fmt.Errorf("failed to update admin: %v", err)
+	}
+
+	if err = tx.Commit(); err != nil {
+		r.logger.WithError(err).Error("Failed to commit update admin")
+		return fmt.Errorf("failed to update admin: %v", err)
+	}
+
 	admin.UpdatedAt = now
 
 	r.logger.WithField("admin_id", admin.ID).Info("Admin updated successfully")
@@ -164,12 +232,7 @@ func (r *AdminRepository) ListAdmins(page, pageSize int) ([]*domain.Admin, int,
 
 	// 获取分页数据
 	offset := (page - 1) * pageSize
-	query := `
-		SELECT id, account, password, name, phone, email, created_at, updated_at
-		FROM admins
-		ORDER BY created_at DESC
-		LIMIT $1 OFFSET $2
-	`
+	query := `SELECT ` + adminColumns + ` FROM admins ORDER BY created_at DESC LIMIT $1 OFFSET $2`
 
 	rows, err := r.db.Query(query, pageSize, offset)
 	if err != nil {
@@ -180,11 +243,7 @@ func (r *AdminRepository) ListAdmins(page, pageSize int) ([]*domain.Admin, int,
 
 	var admins []*domain.Admin
 	for rows.Next() {
-		admin := &domain.Admin{}
-		err := rows.Scan(
-			&admin.ID, &admin.Account, &admin.Password, &admin.Name,
-			&admin.Phone, &admin.Email, &admin.CreatedAt, &admin.UpdatedAt,
-		)
+		admin, err := scanAdmin(rows)
 		if err != nil {
 			r.logger.WithError(err).Error("Failed to scan admin row")
 			return nil, 0, fmt.Errorf("failed to scan admin: %v", err)
@@ -199,3 +258,268 @@ func (r *AdminRepository) ListAdmins(page, pageSize int) ([]*domain.Admin, int,
 
 	return admins, total, nil
 }
+
+// UpdatePassword 仅更新管理员密码哈希
+func (r *AdminRepository) UpdatePassword(id int, hashedPassword string) error {
+	result, err := r.db.Exec(
+		`UPDATE admins SET password = $1, updated_at = $2 WHERE id = $3`,
+		hashedPassword, time.Now(), id,
+	)
+	if err != nil {
+		r.logger.WithError(err).Error("Failed to update admin password")
+		return fmt.Errorf("failed to update admin password: %v", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get rows affected: %v", err)
+	}
+
+	if rowsAffected == 0 {
+		return fmt.Errorf("admin not found")
+	}
+
+	return nil
+}
+
+// ListAllAdmins 获取全部管理员（包含密码哈希），用于密码算法统计
+func (r *AdminRepository) ListAllAdmins() ([]*domain.Admin, error) {
+	rows, err := r.db.Query(`SELECT ` + adminColumns + ` FROM admins ORDER BY id`)
+	if err != nil {
+		r.logger.WithError(err).Error("Failed to list all admins")
+		return nil, fmt.Errorf("failed to list admins: %v", err)
+	}
+	defer rows.Close()
+
+	var admins []*domain.Admin
+	for rows.Next() {
+		admin, err := scanAdmin(rows)
+		if err != nil {
+			r.logger.WithError(err).Error("Failed to scan admin row")
+			return nil, fmt.Errorf("failed to scan admin: %v", err)
+		}
+		admins = append(admins, admin)
+	}
+
+	if err = rows.Err(); err != nil {
+		r.logger.WithError(err).Error("Error iterating admin rows")
+		return nil, fmt.Errorf("error iterating rows: %v", err)
+	}
+
+	return admins, nil
+}
+
+// SetTOTPSecret 保存待确认的两步验证密钥，已启用两步验证的账号不能覆盖
+func (r *AdminRepository) SetTOTPSecret(id int, secret string) error {
+	result, err := r.db.Exec(
+		`UPDATE admins SET totp_secret = $1 WHERE id = $2 AND totp_enabled = FALSE`,
+		secret, id,
+	)
+	if err != nil {
+		r.logger.WithError(err).Error("Failed to set totp secret")
+		return fmt.Errorf("failed to set totp secret: %v", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get rows affected: %v", err)
+	}
+
+	if rowsAffected == 0 {
+		return fmt.Errorf("admin not found or two-factor already enabled")
+	}
+
+	return nil
+}
+
+// EnableTOTP 启用两步验证并写入恢复码哈希
+func (r *AdminRepository) EnableTOTP(id int, codeHashes []string) error {
+	tx, err := r.db.Begin()
+	if err != nil {
+		r.logger.WithError(err).Error("Failed to begin transaction for enable totp")
+		return fmt.Errorf("failed to enable totp: %v", err)
+	}
+	defer tx.Rollback()
+
+	result, err := tx.Exec(
+		`UPDATE admins SET totp_enabled = TRUE, totp_enabled_at = $1 WHERE id = $2 AND totp_secret IS NOT NULL`,
+		time.Now(), id,
+	)
+	if err != nil {
+		r.logger.WithError(err).Error("Failed to enable totp")
+		return fmt.Errorf("failed to enable totp: %v", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get rows affected: %v", err)
+	}
+
+	if rowsAffected == 0 {
+		return fmt.Errorf("admin not found")
+	}
+
+	if err = replaceRecoveryCodes(tx, id, codeHashes); err != nil {
+		r.logger.WithError(err).Error("Failed to save recovery codes")
+		return fmt.Errorf("failed to enable totp: %v", err)
+	}
+
+	if err = tx.Commit(); err != nil {
+		r.logger.WithError(err).Error("Failed to commit enable totp")
+		return fmt.Errorf("failed to enable totp: %v", err)
+	}
+
+	r.logger.WithField("admin_id", id).Info("Two-factor authentication enabled")
+	return nil
+}
+
+// DisableTOTP 关闭两步验证并删除密钥和恢复码
+func (r *AdminRepository) DisableTOTP(id int) error {
+	tx, err := r.db.Begin()
+	if err != nil {
+		r.logger.WithError(err).Error("Failed to begin transaction for disable totp")
+		return fmt.Errorf("failed to disable totp: %v", err)
+	}
+	defer tx.Rollback()
+
+	result, err := tx.Exec(
+		`UPDATE admins SET totp_secret = NULL, totp_enabled = FALSE, totp_enabled_at = NULL WHERE id = $1`,
+		id,
+	)
+	if err != nil {
+		r.logger.WithError(err).Error("Failed to disable totp")
+		return fmt.Errorf("failed to disable totp: %v", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get rows affected: %v", err)
+	}
+
+	if rowsAffected == 0 {
+		return fmt.Errorf("admin not found")
+	}
+
+	if _, err = tx.Exec(`DELETE FROM admin_recovery_codes WHERE admin_id = $1`, id); err != nil {
+		r.logger.WithError(err).Error("Failed to delete recovery codes")
+		return fmt.Errorf("failed to disable totp: %v", err)
+	}
+
+	if err = tx.Commit(); err != nil {
+		r.logger.WithError(err).Error("Failed to commit disable totp")
+		return fmt.Errorf("failed to disable totp: %v", err)
+	}
+
+	r.logger.WithField("admin_id", id).Info("Two-factor authentication disabled")
+	return nil
+}
+
+// ReplaceRecoveryCodes 用新的恢复码替换全部旧恢复码
+func (r *AdminRepository) ReplaceRecoveryCodes(id int, codeHashes []string) error {
+	tx, err := r.db.Begin()
+	if err != nil {
+		r.logger.WithError(err).Error("Failed to begin transaction for recovery codes")
+		return fmt.Errorf("failed to replace recovery codes: %v", err)
+	}
+	defer tx.Rollback()
+
+	if err = replaceRecoveryCodes(tx, id, codeHashes); err != nil {
+		r.logger.WithError(err).Error("Failed to replace recovery codes")
+		return fmt.Errorf("failed to replace recovery codes: %v", err)
+	}
+
+	if err = tx.Commit(); err != nil {
+		r.logger.WithError(err).Error("Failed to commit recovery codes")
+		return fmt.Errorf("failed to replace recovery codes: %v", err)
+	}
+
+	return nil
+}
+
+// UseRecoveryCode 使用一个恢复码，返回恢复码是否有效且未被使用
+func (r *AdminRepository) UseRecoveryCode(id int, codeHash string) (bool, error) {
+	result, err := r.db.Exec(
+		`UPDATE admin_recovery_codes SET used_at = $1 WHERE admin_id = $2 AND code_hash = $3 AND used_at IS NULL`,
+		time.Now(), id, codeHash,
+	)
+	if err != nil {
+		r.logger.WithError(err).Error("Failed to use recovery code")
+		return false, fmt.Errorf("failed to use recovery code: %v", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return false, fmt.Errorf("failed to get rows affected: %v", err)
+	}
+
+	return rowsAffected > 0, nil
+}
+
+// CountRecoveryCodes 统计未使用的恢复码数量
+func (r *AdminRepository) CountRecoveryCodes(id int) (int, error) {
+	var count int
+	err := r.db.QueryRow(
+		`SELECT COUNT(*) FROM admin_recovery_codes WHERE admin_id = $1 AND used_at IS NULL`,
+		id,
+	).Scan(&count)
+	if err != nil {
+		r.logger.WithError(err).Error("Failed to count recovery codes")
+		return 0, fmt.Errorf("failed to count recovery codes: %v", err)
+	}
+	return count, nil
+}
+
+// loadParentStudents 加载家长账号关联的子女
+func (r *AdminRepository) loadParentStudents(admin *domain.Admin) error {
+	if admin.Role != domain.RoleParent {
+		return nil
+	}
+
+	var studentIDs []int64
+	err := r.db.QueryRow(
+		`SELECT COALESCE(array_agg(student_id ORDER BY student_id), '{}') FROM parent_students WHERE admin_id = $1`,
+		admin.ID,
+	).Scan(pq.Array(&studentIDs))
+	if err != nil {
+		r.logger.WithError(err).Error("Failed to load parent students")
+		return fmt.Errorf("failed to load parent students: %v", err)
+	}
+
+	admin.StudentIDs = make([]int, len(studentIDs))
+	for i, id := range studentIDs {
+		admin.StudentIDs[i] = int(id)
+	}
+
+	return nil
+}
+
+// setParentStudents 写入家长与子女的关联
+func setParentStudents(tx *sql.Tx, adminID int, studentIDs []int) error {
+	for _, studentID := range studentIDs {
+		_, err := tx.Exec(
+			`INSERT INTO parent_students (admin_id, student_id) VALUES ($1, $2) ON CONFLICT DO NOTHING`,
+			adminID, studentID,
+		)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// replaceRecoveryCodes 删除旧恢复码并写入新的恢复码哈希
+func replaceRecoveryCodes(tx *sql.Tx, adminID int, codeHashes []string) error {
+	if _, err := tx.Exec(`DELETE FROM admin_recovery_codes WHERE admin_id = $1`, adminID); err != nil {
+		return err
+	}
+	for _, hash := range codeHashes {
+		_, err := tx.Exec(
+			`INSERT INTO admin_recovery_codes (admin_id, code_hash) VALUES ($1, $2)`,
+			adminID, hash,
+		)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,346 @@
+package repository
+
+import (
+	"database/sql"
+	"fmt"
+	"strings"
+	"time"
+
+	"student-management-system/internal/domain"
+	"student-management-system/pkg/logger"
+
+	"github.com/lib/pq"
+)
+
+// AttendanceRepository 考勤仓储接口
+type AttendanceRepository interface {
+	SaveSession(session *domain.AttendanceSession, records []*domain.AttendanceRecord) error
+	GetSession(id int) (*domain.AttendanceSession, error)
+	ListSessions(req *domain.AttendanceSessionListRequest) ([]*domain.AttendanceSession, int64, error)
+	ListRecords(sessionID int) ([]*domain.AttendanceRecord, error)
+	DeleteSession(id int) error
+	ListRoster(offeringID int, classID *int, date time.Time) ([]int, error)
+	Summaries(req *domain.AttendanceSummaryRequest) ([]*domain.AttendanceSummary, error)
+}
+
+// attendanceRepository 考勤仓储实现
+type attendanceRepository struct {
+	db *sql.DB
+}
+
+// NewAttendanceRepository 创建考勤仓储实例
+func NewAttendanceRepository(db *sql.DB) AttendanceRepository {
+	return &attendanceRepository{db: db}
+}
+
+// attendanceCountColumns 按出勤状态统计课次数的字段
+const attendanceCountColumns = `COUNT(ar.id),
+		       COUNT(ar.id) FILTER (WHERE ar.status = 'present'), COUNT(ar.id) FILTER (WHERE ar.status = 'late'),
+		       COUNT(ar.id) FILTER (WHERE ar.status = 'absent'), COUNT(ar.id) FILTER (WHERE ar.status = 'excused'),
+		       COUNT(ar.id) FILTER (WHERE ar.status = 'sick')`
+
+// attendanceSessionSelect 考勤课次查询的字段和关联，需配合 attendanceSessionGroup 使用
+const attendanceSessionSelect = `
+		SELECT a.id, a.offering_id, a.slot_id, a.class_id, a.session_date, a.start_period, a.end_period, a.recorded_by,
+		       a.created_at, a.updated_at, o.semester, o.subject_id, COALESCE(sub.name, ''), o.section, COALESCE(c.name, ''),
+		       ` + attendanceCountColumns + `
+		FROM attendance_sessions a
+		JOIN course_offerings o ON a.offering_id = o.id
+		LEFT JOIN subjects sub ON o.subject_id = sub.id
+		LEFT JOIN classes c ON a.class_id = c.id
+		LEFT JOIN attendance_records ar ON ar.session_id = a.id`
+
+// attendanceSessionGroup 考勤课次查询的分组和排序
+const attendanceSessionGroup = ` GROUP BY a.id, o.id, sub.id, c.id ORDER BY a.session_date DESC, a.start_period, a.id`
+
+// SaveSession 保存一次课的考勤。同一开课同一天同一开始节次的课次已存在时覆盖，名单外的旧记录一并删除
+func (r *attendanceRepository) SaveSession(session *domain.AttendanceSession, records []*domain.AttendanceRecord) error {
+	logger.WithFields(map[string]interface{}{
+		"offering_id":  session.OfferingID,
+		"session_date": session.SessionDate.Format("2006-01-02"),
+		"start_period": session.StartPeriod,
+		"records":      len(records),
+	}).Info("Saving attendance session")
+
+	tx, err := r.db.Begin()
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	err = tx.QueryRow(`
+		INSERT INTO attendance_sessions (offering_id, slot_id, class_id, session_date, start_period, end_period, recorded_by)
+		VALUES ($1, $2, $3, $4, $5, $6, $7)
+		ON CONFLICT (offering_id, session_date, start_period) DO UPDATE
+		SET slot_id = EXCLUDED.slot_id, class_id = EXCLUDED.class_id, end_period = EXCLUDED.end_period,
+		    recorded_by = EXCLUDED.recorded_by
+		RETURNING id, created_at, updated_at
+	`, session.OfferingID, session.SlotID, session.ClassID, session.SessionDate, session.StartPeriod, session.EndPeriod,
+		session.RecordedBy).Scan(&session.ID, &session.CreatedAt, &session.UpdatedAt)
+	if err != nil {
+		logger.WithError(err).Error("Failed to save attendance session")
+		return fmt.Errorf("failed to save attendance session: %w", err)
+	}
+
+	studentIDs := make([]int64, 0, len(records))
+	for _, record := range records {
+		record.SessionID = session.ID
+		err = tx.QueryRow(`
+			INSERT INTO attendance_records (session_id, student_id, status, note)
+			VALUES ($1, $2, $3, NULLIF($4, ''))
+			ON CONFLICT (session_id, student_id) DO UPDATE
+			SET status = EXCLUDED.status, note = EXCLUDED.note
+			RETURNING id, created_at, updated_at
+		`, session.ID, record.StudentID, record.Status, record.Note).Scan(&record.ID, &record.CreatedAt, &record.UpdatedAt)
+		if err != nil {
+			logger.WithError(err).WithFields(map[string]interface{}{
+				"session_id": session.ID,
+				"student_id": record.StudentID,
+			}).Error("Failed to save attendance record")
+			return fmt.Errorf("failed to save attendance record: %w", err)
+		}
+		studentIDs = append(studentIDs, int64(record.StudentID))
+	}
+
+	if _, err := tx.Exec(`DELETE FROM attendance_records WHERE session_id = $1 AND NOT (student_id = ANY($2))`,
+		session.ID, pq.Array(studentIDs)); err != nil {
+		return fmt.Errorf("failed to delete stale attendance records: %w", err)
+	}
+
+	return tx.Commit()
+}
+
+// GetSession 根据ID获取考勤课次（含各状态人数），不存在时返回 nil
+func (r *attendanceRepository) GetSession(id int) (*domain.AttendanceSession, error) {
+	session, err := scanAttendanceSession(r.db.QueryRow(attendanceSessionSelect+` WHERE a.id = $1`+attendanceSessionGroup, id))
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get attendance session: %w", err)
+	}
+	return session, nil
+}
+
+// ListSessions 获取考勤课次列表（分页），按日期倒序
+func (r *attendanceRepository) ListSessions(req *domain.AttendanceSessionListRequest) ([]*domain.AttendanceSession, int64, error) {
+	var conditions []string
+	var args []interface{}
+	argIndex := 1
+
+	if req.Semester != "" {
+		conditions = append(conditions, fmt.Sprintf("o.semester = $%d", argIndex))
+		args = append(args, req.Semester)
+		argIndex++
+	}
+	if req.OfferingID > 0 {
+		conditions = append(conditions, fmt.Sprintf("a.offering_id = $%d", argIndex))
+		args = append(args, req.OfferingID)
+		argIndex++
+	}
+	if req.ClassID > 0 {
+		conditions = append(conditions, fmt.Sprintf("a.class_id = $%d", argIndex))
+		args = append(args, req.ClassID)
+		argIndex++
+	}
+	if req.DateFrom != nil {
+		conditions = append(conditions, fmt.Sprintf("a.session_date >= $%d", argIndex))
+		args = append(args, *req.DateFrom)
+		argIndex++
+	}
+	if req.DateTo != nil {
+		conditions = append(conditions, fmt.Sprintf("a.session_date <= $%d", argIndex))
+		args = append(args, *req.DateTo)
+		argIndex++
+	}
+
+	whereClause := ""
+	if len(conditions) > 0 {
+		whereClause = " WHERE " + strings.Join(conditions, " AND ")
+	}
+
+	var total int64
+	countQuery := `SELECT COUNT(*) FROM attendance_sessions a JOIN course_offerings o ON a.offering_id = o.id` + whereClause
+	if err := r.db.QueryRow(countQuery, args...).Scan(&total); err != nil {
+		return nil, 0, fmt.Errorf("failed to count attendance sessions: %w", err)
+	}
+
+	query := fmt.Sprintf(`%s%s%s LIMIT $%d OFFSET $%d`, attendanceSessionSelect, whereClause, attendanceSessionGroup, argIndex, argIndex+1)
+	args = append(args, req.Size, (req.Page-1)*req.Size)
+
+	rows, err := r.db.Query(query, args...)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to query attendance sessions: %w", err)
+	}
+	defer rows.Close()
+
+	var sessions []*domain.AttendanceSession
+	for rows.Next() {
+		session, err := scanAttendanceSession(rows)
+		if err != nil {
+			return nil, 0, fmt.Errorf("failed to scan attendance session: %w", err)
+		}
+		sessions = append(sessions, session)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, 0, fmt.Errorf("failed to iterate attendance sessions: %w", err)
+	}
+	return sessions, total, nil
+}
+
+// ListRecords 获取一次课的考勤记录，按学号排序
+func (r *attendanceRepository) ListRecords(sessionID int) ([]*domain.AttendanceRecord, error) {
+	rows, err := r.db.Query(`
+		SELECT ar.id, ar.session_id, ar.student_id, ar.status, COALESCE(ar.note, ''), ar.created_at, ar.updated_at,
+		       s.student_id, s.name
+		FROM attendance_records ar
+		JOIN students s ON ar.student_id = s.id
+		WHERE ar.session_id = $1
+		ORDER BY s.student_id
+	`, sessionID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query attendance records: %w", err)
+	}
+	defer rows.Close()
+
+	var records []*domain.AttendanceRecord
+	for rows.Next() {
+		record := &domain.AttendanceRecord{}
+		if err := rows.Scan(&record.ID, &record.SessionID, &record.StudentID, &record.Status, &record.Note,
+			&record.CreatedAt, &record.UpdatedAt, &record.StudentNo, &record.StudentName); err != nil {
+			return nil, fmt.Errorf("failed to scan attendance record: %w", err)
+		}
+		records = append(records, record)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to iterate attendance records: %w", err)
+	}
+	return records, nil
+}
+
+// DeleteSession 删除考勤课次及其考勤记录
+func (r *attendanceRepository) DeleteSession(id int) error {
+	logger.WithFields(map[string]interface{}{
+		"session_id": id,
+	}).Info("Deleting attendance session")
+
+	if _, err := r.db.Exec(`DELETE FROM attendance_sessions WHERE id = $1`, id); err != nil {
+		logger.WithError(err).Error("Failed to delete attendance session")
+		return fmt.Errorf("failed to delete attendance session: %w", err)
+	}
+	return nil
+}
+
+// ListRoster 获取一次课的考勤名单：已选上该开课的学生，以及上课当天在该行政班的学生
+func (r *attendanceRepository) ListRoster(offeringID int, classID *int, date time.Time) ([]int, error) {
+	rows, err := r.db.Query(`
+		SELECT student_id FROM enrollments WHERE offering_id = $1 AND status = $4
+		UNION
+		SELECT student_id FROM class_memberships
+		WHERE class_id = $2 AND joined_at <= $3 AND (left_at IS NULL OR left_at > $3)
+		ORDER BY student_id
+	`, offeringID, classID, date, domain.EnrollmentStatusEnrolled)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query attendance roster: %w", err)
+	}
+	defer rows.Close()
+
+	var studentIDs []int
+	for rows.Next() {
+		var id int
+		if err := rows.Scan(&id); err != nil {
+			return nil, fmt.Errorf("failed to scan attendance roster: %w", err)
+		}
+		studentIDs = append(studentIDs, id)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to iterate attendance roster: %w", err)
+	}
+	return studentIDs, nil
+}
+
+// Summaries 按学生和开课汇总学期内各出勤状态的课次数，只包含已有考勤记录的学生
+func (r *attendanceRepository) Summaries(req *domain.AttendanceSummaryRequest) ([]*domain.AttendanceSummary, error) {
+	conditions := []string{"o.semester = $1"}
+	args := []interface{}{req.Semester}
+	argIndex := 2
+
+	if req.OfferingID > 0 {
+		conditions = append(conditions, fmt.Sprintf("o.id = $%d", argIndex))
+		args = append(args, req.OfferingID)
+		argIndex++
+	}
+	if req.ClassID > 0 {
+		conditions = append(conditions, fmt.Sprintf("s.class_id = $%d", argIndex))
+		args = append(args, req.ClassID)
+		argIndex++
+	}
+	if req.StudentID > 0 {
+		conditions = append(conditions, fmt.Sprintf("ar.student_id = $%d", argIndex))
+		args = append(args, req.StudentID)
+	}
+
+	query := `
+		SELECT ar.student_id, s.student_id, s.name, o.id, o.semester, o.subject_id, COALESCE(sub.name, ''), o.section,
+		       ` + attendanceCountColumns + `
+		FROM attendance_records ar
+		JOIN attendance_sessions a ON ar.session_id = a.id
+		JOIN course_offerings o ON a.offering_id = o.id
+		JOIN students s ON ar.student_id = s.id
+		LEFT JOIN subjects sub ON o.subject_id = sub.id
+		WHERE ` + strings.Join(conditions, " AND ") + `
+		GROUP BY ar.student_id, s.student_id, s.name, o.id, sub.name
+		ORDER BY s.student_id, sub.name, o.id`
+
+	rows, err := r.db.Query(query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query attendance summaries: %w", err)
+	}
+	defer rows.Close()
+
+	var summaries []*domain.AttendanceSummary
+	for rows.Next() {
+		summary := &domain.AttendanceSummary{}
+		c := &summary.AttendanceCounts
+		if err := rows.Scan(&summary.StudentID, &summary.StudentNo, &summary.StudentName, &summary.OfferingID,
+			&summary.Semester, &summary.SubjectID, &summary.SubjectName, &summary.Section,
+			&c.Total, &c.Present, &c.Late, &c.Absent, &c.Excused, &c.Sick); err != nil {
+			return nil, fmt.Errorf("failed to scan attendance summary: %w", err)
+		}
+		summaries = append(summaries, summary)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to iterate attendance summaries: %w", err)
+	}
+	return summaries, nil
+}
+
+// scanAttendanceSession 扫描考勤课次查询的一行
+func scanAttendanceSession(row rowScanner) (*domain.AttendanceSession, error) {
+	session := &domain.AttendanceSession{}
+	var slotID, classID, recordedBy sql.NullInt64
+	c := &session.Counts
+	if err := row.Scan(&session.ID, &session.OfferingID, &slotID, &classID, &session.SessionDate, &session.StartPeriod,
+		&session.EndPeriod, &recordedBy, &session.CreatedAt, &session.UpdatedAt, &session.Semester, &session.SubjectID,
+		&session.SubjectName, &session.Section, &session.ClassName,
+		&c.Total, &c.Present, &c.Late, &c.Absent, &c.Excused, &c.Sick); err != nil {
+		return nil, err
+	}
+	if slotID.Valid {
+		id := int(slotID.Int64)
+		session.SlotID = &id
+	}
+	if classID.Valid {
+		id := int(classID.Int64)
+		session.ClassID = &id
+	}
+	if recordedBy.Valid {
+		id := int(recordedBy.Int64)
+		session.RecordedBy = &id
+	}
+	return session, nil
+}
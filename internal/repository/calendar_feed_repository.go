@@ -0,0 +1,143 @@
+package repository
+
+import (
+	"database/sql"
+	"fmt"
+
+	"student-management-system/internal/domain"
+	"student-management-system/pkg/logger"
+)
+
+// CalendarFeedRepository 日历订阅仓储接口
+type CalendarFeedRepository interface {
+	Create(feed *domain.CalendarFeed) error
+	GetByID(id int) (*domain.CalendarFeed, error)
+	GetActiveByTokenHash(tokenHash string) (*domain.CalendarFeed, error)
+	ListByCreator(adminID int) ([]*domain.CalendarFeed, error)
+	Revoke(id int) error
+	TouchLastUsed(id int) error
+}
+
+// calendarFeedRepository 日历订阅仓储实现
+type calendarFeedRepository struct {
+	db *sql.DB
+}
+
+// NewCalendarFeedRepository 创建日历订阅仓储实例
+func NewCalendarFeedRepository(db *sql.DB) CalendarFeedRepository {
+	return &calendarFeedRepository{db: db}
+}
+
+// calendarFeedSelect 日历订阅查询的字段
+const calendarFeedSelect = `
+		SELECT id, token_hash, owner_type, owner_id, COALESCE(name, ''), created_by, last_used_at, revoked_at, created_at
+		FROM calendar_feeds`
+
+// Create 创建日历订阅
+func (r *calendarFeedRepository) Create(feed *domain.CalendarFeed) error {
+	logger.WithFields(map[string]interface{}{
+		"owner_type": feed.OwnerType,
+		"owner_id":   feed.OwnerID,
+		"created_by": feed.CreatedBy,
+	}).Info("Creating calendar feed")
+
+	query := `
+		INSERT INTO calendar_feeds (token_hash, owner_type, owner_id, name, created_by)
+		VALUES ($1, $2, $3, NULLIF($4, ''), $5)
+		RETURNING id, created_at
+	`
+
+	err := r.db.QueryRow(query, feed.TokenHash, feed.OwnerType, feed.OwnerID, feed.Name, feed.CreatedBy).
+		Scan(&feed.ID, &feed.CreatedAt)
+	if err != nil {
+		logger.WithError(err).Error("Failed to create calendar feed")
+		return fmt.Errorf("failed to create calendar feed: %w", err)
+	}
+
+	return nil
+}
+
+// GetByID 根据ID获取日历订阅，不存在时返回 nil
+func (r *calendarFeedRepository) GetByID(id int) (*domain.CalendarFeed, error) {
+	feed, err := scanCalendarFeed(r.db.QueryRow(calendarFeedSelect+` WHERE id = $1`, id))
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get calendar feed: %w", err)
+	}
+	return feed, nil
+}
+
+// GetActiveByTokenHash 根据令牌哈希获取未撤销的日历订阅，不存在时返回 nil
+func (r *calendarFeedRepository) GetActiveByTokenHash(tokenHash string) (*domain.CalendarFeed, error) {
+	feed, err := scanCalendarFeed(r.db.QueryRow(calendarFeedSelect+` WHERE token_hash = $1 AND revoked_at IS NULL`, tokenHash))
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get calendar feed: %w", err)
+	}
+	return feed, nil
+}
+
+// ListByCreator 获取账号创建的日历订阅，按创建时间倒序
+func (r *calendarFeedRepository) ListByCreator(adminID int) ([]*domain.CalendarFeed, error) {
+	rows, err := r.db.Query(calendarFeedSelect+` WHERE created_by = $1 ORDER BY created_at DESC, id DESC`, adminID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query calendar feeds: %w", err)
+	}
+	defer rows.Close()
+
+	var feeds []*domain.CalendarFeed
+	for rows.Next() {
+		feed, err := scanCalendarFeed(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan calendar feed: %w", err)
+		}
+		feeds = append(feeds, feed)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to iterate calendar feeds: %w", err)
+	}
+	return feeds, nil
+}
+
+// Revoke 撤销日历订阅
+func (r *calendarFeedRepository) Revoke(id int) error {
+	logger.WithFields(map[string]interface{}{
+		"feed_id": id,
+	}).Info("Revoking calendar feed")
+
+	if _, err := r.db.Exec(`UPDATE calendar_feeds SET revoked_at = CURRENT_TIMESTAMP WHERE id = $1 AND revoked_at IS NULL`, id); err != nil {
+		logger.WithError(err).Error("Failed to revoke calendar feed")
+		return fmt.Errorf("failed to revoke calendar feed: %w", err)
+	}
+	return nil
+}
+
+// TouchLastUsed 记录订阅最近一次被日历客户端访问的时间
+func (r *calendarFeedRepository) TouchLastUsed(id int) error {
+	if _, err := r.db.Exec(`UPDATE calendar_feeds SET last_used_at = CURRENT_TIMESTAMP WHERE id = $1`, id); err != nil {
+		return fmt.Errorf("failed to update calendar feed: %w", err)
+	}
+	return nil
+}
+
+// scanCalendarFeed 扫描日历订阅查询的一行
+func scanCalendarFeed(row rowScanner) (*domain.CalendarFeed, error) {
+	feed := &domain.CalendarFeed{}
+	var lastUsedAt, revokedAt sql.NullTime
+	if err := row.Scan(&feed.ID, &feed.TokenHash, &feed.OwnerType, &feed.OwnerID, &feed.Name, &feed.CreatedBy,
+		&lastUsedAt, &revokedAt, &feed.CreatedAt); err != nil {
+		return nil, err
+	}
+	if lastUsedAt.Valid {
+		feed.LastUsedAt = &lastUsedAt.Time
+	}
+	if revokedAt.Valid {
+		feed.RevokedAt = &revokedAt.Time
+	}
+	return feed, nil
+}
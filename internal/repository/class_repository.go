@@ -0,0 +1,422 @@
+package repository
+
+import (
+	"database/sql"
+	"fmt"
+	"strings"
+	"time"
+
+	"student-management-system/internal/domain"
+	"student-management-system/pkg/logger"
+
+	"github.com/lib/pq"
+)
+
+// ClassRepository 班级仓储接口
+type ClassRepository interface {
+	Create(class *domain.Class) error
+	GetByID(id int) (*domain.Class, error)
+	Update(class *domain.Class) error
+	Delete(id int) error
+	List(req *domain.ClassListRequest) ([]*domain.Class, int64, error)
+	ExistsByName(gradeYear int, name string, excludeID int) (bool, error)
+	TeacherExists(teacherID int) (bool, error)
+	HasMemberships(classID int) (bool, error)
+	MissingStudentIDs(studentIDs []int) ([]int, error)
+	AssignStudents(classID int, studentIDs []int, joinedAt time.Time, reason string, createdBy *int) error
+	RemoveStudent(classID, studentID int, leftAt time.Time, reason string) (bool, error)
+	ListStudents(classID int) ([]*domain.Student, error)
+	ListMemberships(studentID int) ([]*domain.ClassMembership, error)
+}
+
+// classRepository 班级仓储实现
+type classRepository struct {
+	db *sql.DB
+}
+
+// NewClassRepository 创建班级仓储实例
+func NewClassRepository(db *sql.DB) ClassRepository {
+	return &classRepository{db: db}
+}
+
+// classSelect 班级查询的字段和关联，附带班主任姓名和当前在班人数
+const classSelect = `
+		SELECT c.id, c.name, c.grade_year, c.major, c.homeroom_teacher_id, c.status, c.created_at, c.updated_at,
+		       COALESCE(t.name, ''),
+		       (SELECT COUNT(*) FROM students st WHERE st.class_id = c.id)
+		FROM classes c
+		LEFT JOIN teachers t ON c.homeroom_teacher_id = t.id`
+
+// Create 创建班级
+func (r *classRepository) Create(class *domain.Class) error {
+	logger.WithFields(map[string]interface{}{
+		"name":       class.Name,
+		"grade_year": class.GradeYear,
+	}).Info("Creating class")
+
+	query := `
+		INSERT INTO classes (name, grade_year, major, homeroom_teacher_id, status)
+		VALUES ($1, $2, $3, $4, $5)
+		RETURNING id, created_at, updated_at
+	`
+
+	err := r.db.QueryRow(query, class.Name, class.GradeYear, class.Major, class.HomeroomTeacherID, class.Status).
+		Scan(&class.ID, &class.CreatedAt, &class.UpdatedAt)
+	if err != nil {
+		logger.WithError(err).Error("Failed to create class")
+		return fmt.Errorf("failed to create class: %w", err)
+	}
+
+	return nil
+}
+
+// GetByID 根据ID获取班级，不存在时返回 nil
+func (r *classRepository) GetByID(id int) (*domain.Class, error) {
+	class, err := scanClass(r.db.QueryRow(classSelect+` WHERE c.id = $1`, id))
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get class: %w", err)
+	}
+	return class, nil
+}
+
+// Update 更新班级
+func (r *classRepository) Update(class *domain.Class) error {
+	logger.WithFields(map[string]interface{}{
+		"class_id": class.ID,
+	}).Info("Updating class")
+
+	query := `
+		UPDATE classes
+		SET name = $2, grade_year = $3, major = $4, homeroom_teacher_id = $5, status = $6
+		WHERE id = $1
+		RETURNING updated_at
+	`
+
+	err := r.db.QueryRow(query, class.ID, class.Name, class.GradeYear, class.Major, class.HomeroomTeacherID, class.Status).
+		Scan(&class.UpdatedAt)
+	if err != nil {
+		logger.WithError(err).Error("Failed to update class")
+		return fmt.Errorf("failed to update class: %w", err)
+	}
+
+	return nil
+}
+
+// Delete 删除班级，学生的当前班级置空，分班历史一并删除
+func (r *classRepository) Delete(id int) error {
+	logger.WithFields(map[string]interface{}{
+		"class_id": id,
+	}).Info("Deleting class")
+
+	if _, err := r.db.Exec(`DELETE FROM classes WHERE id = $1`, id); err != nil {
+		logger.WithError(err).Error("Failed to delete class")
+		return fmt.Errorf("failed to delete class: %w", err)
+	}
+
+	return nil
+}
+
+// List 获取班级列表
+func (r *classRepository) List(req *domain.ClassListRequest) ([]*domain.Class, int64, error) {
+	var conditions []string
+	var args []interface{}
+	argIndex := 1
+
+	if req.Name != "" {
+		conditions = append(conditions, fmt.Sprintf("c.name ILIKE $%d", argIndex))
+		args = append(args, "%"+req.Name+"%")
+		argIndex++
+	}
+
+	if req.GradeYear > 0 {
+		conditions = append(conditions, fmt.Sprintf("c.grade_year = $%d", argIndex))
+		args = append(args, req.GradeYear)
+		argIndex++
+	}
+
+	if req.Major != "" {
+		conditions = append(conditions, fmt.Sprintf("c.major = $%d", argIndex))
+		args = append(args, req.Major)
+		argIndex++
+	}
+
+	if req.HomeroomTeacherID > 0 {
+		conditions = append(conditions, fmt.Sprintf("c.homeroom_teacher_id = $%d", argIndex))
+		args = append(args, req.HomeroomTeacherID)
+		argIndex++
+	}
+
+	if req.Status != "" {
+		conditions = append(conditions, fmt.Sprintf("c.status = $%d", argIndex))
+		args = append(args, req.Status)
+		argIndex++
+	}
+
+	whereClause := ""
+	if len(conditions) > 0 {
+		whereClause = "WHERE " + strings.Join(conditions, " AND ")
+	}
+
+	var total int64
+	if err := r.db.QueryRow(`SELECT COUNT(*) FROM classes c `+whereClause, args...).Scan(&total); err != nil {
+		return nil, 0, fmt.Errorf("failed to count classes: %w", err)
+	}
+
+	offset := (req.Page - 1) * req.Size
+	query := fmt.Sprintf(`%s
+		%s
+		ORDER BY c.grade_year DESC, c.name
+		LIMIT $%d OFFSET $%d
+	`, classSelect, whereClause, argIndex, argIndex+1)
+	args = append(args, req.Size, offset)
+
+	rows, err := r.db.Query(query, args...)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to query classes: %w", err)
+	}
+	defer rows.Close()
+
+	var classes []*domain.Class
+	for rows.Next() {
+		class, err := scanClass(rows)
+		if err != nil {
+			return nil, 0, fmt.Errorf("failed to scan class: %w", err)
+		}
+		classes = append(classes, class)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, 0, fmt.Errorf("failed to iterate classes: %w", err)
+	}
+
+	return classes, total, nil
+}
+
+// ExistsByName 检查同一年级下是否已存在同名班级
+func (r *classRepository) ExistsByName(gradeYear int, name string, excludeID int) (bool, error) {
+	var exists bool
+	err := r.db.QueryRow(
+		`SELECT EXISTS(SELECT 1 FROM classes WHERE grade_year = $1 AND name = $2 AND id <> $3)`,
+		gradeYear, name, excludeID,
+	).Scan(&exists)
+	if err != nil {
+		return false, fmt.Errorf("failed to check class name: %w", err)
+	}
+	return exists, nil
+}
+
+// TeacherExists 检查老师是否存在
+func (r *classRepository) TeacherExists(teacherID int) (bool, error) {
+	var exists bool
+	if err := r.db.QueryRow(`SELECT EXISTS(SELECT 1 FROM teachers WHERE id = $1)`, teacherID).Scan(&exists); err != nil {
+		return false, fmt.Errorf("failed to check teacher: %w", err)
+	}
+	return exists, nil
+}
+
+// HasMemberships 检查班级是否有分班记录（包括历史记录）
+func (r *classRepository) HasMemberships(classID int) (bool, error) {
+	var exists bool
+	if err := r.db.QueryRow(`SELECT EXISTS(SELECT 1 FROM class_memberships WHERE class_id = $1)`, classID).Scan(&exists); err != nil {
+		return false, fmt.Errorf("failed to check class memberships: %w", err)
+	}
+	return exists, nil
+}
+
+// MissingStudentIDs 返回不存在的学生ID
+func (r *classRepository) MissingStudentIDs(studentIDs []int) ([]int, error) {
+	rows, err := r.db.Query(`
+		SELECT u.id FROM UNNEST($1::int[]) AS u(id)
+		WHERE NOT EXISTS (SELECT 1 FROM students st WHERE st.id = u.id)
+		ORDER BY u.id
+	`, pq.Array(studentIDs))
+	if err != nil {
+		return nil, fmt.Errorf("failed to check students: %w", err)
+	}
+	defer rows.Close()
+
+	var missing []int
+	for rows.Next() {
+		var id int
+		if err := rows.Scan(&id); err != nil {
+			return nil, fmt.Errorf("failed to scan student id: %w", err)
+		}
+		missing = append(missing, id)
+	}
+	return missing, rows.Err()
+}
+
+// AssignStudents 将学生分入班级：结束学生在其他班级的当前记录，新增本班记录并更新学生的当前班级。
+// 已在本班的学生保持不变。
+func (r *classRepository) AssignStudents(classID int, studentIDs []int, joinedAt time.Time, reason string, createdBy *int) error {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	ids := pq.Array(studentIDs)
+
+	// 锁定学生行，避免并发分班产生多条当前记录
+	if _, err := tx.Exec(`SELECT id FROM students WHERE id = ANY($1) FOR UPDATE`, ids); err != nil {
+		return fmt.Errorf("failed to lock students: %w", err)
+	}
+
+	if _, err := tx.Exec(`
+		UPDATE class_memberships SET left_at = GREATEST($3::date, joined_at)
+		WHERE student_id = ANY($1) AND class_id <> $2 AND left_at IS NULL
+	`, ids, classID, joinedAt); err != nil {
+		return fmt.Errorf("failed to close previous memberships: %w", err)
+	}
+
+	if _, err := tx.Exec(`
+		INSERT INTO class_memberships (student_id, class_id, joined_at, reason, created_by)
+		SELECT u.id, $2, $3, NULLIF($4, ''), $5 FROM UNNEST($1::int[]) AS u(id)
+		WHERE NOT EXISTS (
+			SELECT 1 FROM class_memberships m
+			WHERE m.student_id = u.id AND m.class_id = $2 AND m.left_at IS NULL
+		)
+	`, ids, classID, joinedAt, reason, createdBy); err != nil {
+		return fmt.Errorf("failed to create memberships: %w", err)
+	}
+
+	if _, err := tx.Exec(`UPDATE students SET class_id = $2 WHERE id = ANY($1)`, ids, classID); err != nil {
+		return fmt.Errorf("failed to update student class: %w", err)
+	}
+
+	return tx.Commit()
+}
+
+// RemoveStudent 学生离开班级，学生不在该班时返回 false
+func (r *classRepository) RemoveStudent(classID, studentID int, leftAt time.Time, reason string) (bool, error) {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return false, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	result, err := tx.Exec(`
+		UPDATE class_memberships
+		SET left_at = GREATEST($3::date, joined_at),
+		    reason = COALESCE(NULLIF($4, ''), reason)
+		WHERE student_id = $1 AND class_id = $2 AND left_at IS NULL
+	`, studentID, classID, leftAt, reason)
+	if err != nil {
+		return false, fmt.Errorf("failed to close membership: %w", err)
+	}
+
+	if n, _ := result.RowsAffected(); n == 0 {
+		return false, nil
+	}
+
+	if _, err := tx.Exec(`UPDATE students SET class_id = NULL WHERE id = $1 AND class_id = $2`, studentID, classID); err != nil {
+		return false, fmt.Errorf("failed to update student class: %w", err)
+	}
+
+	return true, tx.Commit()
+}
+
+// ListStudents 获取班级当前的学生
+func (r *classRepository) ListStudents(classID int) ([]*domain.Student, error) {
+	query := `
+		SELECT id, student_id, name, age, gender, phone, email, address, major,
+		       enrollment_date, graduation_date, status, class_id, created_at, updated_at
+		FROM students
+		WHERE class_id = $1
+		ORDER BY student_id
+	`
+
+	rows, err := r.db.Query(query, classID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query class students: %w", err)
+	}
+	defer rows.Close()
+
+	var students []*domain.Student
+	for rows.Next() {
+		student := &domain.Student{}
+		if err := rows.Scan(
+			&student.ID,
+			&student.StudentID,
+			&student.Name,
+			&student.Age,
+			&student.Gender,
+			&student.Phone,
+			&student.Email,
+			&student.Address,
+			&student.Major,
+			&student.EnrollmentDate,
+			&student.GraduationDate,
+			&student.Status,
+			&student.ClassID,
+			&student.CreatedAt,
+			&student.UpdatedAt,
+		); err != nil {
+			return nil, fmt.Errorf("failed to scan class student: %w", err)
+		}
+		students = append(students, student)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to iterate class students: %w", err)
+	}
+
+	return students, nil
+}
+
+// ListMemberships 获取学生的分班历史，按入班时间倒序
+func (r *classRepository) ListMemberships(studentID int) ([]*domain.ClassMembership, error) {
+	query := `
+		SELECT m.id, m.student_id, m.class_id, m.joined_at, m.left_at, COALESCE(m.reason, ''), m.created_by, m.created_at,
+		       c.name, c.grade_year
+		FROM class_memberships m
+		JOIN classes c ON m.class_id = c.id
+		WHERE m.student_id = $1
+		ORDER BY m.joined_at DESC, m.id DESC
+	`
+
+	rows, err := r.db.Query(query, studentID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query class memberships: %w", err)
+	}
+	defer rows.Close()
+
+	var memberships []*domain.ClassMembership
+	for rows.Next() {
+		m := &domain.ClassMembership{}
+		var createdBy sql.NullInt64
+		if err := rows.Scan(&m.ID, &m.StudentID, &m.ClassID, &m.JoinedAt, &m.LeftAt, &m.Reason, &createdBy, &m.CreatedAt,
+			&m.ClassName, &m.GradeYear); err != nil {
+			return nil, fmt.Errorf("failed to scan class membership: %w", err)
+		}
+		if createdBy.Valid {
+			id := int(createdBy.Int64)
+			m.CreatedBy = &id
+		}
+		memberships = append(memberships, m)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to iterate class memberships: %w", err)
+	}
+
+	return memberships, nil
+}
+
+// scanClass 扫描班级查询的一行
+func scanClass(row rowScanner) (*domain.Class, error) {
+	class := &domain.Class{}
+	var teacherID sql.NullInt64
+	if err := row.Scan(&class.ID, &class.Name, &class.GradeYear, &class.Major, &teacherID, &class.Status,
+		&class.CreatedAt, &class.UpdatedAt, &class.HomeroomTeacherName, &class.StudentCount); err != nil {
+		return nil, err
+	}
+	if teacherID.Valid {
+		id := int(teacherID.Int64)
+		class.HomeroomTeacherID = &id
+	}
+	return class, nil
+}
@@ -0,0 +1,553 @@
+package repository
+
+import (
+	"database/sql"
+	"fmt"
+	"strings"
+
+	"student-management-system/internal/domain"
+	"student-management-system/pkg/logger"
+
+	"github.com/lib/pq"
+)
+
+// CourseOfferingRepository 开课与选课仓储接口
+type CourseOfferingRepository interface {
+	Create(offering *domain.CourseOffering) error
+	GetByID(id int) (*domain.CourseOffering, error)
+	Update(offering *domain.CourseOffering) error
+	Delete(id int) error
+	List(req *domain.CourseOfferingListRequest) ([]*domain.CourseOffering, int64, error)
+	ExistsBySection(subjectID int, semester, section string, excludeID int) (bool, error)
+	SubjectExists(subjectID int) (bool, error)
+	TeacherExists(teacherID int) (bool, error)
+	StudentExists(studentID int) (bool, error)
+	HasEnrollments(offeringID int) (bool, error)
+	StudentHasScores(offeringID, studentID int) (bool, error)
+	GetEnrollment(offeringID, studentID int) (*domain.Enrollment, error)
+	FindStudentOffering(studentID, subjectID int, semester string, statuses []string) (int, error)
+	Enroll(offeringID, studentID int, createdBy *int) (*domain.Enrollment, error)
+	Drop(offeringID, studentID int) ([]int, error)
+	PromoteWaitlist(offeringID int) ([]int, error)
+	ListEnrollments(offeringID int, req *domain.EnrollmentListRequest) ([]*domain.Enrollment, error)
+	ListStudentEnrollments(studentID int, req *domain.EnrollmentListRequest) ([]*domain.Enrollment, error)
+}
+
+// courseOfferingRepository 开课与选课仓储实现
+type courseOfferingRepository struct {
+	db *sql.DB
+}
+
+// NewCourseOfferingRepository 创建开课仓储实例
+func NewCourseOfferingRepository(db *sql.DB) CourseOfferingRepository {
+	return &courseOfferingRepository{db: db}
+}
+
+// offeringSelect 开课查询的字段和关联，附带科目、任课老师以及已选和候补人数
+const offeringSelect = `
+		SELECT o.id, o.subject_id, o.semester, o.section, o.teacher_id, o.capacity, o.waitlist_capacity,
+		       COALESCE(o.schedule, ''), o.add_deadline, o.drop_deadline, o.status, o.created_at, o.updated_at,
+		       COALESCE(sub.name, ''), COALESCE(sub.code, ''), COALESCE(t.name, ''),
+		       (SELECT COUNT(*) FROM enrollments e WHERE e.offering_id = o.id AND e.status = 'enrolled'),
+		       (SELECT COUNT(*) FROM enrollments e WHERE e.offering_id = o.id AND e.status = 'waitlisted')
+		FROM course_offerings o
+		LEFT JOIN subjects sub ON o.subject_id = sub.id
+		LEFT JOIN teachers t ON o.teacher_id = t.id`
+
+// enrollmentSelect 选课记录查询的字段和关联，候补排位按提交时间计算
+const enrollmentSelect = `
+		SELECT e.id, e.offering_id, e.student_id, e.status, e.requested_at, e.enrolled_at, e.dropped_at, e.created_by, e.updated_at,
+		       COALESCE(st.name, ''), COALESCE(st.student_id, ''),
+		       o.subject_id, COALESCE(sub.name, ''), o.semester, o.section,
+		       CASE WHEN e.status = 'waitlisted' THEN (
+		           SELECT COUNT(*) FROM enrollments w
+		           WHERE w.offering_id = e.offering_id AND w.status = 'waitlisted'
+		             AND (w.requested_at, w.id) <= (e.requested_at, e.id)
+		       ) ELSE 0 END
+		FROM enrollments e
+		JOIN course_offerings o ON e.offering_id = o.id
+		LEFT JOIN students st ON e.student_id = st.id
+		LEFT JOIN subjects sub ON o.subject_id = sub.id`
+
+// Create 创建开课
+func (r *courseOfferingRepository) Create(offering *domain.CourseOffering) error {
+	logger.WithFields(map[string]interface{}{
+		"subject_id": offering.SubjectID,
+		"semester":   offering.Semester,
+		"section":    offering.Section,
+	}).Info("Creating course offering")
+
+	query := `
+		INSERT INTO course_offerings (subject_id, semester, section, teacher_id, capacity, waitlist_capacity,
+		                              schedule, add_deadline, drop_deadline, status)
+		VALUES ($1, $2, $3, $4, $5, $6, NULLIF($7, ''), $8, $9, $10)
+		RETURNING id, created_at, updated_at
+	`
+
+	err := r.db.QueryRow(query, offering.SubjectID, offering.Semester, offering.Section, offering.TeacherID,
+		offering.Capacity, offering.WaitlistCapacity, offering.Schedule, offering.AddDeadline, offering.DropDeadline,
+		offering.Status).Scan(&offering.ID, &offering.CreatedAt, &offering.UpdatedAt)
+	if err != nil {
+		logger.WithError(err).Error("Failed to create course offering")
+		return fmt.Errorf("failed to create course offering: %w", err)
+	}
+
+	return nil
+}
+
+// GetByID 根据ID获取开课，不存在时返回 nil
+func (r *courseOfferingRepository) GetByID(id int) (*domain.CourseOffering, error) {
+	offering, err := scanOffering(r.db.QueryRow(offeringSelect+` WHERE o.id = $1`, id))
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get course offering: %w", err)
+	}
+	return offering, nil
+}
+
+// Update 更新开课
+func (r *courseOfferingRepository) Update(offering *domain.CourseOffering) error {
+	logger.WithFields(map[string]interface{}{
+		"offering_id": offering.ID,
+	}).Info("Updating course offering")
+
+	query := `
+		UPDATE course_offerings
+		SET section = $2, teacher_id = $3, capacity = $4, waitlist_capacity = $5, schedule = NULLIF($6, ''),
+		    add_deadline = $7, drop_deadline = $8, status = $9
+		WHERE id = $1
+		RETURNING updated_at
+	`
+
+	err := r.db.QueryRow(query, offering.ID, offering.Section, offering.TeacherID, offering.Capacity,
+		offering.WaitlistCapacity, offering.Schedule, offering.AddDeadline, offering.DropDeadline, offering.Status).
+		Scan(&offering.UpdatedAt)
+	if err != nil {
+		logger.WithError(err).Error("Failed to update course offering")
+		return fmt.Errorf("failed to update course offering: %w", err)
+	}
+
+	return nil
+}
+
+// Delete 删除开课
+func (r *courseOfferingRepository) Delete(id int) error {
+	logger.WithFields(map[string]interface{}{
+		"offering_id": id,
+	}).Info("Deleting course offering")
+
+	if _, err := r.db.Exec(`DELETE FROM course_offerings WHERE id = $1`, id); err != nil {
+		logger.WithError(err).Error("Failed to delete course offering")
+		return fmt.Errorf("failed to delete course offering: %w", err)
+	}
+
+	return nil
+}
+
+// List 获取开课列表
+func (r *courseOfferingRepository) List(req *domain.CourseOfferingListRequest) ([]*domain.CourseOffering, int64, error) {
+	var conditions []string
+	var args []interface{}
+	argIndex := 1
+
+	if req.SubjectID > 0 {
+		conditions = append(conditions, fmt.Sprintf("o.subject_id = $%d", argIndex))
+		args = append(args, req.SubjectID)
+		argIndex++
+	}
+
+	if req.Semester != "" {
+		conditions = append(conditions, fmt.Sprintf("o.semester = $%d", argIndex))
+		args = append(args, req.Semester)
+		argIndex++
+	}
+
+	if req.TeacherID > 0 {
+		conditions = append(conditions, fmt.Sprintf("o.teacher_id = $%d", argIndex))
+		args = append(args, req.TeacherID)
+		argIndex++
+	}
+
+	if req.Status != "" {
+		conditions = append(conditions, fmt.Sprintf("o.status = $%d", argIndex))
+		args = append(args, req.Status)
+		argIndex++
+	}
+
+	whereClause := ""
+	if len(conditions) > 0 {
+		whereClause = "WHERE " + strings.Join(conditions, " AND ")
+	}
+
+	var total int64
+	if err := r.db.QueryRow(`SELECT COUNT(*) FROM course_offerings o `+whereClause, args...).Scan(&total); err != nil {
+		return nil, 0, fmt.Errorf("failed to count course offerings: %w", err)
+	}
+
+	offset := (req.Page - 1) * req.Size
+	query := fmt.Sprintf(`%s
+		%s
+		ORDER BY o.semester DESC, sub.code, o.section
+		LIMIT $%d OFFSET $%d
+	`, offeringSelect, whereClause, argIndex, argIndex+1)
+	args = append(args, req.Size, offset)
+
+	rows, err := r.db.Query(query, args...)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to query course offerings: %w", err)
+	}
+	defer rows.Close()
+
+	var offerings []*domain.CourseOffering
+	for rows.Next() {
+		offering, err := scanOffering(rows)
+		if err != nil {
+			return nil, 0, fmt.Errorf("failed to scan course offering: %w", err)
+		}
+		offerings = append(offerings, offering)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, 0, fmt.Errorf("failed to iterate course offerings: %w", err)
+	}
+
+	return offerings, total, nil
+}
+
+// ExistsBySection 检查同一科目同一学期是否已存在相同教学班号
+func (r *courseOfferingRepository) ExistsBySection(subjectID int, semester, section string, excludeID int) (bool, error) {
+	var exists bool
+	err := r.db.QueryRow(
+		`SELECT EXISTS(SELECT 1 FROM course_offerings WHERE subject_id = $1 AND semester = $2 AND section = $3 AND id <> $4)`,
+		subjectID, semester, section, excludeID,
+	).Scan(&exists)
+	if err != nil {
+		return false, fmt.Errorf("failed to check course offering section: %w", err)
+	}
+	return exists, nil
+}
+
+// SubjectExists 检查科目是否存在
+func (r *courseOfferingRepository) SubjectExists(subjectID int) (bool, error) {
+	var exists bool
+	if err := r.db.QueryRow(`SELECT EXISTS(SELECT 1 FROM subjects WHERE id = $1)`, subjectID).Scan(&exists); err != nil {
+		return false, fmt.Errorf("failed to check subject: %w", err)
+	}
+	return exists, nil
+}
+
+// TeacherExists 检查老师是否存在
+func (r *courseOfferingRepository) TeacherExists(teacherID int) (bool, error) {
+	var exists bool
+	if err := r.db.QueryRow(`SELECT EXISTS(SELECT 1 FROM teachers WHERE id = $1)`, teacherID).Scan(&exists); err != nil {
+		return false, fmt.Errorf("failed to check teacher: %w", err)
+	}
+	return exists, nil
+}
+
+// StudentExists 检查学生是否存在
+func (r *courseOfferingRepository) StudentExists(studentID int) (bool, error) {
+	var exists bool
+	if err := r.db.QueryRow(`SELECT EXISTS(SELECT 1 FROM students WHERE id = $1)`, studentID).Scan(&exists); err != nil {
+		return false, fmt.Errorf("failed to check student: %w", err)
+	}
+	return exists, nil
+}
+
+// HasEnrollments 检查开课是否有选课记录或成绩
+func (r *courseOfferingRepository) HasEnrollments(offeringID int) (bool, error) {
+	var exists bool
+	err := r.db.QueryRow(`
+		SELECT EXISTS(SELECT 1 FROM enrollments WHERE offering_id = $1)
+		    OR EXISTS(SELECT 1 FROM scores WHERE offering_id = $1)
+	`, offeringID).Scan(&exists)
+	if err != nil {
+		return false, fmt.Errorf("failed to check enrollments: %w", err)
+	}
+	return exists, nil
+}
+
+// StudentHasScores 检查学生在该开课下是否已有成绩
+func (r *courseOfferingRepository) StudentHasScores(offeringID, studentID int) (bool, error) {
+	var exists bool
+	err := r.db.QueryRow(
+		`SELECT EXISTS(SELECT 1 FROM scores WHERE offering_id = $1 AND student_id = $2)`,
+		offeringID, studentID,
+	).Scan(&exists)
+	if err != nil {
+		return false, fmt.Errorf("failed to check student scores: %w", err)
+	}
+	return exists, nil
+}
+
+// GetEnrollment 获取学生在该开课的选课记录，不存在时返回 nil
+func (r *courseOfferingRepository) GetEnrollment(offeringID, studentID int) (*domain.Enrollment, error) {
+	enrollment, err := scanEnrollment(r.db.QueryRow(enrollmentSelect+` WHERE e.offering_id = $1 AND e.student_id = $2`,
+		offeringID, studentID))
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get enrollment: %w", err)
+	}
+	return enrollment, nil
+}
+
+// FindStudentOffering 查找学生在某科目某学期处于指定选课状态的开课，没有时返回 0
+func (r *courseOfferingRepository) FindStudentOffering(studentID, subjectID int, semester string, statuses []string) (int, error) {
+	var offeringID int
+	err := r.db.QueryRow(`
+		SELECT o.id
+		FROM enrollments e
+		JOIN course_offerings o ON e.offering_id = o.id
+		WHERE e.student_id = $1 AND o.subject_id = $2 AND o.semester = $3 AND e.status = ANY($4)
+		ORDER BY o.id
+		LIMIT 1
+	`, studentID, subjectID, semester, pq.Array(statuses)).Scan(&offeringID)
+	if err == sql.ErrNoRows {
+		return 0, nil
+	}
+	if err != nil {
+		return 0, fmt.Errorf("failed to find student offering: %w", err)
+	}
+	return offeringID, nil
+}
+
+// Enroll 学生选课：未满时直接选上，已满时进入候补，候补也已满时返回 nil。
+// 开课行在事务中加锁，保证并发选课不会超出容量。已有有效选课记录时原样返回。
+func (r *courseOfferingRepository) Enroll(offeringID, studentID int, createdBy *int) (*domain.Enrollment, error) {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	var capacity, waitlistCapacity int
+	if err := tx.QueryRow(
+		`SELECT capacity, waitlist_capacity FROM course_offerings WHERE id = $1 FOR UPDATE`, offeringID,
+	).Scan(&capacity, &waitlistCapacity); err != nil {
+		return nil, fmt.Errorf("failed to lock course offering: %w", err)
+	}
+
+	var current string
+	err = tx.QueryRow(`SELECT status FROM enrollments WHERE offering_id = $1 AND student_id = $2`, offeringID, studentID).
+		Scan(&current)
+	if err != nil && err != sql.ErrNoRows {
+		return nil, fmt.Errorf("failed to get enrollment: %w", err)
+	}
+
+	if current != domain.EnrollmentStatusEnrolled && current != domain.EnrollmentStatusWaitlisted {
+		var enrolled, waitlisted int
+		if err := tx.QueryRow(`
+			SELECT COUNT(*) FILTER (WHERE status = 'enrolled'), COUNT(*) FILTER (WHERE status = 'waitlisted')
+			FROM enrollments WHERE offering_id = $1
+		`, offeringID).Scan(&enrolled, &waitlisted); err != nil {
+			return nil, fmt.Errorf("failed to count enrollments: %w", err)
+		}
+
+		// 有人候补时新的选课一律排在候补队尾，避免插队
+		status := domain.EnrollmentStatusEnrolled
+		if enrolled >= capacity || waitlisted > 0 {
+			if waitlisted >= waitlistCapacity {
+				return nil, nil
+			}
+			status = domain.EnrollmentStatusWaitlisted
+		}
+
+		if _, err := tx.Exec(`
+			INSERT INTO enrollments (offering_id, student_id, status, requested_at, enrolled_at, created_by)
+			VALUES ($1, $2, $3::varchar, CURRENT_TIMESTAMP,
+			        CASE WHEN $3::varchar = 'enrolled' THEN CURRENT_TIMESTAMP END, $4)
+			ON CONFLICT (offering_id, student_id) DO UPDATE
+			SET status = EXCLUDED.status, requested_at = EXCLUDED.requested_at, enrolled_at = EXCLUDED.enrolled_at,
+			    dropped_at = NULL, created_by = EXCLUDED.created_by
+		`, offeringID, studentID, status, createdBy); err != nil {
+			return nil, fmt.Errorf("failed to create enrollment: %w", err)
+		}
+	}
+
+	enrollment, err := scanEnrollment(tx.QueryRow(enrollmentSelect+` WHERE e.offering_id = $1 AND e.student_id = $2`,
+		offeringID, studentID))
+	if err != nil {
+		return nil, fmt.Errorf("failed to get enrollment: %w", err)
+	}
+
+	return enrollment, tx.Commit()
+}
+
+// Drop 学生退课或退出候补，空出的名额按候补顺序递补，返回递补选上的学生ID
+func (r *courseOfferingRepository) Drop(offeringID, studentID int) ([]int, error) {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.Exec(`SELECT id FROM course_offerings WHERE id = $1 FOR UPDATE`, offeringID); err != nil {
+		return nil, fmt.Errorf("failed to lock course offering: %w", err)
+	}
+
+	if _, err := tx.Exec(`
+		UPDATE enrollments SET status = 'dropped', dropped_at = CURRENT_TIMESTAMP
+		WHERE offering_id = $1 AND student_id = $2 AND status IN ('enrolled', 'waitlisted')
+	`, offeringID, studentID); err != nil {
+		return nil, fmt.Errorf("failed to drop enrollment: %w", err)
+	}
+
+	promoted, err := promoteWaitlist(tx, offeringID)
+	if err != nil {
+		return nil, err
+	}
+
+	return promoted, tx.Commit()
+}
+
+// PromoteWaitlist 按候补顺序递补到容量上限，用于扩容后处理候补名单
+func (r *courseOfferingRepository) PromoteWaitlist(offeringID int) ([]int, error) {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.Exec(`SELECT id FROM course_offerings WHERE id = $1 FOR UPDATE`, offeringID); err != nil {
+		return nil, fmt.Errorf("failed to lock course offering: %w", err)
+	}
+
+	promoted, err := promoteWaitlist(tx, offeringID)
+	if err != nil {
+		return nil, err
+	}
+
+	return promoted, tx.Commit()
+}
+
+// promoteWaitlist 在已锁定开课的事务中将候补学生递补到空出的名额
+func promoteWaitlist(tx *sql.Tx, offeringID int) ([]int, error) {
+	rows, err := tx.Query(`
+		UPDATE enrollments SET status = 'enrolled', enrolled_at = CURRENT_TIMESTAMP
+		WHERE id IN (
+			SELECT w.id FROM enrollments w
+			WHERE w.offering_id = $1 AND w.status = 'waitlisted'
+			ORDER BY w.requested_at, w.id
+			LIMIT GREATEST((
+				SELECT o.capacity - (SELECT COUNT(*) FROM enrollments e WHERE e.offering_id = o.id AND e.status = 'enrolled')
+				FROM course_offerings o WHERE o.id = $1
+			), 0)
+		)
+		RETURNING student_id
+	`, offeringID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to promote waitlist: %w", err)
+	}
+	defer rows.Close()
+
+	var promoted []int
+	for rows.Next() {
+		var id int
+		if err := rows.Scan(&id); err != nil {
+			return nil, fmt.Errorf("failed to scan promoted student: %w", err)
+		}
+		promoted = append(promoted, id)
+	}
+	return promoted, rows.Err()
+}
+
+// ListEnrollments 获取开课的选课名单，依次为已选（按学号）、候补（按排位）和已退课的学生
+func (r *courseOfferingRepository) ListEnrollments(offeringID int, req *domain.EnrollmentListRequest) ([]*domain.Enrollment, error) {
+	query := enrollmentSelect + ` WHERE e.offering_id = $1`
+	args := []interface{}{offeringID}
+	if req.Status != "" {
+		query += ` AND e.status = $2`
+		args = append(args, req.Status)
+	}
+	query += ` ORDER BY CASE e.status WHEN 'enrolled' THEN 0 WHEN 'waitlisted' THEN 1 ELSE 2 END,
+		CASE WHEN e.status = 'waitlisted' THEN e.requested_at END, st.student_id, e.id`
+
+	return r.queryEnrollments(query, args...)
+}
+
+// ListStudentEnrollments 获取学生的选课记录，按学期倒序
+func (r *courseOfferingRepository) ListStudentEnrollments(studentID int, req *domain.EnrollmentListRequest) ([]*domain.Enrollment, error) {
+	var conditions []string
+	args := []interface{}{studentID}
+	argIndex := 2
+
+	if req.Status != "" {
+		conditions = append(conditions, fmt.Sprintf("e.status = $%d", argIndex))
+		args = append(args, req.Status)
+		argIndex++
+	}
+
+	if req.Semester != "" {
+		conditions = append(conditions, fmt.Sprintf("o.semester = $%d", argIndex))
+		args = append(args, req.Semester)
+		argIndex++
+	}
+
+	query := enrollmentSelect + ` WHERE e.student_id = $1`
+	if len(conditions) > 0 {
+		query += " AND " + strings.Join(conditions, " AND ")
+	}
+	query += ` ORDER BY o.semester DESC, sub.code, o.section`
+
+	return r.queryEnrollments(query, args...)
+}
+
+// queryEnrollments 执行选课记录查询
+func (r *courseOfferingRepository) queryEnrollments(query string, args ...interface{}) ([]*domain.Enrollment, error) {
+	rows, err := r.db.Query(query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query enrollments: %w", err)
+	}
+	defer rows.Close()
+
+	var enrollments []*domain.Enrollment
+	for rows.Next() {
+		enrollment, err := scanEnrollment(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan enrollment: %w", err)
+		}
+		enrollments = append(enrollments, enrollment)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to iterate enrollments: %w", err)
+	}
+
+	return enrollments, nil
+}
+
+// scanOffering 扫描开课查询的一行
+func scanOffering(row rowScanner) (*domain.CourseOffering, error) {
+	o := &domain.CourseOffering{}
+	var teacherID sql.NullInt64
+	if err := row.Scan(&o.ID, &o.SubjectID, &o.Semester, &o.Section, &teacherID, &o.Capacity, &o.WaitlistCapacity,
+		&o.Schedule, &o.AddDeadline, &o.DropDeadline, &o.Status, &o.CreatedAt, &o.UpdatedAt,
+		&o.SubjectName, &o.SubjectCode, &o.TeacherName, &o.EnrolledCount, &o.WaitlistCount); err != nil {
+		return nil, err
+	}
+	if teacherID.Valid {
+		id := int(teacherID.Int64)
+		o.TeacherID = &id
+	}
+	return o, nil
+}
+
+// scanEnrollment 扫描选课记录查询的一行
+func scanEnrollment(row rowScanner) (*domain.Enrollment, error) {
+	e := &domain.Enrollment{}
+	var createdBy sql.NullInt64
+	if err := row.Scan(&e.ID, &e.OfferingID, &e.StudentID, &e.Status, &e.RequestedAt, &e.EnrolledAt, &e.DroppedAt,
+		&createdBy, &e.UpdatedAt, &e.StudentName, &e.StudentCode, &e.SubjectID, &e.SubjectName, &e.Semester,
+		&e.Section, &e.WaitlistPosition); err != nil {
+		return nil, err
+	}
+	if createdBy.Valid {
+		id := int(createdBy.Int64)
+		e.CreatedBy = &id
+	}
+	return e, nil
+}
@@ -0,0 +1,252 @@
+package repository
+
+import (
+	"database/sql"
+	"fmt"
+	"strings"
+	"time"
+
+	"student-management-system/internal/domain"
+	"student-management-system/pkg/logger"
+)
+
+// ExamRepository 考试安排仓储接口
+type ExamRepository interface {
+	Create(exam *domain.Exam) error
+	GetByID(id int) (*domain.Exam, error)
+	Update(exam *domain.Exam) error
+	Delete(id int) error
+	List(req *domain.ExamListRequest) ([]*domain.Exam, int64, error)
+	ListForOwner(semester, ownerType string, ownerID int) ([]*domain.Exam, error)
+	FindRoomConflict(roomID int, date time.Time, startTime, endTime string, excludeID int) (*domain.Exam, error)
+}
+
+// examRepository 考试安排仓储实现
+type examRepository struct {
+	db *sql.DB
+}
+
+// NewExamRepository 创建考试安排仓储实例
+func NewExamRepository(db *sql.DB) ExamRepository {
+	return &examRepository{db: db}
+}
+
+// examSelect 考试安排查询的字段和关联
+const examSelect = `
+		SELECT e.id, e.offering_id, e.exam_type, e.exam_date, to_char(e.start_time, 'HH24:MI'), to_char(e.end_time, 'HH24:MI'),
+		       e.room_id, COALESCE(e.location, ''), COALESCE(e.note, ''), e.created_by, e.created_at, e.updated_at,
+		       o.semester, o.subject_id, COALESCE(sub.code, ''), COALESCE(sub.name, ''), o.section,
+		       COALESCE(t.name, ''), COALESCE(r.code, ''), COALESCE(r.name, '')
+		FROM exams e
+		JOIN course_offerings o ON e.offering_id = o.id
+		LEFT JOIN subjects sub ON o.subject_id = sub.id
+		LEFT JOIN teachers t ON o.teacher_id = t.id
+		LEFT JOIN rooms r ON e.room_id = r.id`
+
+// examOrder 考试安排的默认排序
+const examOrder = ` ORDER BY e.exam_date, e.start_time, e.id`
+
+// Create 创建考试安排
+func (r *examRepository) Create(exam *domain.Exam) error {
+	logger.WithFields(map[string]interface{}{
+		"offering_id": exam.OfferingID,
+		"exam_type":   exam.ExamType,
+	}).Info("Creating exam")
+
+	query := `
+		INSERT INTO exams (offering_id, exam_type, exam_date, start_time, end_time, room_id, location, note, created_by)
+		VALUES ($1, $2, $3, $4, $5, $6, NULLIF($7, ''), NULLIF($8, ''), $9)
+		RETURNING id, created_at, updated_at
+	`
+
+	err := r.db.QueryRow(query, exam.OfferingID, exam.ExamType, exam.ExamDate, exam.StartTime, exam.EndTime,
+		exam.RoomID, exam.Location, exam.Note, exam.CreatedBy).Scan(&exam.ID, &exam.CreatedAt, &exam.UpdatedAt)
+	if err != nil {
+		logger.WithError(err).Error("Failed to create exam")
+		return fmt.Errorf("failed to create exam: %w", err)
+	}
+
+	return nil
+}
+
+// GetByID 根据ID获取考试安排，不存在时返回 nil
+func (r *examRepository) GetByID(id int) (*domain.Exam, error) {
+	exam, err := scanExam(r.db.QueryRow(examSelect+` WHERE e.id = $1`, id))
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get exam: %w", err)
+	}
+	return exam, nil
+}
+
+// Update 更新考试安排
+func (r *examRepository) Update(exam *domain.Exam) error {
+	logger.WithFields(map[string]interface{}{
+		"exam_id": exam.ID,
+	}).Info("Updating exam")
+
+	query := `
+		UPDATE exams
+		SET exam_type = $2, exam_date = $3, start_time = $4, end_time = $5, room_id = $6,
+		    location = NULLIF($7, ''), note = NULLIF($8, '')
+		WHERE id = $1
+		RETURNING updated_at
+	`
+
+	err := r.db.QueryRow(query, exam.ID, exam.ExamType, exam.ExamDate, exam.StartTime, exam.EndTime,
+		exam.RoomID, exam.Location, exam.Note).Scan(&exam.UpdatedAt)
+	if err != nil {
+		logger.WithError(err).Error("Failed to update exam")
+		return fmt.Errorf("failed to update exam: %w", err)
+	}
+
+	return nil
+}
+
+// Delete 删除考试安排
+func (r *examRepository) Delete(id int) error {
+	logger.WithFields(map[string]interface{}{
+		"exam_id": id,
+	}).Info("Deleting exam")
+
+	if _, err := r.db.Exec(`DELETE FROM exams WHERE id = $1`, id); err != nil {
+		logger.WithError(err).Error("Failed to delete exam")
+		return fmt.Errorf("failed to delete exam: %w", err)
+	}
+	return nil
+}
+
+// List 获取考试安排列表（分页）
+func (r *examRepository) List(req *domain.ExamListRequest) ([]*domain.Exam, int64, error) {
+	var conditions []string
+	var args []interface{}
+	argIndex := 1
+
+	if req.Semester != "" {
+		conditions = append(conditions, fmt.Sprintf("o.semester = $%d", argIndex))
+		args = append(args, req.Semester)
+		argIndex++
+	}
+	if req.OfferingID > 0 {
+		conditions = append(conditions, fmt.Sprintf("e.offering_id = $%d", argIndex))
+		args = append(args, req.OfferingID)
+		argIndex++
+	}
+	if req.ExamType != "" {
+		conditions = append(conditions, fmt.Sprintf("e.exam_type = $%d", argIndex))
+		args = append(args, req.ExamType)
+		argIndex++
+	}
+	if req.RoomID > 0 {
+		conditions = append(conditions, fmt.Sprintf("e.room_id = $%d", argIndex))
+		args = append(args, req.RoomID)
+		argIndex++
+	}
+
+	whereClause := ""
+	if len(conditions) > 0 {
+		whereClause = " WHERE " + strings.Join(conditions, " AND ")
+	}
+
+	var total int64
+	countQuery := `SELECT COUNT(*) FROM exams e JOIN course_offerings o ON e.offering_id = o.id` + whereClause
+	if err := r.db.QueryRow(countQuery, args...).Scan(&total); err != nil {
+		return nil, 0, fmt.Errorf("failed to count exams: %w", err)
+	}
+
+	query := fmt.Sprintf(`%s%s%s LIMIT $%d OFFSET $%d`, examSelect, whereClause, examOrder, argIndex, argIndex+1)
+	args = append(args, req.Size, (req.Page-1)*req.Size)
+
+	exams, err := r.queryExams(query, args...)
+	if err != nil {
+		return nil, 0, err
+	}
+	return exams, total, nil
+}
+
+// ListForOwner 获取教师、行政班或学生在学期内的考试安排。教师为开课的任课老师；行政班为课表中安排给该班的开课；
+// 学生为已选上的开课，以及学期内所在行政班的开课
+func (r *examRepository) ListForOwner(semester, ownerType string, ownerID int) ([]*domain.Exam, error) {
+	var condition string
+	switch ownerType {
+	case domain.CalendarOwnerTeacher:
+		condition = `o.teacher_id = $2`
+	case domain.CalendarOwnerClass:
+		condition = `EXISTS (SELECT 1 FROM timetable_slots ts WHERE ts.offering_id = e.offering_id AND ts.class_id = $2)`
+	case domain.CalendarOwnerStudent:
+		condition = `(EXISTS (
+			SELECT 1 FROM enrollments en WHERE en.offering_id = e.offering_id AND en.student_id = $2 AND en.status = '` + domain.EnrollmentStatusEnrolled + `'
+		) OR EXISTS (
+			SELECT 1 FROM timetable_slots ts
+			JOIN class_memberships cm ON cm.class_id = ts.class_id
+			JOIN terms tm ON tm.code = o.semester
+			WHERE ts.offering_id = e.offering_id AND cm.student_id = $2
+			  AND cm.joined_at <= tm.end_date AND (cm.left_at IS NULL OR cm.left_at > tm.start_date)
+		))`
+	default:
+		return nil, fmt.Errorf("unknown exam owner type: %s", ownerType)
+	}
+
+	return r.queryExams(examSelect+` WHERE o.semester = $1 AND `+condition+examOrder, semester, ownerID)
+}
+
+// FindRoomConflict 查找同一教室同一天时间重叠的其他考试，没有时返回 nil
+func (r *examRepository) FindRoomConflict(roomID int, date time.Time, startTime, endTime string, excludeID int) (*domain.Exam, error) {
+	exam, err := scanExam(r.db.QueryRow(examSelect+`
+		WHERE e.room_id = $1 AND e.exam_date = $2 AND e.start_time < $4::time AND e.end_time > $3::time AND e.id <> $5
+		LIMIT 1
+	`, roomID, date, startTime, endTime, excludeID))
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to check exam room conflict: %w", err)
+	}
+	return exam, nil
+}
+
+// queryExams 查询考试安排列表
+func (r *examRepository) queryExams(query string, args ...interface{}) ([]*domain.Exam, error) {
+	rows, err := r.db.Query(query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query exams: %w", err)
+	}
+	defer rows.Close()
+
+	var exams []*domain.Exam
+	for rows.Next() {
+		exam, err := scanExam(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan exam: %w", err)
+		}
+		exams = append(exams, exam)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to iterate exams: %w", err)
+	}
+	return exams, nil
+}
+
+// scanExam 扫描考试安排查询的一行
+func scanExam(row rowScanner) (*domain.Exam, error) {
+	exam := &domain.Exam{}
+	var roomID, createdBy sql.NullInt64
+	if err := row.Scan(&exam.ID, &exam.OfferingID, &exam.ExamType, &exam.ExamDate, &exam.StartTime, &exam.EndTime,
+		&roomID, &exam.Location, &exam.Note, &createdBy, &exam.CreatedAt, &exam.UpdatedAt,
+		&exam.Semester, &exam.SubjectID, &exam.SubjectCode, &exam.SubjectName, &exam.Section,
+		&exam.TeacherName, &exam.RoomCode, &exam.RoomName); err != nil {
+		return nil, err
+	}
+	if roomID.Valid {
+		id := int(roomID.Int64)
+		exam.RoomID = &id
+	}
+	if createdBy.Valid {
+		id := int(createdBy.Int64)
+		exam.CreatedBy = &id
+	}
+	return exam, nil
+}
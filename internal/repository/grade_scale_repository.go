@@ -0,0 +1,318 @@
+package repository
+
+import (
+	"database/sql"
+	"fmt"
+
+	"student-management-system/internal/domain"
+	"student-management-system/pkg/logger"
+)
+
+// GradeScaleRepository 绩点制仓储接口
+type GradeScaleRepository interface {
+	List() ([]*domain.GradeScale, error)
+	GetByName(name string) (*domain.GradeScale, error)
+	Create(scale *domain.GradeScale) error
+	Update(scale *domain.GradeScale) error
+	Delete(id int) error
+	ListMajorScales() ([]*domain.MajorGradeScale, error)
+	SetMajorScale(major, scaleName string) error
+	DeleteMajorScale(major string) (bool, error)
+	MajorsUsingScale(scaleName string) (int, error)
+	GetStudentScaleName(studentID int) (string, error)
+	GetMajorScaleName(major string) (string, error)
+}
+
+// gradeScaleRepository 绩点制仓储实现
+type gradeScaleRepository struct {
+	db *sql.DB
+}
+
+// NewGradeScaleRepository 创建绩点制仓储实例
+func NewGradeScaleRepository(db *sql.DB) GradeScaleRepository {
+	return &gradeScaleRepository{db: db}
+}
+
+// gradeScaleSelect 绩点制查询的字段
+const gradeScaleSelect = `
+		SELECT id, name, display_name, COALESCE(description, ''), formula, max_point, is_builtin, created_at, updated_at
+		FROM grade_scales`
+
+// List 获取全部绩点制及其分数段
+func (r *gradeScaleRepository) List() ([]*domain.GradeScale, error) {
+	rows, err := r.db.Query(gradeScaleSelect + ` ORDER BY is_builtin DESC, name`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query grade scales: %w", err)
+	}
+	defer rows.Close()
+
+	var scales []*domain.GradeScale
+	byID := make(map[int]*domain.GradeScale)
+	for rows.Next() {
+		scale, err := scanGradeScale(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan grade scale: %w", err)
+		}
+		scales = append(scales, scale)
+		byID[scale.ID] = scale
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to iterate grade scales: %w", err)
+	}
+
+	if err := r.loadBands(byID); err != nil {
+		return nil, err
+	}
+	return scales, nil
+}
+
+// GetByName 根据名称获取绩点制，不存在时返回 nil
+func (r *gradeScaleRepository) GetByName(name string) (*domain.GradeScale, error) {
+	scale, err := scanGradeScale(r.db.QueryRow(gradeScaleSelect+` WHERE name = $1`, name))
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get grade scale: %w", err)
+	}
+
+	if err := r.loadBands(map[int]*domain.GradeScale{scale.ID: scale}); err != nil {
+		return nil, err
+	}
+	return scale, nil
+}
+
+// loadBands 加载绩点制的分数段，按最低分从高到低排列
+func (r *gradeScaleRepository) loadBands(scales map[int]*domain.GradeScale) error {
+	if len(scales) == 0 {
+		return nil
+	}
+
+	rows, err := r.db.Query(`
+		SELECT scale_id, min_score, letter, grade_point, passed
+		FROM grade_scale_bands
+		ORDER BY scale_id, min_score DESC
+	`)
+	if err != nil {
+		return fmt.Errorf("failed to query grade scale bands: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var scaleID int
+		var band domain.GradeBand
+		var point sql.NullFloat64
+		if err := rows.Scan(&scaleID, &band.MinScore, &band.Letter, &point, &band.Passed); err != nil {
+			return fmt.Errorf("failed to scan grade scale band: %w", err)
+		}
+		scale, ok := scales[scaleID]
+		if !ok {
+			continue
+		}
+		if point.Valid {
+			band.GradePoint = &point.Float64
+		}
+		scale.Bands = append(scale.Bands, band)
+	}
+
+	if err = rows.Err(); err != nil {
+		return fmt.Errorf("failed to iterate grade scale bands: %w", err)
+	}
+	return nil
+}
+
+// Create 创建绩点制及其分数段
+func (r *gradeScaleRepository) Create(scale *domain.GradeScale) error {
+	logger.WithFields(map[string]interface{}{
+		"name": scale.Name,
+	}).Info("Creating grade scale")
+
+	tx, err := r.db.Begin()
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	err = tx.QueryRow(`
+		INSERT INTO grade_scales (name, display_name, description, formula, max_point)
+		VALUES ($1, $2, NULLIF($3, ''), $4, $5)
+		RETURNING id, created_at, updated_at
+	`, scale.Name, scale.DisplayName, scale.Description, scale.Formula, scale.MaxPoint).
+		Scan(&scale.ID, &scale.CreatedAt, &scale.UpdatedAt)
+	if err != nil {
+		logger.WithError(err).Error("Failed to create grade scale")
+		return fmt.Errorf("failed to create grade scale: %w", err)
+	}
+
+	if err := insertGradeBands(tx, scale); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// Update 更新绩点制并整体替换分数段
+func (r *gradeScaleRepository) Update(scale *domain.GradeScale) error {
+	logger.WithFields(map[string]interface{}{
+		"name": scale.Name,
+	}).Info("Updating grade scale")
+
+	tx, err := r.db.Begin()
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	err = tx.QueryRow(`
+		UPDATE grade_scales
+		SET display_name = $2, description = NULLIF($3, ''), formula = $4, max_point = $5
+		WHERE id = $1
+		RETURNING updated_at
+	`, scale.ID, scale.DisplayName, scale.Description, scale.Formula, scale.MaxPoint).Scan(&scale.UpdatedAt)
+	if err != nil {
+		logger.WithError(err).Error("Failed to update grade scale")
+		return fmt.Errorf("failed to update grade scale: %w", err)
+	}
+
+	if _, err := tx.Exec(`DELETE FROM grade_scale_bands WHERE scale_id = $1`, scale.ID); err != nil {
+		return fmt.Errorf("failed to clear grade scale bands: %w", err)
+	}
+	if err := insertGradeBands(tx, scale); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// insertGradeBands 写入绩点制的分数段
+func insertGradeBands(tx *sql.Tx, scale *domain.GradeScale) error {
+	for _, band := range scale.Bands {
+		_, err := tx.Exec(`
+			INSERT INTO grade_scale_bands (scale_id, min_score, letter, grade_point, passed)
+			VALUES ($1, $2, $3, $4, $5)
+		`, scale.ID, band.MinScore, band.Letter, band.GradePoint, band.Passed)
+		if err != nil {
+			return fmt.Errorf("failed to insert grade scale band: %w", err)
+		}
+	}
+	return nil
+}
+
+// Delete 删除绩点制
+func (r *gradeScaleRepository) Delete(id int) error {
+	logger.WithFields(map[string]interface{}{
+		"scale_id": id,
+	}).Info("Deleting grade scale")
+
+	if _, err := r.db.Exec(`DELETE FROM grade_scales WHERE id = $1`, id); err != nil {
+		logger.WithError(err).Error("Failed to delete grade scale")
+		return fmt.Errorf("failed to delete grade scale: %w", err)
+	}
+	return nil
+}
+
+// ListMajorScales 获取各专业使用的绩点制
+func (r *gradeScaleRepository) ListMajorScales() ([]*domain.MajorGradeScale, error) {
+	rows, err := r.db.Query(`SELECT major, scale_name, updated_at FROM major_grade_scales ORDER BY major`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query major grade scales: %w", err)
+	}
+	defer rows.Close()
+
+	var majors []*domain.MajorGradeScale
+	for rows.Next() {
+		m := &domain.MajorGradeScale{}
+		if err := rows.Scan(&m.Major, &m.ScaleName, &m.UpdatedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan major grade scale: %w", err)
+		}
+		majors = append(majors, m)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to iterate major grade scales: %w", err)
+	}
+	return majors, nil
+}
+
+// SetMajorScale 设置专业使用的绩点制
+func (r *gradeScaleRepository) SetMajorScale(major, scaleName string) error {
+	_, err := r.db.Exec(`
+		INSERT INTO major_grade_scales (major, scale_name) VALUES ($1, $2)
+		ON CONFLICT (major) DO UPDATE SET scale_name = EXCLUDED.scale_name, updated_at = CURRENT_TIMESTAMP
+	`, major, scaleName)
+	if err != nil {
+		return fmt.Errorf("failed to set major grade scale: %w", err)
+	}
+	return nil
+}
+
+// DeleteMajorScale 取消专业的绩点制设置，返回是否存在该设置
+func (r *gradeScaleRepository) DeleteMajorScale(major string) (bool, error) {
+	result, err := r.db.Exec(`DELETE FROM major_grade_scales WHERE major = $1`, major)
+	if err != nil {
+		return false, fmt.Errorf("failed to delete major grade scale: %w", err)
+	}
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return false, fmt.Errorf("failed to get affected rows: %w", err)
+	}
+	return affected > 0, nil
+}
+
+// MajorsUsingScale 统计使用某绩点制的专业数量
+func (r *gradeScaleRepository) MajorsUsingScale(scaleName string) (int, error) {
+	var count int
+	if err := r.db.QueryRow(`SELECT COUNT(*) FROM major_grade_scales WHERE scale_name = $1`, scaleName).Scan(&count); err != nil {
+		return 0, fmt.Errorf("failed to count majors using grade scale: %w", err)
+	}
+	return count, nil
+}
+
+// GetStudentScaleName 获取学生所在专业设置的绩点制，未设置时返回空字符串
+func (r *gradeScaleRepository) GetStudentScaleName(studentID int) (string, error) {
+	var name string
+	err := r.db.QueryRow(`
+		SELECT m.scale_name
+		FROM students st
+		JOIN major_grade_scales m ON m.major = st.major
+		WHERE st.id = $1
+	`, studentID).Scan(&name)
+	if err == sql.ErrNoRows {
+		return "", nil
+	}
+	if err != nil {
+		return "", fmt.Errorf("failed to get student grade scale: %w", err)
+	}
+	return name, nil
+}
+
+// scanGradeScale 扫描绩点制查询的一行
+func scanGradeScale(row rowScanner) (*domain.GradeScale, error) {
+	scale := &domain.GradeScale{Source: domain.GradeScaleSourceDatabase}
+	var createdAt, updatedAt sql.NullTime
+	if err := row.Scan(&scale.ID, &scale.Name, &scale.DisplayName, &scale.Description, &scale.Formula,
+		&scale.MaxPoint, &scale.IsBuiltin, &createdAt, &updatedAt); err != nil {
+		return nil, err
+	}
+	if createdAt.Valid {
+		scale.CreatedAt = &createdAt.Time
+	}
+	if updatedAt.Valid {
+		scale.UpdatedAt = &updatedAt.Time
+	}
+	return scale, nil
+}
+
+// GetMajorScaleName 获取专业设置的绩点制名称，未设置时返回空字符串
+func (r *gradeScaleRepository) GetMajorScaleName(major string) (string, error) {
+	var name string
+	err := r.db.QueryRow(`SELECT scale_name FROM major_grade_scales WHERE major = $1`, major).Scan(&name)
+	if err == sql.ErrNoRows {
+		return "", nil
+	}
+	if err != nil {
+		return "", fmt.Errorf("failed to get major grade scale: %w", err)
+	}
+	return name, nil
+}
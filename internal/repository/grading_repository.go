@@ -0,0 +1,337 @@
+package repository
+
+import (
+	"database/sql"
+	"fmt"
+
+	"student-management-system/internal/domain"
+	"student-management-system/pkg/logger"
+
+	"github.com/lib/pq"
+)
+
+// GradingRepository 评分方案与总评成绩仓储接口
+type GradingRepository interface {
+	GetSchemeBySubject(subjectID int) (*domain.GradingScheme, error)
+	GetSchemeByOffering(offeringID int) (*domain.GradingScheme, error)
+	SaveScheme(scheme *domain.GradingScheme) error
+	DeleteScheme(id int) error
+	GetComponentScores(key domain.FinalGradeKey) (map[string]float64, int, error)
+	SaveFinalGrade(grade *domain.FinalGrade) error
+	DeleteFinalGrade(key domain.FinalGradeKey) error
+	ListStudentFinalGrades(studentID int, semester string, publishedOnly bool) ([]*domain.FinalGrade, error)
+	ListOfferingFinalGrades(offeringID int) ([]*domain.FinalGrade, error)
+	ListOpenGradeKeys(subjectID, offeringID int) ([]domain.FinalGradeKey, error)
+}
+
+// gradingRepository 评分方案与总评成绩仓储实现
+type gradingRepository struct {
+	db *sql.DB
+}
+
+// NewGradingRepository 创建评分仓储实例
+func NewGradingRepository(db *sql.DB) GradingRepository {
+	return &gradingRepository{db: db}
+}
+
+// schemeSelect 评分方案查询的字段
+const schemeSelect = `
+		SELECT id, subject_id, offering_id, midterm_weight, final_weight, quiz_weight, assignment_weight,
+		       missing_policy, created_at, updated_at
+		FROM grading_schemes`
+
+// finalGradeSelect 总评成绩查询的字段，未关联评分方案时按默认方案（期末100%）展示权重
+const finalGradeSelect = `
+		SELECT fg.id, fg.student_id, fg.subject_id, fg.semester, COALESCE(fg.offering_id, 0), fg.scheme_id,
+		       fg.midterm_score, fg.final_score, fg.quiz_score, fg.assignment_score,
+		       fg.composite_score, fg.weight_covered, fg.is_complete, fg.computed_at,
+		       COALESCE(gs.midterm_weight, 0), COALESCE(gs.final_weight, 100),
+		       COALESCE(gs.quiz_weight, 0), COALESCE(gs.assignment_weight, 0),
+		       COALESCE(gs.missing_policy, 'renormalize'),
+		       st.name, st.student_id, sub.name, sub.code, sub.credits
+		FROM final_grades fg
+		JOIN students st ON fg.student_id = st.id
+		JOIN subjects sub ON fg.subject_id = sub.id
+		LEFT JOIN grading_schemes gs ON fg.scheme_id = gs.id`
+
+// GetSchemeBySubject 获取科目的评分方案，未设置时返回 nil
+func (r *gradingRepository) GetSchemeBySubject(subjectID int) (*domain.GradingScheme, error) {
+	return r.getScheme(schemeSelect+` WHERE subject_id = $1`, subjectID)
+}
+
+// GetSchemeByOffering 获取开课的评分方案，未设置时返回 nil
+func (r *gradingRepository) GetSchemeByOffering(offeringID int) (*domain.GradingScheme, error) {
+	return r.getScheme(schemeSelect+` WHERE offering_id = $1`, offeringID)
+}
+
+// getScheme 查询单个评分方案
+func (r *gradingRepository) getScheme(query string, args ...interface{}) (*domain.GradingScheme, error) {
+	scheme := &domain.GradingScheme{}
+	var subjectID, offeringID sql.NullInt64
+	err := r.db.QueryRow(query, args...).Scan(&scheme.ID, &subjectID, &offeringID,
+		&scheme.MidtermWeight, &scheme.FinalWeight, &scheme.QuizWeight, &scheme.AssignmentWeight,
+		&scheme.MissingPolicy, &scheme.CreatedAt, &scheme.UpdatedAt)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get grading scheme: %w", err)
+	}
+
+	if subjectID.Valid {
+		id := int(subjectID.Int64)
+		scheme.SubjectID = &id
+		scheme.Source = domain.GradingSchemeSourceSubject
+	}
+	if offeringID.Valid {
+		id := int(offeringID.Int64)
+		scheme.OfferingID = &id
+		scheme.Source = domain.GradingSchemeSourceOffering
+	}
+	return scheme, nil
+}
+
+// SaveScheme 保存评分方案，科目或开课已有方案时覆盖
+func (r *gradingRepository) SaveScheme(scheme *domain.GradingScheme) error {
+	logger.WithFields(map[string]interface{}{
+		"subject_id":  scheme.SubjectID,
+		"offering_id": scheme.OfferingID,
+	}).Info("Saving grading scheme")
+
+	conflict := "subject_id"
+	if scheme.OfferingID != nil {
+		conflict = "offering_id"
+	}
+
+	query := fmt.Sprintf(`
+		INSERT INTO grading_schemes (subject_id, offering_id, midterm_weight, final_weight, quiz_weight,
+		                             assignment_weight, missing_policy)
+		VALUES ($1, $2, $3, $4, $5, $6, $7)
+		ON CONFLICT (%s) DO UPDATE
+		SET midterm_weight = EXCLUDED.midterm_weight, final_weight = EXCLUDED.final_weight,
+		    quiz_weight = EXCLUDED.quiz_weight, assignment_weight = EXCLUDED.assignment_weight,
+		    missing_policy = EXCLUDED.missing_policy
+		RETURNING id, created_at, updated_at
+	`, conflict)
+
+	err := r.db.QueryRow(query, scheme.SubjectID, scheme.OfferingID, scheme.MidtermWeight, scheme.FinalWeight,
+		scheme.QuizWeight, scheme.AssignmentWeight, scheme.MissingPolicy).
+		Scan(&scheme.ID, &scheme.CreatedAt, &scheme.UpdatedAt)
+	if err != nil {
+		logger.WithError(err).Error("Failed to save grading scheme")
+		return fmt.Errorf("failed to save grading scheme: %w", err)
+	}
+
+	return nil
+}
+
+// DeleteScheme 删除评分方案
+func (r *gradingRepository) DeleteScheme(id int) error {
+	logger.WithFields(map[string]interface{}{
+		"scheme_id": id,
+	}).Info("Deleting grading scheme")
+
+	if _, err := r.db.Exec(`DELETE FROM grading_schemes WHERE id = $1`, id); err != nil {
+		logger.WithError(err).Error("Failed to delete grading scheme")
+		return fmt.Errorf("failed to delete grading scheme: %w", err)
+	}
+
+	return nil
+}
+
+// GetComponentScores 获取学生某学期某科目各考试类型的成绩及所属开课
+func (r *gradingRepository) GetComponentScores(key domain.FinalGradeKey) (map[string]float64, int, error) {
+	rows, err := r.db.Query(`
+		SELECT exam_type, score, COALESCE(offering_id, 0)
+		FROM scores
+		WHERE student_id = $1 AND subject_id = $2 AND semester = $3
+	`, key.StudentID, key.SubjectID, key.Semester)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to query component scores: %w", err)
+	}
+	defer rows.Close()
+
+	scores := make(map[string]float64)
+	offeringID := 0
+	for rows.Next() {
+		var examType string
+		var score float64
+		var id int
+		if err := rows.Scan(&examType, &score, &id); err != nil {
+			return nil, 0, fmt.Errorf("failed to scan component score: %w", err)
+		}
+		scores[examType] = score
+		if id != 0 {
+			offeringID = id
+		}
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, 0, fmt.Errorf("failed to iterate component scores: %w", err)
+	}
+
+	return scores, offeringID, nil
+}
+
+// SaveFinalGrade 保存总评成绩，已存在时覆盖
+func (r *gradingRepository) SaveFinalGrade(grade *domain.FinalGrade) error {
+	components := make(map[string]*float64)
+	for _, c := range grade.Components {
+		components[c.ExamType] = c.Score
+	}
+
+	query := `
+		INSERT INTO final_grades (student_id, subject_id, semester, offering_id, scheme_id,
+		                          midterm_score, final_score, quiz_score, assignment_score,
+		                          composite_score, weight_covered, is_complete, computed_at)
+		VALUES ($1, $2, $3, NULLIF($4, 0), $5, $6, $7, $8, $9, $10, $11, $12, CURRENT_TIMESTAMP)
+		ON CONFLICT (student_id, subject_id, semester) DO UPDATE
+		SET offering_id = EXCLUDED.offering_id, scheme_id = EXCLUDED.scheme_id,
+		    midterm_score = EXCLUDED.midterm_score, final_score = EXCLUDED.final_score,
+		    quiz_score = EXCLUDED.quiz_score, assignment_score = EXCLUDED.assignment_score,
+		    composite_score = EXCLUDED.composite_score, weight_covered = EXCLUDED.weight_covered,
+		    is_complete = EXCLUDED.is_complete, computed_at = EXCLUDED.computed_at
+		RETURNING id, computed_at
+	`
+
+	err := r.db.QueryRow(query, grade.StudentID, grade.SubjectID, grade.Semester, grade.OfferingID, grade.SchemeID,
+		components[domain.ExamTypeMidterm], components[domain.ExamTypeFinal],
+		components[domain.ExamTypeQuiz], components[domain.ExamTypeAssignment],
+		grade.CompositeScore, grade.WeightCovered, grade.IsComplete).
+		Scan(&grade.ID, &grade.ComputedAt)
+	if err != nil {
+		logger.WithError(err).Error("Failed to save final grade")
+		return fmt.Errorf("failed to save final grade: %w", err)
+	}
+
+	return nil
+}
+
+// DeleteFinalGrade 删除总评成绩（各考试类型成绩均已删除时）
+func (r *gradingRepository) DeleteFinalGrade(key domain.FinalGradeKey) error {
+	_, err := r.db.Exec(`DELETE FROM final_grades WHERE student_id = $1 AND subject_id = $2 AND semester = $3`,
+		key.StudentID, key.SubjectID, key.Semester)
+	if err != nil {
+		return fmt.Errorf("failed to delete final grade: %w", err)
+	}
+	return nil
+}
+
+// ListStudentFinalGrades 获取学生的总评成绩，semester 为空时返回所有学期。
+// publishedOnly 为 true 时只返回各项成绩均已发布的总评成绩
+func (r *gradingRepository) ListStudentFinalGrades(studentID int, semester string, publishedOnly bool) ([]*domain.FinalGrade, error) {
+	return r.listFinalGrades(finalGradeSelect+`
+		WHERE fg.student_id = $1 AND ($2 = '' OR fg.semester = $2)
+		  AND (NOT $3 OR NOT EXISTS (
+		      SELECT 1 FROM scores s
+		      WHERE s.student_id = fg.student_id AND s.subject_id = fg.subject_id AND s.semester = fg.semester
+		        AND s.status <> ALL($4)
+		  ))
+		ORDER BY fg.semester, sub.code
+	`, studentID, semester, publishedOnly, pq.Array(domain.PublishedScoreStatuses))
+}
+
+// ListOfferingFinalGrades 获取开课的总评成绩，按学号排序
+func (r *gradingRepository) ListOfferingFinalGrades(offeringID int) ([]*domain.FinalGrade, error) {
+	return r.listFinalGrades(finalGradeSelect+`
+		WHERE fg.offering_id = $1
+		ORDER BY st.student_id
+	`, offeringID)
+}
+
+// listFinalGrades 查询总评成绩列表
+func (r *gradingRepository) listFinalGrades(query string, args ...interface{}) ([]*domain.FinalGrade, error) {
+	rows, err := r.db.Query(query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query final grades: %w", err)
+	}
+	defer rows.Close()
+
+	var grades []*domain.FinalGrade
+	for rows.Next() {
+		grade, err := scanFinalGrade(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan final grade: %w", err)
+		}
+		grades = append(grades, grade)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to iterate final grades: %w", err)
+	}
+
+	return grades, nil
+}
+
+// ListOpenGradeKeys 列出未结束学期中某科目或某开课的成绩所对应的总评成绩，评分方案变更后据此重新计算。
+// subjectID 或 offeringID 为0时不作为条件。
+func (r *gradingRepository) ListOpenGradeKeys(subjectID, offeringID int) ([]domain.FinalGradeKey, error) {
+	rows, err := r.db.Query(`
+		SELECT DISTINCT s.student_id, s.subject_id, s.semester
+		FROM scores s
+		JOIN terms t ON s.semester = t.code
+		WHERE t.status <> 'closed'
+		  AND ($1 = 0 OR s.subject_id = $1)
+		  AND ($2 = 0 OR s.offering_id = $2)
+	`, subjectID, offeringID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query grade keys: %w", err)
+	}
+	defer rows.Close()
+
+	var keys []domain.FinalGradeKey
+	for rows.Next() {
+		var key domain.FinalGradeKey
+		if err := rows.Scan(&key.StudentID, &key.SubjectID, &key.Semester); err != nil {
+			return nil, fmt.Errorf("failed to scan grade key: %w", err)
+		}
+		keys = append(keys, key)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to iterate grade keys: %w", err)
+	}
+
+	return keys, nil
+}
+
+// scanFinalGrade 扫描总评成绩查询的一行，并按计算时的方案还原各组成部分
+func scanFinalGrade(row rowScanner) (*domain.FinalGrade, error) {
+	grade := &domain.FinalGrade{}
+	scheme := &domain.GradingScheme{}
+	var schemeID sql.NullInt64
+	var midterm, final, quiz, assignment, composite sql.NullFloat64
+
+	err := row.Scan(&grade.ID, &grade.StudentID, &grade.SubjectID, &grade.Semester, &grade.OfferingID, &schemeID,
+		&midterm, &final, &quiz, &assignment,
+		&composite, &grade.WeightCovered, &grade.IsComplete, &grade.ComputedAt,
+		&scheme.MidtermWeight, &scheme.FinalWeight, &scheme.QuizWeight, &scheme.AssignmentWeight,
+		&scheme.MissingPolicy,
+		&grade.StudentName, &grade.StudentCode, &grade.SubjectName, &grade.SubjectCode, &grade.Credits)
+	if err != nil {
+		return nil, err
+	}
+
+	if schemeID.Valid {
+		id := int(schemeID.Int64)
+		grade.SchemeID = &id
+	}
+	if composite.Valid {
+		grade.CompositeScore = &composite.Float64
+	}
+
+	scores := make(map[string]float64)
+	for examType, value := range map[string]sql.NullFloat64{
+		domain.ExamTypeMidterm:    midterm,
+		domain.ExamTypeFinal:      final,
+		domain.ExamTypeQuiz:       quiz,
+		domain.ExamTypeAssignment: assignment,
+	} {
+		if value.Valid {
+			scores[examType] = value.Float64
+		}
+	}
+	grade.Components = scheme.Components(scores)
+
+	return grade, nil
+}
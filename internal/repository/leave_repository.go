@@ -0,0 +1,367 @@
+package repository
+
+import (
+	"database/sql"
+	"fmt"
+	"strings"
+	"time"
+
+	"student-management-system/internal/domain"
+	"student-management-system/pkg/logger"
+
+	"github.com/lib/pq"
+)
+
+// LeaveRepository 请假申请仓储接口
+type LeaveRepository interface {
+	Create(leave *domain.LeaveRequest) error
+	GetByID(id int) (*domain.LeaveRequest, error)
+	List(req *domain.LeaveListRequest) ([]*domain.LeaveRequest, int64, error)
+	ListApprovals(leaveID int) ([]*domain.LeaveApproval, error)
+	HasOverlap(studentID int, startDate, endDate time.Time) (bool, error)
+	ListApprovedOn(studentIDs []int, date time.Time) ([]*domain.LeaveRequest, error)
+	Decide(leave *domain.LeaveRequest, approval *domain.LeaveApproval) (bool, error)
+	Cancel(id int, statuses []string) (bool, error)
+}
+
+// leaveRepository 请假申请仓储实现
+type leaveRepository struct {
+	db *sql.DB
+}
+
+// NewLeaveRepository 创建请假申请仓储实例
+func NewLeaveRepository(db *sql.DB) LeaveRepository {
+	return &leaveRepository{db: db}
+}
+
+// Create 创建请假申请
+func (r *leaveRepository) Create(leave *domain.LeaveRequest) error {
+	logger.WithFields(map[string]interface{}{
+		"student_id": leave.StudentID,
+		"start_date": leave.StartDate.Format("2006-01-02"),
+		"end_date":   leave.EndDate.Format("2006-01-02"),
+	}).Info("Creating leave request")
+
+	if leave.Attachments == nil {
+		leave.Attachments = []string{}
+	}
+
+	err := r.db.QueryRow(`
+		INSERT INTO leave_requests (student_id, leave_type, start_date, end_date, days, reason, attachments,
+		                            status, approval_chain, current_step, submitted_by)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11)
+		RETURNING id, created_at, updated_at
+	`, leave.StudentID, leave.LeaveType, leave.StartDate, leave.EndDate, leave.Days, leave.Reason,
+		pq.Array(leave.Attachments), leave.Status, pq.Array(leave.ApprovalChain), leave.CurrentStep, leave.SubmittedBy).
+		Scan(&leave.ID, &leave.CreatedAt, &leave.UpdatedAt)
+	if err != nil {
+		return fmt.Errorf("failed to create leave request: %w", err)
+	}
+	return nil
+}
+
+// leaveSelect 请假申请查询的字段，附带学生当前所在班级及班主任
+const leaveSelect = `
+		SELECT l.id, l.student_id, l.leave_type, l.start_date, l.end_date, l.days, l.reason, l.attachments,
+		       l.status, l.approval_chain, l.current_step, l.submitted_by, l.excused_sessions,
+		       l.created_at, l.updated_at, l.decided_at,
+		       COALESCE(s.student_id, ''), COALESCE(s.name, ''), s.class_id, COALESCE(c.name, ''), c.homeroom_teacher_id
+		FROM leave_requests l
+		JOIN students s ON l.student_id = s.id
+		LEFT JOIN classes c ON s.class_id = c.id`
+
+// GetByID 根据ID获取请假申请，不存在时返回 nil
+func (r *leaveRepository) GetByID(id int) (*domain.LeaveRequest, error) {
+	leave, err := scanLeave(r.db.QueryRow(leaveSelect+` WHERE l.id = $1`, id))
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get leave request: %w", err)
+	}
+	return leave, nil
+}
+
+// List 获取请假申请列表（分页）。待审批队列按提交时间先后排列，否则按请假开始日期倒序
+func (r *leaveRepository) List(req *domain.LeaveListRequest) ([]*domain.LeaveRequest, int64, error) {
+	var conditions []string
+	var args []interface{}
+	argIndex := 1
+
+	if req.Status != "" {
+		conditions = append(conditions, fmt.Sprintf("l.status = $%d", argIndex))
+		args = append(args, req.Status)
+		argIndex++
+	}
+	if req.LeaveType != "" {
+		conditions = append(conditions, fmt.Sprintf("l.leave_type = $%d", argIndex))
+		args = append(args, req.LeaveType)
+		argIndex++
+	}
+	if req.StudentID > 0 {
+		conditions = append(conditions, fmt.Sprintf("l.student_id = $%d", argIndex))
+		args = append(args, req.StudentID)
+		argIndex++
+	}
+	if len(req.StudentIDs) > 0 {
+		conditions = append(conditions, fmt.Sprintf("l.student_id = ANY($%d)", argIndex))
+		args = append(args, pq.Array(req.StudentIDs))
+		argIndex++
+	}
+	if req.ClassID > 0 {
+		conditions = append(conditions, fmt.Sprintf("s.class_id = $%d", argIndex))
+		args = append(args, req.ClassID)
+		argIndex++
+	}
+	if req.DateFrom != nil {
+		conditions = append(conditions, fmt.Sprintf("l.end_date >= $%d", argIndex))
+		args = append(args, *req.DateFrom)
+		argIndex++
+	}
+	if req.DateTo != nil {
+		conditions = append(conditions, fmt.Sprintf("l.start_date <= $%d", argIndex))
+		args = append(args, *req.DateTo)
+		argIndex++
+	}
+	if req.HomeroomTeacherID > 0 {
+		conditions = append(conditions, fmt.Sprintf("c.homeroom_teacher_id = $%d", argIndex))
+		args = append(args, req.HomeroomTeacherID)
+		argIndex++
+	}
+	if req.PendingApprover != "" {
+		// 数组下标从1开始
+		conditions = append(conditions, fmt.Sprintf("l.status = $%d AND l.approval_chain[l.current_step + 1] = $%d", argIndex, argIndex+1))
+		args = append(args, domain.LeaveStatusPending, req.PendingApprover)
+		argIndex += 2
+	}
+	orderBy := "l.start_date DESC, l.id DESC"
+	if req.Queue {
+		orderBy = "l.created_at, l.id"
+	}
+
+	whereClause := ""
+	if len(conditions) > 0 {
+		whereClause = " WHERE " + strings.Join(conditions, " AND ")
+	}
+
+	var total int64
+	countQuery := `SELECT COUNT(*) FROM leave_requests l JOIN students s ON l.student_id = s.id
+		LEFT JOIN classes c ON s.class_id = c.id` + whereClause
+	if err := r.db.QueryRow(countQuery, args...).Scan(&total); err != nil {
+		return nil, 0, fmt.Errorf("failed to count leave requests: %w", err)
+	}
+
+	query := fmt.Sprintf(`%s%s ORDER BY %s LIMIT $%d OFFSET $%d`, leaveSelect, whereClause, orderBy, argIndex, argIndex+1)
+	args = append(args, req.Size, (req.Page-1)*req.Size)
+
+	rows, err := r.db.Query(query, args...)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to query leave requests: %w", err)
+	}
+	defer rows.Close()
+
+	leaves, err := scanLeaves(rows)
+	if err != nil {
+		return nil, 0, err
+	}
+	return leaves, total, nil
+}
+
+// ListApprovals 获取请假申请的审批记录，按审批步骤排列
+func (r *leaveRepository) ListApprovals(leaveID int) ([]*domain.LeaveApproval, error) {
+	rows, err := r.db.Query(`
+		SELECT la.id, la.leave_id, la.step, la.approver, la.decision, COALESCE(la.comment, ''), la.actor_id,
+		       COALESCE(a.name, ''), la.created_at
+		FROM leave_approvals la
+		LEFT JOIN admins a ON la.actor_id = a.id
+		WHERE la.leave_id = $1
+		ORDER BY la.step
+	`, leaveID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query leave approvals: %w", err)
+	}
+	defer rows.Close()
+
+	approvals := []*domain.LeaveApproval{}
+	for rows.Next() {
+		approval := &domain.LeaveApproval{}
+		var actorID sql.NullInt64
+		if err := rows.Scan(&approval.ID, &approval.LeaveID, &approval.Step, &approval.Approver, &approval.Decision,
+			&approval.Comment, &actorID, &approval.ActorName, &approval.CreatedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan leave approval: %w", err)
+		}
+		if actorID.Valid {
+			id := int(actorID.Int64)
+			approval.ActorID = &id
+		}
+		approvals = append(approvals, approval)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to iterate leave approvals: %w", err)
+	}
+	return approvals, nil
+}
+
+// HasOverlap 学生在日期范围内是否已有审批中或已批准的请假
+func (r *leaveRepository) HasOverlap(studentID int, startDate, endDate time.Time) (bool, error) {
+	var exists bool
+	err := r.db.QueryRow(`
+		SELECT EXISTS (
+			SELECT 1 FROM leave_requests
+			WHERE student_id = $1 AND status = ANY($2) AND start_date <= $4 AND end_date >= $3
+		)
+	`, studentID, pq.Array([]string{domain.LeaveStatusPending, domain.LeaveStatusApproved}), startDate, endDate).Scan(&exists)
+	if err != nil {
+		return false, fmt.Errorf("failed to check overlapping leave requests: %w", err)
+	}
+	return exists, nil
+}
+
+// ListApprovedOn 获取学生在某天已批准的请假
+func (r *leaveRepository) ListApprovedOn(studentIDs []int, date time.Time) ([]*domain.LeaveRequest, error) {
+	rows, err := r.db.Query(leaveSelect+`
+		WHERE l.status = $1 AND l.student_id = ANY($2) AND $3 BETWEEN l.start_date AND l.end_date
+		ORDER BY l.student_id, l.id
+	`, domain.LeaveStatusApproved, pq.Array(studentIDs), date)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query approved leave requests: %w", err)
+	}
+	defer rows.Close()
+
+	return scanLeaves(rows)
+}
+
+// Decide 保存一步审批意见并更新申请状态，申请已被处理（状态或当前步骤已变化）时返回 false。
+// 申请最终批准时，请假期间已记录为旷课的考勤同时改为请假（已结束学期的考勤不变），改动的课次数写入 excused_sessions
+func (r *leaveRepository) Decide(leave *domain.LeaveRequest, approval *domain.LeaveApproval) (bool, error) {
+	logger.WithFields(map[string]interface{}{
+		"leave_id": leave.ID,
+		"step":     approval.Step,
+		"decision": approval.Decision,
+	}).Info("Deciding leave request")
+
+	tx, err := r.db.Begin()
+	if err != nil {
+		return false, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	leave.ExcusedSessions = 0
+	if leave.Status == domain.LeaveStatusApproved {
+		result, err := tx.Exec(`
+			UPDATE attendance_records ar
+			SET status = $2, note = CASE WHEN COALESCE(ar.note, '') = '' THEN $3 ELSE ar.note END
+			FROM attendance_sessions sess
+			JOIN course_offerings o ON sess.offering_id = o.id
+			WHERE ar.session_id = sess.id AND ar.student_id = $1 AND ar.status = $4
+			  AND sess.session_date BETWEEN $5 AND $6
+			  AND NOT EXISTS (SELECT 1 FROM terms t WHERE t.code = o.semester AND t.status = $7)
+		`, leave.StudentID, leave.AttendanceStatus(), fmt.Sprintf("请假 #%d", leave.ID), domain.AttendanceAbsent,
+			leave.StartDate, leave.EndDate, domain.TermStatusClosed)
+		if err != nil {
+			return false, fmt.Errorf("failed to excuse attendance records: %w", err)
+		}
+		excused, err := result.RowsAffected()
+		if err != nil {
+			return false, fmt.Errorf("failed to get rows affected: %w", err)
+		}
+		leave.ExcusedSessions = int(excused)
+	}
+
+	err = tx.QueryRow(`
+		UPDATE leave_requests
+		SET status = $2, current_step = $3, excused_sessions = $4,
+		    decided_at = CASE WHEN $2 = $5 THEN NULL ELSE CURRENT_TIMESTAMP END
+		WHERE id = $1 AND status = $5 AND current_step = $6
+		RETURNING updated_at, decided_at
+	`, leave.ID, leave.Status, leave.CurrentStep, leave.ExcusedSessions, domain.LeaveStatusPending, approval.Step).
+		Scan(&leave.UpdatedAt, &leave.DecidedAt)
+	if err == sql.ErrNoRows {
+		return false, nil
+	}
+	if err != nil {
+		return false, fmt.Errorf("failed to update leave request: %w", err)
+	}
+
+	err = tx.QueryRow(`
+		INSERT INTO leave_approvals (leave_id, step, approver, decision, comment, actor_id)
+		VALUES ($1, $2, $3, $4, $5, $6)
+		RETURNING id, created_at
+	`, leave.ID, approval.Step, approval.Approver, approval.Decision, approval.Comment, approval.ActorID).
+		Scan(&approval.ID, &approval.CreatedAt)
+	if err != nil {
+		return false, fmt.Errorf("failed to create leave approval: %w", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return false, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+	return true, nil
+}
+
+// Cancel 撤销请假申请，申请不处于 statuses 中的状态时返回 false
+func (r *leaveRepository) Cancel(id int, statuses []string) (bool, error) {
+	result, err := r.db.Exec(`
+		UPDATE leave_requests SET status = $2, decided_at = CURRENT_TIMESTAMP
+		WHERE id = $1 AND status = ANY($3)
+	`, id, domain.LeaveStatusCancelled, pq.Array(statuses))
+	if err != nil {
+		return false, fmt.Errorf("failed to cancel leave request: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return false, fmt.Errorf("failed to get rows affected: %w", err)
+	}
+	return rowsAffected > 0, nil
+}
+
+// scanLeaves 扫描请假申请查询结果
+func scanLeaves(rows *sql.Rows) ([]*domain.LeaveRequest, error) {
+	var leaves []*domain.LeaveRequest
+	for rows.Next() {
+		leave, err := scanLeave(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan leave request: %w", err)
+		}
+		leaves = append(leaves, leave)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to iterate leave requests: %w", err)
+	}
+	return leaves, nil
+}
+
+// scanLeave 扫描请假申请查询的一行
+func scanLeave(row rowScanner) (*domain.LeaveRequest, error) {
+	leave := &domain.LeaveRequest{}
+	var submittedBy, classID, homeroomTeacherID sql.NullInt64
+	var decidedAt sql.NullTime
+	if err := row.Scan(&leave.ID, &leave.StudentID, &leave.LeaveType, &leave.StartDate, &leave.EndDate, &leave.Days,
+		&leave.Reason, pq.Array(&leave.Attachments), &leave.Status, pq.Array(&leave.ApprovalChain), &leave.CurrentStep,
+		&submittedBy, &leave.ExcusedSessions, &leave.CreatedAt, &leave.UpdatedAt, &decidedAt,
+		&leave.StudentNo, &leave.StudentName, &classID, &leave.ClassName, &homeroomTeacherID); err != nil {
+		return nil, err
+	}
+	if submittedBy.Valid {
+		id := int(submittedBy.Int64)
+		leave.SubmittedBy = &id
+	}
+	if classID.Valid {
+		id := int(classID.Int64)
+		leave.ClassID = &id
+	}
+	if homeroomTeacherID.Valid {
+		id := int(homeroomTeacherID.Int64)
+		leave.HomeroomTeacherID = &id
+	}
+	if decidedAt.Valid {
+		leave.DecidedAt = &decidedAt.Time
+	}
+	if leave.Attachments == nil {
+		leave.Attachments = []string{}
+	}
+	return leave, nil
+}
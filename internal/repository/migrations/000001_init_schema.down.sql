@@ -0,0 +1,6 @@
+DROP TABLE IF EXISTS scores;
+DROP TABLE IF EXISTS admins;
+DROP TABLE IF EXISTS teachers;
+DROP TABLE IF EXISTS students;
+DROP TABLE IF EXISTS subjects;
+DROP FUNCTION IF EXISTS update_updated_at_column();
@@ -0,0 +1,112 @@
+-- 初始表结构（与原 CreateTables 一致，使用 IF NOT EXISTS 以兼容已有数据库）
+
+-- 科目表（须先于教师表和成绩表创建）
+CREATE TABLE IF NOT EXISTS subjects (
+	id SERIAL PRIMARY KEY,
+	name VARCHAR(50) NOT NULL,
+	code VARCHAR(20) NOT NULL UNIQUE,
+	description TEXT,
+	credits INTEGER NOT NULL DEFAULT 1,
+	status VARCHAR(20) NOT NULL DEFAULT 'active',
+	created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+	updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+-- 学生表
+CREATE TABLE IF NOT EXISTS students (
+	id SERIAL PRIMARY KEY,
+	student_id VARCHAR(20) UNIQUE,
+	name VARCHAR(100) NOT NULL,
+	age INTEGER,
+	gender VARCHAR(10),
+	phone VARCHAR(20),
+	email VARCHAR(100),
+	address TEXT,
+	major VARCHAR(100),
+	enrollment_date DATE,
+	graduation_date DATE,
+	status VARCHAR(20) DEFAULT 'active',
+	created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+	updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+-- 教师表
+CREATE TABLE IF NOT EXISTS teachers (
+	id SERIAL PRIMARY KEY,
+	name VARCHAR(100) NOT NULL,
+	age INTEGER,
+	gender VARCHAR(10),
+	email VARCHAR(100),
+	phone VARCHAR(20),
+	subject_id INTEGER REFERENCES subjects(id) ON DELETE SET NULL,
+	subject VARCHAR(50) NOT NULL,
+	title VARCHAR(50),
+	department VARCHAR(100),
+	created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+	updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+-- 管理员表
+CREATE TABLE IF NOT EXISTS admins (
+	id SERIAL PRIMARY KEY,
+	account VARCHAR(50) UNIQUE NOT NULL,
+	password VARCHAR(255) NOT NULL,
+	name VARCHAR(50) NOT NULL,
+	phone VARCHAR(11),
+	email VARCHAR(100),
+	created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+	updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+-- 成绩表
+CREATE TABLE IF NOT EXISTS scores (
+	id SERIAL PRIMARY KEY,
+	student_id INTEGER NOT NULL REFERENCES students(id) ON DELETE CASCADE,
+	subject_id INTEGER NOT NULL REFERENCES subjects(id) ON DELETE CASCADE,
+	score DECIMAL(5,2) NOT NULL CHECK (score >= 0 AND score <= 100),
+	semester VARCHAR(20) NOT NULL,
+	exam_type VARCHAR(20) NOT NULL,
+	remarks TEXT,
+	created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+	updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+	UNIQUE(student_id, subject_id, semester, exam_type)
+);
+
+-- 更新时间触发器函数
+CREATE OR REPLACE FUNCTION update_updated_at_column()
+RETURNS TRIGGER AS $$
+BEGIN
+	NEW.updated_at = CURRENT_TIMESTAMP;
+	RETURN NEW;
+END;
+$$ language 'plpgsql';
+
+DROP TRIGGER IF EXISTS update_subjects_updated_at ON subjects;
+CREATE TRIGGER update_subjects_updated_at
+	BEFORE UPDATE ON subjects
+	FOR EACH ROW
+	EXECUTE FUNCTION update_updated_at_column();
+
+DROP TRIGGER IF EXISTS update_students_updated_at ON students;
+CREATE TRIGGER update_students_updated_at
+	BEFORE UPDATE ON students
+	FOR EACH ROW
+	EXECUTE FUNCTION update_updated_at_column();
+
+DROP TRIGGER IF EXISTS update_teachers_updated_at ON teachers;
+CREATE TRIGGER update_teachers_updated_at
+	BEFORE UPDATE ON teachers
+	FOR EACH ROW
+	EXECUTE FUNCTION update_updated_at_column();
+
+DROP TRIGGER IF EXISTS update_admins_updated_at ON admins;
+CREATE TRIGGER update_admins_updated_at
+	BEFORE UPDATE ON admins
+	FOR EACH ROW
+	EXECUTE FUNCTION update_updated_at_column();
+
+DROP TRIGGER IF EXISTS update_scores_updated_at ON scores;
+CREATE TRIGGER update_scores_updated_at
+	BEFORE UPDATE ON scores
+	FOR EACH ROW
+	EXECUTE FUNCTION update_updated_at_column();
@@ -0,0 +1,2 @@
+UPDATE teachers SET subject = '' WHERE subject IS NULL;
+ALTER TABLE teachers ALTER COLUMN subject SET NOT NULL;
@@ -0,0 +1,2 @@
+-- 教师的任教科目已由 subject_id 外键表示，旧的 subject 文本列不再必填
+ALTER TABLE teachers ALTER COLUMN subject DROP NOT NULL;
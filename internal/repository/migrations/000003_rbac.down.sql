@@ -0,0 +1,8 @@
+ALTER TABLE scores DROP COLUMN IF EXISTS teacher_id;
+DROP TABLE IF EXISTS parent_students;
+ALTER TABLE admins DROP COLUMN IF EXISTS student_id;
+ALTER TABLE admins DROP COLUMN IF EXISTS teacher_id;
+ALTER TABLE admins DROP COLUMN IF EXISTS role;
+DROP TABLE IF EXISTS role_permissions;
+DROP TABLE IF EXISTS permissions;
+DROP TABLE IF EXISTS roles;
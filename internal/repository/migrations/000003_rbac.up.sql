@@ -0,0 +1,79 @@
+-- 角色与权限
+CREATE TABLE IF NOT EXISTS roles (
+	id SERIAL PRIMARY KEY,
+	name VARCHAR(30) NOT NULL UNIQUE,
+	description VARCHAR(100),
+	created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE TABLE IF NOT EXISTS permissions (
+	id SERIAL PRIMARY KEY,
+	code VARCHAR(50) NOT NULL UNIQUE,
+	description VARCHAR(100),
+	created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE TABLE IF NOT EXISTS role_permissions (
+	role_id INTEGER NOT NULL REFERENCES roles(id) ON DELETE CASCADE,
+	permission_id INTEGER NOT NULL REFERENCES permissions(id) ON DELETE CASCADE,
+	PRIMARY KEY (role_id, permission_id)
+);
+
+INSERT INTO roles (name, description) VALUES
+	('admin', '系统管理员'),
+	('teacher', '教师'),
+	('student', '学生'),
+	('parent', '家长')
+ON CONFLICT (name) DO NOTHING;
+
+INSERT INTO permissions (code, description) VALUES
+	('students:read', '查看学生'),
+	('students:write', '管理学生'),
+	('teachers:read', '查看教师'),
+	('teachers:write', '管理教师'),
+	('subjects:read', '查看科目'),
+	('subjects:write', '管理科目'),
+	('scores:read', '查看成绩'),
+	('scores:write', '录入和修改成绩'),
+	('statistics:read', '查看成绩统计'),
+	('admins:read', '查看账号和角色'),
+	('admins:write', '管理账号和角色')
+ON CONFLICT (code) DO NOTHING;
+
+-- 管理员拥有全部权限
+INSERT INTO role_permissions (role_id, permission_id)
+SELECT r.id, p.id FROM roles r CROSS JOIN permissions p
+WHERE r.name = 'admin'
+ON CONFLICT DO NOTHING;
+
+INSERT INTO role_permissions (role_id, permission_id)
+SELECT r.id, p.id FROM roles r JOIN permissions p ON p.code IN (
+	'students:read', 'teachers:read', 'subjects:read', 'scores:read', 'scores:write', 'statistics:read'
+)
+WHERE r.name = 'teacher'
+ON CONFLICT DO NOTHING;
+
+-- 学生和家长只读，且仅限本人（子女）的数据，由服务层进一步限定范围
+INSERT INTO role_permissions (role_id, permission_id)
+SELECT r.id, p.id FROM roles r JOIN permissions p ON p.code IN (
+	'students:read', 'subjects:read', 'scores:read'
+)
+WHERE r.name IN ('student', 'parent')
+ON CONFLICT DO NOTHING;
+
+-- 登录账号关联角色和主体（教师/学生）
+ALTER TABLE admins ADD COLUMN IF NOT EXISTS role VARCHAR(30) NOT NULL DEFAULT 'admin'
+	REFERENCES roles(name) ON UPDATE CASCADE;
+ALTER TABLE admins ADD COLUMN IF NOT EXISTS teacher_id INTEGER REFERENCES teachers(id) ON DELETE SET NULL;
+ALTER TABLE admins ADD COLUMN IF NOT EXISTS student_id INTEGER REFERENCES students(id) ON DELETE SET NULL;
+
+-- 家长与子女的关联
+CREATE TABLE IF NOT EXISTS parent_students (
+	admin_id INTEGER NOT NULL REFERENCES admins(id) ON DELETE CASCADE,
+	student_id INTEGER NOT NULL REFERENCES students(id) ON DELETE CASCADE,
+	created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+	PRIMARY KEY (admin_id, student_id)
+);
+
+-- 成绩记录录入教师
+ALTER TABLE scores ADD COLUMN IF NOT EXISTS teacher_id INTEGER REFERENCES teachers(id) ON DELETE SET NULL;
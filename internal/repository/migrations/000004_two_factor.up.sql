@@ -0,0 +1,16 @@
+-- 两步验证（TOTP）
+ALTER TABLE admins ADD COLUMN IF NOT EXISTS totp_secret VARCHAR(64);
+ALTER TABLE admins ADD COLUMN IF NOT EXISTS totp_enabled BOOLEAN NOT NULL DEFAULT FALSE;
+ALTER TABLE admins ADD COLUMN IF NOT EXISTS totp_enabled_at TIMESTAMP;
+
+-- 一次性恢复码，只保存哈希
+CREATE TABLE IF NOT EXISTS admin_recovery_codes (
+	id SERIAL PRIMARY KEY,
+	admin_id INTEGER NOT NULL REFERENCES admins(id) ON DELETE CASCADE,
+	code_hash CHAR(64) NOT NULL,
+	used_at TIMESTAMP,
+	created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+	UNIQUE (admin_id, code_hash)
+);
+
+CREATE INDEX IF NOT EXISTS idx_admin_recovery_codes_admin_id ON admin_recovery_codes(admin_id);
@@ -0,0 +1,15 @@
+-- 成绩单签发记录，用于通过验证码公开验证成绩单真伪
+CREATE TABLE IF NOT EXISTS transcript_verifications (
+	id SERIAL PRIMARY KEY,
+	code VARCHAR(32) NOT NULL UNIQUE,
+	kind VARCHAR(20) NOT NULL,
+	student_id INTEGER NOT NULL REFERENCES students(id) ON DELETE CASCADE,
+	semester VARCHAR(20),
+	total_credits INTEGER NOT NULL DEFAULT 0,
+	cumulative_gpa NUMERIC(4,2) NOT NULL DEFAULT 0,
+	checksum CHAR(64) NOT NULL,
+	issued_by INTEGER REFERENCES admins(id) ON DELETE SET NULL,
+	issued_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_transcript_verifications_student_id ON transcript_verifications(student_id);
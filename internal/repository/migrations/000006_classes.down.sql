@@ -0,0 +1,4 @@
+DELETE FROM permissions WHERE code IN ('classes:read', 'classes:write');
+DROP TABLE IF EXISTS class_memberships;
+ALTER TABLE students DROP COLUMN IF EXISTS class_id;
+DROP TABLE IF EXISTS classes;
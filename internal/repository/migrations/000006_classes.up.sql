@@ -0,0 +1,57 @@
+-- 行政班
+CREATE TABLE IF NOT EXISTS classes (
+	id SERIAL PRIMARY KEY,
+	name VARCHAR(50) NOT NULL,
+	grade_year INTEGER NOT NULL CHECK (grade_year BETWEEN 1900 AND 2100),
+	major VARCHAR(100) NOT NULL,
+	homeroom_teacher_id INTEGER REFERENCES teachers(id) ON DELETE SET NULL,
+	status VARCHAR(20) NOT NULL DEFAULT 'active',
+	created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+	updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+	UNIQUE (grade_year, name)
+);
+
+CREATE INDEX IF NOT EXISTS idx_classes_major ON classes(major);
+CREATE INDEX IF NOT EXISTS idx_classes_homeroom_teacher_id ON classes(homeroom_teacher_id);
+
+DROP TRIGGER IF EXISTS update_classes_updated_at ON classes;
+CREATE TRIGGER update_classes_updated_at
+	BEFORE UPDATE ON classes
+	FOR EACH ROW
+	EXECUTE FUNCTION update_updated_at_column();
+
+-- 学生当前所在班级，历史记录见 class_memberships
+ALTER TABLE students ADD COLUMN IF NOT EXISTS class_id INTEGER REFERENCES classes(id) ON DELETE SET NULL;
+CREATE INDEX IF NOT EXISTS idx_students_class_id ON students(class_id);
+
+-- 学生分班历史，left_at 为空表示当前所在班级
+CREATE TABLE IF NOT EXISTS class_memberships (
+	id SERIAL PRIMARY KEY,
+	student_id INTEGER NOT NULL REFERENCES students(id) ON DELETE CASCADE,
+	class_id INTEGER NOT NULL REFERENCES classes(id) ON DELETE CASCADE,
+	joined_at DATE NOT NULL DEFAULT CURRENT_DATE,
+	left_at DATE,
+	reason VARCHAR(200),
+	created_by INTEGER REFERENCES admins(id) ON DELETE SET NULL,
+	created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_class_memberships_student_id ON class_memberships(student_id);
+CREATE INDEX IF NOT EXISTS idx_class_memberships_class_id ON class_memberships(class_id);
+CREATE UNIQUE INDEX IF NOT EXISTS uq_class_memberships_current ON class_memberships(student_id) WHERE left_at IS NULL;
+
+-- 班级权限
+INSERT INTO permissions (code, description) VALUES
+	('classes:read', '查看班级'),
+	('classes:write', '管理班级和分班')
+ON CONFLICT (code) DO NOTHING;
+
+INSERT INTO role_permissions (role_id, permission_id)
+SELECT r.id, p.id FROM roles r JOIN permissions p ON p.code IN ('classes:read', 'classes:write')
+WHERE r.name = 'admin'
+ON CONFLICT DO NOTHING;
+
+INSERT INTO role_permissions (role_id, permission_id)
+SELECT r.id, p.id FROM roles r JOIN permissions p ON p.code = 'classes:read'
+WHERE r.name = 'teacher'
+ON CONFLICT DO NOTHING;
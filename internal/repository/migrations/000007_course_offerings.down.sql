@@ -0,0 +1,4 @@
+DELETE FROM permissions WHERE code IN ('offerings:read', 'offerings:write', 'enrollments:write');
+ALTER TABLE scores DROP COLUMN IF EXISTS offering_id;
+DROP TABLE IF EXISTS enrollments;
+DROP TABLE IF EXISTS course_offerings;
@@ -0,0 +1,95 @@
+-- 开课：某学期由某位老师开设的科目教学班
+CREATE TABLE IF NOT EXISTS course_offerings (
+	id SERIAL PRIMARY KEY,
+	subject_id INTEGER NOT NULL REFERENCES subjects(id) ON DELETE CASCADE,
+	semester VARCHAR(20) NOT NULL,
+	section VARCHAR(20) NOT NULL DEFAULT '01',
+	teacher_id INTEGER REFERENCES teachers(id) ON DELETE SET NULL,
+	capacity INTEGER NOT NULL CHECK (capacity > 0),
+	waitlist_capacity INTEGER NOT NULL DEFAULT 0 CHECK (waitlist_capacity >= 0),
+	schedule VARCHAR(200),
+	add_deadline TIMESTAMP,
+	drop_deadline TIMESTAMP,
+	status VARCHAR(20) NOT NULL DEFAULT 'open',
+	created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+	updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+	UNIQUE (subject_id, semester, section)
+);
+
+CREATE INDEX IF NOT EXISTS idx_course_offerings_semester ON course_offerings(semester);
+CREATE INDEX IF NOT EXISTS idx_course_offerings_teacher_id ON course_offerings(teacher_id);
+
+DROP TRIGGER IF EXISTS update_course_offerings_updated_at ON course_offerings;
+CREATE TRIGGER update_course_offerings_updated_at
+	BEFORE UPDATE ON course_offerings
+	FOR EACH ROW
+	EXECUTE FUNCTION update_updated_at_column();
+
+-- 选课记录，退课后保留为 dropped，候补按 requested_at 排序
+CREATE TABLE IF NOT EXISTS enrollments (
+	id SERIAL PRIMARY KEY,
+	offering_id INTEGER NOT NULL REFERENCES course_offerings(id) ON DELETE CASCADE,
+	student_id INTEGER NOT NULL REFERENCES students(id) ON DELETE CASCADE,
+	status VARCHAR(20) NOT NULL DEFAULT 'enrolled',
+	requested_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+	enrolled_at TIMESTAMP,
+	dropped_at TIMESTAMP,
+	created_by INTEGER REFERENCES admins(id) ON DELETE SET NULL,
+	updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+	UNIQUE (offering_id, student_id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_enrollments_student_id ON enrollments(student_id);
+CREATE INDEX IF NOT EXISTS idx_enrollments_offering_status ON enrollments(offering_id, status, requested_at);
+
+DROP TRIGGER IF EXISTS update_enrollments_updated_at ON enrollments;
+CREATE TRIGGER update_enrollments_updated_at
+	BEFORE UPDATE ON enrollments
+	FOR EACH ROW
+	EXECUTE FUNCTION update_updated_at_column();
+
+-- 成绩所属开课
+ALTER TABLE scores ADD COLUMN IF NOT EXISTS offering_id INTEGER REFERENCES course_offerings(id) ON DELETE SET NULL;
+CREATE INDEX IF NOT EXISTS idx_scores_offering_id ON scores(offering_id);
+
+-- 为已有成绩补建开课和选课记录，历史开课状态为 closed
+INSERT INTO course_offerings (subject_id, semester, teacher_id, capacity, status)
+SELECT s.subject_id, s.semester, MIN(s.teacher_id), GREATEST(COUNT(DISTINCT s.student_id), 1), 'closed'
+FROM scores s
+GROUP BY s.subject_id, s.semester
+ON CONFLICT (subject_id, semester, section) DO NOTHING;
+
+INSERT INTO enrollments (offering_id, student_id, status, requested_at, enrolled_at)
+SELECT o.id, s.student_id, 'enrolled',
+       COALESCE(MIN(s.created_at), CURRENT_TIMESTAMP), COALESCE(MIN(s.created_at), CURRENT_TIMESTAMP)
+FROM scores s
+JOIN course_offerings o ON o.subject_id = s.subject_id AND o.semester = s.semester AND o.section = '01'
+GROUP BY o.id, s.student_id
+ON CONFLICT (offering_id, student_id) DO NOTHING;
+
+UPDATE scores s SET offering_id = o.id
+FROM course_offerings o
+WHERE o.subject_id = s.subject_id AND o.semester = s.semester AND o.section = '01' AND s.offering_id IS NULL;
+
+-- 开课和选课权限
+INSERT INTO permissions (code, description) VALUES
+	('offerings:read', '查看开课和选课名单'),
+	('offerings:write', '管理开课'),
+	('enrollments:write', '选课和退课')
+ON CONFLICT (code) DO NOTHING;
+
+INSERT INTO role_permissions (role_id, permission_id)
+SELECT r.id, p.id FROM roles r JOIN permissions p ON p.code IN ('offerings:read', 'offerings:write', 'enrollments:write')
+WHERE r.name = 'admin'
+ON CONFLICT DO NOTHING;
+
+INSERT INTO role_permissions (role_id, permission_id)
+SELECT r.id, p.id FROM roles r JOIN permissions p ON p.code = 'offerings:read'
+WHERE r.name IN ('teacher', 'parent')
+ON CONFLICT DO NOTHING;
+
+-- 学生可在加退选截止时间前为本人选课和退课
+INSERT INTO role_permissions (role_id, permission_id)
+SELECT r.id, p.id FROM roles r JOIN permissions p ON p.code IN ('offerings:read', 'enrollments:write')
+WHERE r.name = 'student'
+ON CONFLICT DO NOTHING;
@@ -0,0 +1,5 @@
+DELETE FROM permissions WHERE code IN ('terms:read', 'terms:write');
+ALTER TABLE course_offerings DROP CONSTRAINT IF EXISTS fk_course_offerings_semester;
+ALTER TABLE scores DROP CONSTRAINT IF EXISTS fk_scores_semester;
+DROP FUNCTION IF EXISTS normalize_term_code(TEXT);
+DROP TABLE IF EXISTS terms;
@@ -0,0 +1,186 @@
+-- 学期
+CREATE TABLE IF NOT EXISTS terms (
+	id SERIAL PRIMARY KEY,
+	code VARCHAR(20) NOT NULL UNIQUE,
+	name VARCHAR(50) NOT NULL,
+	start_date DATE NOT NULL,
+	end_date DATE NOT NULL,
+	is_current BOOLEAN NOT NULL DEFAULT FALSE,
+	status VARCHAR(20) NOT NULL DEFAULT 'planning',
+	created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+	updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+	CHECK (end_date >= start_date)
+);
+
+-- 同一时间只能有一个当前学期
+CREATE UNIQUE INDEX IF NOT EXISTS uq_terms_current ON terms(is_current) WHERE is_current;
+
+DROP TRIGGER IF EXISTS update_terms_updated_at ON terms;
+CREATE TRIGGER update_terms_updated_at
+	BEFORE UPDATE ON terms
+	FOR EACH ROW
+	EXECUTE FUNCTION update_updated_at_column();
+
+-- 将历史学期写法规范为 <学年起始年>-<学年结束年>-<学期序号>，如 2024-2025-1，无法识别时返回 NULL。
+-- 与 domain.NormalizeTermCode 的规则保持一致：
+--   2024-2025学年第一学期 -> 2024-2025-1
+--   2024-1、2024-2        -> 2024-2025-1、2024-2025-2
+--   2024秋、2024 Fall     -> 2024-2025-1
+--   2024春、Spring 2024   -> 2023-2024-2
+CREATE OR REPLACE FUNCTION normalize_term_code(raw TEXT) RETURNS TEXT AS $$
+DECLARE
+	s TEXT := lower(regexp_replace(btrim(raw), '\s+', '', 'g'));
+	m TEXT[];
+BEGIN
+	IF s ~ '^\d{4}-\d{4}-[123]$' THEN
+		RETURN s;
+	END IF;
+
+	m := regexp_match(s, '^(\d{4})-(\d{4})学年第([一二三123])学期$');
+	IF m IS NOT NULL THEN
+		RETURN m[1] || '-' || m[2] || '-' || translate(m[3], '一二三', '123');
+	END IF;
+
+	m := regexp_match(s, '^(\d{4})-([123])$');
+	IF m IS NOT NULL THEN
+		RETURN m[1] || '-' || (m[1]::int + 1) || '-' || m[2];
+	END IF;
+
+	s := regexp_replace(s, '(季|学期)+$', '');
+	m := regexp_match(s, '^(\d{4})(春|秋|spring|fall|autumn)$');
+	IF m IS NULL THEN
+		m := regexp_match(s, '^(春|秋|spring|fall|autumn)(\d{4})$');
+		IF m IS NOT NULL THEN
+			m := ARRAY[m[2], m[1]];
+		END IF;
+	END IF;
+	IF m IS NOT NULL THEN
+		IF m[2] IN ('春', 'spring') THEN
+			RETURN (m[1]::int - 1) || '-' || m[1] || '-2';
+		END IF;
+		RETURN m[1] || '-' || (m[1]::int + 1) || '-1';
+	END IF;
+
+	RETURN NULL;
+END;
+$$ LANGUAGE plpgsql IMMUTABLE;
+
+-- 规范化后同一学生、科目、考试类型出现重复成绩时无法自动合并，需人工处理后再执行迁移
+DO $$
+DECLARE
+	dup RECORD;
+BEGIN
+	SELECT student_id, subject_id, exam_type, COALESCE(normalize_term_code(semester), btrim(semester)) AS code
+	INTO dup
+	FROM scores
+	GROUP BY 1, 2, 3, 4
+	HAVING COUNT(*) > 1
+	LIMIT 1;
+
+	IF FOUND THEN
+		RAISE EXCEPTION '学期规范化后存在重复成绩（学生 %, 科目 %, 考试类型 %, 学期 %），请先人工合并',
+			dup.student_id, dup.subject_id, dup.exam_type, dup.code;
+	END IF;
+END;
+$$;
+
+-- 规范化后重复的开课（同一科目、学期、教学班号）合并到编号最小的一个
+CREATE TEMP TABLE offering_merge ON COMMIT DROP AS
+SELECT id, keep_id FROM (
+	SELECT id, MIN(id) OVER (
+		PARTITION BY subject_id, COALESCE(normalize_term_code(semester), btrim(semester)), section
+	) AS keep_id
+	FROM course_offerings
+) o
+WHERE id <> keep_id;
+
+UPDATE scores s SET offering_id = m.keep_id
+FROM offering_merge m
+WHERE s.offering_id = m.id;
+
+INSERT INTO enrollments (offering_id, student_id, status, requested_at, enrolled_at, dropped_at, created_by)
+SELECT m.keep_id, e.student_id, e.status, e.requested_at, e.enrolled_at, e.dropped_at, e.created_by
+FROM enrollments e
+JOIN offering_merge m ON e.offering_id = m.id
+ON CONFLICT (offering_id, student_id) DO NOTHING;
+
+DELETE FROM course_offerings WHERE id IN (SELECT id FROM offering_merge);
+
+UPDATE course_offerings o
+SET capacity = GREATEST(o.capacity, (SELECT COUNT(*) FROM enrollments e WHERE e.offering_id = o.id AND e.status = 'enrolled'))
+WHERE o.id IN (SELECT keep_id FROM offering_merge);
+
+-- 为成绩和开课中出现过的学期建档。可识别的学期按惯例推算起止日期（第一学期 9月-次年1月，
+-- 第二学期 2月-6月，第三学期为夏季学期 7月-8月），无法识别的写法原样作为学期代码，起止日期取成绩录入时间
+WITH raw AS (
+	SELECT semester, created_at FROM scores
+	UNION ALL
+	SELECT semester, created_at FROM course_offerings
+), codes AS (
+	SELECT COALESCE(normalize_term_code(semester), btrim(semester)) AS code,
+	       COALESCE(MIN(created_at)::date, CURRENT_DATE) AS first_seen,
+	       COALESCE(MAX(created_at)::date, CURRENT_DATE) AS last_seen
+	FROM raw
+	GROUP BY 1
+), dated AS (
+	SELECT code,
+	       CASE
+	           WHEN code !~ '^\d{4}-\d{4}-[123]$' THEN code
+	           ELSE split_part(code, '-', 1) || '-' || split_part(code, '-', 2) || '学年第'
+	                || translate(split_part(code, '-', 3), '123', '一二三') || '学期'
+	       END AS name,
+	       CASE
+	           WHEN code !~ '^\d{4}-\d{4}-[123]$' THEN first_seen
+	           WHEN split_part(code, '-', 3) = '1' THEN make_date(split_part(code, '-', 1)::int, 9, 1)
+	           WHEN split_part(code, '-', 3) = '2' THEN make_date(split_part(code, '-', 2)::int, 2, 1)
+	           ELSE make_date(split_part(code, '-', 2)::int, 7, 1)
+	       END AS start_date,
+	       CASE
+	           WHEN code !~ '^\d{4}-\d{4}-[123]$' THEN last_seen
+	           WHEN split_part(code, '-', 3) = '1' THEN make_date(split_part(code, '-', 2)::int, 1, 31)
+	           WHEN split_part(code, '-', 3) = '2' THEN make_date(split_part(code, '-', 2)::int, 6, 30)
+	           ELSE make_date(split_part(code, '-', 2)::int, 8, 31)
+	       END AS end_date
+	FROM codes
+)
+INSERT INTO terms (code, name, start_date, end_date, status)
+SELECT code, name, start_date, end_date,
+       CASE WHEN end_date < CURRENT_DATE THEN 'grading' ELSE 'open' END
+FROM dated
+ON CONFLICT (code) DO NOTHING;
+
+UPDATE scores SET semester = COALESCE(normalize_term_code(semester), btrim(semester))
+WHERE semester IS DISTINCT FROM COALESCE(normalize_term_code(semester), btrim(semester));
+
+UPDATE course_offerings SET semester = COALESCE(normalize_term_code(semester), btrim(semester))
+WHERE semester IS DISTINCT FROM COALESCE(normalize_term_code(semester), btrim(semester));
+
+UPDATE terms SET is_current = TRUE
+WHERE id = (
+	SELECT id FROM terms
+	WHERE CURRENT_DATE BETWEEN start_date AND end_date
+	ORDER BY start_date DESC
+	LIMIT 1
+);
+
+-- 成绩和开课的学期改为引用学期代码
+ALTER TABLE scores ADD CONSTRAINT fk_scores_semester
+	FOREIGN KEY (semester) REFERENCES terms(code) ON UPDATE CASCADE;
+ALTER TABLE course_offerings ADD CONSTRAINT fk_course_offerings_semester
+	FOREIGN KEY (semester) REFERENCES terms(code) ON UPDATE CASCADE;
+
+-- 学期权限
+INSERT INTO permissions (code, description) VALUES
+	('terms:read', '查看学期'),
+	('terms:write', '管理学期')
+ON CONFLICT (code) DO NOTHING;
+
+INSERT INTO role_permissions (role_id, permission_id)
+SELECT r.id, p.id FROM roles r JOIN permissions p ON p.code IN ('terms:read', 'terms:write')
+WHERE r.name = 'admin'
+ON CONFLICT DO NOTHING;
+
+INSERT INTO role_permissions (role_id, permission_id)
+SELECT r.id, p.id FROM roles r JOIN permissions p ON p.code = 'terms:read'
+WHERE r.name IN ('teacher', 'student', 'parent')
+ON CONFLICT DO NOTHING;
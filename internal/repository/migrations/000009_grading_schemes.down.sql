@@ -0,0 +1,2 @@
+DROP TABLE IF EXISTS final_grades;
+DROP TABLE IF EXISTS grading_schemes;
@@ -0,0 +1,59 @@
+-- 评分方案：总评成绩中各考试类型所占权重（百分比），可按科目或按开课设置，开课方案优先
+CREATE TABLE IF NOT EXISTS grading_schemes (
+	id SERIAL PRIMARY KEY,
+	subject_id INTEGER UNIQUE REFERENCES subjects(id) ON DELETE CASCADE,
+	offering_id INTEGER UNIQUE REFERENCES course_offerings(id) ON DELETE CASCADE,
+	midterm_weight NUMERIC(5,2) NOT NULL DEFAULT 0 CHECK (midterm_weight >= 0),
+	final_weight NUMERIC(5,2) NOT NULL DEFAULT 0 CHECK (final_weight >= 0),
+	quiz_weight NUMERIC(5,2) NOT NULL DEFAULT 0 CHECK (quiz_weight >= 0),
+	assignment_weight NUMERIC(5,2) NOT NULL DEFAULT 0 CHECK (assignment_weight >= 0),
+	missing_policy VARCHAR(20) NOT NULL DEFAULT 'renormalize',
+	created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+	updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+	CHECK ((subject_id IS NULL) <> (offering_id IS NULL)),
+	CHECK (midterm_weight + final_weight + quiz_weight + assignment_weight = 100)
+);
+
+DROP TRIGGER IF EXISTS update_grading_schemes_updated_at ON grading_schemes;
+CREATE TRIGGER update_grading_schemes_updated_at
+	BEFORE UPDATE ON grading_schemes
+	FOR EACH ROW
+	EXECUTE FUNCTION update_updated_at_column();
+
+-- 总评成绩：学生某学期某科目各考试类型成绩按评分方案加权的结果，成绩变动时重新计算
+CREATE TABLE IF NOT EXISTS final_grades (
+	id SERIAL PRIMARY KEY,
+	student_id INTEGER NOT NULL REFERENCES students(id) ON DELETE CASCADE,
+	subject_id INTEGER NOT NULL REFERENCES subjects(id) ON DELETE CASCADE,
+	semester VARCHAR(20) NOT NULL REFERENCES terms(code) ON UPDATE CASCADE,
+	offering_id INTEGER REFERENCES course_offerings(id) ON DELETE SET NULL,
+	-- 计算时使用的评分方案，为空表示默认方案（期末成绩占100%）
+	scheme_id INTEGER REFERENCES grading_schemes(id) ON DELETE SET NULL,
+	midterm_score NUMERIC(5,2),
+	final_score NUMERIC(5,2),
+	quiz_score NUMERIC(5,2),
+	assignment_score NUMERIC(5,2),
+	composite_score NUMERIC(5,2),
+	weight_covered NUMERIC(5,2) NOT NULL DEFAULT 0,
+	is_complete BOOLEAN NOT NULL DEFAULT FALSE,
+	computed_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+	UNIQUE (student_id, subject_id, semester)
+);
+
+CREATE INDEX IF NOT EXISTS idx_final_grades_offering_id ON final_grades(offering_id);
+CREATE INDEX IF NOT EXISTS idx_final_grades_semester ON final_grades(semester);
+
+-- 按默认方案为已有成绩生成总评成绩
+INSERT INTO final_grades (student_id, subject_id, semester, offering_id,
+	midterm_score, final_score, quiz_score, assignment_score, composite_score, weight_covered, is_complete)
+SELECT s.student_id, s.subject_id, s.semester, MAX(s.offering_id),
+	MAX(s.score) FILTER (WHERE s.exam_type = 'midterm'),
+	MAX(s.score) FILTER (WHERE s.exam_type = 'final'),
+	MAX(s.score) FILTER (WHERE s.exam_type = 'quiz'),
+	MAX(s.score) FILTER (WHERE s.exam_type = 'assignment'),
+	MAX(s.score) FILTER (WHERE s.exam_type = 'final'),
+	CASE WHEN BOOL_OR(s.exam_type = 'final') THEN 100 ELSE 0 END,
+	BOOL_OR(s.exam_type = 'final')
+FROM scores s
+GROUP BY s.student_id, s.subject_id, s.semester
+ON CONFLICT (student_id, subject_id, semester) DO NOTHING;
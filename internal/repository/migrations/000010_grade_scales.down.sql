@@ -0,0 +1,5 @@
+DELETE FROM permissions WHERE code IN ('grade_scales:read', 'grade_scales:write');
+ALTER TABLE transcript_verifications DROP COLUMN IF EXISTS grade_scale;
+DROP TABLE IF EXISTS major_grade_scales;
+DROP TABLE IF EXISTS grade_scale_bands;
+DROP TABLE IF EXISTS grade_scales;
@@ -0,0 +1,99 @@
+-- 绩点制：百分制成绩换算为等级和绩点的规则
+-- formula 为 bands 时绩点取分数段的 grade_point；linear、pku 按公式计算，分数段只决定等级和是否通过
+CREATE TABLE IF NOT EXISTS grade_scales (
+	id SERIAL PRIMARY KEY,
+	name VARCHAR(30) NOT NULL UNIQUE,
+	display_name VARCHAR(50) NOT NULL,
+	description VARCHAR(200),
+	formula VARCHAR(20) NOT NULL DEFAULT 'bands',
+	max_point NUMERIC(3,1) NOT NULL DEFAULT 4.0,
+	is_builtin BOOLEAN NOT NULL DEFAULT FALSE,
+	created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+	updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+DROP TRIGGER IF EXISTS update_grade_scales_updated_at ON grade_scales;
+CREATE TRIGGER update_grade_scales_updated_at
+	BEFORE UPDATE ON grade_scales
+	FOR EACH ROW
+	EXECUTE FUNCTION update_updated_at_column();
+
+-- 等级分数段，成绩不低于 min_score 时取该段；grade_point 为空表示不计入平均绩点（如通过/不通过制）
+CREATE TABLE IF NOT EXISTS grade_scale_bands (
+	id SERIAL PRIMARY KEY,
+	scale_id INTEGER NOT NULL REFERENCES grade_scales(id) ON DELETE CASCADE,
+	min_score NUMERIC(5,2) NOT NULL CHECK (min_score >= 0 AND min_score <= 100),
+	letter VARCHAR(5) NOT NULL,
+	grade_point NUMERIC(3,2),
+	passed BOOLEAN NOT NULL,
+	UNIQUE (scale_id, min_score)
+);
+
+-- 专业使用的绩点制，未设置的专业使用配置中的默认绩点制
+CREATE TABLE IF NOT EXISTS major_grade_scales (
+	major VARCHAR(50) PRIMARY KEY,
+	scale_name VARCHAR(30) NOT NULL,
+	updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+-- 成绩单记录计算绩点所用的绩点制
+ALTER TABLE transcript_verifications ADD COLUMN IF NOT EXISTS grade_scale VARCHAR(30) NOT NULL DEFAULT 'standard4';
+
+-- 内置绩点制
+INSERT INTO grade_scales (name, display_name, description, formula, max_point, is_builtin) VALUES
+	('standard4', '标准4.0分制', '90分及以上4.0，80-89分3.0，70-79分2.0，60-69分1.0，60分以下0', 'bands', 4.0, TRUE),
+	('linear4', '4.0线性分制', '绩点=(成绩-50)/10，最高4.0，60分以下为0', 'linear', 4.0, TRUE),
+	('pku4', '北大4.0分制', '绩点=4-3×(100-成绩)²/1600，60分以下为0', 'pku', 4.0, TRUE),
+	('five_point', '5分制', '90分及以上5.0，80-89分4.0，70-79分3.0，60-69分2.0，60分以下0', 'bands', 5.0, TRUE),
+	('pass_fail', '通过/不通过', '60分及以上为通过，不计入平均绩点', 'bands', 4.0, TRUE)
+ON CONFLICT (name) DO NOTHING;
+
+INSERT INTO grade_scale_bands (scale_id, min_score, letter, grade_point, passed)
+SELECT gs.id, b.min_score, b.letter, b.grade_point, b.passed
+FROM grade_scales gs
+JOIN (VALUES
+	('standard4', 90, 'A', 4.0, TRUE),
+	('standard4', 80, 'B', 3.0, TRUE),
+	('standard4', 70, 'C', 2.0, TRUE),
+	('standard4', 60, 'D', 1.0, TRUE),
+	('standard4', 0, 'F', 0, FALSE),
+	('linear4', 90, 'A', NULL, TRUE),
+	('linear4', 80, 'B', NULL, TRUE),
+	('linear4', 70, 'C', NULL, TRUE),
+	('linear4', 60, 'D', NULL, TRUE),
+	('linear4', 0, 'F', NULL, FALSE),
+	('pku4', 90, 'A', NULL, TRUE),
+	('pku4', 85, 'A-', NULL, TRUE),
+	('pku4', 82, 'B+', NULL, TRUE),
+	('pku4', 78, 'B', NULL, TRUE),
+	('pku4', 75, 'B-', NULL, TRUE),
+	('pku4', 72, 'C+', NULL, TRUE),
+	('pku4', 68, 'C', NULL, TRUE),
+	('pku4', 64, 'C-', NULL, TRUE),
+	('pku4', 60, 'D', NULL, TRUE),
+	('pku4', 0, 'F', NULL, FALSE),
+	('five_point', 90, 'A', 5.0, TRUE),
+	('five_point', 80, 'B', 4.0, TRUE),
+	('five_point', 70, 'C', 3.0, TRUE),
+	('five_point', 60, 'D', 2.0, TRUE),
+	('five_point', 0, 'F', 0, FALSE),
+	('pass_fail', 60, 'P', NULL, TRUE),
+	('pass_fail', 0, 'NP', NULL, FALSE)
+) AS b(scale_name, min_score, letter, grade_point, passed) ON b.scale_name = gs.name
+ON CONFLICT (scale_id, min_score) DO NOTHING;
+
+-- 绩点制权限
+INSERT INTO permissions (code, description) VALUES
+	('grade_scales:read', '查看绩点制'),
+	('grade_scales:write', '管理绩点制')
+ON CONFLICT (code) DO NOTHING;
+
+INSERT INTO role_permissions (role_id, permission_id)
+SELECT r.id, p.id FROM roles r JOIN permissions p ON p.code IN ('grade_scales:read', 'grade_scales:write')
+WHERE r.name = 'admin'
+ON CONFLICT DO NOTHING;
+
+INSERT INTO role_permissions (role_id, permission_id)
+SELECT r.id, p.id FROM roles r JOIN permissions p ON p.code = 'grade_scales:read'
+WHERE r.name IN ('teacher', 'student', 'parent')
+ON CONFLICT DO NOTHING;
@@ -0,0 +1,5 @@
+DELETE FROM permissions WHERE code = 'scores:approve';
+DROP TABLE IF EXISTS grade_change_requests;
+DROP TABLE IF EXISTS score_workflow_events;
+DROP INDEX IF EXISTS idx_scores_offering_status;
+ALTER TABLE scores DROP COLUMN IF EXISTS status;
@@ -0,0 +1,56 @@
+-- 成绩状态：draft 草稿 -> submitted 已提交 -> approved 已审核 -> published 已发布 -> locked 已锁定
+-- 只有草稿可以直接修改；已提交、已审核的成绩可退回为草稿；已发布、已锁定的成绩只能通过成绩更正申请修改
+ALTER TABLE scores ADD COLUMN IF NOT EXISTS status VARCHAR(20) NOT NULL DEFAULT 'draft'
+	CHECK (status IN ('draft', 'submitted', 'approved', 'published', 'locked'));
+
+-- 已有成绩此前对学生可见，视为已发布；已结束学期的成绩直接锁定
+UPDATE scores s SET status = CASE
+	WHEN EXISTS (SELECT 1 FROM terms t WHERE t.code = s.semester AND t.status = 'closed') THEN 'locked'
+	ELSE 'published'
+END;
+
+CREATE INDEX IF NOT EXISTS idx_scores_offering_status ON scores(offering_id, status);
+
+-- 开课成绩的提交、审核、退回、发布和锁定记录
+CREATE TABLE IF NOT EXISTS score_workflow_events (
+	id SERIAL PRIMARY KEY,
+	offering_id INTEGER NOT NULL REFERENCES course_offerings(id) ON DELETE CASCADE,
+	action VARCHAR(20) NOT NULL CHECK (action IN ('submit', 'approve', 'reject', 'publish', 'lock')),
+	from_status VARCHAR(20) NOT NULL,
+	to_status VARCHAR(20) NOT NULL,
+	affected INTEGER NOT NULL DEFAULT 0,
+	comment TEXT,
+	actor_id INTEGER REFERENCES admins(id) ON DELETE SET NULL,
+	created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_score_workflow_events_offering_id ON score_workflow_events(offering_id);
+
+-- 成绩更正申请：已发布或已锁定的成绩须经审批后才能修改
+CREATE TABLE IF NOT EXISTS grade_change_requests (
+	id SERIAL PRIMARY KEY,
+	score_id INTEGER NOT NULL REFERENCES scores(id) ON DELETE CASCADE,
+	old_score DECIMAL(5,2) NOT NULL,
+	new_score DECIMAL(5,2) NOT NULL CHECK (new_score >= 0 AND new_score <= 100),
+	reason TEXT NOT NULL,
+	status VARCHAR(20) NOT NULL DEFAULT 'pending' CHECK (status IN ('pending', 'approved', 'rejected')),
+	requested_by INTEGER REFERENCES admins(id) ON DELETE SET NULL,
+	reviewed_by INTEGER REFERENCES admins(id) ON DELETE SET NULL,
+	review_comment TEXT,
+	created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+	reviewed_at TIMESTAMP
+);
+
+-- 同一成绩同时只能有一条待审批的更正申请
+CREATE UNIQUE INDEX IF NOT EXISTS uq_grade_change_requests_pending ON grade_change_requests(score_id) WHERE status = 'pending';
+CREATE INDEX IF NOT EXISTS idx_grade_change_requests_status ON grade_change_requests(status);
+
+-- 成绩审核权限，默认只授予管理员，可通过角色权限接口授予负责审核的角色
+INSERT INTO permissions (code, description) VALUES
+	('scores:approve', '审核、发布和锁定成绩，审批成绩更正申请')
+ON CONFLICT (code) DO NOTHING;
+
+INSERT INTO role_permissions (role_id, permission_id)
+SELECT r.id, p.id FROM roles r JOIN permissions p ON p.code = 'scores:approve'
+WHERE r.name = 'admin'
+ON CONFLICT DO NOTHING;
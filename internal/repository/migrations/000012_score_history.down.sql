@@ -0,0 +1,2 @@
+DROP TABLE IF EXISTS score_history;
+DROP FUNCTION IF EXISTS prevent_score_history_change();
@@ -0,0 +1,41 @@
+-- 成绩变更历史：记录成绩的录入、修改、删除和更正，只能追加，不能修改或删除
+-- 不设外键，成绩被删除后仍保留其变更记录
+CREATE TABLE IF NOT EXISTS score_history (
+	id SERIAL PRIMARY KEY,
+	score_id INTEGER NOT NULL,
+	student_id INTEGER NOT NULL,
+	subject_id INTEGER NOT NULL,
+	semester VARCHAR(20) NOT NULL,
+	exam_type VARCHAR(20) NOT NULL,
+	action VARCHAR(20) NOT NULL CHECK (action IN ('create', 'update', 'delete', 'grade_change')),
+	old_score DECIMAL(5,2),
+	new_score DECIMAL(5,2),
+	details TEXT,
+	reason TEXT,
+	actor_id INTEGER,
+	ip_address VARCHAR(45),
+	created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_score_history_score_id ON score_history(score_id);
+CREATE INDEX IF NOT EXISTS idx_score_history_student_id ON score_history(student_id, created_at);
+
+-- 禁止修改和删除变更记录
+CREATE OR REPLACE FUNCTION prevent_score_history_change()
+RETURNS TRIGGER AS $$
+BEGIN
+	RAISE EXCEPTION 'score_history is append-only';
+END;
+$$ language 'plpgsql';
+
+DROP TRIGGER IF EXISTS score_history_append_only ON score_history;
+CREATE TRIGGER score_history_append_only
+	BEFORE UPDATE OR DELETE ON score_history
+	FOR EACH ROW
+	EXECUTE FUNCTION prevent_score_history_change();
+
+DROP TRIGGER IF EXISTS score_history_no_truncate ON score_history;
+CREATE TRIGGER score_history_no_truncate
+	BEFORE TRUNCATE ON score_history
+	FOR EACH STATEMENT
+	EXECUTE FUNCTION prevent_score_history_change();
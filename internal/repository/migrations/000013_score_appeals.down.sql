@@ -0,0 +1,6 @@
+DELETE FROM permissions WHERE code IN ('appeals:submit', 'appeals:review');
+ALTER TABLE score_history DROP CONSTRAINT IF EXISTS score_history_action_check;
+-- 变更记录不能删除，已有的复核调整记录保留，不再校验
+ALTER TABLE score_history ADD CONSTRAINT score_history_action_check
+	CHECK (action IN ('create', 'update', 'delete', 'grade_change')) NOT VALID;
+DROP TABLE IF EXISTS score_appeals;
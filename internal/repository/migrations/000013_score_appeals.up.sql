@@ -0,0 +1,59 @@
+-- 成绩复核申请：学生或家长对已发布的成绩提出复核，由任课教师或管理员审理
+-- 状态：submitted 已提交 -> under_review 复核中 -> upheld 维持原成绩 / adjusted 已调整成绩
+CREATE TABLE IF NOT EXISTS score_appeals (
+	id SERIAL PRIMARY KEY,
+	score_id INTEGER NOT NULL REFERENCES scores(id) ON DELETE CASCADE,
+	student_id INTEGER NOT NULL REFERENCES students(id) ON DELETE CASCADE,
+	reason TEXT NOT NULL,
+	attachments TEXT[] NOT NULL DEFAULT '{}',
+	status VARCHAR(20) NOT NULL DEFAULT 'submitted'
+		CHECK (status IN ('submitted', 'under_review', 'upheld', 'adjusted')),
+	original_score DECIMAL(5,2) NOT NULL,
+	adjusted_score DECIMAL(5,2) CHECK (adjusted_score >= 0 AND adjusted_score <= 100),
+	assigned_teacher_id INTEGER REFERENCES teachers(id) ON DELETE SET NULL,
+	submitted_by INTEGER REFERENCES admins(id) ON DELETE SET NULL,
+	reviewer_id INTEGER REFERENCES admins(id) ON DELETE SET NULL,
+	resolution TEXT,
+	created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+	updated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+	resolved_at TIMESTAMP
+);
+
+-- 同一成绩同时只能有一条未结案的复核申请
+CREATE UNIQUE INDEX IF NOT EXISTS uq_score_appeals_open ON score_appeals(score_id)
+	WHERE status IN ('submitted', 'under_review');
+CREATE INDEX IF NOT EXISTS idx_score_appeals_student_id ON score_appeals(student_id);
+CREATE INDEX IF NOT EXISTS idx_score_appeals_teacher_status ON score_appeals(assigned_teacher_id, status);
+
+DROP TRIGGER IF EXISTS update_score_appeals_updated_at ON score_appeals;
+CREATE TRIGGER update_score_appeals_updated_at
+	BEFORE UPDATE ON score_appeals
+	FOR EACH ROW
+	EXECUTE FUNCTION update_updated_at_column();
+
+-- 复核调整成绩写入成绩变更记录
+ALTER TABLE score_history DROP CONSTRAINT IF EXISTS score_history_action_check;
+ALTER TABLE score_history ADD CONSTRAINT score_history_action_check
+	CHECK (action IN ('create', 'update', 'delete', 'grade_change', 'appeal'));
+
+INSERT INTO permissions (code, description) VALUES
+	('appeals:submit', '提交成绩复核申请'),
+	('appeals:review', '审理成绩复核申请')
+ON CONFLICT (code) DO NOTHING;
+
+INSERT INTO role_permissions (role_id, permission_id)
+SELECT r.id, p.id FROM roles r JOIN permissions p ON p.code IN ('appeals:submit', 'appeals:review')
+WHERE r.name = 'admin'
+ON CONFLICT DO NOTHING;
+
+-- 教师只能审理分配给本人的复核申请，由服务层进一步限定范围
+INSERT INTO role_permissions (role_id, permission_id)
+SELECT r.id, p.id FROM roles r JOIN permissions p ON p.code = 'appeals:review'
+WHERE r.name = 'teacher'
+ON CONFLICT DO NOTHING;
+
+-- 学生和家长只能为本人（子女）已发布的成绩提交复核
+INSERT INTO role_permissions (role_id, permission_id)
+SELECT r.id, p.id FROM roles r JOIN permissions p ON p.code = 'appeals:submit'
+WHERE r.name IN ('student', 'parent')
+ON CONFLICT DO NOTHING;
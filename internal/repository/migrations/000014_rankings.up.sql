@@ -0,0 +1,10 @@
+-- 成绩排名权限。学生和家长默认不开放，学校可通过角色权限接口为 student、parent 角色授予该权限，
+-- 授权后学生和家长只能查看本人（子女）的名次
+INSERT INTO permissions (code, description) VALUES
+	('rankings:read', '查看成绩排名')
+ON CONFLICT (code) DO NOTHING;
+
+INSERT INTO role_permissions (role_id, permission_id)
+SELECT r.id, p.id FROM roles r JOIN permissions p ON p.code = 'rankings:read'
+WHERE r.name IN ('admin', 'teacher')
+ON CONFLICT DO NOTHING;
@@ -0,0 +1,3 @@
+DELETE FROM permissions WHERE code IN ('timetable:read', 'timetable:write');
+DROP TABLE IF EXISTS timetable_slots;
+DROP TABLE IF EXISTS rooms;
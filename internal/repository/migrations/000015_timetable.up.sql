@@ -0,0 +1,65 @@
+-- 教室
+CREATE TABLE IF NOT EXISTS rooms (
+	id SERIAL PRIMARY KEY,
+	code VARCHAR(20) NOT NULL UNIQUE,
+	name VARCHAR(50) NOT NULL,
+	building VARCHAR(50),
+	room_type VARCHAR(20) NOT NULL DEFAULT 'classroom'
+		CHECK (room_type IN ('classroom', 'lab', 'computer_lab', 'lecture_hall', 'gym', 'other')),
+	capacity INTEGER NOT NULL CHECK (capacity > 0),
+	status VARCHAR(20) NOT NULL DEFAULT 'active' CHECK (status IN ('active', 'inactive')),
+	created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+	updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+DROP TRIGGER IF EXISTS update_rooms_updated_at ON rooms;
+CREATE TRIGGER update_rooms_updated_at
+	BEFORE UPDATE ON rooms
+	FOR EACH ROW
+	EXECUTE FUNCTION update_updated_at_column();
+
+-- 课表时段：开课每周固定某天的连续若干节（start_period 至 end_period），在某教室由某位老师上课。
+-- 教师、教室和行政班的时间冲突由服务层在同一事务中检查
+CREATE TABLE IF NOT EXISTS timetable_slots (
+	id SERIAL PRIMARY KEY,
+	offering_id INTEGER NOT NULL REFERENCES course_offerings(id) ON DELETE CASCADE,
+	room_id INTEGER NOT NULL REFERENCES rooms(id) ON DELETE RESTRICT,
+	teacher_id INTEGER REFERENCES teachers(id) ON DELETE SET NULL,
+	class_id INTEGER REFERENCES classes(id) ON DELETE SET NULL,
+	day_of_week SMALLINT NOT NULL CHECK (day_of_week BETWEEN 1 AND 7),
+	start_period SMALLINT NOT NULL CHECK (start_period >= 1),
+	end_period SMALLINT NOT NULL,
+	note VARCHAR(200),
+	created_by INTEGER REFERENCES admins(id) ON DELETE SET NULL,
+	created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+	updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+	CHECK (end_period >= start_period)
+);
+
+CREATE INDEX IF NOT EXISTS idx_timetable_slots_offering_id ON timetable_slots(offering_id);
+CREATE INDEX IF NOT EXISTS idx_timetable_slots_room_day ON timetable_slots(room_id, day_of_week);
+CREATE INDEX IF NOT EXISTS idx_timetable_slots_teacher_day ON timetable_slots(teacher_id, day_of_week);
+CREATE INDEX IF NOT EXISTS idx_timetable_slots_class_day ON timetable_slots(class_id, day_of_week);
+
+DROP TRIGGER IF EXISTS update_timetable_slots_updated_at ON timetable_slots;
+CREATE TRIGGER update_timetable_slots_updated_at
+	BEFORE UPDATE ON timetable_slots
+	FOR EACH ROW
+	EXECUTE FUNCTION update_updated_at_column();
+
+-- 课表权限
+INSERT INTO permissions (code, description) VALUES
+	('timetable:read', '查看教室和课表'),
+	('timetable:write', '管理教室和排课')
+ON CONFLICT (code) DO NOTHING;
+
+INSERT INTO role_permissions (role_id, permission_id)
+SELECT r.id, p.id FROM roles r JOIN permissions p ON p.code IN ('timetable:read', 'timetable:write')
+WHERE r.name = 'admin'
+ON CONFLICT DO NOTHING;
+
+-- 学生和家长只能查看本人（子女）的课表，由服务层进一步限定范围
+INSERT INTO role_permissions (role_id, permission_id)
+SELECT r.id, p.id FROM roles r JOIN permissions p ON p.code = 'timetable:read'
+WHERE r.name IN ('teacher', 'student', 'parent')
+ON CONFLICT DO NOTHING;
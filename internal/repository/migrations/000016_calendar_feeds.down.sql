@@ -0,0 +1,4 @@
+DELETE FROM permissions WHERE code IN ('exams:write', 'calendar:subscribe');
+DROP TABLE IF EXISTS calendar_feeds;
+DROP TABLE IF EXISTS exams;
+DROP TABLE IF EXISTS term_holidays;
@@ -0,0 +1,71 @@
+-- 学期内的节假日，日历订阅中对应日期的课程作为重复规则的例外（EXDATE）
+CREATE TABLE IF NOT EXISTS term_holidays (
+	id SERIAL PRIMARY KEY,
+	term_id INTEGER NOT NULL REFERENCES terms(id) ON DELETE CASCADE,
+	name VARCHAR(50) NOT NULL,
+	start_date DATE NOT NULL,
+	end_date DATE NOT NULL,
+	created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+	CHECK (end_date >= start_date)
+);
+
+CREATE INDEX IF NOT EXISTS idx_term_holidays_term_id ON term_holidays(term_id);
+
+-- 考试安排，考试类型与成绩的考试类型一致，时间为学校所在时区的当地时间
+CREATE TABLE IF NOT EXISTS exams (
+	id SERIAL PRIMARY KEY,
+	offering_id INTEGER NOT NULL REFERENCES course_offerings(id) ON DELETE CASCADE,
+	exam_type VARCHAR(20) NOT NULL DEFAULT 'final' CHECK (exam_type IN ('quiz', 'midterm', 'final')),
+	exam_date DATE NOT NULL,
+	start_time TIME NOT NULL,
+	end_time TIME NOT NULL,
+	room_id INTEGER REFERENCES rooms(id) ON DELETE SET NULL,
+	location VARCHAR(100),
+	note VARCHAR(200),
+	created_by INTEGER REFERENCES admins(id) ON DELETE SET NULL,
+	created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+	updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+	CHECK (end_time > start_time)
+);
+
+CREATE INDEX IF NOT EXISTS idx_exams_offering_id ON exams(offering_id);
+CREATE INDEX IF NOT EXISTS idx_exams_room_date ON exams(room_id, exam_date);
+
+DROP TRIGGER IF EXISTS update_exams_updated_at ON exams;
+CREATE TRIGGER update_exams_updated_at
+	BEFORE UPDATE ON exams
+	FOR EACH ROW
+	EXECUTE FUNCTION update_updated_at_column();
+
+-- 日历订阅：订阅地址中的令牌只保存哈希，与接口使用的JWT相互独立，撤销后立即失效
+CREATE TABLE IF NOT EXISTS calendar_feeds (
+	id SERIAL PRIMARY KEY,
+	token_hash CHAR(64) NOT NULL UNIQUE,
+	owner_type VARCHAR(20) NOT NULL CHECK (owner_type IN ('teacher', 'class', 'student')),
+	owner_id INTEGER NOT NULL,
+	name VARCHAR(50),
+	created_by INTEGER NOT NULL REFERENCES admins(id) ON DELETE CASCADE,
+	last_used_at TIMESTAMP,
+	revoked_at TIMESTAMP,
+	created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_calendar_feeds_created_by ON calendar_feeds(created_by);
+CREATE INDEX IF NOT EXISTS idx_calendar_feeds_owner ON calendar_feeds(owner_type, owner_id);
+
+-- 考试安排和日历订阅权限
+INSERT INTO permissions (code, description) VALUES
+	('exams:write', '管理考试安排'),
+	('calendar:subscribe', '创建和撤销日历订阅')
+ON CONFLICT (code) DO NOTHING;
+
+INSERT INTO role_permissions (role_id, permission_id)
+SELECT r.id, p.id FROM roles r JOIN permissions p ON p.code IN ('exams:write', 'calendar:subscribe')
+WHERE r.name = 'admin'
+ON CONFLICT DO NOTHING;
+
+-- 教师只能订阅本人和班级的日历，学生和家长只能订阅本人（子女）的日历，由服务层进一步限定范围
+INSERT INTO role_permissions (role_id, permission_id)
+SELECT r.id, p.id FROM roles r JOIN permissions p ON p.code = 'calendar:subscribe'
+WHERE r.name IN ('teacher', 'student', 'parent')
+ON CONFLICT DO NOTHING;
@@ -0,0 +1,3 @@
+DELETE FROM permissions WHERE code IN ('attendance:read', 'attendance:write');
+DROP TABLE IF EXISTS attendance_records;
+DROP TABLE IF EXISTS attendance_sessions;
@@ -0,0 +1,63 @@
+-- 考勤课次：开课在某天的一次课（start_period 至 end_period）。按课表时段记录时同时记录上课的行政班，
+-- 同一开课同一天同一开始节次只有一条，重复提交时覆盖原有考勤
+CREATE TABLE IF NOT EXISTS attendance_sessions (
+	id SERIAL PRIMARY KEY,
+	offering_id INTEGER NOT NULL REFERENCES course_offerings(id) ON DELETE CASCADE,
+	slot_id INTEGER REFERENCES timetable_slots(id) ON DELETE SET NULL,
+	class_id INTEGER REFERENCES classes(id) ON DELETE SET NULL,
+	session_date DATE NOT NULL,
+	start_period SMALLINT NOT NULL CHECK (start_period >= 1),
+	end_period SMALLINT NOT NULL,
+	recorded_by INTEGER REFERENCES admins(id) ON DELETE SET NULL,
+	created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+	updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+	CHECK (end_period >= start_period),
+	UNIQUE (offering_id, session_date, start_period)
+);
+
+CREATE INDEX IF NOT EXISTS idx_attendance_sessions_session_date ON attendance_sessions(session_date);
+CREATE INDEX IF NOT EXISTS idx_attendance_sessions_class_id ON attendance_sessions(class_id);
+
+DROP TRIGGER IF EXISTS update_attendance_sessions_updated_at ON attendance_sessions;
+CREATE TRIGGER update_attendance_sessions_updated_at
+	BEFORE UPDATE ON attendance_sessions
+	FOR EACH ROW
+	EXECUTE FUNCTION update_updated_at_column();
+
+-- 考勤记录：学生在一次课的出勤状态
+CREATE TABLE IF NOT EXISTS attendance_records (
+	id SERIAL PRIMARY KEY,
+	session_id INTEGER NOT NULL REFERENCES attendance_sessions(id) ON DELETE CASCADE,
+	student_id INTEGER NOT NULL REFERENCES students(id) ON DELETE CASCADE,
+	status VARCHAR(10) NOT NULL CHECK (status IN ('present', 'late', 'absent', 'excused', 'sick')),
+	note VARCHAR(200),
+	created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+	updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+	UNIQUE (session_id, student_id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_attendance_records_student_id ON attendance_records(student_id);
+
+DROP TRIGGER IF EXISTS update_attendance_records_updated_at ON attendance_records;
+CREATE TRIGGER update_attendance_records_updated_at
+	BEFORE UPDATE ON attendance_records
+	FOR EACH ROW
+	EXECUTE FUNCTION update_updated_at_column();
+
+-- 考勤权限
+INSERT INTO permissions (code, description) VALUES
+	('attendance:read', '查看考勤'),
+	('attendance:write', '记录考勤')
+ON CONFLICT (code) DO NOTHING;
+
+-- 教师只能记录本人任教课程的考勤，由服务层进一步限定范围
+INSERT INTO role_permissions (role_id, permission_id)
+SELECT r.id, p.id FROM roles r JOIN permissions p ON p.code IN ('attendance:read', 'attendance:write')
+WHERE r.name IN ('admin', 'teacher')
+ON CONFLICT DO NOTHING;
+
+-- 学生和家长只能查看本人（子女）的考勤汇总
+INSERT INTO role_permissions (role_id, permission_id)
+SELECT r.id, p.id FROM roles r JOIN permissions p ON p.code = 'attendance:read'
+WHERE r.name IN ('student', 'parent')
+ON CONFLICT DO NOTHING;
@@ -0,0 +1,3 @@
+DELETE FROM permissions WHERE code IN ('leaves:submit', 'leaves:approve');
+DROP TABLE IF EXISTS leave_approvals;
+DROP TABLE IF EXISTS leave_requests;
@@ -0,0 +1,67 @@
+-- 请假申请：学生或家长提交，按请假天数确定审批链（approval_chain），依次由班主任或管理员审批，
+-- current_step 为当前待审批的步骤。状态：pending 审批中 -> approved 已批准 / rejected 已驳回 / cancelled 已撤销
+CREATE TABLE IF NOT EXISTS leave_requests (
+	id SERIAL PRIMARY KEY,
+	student_id INTEGER NOT NULL REFERENCES students(id) ON DELETE CASCADE,
+	leave_type VARCHAR(20) NOT NULL CHECK (leave_type IN ('personal', 'sick', 'other')),
+	start_date DATE NOT NULL,
+	end_date DATE NOT NULL,
+	days INTEGER NOT NULL CHECK (days >= 1),
+	reason TEXT NOT NULL,
+	attachments TEXT[] NOT NULL DEFAULT '{}',
+	status VARCHAR(20) NOT NULL DEFAULT 'pending'
+		CHECK (status IN ('pending', 'approved', 'rejected', 'cancelled')),
+	approval_chain TEXT[] NOT NULL,
+	current_step INTEGER NOT NULL DEFAULT 0,
+	submitted_by INTEGER REFERENCES admins(id) ON DELETE SET NULL,
+	excused_sessions INTEGER NOT NULL DEFAULT 0,
+	created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+	updated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+	decided_at TIMESTAMP,
+	CHECK (end_date >= start_date)
+);
+
+CREATE INDEX IF NOT EXISTS idx_leave_requests_student_dates ON leave_requests(student_id, start_date, end_date);
+CREATE INDEX IF NOT EXISTS idx_leave_requests_status ON leave_requests(status);
+
+DROP TRIGGER IF EXISTS update_leave_requests_updated_at ON leave_requests;
+CREATE TRIGGER update_leave_requests_updated_at
+	BEFORE UPDATE ON leave_requests
+	FOR EACH ROW
+	EXECUTE FUNCTION update_updated_at_column();
+
+-- 请假审批记录：审批链中每一步的审批意见，只追加不修改
+CREATE TABLE IF NOT EXISTS leave_approvals (
+	id SERIAL PRIMARY KEY,
+	leave_id INTEGER NOT NULL REFERENCES leave_requests(id) ON DELETE CASCADE,
+	step INTEGER NOT NULL,
+	approver VARCHAR(20) NOT NULL CHECK (approver IN ('homeroom', 'admin')),
+	decision VARCHAR(20) NOT NULL CHECK (decision IN ('approved', 'rejected')),
+	comment TEXT,
+	actor_id INTEGER REFERENCES admins(id) ON DELETE SET NULL,
+	created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+	UNIQUE (leave_id, step)
+);
+
+-- 请假权限，查看请假沿用 attendance:read
+INSERT INTO permissions (code, description) VALUES
+	('leaves:submit', '提交请假申请'),
+	('leaves:approve', '审批请假申请')
+ON CONFLICT (code) DO NOTHING;
+
+INSERT INTO role_permissions (role_id, permission_id)
+SELECT r.id, p.id FROM roles r JOIN permissions p ON p.code IN ('leaves:submit', 'leaves:approve')
+WHERE r.name = 'admin'
+ON CONFLICT DO NOTHING;
+
+-- 教师只能审批本班学生（班主任）的请假，由服务层进一步限定范围
+INSERT INTO role_permissions (role_id, permission_id)
+SELECT r.id, p.id FROM roles r JOIN permissions p ON p.code = 'leaves:approve'
+WHERE r.name = 'teacher'
+ON CONFLICT DO NOTHING;
+
+-- 学生和家长只能为本人（子女）请假
+INSERT INTO role_permissions (role_id, permission_id)
+SELECT r.id, p.id FROM roles r JOIN permissions p ON p.code = 'leaves:submit'
+WHERE r.name IN ('student', 'parent')
+ON CONFLICT DO NOTHING;
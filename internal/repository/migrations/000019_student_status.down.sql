@@ -0,0 +1,6 @@
+DROP TABLE IF EXISTS student_status_history;
+ALTER TABLE students DROP CONSTRAINT IF EXISTS students_status_check;
+ALTER TABLE students ALTER COLUMN status DROP NOT NULL;
+ALTER TABLE students ALTER COLUMN status SET DEFAULT 'active';
+UPDATE students SET status = 'active' WHERE status IN ('enrolled', 'resumed');
+UPDATE students SET status = 'inactive' WHERE status IN ('suspended', 'transferred_out', 'withdrawn', 'expelled');
@@ -0,0 +1,29 @@
+-- 学籍状态：原 active/inactive 分别迁移为在读/休学，其他无法识别的值按在读处理。
+-- 状态流转由服务层按规则校验：enrolled 在读、suspended 休学、resumed 复学、
+-- transferred_out 转出、withdrawn 退学、graduated 毕业、expelled 开除学籍
+UPDATE students SET status = 'suspended' WHERE status = 'inactive';
+UPDATE students SET status = 'enrolled'
+WHERE status IS NULL
+	OR status NOT IN ('suspended', 'resumed', 'transferred_out', 'withdrawn', 'graduated', 'expelled');
+
+ALTER TABLE students ALTER COLUMN status SET DEFAULT 'enrolled';
+ALTER TABLE students ALTER COLUMN status SET NOT NULL;
+ALTER TABLE students DROP CONSTRAINT IF EXISTS students_status_check;
+ALTER TABLE students ADD CONSTRAINT students_status_check
+	CHECK (status IN ('enrolled', 'suspended', 'resumed', 'transferred_out', 'withdrawn', 'graduated', 'expelled'));
+
+-- 学籍异动记录：每次状态变更的原因、批准文件、生效日期及操作人，只追加不修改
+CREATE TABLE IF NOT EXISTS student_status_history (
+	id SERIAL PRIMARY KEY,
+	student_id INTEGER NOT NULL REFERENCES students(id) ON DELETE CASCADE,
+	from_status VARCHAR(20) NOT NULL,
+	to_status VARCHAR(20) NOT NULL,
+	reason TEXT NOT NULL,
+	document_no VARCHAR(100),
+	attachments TEXT[] NOT NULL DEFAULT '{}',
+	effective_date DATE NOT NULL,
+	actor_id INTEGER REFERENCES admins(id) ON DELETE SET NULL,
+	created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_student_status_history_student ON student_status_history(student_id, effective_date);
@@ -0,0 +1,3 @@
+DELETE FROM role_permissions
+WHERE permission_id IN (SELECT id FROM permissions WHERE code = 'rankings:read')
+	AND role_id IN (SELECT id FROM roles WHERE name IN ('student', 'parent'));
@@ -0,0 +1,5 @@
+-- 学生和家长可以查看本人（子女）的名次，服务层只返回指定学生的名次，不包含其他学生的信息
+INSERT INTO role_permissions (role_id, permission_id)
+SELECT r.id, p.id FROM roles r JOIN permissions p ON p.code = 'rankings:read'
+WHERE r.name IN ('student', 'parent')
+ON CONFLICT DO NOTHING;
@@ -0,0 +1,108 @@
+package repository
+
+import (
+	"database/sql"
+	"fmt"
+	"strings"
+
+	"student-management-system/internal/domain"
+
+	"github.com/lib/pq"
+)
+
+// RankingRepository 成绩排名仓储接口
+type RankingRepository interface {
+	ListCourseScores(filter *domain.RankingFilter) ([]*domain.RankingCourseScore, error)
+}
+
+// rankingRepository 成绩排名仓储实现
+type rankingRepository struct {
+	db *sql.DB
+}
+
+// NewRankingRepository 创建成绩排名仓储实例
+func NewRankingRepository(db *sql.DB) RankingRepository {
+	return &rankingRepository{db: db}
+}
+
+// ListCourseScores 获取范围内学生在指定学期的课程成绩。课程成绩与成绩单一致：各项成绩均已发布时取总评成绩，
+// 否则取已发布的期末成绩，两者都没有的课程不计入。学生的班级和年级按参考日期所在的班级确定
+func (r *rankingRepository) ListCourseScores(filter *domain.RankingFilter) ([]*domain.RankingCourseScore, error) {
+	conditions := []string{"cs.score IS NOT NULL"}
+	args := []interface{}{filter.Semester, pq.Array(domain.PublishedScoreStatuses), filter.ReferenceDate}
+	argIndex := 4
+
+	if filter.ClassID > 0 {
+		conditions = append(conditions, fmt.Sprintf("c.id = $%d", argIndex))
+		args = append(args, filter.ClassID)
+		argIndex++
+	}
+	if filter.Major != "" {
+		conditions = append(conditions, fmt.Sprintf("st.major = $%d", argIndex))
+		args = append(args, filter.Major)
+		argIndex++
+	}
+	if filter.GradeYear > 0 {
+		conditions = append(conditions, fmt.Sprintf("c.grade_year = $%d", argIndex))
+		args = append(args, filter.GradeYear)
+		argIndex++
+	}
+	if filter.SubjectID > 0 {
+		conditions = append(conditions, fmt.Sprintf("cs.subject_id = $%d", argIndex))
+		args = append(args, filter.SubjectID)
+		argIndex++
+	}
+
+	query := `
+		WITH course AS (
+			SELECT s.student_id, s.subject_id,
+			       MAX(s.score) FILTER (WHERE s.exam_type = 'final' AND s.status = ANY($2)) AS final_score,
+			       BOOL_AND(s.status = ANY($2)) AS all_published
+			FROM scores s
+			WHERE s.semester = $1
+			GROUP BY s.student_id, s.subject_id
+		), cs AS (
+			SELECT course.student_id, course.subject_id,
+			       COALESCE(CASE WHEN course.all_published THEN fg.composite_score END, course.final_score) AS score
+			FROM course
+			LEFT JOIN final_grades fg
+			       ON fg.student_id = course.student_id AND fg.subject_id = course.subject_id AND fg.semester = $1
+		)
+		SELECT st.id, st.student_id, st.name, st.major, c.id, COALESCE(c.name, ''),
+		       sub.id, sub.name, sub.credits, cs.score
+		FROM cs
+		JOIN students st ON cs.student_id = st.id
+		JOIN subjects sub ON cs.subject_id = sub.id
+		LEFT JOIN class_memberships cm
+		       ON cm.student_id = st.id AND cm.joined_at <= $3 AND (cm.left_at IS NULL OR cm.left_at > $3)
+		LEFT JOIN classes c ON cm.class_id = c.id
+		WHERE ` + strings.Join(conditions, " AND ") + `
+		ORDER BY st.student_id, sub.code`
+
+	rows, err := r.db.Query(query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query ranking scores: %w", err)
+	}
+	defer rows.Close()
+
+	var scores []*domain.RankingCourseScore
+	for rows.Next() {
+		score := &domain.RankingCourseScore{}
+		var classID sql.NullInt64
+		if err := rows.Scan(&score.StudentID, &score.StudentNo, &score.StudentName, &score.Major, &classID, &score.ClassName,
+			&score.SubjectID, &score.SubjectName, &score.Credits, &score.Score); err != nil {
+			return nil, fmt.Errorf("failed to scan ranking score: %w", err)
+		}
+		if classID.Valid {
+			id := int(classID.Int64)
+			score.ClassID = &id
+		}
+		scores = append(scores, score)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to iterate ranking scores: %w", err)
+	}
+
+	return scores, nil
+}
@@ -0,0 +1,170 @@
+package repository
+
+import (
+	"database/sql"
+	"fmt"
+	"student-management-system/internal/domain"
+	"student-management-system/pkg/logger"
+
+	"github.com/lib/pq"
+)
+
+// RoleRepository 角色权限仓储接口
+type RoleRepository interface {
+	ListRoles() ([]*domain.Role, error)
+	ListPermissions() ([]*domain.Permission, error)
+	GetRolePermissions() (map[string][]string, error)
+	SetRolePermissions(role string, permissions []string) error
+}
+
+// roleRepository 角色权限仓储实现
+type roleRepository struct {
+	db *sql.DB
+}
+
+// NewRoleRepository 创建角色权限仓储实例
+func NewRoleRepository(db *sql.DB) RoleRepository {
+	return &roleRepository{db: db}
+}
+
+// ListRoles 获取所有角色及其权限
+func (r *roleRepository) ListRoles() ([]*domain.Role, error) {
+	query := `
+		SELECT r.id, r.name, COALESCE(r.description, ''),
+		       COALESCE(array_agg(p.code ORDER BY p.code) FILTER (WHERE p.code IS NOT NULL), '{}')
+		FROM roles r
+		LEFT JOIN role_permissions rp ON rp.role_id = r.id
+		LEFT JOIN permissions p ON p.id = rp.permission_id
+		GROUP BY r.id, r.name, r.description
+		ORDER BY r.id
+	`
+
+	rows, err := r.db.Query(query)
+	if err != nil {
+		logger.WithError(err).Error("Failed to query roles")
+		return nil, fmt.Errorf("failed to query roles: %w", err)
+	}
+	defer rows.Close()
+
+	var roles []*domain.Role
+	for rows.Next() {
+		role := &domain.Role{}
+		if err := rows.Scan(&role.ID, &role.Name, &role.Description, pq.Array(&role.Permissions)); err != nil {
+			return nil, fmt.Errorf("failed to scan role: %w", err)
+		}
+		roles = append(roles, role)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to iterate roles: %w", err)
+	}
+
+	return roles, nil
+}
+
+// ListPermissions 获取所有权限
+func (r *roleRepository) ListPermissions() ([]*domain.Permission, error) {
+	rows, err := r.db.Query(`SELECT id, code, COALESCE(description, '') FROM permissions ORDER BY code`)
+	if err != nil {
+		logger.WithError(err).Error("Failed to query permissions")
+		return nil, fmt.Errorf("failed to query permissions: %w", err)
+	}
+	defer rows.Close()
+
+	var permissions []*domain.Permission
+	for rows.Next() {
+		permission := &domain.Permission{}
+		if err := rows.Scan(&permission.ID, &permission.Code, &permission.Description); err != nil {
+			return nil, fmt.Errorf("failed to scan permission: %w", err)
+		}
+		permissions = append(permissions, permission)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to iterate permissions: %w", err)
+	}
+
+	return permissions, nil
+}
+
+// GetRolePermissions 获取角色到权限代码的映射
+func (r *roleRepository) GetRolePermissions() (map[string][]string, error) {
+	query := `
+		SELECT r.name, p.code
+		FROM role_permissions rp
+		JOIN roles r ON r.id = rp.role_id
+		JOIN permissions p ON p.id = rp.permission_id
+	`
+
+	rows, err := r.db.Query(query)
+	if err != nil {
+		logger.WithError(err).Error("Failed to query role permissions")
+		return nil, fmt.Errorf("failed to query role permissions: %w", err)
+	}
+	defer rows.Close()
+
+	result := make(map[string][]string)
+	for rows.Next() {
+		var role, code string
+		if err := rows.Scan(&role, &code); err != nil {
+			return nil, fmt.Errorf("failed to scan role permission: %w", err)
+		}
+		result[role] = append(result[role], code)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to iterate role permissions: %w", err)
+	}
+
+	return result, nil
+}
+
+// SetRolePermissions 替换角色的全部权限
+func (r *roleRepository) SetRolePermissions(role string, permissions []string) error {
+	logger.WithFields(map[string]interface{}{
+		"role":        role,
+		"permissions": permissions,
+	}).Info("Setting role permissions")
+
+	tx, err := r.db.Begin()
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	var roleID int
+	err = tx.QueryRow(`SELECT id FROM roles WHERE name = $1`, role).Scan(&roleID)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return fmt.Errorf("role not found")
+		}
+		return fmt.Errorf("failed to get role: %w", err)
+	}
+
+	var known int
+	err = tx.QueryRow(`SELECT COUNT(*) FROM permissions WHERE code = ANY($1)`, pq.Array(permissions)).Scan(&known)
+	if err != nil {
+		return fmt.Errorf("failed to check permissions: %w", err)
+	}
+	if known != len(permissions) {
+		return fmt.Errorf("unknown permission in %v", permissions)
+	}
+
+	if _, err := tx.Exec(`DELETE FROM role_permissions WHERE role_id = $1`, roleID); err != nil {
+		return fmt.Errorf("failed to clear role permissions: %w", err)
+	}
+
+	_, err = tx.Exec(`
+		INSERT INTO role_permissions (role_id, permission_id)
+		SELECT $1, id FROM permissions WHERE code = ANY($2)
+	`, roleID, pq.Array(permissions))
+	if err != nil {
+		return fmt.Errorf("failed to insert role permissions: %w", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit role permissions: %w", err)
+	}
+
+	return nil
+}
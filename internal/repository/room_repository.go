@@ -0,0 +1,217 @@
+package repository
+
+import (
+	"database/sql"
+	"fmt"
+	"strings"
+
+	"student-management-system/internal/domain"
+	"student-management-system/pkg/logger"
+)
+
+// RoomRepository 教室仓储接口
+type RoomRepository interface {
+	Create(room *domain.Room) error
+	GetByID(id int) (*domain.Room, error)
+	Update(room *domain.Room) error
+	Delete(id int) error
+	List(req *domain.RoomListRequest) ([]*domain.Room, int64, error)
+	ListActive() ([]*domain.Room, error)
+	ExistsByCode(code string, excludeID int) (bool, error)
+	HasSlots(roomID int) (bool, error)
+}
+
+// roomRepository 教室仓储实现
+type roomRepository struct {
+	db *sql.DB
+}
+
+// NewRoomRepository 创建教室仓储实例
+func NewRoomRepository(db *sql.DB) RoomRepository {
+	return &roomRepository{db: db}
+}
+
+// roomSelect 教室查询的字段
+const roomSelect = `
+		SELECT id, code, name, COALESCE(building, ''), room_type, capacity, status, created_at, updated_at
+		FROM rooms`
+
+// Create 创建教室
+func (r *roomRepository) Create(room *domain.Room) error {
+	logger.WithFields(map[string]interface{}{
+		"code": room.Code,
+	}).Info("Creating room")
+
+	query := `
+		INSERT INTO rooms (code, name, building, room_type, capacity, status)
+		VALUES ($1, $2, NULLIF($3, ''), $4, $5, $6)
+		RETURNING id, created_at, updated_at
+	`
+
+	err := r.db.QueryRow(query, room.Code, room.Name, room.Building, room.RoomType, room.Capacity, room.Status).
+		Scan(&room.ID, &room.CreatedAt, &room.UpdatedAt)
+	if err != nil {
+		logger.WithError(err).Error("Failed to create room")
+		return fmt.Errorf("failed to create room: %w", err)
+	}
+
+	return nil
+}
+
+// GetByID 根据ID获取教室，不存在时返回 nil
+func (r *roomRepository) GetByID(id int) (*domain.Room, error) {
+	room, err := scanRoom(r.db.QueryRow(roomSelect+` WHERE id = $1`, id))
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get room: %w", err)
+	}
+	return room, nil
+}
+
+// Update 更新教室
+func (r *roomRepository) Update(room *domain.Room) error {
+	logger.WithFields(map[string]interface{}{
+		"room_id": room.ID,
+	}).Info("Updating room")
+
+	query := `
+		UPDATE rooms
+		SET code = $2, name = $3, building = NULLIF($4, ''), room_type = $5, capacity = $6, status = $7
+		WHERE id = $1
+		RETURNING updated_at
+	`
+
+	err := r.db.QueryRow(query, room.ID, room.Code, room.Name, room.Building, room.RoomType, room.Capacity, room.Status).
+		Scan(&room.UpdatedAt)
+	if err != nil {
+		logger.WithError(err).Error("Failed to update room")
+		return fmt.Errorf("failed to update room: %w", err)
+	}
+
+	return nil
+}
+
+// Delete 删除教室
+func (r *roomRepository) Delete(id int) error {
+	logger.WithFields(map[string]interface{}{
+		"room_id": id,
+	}).Info("Deleting room")
+
+	if _, err := r.db.Exec(`DELETE FROM rooms WHERE id = $1`, id); err != nil {
+		logger.WithError(err).Error("Failed to delete room")
+		return fmt.Errorf("failed to delete room: %w", err)
+	}
+
+	return nil
+}
+
+// List 获取教室列表
+func (r *roomRepository) List(req *domain.RoomListRequest) ([]*domain.Room, int64, error) {
+	var conditions []string
+	var args []interface{}
+	argIndex := 1
+
+	if req.Building != "" {
+		conditions = append(conditions, fmt.Sprintf("building = $%d", argIndex))
+		args = append(args, req.Building)
+		argIndex++
+	}
+
+	if req.RoomType != "" {
+		conditions = append(conditions, fmt.Sprintf("room_type = $%d", argIndex))
+		args = append(args, req.RoomType)
+		argIndex++
+	}
+
+	if req.Status != "" {
+		conditions = append(conditions, fmt.Sprintf("status = $%d", argIndex))
+		args = append(args, req.Status)
+		argIndex++
+	}
+
+	if req.MinCapacity > 0 {
+		conditions = append(conditions, fmt.Sprintf("capacity >= $%d", argIndex))
+		args = append(args, req.MinCapacity)
+		argIndex++
+	}
+
+	whereClause := ""
+	if len(conditions) > 0 {
+		whereClause = " WHERE " + strings.Join(conditions, " AND ")
+	}
+
+	var total int64
+	if err := r.db.QueryRow(`SELECT COUNT(*) FROM rooms`+whereClause, args...).Scan(&total); err != nil {
+		return nil, 0, fmt.Errorf("failed to count rooms: %w", err)
+	}
+
+	query := fmt.Sprintf(`%s%s ORDER BY code LIMIT $%d OFFSET $%d`, roomSelect, whereClause, argIndex, argIndex+1)
+	args = append(args, req.Size, (req.Page-1)*req.Size)
+
+	rooms, err := r.queryRooms(query, args...)
+	if err != nil {
+		return nil, 0, err
+	}
+	return rooms, total, nil
+}
+
+// ListActive 获取全部可用教室，按容量从小到大排列
+func (r *roomRepository) ListActive() ([]*domain.Room, error) {
+	return r.queryRooms(roomSelect+` WHERE status = $1 ORDER BY capacity, code`, domain.RoomStatusActive)
+}
+
+// ExistsByCode 检查教室编号是否已被使用
+func (r *roomRepository) ExistsByCode(code string, excludeID int) (bool, error) {
+	var exists bool
+	err := r.db.QueryRow(`SELECT EXISTS (SELECT 1 FROM rooms WHERE code = $1 AND id <> $2)`, code, excludeID).Scan(&exists)
+	if err != nil {
+		return false, fmt.Errorf("failed to check room code: %w", err)
+	}
+	return exists, nil
+}
+
+// HasSlots 教室是否已排课
+func (r *roomRepository) HasSlots(roomID int) (bool, error) {
+	var exists bool
+	err := r.db.QueryRow(`SELECT EXISTS (SELECT 1 FROM timetable_slots WHERE room_id = $1)`, roomID).Scan(&exists)
+	if err != nil {
+		return false, fmt.Errorf("failed to check room slots: %w", err)
+	}
+	return exists, nil
+}
+
+// queryRooms 查询教室列表
+func (r *roomRepository) queryRooms(query string, args ...interface{}) ([]*domain.Room, error) {
+	rows, err := r.db.Query(query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query rooms: %w", err)
+	}
+	defer rows.Close()
+
+	var rooms []*domain.Room
+	for rows.Next() {
+		room, err := scanRoom(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan room: %w", err)
+		}
+		rooms = append(rooms, room)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to iterate rooms: %w", err)
+	}
+
+	return rooms, nil
+}
+
+// scanRoom 扫描教室查询的一行
+func scanRoom(row rowScanner) (*domain.Room, error) {
+	room := &domain.Room{}
+	if err := row.Scan(&room.ID, &room.Code, &room.Name, &room.Building, &room.RoomType, &room.Capacity, &room.Status,
+		&room.CreatedAt, &room.UpdatedAt); err != nil {
+		return nil, err
+	}
+	return room, nil
+}
@@ -0,0 +1,263 @@
+package repository
+
+import (
+	"database/sql"
+	"fmt"
+	"strings"
+
+	"student-management-system/internal/domain"
+	"student-management-system/pkg/logger"
+
+	"github.com/lib/pq"
+)
+
+// ScoreAppealRepository 成绩复核申请仓储接口
+type ScoreAppealRepository interface {
+	Create(appeal *domain.ScoreAppeal) error
+	GetByID(id int) (*domain.ScoreAppeal, error)
+	HasOpenAppeal(scoreID int) (bool, error)
+	List(req *domain.ScoreAppealListRequest) ([]*domain.ScoreAppeal, int64, error)
+	StartReview(id, reviewerID int) (bool, error)
+	Resolve(appeal *domain.ScoreAppeal, score *domain.Score, history *domain.ScoreHistory) (bool, error)
+}
+
+// scoreAppealRepository 成绩复核申请仓储实现
+type scoreAppealRepository struct {
+	db *sql.DB
+}
+
+// NewScoreAppealRepository 创建成绩复核申请仓储实例
+func NewScoreAppealRepository(db *sql.DB) ScoreAppealRepository {
+	return &scoreAppealRepository{db: db}
+}
+
+// Create 创建成绩复核申请
+func (r *scoreAppealRepository) Create(appeal *domain.ScoreAppeal) error {
+	logger.WithFields(map[string]interface{}{
+		"score_id":   appeal.ScoreID,
+		"student_id": appeal.StudentID,
+	}).Info("Creating score appeal")
+
+	if appeal.Attachments == nil {
+		appeal.Attachments = []string{}
+	}
+
+	err := r.db.QueryRow(`
+		INSERT INTO score_appeals (score_id, student_id, reason, attachments, status, original_score, assigned_teacher_id, submitted_by)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+		RETURNING id, created_at, updated_at
+	`, appeal.ScoreID, appeal.StudentID, appeal.Reason, pq.Array(appeal.Attachments), appeal.Status,
+		appeal.OriginalScore, appeal.AssignedTeacherID, appeal.SubmittedBy).
+		Scan(&appeal.ID, &appeal.CreatedAt, &appeal.UpdatedAt)
+	if err != nil {
+		return fmt.Errorf("failed to create score appeal: %w", err)
+	}
+	return nil
+}
+
+// scoreAppealSelect 成绩复核申请查询的字段
+const scoreAppealSelect = `
+		SELECT a.id, a.score_id, a.student_id, a.reason, a.attachments, a.status, a.original_score, a.adjusted_score,
+		       a.assigned_teacher_id, a.submitted_by, a.reviewer_id, COALESCE(a.resolution, ''),
+		       a.created_at, a.updated_at, a.resolved_at,
+		       COALESCE(st.name, ''), s.subject_id, COALESCE(sub.name, ''), s.semester, s.exam_type,
+		       COALESCE(t.name, ''), COALESCE(rv.name, '')
+		FROM score_appeals a
+		JOIN scores s ON a.score_id = s.id
+		LEFT JOIN students st ON a.student_id = st.id
+		LEFT JOIN subjects sub ON s.subject_id = sub.id
+		LEFT JOIN teachers t ON a.assigned_teacher_id = t.id
+		LEFT JOIN admins rv ON a.reviewer_id = rv.id`
+
+// GetByID 根据ID获取成绩复核申请，不存在时返回 nil
+func (r *scoreAppealRepository) GetByID(id int) (*domain.ScoreAppeal, error) {
+	appeal, err := scanScoreAppeal(r.db.QueryRow(scoreAppealSelect+` WHERE a.id = $1`, id))
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get score appeal: %w", err)
+	}
+	return appeal, nil
+}
+
+// HasOpenAppeal 成绩是否有未结案的复核申请
+func (r *scoreAppealRepository) HasOpenAppeal(scoreID int) (bool, error) {
+	var exists bool
+	err := r.db.QueryRow(`
+		SELECT EXISTS (SELECT 1 FROM score_appeals WHERE score_id = $1 AND status = ANY($2))
+	`, scoreID, pq.Array(domain.OpenAppealStatuses)).Scan(&exists)
+	if err != nil {
+		return false, fmt.Errorf("failed to check open score appeal: %w", err)
+	}
+	return exists, nil
+}
+
+// List 获取成绩复核申请列表（分页），按提交时间先后排列，便于按顺序处理
+func (r *scoreAppealRepository) List(req *domain.ScoreAppealListRequest) ([]*domain.ScoreAppeal, int64, error) {
+	var conditions []string
+	var args []interface{}
+	argIndex := 1
+
+	if req.Status != "" {
+		conditions = append(conditions, fmt.Sprintf("a.status = $%d", argIndex))
+		args = append(args, req.Status)
+		argIndex++
+	}
+	if len(req.Statuses) > 0 {
+		conditions = append(conditions, fmt.Sprintf("a.status = ANY($%d)", argIndex))
+		args = append(args, pq.Array(req.Statuses))
+		argIndex++
+	}
+	if req.StudentID > 0 {
+		conditions = append(conditions, fmt.Sprintf("a.student_id = $%d", argIndex))
+		args = append(args, req.StudentID)
+		argIndex++
+	}
+	if len(req.StudentIDs) > 0 {
+		conditions = append(conditions, fmt.Sprintf("a.student_id = ANY($%d)", argIndex))
+		args = append(args, pq.Array(req.StudentIDs))
+		argIndex++
+	}
+	if req.SubjectID > 0 {
+		conditions = append(conditions, fmt.Sprintf("s.subject_id = $%d", argIndex))
+		args = append(args, req.SubjectID)
+		argIndex++
+	}
+	if req.AssignedTeacherID > 0 {
+		conditions = append(conditions, fmt.Sprintf("a.assigned_teacher_id = $%d", argIndex))
+		args = append(args, req.AssignedTeacherID)
+		argIndex++
+	}
+
+	whereClause := ""
+	if len(conditions) > 0 {
+		whereClause = " WHERE " + strings.Join(conditions, " AND ")
+	}
+
+	var total int64
+	countQuery := `SELECT COUNT(*) FROM score_appeals a JOIN scores s ON a.score_id = s.id` + whereClause
+	if err := r.db.QueryRow(countQuery, args...).Scan(&total); err != nil {
+		return nil, 0, fmt.Errorf("failed to count score appeals: %w", err)
+	}
+
+	query := fmt.Sprintf(`%s%s ORDER BY a.created_at, a.id LIMIT $%d OFFSET $%d`,
+		scoreAppealSelect, whereClause, argIndex, argIndex+1)
+	args = append(args, req.Size, (req.Page-1)*req.Size)
+
+	rows, err := r.db.Query(query, args...)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to query score appeals: %w", err)
+	}
+	defer rows.Close()
+
+	var appeals []*domain.ScoreAppeal
+	for rows.Next() {
+		appeal, err := scanScoreAppeal(rows)
+		if err != nil {
+			return nil, 0, fmt.Errorf("failed to scan score appeal: %w", err)
+		}
+		appeals = append(appeals, appeal)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, 0, fmt.Errorf("failed to iterate score appeals: %w", err)
+	}
+	return appeals, total, nil
+}
+
+// StartReview 将已提交的复核申请标记为复核中，申请不处于已提交状态时返回 false
+func (r *scoreAppealRepository) StartReview(id, reviewerID int) (bool, error) {
+	result, err := r.db.Exec(`
+		UPDATE score_appeals SET status = $2, reviewer_id = NULLIF($3, 0)
+		WHERE id = $1 AND status = $4
+	`, id, domain.AppealStatusUnderReview, reviewerID, domain.AppealStatusSubmitted)
+	if err != nil {
+		return false, fmt.Errorf("failed to start score appeal review: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return false, fmt.Errorf("failed to get rows affected: %w", err)
+	}
+	return rowsAffected > 0, nil
+}
+
+// Resolve 保存复核结论。调整成绩时传入修改后的成绩及变更记录，与结案在同一事务中保存（见 updateScore）。
+// 申请已结案或成绩已被修改时不做修改并返回 false
+func (r *scoreAppealRepository) Resolve(appeal *domain.ScoreAppeal, score *domain.Score, history *domain.ScoreHistory) (bool, error) {
+	logger.WithFields(map[string]interface{}{
+		"appeal_id": appeal.ID,
+		"status":    appeal.Status,
+	}).Info("Resolving score appeal")
+
+	tx, err := r.db.Begin()
+	if err != nil {
+		return false, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	err = tx.QueryRow(`
+		UPDATE score_appeals
+		SET status = $2, adjusted_score = $3, reviewer_id = $4, resolution = $5, resolved_at = CURRENT_TIMESTAMP
+		WHERE id = $1 AND status = ANY($6)
+		RETURNING updated_at, resolved_at
+	`, appeal.ID, appeal.Status, appeal.AdjustedScore, appeal.ReviewerID, appeal.Resolution,
+		pq.Array(domain.OpenAppealStatuses)).Scan(&appeal.UpdatedAt, &appeal.ResolvedAt)
+	if err == sql.ErrNoRows {
+		return false, nil
+	}
+	if err != nil {
+		return false, fmt.Errorf("failed to resolve score appeal: %w", err)
+	}
+
+	if score != nil {
+		updated, err := updateScore(tx, score, history)
+		if err != nil || !updated {
+			return false, err
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return false, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+	return true, nil
+}
+
+// scanScoreAppeal 扫描成绩复核申请查询的一行
+func scanScoreAppeal(row rowScanner) (*domain.ScoreAppeal, error) {
+	appeal := &domain.ScoreAppeal{}
+	var adjustedScore sql.NullFloat64
+	var assignedTeacherID, submittedBy, reviewerID sql.NullInt64
+	var resolvedAt sql.NullTime
+	if err := row.Scan(&appeal.ID, &appeal.ScoreID, &appeal.StudentID, &appeal.Reason, pq.Array(&appeal.Attachments),
+		&appeal.Status, &appeal.OriginalScore, &adjustedScore,
+		&assignedTeacherID, &submittedBy, &reviewerID, &appeal.Resolution,
+		&appeal.CreatedAt, &appeal.UpdatedAt, &resolvedAt,
+		&appeal.StudentName, &appeal.SubjectID, &appeal.SubjectName, &appeal.Semester, &appeal.ExamType,
+		&appeal.TeacherName, &appeal.ReviewerName); err != nil {
+		return nil, err
+	}
+	if adjustedScore.Valid {
+		appeal.AdjustedScore = &adjustedScore.Float64
+	}
+	if assignedTeacherID.Valid {
+		id := int(assignedTeacherID.Int64)
+		appeal.AssignedTeacherID = &id
+	}
+	if submittedBy.Valid {
+		id := int(submittedBy.Int64)
+		appeal.SubmittedBy = &id
+	}
+	if reviewerID.Valid {
+		id := int(reviewerID.Int64)
+		appeal.ReviewerID = &id
+	}
+	if resolvedAt.Valid {
+		appeal.ResolvedAt = &resolvedAt.Time
+	}
+	if appeal.Attachments == nil {
+		appeal.Attachments = []string{}
+	}
+	return appeal, nil
+}
@@ -0,0 +1,150 @@
+package repository
+
+import (
+	"database/sql"
+	"fmt"
+	"strings"
+
+	"student-management-system/internal/domain"
+)
+
+// ScoreHistoryRepository 成绩变更记录仓储接口。变更记录由成绩仓储在修改成绩的同一事务中写入，这里只提供查询
+type ScoreHistoryRepository interface {
+	List(req *domain.ScoreHistoryListRequest) ([]*domain.ScoreHistory, int64, error)
+	GetScoreSubject(scoreID int) (int, error)
+}
+
+// scoreHistoryRepository 成绩变更记录仓储实现
+type scoreHistoryRepository struct {
+	db *sql.DB
+}
+
+// NewScoreHistoryRepository 创建成绩变更记录仓储实例
+func NewScoreHistoryRepository(db *sql.DB) ScoreHistoryRepository {
+	return &scoreHistoryRepository{db: db}
+}
+
+// scoreHistorySelect 成绩变更记录查询的字段
+const scoreHistorySelect = `
+		SELECT h.id, h.score_id, h.student_id, h.subject_id, h.semester, h.exam_type, h.action,
+		       h.old_score, h.new_score, COALESCE(h.details, ''), COALESCE(h.reason, ''),
+		       h.actor_id, COALESCE(a.name, ''), COALESCE(h.ip_address, ''), h.created_at,
+		       COALESCE(st.name, ''), COALESCE(sub.name, '')
+		FROM score_history h
+		LEFT JOIN admins a ON h.actor_id = a.id
+		LEFT JOIN students st ON h.student_id = st.id
+		LEFT JOIN subjects sub ON h.subject_id = sub.id`
+
+// List 获取成绩变更记录（分页），按时间倒序
+func (r *scoreHistoryRepository) List(req *domain.ScoreHistoryListRequest) ([]*domain.ScoreHistory, int64, error) {
+	var conditions []string
+	var args []interface{}
+	argIndex := 1
+
+	if req.ScoreID > 0 {
+		conditions = append(conditions, fmt.Sprintf("h.score_id = $%d", argIndex))
+		args = append(args, req.ScoreID)
+		argIndex++
+	}
+	if req.StudentID > 0 {
+		conditions = append(conditions, fmt.Sprintf("h.student_id = $%d", argIndex))
+		args = append(args, req.StudentID)
+		argIndex++
+	}
+	if req.SubjectID > 0 {
+		conditions = append(conditions, fmt.Sprintf("h.subject_id = $%d", argIndex))
+		args = append(args, req.SubjectID)
+		argIndex++
+	}
+	if req.Semester != "" {
+		conditions = append(conditions, fmt.Sprintf("h.semester = $%d", argIndex))
+		args = append(args, req.Semester)
+		argIndex++
+	}
+
+	whereClause := ""
+	if len(conditions) > 0 {
+		whereClause = " WHERE " + strings.Join(conditions, " AND ")
+	}
+
+	var total int64
+	if err := r.db.QueryRow(`SELECT COUNT(*) FROM score_history h`+whereClause, args...).Scan(&total); err != nil {
+		return nil, 0, fmt.Errorf("failed to count score history: %w", err)
+	}
+
+	query := fmt.Sprintf(`%s%s ORDER BY h.created_at DESC, h.id DESC LIMIT $%d OFFSET $%d`,
+		scoreHistorySelect, whereClause, argIndex, argIndex+1)
+	args = append(args, req.Size, (req.Page-1)*req.Size)
+
+	rows, err := r.db.Query(query, args...)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to query score history: %w", err)
+	}
+	defer rows.Close()
+
+	var history []*domain.ScoreHistory
+	for rows.Next() {
+		h, err := scanScoreHistory(rows)
+		if err != nil {
+			return nil, 0, fmt.Errorf("failed to scan score history: %w", err)
+		}
+		history = append(history, h)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, 0, fmt.Errorf("failed to iterate score history: %w", err)
+	}
+	return history, total, nil
+}
+
+// GetScoreSubject 根据变更记录获取成绩所属科目，成绩已删除时同样可用。没有变更记录时返回0
+func (r *scoreHistoryRepository) GetScoreSubject(scoreID int) (int, error) {
+	var subjectID int
+	err := r.db.QueryRow(`SELECT subject_id FROM score_history WHERE score_id = $1 ORDER BY id LIMIT 1`, scoreID).Scan(&subjectID)
+	if err == sql.ErrNoRows {
+		return 0, nil
+	}
+	if err != nil {
+		return 0, fmt.Errorf("failed to get score subject from history: %w", err)
+	}
+	return subjectID, nil
+}
+
+// insertScoreHistory 在事务中追加一条成绩变更记录
+func insertScoreHistory(tx *sql.Tx, h *domain.ScoreHistory) error {
+	err := tx.QueryRow(`
+		INSERT INTO score_history (score_id, student_id, subject_id, semester, exam_type, action,
+			old_score, new_score, details, reason, actor_id, ip_address)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, NULLIF($9, ''), NULLIF($10, ''), $11, NULLIF($12, ''))
+		RETURNING id, created_at
+	`, h.ScoreID, h.StudentID, h.SubjectID, h.Semester, h.ExamType, h.Action,
+		h.OldScore, h.NewScore, h.Details, h.Reason, h.ActorID, h.IP).Scan(&h.ID, &h.CreatedAt)
+	if err != nil {
+		return fmt.Errorf("failed to record score history: %w", err)
+	}
+	return nil
+}
+
+// scanScoreHistory 扫描成绩变更记录查询的一行
+func scanScoreHistory(row rowScanner) (*domain.ScoreHistory, error) {
+	h := &domain.ScoreHistory{}
+	var oldScore, newScore sql.NullFloat64
+	var actorID sql.NullInt64
+	if err := row.Scan(&h.ID, &h.ScoreID, &h.StudentID, &h.SubjectID, &h.Semester, &h.ExamType, &h.Action,
+		&oldScore, &newScore, &h.Details, &h.Reason,
+		&actorID, &h.ActorName, &h.IP, &h.CreatedAt,
+		&h.StudentName, &h.SubjectName); err != nil {
+		return nil, err
+	}
+	if oldScore.Valid {
+		h.OldScore = &oldScore.Float64
+	}
+	if newScore.Valid {
+		h.NewScore = &newScore.Float64
+	}
+	if actorID.Valid {
+		id := int(actorID.Int64)
+		h.ActorID = &id
+	}
+	return h, nil
+}
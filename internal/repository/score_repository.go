@@ -6,16 +6,24 @@ import (
 	"strings"
 	"student-management-system/internal/domain"
 	"student-management-system/pkg/logger"
+
+	"github.com/lib/pq"
 )
 
 // ScoreRepository 成绩仓储接口
 type ScoreRepository interface {
-	Create(score *domain.Score) error
+	Create(score *domain.Score, history *domain.ScoreHistory) error
 	GetByID(id int) (*domain.Score, error)
 	GetByStudentAndSubject(studentID, subjectID int) (*domain.Score, error)
-	Update(score *domain.Score) error
-	Delete(id int) error
+	Update(score *domain.Score, history *domain.ScoreHistory) (bool, error)
+	Delete(id int, status string, history *domain.ScoreHistory) (bool, error)
 	List(req *domain.ScoreListRequest) ([]*domain.Score, int64, error)
+	Count(req *domain.ScoreListRequest) (int64, error)
+	Each(req *domain.ScoreListRequest, fn func(*domain.Score) error) error
+	GetStudentReport(studentID int, req *domain.StudentScoreReportRequest) (*domain.StudentScoreReport, error)
+	GetSubjectStatistics(subjectID int, req *domain.ScoreStatisticsRequest) (*domain.SubjectScoreStatistics, error)
+	GetClassStatistics(req *domain.ScoreStatisticsRequest) ([]*domain.ClassScoreStatistics, error)
+	GetTeacherSubjectID(teacherID int) (int, error)
 }
 
 // scoreRepository 成绩仓储实现
@@ -28,24 +36,43 @@ func NewScoreRepository(db *sql.DB) ScoreRepository {
 	return &scoreRepository{db: db}
 }
 
-// Create 创建成绩
-func (r *scoreRepository) Create(score *domain.Score) error {
+// Create 创建成绩，并在同一事务中写入变更记录
+func (r *scoreRepository) Create(score *domain.Score, history *domain.ScoreHistory) error {
 	logger.Info("Creating new score")
 
 	query := `
-		INSERT INTO scores (student_id, subject_id, score, semester, exam_type, remarks, created_at, updated_at)
-		VALUES ($1, $2, $3, $4, $5, $6, CURRENT_TIMESTAMP, CURRENT_TIMESTAMP)
+		INSERT INTO scores (student_id, subject_id, teacher_id, offering_id, score, semester, exam_type, remarks, status, created_at, updated_at)
+		VALUES ($1, $2, NULLIF($3, 0), NULLIF($4, 0), $5, $6, $7, $8, $9, CURRENT_TIMESTAMP, CURRENT_TIMESTAMP)
 		RETURNING id
 	`
 
+	if score.Status == "" {
+		score.Status = domain.ScoreStatusDraft
+	}
+
+	tx, err := r.db.Begin()
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
 	var id int
-	err := r.db.QueryRow(query, score.StudentID, score.SubjectID, score.Score,
-		score.Semester, score.ExamType, score.Remarks).Scan(&id)
+	err = tx.QueryRow(query, score.StudentID, score.SubjectID, score.TeacherID, score.OfferingID, score.Score,
+		score.Semester, score.ExamType, score.Remarks, score.Status).Scan(&id)
 	if err != nil {
 		logger.Error("Failed to create score", "error", err)
 		return fmt.Errorf("failed to create score: %w", err)
 	}
 
+	history.ScoreID = id
+	if err := insertScoreHistory(tx, history); err != nil {
+		return err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
 	score.ID = id
 	logger.Info("Score created successfully", "score_id", id)
 	return nil
@@ -54,7 +81,7 @@ func (r *scoreRepository) Create(score *domain.Score) error {
 // GetByID 根据ID获取成绩
 func (r *scoreRepository) GetByID(id int) (*domain.Score, error) {
 	query := `
-		SELECT s.id, s.student_id, s.subject_id, s.score, s.semester, s.exam_type, s.remarks, s.created_at, s.updated_at,
+		SELECT s.id, s.student_id, s.subject_id, COALESCE(s.teacher_id, 0), COALESCE(s.offering_id, 0), s.score, s.semester, s.exam_type, s.remarks, s.status, s.created_at, s.updated_at,
 		       st.name as student_name, st.student_id as student_code,
 		       sub.name as subject_name, sub.code as subject_code
 		FROM scores s
@@ -67,8 +94,8 @@ func (r *scoreRepository) GetByID(id int) (*domain.Score, error) {
 	var studentName, studentCode, subjectName, subjectCode sql.NullString
 
 	err := r.db.QueryRow(query, id).Scan(
-		&score.ID, &score.StudentID, &score.SubjectID, &score.Score,
-		&score.Semester, &score.ExamType, &score.Remarks, &score.CreatedAt, &score.UpdatedAt,
+		&score.ID, &score.StudentID, &score.SubjectID, &score.TeacherID, &score.OfferingID, &score.Score,
+		&score.Semester, &score.ExamType, &score.Remarks, &score.Status, &score.CreatedAt, &score.UpdatedAt,
 		&studentName, &studentCode, &subjectName, &subjectCode,
 	)
 
@@ -104,7 +131,7 @@ func (r *scoreRepository) GetByStudentAndSubject(studentID, subjectID int) (*dom
 	logger.Info("Getting score by student and subject", "student_id", studentID, "subject_id", subjectID)
 
 	query := `
-		SELECT s.id, s.student_id, s.subject_id, s.score, s.semester, s.exam_type, s.remarks, s.created_at, s.updated_at,
+		SELECT s.id, s.student_id, s.subject_id, COALESCE(s.teacher_id, 0), COALESCE(s.offering_id, 0), s.score, s.semester, s.exam_type, s.remarks, s.status, s.created_at, s.updated_at,
 		       st.name as student_name, st.student_id as student_code,
 		       sub.name as subject_name, sub.code as subject_code
 		FROM scores s
@@ -117,8 +144,8 @@ func (r *scoreRepository) GetByStudentAndSubject(studentID, subjectID int) (*dom
 	var studentName, studentCode, subjectName, subjectCode sql.NullString
 
 	err := r.db.QueryRow(query, studentID, subjectID).Scan(
-		&score.ID, &score.StudentID, &score.SubjectID, &score.Score,
-		&score.Semester, &score.ExamType, &score.Remarks, &score.CreatedAt, &score.UpdatedAt,
+		&score.ID, &score.StudentID, &score.SubjectID, &score.TeacherID, &score.OfferingID, &score.Score,
+		&score.Semester, &score.ExamType, &score.Remarks, &score.Status, &score.CreatedAt, &score.UpdatedAt,
 		&studentName, &studentCode, &subjectName, &subjectCode,
 	)
 
@@ -151,50 +178,88 @@ func (r *scoreRepository) GetByStudentAndSubject(studentID, subjectID int) (*dom
 	return score, nil
 }
 
-// Update 更新成绩
-func (r *scoreRepository) Update(score *domain.Score) error {
+// Update 更新成绩，并在同一事务中写入变更记录。成绩已被删除或已被他人修改时返回 false，见 updateScore
+func (r *scoreRepository) Update(score *domain.Score, history *domain.ScoreHistory) (bool, error) {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return false, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	updated, err := updateScore(tx, score, history)
+	if err != nil || !updated {
+		return false, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return false, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+	return true, nil
+}
+
+// updateScore 在调用方的事务中更新成绩并写入变更记录，供需要与其他修改一并提交的仓储使用（如复核结案时调整成绩）。
+// 只有成绩状态仍为 score.Status、分数仍为 history.OldScore 时才更新，否则返回 false
+func updateScore(tx *sql.Tx, score *domain.Score, history *domain.ScoreHistory) (bool, error) {
 	query := `
 		UPDATE scores 
-		SET score = $1, semester = $2, exam_type = $3, remarks = $4, updated_at = CURRENT_TIMESTAMP
-		WHERE id = $5
+		SET score = $1, semester = $2, exam_type = $3, remarks = $4, offering_id = NULLIF($5, 0), updated_at = CURRENT_TIMESTAMP
+		WHERE id = $6 AND status = $7 AND score = $8
 	`
 
-	result, err := r.db.Exec(query, score.Score, score.Semester, score.ExamType, score.Remarks, score.ID)
+	result, err := tx.Exec(query, score.Score, score.Semester, score.ExamType, score.Remarks, score.OfferingID, score.ID,
+		score.Status, history.OldScore)
 	if err != nil {
-		return fmt.Errorf("failed to update score: %w", err)
+		return false, fmt.Errorf("failed to update score: %w", err)
 	}
 
 	rowsAffected, err := result.RowsAffected()
 	if err != nil {
-		return fmt.Errorf("failed to get rows affected: %w", err)
+		return false, fmt.Errorf("failed to get rows affected: %w", err)
 	}
 
 	if rowsAffected == 0 {
-		return fmt.Errorf("score not found")
+		return false, nil
 	}
 
-	return nil
+	if err := insertScoreHistory(tx, history); err != nil {
+		return false, err
+	}
+	return true, nil
 }
 
-// Delete 删除成绩
-func (r *scoreRepository) Delete(id int) error {
-	query := `DELETE FROM scores WHERE id = $1`
+// Delete 删除成绩，并在同一事务中写入变更记录。只有成绩状态仍为 status 时才删除，
+// 成绩已被删除或状态已变化时返回 false
+func (r *scoreRepository) Delete(id int, status string, history *domain.ScoreHistory) (bool, error) {
+	query := `DELETE FROM scores WHERE id = $1 AND status = $2`
+
+	tx, err := r.db.Begin()
+	if err != nil {
+		return false, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
 
-	result, err := r.db.Exec(query, id)
+	result, err := tx.Exec(query, id, status)
 	if err != nil {
-		return fmt.Errorf("failed to delete score: %w", err)
+		return false, fmt.Errorf("failed to delete score: %w", err)
 	}
 
 	rowsAffected, err := result.RowsAffected()
 	if err != nil {
-		return fmt.Errorf("failed to get rows affected: %w", err)
+		return false, fmt.Errorf("failed to get rows affected: %w", err)
 	}
 
 	if rowsAffected == 0 {
-		return fmt.Errorf("score not found")
+		return false, nil
 	}
 
-	return nil
+	if err := insertScoreHistory(tx, history); err != nil {
+		return false, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return false, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+	return true, nil
 }
 
 // List 获取成绩列表
@@ -207,7 +272,104 @@ func (r *scoreRepository) List(req *domain.ScoreListRequest) ([]*domain.Score, i
 		req.Size = 10
 	}
 
-	// 构建查询条件
+	total, err := r.Count(req)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	// 查询数据
+	whereClause, args := scoreListFilter(req)
+	argIndex := len(args) + 1
+	offset := (req.Page - 1) * req.Size
+	dataQuery := fmt.Sprintf(`%s
+		%s
+		ORDER BY s.created_at DESC
+		LIMIT $%d OFFSET $%d
+	`, scoreListSelect, whereClause, argIndex, argIndex+1)
+
+	args = append(args, req.Size, offset)
+
+	rows, err := r.db.Query(dataQuery, args...)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to query scores: %w", err)
+	}
+	defer rows.Close()
+
+	var scores []*domain.Score
+	for rows.Next() {
+		score, err := scanListScore(rows)
+		if err != nil {
+			return nil, 0, err
+		}
+		scores = append(scores, score)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, 0, fmt.Errorf("failed to iterate scores: %w", err)
+	}
+
+	return scores, total, nil
+}
+
+// Count 统计符合列表条件的成绩数量
+func (r *scoreRepository) Count(req *domain.ScoreListRequest) (int64, error) {
+	whereClause, args := scoreListFilter(req)
+	countQuery := fmt.Sprintf(`
+		SELECT COUNT(*) 
+		FROM scores s
+		LEFT JOIN students st ON s.student_id = st.id
+		LEFT JOIN subjects sub ON s.subject_id = sub.id
+		%s
+	`, whereClause)
+
+	var total int64
+	if err := r.db.QueryRow(countQuery, args...).Scan(&total); err != nil {
+		return 0, fmt.Errorf("failed to count scores: %w", err)
+	}
+	return total, nil
+}
+
+// Each 按列表条件逐行遍历全部成绩（不分页），用于导出
+func (r *scoreRepository) Each(req *domain.ScoreListRequest, fn func(*domain.Score) error) error {
+	whereClause, args := scoreListFilter(req)
+	query := fmt.Sprintf(`%s
+		%s
+		ORDER BY s.id
+	`, scoreListSelect, whereClause)
+
+	rows, err := r.db.Query(query, args...)
+	if err != nil {
+		return fmt.Errorf("failed to query scores: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		score, err := scanListScore(rows)
+		if err != nil {
+			return err
+		}
+		if err := fn(score); err != nil {
+			return err
+		}
+	}
+
+	if err = rows.Err(); err != nil {
+		return fmt.Errorf("failed to iterate scores: %w", err)
+	}
+	return nil
+}
+
+// scoreListSelect 成绩列表查询的字段和关联
+const scoreListSelect = `
+		SELECT s.id, s.student_id, s.subject_id, COALESCE(s.teacher_id, 0), COALESCE(s.offering_id, 0), s.score, s.semester, s.exam_type, s.remarks, s.status, s.created_at, s.updated_at,
+		       st.name as student_name, st.student_id as student_code,
+		       sub.name as subject_name, sub.code as subject_code
+		FROM scores s
+		LEFT JOIN students st ON s.student_id = st.id
+		LEFT JOIN subjects sub ON s.subject_id = sub.id`
+
+// scoreListFilter 根据列表请求构建查询条件
+func scoreListFilter(req *domain.ScoreListRequest) (string, []interface{}) {
 	var conditions []string
 	var args []interface{}
 	argIndex := 1
@@ -218,12 +380,30 @@ func (r *scoreRepository) List(req *domain.ScoreListRequest) ([]*domain.Score, i
 		argIndex++
 	}
 
+	if len(req.StudentIDs) > 0 {
+		conditions = append(conditions, fmt.Sprintf("s.student_id = ANY($%d)", argIndex))
+		args = append(args, pq.Array(req.StudentIDs))
+		argIndex++
+	}
+
 	if req.SubjectID > 0 {
 		conditions = append(conditions, fmt.Sprintf("s.subject_id = $%d", argIndex))
 		args = append(args, req.SubjectID)
 		argIndex++
 	}
 
+	if req.TeacherID > 0 {
+		conditions = append(conditions, fmt.Sprintf("s.teacher_id = $%d", argIndex))
+		args = append(args, req.TeacherID)
+		argIndex++
+	}
+
+	if req.OfferingID > 0 {
+		conditions = append(conditions, fmt.Sprintf("s.offering_id = $%d", argIndex))
+		args = append(args, req.OfferingID)
+		argIndex++
+	}
+
 	if req.Semester != "" {
 		conditions = append(conditions, fmt.Sprintf("s.semester = $%d", argIndex))
 		args = append(args, req.Semester)
@@ -236,85 +416,344 @@ func (r *scoreRepository) List(req *domain.ScoreListRequest) ([]*domain.Score, i
 		argIndex++
 	}
 
+	if req.MinScore > 0 {
+		conditions = append(conditions, fmt.Sprintf("s.score >= $%d", argIndex))
+		args = append(args, req.MinScore)
+		argIndex++
+	}
+
+	if req.MaxScore > 0 {
+		conditions = append(conditions, fmt.Sprintf("s.score <= $%d", argIndex))
+		args = append(args, req.MaxScore)
+		argIndex++
+	}
+
+	if req.Status != "" {
+		conditions = append(conditions, fmt.Sprintf("s.status = $%d", argIndex))
+		args = append(args, req.Status)
+		argIndex++
+	}
+
+	if len(req.Statuses) > 0 {
+		conditions = append(conditions, fmt.Sprintf("s.status = ANY($%d)", argIndex))
+		args = append(args, pq.Array(req.Statuses))
+		argIndex++
+	}
+
+	if req.ClassID > 0 {
+		conditions = append(conditions, fmt.Sprintf("st.class_id = $%d", argIndex))
+		args = append(args, req.ClassID)
+		argIndex++
+	}
+
+	if req.GradeYear > 0 {
+		conditions = append(conditions, fmt.Sprintf("st.class_id IN (SELECT id FROM classes WHERE grade_year = $%d)", argIndex))
+		args = append(args, req.GradeYear)
+		argIndex++
+	}
+
 	whereClause := ""
 	if len(conditions) > 0 {
 		whereClause = "WHERE " + strings.Join(conditions, " AND ")
 	}
+	return whereClause, args
+}
 
-	// 查询总数
-	countQuery := fmt.Sprintf(`
-		SELECT COUNT(*) 
-		FROM scores s
-		LEFT JOIN students st ON s.student_id = st.id
-		LEFT JOIN subjects sub ON s.subject_id = sub.id
-		%s
-	`, whereClause)
+// scanListScore 扫描成绩列表中的一行
+func scanListScore(rows *sql.Rows) (*domain.Score, error) {
+	score := &domain.Score{}
+	var studentName, studentCode, subjectName, subjectCode sql.NullString
 
-	var total int64
-	err := r.db.QueryRow(countQuery, args...).Scan(&total)
+	err := rows.Scan(
+		&score.ID, &score.StudentID, &score.SubjectID, &score.TeacherID, &score.OfferingID, &score.Score,
+		&score.Semester, &score.ExamType, &score.Remarks, &score.Status, &score.CreatedAt, &score.UpdatedAt,
+		&studentName, &studentCode, &subjectName, &subjectCode,
+	)
 	if err != nil {
-		return nil, 0, fmt.Errorf("failed to count scores: %w", err)
+		return nil, fmt.Errorf("failed to scan score: %w", err)
 	}
 
-	// 查询数据
-	offset := (req.Page - 1) * req.Size
-	dataQuery := fmt.Sprintf(`
-		SELECT s.id, s.student_id, s.subject_id, s.score, s.semester, s.exam_type, s.remarks, s.created_at, s.updated_at,
-		       st.name as student_name, st.student_id as student_code,
-		       sub.name as subject_name, sub.code as subject_code
-		FROM scores s
-		LEFT JOIN students st ON s.student_id = st.id
-		LEFT JOIN subjects sub ON s.subject_id = sub.id
-		%s
-		ORDER BY s.created_at DESC
-		LIMIT $%d OFFSET $%d
-	`, whereClause, argIndex, argIndex+1)
+	// 设置关联数据
+	if studentName.Valid {
+		score.Student = &domain.Student{
+			ID:        score.StudentID,
+			Name:      studentName.String,
+			StudentID: studentCode.String,
+		}
+	}
 
-	args = append(args, req.Size, offset)
+	if subjectName.Valid {
+		score.Subject = &domain.Subject{
+			ID:   score.SubjectID,
+			Name: subjectName.String,
+			Code: subjectCode.String,
+		}
+	}
 
-	rows, err := r.db.Query(dataQuery, args...)
+	return score, nil
+}
+
+// GetStudentReport 获取学生学期成绩报告，GPA由服务层按绩点制计算
+func (r *scoreRepository) GetStudentReport(studentID int, req *domain.StudentScoreReportRequest) (*domain.StudentScoreReport, error) {
+	logger.Info("Getting student score report", "student_id", studentID, "semester", req.Semester)
+
+	report := &domain.StudentScoreReport{
+		StudentID: studentID,
+		Semester:  req.Semester,
+		Scores:    []domain.SubjectScoreDetail{},
+	}
+
+	err := r.db.QueryRow(`SELECT name FROM students WHERE id = $1`, studentID).Scan(&report.StudentName)
 	if err != nil {
-		return nil, 0, fmt.Errorf("failed to query scores: %w", err)
+		if err == sql.ErrNoRows {
+			return nil, fmt.Errorf("student not found")
+		}
+		return nil, fmt.Errorf("failed to get student: %w", err)
+	}
+
+	// 学生和家长只能看到已发布的成绩
+	statuses := []string{}
+	if req.PublishedOnly {
+		statuses = domain.PublishedScoreStatuses
+	}
+
+	detailQuery := `
+		SELECT sub.id, sub.name, sub.code, sub.credits, s.score, s.exam_type
+		FROM scores s
+		JOIN subjects sub ON s.subject_id = sub.id
+		WHERE s.student_id = $1 AND s.semester = $2 AND s.exam_type = $3
+		  AND (cardinality($4::text[]) = 0 OR s.status = ANY($4))
+		ORDER BY sub.code
+	`
+
+	rows, err := r.db.Query(detailQuery, studentID, req.Semester, req.ExamType, pq.Array(statuses))
+	if err != nil {
+		return nil, fmt.Errorf("failed to query report scores: %w", err)
 	}
 	defer rows.Close()
 
-	var scores []*domain.Score
 	for rows.Next() {
-		score := &domain.Score{}
-		var studentName, studentCode, subjectName, subjectCode sql.NullString
-
-		err := rows.Scan(
-			&score.ID, &score.StudentID, &score.SubjectID, &score.Score,
-			&score.Semester, &score.ExamType, &score.Remarks, &score.CreatedAt, &score.UpdatedAt,
-			&studentName, &studentCode, &subjectName, &subjectCode,
-		)
-		if err != nil {
-			return nil, 0, fmt.Errorf("failed to scan score: %w", err)
+		var detail domain.SubjectScoreDetail
+		if err := rows.Scan(&detail.SubjectID, &detail.SubjectName, &detail.SubjectCode,
+			&detail.Credits, &detail.Score, &detail.ExamType); err != nil {
+			return nil, fmt.Errorf("failed to scan report score: %w", err)
 		}
+		report.Scores = append(report.Scores, detail)
+	}
 
-		// 设置关联数据
-		if studentName.Valid {
-			score.Student = &domain.Student{
-				ID:        score.StudentID,
-				Name:      studentName.String,
-				StudentID: studentCode.String,
-			}
-		}
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to iterate report scores: %w", err)
+	}
+
+	summaryQuery := `
+		SELECT COALESCE(SUM(s.score), 0),
+		       COALESCE(ROUND(AVG(s.score), 2), 0)
+		FROM scores s
+		WHERE s.student_id = $1 AND s.semester = $2 AND s.exam_type = $3
+		  AND (cardinality($4::text[]) = 0 OR s.status = ANY($4))
+	`
+
+	err = r.db.QueryRow(summaryQuery, studentID, req.Semester, req.ExamType, pq.Array(statuses)).Scan(
+		&report.TotalScore, &report.AverageScore,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to summarize report scores: %w", err)
+	}
 
-		if subjectName.Valid {
-			score.Subject = &domain.Subject{
-				ID:   score.SubjectID,
-				Name: subjectName.String,
-				Code: subjectCode.String,
-			}
+	return report, nil
+}
+
+// scoreTermClassJoin 按成绩所在学期结束时（学期未结束或未登记时为当天）的分班记录关联班级，与排名的口径一致，
+// 学生此后转班不影响历史学期的统计
+const scoreTermClassJoin = `
+		LEFT JOIN terms t ON t.code = s.semester
+		LEFT JOIN class_memberships cm
+		       ON cm.student_id = st.id
+		      AND cm.joined_at <= LEAST(COALESCE(t.end_date, CURRENT_DATE), CURRENT_DATE)
+		      AND (cm.left_at IS NULL OR cm.left_at > LEAST(COALESCE(t.end_date, CURRENT_DATE), CURRENT_DATE))
+		LEFT JOIN classes c ON cm.class_id = c.id`
+
+// GetSubjectStatistics 获取科目成绩分段统计，班级和年级按成绩所在学期的分班记录筛选
+func (r *scoreRepository) GetSubjectStatistics(subjectID int, req *domain.ScoreStatisticsRequest) (*domain.SubjectScoreStatistics, error) {
+	logger.Info("Getting subject score statistics", "subject_id", subjectID)
+
+	stats := &domain.SubjectScoreStatistics{
+		SubjectID: subjectID,
+		Semester:  req.Semester,
+		ExamType:  req.ExamType,
+	}
+
+	err := r.db.QueryRow(`SELECT name FROM subjects WHERE id = $1`, subjectID).Scan(&stats.SubjectName)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, fmt.Errorf("subject not found")
 		}
+		return nil, fmt.Errorf("failed to get subject: %w", err)
+	}
 
-		scores = append(scores, score)
+	conditions := []string{"s.subject_id = $1"}
+	args := []interface{}{subjectID}
+	argIndex := 2
+
+	if req.Semester != "" {
+		conditions = append(conditions, fmt.Sprintf("s.semester = $%d", argIndex))
+		args = append(args, req.Semester)
+		argIndex++
+	}
+
+	if req.ExamType != "" {
+		conditions = append(conditions, fmt.Sprintf("s.exam_type = $%d", argIndex))
+		args = append(args, req.ExamType)
+		argIndex++
+	}
+
+	if req.Major != "" {
+		conditions = append(conditions, fmt.Sprintf("st.major = $%d", argIndex))
+		args = append(args, req.Major)
+		argIndex++
+	}
+
+	if req.ClassID > 0 {
+		conditions = append(conditions, fmt.Sprintf("c.id = $%d", argIndex))
+		args = append(args, req.ClassID)
+		argIndex++
+	}
+
+	if req.GradeYear > 0 {
+		conditions = append(conditions, fmt.Sprintf("c.grade_year = $%d", argIndex))
+		args = append(args, req.GradeYear)
+		argIndex++
+	}
+
+	query := fmt.Sprintf(`
+		SELECT COUNT(*),
+		       COALESCE(ROUND(AVG(s.score), 2), 0),
+		       COALESCE(MIN(s.score), 0),
+		       COALESCE(MAX(s.score), 0),
+		       COUNT(*) FILTER (WHERE s.score >= 90),
+		       COUNT(*) FILTER (WHERE s.score >= 80 AND s.score < 90),
+		       COUNT(*) FILTER (WHERE s.score >= 70 AND s.score < 80),
+		       COUNT(*) FILTER (WHERE s.score >= 60 AND s.score < 70),
+		       COUNT(*) FILTER (WHERE s.score < 60)
+		FROM scores s
+		JOIN students st ON s.student_id = st.id`+scoreTermClassJoin+`
+		WHERE %s
+	`, strings.Join(conditions, " AND "))
+
+	err = r.db.QueryRow(query, args...).Scan(
+		&stats.TotalCount, &stats.AverageScore, &stats.MinScore, &stats.MaxScore,
+		&stats.ExcellentCount, &stats.GoodCount, &stats.FairCount, &stats.PassCount, &stats.FailCount,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get subject statistics: %w", err)
+	}
+
+	return stats, nil
+}
+
+// GetClassStatistics 获取班级成绩统计（按班级、科目、学期、考试类型分组），学生计入成绩所在学期所在的班级
+func (r *scoreRepository) GetClassStatistics(req *domain.ScoreStatisticsRequest) ([]*domain.ClassScoreStatistics, error) {
+	logger.Info("Getting class score statistics", "semester", req.Semester, "major", req.Major, "class_id", req.ClassID)
+
+	var conditions []string
+	var args []interface{}
+	argIndex := 1
+
+	if req.SubjectID > 0 {
+		conditions = append(conditions, fmt.Sprintf("s.subject_id = $%d", argIndex))
+		args = append(args, req.SubjectID)
+		argIndex++
+	}
+
+	if req.Semester != "" {
+		conditions = append(conditions, fmt.Sprintf("s.semester = $%d", argIndex))
+		args = append(args, req.Semester)
+		argIndex++
+	}
+
+	if req.ExamType != "" {
+		conditions = append(conditions, fmt.Sprintf("s.exam_type = $%d", argIndex))
+		args = append(args, req.ExamType)
+		argIndex++
+	}
+
+	if req.Major != "" {
+		conditions = append(conditions, fmt.Sprintf("st.major = $%d", argIndex))
+		args = append(args, req.Major)
+		argIndex++
+	}
+
+	if req.ClassID > 0 {
+		conditions = append(conditions, fmt.Sprintf("c.id = $%d", argIndex))
+		args = append(args, req.ClassID)
+		argIndex++
+	}
+
+	if req.GradeYear > 0 {
+		conditions = append(conditions, fmt.Sprintf("c.grade_year = $%d", argIndex))
+		args = append(args, req.GradeYear)
+		argIndex++
+	}
+
+	whereClause := ""
+	if len(conditions) > 0 {
+		whereClause = "WHERE " + strings.Join(conditions, " AND ")
+	}
+
+	query := fmt.Sprintf(`
+		SELECT COALESCE(c.id, 0), COALESCE(c.name, ''), COALESCE(c.grade_year, 0),
+		       s.subject_id, sub.name, s.semester, s.exam_type,
+		       COUNT(*),
+		       ROUND(AVG(s.score), 2),
+		       MAX(s.score),
+		       MIN(s.score),
+		       ROUND(100.0 * COUNT(*) FILTER (WHERE s.score >= 60) / COUNT(*), 2),
+		       ROUND(100.0 * COUNT(*) FILTER (WHERE s.score >= 90) / COUNT(*), 2)
+		FROM scores s
+		JOIN subjects sub ON s.subject_id = sub.id
+		JOIN students st ON s.student_id = st.id`+scoreTermClassJoin+`
+		%s
+		GROUP BY c.id, c.name, c.grade_year, s.subject_id, sub.name, s.semester, s.exam_type
+		ORDER BY s.semester DESC, c.grade_year, c.name, sub.name, s.exam_type
+	`, whereClause)
+
+	rows, err := r.db.Query(query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query class statistics: %w", err)
+	}
+	defer rows.Close()
+
+	var statistics []*domain.ClassScoreStatistics
+	for rows.Next() {
+		stat := &domain.ClassScoreStatistics{}
+		if err := rows.Scan(
+			&stat.ClassID, &stat.ClassName, &stat.GradeYear,
+			&stat.SubjectID, &stat.SubjectName, &stat.Semester, &stat.ExamType,
+			&stat.StudentCount, &stat.AverageScore, &stat.MaxScore, &stat.MinScore,
+			&stat.PassRate, &stat.ExcellentRate,
+		); err != nil {
+			return nil, fmt.Errorf("failed to scan class statistics: %w", err)
+		}
+		statistics = append(statistics, stat)
 	}
 
 	if err = rows.Err(); err != nil {
-		return nil, 0, fmt.Errorf("failed to iterate scores: %w", err)
+		return nil, fmt.Errorf("failed to iterate class statistics: %w", err)
 	}
 
-	return scores, total, nil
+	return statistics, nil
+}
+
+// GetTeacherSubjectID 获取教师任教的科目ID，未设置任教科目时返回0
+func (r *scoreRepository) GetTeacherSubjectID(teacherID int) (int, error) {
+	var subjectID sql.NullInt64
+	err := r.db.QueryRow(`SELECT subject_id FROM teachers WHERE id = $1`, teacherID).Scan(&subjectID)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return 0, fmt.Errorf("teacher not found")
+		}
+		return 0, fmt.Errorf("failed to get teacher subject: %w", err)
+	}
+
+	return int(subjectID.Int64), nil
 }
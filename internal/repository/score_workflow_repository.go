@@ -0,0 +1,363 @@
+package repository
+
+import (
+	"database/sql"
+	"fmt"
+	"strings"
+
+	"student-management-system/internal/domain"
+	"student-management-system/pkg/logger"
+
+	"github.com/lib/pq"
+)
+
+// ScoreWorkflowRepository 成绩发布流程仓储接口
+type ScoreWorkflowRepository interface {
+	CountOfferingScores(offeringID int) (map[string]int, error)
+	TransitionOffering(offeringID int, from []string, event *domain.ScoreWorkflowEvent) (int, error)
+	ListEvents(offeringID int) ([]domain.ScoreWorkflowEvent, error)
+	LastEvent(offeringID int, action string) (*domain.ScoreWorkflowEvent, error)
+	CreateChangeRequest(req *domain.GradeChangeRequest) error
+	GetChangeRequest(id int) (*domain.GradeChangeRequest, error)
+	HasPendingChangeRequest(scoreID int) (bool, error)
+	ListChangeRequests(req *domain.GradeChangeListRequest) ([]*domain.GradeChangeRequest, int64, error)
+	ReviewChangeRequest(req *domain.GradeChangeRequest, history *domain.ScoreHistory) (bool, error)
+}
+
+// scoreWorkflowRepository 成绩发布流程仓储实现
+type scoreWorkflowRepository struct {
+	db *sql.DB
+}
+
+// NewScoreWorkflowRepository 创建成绩发布流程仓储实例
+func NewScoreWorkflowRepository(db *sql.DB) ScoreWorkflowRepository {
+	return &scoreWorkflowRepository{db: db}
+}
+
+// CountOfferingScores 统计开课各状态的成绩数量
+func (r *scoreWorkflowRepository) CountOfferingScores(offeringID int) (map[string]int, error) {
+	rows, err := r.db.Query(`SELECT status, COUNT(*) FROM scores WHERE offering_id = $1 GROUP BY status`, offeringID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to count offering scores: %w", err)
+	}
+	defer rows.Close()
+
+	counts := make(map[string]int)
+	for rows.Next() {
+		var status string
+		var count int
+		if err := rows.Scan(&status, &count); err != nil {
+			return nil, fmt.Errorf("failed to scan offering score count: %w", err)
+		}
+		counts[status] = count
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to iterate offering score counts: %w", err)
+	}
+	return counts, nil
+}
+
+// TransitionOffering 将开课中处于 from 状态的成绩变更为 event.ToStatus，并记录流转，返回变更的成绩数量
+func (r *scoreWorkflowRepository) TransitionOffering(offeringID int, from []string, event *domain.ScoreWorkflowEvent) (int, error) {
+	logger.WithFields(map[string]interface{}{
+		"offering_id": offeringID,
+		"action":      event.Action,
+	}).Info("Transitioning offering scores")
+
+	tx, err := r.db.Begin()
+	if err != nil {
+		return 0, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	result, err := tx.Exec(`
+		UPDATE scores SET status = $3, updated_at = CURRENT_TIMESTAMP
+		WHERE offering_id = $1 AND status = ANY($2)
+	`, offeringID, pq.Array(from), event.ToStatus)
+	if err != nil {
+		return 0, fmt.Errorf("failed to transition offering scores: %w", err)
+	}
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return 0, fmt.Errorf("failed to get affected rows: %w", err)
+	}
+	if affected == 0 {
+		return 0, nil
+	}
+
+	event.OfferingID = offeringID
+	event.Affected = int(affected)
+	err = tx.QueryRow(`
+		INSERT INTO score_workflow_events (offering_id, action, from_status, to_status, affected, comment, actor_id)
+		VALUES ($1, $2, $3, $4, $5, NULLIF($6, ''), $7)
+		RETURNING id, created_at
+	`, offeringID, event.Action, event.FromStatus, event.ToStatus, event.Affected, event.Comment, event.ActorID).
+		Scan(&event.ID, &event.CreatedAt)
+	if err != nil {
+		return 0, fmt.Errorf("failed to record score workflow event: %w", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return 0, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+	return event.Affected, nil
+}
+
+// workflowEventSelect 成绩流转记录查询的字段
+const workflowEventSelect = `
+		SELECT e.id, e.offering_id, e.action, e.from_status, e.to_status, e.affected, COALESCE(e.comment, ''),
+		       e.actor_id, COALESCE(a.name, ''), e.created_at
+		FROM score_workflow_events e
+		LEFT JOIN admins a ON e.actor_id = a.id`
+
+// ListEvents 获取开课的成绩流转记录，按时间先后排列
+func (r *scoreWorkflowRepository) ListEvents(offeringID int) ([]domain.ScoreWorkflowEvent, error) {
+	rows, err := r.db.Query(workflowEventSelect+` WHERE e.offering_id = $1 ORDER BY e.created_at, e.id`, offeringID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query score workflow events: %w", err)
+	}
+	defer rows.Close()
+
+	events := []domain.ScoreWorkflowEvent{}
+	for rows.Next() {
+		event, err := scanWorkflowEvent(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan score workflow event: %w", err)
+		}
+		events = append(events, *event)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to iterate score workflow events: %w", err)
+	}
+	return events, nil
+}
+
+// LastEvent 获取开课最近一次指定操作的流转记录，不存在时返回 nil
+func (r *scoreWorkflowRepository) LastEvent(offeringID int, action string) (*domain.ScoreWorkflowEvent, error) {
+	event, err := scanWorkflowEvent(r.db.QueryRow(workflowEventSelect+`
+		WHERE e.offering_id = $1 AND e.action = $2
+		ORDER BY e.created_at DESC, e.id DESC
+		LIMIT 1
+	`, offeringID, action))
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get score workflow event: %w", err)
+	}
+	return event, nil
+}
+
+// scanWorkflowEvent 扫描成绩流转记录的一行
+func scanWorkflowEvent(row rowScanner) (*domain.ScoreWorkflowEvent, error) {
+	event := &domain.ScoreWorkflowEvent{}
+	var actorID sql.NullInt64
+	if err := row.Scan(&event.ID, &event.OfferingID, &event.Action, &event.FromStatus, &event.ToStatus,
+		&event.Affected, &event.Comment, &actorID, &event.ActorName, &event.CreatedAt); err != nil {
+		return nil, err
+	}
+	if actorID.Valid {
+		id := int(actorID.Int64)
+		event.ActorID = &id
+	}
+	return event, nil
+}
+
+// CreateChangeRequest 创建成绩更正申请
+func (r *scoreWorkflowRepository) CreateChangeRequest(req *domain.GradeChangeRequest) error {
+	logger.WithFields(map[string]interface{}{
+		"score_id": req.ScoreID,
+	}).Info("Creating grade change request")
+
+	err := r.db.QueryRow(`
+		INSERT INTO grade_change_requests (score_id, old_score, new_score, reason, status, requested_by)
+		VALUES ($1, $2, $3, $4, $5, $6)
+		RETURNING id, created_at
+	`, req.ScoreID, req.OldScore, req.NewScore, req.Reason, req.Status, req.RequestedBy).Scan(&req.ID, &req.CreatedAt)
+	if err != nil {
+		logger.WithError(err).Error("Failed to create grade change request")
+		return fmt.Errorf("failed to create grade change request: %w", err)
+	}
+	return nil
+}
+
+// changeRequestSelect 成绩更正申请查询的字段和关联
+const changeRequestSelect = `
+		SELECT g.id, g.score_id, g.old_score, g.new_score, g.reason, g.status, g.requested_by, g.reviewed_by,
+		       COALESCE(g.review_comment, ''), g.created_at, g.reviewed_at,
+		       s.student_id, st.name, s.subject_id, sub.name, COALESCE(s.offering_id, 0), s.semester, s.exam_type
+		FROM grade_change_requests g
+		JOIN scores s ON g.score_id = s.id
+		JOIN students st ON s.student_id = st.id
+		JOIN subjects sub ON s.subject_id = sub.id`
+
+// GetChangeRequest 根据ID获取成绩更正申请，不存在时返回 nil
+func (r *scoreWorkflowRepository) GetChangeRequest(id int) (*domain.GradeChangeRequest, error) {
+	req, err := scanChangeRequest(r.db.QueryRow(changeRequestSelect+` WHERE g.id = $1`, id))
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get grade change request: %w", err)
+	}
+	return req, nil
+}
+
+// HasPendingChangeRequest 成绩是否有待审批的更正申请
+func (r *scoreWorkflowRepository) HasPendingChangeRequest(scoreID int) (bool, error) {
+	var exists bool
+	err := r.db.QueryRow(`
+		SELECT EXISTS (SELECT 1 FROM grade_change_requests WHERE score_id = $1 AND status = $2)
+	`, scoreID, domain.GradeChangeStatusPending).Scan(&exists)
+	if err != nil {
+		return false, fmt.Errorf("failed to check pending grade change request: %w", err)
+	}
+	return exists, nil
+}
+
+// ListChangeRequests 获取成绩更正申请列表（分页），按提交时间倒序
+func (r *scoreWorkflowRepository) ListChangeRequests(req *domain.GradeChangeListRequest) ([]*domain.GradeChangeRequest, int64, error) {
+	var conditions []string
+	var args []interface{}
+	argIndex := 1
+
+	if req.Status != "" {
+		conditions = append(conditions, fmt.Sprintf("g.status = $%d", argIndex))
+		args = append(args, req.Status)
+		argIndex++
+	}
+	if req.OfferingID > 0 {
+		conditions = append(conditions, fmt.Sprintf("s.offering_id = $%d", argIndex))
+		args = append(args, req.OfferingID)
+		argIndex++
+	}
+	if req.RequestedBy > 0 {
+		conditions = append(conditions, fmt.Sprintf("g.requested_by = $%d", argIndex))
+		args = append(args, req.RequestedBy)
+		argIndex++
+	}
+
+	whereClause := ""
+	if len(conditions) > 0 {
+		whereClause = " WHERE " + strings.Join(conditions, " AND ")
+	}
+
+	var total int64
+	countQuery := `SELECT COUNT(*) FROM grade_change_requests g JOIN scores s ON g.score_id = s.id` + whereClause
+	if err := r.db.QueryRow(countQuery, args...).Scan(&total); err != nil {
+		return nil, 0, fmt.Errorf("failed to count grade change requests: %w", err)
+	}
+
+	query := fmt.Sprintf(`%s%s ORDER BY g.created_at DESC, g.id DESC LIMIT $%d OFFSET $%d`,
+		changeRequestSelect, whereClause, argIndex, argIndex+1)
+	args = append(args, req.Size, (req.Page-1)*req.Size)
+
+	rows, err := r.db.Query(query, args...)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to query grade change requests: %w", err)
+	}
+	defer rows.Close()
+
+	var requests []*domain.GradeChangeRequest
+	for rows.Next() {
+		cr, err := scanChangeRequest(rows)
+		if err != nil {
+			return nil, 0, fmt.Errorf("failed to scan grade change request: %w", err)
+		}
+		requests = append(requests, cr)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, 0, fmt.Errorf("failed to iterate grade change requests: %w", err)
+	}
+	return requests, total, nil
+}
+
+// ReviewChangeRequest 保存成绩更正申请的审批结果，批准时同时修改成绩并写入变更记录，变更记录的原成绩取加锁后读到的成绩。
+// 申请已被审批，或批准时成绩已不是申请时的分数、已不是已发布或已锁定状态时不做修改并返回 false
+func (r *scoreWorkflowRepository) ReviewChangeRequest(req *domain.GradeChangeRequest, history *domain.ScoreHistory) (bool, error) {
+	logger.WithFields(map[string]interface{}{
+		"request_id": req.ID,
+		"status":     req.Status,
+	}).Info("Reviewing grade change request")
+
+	tx, err := r.db.Begin()
+	if err != nil {
+		return false, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	err = tx.QueryRow(`
+		UPDATE grade_change_requests
+		SET status = $2, reviewed_by = $3, review_comment = NULLIF($4, ''), reviewed_at = CURRENT_TIMESTAMP
+		WHERE id = $1 AND status = $5
+		RETURNING reviewed_at
+	`, req.ID, req.Status, req.ReviewedBy, req.ReviewComment, domain.GradeChangeStatusPending).Scan(&req.ReviewedAt)
+	if err == sql.ErrNoRows {
+		return false, nil
+	}
+	if err != nil {
+		return false, fmt.Errorf("failed to review grade change request: %w", err)
+	}
+
+	if req.Status == domain.GradeChangeStatusApproved {
+		var current float64
+		var status string
+		err = tx.QueryRow(`SELECT score, status FROM scores WHERE id = $1 FOR UPDATE`, req.ScoreID).Scan(&current, &status)
+		if err == sql.ErrNoRows {
+			return false, nil
+		}
+		if err != nil {
+			return false, fmt.Errorf("failed to lock score: %w", err)
+		}
+		if current != req.OldScore || !domain.IsPublishedScoreStatus(status) {
+			logger.WithFields(map[string]interface{}{
+				"request_id": req.ID,
+				"score_id":   req.ScoreID,
+				"score":      current,
+				"status":     status,
+			}).Warn("Score changed since grade change request was submitted")
+			return false, nil
+		}
+
+		_, err = tx.Exec(`UPDATE scores SET score = $2, updated_at = CURRENT_TIMESTAMP WHERE id = $1`, req.ScoreID, req.NewScore)
+		if err != nil {
+			return false, fmt.Errorf("failed to apply grade change: %w", err)
+		}
+		history.OldScore = &current
+		if err := insertScoreHistory(tx, history); err != nil {
+			return false, err
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return false, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+	return true, nil
+}
+
+// scanChangeRequest 扫描成绩更正申请查询的一行
+func scanChangeRequest(row rowScanner) (*domain.GradeChangeRequest, error) {
+	req := &domain.GradeChangeRequest{}
+	var requestedBy, reviewedBy sql.NullInt64
+	var reviewedAt sql.NullTime
+	if err := row.Scan(&req.ID, &req.ScoreID, &req.OldScore, &req.NewScore, &req.Reason, &req.Status,
+		&requestedBy, &reviewedBy, &req.ReviewComment, &req.CreatedAt, &reviewedAt,
+		&req.StudentID, &req.StudentName, &req.SubjectID, &req.SubjectName, &req.OfferingID, &req.Semester, &req.ExamType); err != nil {
+		return nil, err
+	}
+	if requestedBy.Valid {
+		id := int(requestedBy.Int64)
+		req.RequestedBy = &id
+	}
+	if reviewedBy.Valid {
+		id := int(reviewedBy.Int64)
+		req.ReviewedBy = &id
+	}
+	if reviewedAt.Valid {
+		req.ReviewedAt = &reviewedAt.Time
+	}
+	return req, nil
+}
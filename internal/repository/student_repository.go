@@ -2,8 +2,12 @@ package repository
 
 import (
 	"database/sql"
+	"fmt"
+	"strings"
 	"student-management-system/internal/domain"
 	"student-management-system/pkg/logger"
+
+	"github.com/lib/pq"
 )
 
 // StudentRepository 学生仓储接口
@@ -14,10 +18,12 @@ type StudentRepository interface {
 	Update(student *domain.Student) error
 	UpdateMajor(studentID int, newMajor string) error
 	Delete(id int) error
-	List(offset, limit int) ([]*domain.Student, error)
-	Count() (int, error)
+	List(offset, limit int, filter domain.StudentFilter) ([]*domain.Student, error)
+	Count(filter domain.StudentFilter) (int, error)
 	BatchCreate(students []*domain.Student) error
 	BatchDelete(ids []int) error
+	ExistingStudentIDs(studentIDs []string) ([]string, error)
+	Each(ids []int, filter domain.StudentFilter, fn func(*domain.Student) error) error
 }
 
 // studentRepository 学生仓储实现
@@ -84,7 +90,7 @@ func (r *studentRepository) GetByID(id int) (*domain.Student, error) {
 	student := &domain.Student{}
 	query := `
 		SELECT id, student_id, name, age, gender, phone, email, address, major, 
-		       enrollment_date, graduation_date, status, created_at, updated_at
+		       enrollment_date, graduation_date, status, class_id, created_at, updated_at
 		FROM students 
 		WHERE id = $1
 	`
@@ -102,6 +108,7 @@ func (r *studentRepository) GetByID(id int) (*domain.Student, error) {
 		&student.EnrollmentDate,
 		&student.GraduationDate,
 		&student.Status,
+		&student.ClassID,
 		&student.CreatedAt,
 		&student.UpdatedAt,
 	)
@@ -138,7 +145,7 @@ func (r *studentRepository) GetByStudentID(studentID string) (*domain.Student, e
 	student := &domain.Student{}
 	query := `
 		SELECT id, student_id, name, age, gender, phone, email, address, major, 
-		       enrollment_date, graduation_date, status, created_at, updated_at
+		       enrollment_date, graduation_date, status, class_id, created_at, updated_at
 		FROM students 
 		WHERE student_id = $1
 	`
@@ -156,6 +163,7 @@ func (r *studentRepository) GetByStudentID(studentID string) (*domain.Student, e
 		&student.EnrollmentDate,
 		&student.GraduationDate,
 		&student.Status,
+		&student.ClassID,
 		&student.CreatedAt,
 		&student.UpdatedAt,
 	)
@@ -195,7 +203,7 @@ func (r *studentRepository) Update(student *domain.Student) error {
 		UPDATE students 
 		SET student_id = $2, name = $3, age = $4, gender = $5, phone = $6, 
 		    email = $7, address = $8, major = $9, enrollment_date = $10, 
-		    graduation_date = $11, status = $12
+		    graduation_date = $11
 		WHERE id = $1
 	`
 
@@ -212,7 +220,6 @@ func (r *studentRepository) Update(student *domain.Student) error {
 		student.Major,
 		student.EnrollmentDate,
 		student.GraduationDate,
-		student.Status,
 	)
 
 	if err != nil {
@@ -257,21 +264,25 @@ func (r *studentRepository) Delete(id int) error {
 }
 
 // List 获取学生列表
-func (r *studentRepository) List(offset, limit int) ([]*domain.Student, error) {
+func (r *studentRepository) List(offset, limit int, filter domain.StudentFilter) ([]*domain.Student, error) {
 	logger.WithFields(map[string]interface{}{
-		"offset": offset,
-		"limit":  limit,
+		"offset":   offset,
+		"limit":    limit,
+		"class_id": filter.ClassID,
 	}).Info("Getting student list")
 
-	query := `
+	whereClause, args := studentFilterClause(filter)
+	query := fmt.Sprintf(`
 		SELECT id, student_id, name, age, gender, phone, email, address, major, 
-		       enrollment_date, graduation_date, status, created_at, updated_at
+		       enrollment_date, graduation_date, status, class_id, created_at, updated_at
 		FROM students 
+		%s
 		ORDER BY id 
-		LIMIT $1 OFFSET $2
-	`
+		LIMIT $%d OFFSET $%d
+	`, whereClause, len(args)+1, len(args)+2)
 
-	rows, err := r.db.Query(query, limit, offset)
+	args = append(args, limit, offset)
+	rows, err := r.db.Query(query, args...)
 	if err != nil {
 		logger.WithError(err).WithFields(map[string]interface{}{
 			"offset": offset,
@@ -297,6 +308,7 @@ func (r *studentRepository) List(offset, limit int) ([]*domain.Student, error) {
 			&student.EnrollmentDate,
 			&student.GraduationDate,
 			&student.Status,
+			&student.ClassID,
 			&student.CreatedAt,
 			&student.UpdatedAt,
 		)
@@ -322,12 +334,13 @@ func (r *studentRepository) List(offset, limit int) ([]*domain.Student, error) {
 }
 
 // Count 获取学生总数
-func (r *studentRepository) Count() (int, error) {
+func (r *studentRepository) Count(filter domain.StudentFilter) (int, error) {
 	logger.Info("Getting student count")
 
-	query := `SELECT COUNT(*) FROM students`
+	whereClause, args := studentFilterClause(filter)
+	query := `SELECT COUNT(*) FROM students ` + whereClause
 	var count int
-	err := r.db.QueryRow(query).Scan(&count)
+	err := r.db.QueryRow(query, args...).Scan(&count)
 
 	if err != nil {
 		logger.WithError(err).Error("Failed to get student count")
@@ -471,3 +484,108 @@ func (r *studentRepository) UpdateMajor(studentID int, newMajor string) error {
 
 	return nil
 }
+
+// ExistingStudentIDs 返回给定学号中已存在的学号
+func (r *studentRepository) ExistingStudentIDs(studentIDs []string) ([]string, error) {
+	if len(studentIDs) == 0 {
+		return nil, nil
+	}
+
+	rows, err := r.db.Query(`SELECT student_id FROM students WHERE student_id = ANY($1)`, pq.Array(studentIDs))
+	if err != nil {
+		logger.WithError(err).WithFields(map[string]interface{}{
+			"count": len(studentIDs),
+		}).Error("Failed to query existing student ids")
+		return nil, err
+	}
+	defer rows.Close()
+
+	var existing []string
+	for rows.Next() {
+		var studentID string
+		if err := rows.Scan(&studentID); err != nil {
+			return nil, err
+		}
+		existing = append(existing, studentID)
+	}
+
+	return existing, rows.Err()
+}
+
+// Each 按筛选条件逐行遍历学生（不分页），ids 不为空时只遍历指定学生，用于导出
+func (r *studentRepository) Each(ids []int, filter domain.StudentFilter, fn func(*domain.Student) error) error {
+	whereClause, args := studentFilterClause(filter)
+	if ids != nil {
+		args = append(args, pq.Array(ids))
+		if whereClause == "" {
+			whereClause = fmt.Sprintf("WHERE id = ANY($%d)", len(args))
+		} else {
+			whereClause += fmt.Sprintf(" AND id = ANY($%d)", len(args))
+		}
+	}
+	query := fmt.Sprintf(`
+		SELECT id, student_id, name, age, gender, phone, email, address, major,
+		       enrollment_date, graduation_date, status, class_id, created_at, updated_at
+		FROM students
+		%s
+		ORDER BY id
+	`, whereClause)
+
+	rows, err := r.db.Query(query, args...)
+	if err != nil {
+		logger.WithError(err).Error("Failed to query students for iteration")
+		return err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		student := &domain.Student{}
+		err := rows.Scan(
+			&student.ID,
+			&student.StudentID,
+			&student.Name,
+			&student.Age,
+			&student.Gender,
+			&student.Phone,
+			&student.Email,
+			&student.Address,
+			&student.Major,
+			&student.EnrollmentDate,
+			&student.GraduationDate,
+			&student.Status,
+			&student.ClassID,
+			&student.CreatedAt,
+			&student.UpdatedAt,
+		)
+		if err != nil {
+			logger.WithError(err).Error("Failed to scan student row")
+			return err
+		}
+		if err := fn(student); err != nil {
+			return err
+		}
+	}
+
+	return rows.Err()
+}
+
+// studentFilterClause 根据筛选条件构建查询条件
+func studentFilterClause(filter domain.StudentFilter) (string, []interface{}) {
+	var conditions []string
+	var args []interface{}
+
+	if filter.ClassID > 0 {
+		args = append(args, filter.ClassID)
+		conditions = append(conditions, fmt.Sprintf("class_id = $%d", len(args)))
+	}
+
+	if filter.GradeYear > 0 {
+		args = append(args, filter.GradeYear)
+		conditions = append(conditions, fmt.Sprintf("class_id IN (SELECT id FROM classes WHERE grade_year = $%d)", len(args)))
+	}
+
+	if len(conditions) == 0 {
+		return "", nil
+	}
+	return "WHERE " + strings.Join(conditions, " AND "), args
+}
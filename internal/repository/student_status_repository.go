@@ -0,0 +1,119 @@
+package repository
+
+import (
+	"database/sql"
+	"fmt"
+
+	"student-management-system/internal/domain"
+	"student-management-system/pkg/logger"
+
+	"github.com/lib/pq"
+)
+
+// StudentStatusRepository 学籍异动仓储接口
+type StudentStatusRepository interface {
+	ChangeStatus(change *domain.StudentStatusChange) (bool, error)
+	ListHistory(studentID int) ([]*domain.StudentStatusChange, error)
+}
+
+// studentStatusRepository 学籍异动仓储实现
+type studentStatusRepository struct {
+	db *sql.DB
+}
+
+// NewStudentStatusRepository 创建学籍异动仓储实例
+func NewStudentStatusRepository(db *sql.DB) StudentStatusRepository {
+	return &studentStatusRepository{db: db}
+}
+
+// ChangeStatus 变更学生的学籍状态并写入异动记录，学生当前状态已不是 from_status 时返回 false。
+// 转为毕业时毕业日期同时设为生效日期
+func (r *studentStatusRepository) ChangeStatus(change *domain.StudentStatusChange) (bool, error) {
+	logger.WithFields(map[string]interface{}{
+		"student_id":     change.StudentID,
+		"from_status":    change.FromStatus,
+		"to_status":      change.ToStatus,
+		"effective_date": change.EffectiveDate.Format("2006-01-02"),
+	}).Info("Changing student status")
+
+	if change.Attachments == nil {
+		change.Attachments = []string{}
+	}
+
+	tx, err := r.db.Begin()
+	if err != nil {
+		return false, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	result, err := tx.Exec(`
+		UPDATE students
+		SET status = $2,
+		    graduation_date = CASE WHEN $2 = $4 THEN $5 ELSE graduation_date END
+		WHERE id = $1 AND status = $3
+	`, change.StudentID, change.ToStatus, change.FromStatus, domain.StudentStatusGraduated, change.EffectiveDate)
+	if err != nil {
+		return false, fmt.Errorf("failed to update student status: %w", err)
+	}
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return false, fmt.Errorf("failed to get rows affected: %w", err)
+	}
+	if rowsAffected == 0 {
+		return false, nil
+	}
+
+	err = tx.QueryRow(`
+		INSERT INTO student_status_history (student_id, from_status, to_status, reason, document_no, attachments,
+		                                    effective_date, actor_id)
+		VALUES ($1, $2, $3, $4, NULLIF($5, ''), $6, $7, $8)
+		RETURNING id, created_at
+	`, change.StudentID, change.FromStatus, change.ToStatus, change.Reason, change.DocumentNo,
+		pq.Array(change.Attachments), change.EffectiveDate, change.ActorID).
+		Scan(&change.ID, &change.CreatedAt)
+	if err != nil {
+		return false, fmt.Errorf("failed to create student status history: %w", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return false, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+	return true, nil
+}
+
+// ListHistory 获取学生的学籍异动记录，按生效日期先后排列
+func (r *studentStatusRepository) ListHistory(studentID int) ([]*domain.StudentStatusChange, error) {
+	rows, err := r.db.Query(`
+		SELECT h.id, h.student_id, h.from_status, h.to_status, h.reason, COALESCE(h.document_no, ''), h.attachments,
+		       h.effective_date, h.actor_id, COALESCE(a.name, ''), h.created_at
+		FROM student_status_history h
+		LEFT JOIN admins a ON h.actor_id = a.id
+		WHERE h.student_id = $1
+		ORDER BY h.effective_date, h.id
+	`, studentID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query student status history: %w", err)
+	}
+	defer rows.Close()
+
+	history := []*domain.StudentStatusChange{}
+	for rows.Next() {
+		change := &domain.StudentStatusChange{}
+		var actorID sql.NullInt64
+		if err := rows.Scan(&change.ID, &change.StudentID, &change.FromStatus, &change.ToStatus, &change.Reason,
+			&change.DocumentNo, pq.Array(&change.Attachments), &change.EffectiveDate, &actorID, &change.ActorName,
+			&change.CreatedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan student status history: %w", err)
+		}
+		if actorID.Valid {
+			id := int(actorID.Int64)
+			change.ActorID = &id
+		}
+		history = append(history, change)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to iterate student status history: %w", err)
+	}
+	return history, nil
+}
@@ -0,0 +1,325 @@
+package repository
+
+import (
+	"database/sql"
+	"fmt"
+	"time"
+
+	"student-management-system/internal/domain"
+	"student-management-system/pkg/logger"
+)
+
+// TermRepository 学期仓储接口
+type TermRepository interface {
+	Create(term *domain.Term) error
+	GetByID(id int) (*domain.Term, error)
+	GetByCode(code string) (*domain.Term, error)
+	GetCurrent() (*domain.Term, error)
+	Update(term *domain.Term) error
+	UpdateStatus(id int, status string) error
+	SetCurrent(id int) error
+	Delete(id int) error
+	List(req *domain.TermListRequest) ([]*domain.Term, int64, error)
+	HasReferences(code string) (bool, error)
+	ListOverlapping(from, to time.Time) ([]*domain.Term, error)
+	CreateHoliday(holiday *domain.TermHoliday) error
+	GetHoliday(id int) (*domain.TermHoliday, error)
+	DeleteHoliday(id int) error
+	ListHolidays(termID int) ([]*domain.TermHoliday, error)
+}
+
+// termRepository 学期仓储实现
+type termRepository struct {
+	db *sql.DB
+}
+
+// NewTermRepository 创建学期仓储实例
+func NewTermRepository(db *sql.DB) TermRepository {
+	return &termRepository{db: db}
+}
+
+// termSelect 学期查询的字段
+const termSelect = `
+		SELECT id, code, name, start_date, end_date, is_current, status, created_at, updated_at
+		FROM terms`
+
+// Create 创建学期
+func (r *termRepository) Create(term *domain.Term) error {
+	logger.WithFields(map[string]interface{}{
+		"code": term.Code,
+	}).Info("Creating term")
+
+	query := `
+		INSERT INTO terms (code, name, start_date, end_date, status)
+		VALUES ($1, $2, $3, $4, $5)
+		RETURNING id, is_current, created_at, updated_at
+	`
+
+	err := r.db.QueryRow(query, term.Code, term.Name, term.StartDate, term.EndDate, term.Status).
+		Scan(&term.ID, &term.IsCurrent, &term.CreatedAt, &term.UpdatedAt)
+	if err != nil {
+		logger.WithError(err).Error("Failed to create term")
+		return fmt.Errorf("failed to create term: %w", err)
+	}
+
+	return nil
+}
+
+// GetByID 根据ID获取学期，不存在时返回 nil
+func (r *termRepository) GetByID(id int) (*domain.Term, error) {
+	return r.getOne(termSelect+` WHERE id = $1`, id)
+}
+
+// GetByCode 根据学期代码获取学期，不存在时返回 nil
+func (r *termRepository) GetByCode(code string) (*domain.Term, error) {
+	return r.getOne(termSelect+` WHERE code = $1`, code)
+}
+
+// GetCurrent 获取当前学期，未设置时返回 nil
+func (r *termRepository) GetCurrent() (*domain.Term, error) {
+	return r.getOne(termSelect + ` WHERE is_current`)
+}
+
+// getOne 查询单个学期
+func (r *termRepository) getOne(query string, args ...interface{}) (*domain.Term, error) {
+	term, err := scanTerm(r.db.QueryRow(query, args...))
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get term: %w", err)
+	}
+	return term, nil
+}
+
+// Update 更新学期名称和起止日期
+func (r *termRepository) Update(term *domain.Term) error {
+	logger.WithFields(map[string]interface{}{
+		"term_id": term.ID,
+	}).Info("Updating term")
+
+	err := r.db.QueryRow(
+		`UPDATE terms SET name = $2, start_date = $3, end_date = $4 WHERE id = $1 RETURNING updated_at`,
+		term.ID, term.Name, term.StartDate, term.EndDate,
+	).Scan(&term.UpdatedAt)
+	if err != nil {
+		logger.WithError(err).Error("Failed to update term")
+		return fmt.Errorf("failed to update term: %w", err)
+	}
+
+	return nil
+}
+
+// UpdateStatus 更新学期状态
+func (r *termRepository) UpdateStatus(id int, status string) error {
+	logger.WithFields(map[string]interface{}{
+		"term_id": id,
+		"status":  status,
+	}).Info("Updating term status")
+
+	if _, err := r.db.Exec(`UPDATE terms SET status = $2 WHERE id = $1`, id, status); err != nil {
+		logger.WithError(err).Error("Failed to update term status")
+		return fmt.Errorf("failed to update term status: %w", err)
+	}
+
+	return nil
+}
+
+// SetCurrent 设为当前学期，同时取消其他学期的当前标记
+func (r *termRepository) SetCurrent(id int) error {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.Exec(`UPDATE terms SET is_current = FALSE WHERE is_current AND id <> $1`, id); err != nil {
+		return fmt.Errorf("failed to clear current term: %w", err)
+	}
+
+	if _, err := tx.Exec(`UPDATE terms SET is_current = TRUE WHERE id = $1`, id); err != nil {
+		return fmt.Errorf("failed to set current term: %w", err)
+	}
+
+	return tx.Commit()
+}
+
+// Delete 删除学期
+func (r *termRepository) Delete(id int) error {
+	logger.WithFields(map[string]interface{}{
+		"term_id": id,
+	}).Info("Deleting term")
+
+	if _, err := r.db.Exec(`DELETE FROM terms WHERE id = $1`, id); err != nil {
+		logger.WithError(err).Error("Failed to delete term")
+		return fmt.Errorf("failed to delete term: %w", err)
+	}
+
+	return nil
+}
+
+// List 获取学期列表，按开始日期倒序
+func (r *termRepository) List(req *domain.TermListRequest) ([]*domain.Term, int64, error) {
+	whereClause := ""
+	var args []interface{}
+	if req.Status != "" {
+		whereClause = "WHERE status = $1"
+		args = append(args, req.Status)
+	}
+
+	var total int64
+	if err := r.db.QueryRow(`SELECT COUNT(*) FROM terms `+whereClause, args...).Scan(&total); err != nil {
+		return nil, 0, fmt.Errorf("failed to count terms: %w", err)
+	}
+
+	offset := (req.Page - 1) * req.Size
+	query := fmt.Sprintf(`%s
+		%s
+		ORDER BY start_date DESC, code DESC
+		LIMIT $%d OFFSET $%d
+	`, termSelect, whereClause, len(args)+1, len(args)+2)
+	args = append(args, req.Size, offset)
+
+	rows, err := r.db.Query(query, args...)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to query terms: %w", err)
+	}
+	defer rows.Close()
+
+	var terms []*domain.Term
+	for rows.Next() {
+		term, err := scanTerm(rows)
+		if err != nil {
+			return nil, 0, fmt.Errorf("failed to scan term: %w", err)
+		}
+		terms = append(terms, term)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, 0, fmt.Errorf("failed to iterate terms: %w", err)
+	}
+
+	return terms, total, nil
+}
+
+// HasReferences 检查学期是否已被成绩或开课引用
+func (r *termRepository) HasReferences(code string) (bool, error) {
+	var exists bool
+	err := r.db.QueryRow(`
+		SELECT EXISTS(SELECT 1 FROM scores WHERE semester = $1)
+		    OR EXISTS(SELECT 1 FROM course_offerings WHERE semester = $1)
+	`, code).Scan(&exists)
+	if err != nil {
+		return false, fmt.Errorf("failed to check term references: %w", err)
+	}
+	return exists, nil
+}
+
+// ListOverlapping 获取与日期范围有交集的学期，按开始日期排列
+func (r *termRepository) ListOverlapping(from, to time.Time) ([]*domain.Term, error) {
+	rows, err := r.db.Query(termSelect+` WHERE start_date <= $2 AND end_date >= $1 ORDER BY start_date, code`, from, to)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query terms: %w", err)
+	}
+	defer rows.Close()
+
+	var terms []*domain.Term
+	for rows.Next() {
+		term, err := scanTerm(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan term: %w", err)
+		}
+		terms = append(terms, term)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to iterate terms: %w", err)
+	}
+	return terms, nil
+}
+
+// CreateHoliday 创建学期节假日
+func (r *termRepository) CreateHoliday(holiday *domain.TermHoliday) error {
+	logger.WithFields(map[string]interface{}{
+		"term_id": holiday.TermID,
+		"name":    holiday.Name,
+	}).Info("Creating term holiday")
+
+	err := r.db.QueryRow(`
+		INSERT INTO term_holidays (term_id, name, start_date, end_date)
+		VALUES ($1, $2, $3, $4)
+		RETURNING id, created_at
+	`, holiday.TermID, holiday.Name, holiday.StartDate, holiday.EndDate).Scan(&holiday.ID, &holiday.CreatedAt)
+	if err != nil {
+		logger.WithError(err).Error("Failed to create term holiday")
+		return fmt.Errorf("failed to create term holiday: %w", err)
+	}
+
+	return nil
+}
+
+// GetHoliday 根据ID获取学期节假日，不存在时返回 nil
+func (r *termRepository) GetHoliday(id int) (*domain.TermHoliday, error) {
+	holiday := &domain.TermHoliday{}
+	err := r.db.QueryRow(`SELECT id, term_id, name, start_date, end_date, created_at FROM term_holidays WHERE id = $1`, id).
+		Scan(&holiday.ID, &holiday.TermID, &holiday.Name, &holiday.StartDate, &holiday.EndDate, &holiday.CreatedAt)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get term holiday: %w", err)
+	}
+	return holiday, nil
+}
+
+// DeleteHoliday 删除学期节假日
+func (r *termRepository) DeleteHoliday(id int) error {
+	logger.WithFields(map[string]interface{}{
+		"holiday_id": id,
+	}).Info("Deleting term holiday")
+
+	if _, err := r.db.Exec(`DELETE FROM term_holidays WHERE id = $1`, id); err != nil {
+		logger.WithError(err).Error("Failed to delete term holiday")
+		return fmt.Errorf("failed to delete term holiday: %w", err)
+	}
+	return nil
+}
+
+// ListHolidays 获取学期的全部节假日，按开始日期排列
+func (r *termRepository) ListHolidays(termID int) ([]*domain.TermHoliday, error) {
+	rows, err := r.db.Query(`
+		SELECT id, term_id, name, start_date, end_date, created_at
+		FROM term_holidays
+		WHERE term_id = $1
+		ORDER BY start_date, id
+	`, termID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query term holidays: %w", err)
+	}
+	defer rows.Close()
+
+	var holidays []*domain.TermHoliday
+	for rows.Next() {
+		holiday := &domain.TermHoliday{}
+		if err := rows.Scan(&holiday.ID, &holiday.TermID, &holiday.Name, &holiday.StartDate, &holiday.EndDate,
+			&holiday.CreatedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan term holiday: %w", err)
+		}
+		holidays = append(holidays, holiday)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to iterate term holidays: %w", err)
+	}
+	return holidays, nil
+}
+
+// scanTerm 扫描学期查询的一行
+func scanTerm(row rowScanner) (*domain.Term, error) {
+	term := &domain.Term{}
+	if err := row.Scan(&term.ID, &term.Code, &term.Name, &term.StartDate, &term.EndDate, &term.IsCurrent,
+		&term.Status, &term.CreatedAt, &term.UpdatedAt); err != nil {
+		return nil, err
+	}
+	return term, nil
+}
@@ -0,0 +1,300 @@
+package repository
+
+import (
+	"database/sql"
+	"fmt"
+	"strings"
+
+	"student-management-system/internal/domain"
+	"student-management-system/pkg/logger"
+)
+
+// TimetableRepository 课表仓储接口
+type TimetableRepository interface {
+	Save(slots []*domain.TimetableSlot) ([]*domain.TimetableConflict, error)
+	GetByID(id int) (*domain.TimetableSlot, error)
+	Delete(id int) error
+	List(req *domain.TimetableSlotListRequest) ([]*domain.TimetableSlot, int64, error)
+	ListBySemester(semester string) ([]*domain.TimetableSlot, error)
+	ListForOwner(semester, ownerType string, ownerID int) ([]*domain.TimetableSlot, error)
+}
+
+// timetableRepository 课表仓储实现
+type timetableRepository struct {
+	db *sql.DB
+}
+
+// NewTimetableRepository 创建课表仓储实例
+func NewTimetableRepository(db *sql.DB) TimetableRepository {
+	return &timetableRepository{db: db}
+}
+
+// timetableSlotSelect 课表时段查询的字段和关联
+const timetableSlotSelect = `
+		SELECT ts.id, ts.offering_id, ts.room_id, ts.teacher_id, ts.class_id, ts.day_of_week, ts.start_period, ts.end_period,
+		       COALESCE(ts.note, ''), ts.created_by, ts.created_at, ts.updated_at,
+		       o.semester, o.subject_id, COALESCE(sub.code, ''), COALESCE(sub.name, ''), o.section,
+		       r.code, r.name, COALESCE(t.name, ''), COALESCE(c.name, '')
+		FROM timetable_slots ts
+		JOIN course_offerings o ON ts.offering_id = o.id
+		JOIN rooms r ON ts.room_id = r.id
+		LEFT JOIN subjects sub ON o.subject_id = sub.id
+		LEFT JOIN teachers t ON ts.teacher_id = t.id
+		LEFT JOIN classes c ON ts.class_id = c.id`
+
+// timetableSlotOrder 课表时段的默认排序
+const timetableSlotOrder = ` ORDER BY ts.day_of_week, ts.start_period, r.code`
+
+// Save 在同一事务中创建（ID 为 0）或更新课表时段。任一时段与同学期已有时段存在教师、教室或行政班的
+// 时间冲突时不保存任何时段，返回冲突列表
+func (r *timetableRepository) Save(slots []*domain.TimetableSlot) ([]*domain.TimetableConflict, error) {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	// 排课串行执行，避免并发写入的时段之间互相冲突
+	if _, err := tx.Exec(`LOCK TABLE timetable_slots IN SHARE ROW EXCLUSIVE MODE`); err != nil {
+		return nil, fmt.Errorf("failed to lock timetable slots: %w", err)
+	}
+
+	var conflicts []*domain.TimetableConflict
+	for _, slot := range slots {
+		found, err := findSlotConflicts(tx, slot)
+		if err != nil {
+			return nil, err
+		}
+		if len(found) > 0 {
+			conflicts = append(conflicts, found...)
+			continue
+		}
+
+		if slot.ID == 0 {
+			err = tx.QueryRow(`
+				INSERT INTO timetable_slots (offering_id, room_id, teacher_id, class_id, day_of_week, start_period, end_period, note, created_by)
+				VALUES ($1, $2, $3, $4, $5, $6, $7, NULLIF($8, ''), $9)
+				RETURNING id, created_at, updated_at
+			`, slot.OfferingID, slot.RoomID, slot.TeacherID, slot.ClassID, slot.DayOfWeek, slot.StartPeriod, slot.EndPeriod,
+				slot.Note, slot.CreatedBy).Scan(&slot.ID, &slot.CreatedAt, &slot.UpdatedAt)
+		} else {
+			err = tx.QueryRow(`
+				UPDATE timetable_slots
+				SET room_id = $2, teacher_id = $3, class_id = $4, day_of_week = $5, start_period = $6, end_period = $7, note = NULLIF($8, '')
+				WHERE id = $1
+				RETURNING updated_at
+			`, slot.ID, slot.RoomID, slot.TeacherID, slot.ClassID, slot.DayOfWeek, slot.StartPeriod, slot.EndPeriod,
+				slot.Note).Scan(&slot.UpdatedAt)
+		}
+		if err != nil {
+			logger.WithError(err).WithFields(map[string]interface{}{
+				"offering_id": slot.OfferingID,
+				"slot_id":     slot.ID,
+			}).Error("Failed to save timetable slot")
+			return nil, fmt.Errorf("failed to save timetable slot: %w", err)
+		}
+	}
+
+	if len(conflicts) > 0 {
+		return conflicts, nil
+	}
+	return nil, tx.Commit()
+}
+
+// findSlotConflicts 查找与时段同学期、同一天、节次重叠，且教师、教室或行政班相同的其他时段
+func findSlotConflicts(tx *sql.Tx, slot *domain.TimetableSlot) ([]*domain.TimetableConflict, error) {
+	rows, err := tx.Query(timetableSlotSelect+`
+		WHERE o.semester = (SELECT semester FROM course_offerings WHERE id = $1)
+		  AND ts.id <> $2 AND ts.day_of_week = $3 AND ts.start_period <= $5 AND ts.end_period >= $4
+		  AND (ts.room_id = $6 OR ts.teacher_id = $7 OR ts.class_id = $8)
+	`+timetableSlotOrder, slot.OfferingID, slot.ID, slot.DayOfWeek, slot.StartPeriod, slot.EndPeriod,
+		slot.RoomID, slot.TeacherID, slot.ClassID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query timetable conflicts: %w", err)
+	}
+	defer rows.Close()
+
+	var conflicts []*domain.TimetableConflict
+	for rows.Next() {
+		other, err := scanTimetableSlot(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan timetable conflict: %w", err)
+		}
+		if slot.TeacherID != nil && other.TeacherID != nil && *slot.TeacherID == *other.TeacherID {
+			conflicts = append(conflicts, &domain.TimetableConflict{Type: domain.TimetableConflictTeacher, Slot: other})
+		}
+		if slot.RoomID == other.RoomID {
+			conflicts = append(conflicts, &domain.TimetableConflict{Type: domain.TimetableConflictRoom, Slot: other})
+		}
+		if slot.ClassID != nil && other.ClassID != nil && *slot.ClassID == *other.ClassID {
+			conflicts = append(conflicts, &domain.TimetableConflict{Type: domain.TimetableConflictClass, Slot: other})
+		}
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to iterate timetable conflicts: %w", err)
+	}
+	return conflicts, nil
+}
+
+// GetByID 根据ID获取课表时段，不存在时返回 nil
+func (r *timetableRepository) GetByID(id int) (*domain.TimetableSlot, error) {
+	slot, err := scanTimetableSlot(r.db.QueryRow(timetableSlotSelect+` WHERE ts.id = $1`, id))
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get timetable slot: %w", err)
+	}
+	return slot, nil
+}
+
+// Delete 删除课表时段
+func (r *timetableRepository) Delete(id int) error {
+	logger.WithFields(map[string]interface{}{
+		"slot_id": id,
+	}).Info("Deleting timetable slot")
+
+	if _, err := r.db.Exec(`DELETE FROM timetable_slots WHERE id = $1`, id); err != nil {
+		logger.WithError(err).Error("Failed to delete timetable slot")
+		return fmt.Errorf("failed to delete timetable slot: %w", err)
+	}
+	return nil
+}
+
+// List 获取课表时段列表（分页）
+func (r *timetableRepository) List(req *domain.TimetableSlotListRequest) ([]*domain.TimetableSlot, int64, error) {
+	var conditions []string
+	var args []interface{}
+	argIndex := 1
+
+	if req.Semester != "" {
+		conditions = append(conditions, fmt.Sprintf("o.semester = $%d", argIndex))
+		args = append(args, req.Semester)
+		argIndex++
+	}
+	if req.OfferingID > 0 {
+		conditions = append(conditions, fmt.Sprintf("ts.offering_id = $%d", argIndex))
+		args = append(args, req.OfferingID)
+		argIndex++
+	}
+	if req.RoomID > 0 {
+		conditions = append(conditions, fmt.Sprintf("ts.room_id = $%d", argIndex))
+		args = append(args, req.RoomID)
+		argIndex++
+	}
+	if req.TeacherID > 0 {
+		conditions = append(conditions, fmt.Sprintf("ts.teacher_id = $%d", argIndex))
+		args = append(args, req.TeacherID)
+		argIndex++
+	}
+	if req.ClassID > 0 {
+		conditions = append(conditions, fmt.Sprintf("ts.class_id = $%d", argIndex))
+		args = append(args, req.ClassID)
+		argIndex++
+	}
+	if req.DayOfWeek > 0 {
+		conditions = append(conditions, fmt.Sprintf("ts.day_of_week = $%d", argIndex))
+		args = append(args, req.DayOfWeek)
+		argIndex++
+	}
+
+	whereClause := ""
+	if len(conditions) > 0 {
+		whereClause = " WHERE " + strings.Join(conditions, " AND ")
+	}
+
+	var total int64
+	countQuery := `SELECT COUNT(*) FROM timetable_slots ts JOIN course_offerings o ON ts.offering_id = o.id` + whereClause
+	if err := r.db.QueryRow(countQuery, args...).Scan(&total); err != nil {
+		return nil, 0, fmt.Errorf("failed to count timetable slots: %w", err)
+	}
+
+	query := fmt.Sprintf(`%s%s%s LIMIT $%d OFFSET $%d`, timetableSlotSelect, whereClause, timetableSlotOrder, argIndex, argIndex+1)
+	args = append(args, req.Size, (req.Page-1)*req.Size)
+
+	slots, err := r.querySlots(query, args...)
+	if err != nil {
+		return nil, 0, err
+	}
+	return slots, total, nil
+}
+
+// ListBySemester 获取学期的全部课表时段
+func (r *timetableRepository) ListBySemester(semester string) ([]*domain.TimetableSlot, error) {
+	return r.querySlots(timetableSlotSelect+` WHERE o.semester = $1`+timetableSlotOrder, semester)
+}
+
+// ListForOwner 获取教师、行政班、学生或教室在学期内的课表时段。学生的课表包括已选上的开课，
+// 以及学期内所在行政班的课程
+func (r *timetableRepository) ListForOwner(semester, ownerType string, ownerID int) ([]*domain.TimetableSlot, error) {
+	var condition string
+	switch ownerType {
+	case domain.TimetableOwnerTeacher:
+		condition = `ts.teacher_id = $2`
+	case domain.TimetableOwnerClass:
+		condition = `ts.class_id = $2`
+	case domain.TimetableOwnerRoom:
+		condition = `ts.room_id = $2`
+	case domain.TimetableOwnerStudent:
+		condition = `(EXISTS (
+			SELECT 1 FROM enrollments e WHERE e.offering_id = ts.offering_id AND e.student_id = $2 AND e.status = '` + domain.EnrollmentStatusEnrolled + `'
+		) OR EXISTS (
+			SELECT 1 FROM class_memberships cm JOIN terms tm ON tm.code = o.semester
+			WHERE cm.student_id = $2 AND cm.class_id = ts.class_id
+			  AND cm.joined_at <= tm.end_date AND (cm.left_at IS NULL OR cm.left_at > tm.start_date)
+		))`
+	default:
+		return nil, fmt.Errorf("unknown timetable owner type: %s", ownerType)
+	}
+
+	return r.querySlots(timetableSlotSelect+` WHERE o.semester = $1 AND `+condition+timetableSlotOrder, semester, ownerID)
+}
+
+// querySlots 查询课表时段列表
+func (r *timetableRepository) querySlots(query string, args ...interface{}) ([]*domain.TimetableSlot, error) {
+	rows, err := r.db.Query(query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query timetable slots: %w", err)
+	}
+	defer rows.Close()
+
+	var slots []*domain.TimetableSlot
+	for rows.Next() {
+		slot, err := scanTimetableSlot(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan timetable slot: %w", err)
+		}
+		slots = append(slots, slot)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to iterate timetable slots: %w", err)
+	}
+	return slots, nil
+}
+
+// scanTimetableSlot 扫描课表时段查询的一行
+func scanTimetableSlot(row rowScanner) (*domain.TimetableSlot, error) {
+	slot := &domain.TimetableSlot{}
+	var teacherID, classID, createdBy sql.NullInt64
+	if err := row.Scan(&slot.ID, &slot.OfferingID, &slot.RoomID, &teacherID, &classID, &slot.DayOfWeek,
+		&slot.StartPeriod, &slot.EndPeriod, &slot.Note, &createdBy, &slot.CreatedAt, &slot.UpdatedAt,
+		&slot.Semester, &slot.SubjectID, &slot.SubjectCode, &slot.SubjectName, &slot.Section,
+		&slot.RoomCode, &slot.RoomName, &slot.TeacherName, &slot.ClassName); err != nil {
+		return nil, err
+	}
+	if teacherID.Valid {
+		id := int(teacherID.Int64)
+		slot.TeacherID = &id
+	}
+	if classID.Valid {
+		id := int(classID.Int64)
+		slot.ClassID = &id
+	}
+	if createdBy.Valid {
+		id := int(createdBy.Int64)
+		slot.CreatedBy = &id
+	}
+	return slot, nil
+}
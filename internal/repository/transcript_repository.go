@@ -0,0 +1,106 @@
+package repository
+
+import (
+	"database/sql"
+	"fmt"
+
+	"student-management-system/internal/domain"
+	"student-management-system/pkg/logger"
+
+	"github.com/lib/pq"
+)
+
+// TranscriptRepository 成绩单仓储接口
+type TranscriptRepository interface {
+	ListStudentScores(studentID int) ([]*domain.TranscriptScore, error)
+	CreateVerification(v *domain.TranscriptVerification) error
+	GetVerification(code string) (*domain.TranscriptVerification, error)
+}
+
+// transcriptRepository 成绩单仓储实现
+type transcriptRepository struct {
+	db *sql.DB
+}
+
+// NewTranscriptRepository 创建成绩单仓储实例
+func NewTranscriptRepository(db *sql.DB) TranscriptRepository {
+	return &transcriptRepository{db: db}
+}
+
+// ListStudentScores 获取学生所有学期、所有考试类型已发布的成绩及科目学分
+func (r *transcriptRepository) ListStudentScores(studentID int) ([]*domain.TranscriptScore, error) {
+	query := `
+		SELECT s.semester, sub.id, sub.code, sub.name, sub.credits, s.exam_type, s.score
+		FROM scores s
+		JOIN subjects sub ON s.subject_id = sub.id
+		WHERE s.student_id = $1 AND s.status = ANY($2)
+		ORDER BY s.semester, sub.code
+	`
+
+	rows, err := r.db.Query(query, studentID, pq.Array(domain.PublishedScoreStatuses))
+	if err != nil {
+		return nil, fmt.Errorf("failed to query transcript scores: %w", err)
+	}
+	defer rows.Close()
+
+	var scores []*domain.TranscriptScore
+	for rows.Next() {
+		score := &domain.TranscriptScore{}
+		if err := rows.Scan(&score.Semester, &score.SubjectID, &score.SubjectCode, &score.SubjectName,
+			&score.Credits, &score.ExamType, &score.Score); err != nil {
+			return nil, fmt.Errorf("failed to scan transcript score: %w", err)
+		}
+		scores = append(scores, score)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to iterate transcript scores: %w", err)
+	}
+
+	return scores, nil
+}
+
+// CreateVerification 保存成绩单签发记录
+func (r *transcriptRepository) CreateVerification(v *domain.TranscriptVerification) error {
+	query := `
+		INSERT INTO transcript_verifications (code, kind, student_id, semester, total_credits, cumulative_gpa, grade_scale, checksum, issued_by, issued_at)
+		VALUES ($1, $2, $3, NULLIF($4, ''), $5, $6, $7, $8, $9, $10)
+		RETURNING id
+	`
+
+	err := r.db.QueryRow(query, v.Code, v.Kind, v.StudentID, v.Semester, v.TotalCredits,
+		v.CumulativeGPA, v.GradeScale, v.Checksum, v.IssuedBy, v.IssuedAt).Scan(&v.ID)
+	if err != nil {
+		logger.Error("Failed to create transcript verification", "student_id", v.StudentID, "error", err)
+		return fmt.Errorf("failed to create transcript verification: %w", err)
+	}
+
+	return nil
+}
+
+// GetVerification 根据验证码获取签发记录，不存在时返回 nil
+func (r *transcriptRepository) GetVerification(code string) (*domain.TranscriptVerification, error) {
+	query := `
+		SELECT id, code, kind, student_id, COALESCE(semester, ''), total_credits, cumulative_gpa, grade_scale, checksum, issued_by, issued_at
+		FROM transcript_verifications
+		WHERE code = $1
+	`
+
+	v := &domain.TranscriptVerification{}
+	var issuedBy sql.NullInt64
+	err := r.db.QueryRow(query, code).Scan(&v.ID, &v.Code, &v.Kind, &v.StudentID, &v.Semester,
+		&v.TotalCredits, &v.CumulativeGPA, &v.GradeScale, &v.Checksum, &issuedBy, &v.IssuedAt)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to get transcript verification: %w", err)
+	}
+
+	if issuedBy.Valid {
+		id := int(issuedBy.Int64)
+		v.IssuedBy = &id
+	}
+
+	return v, nil
+}
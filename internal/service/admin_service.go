@@ -1,23 +1,26 @@
 package service
 
 import (
-	"crypto/md5"
 	"fmt"
 
 	"student-management-system/internal/domain"
 	"student-management-system/internal/repository"
+	"student-management-system/pkg/password"
+	"student-management-system/pkg/utils"
 
 	"github.com/sirupsen/logrus"
 )
 
 type AdminService struct {
 	adminRepo *repository.AdminRepository
+	passwords *password.Manager
 	logger    *logrus.Logger
 }
 
-func NewAdminService(adminRepo *repository.AdminRepository, logger *logrus.Logger) *AdminService {
+func NewAdminService(adminRepo *repository.AdminRepository, passwords *password.Manager, logger *logrus.Logger) *AdminService {
 	return &AdminService{
 		adminRepo: adminRepo,
+		passwords: passwords,
 		logger:    logger,
 	}
 }
@@ -30,8 +33,11 @@ func (s *AdminService) CreateAdmin(req *domain.CreateAdminRequest) (*domain.Admi
 		return nil, fmt.Errorf("账号已存在")
 	}
 
-	// 密码加密
-	hashedPassword := s.md5Password(req.Password)
+	// 校验密码策略并加密
+	hashedPassword, err := s.hashPassword(req.Password, req.Account)
+	if err != nil {
+		return nil, err
+	}
 
 	// 创建管理员对象
 	admin := &domain.Admin{
@@ -42,6 +48,14 @@ func (s *AdminService) CreateAdmin(req *domain.CreateAdminRequest) (*domain.Admi
 		Email:    req.Email,
 	}
 
+	role := req.Role
+	if role == "" {
+		role = domain.RoleAdmin
+	}
+	if err := applyRole(admin, role, req.TeacherID, req.StudentID, req.StudentIDs); err != nil {
+		return nil, err
+	}
+
 	// 保存到数据库
 	err = s.adminRepo.CreateAdmin(admin)
 	if err != nil {
@@ -49,16 +63,10 @@ func (s *AdminService) CreateAdmin(req *domain.CreateAdminRequest) (*domain.Admi
 	}
 
 	// 返回管理员信息（不包含密码）
-	adminInfo := &domain.AdminInfo{
-		ID:      admin.ID,
-		Account: admin.Account,
-		Name:    admin.Name,
-		Phone:   admin.Phone,
-		Email:   admin.Email,
-	}
+	adminInfo := admin.ToInfo()
 
 	s.logger.WithField("admin_id", admin.ID).Info("Admin created successfully")
-	return adminInfo, nil
+	return &adminInfo, nil
 }
 
 // GetAdminByID 根据ID获取管理员信息
@@ -68,15 +76,8 @@ func (s *AdminService) GetAdminByID(id int) (*domain.AdminInfo, error) {
 		return nil, fmt.Errorf("获取管理员信息失败: %v", err)
 	}
 
-	adminInfo := &domain.AdminInfo{
-		ID:      admin.ID,
-		Account: admin.Account,
-		Name:    admin.Name,
-		Phone:   admin.Phone,
-		Email:   admin.Email,
-	}
-
-	return adminInfo, nil
+	adminInfo := admin.ToInfo()
+	return &adminInfo, nil
 }
 
 // UpdateAdmin 更新管理员信息
@@ -107,9 +108,32 @@ func (s *AdminService) UpdateAdmin(id int, req *domain.UpdateAdminRequest) (*dom
 		admin.Email = req.Email
 	}
 
+	// 如果要更新角色，未填写的关联信息沿用原值
+	if req.Role != "" {
+		teacherID, studentID, studentIDs := req.TeacherID, req.StudentID, req.StudentIDs
+		if req.Role == admin.Role {
+			if teacherID == 0 && admin.TeacherID != nil {
+				teacherID = *admin.TeacherID
+			}
+			if studentID == 0 && admin.StudentID != nil {
+				studentID = *admin.StudentID
+			}
+			if len(studentIDs) == 0 {
+				studentIDs = admin.StudentIDs
+			}
+		}
+		if err := applyRole(admin, req.Role, teacherID, studentID, studentIDs); err != nil {
+			return nil, err
+		}
+	}
+
 	// 如果要更新密码
 	if req.Password != "" {
-		admin.Password = s.md5Password(req.Password)
+		hashedPassword, err := s.hashPassword(req.Password, admin.Account)
+		if err != nil {
+			return nil, err
+		}
+		admin.Password = hashedPassword
 	}
 
 	// 保存更新
@@ -119,16 +143,10 @@ func (s *AdminService) UpdateAdmin(id int, req *domain.UpdateAdminRequest) (*dom
 	}
 
 	// 返回更新后的管理员信息
-	adminInfo := &domain.AdminInfo{
-		ID:      admin.ID,
-		Account: admin.Account,
-		Name:    admin.Name,
-		Phone:   admin.Phone,
-		Email:   admin.Email,
-	}
+	adminInfo := admin.ToInfo()
 
 	s.logger.WithField("admin_id", id).Info("Admin updated successfully")
-	return adminInfo, nil
+	return &adminInfo, nil
 }
 
 // DeleteAdmin 删除管理员
@@ -149,6 +167,52 @@ func (s *AdminService) DeleteAdmin(id int) error {
 	return nil
 }
 
+// ListAdminSessions 获取指定账号的全部有效会话
+func (s *AdminService) ListAdminSessions(id int) ([]*domain.Session, error) {
+	if _, err := s.adminRepo.GetAdminByID(id); err != nil {
+		return nil, fmt.Errorf("管理员不存在")
+	}
+
+	sessions, err := utils.ListSessions(id)
+	if err != nil {
+		return nil, fmt.Errorf("获取会话列表失败: %v", err)
+	}
+
+	return sessions, nil
+}
+
+// ForceLogout 强制指定账号在所有设备上登出，返回失效的会话数量
+func (s *AdminService) ForceLogout(id int) (int, error) {
+	if _, err := s.adminRepo.GetAdminByID(id); err != nil {
+		return 0, fmt.Errorf("管理员不存在")
+	}
+
+	revoked, err := utils.InvalidateOtherSessions(id, "")
+	if err != nil {
+		return 0, fmt.Errorf("强制登出失败: %v", err)
+	}
+
+	s.logger.WithFields(logrus.Fields{
+		"admin_id": id,
+		"revoked":  revoked,
+	}).Info("Admin force logged out")
+	return revoked, nil
+}
+
+// ResetTwoFactor 重置账号的两步验证（如手机丢失），同时注销该账号全部会话，下次登录时按需重新绑定
+func (s *AdminService) ResetTwoFactor(id int) error {
+	if err := s.adminRepo.DisableTOTP(id); err != nil {
+		return fmt.Errorf("重置两步验证失败: %v", err)
+	}
+
+	if _, err := utils.InvalidateOtherSessions(id, ""); err != nil {
+		s.logger.WithError(err).WithField("admin_id", id).Warn("Failed to invalidate sessions after two-factor reset")
+	}
+
+	s.logger.WithField("admin_id", id).Info("Admin two-factor reset")
+	return nil
+}
+
 // ListAdmins 获取管理员列表
 func (s *AdminService) ListAdmins(req *domain.AdminListRequest) (*domain.AdminListResponse, error) {
 	// 参数验证
@@ -171,14 +235,7 @@ func (s *AdminService) ListAdmins(req *domain.AdminListRequest) (*domain.AdminLi
 	// 转换为响应格式（不包含密码）
 	var adminInfos []domain.AdminInfo
 	for _, admin := range admins {
-		adminInfo := domain.AdminInfo{
-			ID:      admin.ID,
-			Account: admin.Account,
-			Name:    admin.Name,
-			Phone:   admin.Phone,
-			Email:   admin.Email,
-		}
-		adminInfos = append(adminInfos, adminInfo)
+		adminInfos = append(adminInfos, admin.ToInfo())
 	}
 
 	response := &domain.AdminListResponse{
@@ -200,27 +257,98 @@ func (s *AdminService) ValidateAdmin(account, password string) (*domain.Admin, e
 	}
 
 	// 验证密码
-	if s.md5Password(password) != admin.Password {
+	ok, _, err := s.passwords.Verify(password, admin.Password)
+	if err != nil || !ok {
 		return nil, fmt.Errorf("账号或密码错误")
 	}
 
 	return admin, nil
 }
 
-// md5Password MD5密码加密
-func (s *AdminService) md5Password(password string) string {
-	h := md5.New()
-	h.Write([]byte(password))
-	return fmt.Sprintf("%x", h.Sum(nil))
+// hashPassword 校验密码策略后使用首选算法加密
+func (s *AdminService) hashPassword(plain, account string) (string, error) {
+	if err := s.passwords.Policy().Validate(plain, account); err != nil {
+		return "", fmt.Errorf("密码不符合安全策略: %v", err)
+	}
+
+	hashed, err := s.passwords.Hash(plain)
+	if err != nil {
+		return "", fmt.Errorf("密码加密失败: %v", err)
+	}
+
+	return hashed, nil
+}
+
+// PasswordHashReport 统计各账号的密码哈希算法，列出仍在使用历史MD5（或无法识别）的账号
+func (s *AdminService) PasswordHashReport() (*domain.PasswordHashReport, error) {
+	admins, err := s.adminRepo.ListAllAdmins()
+	if err != nil {
+		return nil, fmt.Errorf("获取管理员列表失败: %v", err)
+	}
+
+	report := &domain.PasswordHashReport{
+		PreferredAlgorithm: s.passwords.Algorithm(),
+		Counts:             make(map[string]int),
+		LegacyAccounts:     []domain.LegacyPasswordAccount{},
+	}
+
+	for _, admin := range admins {
+		algorithm := s.passwords.Identify(admin.Password)
+		if algorithm == "" {
+			algorithm = "unknown"
+		}
+		report.Counts[algorithm]++
+		report.Total++
+
+		if algorithm == password.AlgorithmMD5 || algorithm == "unknown" {
+			report.LegacyAccounts = append(report.LegacyAccounts, domain.LegacyPasswordAccount{
+				ID:        admin.ID,
+				Account:   admin.Account,
+				Name:      admin.Name,
+				Role:      admin.Role,
+				Algorithm: algorithm,
+				UpdatedAt: admin.UpdatedAt,
+			})
+		}
+	}
+
+	s.logger.WithField("legacy_accounts", len(report.LegacyAccounts)).Info("Password hash report generated")
+	return report, nil
 }
 
 // GetAdminInfo 获取管理员信息（不包含密码）
 func (s *AdminService) GetAdminInfo(admin *domain.Admin) *domain.AdminInfo {
-	return &domain.AdminInfo{
-		ID:      admin.ID,
-		Account: admin.Account,
-		Name:    admin.Name,
-		Phone:   admin.Phone,
-		Email:   admin.Email,
+	adminInfo := admin.ToInfo()
+	return &adminInfo
+}
+
+// applyRole 设置账号角色并校验角色所需的关联信息
+func applyRole(admin *domain.Admin, role string, teacherID, studentID int, studentIDs []int) error {
+	admin.Role = role
+	admin.TeacherID = nil
+	admin.StudentID = nil
+	admin.StudentIDs = nil
+
+	switch role {
+	case domain.RoleAdmin:
+	case domain.RoleTeacher:
+		if teacherID <= 0 {
+			return fmt.Errorf("教师账号必须关联教师ID")
+		}
+		admin.TeacherID = &teacherID
+	case domain.RoleStudent:
+		if studentID <= 0 {
+			return fmt.Errorf("学生账号必须关联学生ID")
+		}
+		admin.StudentID = &studentID
+	case domain.RoleParent:
+		if len(studentIDs) == 0 {
+			return fmt.Errorf("家长账号必须关联至少一个学生ID")
+		}
+		admin.StudentIDs = studentIDs
+	default:
+		return fmt.Errorf("未知角色: %s", role)
 	}
+
+	return nil
 }
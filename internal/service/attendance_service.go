@@ -0,0 +1,508 @@
+package service
+
+import (
+	"fmt"
+	"math"
+	"time"
+
+	"student-management-system/internal/config"
+	"student-management-system/internal/domain"
+	"student-management-system/internal/repository"
+	"student-management-system/pkg/errors"
+	"student-management-system/pkg/logger"
+)
+
+// AttendanceService 考勤服务：按课次批量记录学生出勤，汇总学生和学期的考勤，
+// 缺勤率超过配置的阈值时预警或取消该科目的期末考试资格
+type AttendanceService struct {
+	attendanceRepo  repository.AttendanceRepository
+	offeringRepo    repository.CourseOfferingRepository
+	timetableRepo   repository.TimetableRepository
+	termRepo        repository.TermRepository
+	studentRepo     repository.StudentRepository
+	leaveRepo       repository.LeaveRepository
+	latesPerAbsence int
+	countLeave      bool
+	minSessions     int
+	atRiskRate      float64
+	examBlockRate   float64
+}
+
+// NewAttendanceService 创建考勤服务实例，校验考勤阈值配置
+func NewAttendanceService(cfg config.AttendanceConfig, attendanceRepo repository.AttendanceRepository, offeringRepo repository.CourseOfferingRepository, timetableRepo repository.TimetableRepository, termRepo repository.TermRepository, studentRepo repository.StudentRepository, leaveRepo repository.LeaveRepository) (*AttendanceService, error) {
+	if cfg.LatesPerAbsence < 0 {
+		return nil, fmt.Errorf("attendance lates_per_absence must not be negative, got %d", cfg.LatesPerAbsence)
+	}
+	if cfg.MinSessions < 0 {
+		return nil, fmt.Errorf("attendance min_sessions must not be negative, got %d", cfg.MinSessions)
+	}
+	if cfg.AtRiskRate <= 0 || cfg.AtRiskRate > 1 {
+		return nil, fmt.Errorf("attendance at_risk_rate must be in (0, 1], got %v", cfg.AtRiskRate)
+	}
+	if cfg.ExamBlockRate < cfg.AtRiskRate || cfg.ExamBlockRate > 1 {
+		return nil, fmt.Errorf("attendance exam_block_rate must be between at_risk_rate and 1, got %v", cfg.ExamBlockRate)
+	}
+
+	return &AttendanceService{
+		attendanceRepo:  attendanceRepo,
+		offeringRepo:    offeringRepo,
+		timetableRepo:   timetableRepo,
+		termRepo:        termRepo,
+		studentRepo:     studentRepo,
+		leaveRepo:       leaveRepo,
+		latesPerAbsence: cfg.LatesPerAbsence,
+		countLeave:      cfg.CountLeave,
+		minSessions:     cfg.MinSessions,
+		atRiskRate:      cfg.AtRiskRate,
+		examBlockRate:   cfg.ExamBlockRate,
+	}, nil
+}
+
+// RecordAttendance 批量记录一次课的考勤。考勤名单为已选上该开课的学生和上课行政班的学生，
+// 请求中未列出的学生记为默认状态（默认出勤）；当天有已批准请假的学生未列出或记为旷课时改记为请假。
+// 同一课次重复提交时覆盖原有考勤
+func (s *AttendanceService) RecordAttendance(actor *domain.JWTClaims, req domain.RecordAttendanceRequest) (*domain.AttendanceSession, error) {
+	logger.WithFields(map[string]interface{}{
+		"offering_id":  req.OfferingID,
+		"session_date": req.SessionDate.Format("2006-01-02"),
+		"slot_id":      req.SlotID,
+		"admin_id":     actor.AdminID,
+	}).Info("Recording attendance")
+
+	offering, err := s.getOffering(req.OfferingID)
+	if err != nil {
+		return nil, err
+	}
+	if offering.Status == domain.OfferingStatusCancelled {
+		return nil, errors.Newf(errors.ErrCodeConflict, "开课 %d 已取消，不能记录考勤", offering.ID)
+	}
+
+	session := &domain.AttendanceSession{
+		OfferingID:  offering.ID,
+		SessionDate: time.Date(req.SessionDate.Year(), req.SessionDate.Month(), req.SessionDate.Day(), 0, 0, 0, 0, time.UTC),
+		StartPeriod: req.StartPeriod,
+		EndPeriod:   req.EndPeriod,
+	}
+
+	var slot *domain.TimetableSlot
+	if req.SlotID > 0 {
+		if slot, err = s.timetableRepo.GetByID(req.SlotID); err != nil {
+			return nil, err
+		}
+		if slot == nil || slot.OfferingID != offering.ID {
+			return nil, errors.Newf(errors.ErrCodeValidation, "课表时段 %d 不属于该开课", req.SlotID)
+		}
+		if weekday := isoWeekday(session.SessionDate); weekday != slot.DayOfWeek {
+			return nil, errors.Newf(errors.ErrCodeValidation, "%s 是%s，该课表时段安排在%s",
+				session.SessionDate.Format("2006-01-02"), domain.WeekdayNames[weekday], domain.WeekdayNames[slot.DayOfWeek])
+		}
+		session.SlotID = &slot.ID
+		session.ClassID = slot.ClassID
+		session.StartPeriod = slot.StartPeriod
+		session.EndPeriod = slot.EndPeriod
+	} else if session.StartPeriod == 0 {
+		return nil, errors.New(errors.ErrCodeValidation, "请指定课表时段或开始节次")
+	}
+	if session.EndPeriod == 0 {
+		session.EndPeriod = session.StartPeriod
+	}
+	if session.EndPeriod < session.StartPeriod {
+		return nil, errors.New(errors.ErrCodeValidation, "结束节次不能早于开始节次")
+	}
+
+	if err := s.authorizeWrite(actor, offering, slot); err != nil {
+		return nil, err
+	}
+	if err := s.checkSessionDate(offering.Semester, session.SessionDate); err != nil {
+		return nil, err
+	}
+
+	roster, err := s.attendanceRepo.ListRoster(offering.ID, session.ClassID, session.SessionDate)
+	if err != nil {
+		return nil, err
+	}
+	if len(roster) == 0 {
+		return nil, errors.New(errors.ErrCodeValidation, "该课次没有需要考勤的学生")
+	}
+
+	onRoster := make(map[int]bool, len(roster))
+	for _, studentID := range roster {
+		onRoster[studentID] = true
+	}
+	entries := make(map[int]domain.AttendanceEntry, len(req.Records))
+	for _, entry := range req.Records {
+		if !onRoster[entry.StudentID] {
+			return nil, errors.Newf(errors.ErrCodeValidation, "学生 %d 不在该课次的考勤名单中", entry.StudentID)
+		}
+		if _, ok := entries[entry.StudentID]; ok {
+			return nil, errors.Newf(errors.ErrCodeValidation, "学生 %d 重复出现在考勤名单中", entry.StudentID)
+		}
+		entries[entry.StudentID] = entry
+	}
+
+	approvedLeaves, err := s.leaveRepo.ListApprovedOn(roster, session.SessionDate)
+	if err != nil {
+		return nil, err
+	}
+	leaves := make(map[int]*domain.LeaveRequest, len(approvedLeaves))
+	for _, leave := range approvedLeaves {
+		leaves[leave.StudentID] = leave
+	}
+
+	defaultStatus := req.DefaultStatus
+	if defaultStatus == "" {
+		defaultStatus = domain.AttendancePresent
+	}
+	records := make([]*domain.AttendanceRecord, 0, len(roster))
+	for _, studentID := range roster {
+		record := &domain.AttendanceRecord{StudentID: studentID, Status: defaultStatus}
+		entry, listed := entries[studentID]
+		if listed {
+			record.Status = entry.Status
+			record.Note = entry.Note
+		}
+		if leave, ok := leaves[studentID]; ok && (!listed || record.Status == domain.AttendanceAbsent) {
+			record.Status = leave.AttendanceStatus()
+			if record.Note == "" {
+				record.Note = fmt.Sprintf("请假 #%d", leave.ID)
+			}
+		}
+		records = append(records, record)
+	}
+
+	if actor.AdminID != 0 {
+		recordedBy := actor.AdminID
+		session.RecordedBy = &recordedBy
+	}
+	if err := s.attendanceRepo.SaveSession(session, records); err != nil {
+		return nil, err
+	}
+
+	return s.getSessionWithRecords(session.ID)
+}
+
+// GetSession 获取考勤课次及其考勤记录
+func (s *AttendanceService) GetSession(actor *domain.JWTClaims, id int) (*domain.AttendanceSession, error) {
+	if actor.IsStudentScoped() {
+		return nil, errors.ErrForbidden
+	}
+	return s.getSessionWithRecords(id)
+}
+
+// ListSessions 获取考勤课次列表（分页）。学生和家长通过考勤汇总查看本人（子女）的考勤
+func (s *AttendanceService) ListSessions(actor *domain.JWTClaims, req domain.AttendanceSessionListRequest) ([]*domain.AttendanceSession, int64, error) {
+	if actor.IsStudentScoped() {
+		return nil, 0, errors.ErrForbidden
+	}
+	if req.Page <= 0 {
+		req.Page = 1
+	}
+	if req.Size <= 0 {
+		req.Size = 10
+	}
+	req.Semester = domain.NormalizeTermCode(req.Semester)
+
+	sessions, total, err := s.attendanceRepo.ListSessions(&req)
+	if err != nil {
+		logger.WithError(err).Error("Failed to list attendance sessions")
+		return nil, 0, err
+	}
+	return sessions, total, nil
+}
+
+// DeleteSession 删除考勤课次，已结束学期的考勤不能修改
+func (s *AttendanceService) DeleteSession(actor *domain.JWTClaims, id int) error {
+	logger.WithFields(map[string]interface{}{
+		"session_id": id,
+		"admin_id":   actor.AdminID,
+	}).Info("Deleting attendance session")
+
+	session, err := s.getSession(id)
+	if err != nil {
+		return err
+	}
+	offering, err := s.getOffering(session.OfferingID)
+	if err != nil {
+		return err
+	}
+
+	var slot *domain.TimetableSlot
+	if session.SlotID != nil {
+		if slot, err = s.timetableRepo.GetByID(*session.SlotID); err != nil {
+			return err
+		}
+	}
+	if err := s.authorizeWrite(actor, offering, slot); err != nil {
+		return err
+	}
+	if _, err := s.writableTerm(offering.Semester); err != nil {
+		return err
+	}
+
+	return s.attendanceRepo.DeleteSession(id)
+}
+
+// GetSummary 获取学期考勤汇总（分页），每行为一名学生在一门开课的考勤，可只看预警或取消考试资格的学生
+func (s *AttendanceService) GetSummary(actor *domain.JWTClaims, req domain.AttendanceSummaryRequest) ([]*domain.AttendanceSummary, int64, error) {
+	if actor.IsStudentScoped() {
+		return nil, 0, errors.ErrForbidden
+	}
+	if req.Page <= 0 {
+		req.Page = 1
+	}
+	if req.Size <= 0 {
+		req.Size = 10
+	}
+
+	term, err := s.termOrCurrent(req.Semester)
+	if err != nil {
+		return nil, 0, err
+	}
+	req.Semester = term.Code
+	req.StudentID = 0
+
+	summaries, err := s.attendanceRepo.Summaries(&req)
+	if err != nil {
+		logger.WithError(err).Error("Failed to summarize attendance")
+		return nil, 0, err
+	}
+
+	filtered := make([]*domain.AttendanceSummary, 0, len(summaries))
+	for _, summary := range summaries {
+		s.evaluate(summary)
+		switch req.Flag {
+		case domain.AttendanceFlagAtRisk:
+			if !summary.AtRisk {
+				continue
+			}
+		case domain.AttendanceFlagExamBlocked:
+			if !summary.ExamBlocked {
+				continue
+			}
+		}
+		filtered = append(filtered, summary)
+	}
+
+	total := int64(len(filtered))
+	start := (req.Page - 1) * req.Size
+	if start >= len(filtered) {
+		return []*domain.AttendanceSummary{}, total, nil
+	}
+	end := start + req.Size
+	if end > len(filtered) {
+		end = len(filtered)
+	}
+	return filtered[start:end], total, nil
+}
+
+// GetStudentAttendance 获取学生学期考勤汇总，学生和家长只能查看本人（子女）的考勤
+func (s *AttendanceService) GetStudentAttendance(actor *domain.JWTClaims, studentID int, req domain.StudentAttendanceRequest) (*domain.StudentAttendanceReport, error) {
+	if !actor.CanAccessStudent(studentID) {
+		return nil, errors.ErrForbidden
+	}
+
+	student, err := s.studentRepo.GetByID(studentID)
+	if err != nil {
+		return nil, err
+	}
+	if student == nil {
+		return nil, errors.Newf(errors.ErrCodeNotFound, "学生 %d 不存在", studentID)
+	}
+
+	term, err := s.termOrCurrent(req.Semester)
+	if err != nil {
+		return nil, err
+	}
+
+	summaries, err := s.attendanceRepo.Summaries(&domain.AttendanceSummaryRequest{Semester: term.Code, StudentID: studentID})
+	if err != nil {
+		logger.WithError(err).WithFields(map[string]interface{}{
+			"student_id": studentID,
+		}).Error("Failed to summarize student attendance")
+		return nil, err
+	}
+
+	report := &domain.StudentAttendanceReport{
+		StudentID:   student.ID,
+		StudentNo:   student.StudentID,
+		StudentName: student.Name,
+		Semester:    term.Code,
+		Courses:     []*domain.AttendanceSummary{},
+	}
+	for _, summary := range summaries {
+		s.evaluate(summary)
+		report.Totals.Merge(summary.AttendanceCounts)
+		if summary.AtRisk {
+			report.AtRisk++
+		}
+		if summary.ExamBlocked {
+			report.ExamBlocked++
+		}
+		report.Courses = append(report.Courses, summary)
+	}
+	return report, nil
+}
+
+// CheckExamEligibility 检查学生是否具有开课的期末考试资格，缺勤率达到取消考试资格的标准时返回冲突错误
+func (s *AttendanceService) CheckExamEligibility(studentID, offeringID int) error {
+	offering, err := s.getOffering(offeringID)
+	if err != nil {
+		return err
+	}
+
+	summaries, err := s.attendanceRepo.Summaries(&domain.AttendanceSummaryRequest{
+		Semester:   offering.Semester,
+		OfferingID: offeringID,
+		StudentID:  studentID,
+	})
+	if err != nil {
+		return err
+	}
+
+	for _, summary := range summaries {
+		s.evaluate(summary)
+		if summary.ExamBlocked {
+			logger.WithFields(map[string]interface{}{
+				"student_id":   studentID,
+				"offering_id":  offeringID,
+				"absence_rate": summary.AbsenceRate,
+			}).Warn("Final exam blocked by attendance")
+			return errors.Newf(errors.ErrCodeConflict, "学生 %d 在该课程的缺勤率为 %.0f%%，已取消期末考试资格",
+				studentID, summary.AbsenceRate*100)
+		}
+	}
+	return nil
+}
+
+// evaluate 按配置计算缺勤课次和缺勤率，并判定预警和取消考试资格
+func (s *AttendanceService) evaluate(summary *domain.AttendanceSummary) {
+	absences := summary.Absent
+	if s.latesPerAbsence > 0 {
+		absences += summary.Late / s.latesPerAbsence
+	}
+	if s.countLeave {
+		absences += summary.Excused + summary.Sick
+	}
+	summary.Absences = absences
+	if summary.Total == 0 {
+		return
+	}
+
+	rate := float64(absences) / float64(summary.Total)
+	summary.AbsenceRate = math.Round(rate*10000) / 10000
+	if summary.Total < s.minSessions {
+		return
+	}
+	summary.AtRisk = rate >= s.atRiskRate
+	summary.ExamBlocked = rate >= s.examBlockRate
+}
+
+// authorizeWrite 检查记录考勤的权限：管理员，或开课（课表时段）的任课教师
+func (s *AttendanceService) authorizeWrite(actor *domain.JWTClaims, offering *domain.CourseOffering, slot *domain.TimetableSlot) error {
+	switch actor.Role {
+	case domain.RoleAdmin:
+		return nil
+	case domain.RoleTeacher:
+		if actor.TeacherID > 0 {
+			if offering.TeacherID != nil && *offering.TeacherID == actor.TeacherID {
+				return nil
+			}
+			if slot != nil && slot.TeacherID != nil && *slot.TeacherID == actor.TeacherID {
+				return nil
+			}
+		}
+		return errors.New(errors.ErrCodeForbidden, "只能记录本人任教课程的考勤")
+	default:
+		return errors.ErrForbidden
+	}
+}
+
+// checkSessionDate 检查上课日期：学期未结束，日期在学期内且不晚于今天
+func (s *AttendanceService) checkSessionDate(semester string, date time.Time) error {
+	term, err := s.writableTerm(semester)
+	if err != nil {
+		return err
+	}
+
+	startDate := time.Date(term.StartDate.Year(), term.StartDate.Month(), term.StartDate.Day(), 0, 0, 0, 0, time.UTC)
+	endDate := time.Date(term.EndDate.Year(), term.EndDate.Month(), term.EndDate.Day(), 0, 0, 0, 0, time.UTC)
+	if date.Before(startDate) || date.After(endDate) {
+		return errors.Newf(errors.ErrCodeValidation, "上课日期 %s 不在学期 %s 内", date.Format("2006-01-02"), term.Code)
+	}
+
+	now := time.Now()
+	if date.After(time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC)) {
+		return errors.New(errors.ErrCodeValidation, "不能记录今天以后的考勤")
+	}
+	return nil
+}
+
+// writableTerm 获取允许记录考勤的学期，已结束学期的考勤已冻结
+func (s *AttendanceService) writableTerm(semester string) (*domain.Term, error) {
+	term, err := resolveTerm(s.termRepo, semester)
+	if err != nil {
+		return nil, err
+	}
+	if term.Status == domain.TermStatusClosed {
+		return nil, errors.Newf(errors.ErrCodeConflict, "学期 %s 已结束，考勤已冻结", term.Code)
+	}
+	return term, nil
+}
+
+// termOrCurrent 获取指定学期，学期为空时使用当前学期
+func (s *AttendanceService) termOrCurrent(semester string) (*domain.Term, error) {
+	if semester != "" {
+		return resolveTerm(s.termRepo, semester)
+	}
+
+	term, err := s.termRepo.GetCurrent()
+	if err != nil {
+		return nil, err
+	}
+	if term == nil {
+		return nil, errors.New(errors.ErrCodeValidation, "尚未设置当前学期，请指定学期")
+	}
+	return term, nil
+}
+
+// getOffering 获取开课，不存在时返回不存在错误
+func (s *AttendanceService) getOffering(offeringID int) (*domain.CourseOffering, error) {
+	offering, err := s.offeringRepo.GetByID(offeringID)
+	if err != nil {
+		return nil, err
+	}
+	if offering == nil {
+		return nil, errors.Newf(errors.ErrCodeNotFound, "开课 %d 不存在", offeringID)
+	}
+	return offering, nil
+}
+
+// getSession 获取考勤课次，不存在时返回不存在错误
+func (s *AttendanceService) getSession(id int) (*domain.AttendanceSession, error) {
+	session, err := s.attendanceRepo.GetSession(id)
+	if err != nil {
+		logger.WithError(err).WithFields(map[string]interface{}{
+			"session_id": id,
+		}).Error("Failed to get attendance session")
+		return nil, err
+	}
+	if session == nil {
+		return nil, errors.New(errors.ErrCodeNotFound, "考勤课次不存在")
+	}
+	return session, nil
+}
+
+// getSessionWithRecords 获取考勤课次并附带考勤记录
+func (s *AttendanceService) getSessionWithRecords(id int) (*domain.AttendanceSession, error) {
+	session, err := s.getSession(id)
+	if err != nil {
+		return nil, err
+	}
+
+	records, err := s.attendanceRepo.ListRecords(id)
+	if err != nil {
+		return nil, err
+	}
+	session.Records = records
+	return session, nil
+}
@@ -2,7 +2,7 @@ package service
 
 import (
 	"context"
-	"crypto/md5"
+	"encoding/json"
 	"fmt"
 	"time"
 
@@ -11,27 +11,70 @@ import (
 	"student-management-system/internal/repository"
 	"student-management-system/pkg/errors"
 	"student-management-system/pkg/logger"
+	"student-management-system/pkg/password"
 	"student-management-system/pkg/utils"
+
+	"github.com/redis/go-redis/v9"
 )
 
 // AuthService 认证服务
 type AuthService struct {
 	config    *config.Config
 	adminRepo *repository.AdminRepository
+	passwords *password.Manager
+	twoFactor *TwoFactorService
 }
 
 // NewAuthService 创建认证服务实例
-func NewAuthService(cfg *config.Config, adminRepo *repository.AdminRepository) *AuthService {
+func NewAuthService(cfg *config.Config, adminRepo *repository.AdminRepository, passwords *password.Manager, twoFactor *TwoFactorService) *AuthService {
 	return &AuthService{
 		config:    cfg,
 		adminRepo: adminRepo,
+		passwords: passwords,
+		twoFactor: twoFactor,
+	}
+}
+
+// NewPasswordManager 根据配置创建密码管理器
+func NewPasswordManager(cfg config.PasswordConfig) (*password.Manager, error) {
+	return password.NewManager(password.Config{
+		Algorithm:  cfg.Algorithm,
+		BcryptCost: cfg.BcryptCost,
+		Argon2: password.Argon2Params{
+			Memory:      cfg.Argon2.Memory,
+			Iterations:  cfg.Argon2.Iterations,
+			Parallelism: cfg.Argon2.Parallelism,
+		},
+		Policy: password.Policy{
+			MinLength:       cfg.Policy.MinLength,
+			MaxLength:       cfg.Policy.MaxLength,
+			RequireUpper:    cfg.Policy.RequireUpper,
+			RequireLower:    cfg.Policy.RequireLower,
+			RequireDigit:    cfg.Policy.RequireDigit,
+			RequireSpecial:  cfg.Policy.RequireSpecial,
+			DisallowAccount: cfg.Policy.DisallowAccount,
+		},
+	})
+}
+
+// NewJWTKeySet 根据配置创建JWT密钥集合
+func NewJWTKeySet(cfg config.JWTConfig) (*utils.KeySet, error) {
+	keys := make([]utils.JWTKeyConfig, 0, len(cfg.Keys))
+	for _, key := range cfg.Keys {
+		keys = append(keys, utils.JWTKeyConfig{
+			KID:            key.KID,
+			Algorithm:      key.Algorithm,
+			PrivateKeyFile: key.PrivateKeyFile,
+			PublicKeyFile:  key.PublicKeyFile,
+		})
 	}
+	return utils.NewKeySet(cfg.Issuer, cfg.SigningKeyID, []byte(cfg.Secret), keys)
 }
 
-// Login 管理员登录
-func (s *AuthService) Login(req *domain.LoginRequest) (*domain.LoginResponse, error) {
+// Login 管理员登录，每次登录创建一个独立的会话。
+// 账号启用了两步验证（或角色强制要求两步验证）时不签发token，而是返回两步验证挑战
+func (s *AuthService) Login(req *domain.LoginRequest, meta domain.SessionMeta) (*domain.LoginResponse, *domain.TwoFactorChallenge, error) {
 	ctx := context.Background()
-	lockKey := fmt.Sprintf("login_lock:%s", req.Account)
 	failCountKey := fmt.Sprintf("login_fail_count:%s", req.Account)
 
 	logger.WithFields(map[string]interface{}{
@@ -39,101 +82,379 @@ func (s *AuthService) Login(req *domain.LoginRequest) (*domain.LoginResponse, er
 	}).Info("Admin login attempt")
 
 	// 检查是否被锁定
+	if err := s.checkLoginLock(ctx, req.Account); err != nil {
+		return nil, nil, err
+	}
+
+	// 验证用户名和密码
+	admin, err := s.validateCredentials(req.Account, req.Password)
+	if err != nil {
+		remaining, err := s.recordLoginFailure(ctx, req.Account)
+		if err != nil {
+			return nil, nil, err
+		}
+		return nil, nil, fmt.Errorf("用户名或密码错误，还可尝试 %d 次", remaining)
+	}
+
+	// 登录成功，清除失败次数
+	repository.RedisClient.Del(ctx, failCountKey)
+
+	meta.Device = req.Device
+
+	// 需要两步验证时先返回挑战，完成第二步后再创建会话
+	if admin.TOTPEnabled || s.twoFactor.IsRequired(admin.Role) {
+		challenge, err := s.createLoginChallenge(ctx, admin, meta)
+		if err != nil {
+			return nil, nil, err
+		}
+
+		logger.WithFields(map[string]interface{}{
+			"account":             req.Account,
+			"enrollment_required": challenge.EnrollmentRequired,
+		}).Info("Two-factor challenge issued")
+		return nil, challenge, nil
+	}
+
+	response, err := s.startSession(admin, meta)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	logger.WithFields(map[string]interface{}{
+		"account":    req.Account,
+		"role":       admin.Role,
+		"ip":         meta.IP,
+		"expires_at": response.ExpiresAt,
+	}).Info("Admin login successful")
+
+	return response, nil, nil
+}
+
+// checkLoginLock 检查账号是否因多次登录失败被锁定
+func (s *AuthService) checkLoginLock(ctx context.Context, account string) error {
+	lockKey := fmt.Sprintf("login_lock:%s", account)
+
 	locked, err := repository.RedisClient.Exists(ctx, lockKey).Result()
 	if err != nil {
 		logger.WithError(err).WithFields(map[string]interface{}{
-			"account": req.Account,
+			"account": account,
 		}).Error("Failed to check lock status")
-		return nil, fmt.Errorf("检查锁定状态失败: %w", err)
+		return fmt.Errorf("检查锁定状态失败: %w", err)
 	}
 	if locked > 0 {
 		ttl, _ := repository.RedisClient.TTL(ctx, lockKey).Result()
 		logger.WithFields(map[string]interface{}{
-			"account": req.Account,
+			"account": account,
 			"ttl":     ttl,
 		}).Warn("Account is locked")
-		return nil, fmt.Errorf("账户已被锁定，请在 %v 后重试", ttl)
+		return fmt.Errorf("账户已被锁定，请在 %v 后重试", ttl)
 	}
 
-	// 验证用户名和密码
-	admin, err := s.validateCredentials(req.Account, req.Password)
+	return nil
+}
+
+// recordLoginFailure 记录一次登录失败（密码或两步验证错误），返回剩余尝试次数，达到上限时锁定账户并返回错误
+func (s *AuthService) recordLoginFailure(ctx context.Context, account string) (int64, error) {
+	lockKey := fmt.Sprintf("login_lock:%s", account)
+	failCountKey := fmt.Sprintf("login_fail_count:%s", account)
+
+	// 登录失败，增加失败次数
+	failCount, err := repository.RedisClient.Incr(ctx, failCountKey).Result()
 	if err != nil {
-		// 登录失败，增加失败次数
-		failCount, err := repository.RedisClient.Incr(ctx, failCountKey).Result()
+		logger.WithError(err).WithFields(map[string]interface{}{
+			"account": account,
+		}).Error("Failed to record login failure count")
+		return 0, fmt.Errorf("记录登录失败次数失败: %w", err)
+	}
+
+	// 设置失败次数的过期时间（15分钟）
+	repository.RedisClient.Expire(ctx, failCountKey, 15*time.Minute)
+
+	// 如果失败次数达到5次，锁定账户5分钟
+	if failCount >= 5 {
+		err = repository.RedisClient.Set(ctx, lockKey, "locked", 5*time.Minute).Err()
 		if err != nil {
 			logger.WithError(err).WithFields(map[string]interface{}{
-				"account": req.Account,
-			}).Error("Failed to record login failure count")
-			return nil, fmt.Errorf("记录登录失败次数失败: %w", err)
-		}
-
-		// 设置失败次数的过期时间（15分钟）
-		repository.RedisClient.Expire(ctx, failCountKey, 15*time.Minute)
-
-		// 如果失败次数达到5次，锁定账户5分钟
-		if failCount >= 5 {
-			err = repository.RedisClient.Set(ctx, lockKey, "locked", 5*time.Minute).Err()
-			if err != nil {
-				logger.WithError(err).WithFields(map[string]interface{}{
-					"account": req.Account,
-				}).Error("Failed to lock account")
-				return nil, fmt.Errorf("锁定账户失败: %w", err)
-			}
-			// 清除失败次数
-			repository.RedisClient.Del(ctx, failCountKey)
-			logger.WithFields(map[string]interface{}{
-				"account":    req.Account,
-				"fail_count": failCount,
-			}).Warn("Account locked due to too many failed attempts")
-			return nil, fmt.Errorf("登录失败次数过多，账户已被锁定5分钟")
+				"account": account,
+			}).Error("Failed to lock account")
+			return 0, fmt.Errorf("锁定账户失败: %w", err)
 		}
-
+		// 清除失败次数
+		repository.RedisClient.Del(ctx, failCountKey)
 		logger.WithFields(map[string]interface{}{
-			"account":    req.Account,
+			"account":    account,
 			"fail_count": failCount,
-		}).Warn("Login failed - invalid credentials")
-		return nil, fmt.Errorf("用户名或密码错误，还可尝试 %d 次", 5-failCount)
+		}).Warn("Account locked due to too many failed attempts")
+		return 0, fmt.Errorf("登录失败次数过多，账户已被锁定5分钟")
 	}
 
-	// 登录成功，清除失败次数
-	repository.RedisClient.Del(ctx, failCountKey)
+	logger.WithFields(map[string]interface{}{
+		"account":    account,
+		"fail_count": failCount,
+	}).Warn("Login failed - invalid credentials")
+	return 5 - failCount, nil
+}
+
+// startSession 创建会话并签发访问token和刷新token
+func (s *AuthService) startSession(admin *domain.Admin, meta domain.SessionMeta) (*domain.LoginResponse, error) {
+	claims, err := s.buildClaims(admin)
+	if err != nil {
+		return nil, err
+	}
 
-	// 获取JWT过期时间配置
-	expiresIn := s.config.JWT.ExpiresIn
-	if expiresIn == 0 {
-		expiresIn = 24 * time.Hour // 默认24小时
+	// 每次登录创建独立会话，会话有效期即刷新token有效期
+	session, err := utils.CreateSession(admin.ID, meta, s.refreshExpiresIn())
+	if err != nil {
+		return nil, fmt.Errorf("failed to create session: %w", err)
 	}
 
-	// 生成JWT token
-	token, expiresAt, err := utils.GenerateToken(admin.ID, admin.Account, int64(expiresIn.Seconds()))
+	response, err := s.issueTokens(admin, claims, session)
 	if err != nil {
 		logger.WithError(err).WithFields(map[string]interface{}{
-			"account": req.Account,
+			"account": admin.Account,
 		}).Error("Failed to generate token")
-		return nil, fmt.Errorf("failed to generate token: %w", err)
+		return nil, err
 	}
 
-	// 构造响应
-	response := &domain.LoginResponse{
-		Token:     token,
-		ExpiresAt: expiresAt,
-		Admin: domain.AdminInfo{
-			ID:      admin.ID,
-			Account: admin.Account,
-			Name:    admin.Name,
-			Phone:   admin.Phone,
-			Email:   admin.Email,
-		},
+	return response, nil
+}
+
+// loginChallenge 两步验证登录挑战，保存在Redis中
+type loginChallenge struct {
+	AdminID   int    `json:"admin_id"`
+	Account   string `json:"account"`
+	Enroll    bool   `json:"enroll"` // 是否在第二步完成绑定
+	Device    string `json:"device"`
+	IP        string `json:"ip"`
+	UserAgent string `json:"user_agent"`
+	Attempts  int    `json:"attempts"`
+}
+
+// maxChallengeAttempts 单个挑战允许的验证码错误次数
+const maxChallengeAttempts = 5
+
+// loginChallengeKey 登录挑战的Redis键，token本身只保存哈希
+func loginChallengeKey(token string) string {
+	return fmt.Sprintf("login_challenge:%s", utils.HashOpaqueToken(token))
+}
+
+// createLoginChallenge 创建两步验证挑战，角色强制要求但尚未绑定时同时生成绑定密钥
+func (s *AuthService) createLoginChallenge(ctx context.Context, admin *domain.Admin, meta domain.SessionMeta) (*domain.TwoFactorChallenge, error) {
+	token, err := utils.GenerateOpaqueToken()
+	if err != nil {
+		return nil, fmt.Errorf("生成登录挑战失败: %w", err)
+	}
+
+	response := &domain.TwoFactorChallenge{
+		TwoFactorRequired: true,
+		ChallengeToken:    token,
+	}
+
+	if !admin.TOTPEnabled {
+		setup, err := s.twoFactor.newSetup(admin)
+		if err != nil {
+			return nil, err
+		}
+		response.EnrollmentRequired = true
+		response.Setup = setup
+	}
+
+	challenge := loginChallenge{
+		AdminID:   admin.ID,
+		Account:   admin.Account,
+		Enroll:    response.EnrollmentRequired,
+		Device:    meta.Device,
+		IP:        meta.IP,
+		UserAgent: meta.UserAgent,
+	}
+	data, err := json.Marshal(challenge)
+	if err != nil {
+		return nil, err
+	}
+
+	ttl := s.twoFactor.challengeTTL()
+	if err := repository.RedisClient.Set(ctx, loginChallengeKey(token), data, ttl).Err(); err != nil {
+		logger.WithError(err).WithFields(map[string]interface{}{
+			"account": admin.Account,
+		}).Error("Failed to store login challenge")
+		return nil, fmt.Errorf("保存登录挑战失败: %w", err)
+	}
+	response.ExpiresAt = time.Now().Add(ttl)
+
+	return response, nil
+}
+
+// LoginTwoFactor 登录第二步：提交认证器验证码或恢复码，通过后创建会话。
+// 挑战要求绑定时只接受验证码，验证通过即启用两步验证并在响应中返回恢复码
+func (s *AuthService) LoginTwoFactor(req *domain.TwoFactorLoginRequest, meta domain.SessionMeta) (*domain.LoginResponse, error) {
+	ctx := context.Background()
+
+	if req.ChallengeToken == "" || (req.Code == "" && req.RecoveryCode == "") {
+		return nil, errors.New(errors.ErrCodeInvalidRequest, "请提供验证码或恢复码")
+	}
+
+	key := loginChallengeKey(req.ChallengeToken)
+	data, err := repository.RedisClient.Get(ctx, key).Bytes()
+	if err == redis.Nil {
+		return nil, errors.New(errors.ErrCodeInvalidToken, "登录验证已过期，请重新登录")
+	}
+	if err != nil {
+		return nil, fmt.Errorf("获取登录挑战失败: %w", err)
+	}
+
+	var challenge loginChallenge
+	if err := json.Unmarshal(data, &challenge); err != nil {
+		return nil, errors.ErrInvalidToken
+	}
+
+	if err := s.checkLoginLock(ctx, challenge.Account); err != nil {
+		return nil, err
+	}
+
+	admin, err := s.adminRepo.GetAdminByID(challenge.AdminID)
+	if err != nil {
+		repository.RedisClient.Del(ctx, key)
+		return nil, errors.ErrInvalidToken
+	}
+
+	var ok bool
+	switch {
+	case req.Code != "":
+		ok = s.twoFactor.verifyCode(admin, req.Code)
+	case !challenge.Enroll:
+		ok, err = s.twoFactor.verifyRecoveryCode(admin, req.RecoveryCode)
+		if err != nil {
+			return nil, err
+		}
+		if ok {
+			logger.WithFields(map[string]interface{}{
+				"admin_id": admin.ID,
+			}).Warn("Recovery code used for login")
+		}
+	}
+
+	if !ok {
+		// 错误次数过多时作废挑战，需重新输入密码
+		challenge.Attempts++
+		if challenge.Attempts >= maxChallengeAttempts {
+			repository.RedisClient.Del(ctx, key)
+		} else if data, err := json.Marshal(challenge); err == nil {
+			repository.RedisClient.Set(ctx, key, data, redis.KeepTTL)
+		}
+
+		remaining, err := s.recordLoginFailure(ctx, challenge.Account)
+		if err != nil {
+			return nil, err
+		}
+		return nil, errors.Newf(errors.ErrCodeInvalidCredentials, "验证码或恢复码错误，还可尝试 %d 次", remaining)
+	}
+
+	// 挑战只能使用一次
+	repository.RedisClient.Del(ctx, key)
+	repository.RedisClient.Del(ctx, fmt.Sprintf("login_fail_count:%s", challenge.Account))
+
+	var recoveryCodes []string
+	if challenge.Enroll {
+		recoveryCodes, err = s.twoFactor.enable(admin)
+		if err != nil {
+			return nil, err
+		}
 	}
 
+	meta.Device = challenge.Device
+	response, err := s.startSession(admin, meta)
+	if err != nil {
+		return nil, err
+	}
+	response.RecoveryCodes = recoveryCodes
+
 	logger.WithFields(map[string]interface{}{
-		"account":    req.Account,
-		"expires_at": expiresAt,
-	}).Info("Admin login successful")
+		"account":  admin.Account,
+		"role":     admin.Role,
+		"ip":       meta.IP,
+		"enrolled": challenge.Enroll,
+	}).Info("Admin login successful with two-factor authentication")
 
 	return response, nil
 }
 
+// accessExpiresIn 访问token有效期
+func (s *AuthService) accessExpiresIn() time.Duration {
+	if s.config.JWT.ExpiresIn > 0 {
+		return s.config.JWT.ExpiresIn
+	}
+	return 15 * time.Minute // 默认15分钟
+}
+
+// refreshExpiresIn 刷新token（会话）有效期
+func (s *AuthService) refreshExpiresIn() time.Duration {
+	if s.config.JWT.RefreshExpiresIn > 0 {
+		return s.config.JWT.RefreshExpiresIn
+	}
+	return 7 * 24 * time.Hour // 默认7天
+}
+
+// issueTokens 为会话签发访问token和新的刷新token
+func (s *AuthService) issueTokens(admin *domain.Admin, claims *domain.JWTClaims, session *domain.Session) (*domain.LoginResponse, error) {
+	claims.JTI = session.ID
+
+	token, expiresAt, err := utils.GenerateToken(*claims, int64(s.accessExpiresIn().Seconds()))
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate token: %w", err)
+	}
+
+	refreshToken, refreshExpiresAt, err := utils.IssueRefreshToken(session)
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate refresh token: %w", err)
+	}
+
+	return &domain.LoginResponse{
+		Token:            token,
+		ExpiresAt:        expiresAt,
+		RefreshToken:     refreshToken,
+		RefreshExpiresAt: refreshExpiresAt,
+		Admin:            admin.ToInfo(),
+	}, nil
+}
+
+// buildClaims 根据账号角色构造JWT声明
+func (s *AuthService) buildClaims(admin *domain.Admin) (*domain.JWTClaims, error) {
+	claims := &domain.JWTClaims{
+		AdminID: admin.ID,
+		Account: admin.Account,
+		Role:    admin.Role,
+	}
+
+	switch admin.Role {
+	case domain.RoleTeacher:
+		if admin.TeacherID == nil {
+			return nil, fmt.Errorf("教师账号未关联教师信息")
+		}
+		claims.TeacherID = *admin.TeacherID
+	case domain.RoleStudent:
+		if admin.StudentID == nil {
+			return nil, fmt.Errorf("学生账号未关联学生信息")
+		}
+		claims.StudentIDs = []int{*admin.StudentID}
+	case domain.RoleParent:
+		if len(admin.StudentIDs) == 0 {
+			return nil, fmt.Errorf("家长账号未关联学生信息")
+		}
+		claims.StudentIDs = admin.StudentIDs
+	}
+
+	return claims, nil
+}
+
+// JWKS 获取用于验证访问token的公钥集合
+func (s *AuthService) JWKS() utils.JWKSet {
+	ks := utils.CurrentKeySet()
+	if ks == nil {
+		return utils.JWKSet{Keys: []utils.JWK{}}
+	}
+	return ks.JWKS()
+}
+
 // ValidateToken 验证token
 func (s *AuthService) ValidateToken(tokenString string) (*domain.JWTClaims, error) {
 	return utils.ValidateToken(tokenString)
@@ -151,21 +472,47 @@ func (s *AuthService) validateCredentials(account, password string) (*domain.Adm
 	}
 
 	// 验证密码
-	if s.md5Password(password) != admin.Password {
-		logger.WithFields(map[string]interface{}{
+	ok, needsRehash, err := s.passwords.Verify(password, admin.Password)
+	if err != nil || !ok {
+		logger.WithError(err).WithFields(map[string]interface{}{
 			"account": account,
 		}).Warn("Password verification failed")
 		return nil, errors.ErrInvalidCredentials
 	}
 
+	// 历史MD5或参数过低的哈希在登录成功后升级为当前首选算法
+	if needsRehash {
+		s.rehashPassword(admin, password)
+	}
+
 	return admin, nil
 }
 
-// md5Password （使用MD5）
-func (s *AuthService) md5Password(password string) string {
-	h := md5.New()
-	h.Write([]byte(password))
-	return fmt.Sprintf("%x", h.Sum(nil))
+// rehashPassword 使用首选算法重新哈希密码，失败时只记录日志，不影响本次登录
+func (s *AuthService) rehashPassword(admin *domain.Admin, plain string) {
+	oldAlgorithm := s.passwords.Identify(admin.Password)
+
+	hashed, err := s.passwords.Hash(plain)
+	if err != nil {
+		logger.WithError(err).WithFields(map[string]interface{}{
+			"admin_id": admin.ID,
+		}).Error("Failed to rehash password")
+		return
+	}
+
+	if err := s.adminRepo.UpdatePassword(admin.ID, hashed); err != nil {
+		logger.WithError(err).WithFields(map[string]interface{}{
+			"admin_id": admin.ID,
+		}).Error("Failed to save rehashed password")
+		return
+	}
+
+	admin.Password = hashed
+	logger.WithFields(map[string]interface{}{
+		"admin_id": admin.ID,
+		"from":     oldAlgorithm,
+		"to":       s.passwords.Algorithm(),
+	}).Info("Password hash upgraded")
 }
 
 // GetAdminInfo 根据token获取管理员信息
@@ -180,82 +527,127 @@ func (s *AuthService) GetAdminInfo(claims *domain.JWTClaims) *domain.AdminInfo {
 		return &domain.AdminInfo{
 			ID:      claims.AdminID,
 			Account: claims.Account,
+			Role:    claims.Role,
 		}
 	}
 
-	return &domain.AdminInfo{
-		ID:      admin.ID,
-		Account: admin.Account,
-		Name:    admin.Name,
-		Phone:   admin.Phone,
-		Email:   admin.Email,
-	}
+	info := admin.ToInfo()
+	return &info
 }
 
-// RefreshToken 刷新token
-func (s *AuthService) RefreshToken(claims *domain.JWTClaims) (*domain.LoginResponse, error) {
-	// 检查token是否即将过期（剩余时间少于1小时）
-	if time.Now().Unix() > claims.Exp-3600 {
-		// 先使旧token失效
-		if err := utils.InvalidateToken(claims.AdminID); err != nil {
-			logger.WithError(err).WithFields(map[string]interface{}{
-				"admin_id": claims.AdminID,
-				"account":  claims.Account,
-			}).Warn("Failed to invalidate old token during refresh")
-		}
-
-		// 生成新的token
-		expiresIn := s.config.JWT.ExpiresIn
-		if expiresIn == 0 {
-			expiresIn = 12 * time.Hour // 默认12小时
-		}
+// RefreshToken 使用刷新token轮换出新的访问token和刷新token。
+// 旧刷新token随即失效，重复使用会被视为泄露并撤销整个会话。
+func (s *AuthService) RefreshToken(refreshToken string) (*domain.LoginResponse, error) {
+	session, err := utils.ConsumeRefreshToken(refreshToken)
+	if err != nil {
+		logger.WithError(err).Warn("Refresh token rejected")
+		return nil, err
+	}
 
-		token, expiresAt, err := utils.GenerateToken(claims.AdminID, claims.Account, int64(expiresIn.Seconds()))
-		if err != nil {
-			logger.WithError(err).WithFields(map[string]interface{}{
-				"admin_id": claims.AdminID,
-				"account":  claims.Account,
-			}).Error("Failed to refresh token")
-			return nil, fmt.Errorf("failed to refresh token: %w", err)
+	// 重新加载账号，使角色和关联学生的变更在刷新后生效
+	admin, err := s.adminRepo.GetAdminByID(session.AdminID)
+	if err != nil {
+		logger.WithError(err).WithFields(map[string]interface{}{
+			"admin_id": session.AdminID,
+		}).Warn("Admin not found during refresh")
+		if invErr := utils.InvalidateSession(session.AdminID, session.ID); invErr != nil && invErr != utils.ErrSessionNotFound {
+			logger.WithError(invErr).Error("Failed to invalidate session")
 		}
+		return nil, errors.ErrInvalidToken
+	}
 
-		logger.WithFields(map[string]interface{}{
-			"admin_id":   claims.AdminID,
-			"account":    claims.Account,
-			"expires_at": expiresAt,
-		}).Info("Token refreshed successfully")
+	claims, err := s.buildClaims(admin)
+	if err != nil {
+		return nil, err
+	}
 
-		// 获取完整的管理员信息
-		adminInfo := s.GetAdminInfo(claims)
+	// 会话有效期随刷新顺延
+	if err := utils.ExtendSession(session, s.refreshExpiresIn()); err != nil {
+		return nil, fmt.Errorf("failed to extend session: %w", err)
+	}
 
-		return &domain.LoginResponse{
-			Token:     token,
-			ExpiresAt: expiresAt,
-			Admin:     *adminInfo,
-		}, nil
+	response, err := s.issueTokens(admin, claims, session)
+	if err != nil {
+		logger.WithError(err).WithFields(map[string]interface{}{
+			"admin_id": admin.ID,
+		}).Error("Failed to refresh token")
+		return nil, err
 	}
 
-	return nil, fmt.Errorf("token does not need refresh yet")
+	logger.WithFields(map[string]interface{}{
+		"admin_id":   admin.ID,
+		"session_id": session.ID,
+		"expires_at": response.ExpiresAt,
+	}).Info("Token refreshed successfully")
+
+	return response, nil
 }
 
-// Logout 用户登出，使token失效
-func (s *AuthService) Logout(adminID int) error {
+// Logout 用户登出，仅使当前会话失效
+func (s *AuthService) Logout(claims *domain.JWTClaims) error {
 	logger.WithFields(map[string]interface{}{
-		"admin_id": adminID,
+		"admin_id":   claims.AdminID,
+		"session_id": claims.JTI,
 	}).Info("User logout initiated")
 
-	// 使token失效
-	err := utils.InvalidateToken(adminID)
-	if err != nil {
+	// 使当前会话失效
+	err := utils.InvalidateSession(claims.AdminID, claims.JTI)
+	if err != nil && err != utils.ErrSessionNotFound {
 		logger.WithError(err).WithFields(map[string]interface{}{
-			"admin_id": adminID,
-		}).Error("Failed to invalidate token during logout")
+			"admin_id": claims.AdminID,
+		}).Error("Failed to invalidate session during logout")
 		return fmt.Errorf("failed to logout: %w", err)
 	}
 
 	logger.WithFields(map[string]interface{}{
-		"admin_id": adminID,
+		"admin_id": claims.AdminID,
 	}).Info("User logout successful")
 
 	return nil
 }
+
+// ListSessions 获取当前账号的全部有效会话
+func (s *AuthService) ListSessions(claims *domain.JWTClaims) ([]*domain.Session, error) {
+	sessions, err := utils.ListSessions(claims.AdminID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list sessions: %w", err)
+	}
+
+	for _, session := range sessions {
+		session.Current = session.ID == claims.JTI
+	}
+
+	return sessions, nil
+}
+
+// RevokeSession 撤销当前账号的指定会话
+func (s *AuthService) RevokeSession(claims *domain.JWTClaims, sessionID string) error {
+	logger.WithFields(map[string]interface{}{
+		"admin_id":   claims.AdminID,
+		"session_id": sessionID,
+	}).Info("Revoking session")
+
+	if err := utils.InvalidateSession(claims.AdminID, sessionID); err != nil {
+		if err == utils.ErrSessionNotFound {
+			return errors.New(errors.ErrCodeNotFound, "会话不存在或已失效")
+		}
+		return fmt.Errorf("failed to revoke session: %w", err)
+	}
+
+	return nil
+}
+
+// RevokeOtherSessions 撤销当前账号除当前会话以外的全部会话
+func (s *AuthService) RevokeOtherSessions(claims *domain.JWTClaims) (int, error) {
+	revoked, err := utils.InvalidateOtherSessions(claims.AdminID, claims.JTI)
+	if err != nil {
+		return 0, fmt.Errorf("failed to revoke sessions: %w", err)
+	}
+
+	logger.WithFields(map[string]interface{}{
+		"admin_id": claims.AdminID,
+		"revoked":  revoked,
+	}).Info("Other sessions revoked")
+
+	return revoked, nil
+}
@@ -0,0 +1,431 @@
+package service
+
+import (
+	"bytes"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"strings"
+	"time"
+	_ "time/tzdata" // 内置时区数据，运行环境没有 zoneinfo 时也能加载配置的时区
+
+	"student-management-system/internal/config"
+	"student-management-system/internal/domain"
+	"student-management-system/internal/repository"
+	"student-management-system/pkg/errors"
+	"student-management-system/pkg/ical"
+	"student-management-system/pkg/logger"
+)
+
+const (
+	calendarProdID  = "-//student-management-system//calendar feed//ZH"
+	calendarUIDHost = "student-management-system"
+
+	// 订阅包含与该时间窗口有交集的学期：已结束不久的学期和即将开始的学期
+	calendarLookback  = 30 * 24 * time.Hour
+	calendarLookahead = 180 * 24 * time.Hour
+)
+
+// CalendarService 日历订阅服务：为教师、行政班和学生生成包含每周课程和考试安排的 iCalendar 订阅。
+// 订阅令牌与接口使用的JWT相互独立，日历客户端凭订阅地址中的令牌访问，撤销后立即失效
+type CalendarService struct {
+	feedRepo        repository.CalendarFeedRepository
+	timetableRepo   repository.TimetableRepository
+	examRepo        repository.ExamRepository
+	termRepo        repository.TermRepository
+	classRepo       repository.ClassRepository
+	studentRepo     repository.StudentRepository
+	offeringRepo    repository.CourseOfferingRepository
+	periods         []domain.Period
+	location        *time.Location
+	feedURL         string
+	refreshInterval time.Duration
+}
+
+// NewCalendarService 创建日历订阅服务实例，periods 为课表的节次时间
+func NewCalendarService(cfg config.CalendarConfig, periods []domain.Period, feedRepo repository.CalendarFeedRepository, timetableRepo repository.TimetableRepository, examRepo repository.ExamRepository, termRepo repository.TermRepository, classRepo repository.ClassRepository, studentRepo repository.StudentRepository, offeringRepo repository.CourseOfferingRepository) (*CalendarService, error) {
+	location, err := time.LoadLocation(cfg.Timezone)
+	if err != nil {
+		return nil, fmt.Errorf("invalid calendar timezone %q: %w", cfg.Timezone, err)
+	}
+
+	return &CalendarService{
+		feedRepo:        feedRepo,
+		timetableRepo:   timetableRepo,
+		examRepo:        examRepo,
+		termRepo:        termRepo,
+		classRepo:       classRepo,
+		studentRepo:     studentRepo,
+		offeringRepo:    offeringRepo,
+		periods:         periods,
+		location:        location,
+		feedURL:         cfg.FeedURL,
+		refreshInterval: cfg.RefreshInterval,
+	}, nil
+}
+
+// CreateFeed 创建日历订阅，返回只显示一次的订阅令牌和订阅地址。
+// 教师只能订阅本人和班级的日历，学生和家长只能订阅本人（子女）的日历
+func (s *CalendarService) CreateFeed(actor *domain.JWTClaims, req domain.CreateCalendarFeedRequest) (*domain.CalendarFeed, error) {
+	logger.WithFields(map[string]interface{}{
+		"owner_type": req.OwnerType,
+		"owner_id":   req.OwnerID,
+		"admin_id":   actor.AdminID,
+	}).Info("Creating calendar feed")
+
+	if err := s.authorizeOwner(actor, req.OwnerType, req.OwnerID); err != nil {
+		return nil, err
+	}
+
+	token, err := newFeedToken()
+	if err != nil {
+		return nil, err
+	}
+
+	feed := &domain.CalendarFeed{
+		OwnerType: req.OwnerType,
+		OwnerID:   req.OwnerID,
+		Name:      req.Name,
+		CreatedBy: actor.AdminID,
+		TokenHash: hashFeedToken(token),
+	}
+	if err := s.feedRepo.Create(feed); err != nil {
+		return nil, err
+	}
+
+	feed.Token = token
+	feed.URL = s.feedURL + token + ".ics"
+	return feed, nil
+}
+
+// ListFeeds 获取当前账号创建的日历订阅，包括已撤销的订阅
+func (s *CalendarService) ListFeeds(actor *domain.JWTClaims) ([]*domain.CalendarFeed, error) {
+	feeds, err := s.feedRepo.ListByCreator(actor.AdminID)
+	if err != nil {
+		logger.WithError(err).WithFields(map[string]interface{}{
+			"admin_id": actor.AdminID,
+		}).Error("Failed to list calendar feeds")
+		return nil, err
+	}
+	return feeds, nil
+}
+
+// RevokeFeed 撤销日历订阅。只能撤销本人创建的订阅，管理员可以撤销任何订阅
+func (s *CalendarService) RevokeFeed(actor *domain.JWTClaims, id int) error {
+	feed, err := s.feedRepo.GetByID(id)
+	if err != nil {
+		return err
+	}
+	if feed == nil {
+		return errors.New(errors.ErrCodeNotFound, "日历订阅不存在")
+	}
+	if feed.CreatedBy != actor.AdminID && actor.Role != domain.RoleAdmin {
+		return errors.ErrForbidden
+	}
+	if !feed.IsActive() {
+		return nil
+	}
+
+	if err := s.feedRepo.Revoke(id); err != nil {
+		return err
+	}
+
+	logger.WithFields(map[string]interface{}{
+		"feed_id":    id,
+		"owner_type": feed.OwnerType,
+		"owner_id":   feed.OwnerID,
+		"admin_id":   actor.AdminID,
+	}).Info("Calendar feed revoked")
+	return nil
+}
+
+// RenderFeed 按订阅令牌生成 iCalendar 内容。令牌无效或已撤销时返回不存在
+func (s *CalendarService) RenderFeed(token string) ([]byte, error) {
+	feed, err := s.feedRepo.GetActiveByTokenHash(hashFeedToken(token))
+	if err != nil {
+		return nil, err
+	}
+	if feed == nil {
+		return nil, errors.New(errors.ErrCodeNotFound, "日历订阅不存在或已撤销")
+	}
+
+	if err := s.feedRepo.TouchLastUsed(feed.ID); err != nil {
+		logger.WithError(err).WithFields(map[string]interface{}{
+			"feed_id": feed.ID,
+		}).Warn("Failed to record calendar feed access")
+	}
+
+	now := time.Now()
+	terms, err := s.termRepo.ListOverlapping(now.Add(-calendarLookback), now.Add(calendarLookahead))
+	if err != nil {
+		return nil, err
+	}
+
+	cal := &ical.Calendar{
+		ProdID:          calendarProdID,
+		Location:        s.location,
+		RefreshInterval: s.refreshInterval,
+	}
+	ownerName := ""
+	for _, term := range terms {
+		holidays, err := s.termRepo.ListHolidays(term.ID)
+		if err != nil {
+			return nil, err
+		}
+
+		slots, err := s.timetableRepo.ListForOwner(term.Code, feed.OwnerType, feed.OwnerID)
+		if err != nil {
+			return nil, err
+		}
+		for _, slot := range slots {
+			if event, ok := s.slotEvent(term, slot, holidays); ok {
+				cal.Events = append(cal.Events, event)
+			}
+			if ownerName == "" && feed.OwnerType == domain.CalendarOwnerTeacher {
+				ownerName = slot.TeacherName
+			}
+		}
+
+		exams, err := s.examRepo.ListForOwner(term.Code, feed.OwnerType, feed.OwnerID)
+		if err != nil {
+			return nil, err
+		}
+		for _, exam := range exams {
+			if event, ok := s.examEvent(exam); ok {
+				cal.Events = append(cal.Events, event)
+			}
+			if ownerName == "" && feed.OwnerType == domain.CalendarOwnerTeacher {
+				ownerName = exam.TeacherName
+			}
+		}
+	}
+
+	if feed.OwnerType != domain.CalendarOwnerTeacher {
+		if ownerName, err = s.ownerName(feed.OwnerType, feed.OwnerID); err != nil {
+			return nil, err
+		}
+	}
+	cal.Name = "课表与考试"
+	if ownerName != "" {
+		cal.Name = ownerName + "的课表与考试"
+	}
+
+	var buf bytes.Buffer
+	if err := cal.Encode(&buf); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// slotEvent 将课表时段转换为学期内每周重复的事件，节假日作为重复的例外日期
+func (s *CalendarService) slotEvent(term *domain.Term, slot *domain.TimetableSlot, holidays []*domain.TermHoliday) (ical.Event, bool) {
+	if slot.StartPeriod < 1 || slot.EndPeriod > len(s.periods) || slot.StartPeriod > slot.EndPeriod {
+		return ical.Event{}, false
+	}
+	startClock := s.periods[slot.StartPeriod-1].Start
+	endClock := s.periods[slot.EndPeriod-1].End
+
+	// 学期开始后的第一次上课
+	first := s.date(term.StartDate)
+	first = first.AddDate(0, 0, (slot.DayOfWeek-isoWeekday(first)+7)%7)
+	last := s.date(term.EndDate)
+	if first.After(last) {
+		return ical.Event{}, false
+	}
+
+	var exDates []time.Time
+	for _, holiday := range holidays {
+		for d := s.date(holiday.StartDate); !d.After(s.date(holiday.EndDate)); d = d.AddDate(0, 0, 1) {
+			if d.Before(first) || d.After(last) || isoWeekday(d) != slot.DayOfWeek {
+				continue
+			}
+			exDates = append(exDates, s.at(d, startClock))
+		}
+	}
+
+	var description []string
+	if slot.TeacherName != "" {
+		description = append(description, "教师："+slot.TeacherName)
+	}
+	if slot.ClassName != "" {
+		description = append(description, "班级："+slot.ClassName)
+	}
+	if slot.Section != "" {
+		description = append(description, "教学班："+slot.Section)
+	}
+	description = append(description, slot.TimeLabel())
+	if slot.Note != "" {
+		description = append(description, slot.Note)
+	}
+
+	return ical.Event{
+		UID:         fmt.Sprintf("timetable-slot-%d@%s", slot.ID, calendarUIDHost),
+		Summary:     subjectTitle(slot.SubjectName, slot.SubjectCode),
+		Description: strings.Join(description, "\n"),
+		Location:    roomLabel(slot.RoomCode, slot.RoomName),
+		Categories:  []string{"课程"},
+		Start:       s.at(first, startClock),
+		End:         s.at(first, endClock),
+		Stamp:       slot.UpdatedAt,
+		Recurrence: &ical.Recurrence{
+			Frequency: ical.FreqWeekly,
+			Until:     time.Date(last.Year(), last.Month(), last.Day(), 23, 59, 59, 0, s.location),
+		},
+		ExDates: exDates,
+	}, true
+}
+
+// examEvent 将考试安排转换为单次事件
+func (s *CalendarService) examEvent(exam *domain.Exam) (ical.Event, bool) {
+	day := s.date(exam.ExamDate)
+	start, end := s.at(day, exam.StartTime), s.at(day, exam.EndTime)
+	if !end.After(start) {
+		return ical.Event{}, false
+	}
+
+	location := exam.Location
+	if exam.RoomCode != "" {
+		location = roomLabel(exam.RoomCode, exam.RoomName)
+	}
+
+	typeName := domain.ExamTypeNames[exam.ExamType]
+	if typeName == "" {
+		typeName = "考试"
+	}
+
+	var description []string
+	if exam.Section != "" {
+		description = append(description, "教学班："+exam.Section)
+	}
+	if exam.TeacherName != "" {
+		description = append(description, "任课教师："+exam.TeacherName)
+	}
+	if exam.Location != "" && exam.RoomCode != "" {
+		description = append(description, exam.Location)
+	}
+	if exam.Note != "" {
+		description = append(description, exam.Note)
+	}
+
+	return ical.Event{
+		UID:         fmt.Sprintf("exam-%d@%s", exam.ID, calendarUIDHost),
+		Summary:     subjectTitle(exam.SubjectName, exam.SubjectCode) + " " + typeName,
+		Description: strings.Join(description, "\n"),
+		Location:    location,
+		Categories:  []string{"考试"},
+		Start:       start,
+		End:         end,
+		Stamp:       exam.UpdatedAt,
+	}, true
+}
+
+// authorizeOwner 校验当前账号能否订阅指定对象的日历，并确认对象存在
+func (s *CalendarService) authorizeOwner(actor *domain.JWTClaims, ownerType string, ownerID int) error {
+	switch ownerType {
+	case domain.CalendarOwnerStudent:
+		if !actor.CanAccessStudent(ownerID) {
+			return errors.ErrForbidden
+		}
+	case domain.CalendarOwnerTeacher:
+		if actor.IsStudentScoped() || (actor.Role == domain.RoleTeacher && actor.TeacherID != ownerID) {
+			return errors.ErrForbidden
+		}
+		ok, err := s.offeringRepo.TeacherExists(ownerID)
+		if err != nil {
+			return err
+		}
+		if !ok {
+			return errors.New(errors.ErrCodeNotFound, "教师不存在")
+		}
+		return nil
+	case domain.CalendarOwnerClass:
+		if actor.IsStudentScoped() {
+			return errors.ErrForbidden
+		}
+	default:
+		return errors.Newf(errors.ErrCodeValidation, "不支持的订阅对象 %s", ownerType)
+	}
+
+	_, err := s.ownerName(ownerType, ownerID)
+	return err
+}
+
+// ownerName 获取行政班或学生的名称，不存在时返回不存在错误
+func (s *CalendarService) ownerName(ownerType string, ownerID int) (string, error) {
+	switch ownerType {
+	case domain.CalendarOwnerClass:
+		class, err := s.classRepo.GetByID(ownerID)
+		if err != nil {
+			return "", err
+		}
+		if class == nil {
+			return "", errors.New(errors.ErrCodeNotFound, "班级不存在")
+		}
+		return class.Name, nil
+	case domain.CalendarOwnerStudent:
+		student, err := s.studentRepo.GetByID(ownerID)
+		if err != nil {
+			return "", err
+		}
+		if student == nil {
+			return "", errors.Newf(errors.ErrCodeNotFound, "学生 %d 不存在", ownerID)
+		}
+		return student.Name, nil
+	default:
+		return "", nil
+	}
+}
+
+// date 取日期部分，作为学校所在时区的当天零点
+func (s *CalendarService) date(t time.Time) time.Time {
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, s.location)
+}
+
+// at 日期加上 HH:MM 格式的当地时间
+func (s *CalendarService) at(day time.Time, clock string) time.Time {
+	t, err := time.Parse("15:04", clock)
+	if err != nil {
+		return day
+	}
+	return time.Date(day.Year(), day.Month(), day.Day(), t.Hour(), t.Minute(), 0, 0, s.location)
+}
+
+// isoWeekday 星期几，1 为周一，7 为周日
+func isoWeekday(t time.Time) int {
+	if t.Weekday() == time.Sunday {
+		return 7
+	}
+	return int(t.Weekday())
+}
+
+// subjectTitle 日历事件的课程名称，没有名称时使用科目代码
+func subjectTitle(name, code string) string {
+	if name != "" {
+		return name
+	}
+	return code
+}
+
+// roomLabel 教室的显示名称，名称与编号不同时一并显示
+func roomLabel(code, name string) string {
+	if name == "" || name == code {
+		return code
+	}
+	return code + " " + name
+}
+
+// newFeedToken 生成随机订阅令牌
+func newFeedToken() (string, error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", fmt.Errorf("生成订阅令牌失败: %w", err)
+	}
+	return hex.EncodeToString(b), nil
+}
+
+// hashFeedToken 计算订阅令牌的哈希，数据库中只保存哈希
+func hashFeedToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
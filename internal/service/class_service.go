@@ -0,0 +1,267 @@
+package service
+
+import (
+	"time"
+
+	"student-management-system/internal/domain"
+	"student-management-system/internal/repository"
+	"student-management-system/pkg/errors"
+	"student-management-system/pkg/logger"
+)
+
+// ClassService 班级服务
+type ClassService struct {
+	classRepo repository.ClassRepository
+}
+
+// NewClassService 创建班级服务实例
+func NewClassService(classRepo repository.ClassRepository) *ClassService {
+	return &ClassService{classRepo: classRepo}
+}
+
+// CreateClass 创建班级
+func (s *ClassService) CreateClass(req domain.CreateClassRequest) (*domain.Class, error) {
+	logger.WithFields(map[string]interface{}{
+		"name":       req.Name,
+		"grade_year": req.GradeYear,
+	}).Info("Creating class")
+
+	class := &domain.Class{
+		Name:              req.Name,
+		GradeYear:         req.GradeYear,
+		Major:             req.Major,
+		HomeroomTeacherID: req.HomeroomTeacherID,
+		Status:            req.Status,
+	}
+	if class.Status == "" {
+		class.Status = domain.ClassStatusActive
+	}
+
+	if err := s.validateClass(class); err != nil {
+		return nil, err
+	}
+
+	if err := s.classRepo.Create(class); err != nil {
+		return nil, err
+	}
+
+	logger.WithFields(map[string]interface{}{
+		"class_id": class.ID,
+		"name":     class.Name,
+	}).Info("Class created successfully")
+
+	return s.GetClass(class.ID)
+}
+
+// GetClass 获取班级
+func (s *ClassService) GetClass(id int) (*domain.Class, error) {
+	class, err := s.classRepo.GetByID(id)
+	if err != nil {
+		logger.WithError(err).WithFields(map[string]interface{}{
+			"class_id": id,
+		}).Error("Failed to get class")
+		return nil, err
+	}
+	if class == nil {
+		return nil, errors.New(errors.ErrCodeNotFound, "班级不存在")
+	}
+	return class, nil
+}
+
+// ListClasses 获取班级列表（分页）
+func (s *ClassService) ListClasses(req domain.ClassListRequest) ([]*domain.Class, int64, error) {
+	if req.Page <= 0 {
+		req.Page = 1
+	}
+	if req.Size <= 0 {
+		req.Size = 10
+	}
+
+	classes, total, err := s.classRepo.List(&req)
+	if err != nil {
+		logger.WithError(err).Error("Failed to list classes")
+		return nil, 0, err
+	}
+	return classes, total, nil
+}
+
+// UpdateClass 更新班级
+func (s *ClassService) UpdateClass(id int, req domain.UpdateClassRequest) (*domain.Class, error) {
+	logger.WithFields(map[string]interface{}{
+		"class_id": id,
+	}).Info("Updating class")
+
+	class, err := s.GetClass(id)
+	if err != nil {
+		return nil, err
+	}
+
+	if req.Name != "" {
+		class.Name = req.Name
+	}
+	if req.GradeYear > 0 {
+		class.GradeYear = req.GradeYear
+	}
+	if req.Major != "" {
+		class.Major = req.Major
+	}
+	if req.HomeroomTeacherID != nil {
+		if *req.HomeroomTeacherID == 0 {
+			class.HomeroomTeacherID = nil
+		} else {
+			class.HomeroomTeacherID = req.HomeroomTeacherID
+		}
+	}
+	if req.Status != "" {
+		class.Status = req.Status
+	}
+
+	if err := s.validateClass(class); err != nil {
+		return nil, err
+	}
+
+	if err := s.classRepo.Update(class); err != nil {
+		return nil, err
+	}
+
+	return s.GetClass(id)
+}
+
+// DeleteClass 删除班级，已有分班记录的班级只能归档，避免丢失学生的分班历史
+func (s *ClassService) DeleteClass(id int) error {
+	logger.WithFields(map[string]interface{}{
+		"class_id": id,
+	}).Info("Deleting class")
+
+	if _, err := s.GetClass(id); err != nil {
+		return err
+	}
+
+	used, err := s.classRepo.HasMemberships(id)
+	if err != nil {
+		return err
+	}
+	if used {
+		return errors.New(errors.ErrCodeConflict, "班级存在分班记录，不能删除，请将班级状态改为 archived")
+	}
+
+	return s.classRepo.Delete(id)
+}
+
+// AssignStudents 学生分班，已在其他班级的学生转入本班并保留原班级的历史记录
+func (s *ClassService) AssignStudents(actor *domain.JWTClaims, classID int, req domain.AssignClassStudentsRequest) ([]*domain.Student, error) {
+	logger.WithFields(map[string]interface{}{
+		"class_id":    classID,
+		"student_ids": req.StudentIDs,
+		"admin_id":    actor.AdminID,
+	}).Info("Assigning students to class")
+
+	class, err := s.GetClass(classID)
+	if err != nil {
+		return nil, err
+	}
+	if class.Status != domain.ClassStatusActive {
+		return nil, errors.New(errors.ErrCodeConflict, "班级已归档，不能再分入学生")
+	}
+
+	studentIDs := uniqueInts(req.StudentIDs)
+	missing, err := s.classRepo.MissingStudentIDs(studentIDs)
+	if err != nil {
+		return nil, err
+	}
+	if len(missing) > 0 {
+		return nil, errors.Newf(errors.ErrCodeValidation, "学生不存在: %v", missing)
+	}
+
+	joinedAt := time.Now()
+	if req.JoinedAt != nil {
+		joinedAt = *req.JoinedAt
+	}
+
+	var createdBy *int
+	if actor.AdminID != 0 {
+		createdBy = &actor.AdminID
+	}
+
+	if err := s.classRepo.AssignStudents(classID, studentIDs, joinedAt, req.Reason, createdBy); err != nil {
+		logger.WithError(err).WithFields(map[string]interface{}{
+			"class_id": classID,
+		}).Error("Failed to assign students to class")
+		return nil, err
+	}
+
+	return s.classRepo.ListStudents(classID)
+}
+
+// RemoveStudent 学生离开班级，保留分班历史
+func (s *ClassService) RemoveStudent(classID, studentID int, reason string) error {
+	logger.WithFields(map[string]interface{}{
+		"class_id":   classID,
+		"student_id": studentID,
+	}).Info("Removing student from class")
+
+	if _, err := s.GetClass(classID); err != nil {
+		return err
+	}
+
+	removed, err := s.classRepo.RemoveStudent(classID, studentID, time.Now(), reason)
+	if err != nil {
+		return err
+	}
+	if !removed {
+		return errors.Newf(errors.ErrCodeNotFound, "学生 %d 不在该班级", studentID)
+	}
+	return nil
+}
+
+// ListClassStudents 获取班级当前的学生
+func (s *ClassService) ListClassStudents(classID int) ([]*domain.Student, error) {
+	if _, err := s.GetClass(classID); err != nil {
+		return nil, err
+	}
+	return s.classRepo.ListStudents(classID)
+}
+
+// ListStudentClasses 获取学生的分班历史
+func (s *ClassService) ListStudentClasses(actor *domain.JWTClaims, studentID int) ([]*domain.ClassMembership, error) {
+	if !actor.CanAccessStudent(studentID) {
+		return nil, errors.ErrForbidden
+	}
+	return s.classRepo.ListMemberships(studentID)
+}
+
+// validateClass 校验班级名称唯一性和班主任
+func (s *ClassService) validateClass(class *domain.Class) error {
+	exists, err := s.classRepo.ExistsByName(class.GradeYear, class.Name, class.ID)
+	if err != nil {
+		return err
+	}
+	if exists {
+		return errors.Newf(errors.ErrCodeConflict, "%d 级已存在班级 %s", class.GradeYear, class.Name)
+	}
+
+	if class.HomeroomTeacherID != nil {
+		ok, err := s.classRepo.TeacherExists(*class.HomeroomTeacherID)
+		if err != nil {
+			return err
+		}
+		if !ok {
+			return errors.Newf(errors.ErrCodeValidation, "班主任 %d 不存在", *class.HomeroomTeacherID)
+		}
+	}
+
+	return nil
+}
+
+// uniqueInts 去重并保持原有顺序
+func uniqueInts(values []int) []int {
+	seen := make(map[int]bool, len(values))
+	result := make([]int, 0, len(values))
+	for _, v := range values {
+		if !seen[v] {
+			seen[v] = true
+			result = append(result, v)
+		}
+	}
+	return result
+}
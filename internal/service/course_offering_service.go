@@ -0,0 +1,405 @@
+package service
+
+import (
+	"time"
+
+	"student-management-system/internal/domain"
+	"student-management-system/internal/repository"
+	"student-management-system/pkg/errors"
+	"student-management-system/pkg/logger"
+)
+
+// CourseOfferingService 开课与选课服务
+type CourseOfferingService struct {
+	offeringRepo repository.CourseOfferingRepository
+	termRepo     repository.TermRepository
+}
+
+// NewCourseOfferingService 创建开课服务实例
+func NewCourseOfferingService(offeringRepo repository.CourseOfferingRepository, termRepo repository.TermRepository) *CourseOfferingService {
+	return &CourseOfferingService{
+		offeringRepo: offeringRepo,
+		termRepo:     termRepo,
+	}
+}
+
+// CreateOffering 创建开课
+func (s *CourseOfferingService) CreateOffering(req domain.CreateCourseOfferingRequest) (*domain.CourseOffering, error) {
+	logger.WithFields(map[string]interface{}{
+		"subject_id": req.SubjectID,
+		"semester":   req.Semester,
+	}).Info("Creating course offering")
+
+	offering := &domain.CourseOffering{
+		SubjectID:        req.SubjectID,
+		Semester:         req.Semester,
+		Section:          req.Section,
+		TeacherID:        req.TeacherID,
+		Capacity:         req.Capacity,
+		WaitlistCapacity: req.WaitlistCapacity,
+		Schedule:         req.Schedule,
+		AddDeadline:      req.AddDeadline,
+		DropDeadline:     req.DropDeadline,
+		Status:           req.Status,
+	}
+	if offering.Section == "" {
+		offering.Section = "01"
+	}
+	if offering.Status == "" {
+		offering.Status = domain.OfferingStatusOpen
+	}
+
+	term, err := resolveTerm(s.termRepo, req.Semester)
+	if err != nil {
+		return nil, err
+	}
+	if term.Status == domain.TermStatusClosed {
+		return nil, errors.Newf(errors.ErrCodeConflict, "学期 %s 已结束，不能再开课", term.Code)
+	}
+	offering.Semester = term.Code
+
+	ok, err := s.offeringRepo.SubjectExists(offering.SubjectID)
+	if err != nil {
+		return nil, err
+	}
+	if !ok {
+		return nil, errors.Newf(errors.ErrCodeValidation, "科目 %d 不存在", offering.SubjectID)
+	}
+
+	if err := s.validateOffering(offering); err != nil {
+		return nil, err
+	}
+
+	if err := s.offeringRepo.Create(offering); err != nil {
+		return nil, err
+	}
+
+	logger.WithFields(map[string]interface{}{
+		"offering_id": offering.ID,
+	}).Info("Course offering created successfully")
+
+	return s.GetOffering(offering.ID)
+}
+
+// GetOffering 获取开课
+func (s *CourseOfferingService) GetOffering(id int) (*domain.CourseOffering, error) {
+	offering, err := s.offeringRepo.GetByID(id)
+	if err != nil {
+		logger.WithError(err).WithFields(map[string]interface{}{
+			"offering_id": id,
+		}).Error("Failed to get course offering")
+		return nil, err
+	}
+	if offering == nil {
+		return nil, errors.New(errors.ErrCodeNotFound, "开课不存在")
+	}
+	return offering, nil
+}
+
+// ListOfferings 获取开课列表（分页）
+func (s *CourseOfferingService) ListOfferings(req domain.CourseOfferingListRequest) ([]*domain.CourseOffering, int64, error) {
+	if req.Page <= 0 {
+		req.Page = 1
+	}
+	if req.Size <= 0 {
+		req.Size = 10
+	}
+	req.Semester = domain.NormalizeTermCode(req.Semester)
+
+	offerings, total, err := s.offeringRepo.List(&req)
+	if err != nil {
+		logger.WithError(err).Error("Failed to list course offerings")
+		return nil, 0, err
+	}
+	return offerings, total, nil
+}
+
+// UpdateOffering 更新开课，扩容后按候补顺序自动递补
+func (s *CourseOfferingService) UpdateOffering(id int, req domain.UpdateCourseOfferingRequest) (*domain.CourseOffering, error) {
+	logger.WithFields(map[string]interface{}{
+		"offering_id": id,
+	}).Info("Updating course offering")
+
+	offering, err := s.GetOffering(id)
+	if err != nil {
+		return nil, err
+	}
+
+	if req.Section != "" {
+		offering.Section = req.Section
+	}
+	if req.TeacherID != nil {
+		if *req.TeacherID == 0 {
+			offering.TeacherID = nil
+		} else {
+			offering.TeacherID = req.TeacherID
+		}
+	}
+	if req.Capacity > 0 {
+		if req.Capacity < offering.EnrolledCount {
+			return nil, errors.Newf(errors.ErrCodeConflict, "容量不能小于已选人数 %d", offering.EnrolledCount)
+		}
+		offering.Capacity = req.Capacity
+	}
+	if req.WaitlistCapacity != nil {
+		offering.WaitlistCapacity = *req.WaitlistCapacity
+	}
+	if req.Schedule != "" {
+		offering.Schedule = req.Schedule
+	}
+	if req.AddDeadline != nil {
+		offering.AddDeadline = req.AddDeadline
+	}
+	if req.DropDeadline != nil {
+		offering.DropDeadline = req.DropDeadline
+	}
+	if req.Status != "" {
+		offering.Status = req.Status
+	}
+
+	if err := s.validateOffering(offering); err != nil {
+		return nil, err
+	}
+
+	if err := s.offeringRepo.Update(offering); err != nil {
+		return nil, err
+	}
+
+	promoted, err := s.offeringRepo.PromoteWaitlist(id)
+	if err != nil {
+		return nil, err
+	}
+	if len(promoted) > 0 {
+		logger.WithFields(map[string]interface{}{
+			"offering_id": id,
+			"student_ids": promoted,
+		}).Info("Waitlisted students promoted")
+	}
+
+	return s.GetOffering(id)
+}
+
+// DeleteOffering 删除开课，已有选课记录或成绩的开课只能取消，避免丢失选课历史
+func (s *CourseOfferingService) DeleteOffering(id int) error {
+	logger.WithFields(map[string]interface{}{
+		"offering_id": id,
+	}).Info("Deleting course offering")
+
+	if _, err := s.GetOffering(id); err != nil {
+		return err
+	}
+
+	used, err := s.offeringRepo.HasEnrollments(id)
+	if err != nil {
+		return err
+	}
+	if used {
+		return errors.New(errors.ErrCodeConflict, "开课存在选课记录或成绩，不能删除，请将开课状态改为 cancelled")
+	}
+
+	return s.offeringRepo.Delete(id)
+}
+
+// Enroll 学生选课。课程已满时进入候补名单，候补名单也已满时拒绝。
+// 学生本人只能为自己选课，且须在开放选课期间和加选截止时间之前；管理员不受截止时间限制。
+func (s *CourseOfferingService) Enroll(actor *domain.JWTClaims, offeringID, studentID int) (*domain.Enrollment, error) {
+	logger.WithFields(map[string]interface{}{
+		"offering_id": offeringID,
+		"student_id":  studentID,
+		"admin_id":    actor.AdminID,
+	}).Info("Enrolling student")
+
+	if !actor.CanAccessStudent(studentID) {
+		return nil, errors.ErrForbidden
+	}
+
+	offering, err := s.GetOffering(offeringID)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.checkTermOpen(offering.Semester); err != nil {
+		return nil, err
+	}
+
+	override := actor.Role == domain.RoleAdmin
+	switch {
+	case offering.Status == domain.OfferingStatusCancelled:
+		return nil, errors.New(errors.ErrCodeConflict, "开课已取消")
+	case offering.Status != domain.OfferingStatusOpen && !override:
+		return nil, errors.New(errors.ErrCodeConflict, "该课程未开放选课")
+	case offering.AddDeadline != nil && time.Now().After(*offering.AddDeadline) && !override:
+		return nil, errors.New(errors.ErrCodeConflict, "已过加选截止时间")
+	}
+
+	ok, err := s.offeringRepo.StudentExists(studentID)
+	if err != nil {
+		return nil, err
+	}
+	if !ok {
+		return nil, errors.Newf(errors.ErrCodeValidation, "学生 %d 不存在", studentID)
+	}
+
+	existing, err := s.offeringRepo.FindStudentOffering(studentID, offering.SubjectID, offering.Semester,
+		[]string{domain.EnrollmentStatusEnrolled, domain.EnrollmentStatusWaitlisted})
+	if err != nil {
+		return nil, err
+	}
+	if existing == offeringID {
+		return nil, errors.New(errors.ErrCodeConflict, "学生已选该课程或已在候补名单中")
+	}
+	if existing != 0 {
+		return nil, errors.Newf(errors.ErrCodeConflict, "学生本学期已选修该科目的其他教学班（开课 %d）", existing)
+	}
+
+	var createdBy *int
+	if actor.AdminID != 0 {
+		createdBy = &actor.AdminID
+	}
+
+	enrollment, err := s.offeringRepo.Enroll(offeringID, studentID, createdBy)
+	if err != nil {
+		logger.WithError(err).WithFields(map[string]interface{}{
+			"offering_id": offeringID,
+			"student_id":  studentID,
+		}).Error("Failed to enroll student")
+		return nil, err
+	}
+	if enrollment == nil {
+		if offering.WaitlistCapacity == 0 {
+			return nil, errors.New(errors.ErrCodeConflict, "课程已满")
+		}
+		return nil, errors.New(errors.ErrCodeConflict, "课程已满且候补名单已满")
+	}
+
+	logger.WithFields(map[string]interface{}{
+		"offering_id": offeringID,
+		"student_id":  studentID,
+		"status":      enrollment.Status,
+	}).Info("Student enrolled")
+
+	return enrollment, nil
+}
+
+// Drop 学生退课或退出候补，空出的名额由候补学生按顺序递补。
+// 学生本人退课须在退选截止时间之前，已有成绩的选课不能退。
+func (s *CourseOfferingService) Drop(actor *domain.JWTClaims, offeringID, studentID int) error {
+	logger.WithFields(map[string]interface{}{
+		"offering_id": offeringID,
+		"student_id":  studentID,
+		"admin_id":    actor.AdminID,
+	}).Info("Dropping enrollment")
+
+	if !actor.CanAccessStudent(studentID) {
+		return errors.ErrForbidden
+	}
+
+	offering, err := s.GetOffering(offeringID)
+	if err != nil {
+		return err
+	}
+
+	if err := s.checkTermOpen(offering.Semester); err != nil {
+		return err
+	}
+
+	enrollment, err := s.offeringRepo.GetEnrollment(offeringID, studentID)
+	if err != nil {
+		return err
+	}
+	if enrollment == nil || enrollment.Status == domain.EnrollmentStatusDropped {
+		return errors.Newf(errors.ErrCodeNotFound, "学生 %d 未选该课程", studentID)
+	}
+
+	if enrollment.Status == domain.EnrollmentStatusEnrolled {
+		if offering.DropDeadline != nil && time.Now().After(*offering.DropDeadline) && actor.Role != domain.RoleAdmin {
+			return errors.New(errors.ErrCodeConflict, "已过退选截止时间")
+		}
+
+		scored, err := s.offeringRepo.StudentHasScores(offeringID, studentID)
+		if err != nil {
+			return err
+		}
+		if scored {
+			return errors.New(errors.ErrCodeConflict, "该课程已有成绩，不能退课")
+		}
+	}
+
+	promoted, err := s.offeringRepo.Drop(offeringID, studentID)
+	if err != nil {
+		logger.WithError(err).WithFields(map[string]interface{}{
+			"offering_id": offeringID,
+			"student_id":  studentID,
+		}).Error("Failed to drop enrollment")
+		return err
+	}
+
+	if len(promoted) > 0 {
+		logger.WithFields(map[string]interface{}{
+			"offering_id": offeringID,
+			"student_ids": promoted,
+		}).Info("Waitlisted students promoted")
+	}
+
+	return nil
+}
+
+// ListEnrollments 获取开课的选课名单，学生和家长不能查看他人名单
+func (s *CourseOfferingService) ListEnrollments(actor *domain.JWTClaims, offeringID int, req domain.EnrollmentListRequest) ([]*domain.Enrollment, error) {
+	if actor.IsStudentScoped() {
+		return nil, errors.ErrForbidden
+	}
+
+	if _, err := s.GetOffering(offeringID); err != nil {
+		return nil, err
+	}
+	return s.offeringRepo.ListEnrollments(offeringID, &req)
+}
+
+// ListStudentEnrollments 获取学生的选课记录
+func (s *CourseOfferingService) ListStudentEnrollments(actor *domain.JWTClaims, studentID int, req domain.EnrollmentListRequest) ([]*domain.Enrollment, error) {
+	if !actor.CanAccessStudent(studentID) {
+		return nil, errors.ErrForbidden
+	}
+	req.Semester = domain.NormalizeTermCode(req.Semester)
+	return s.offeringRepo.ListStudentEnrollments(studentID, &req)
+}
+
+// checkTermOpen 已结束学期的选课名单随成绩一起冻结
+func (s *CourseOfferingService) checkTermOpen(semester string) error {
+	term, err := resolveTerm(s.termRepo, semester)
+	if err != nil {
+		return err
+	}
+	if term.Status == domain.TermStatusClosed {
+		return errors.Newf(errors.ErrCodeConflict, "学期 %s 已结束，不能再选课或退课", term.Code)
+	}
+	return nil
+}
+
+// validateOffering 校验教学班号唯一性、任课老师和加退选截止时间
+func (s *CourseOfferingService) validateOffering(offering *domain.CourseOffering) error {
+	exists, err := s.offeringRepo.ExistsBySection(offering.SubjectID, offering.Semester, offering.Section, offering.ID)
+	if err != nil {
+		return err
+	}
+	if exists {
+		return errors.Newf(errors.ErrCodeConflict, "%s 学期该科目已存在教学班 %s", offering.Semester, offering.Section)
+	}
+
+	if offering.TeacherID != nil {
+		ok, err := s.offeringRepo.TeacherExists(*offering.TeacherID)
+		if err != nil {
+			return err
+		}
+		if !ok {
+			return errors.Newf(errors.ErrCodeValidation, "老师 %d 不存在", *offering.TeacherID)
+		}
+	}
+
+	if offering.AddDeadline != nil && offering.DropDeadline != nil && offering.DropDeadline.Before(*offering.AddDeadline) {
+		return errors.New(errors.ErrCodeValidation, "退选截止时间不能早于加选截止时间")
+	}
+
+	return nil
+}
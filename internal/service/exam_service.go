@@ -0,0 +1,238 @@
+package service
+
+import (
+	"time"
+
+	"student-management-system/internal/domain"
+	"student-management-system/internal/repository"
+	"student-management-system/pkg/errors"
+	"student-management-system/pkg/logger"
+)
+
+// ExamService 考试安排服务
+type ExamService struct {
+	examRepo     repository.ExamRepository
+	offeringRepo repository.CourseOfferingRepository
+	roomRepo     repository.RoomRepository
+	termRepo     repository.TermRepository
+}
+
+// NewExamService 创建考试安排服务实例
+func NewExamService(examRepo repository.ExamRepository, offeringRepo repository.CourseOfferingRepository, roomRepo repository.RoomRepository, termRepo repository.TermRepository) *ExamService {
+	return &ExamService{
+		examRepo:     examRepo,
+		offeringRepo: offeringRepo,
+		roomRepo:     roomRepo,
+		termRepo:     termRepo,
+	}
+}
+
+// CreateExam 创建考试安排
+func (s *ExamService) CreateExam(actor *domain.JWTClaims, req domain.CreateExamRequest) (*domain.Exam, error) {
+	logger.WithFields(map[string]interface{}{
+		"offering_id": req.OfferingID,
+		"exam_type":   req.ExamType,
+		"admin_id":    actor.AdminID,
+	}).Info("Creating exam")
+
+	offering, err := s.getSchedulableOffering(req.OfferingID)
+	if err != nil {
+		return nil, err
+	}
+
+	exam := &domain.Exam{
+		OfferingID: offering.ID,
+		ExamType:   req.ExamType,
+		ExamDate:   req.ExamDate,
+		StartTime:  req.StartTime,
+		EndTime:    req.EndTime,
+		RoomID:     req.RoomID,
+		Location:   req.Location,
+		Note:       req.Note,
+	}
+	if exam.ExamType == "" {
+		exam.ExamType = domain.ExamTypeFinal
+	}
+	if actor.AdminID != 0 {
+		createdBy := actor.AdminID
+		exam.CreatedBy = &createdBy
+	}
+
+	if err := s.validateExam(exam, offering); err != nil {
+		return nil, err
+	}
+	if err := s.examRepo.Create(exam); err != nil {
+		return nil, err
+	}
+
+	return s.GetExam(exam.ID)
+}
+
+// GetExam 获取考试安排
+func (s *ExamService) GetExam(id int) (*domain.Exam, error) {
+	exam, err := s.examRepo.GetByID(id)
+	if err != nil {
+		logger.WithError(err).WithFields(map[string]interface{}{
+			"exam_id": id,
+		}).Error("Failed to get exam")
+		return nil, err
+	}
+	if exam == nil {
+		return nil, errors.New(errors.ErrCodeNotFound, "考试安排不存在")
+	}
+	return exam, nil
+}
+
+// ListExams 获取考试安排列表（分页）。学生和家长通过日历订阅查看本人（子女）的考试
+func (s *ExamService) ListExams(actor *domain.JWTClaims, req domain.ExamListRequest) ([]*domain.Exam, int64, error) {
+	if actor.IsStudentScoped() {
+		return nil, 0, errors.ErrForbidden
+	}
+	if req.Page <= 0 {
+		req.Page = 1
+	}
+	if req.Size <= 0 {
+		req.Size = 10
+	}
+	req.Semester = domain.NormalizeTermCode(req.Semester)
+
+	exams, total, err := s.examRepo.List(&req)
+	if err != nil {
+		logger.WithError(err).Error("Failed to list exams")
+		return nil, 0, err
+	}
+	return exams, total, nil
+}
+
+// UpdateExam 更新考试安排
+func (s *ExamService) UpdateExam(id int, req domain.UpdateExamRequest) (*domain.Exam, error) {
+	logger.WithFields(map[string]interface{}{
+		"exam_id": id,
+	}).Info("Updating exam")
+
+	exam, err := s.GetExam(id)
+	if err != nil {
+		return nil, err
+	}
+	offering, err := s.getSchedulableOffering(exam.OfferingID)
+	if err != nil {
+		return nil, err
+	}
+
+	if req.ExamType != "" {
+		exam.ExamType = req.ExamType
+	}
+	if req.ExamDate != nil {
+		exam.ExamDate = *req.ExamDate
+	}
+	if req.StartTime != "" {
+		exam.StartTime = req.StartTime
+	}
+	if req.EndTime != "" {
+		exam.EndTime = req.EndTime
+	}
+	if req.RoomID != nil {
+		if *req.RoomID == 0 {
+			exam.RoomID = nil
+		} else {
+			exam.RoomID = req.RoomID
+		}
+	}
+	if req.Location != nil {
+		exam.Location = *req.Location
+	}
+	if req.Note != nil {
+		exam.Note = *req.Note
+	}
+
+	if err := s.validateExam(exam, offering); err != nil {
+		return nil, err
+	}
+	if err := s.examRepo.Update(exam); err != nil {
+		return nil, err
+	}
+
+	return s.GetExam(id)
+}
+
+// DeleteExam 删除考试安排
+func (s *ExamService) DeleteExam(id int) error {
+	logger.WithFields(map[string]interface{}{
+		"exam_id": id,
+	}).Info("Deleting exam")
+
+	exam, err := s.GetExam(id)
+	if err != nil {
+		return err
+	}
+	if _, err := s.getSchedulableOffering(exam.OfferingID); err != nil {
+		return err
+	}
+	return s.examRepo.Delete(id)
+}
+
+// validateExam 校验考试时间和考场，同一考场同一时间只能安排一场考试
+func (s *ExamService) validateExam(exam *domain.Exam, offering *domain.CourseOffering) error {
+	start, err := time.Parse("15:04", exam.StartTime)
+	if err != nil {
+		return errors.Newf(errors.ErrCodeValidation, "开始时间 %s 格式错误，应为 HH:MM", exam.StartTime)
+	}
+	end, err := time.Parse("15:04", exam.EndTime)
+	if err != nil {
+		return errors.Newf(errors.ErrCodeValidation, "结束时间 %s 格式错误，应为 HH:MM", exam.EndTime)
+	}
+	if !end.After(start) {
+		return errors.New(errors.ErrCodeValidation, "结束时间必须晚于开始时间")
+	}
+
+	if exam.RoomID == nil {
+		return nil
+	}
+
+	room, err := s.roomRepo.GetByID(*exam.RoomID)
+	if err != nil {
+		return err
+	}
+	if room == nil {
+		return errors.Newf(errors.ErrCodeValidation, "教室 %d 不存在", *exam.RoomID)
+	}
+	if room.Status != domain.RoomStatusActive {
+		return errors.Newf(errors.ErrCodeConflict, "教室 %s 已停用", room.Code)
+	}
+	if room.Capacity < offering.EnrolledCount {
+		return errors.Newf(errors.ErrCodeValidation, "教室 %s 容量 %d 小于选课人数 %d", room.Code, room.Capacity, offering.EnrolledCount)
+	}
+
+	other, err := s.examRepo.FindRoomConflict(room.ID, exam.ExamDate, exam.StartTime, exam.EndTime, exam.ID)
+	if err != nil {
+		return err
+	}
+	if other != nil {
+		return errors.Newf(errors.ErrCodeConflict, "教室 %s 在 %s %s-%s 已安排 %s(%s) 的考试", room.Code,
+			other.ExamDate.Format("2006-01-02"), other.StartTime, other.EndTime, other.SubjectName, other.Section)
+	}
+	return nil
+}
+
+// getSchedulableOffering 获取可以安排考试的开课：开课未取消且所在学期未结束
+func (s *ExamService) getSchedulableOffering(offeringID int) (*domain.CourseOffering, error) {
+	offering, err := s.offeringRepo.GetByID(offeringID)
+	if err != nil {
+		return nil, err
+	}
+	if offering == nil {
+		return nil, errors.Newf(errors.ErrCodeNotFound, "开课 %d 不存在", offeringID)
+	}
+	if offering.Status == domain.OfferingStatusCancelled {
+		return nil, errors.Newf(errors.ErrCodeConflict, "开课 %d 已取消，不能安排考试", offeringID)
+	}
+
+	term, err := resolveTerm(s.termRepo, offering.Semester)
+	if err != nil {
+		return nil, err
+	}
+	if term.Status == domain.TermStatusClosed {
+		return nil, errors.Newf(errors.ErrCodeConflict, "学期 %s 已结束，考试安排已冻结", term.Code)
+	}
+	return offering, nil
+}
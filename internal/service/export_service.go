@@ -0,0 +1,519 @@
+package service
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"student-management-system/internal/config"
+	"student-management-system/internal/domain"
+	"student-management-system/internal/repository"
+	"student-management-system/pkg/errors"
+	"student-management-system/pkg/logger"
+	"student-management-system/pkg/spreadsheet"
+)
+
+// exportColumn 导出列定义
+type exportColumn[T any] struct {
+	key   string
+	title string
+	value func(*T) interface{}
+}
+
+// studentExportColumns 学生可导出的列
+var studentExportColumns = []exportColumn[domain.Student]{
+	{"id", "ID", func(s *domain.Student) interface{} { return s.ID }},
+	{"student_id", "学号", func(s *domain.Student) interface{} { return s.StudentID }},
+	{"name", "姓名", func(s *domain.Student) interface{} { return s.Name }},
+	{"age", "年龄", func(s *domain.Student) interface{} { return s.Age }},
+	{"gender", "性别", func(s *domain.Student) interface{} { return s.Gender }},
+	{"phone", "手机号", func(s *domain.Student) interface{} { return s.Phone }},
+	{"email", "邮箱", func(s *domain.Student) interface{} { return s.Email }},
+	{"address", "地址", func(s *domain.Student) interface{} { return s.Address }},
+	{"major", "专业", func(s *domain.Student) interface{} { return s.Major }},
+	{"enrollment_date", "入学日期", func(s *domain.Student) interface{} { return s.EnrollmentDate }},
+	{"graduation_date", "毕业日期", func(s *domain.Student) interface{} { return s.GraduationDate }},
+	{"status", "状态", func(s *domain.Student) interface{} { return s.Status }},
+	{"class_id", "班级ID", func(s *domain.Student) interface{} {
+		if s.ClassID == nil {
+			return nil
+		}
+		return *s.ClassID
+	}},
+	{"created_at", "创建时间", func(s *domain.Student) interface{} { return s.CreatedAt }},
+}
+
+// teacherExportColumns 老师可导出的列
+var teacherExportColumns = []exportColumn[domain.Teacher]{
+	{"id", "ID", func(t *domain.Teacher) interface{} { return t.ID }},
+	{"name", "姓名", func(t *domain.Teacher) interface{} { return t.Name }},
+	{"age", "年龄", func(t *domain.Teacher) interface{} { return t.Age }},
+	{"gender", "性别", func(t *domain.Teacher) interface{} { return t.Gender }},
+	{"email", "邮箱", func(t *domain.Teacher) interface{} { return t.Email }},
+	{"phone", "手机号", func(t *domain.Teacher) interface{} { return t.Phone }},
+	{"subject_id", "科目ID", func(t *domain.Teacher) interface{} { return t.SubjectID }},
+	{"subject_name", "任教科目", func(t *domain.Teacher) interface{} { return t.SubjectName }},
+	{"title", "职称", func(t *domain.Teacher) interface{} { return t.Title }},
+	{"department", "院系", func(t *domain.Teacher) interface{} { return t.Department }},
+	{"created_at", "创建时间", func(t *domain.Teacher) interface{} { return t.CreatedAt }},
+}
+
+// scoreExportColumns 成绩可导出的列
+var scoreExportColumns = []exportColumn[domain.Score]{
+	{"id", "ID", func(s *domain.Score) interface{} { return s.ID }},
+	{"student_id", "学生ID", func(s *domain.Score) interface{} { return s.StudentID }},
+	{"student_code", "学号", func(s *domain.Score) interface{} {
+		if s.Student == nil {
+			return ""
+		}
+		return s.Student.StudentID
+	}},
+	{"student_name", "姓名", func(s *domain.Score) interface{} {
+		if s.Student == nil {
+			return ""
+		}
+		return s.Student.Name
+	}},
+	{"subject_id", "科目ID", func(s *domain.Score) interface{} { return s.SubjectID }},
+	{"subject_code", "科目代码", func(s *domain.Score) interface{} {
+		if s.Subject == nil {
+			return ""
+		}
+		return s.Subject.Code
+	}},
+	{"subject_name", "科目", func(s *domain.Score) interface{} {
+		if s.Subject == nil {
+			return ""
+		}
+		return s.Subject.Name
+	}},
+	{"teacher_id", "老师ID", func(s *domain.Score) interface{} { return s.TeacherID }},
+	{"score", "成绩", func(s *domain.Score) interface{} { return s.Score }},
+	{"semester", "学期", func(s *domain.Score) interface{} { return s.Semester }},
+	{"exam_type", "考试类型", func(s *domain.Score) interface{} { return s.ExamType }},
+	{"remarks", "备注", func(s *domain.Score) interface{} { return s.Remarks }},
+	{"created_at", "录入时间", func(s *domain.Score) interface{} { return s.CreatedAt }},
+}
+
+// ExportPlan 一次导出的内容：数据来源、列和格式
+type ExportPlan struct {
+	Resource string
+	Format   string
+
+	count func() (int64, error)
+	write func(w spreadsheet.Writer) (int64, error)
+}
+
+// FileName 导出文件名，如 students_20240901_120000.csv
+func (p *ExportPlan) FileName(t time.Time) string {
+	return fmt.Sprintf("%s_%s.%s", p.Resource, t.Format("20060102_150405"), p.Format)
+}
+
+// exportJobRecord 保存在Redis中的后台导出任务
+type exportJobRecord struct {
+	Job     domain.ExportJob `json:"job"`
+	AdminID int              `json:"admin_id"`
+}
+
+// ExportService 数据导出服务
+type ExportService struct {
+	config         config.ExportConfig
+	studentService *StudentService
+	teacherService *TeacherService
+	scoreService   ScoreService
+}
+
+// NewExportService 创建数据导出服务实例
+func NewExportService(cfg config.ExportConfig, studentService *StudentService, teacherService *TeacherService, scoreService ScoreService) *ExportService {
+	return &ExportService{
+		config:         cfg,
+		studentService: studentService,
+		teacherService: teacherService,
+		scoreService:   scoreService,
+	}
+}
+
+// PlanStudents 准备按学生列表的筛选条件导出学生，学生和家长只导出本人（子女）
+func (s *ExportService) PlanStudents(actor *domain.JWTClaims, filter domain.StudentFilter, req *domain.ExportRequest) (*ExportPlan, error) {
+	return newExportPlan("students", studentExportColumns, req,
+		func() (int64, error) {
+			n, err := s.studentService.CountStudents(actor, filter)
+			return int64(n), err
+		},
+		func(fn func(*domain.Student) error) error {
+			return s.studentService.EachStudent(actor, filter, fn)
+		},
+	)
+}
+
+// PlanTeachers 准备导出老师
+func (s *ExportService) PlanTeachers(req *domain.ExportRequest) (*ExportPlan, error) {
+	return newExportPlan("teachers", teacherExportColumns, req,
+		func() (int64, error) {
+			n, err := s.teacherService.CountTeachers()
+			return int64(n), err
+		},
+		s.teacherService.EachTeacher,
+	)
+}
+
+// PlanScores 准备按成绩列表的筛选条件导出成绩
+func (s *ExportService) PlanScores(actor *domain.JWTClaims, list domain.ScoreListRequest, req *domain.ExportRequest) (*ExportPlan, error) {
+	// 提前校验访问范围，避免后台任务中才发现无权访问
+	if _, err := s.scoreService.CountScores(actor, &list); err != nil {
+		return nil, err
+	}
+
+	return newExportPlan("scores", scoreExportColumns, req,
+		func() (int64, error) {
+			return s.scoreService.CountScores(actor, &list)
+		},
+		func(fn func(*domain.Score) error) error {
+			return s.scoreService.EachScore(actor, &list, fn)
+		},
+	)
+}
+
+// ShouldRunAsync 是否需要转为后台任务：调用方要求或数据量超过阈值
+func (s *ExportService) ShouldRunAsync(plan *ExportPlan, req *domain.ExportRequest) (bool, error) {
+	if req.Async {
+		return true, nil
+	}
+	if s.config.AsyncThreshold <= 0 {
+		return false, nil
+	}
+
+	total, err := plan.count()
+	if err != nil {
+		return false, fmt.Errorf("统计导出数据失败: %w", err)
+	}
+	return total > int64(s.config.AsyncThreshold), nil
+}
+
+// Stream 直接将导出数据写入 w，返回写出的行数
+func (s *ExportService) Stream(plan *ExportPlan, w io.Writer) (int64, error) {
+	writer, err := spreadsheet.NewWriter(w, plan.Format)
+	if err != nil {
+		return 0, err
+	}
+
+	rows, err := plan.write(writer)
+	if err != nil {
+		return rows, err
+	}
+	return rows, writer.Close()
+}
+
+// StartJob 创建后台导出任务，文件生成后可通过下载链接获取
+func (s *ExportService) StartJob(actor *domain.JWTClaims, plan *ExportPlan) (*domain.ExportJob, error) {
+	if repository.RedisClient == nil {
+		return nil, fmt.Errorf("Redis未初始化，无法创建后台导出任务")
+	}
+	if err := os.MkdirAll(s.config.Dir, 0o750); err != nil {
+		return nil, fmt.Errorf("创建导出目录失败: %w", err)
+	}
+	s.removeExpiredFiles()
+
+	id, err := newExportJobID()
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	record := &exportJobRecord{
+		Job: domain.ExportJob{
+			ID:          id,
+			Resource:    plan.Resource,
+			Format:      plan.Format,
+			Status:      domain.ExportJobPending,
+			FileName:    plan.FileName(now),
+			DownloadURL: "/api/v1/exports/" + id + "/download",
+			CreatedAt:   now,
+			ExpiresAt:   now.Add(s.jobTTL()),
+		},
+		AdminID: actor.AdminID,
+	}
+	if err := s.saveJob(record); err != nil {
+		return nil, err
+	}
+
+	logger.WithFields(map[string]interface{}{
+		"job_id":   id,
+		"admin_id": actor.AdminID,
+		"resource": plan.Resource,
+		"format":   plan.Format,
+	}).Info("Export job created")
+
+	go s.runJob(record, plan)
+
+	job := record.Job
+	return &job, nil
+}
+
+// GetJob 获取本人的后台导出任务
+func (s *ExportService) GetJob(actor *domain.JWTClaims, id string) (*domain.ExportJob, error) {
+	record, err := s.loadJob(id)
+	if err != nil {
+		return nil, err
+	}
+	if record.AdminID != actor.AdminID {
+		return nil, errors.New(errors.ErrCodeNotFound, "导出任务不存在或已过期")
+	}
+	return &record.Job, nil
+}
+
+// OpenJobFile 打开已完成的导出文件，调用方负责关闭
+func (s *ExportService) OpenJobFile(actor *domain.JWTClaims, id string) (*os.File, *domain.ExportJob, error) {
+	job, err := s.GetJob(actor, id)
+	if err != nil {
+		return nil, nil, err
+	}
+	if job.Status != domain.ExportJobCompleted {
+		return nil, nil, errors.Newf(errors.ErrCodeConflict, "导出任务尚未完成，当前状态: %s", job.Status)
+	}
+
+	f, err := os.Open(s.jobFilePath(job))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil, errors.New(errors.ErrCodeNotFound, "导出文件不存在或已过期")
+		}
+		return nil, nil, err
+	}
+	return f, job, nil
+}
+
+// runJob 执行后台导出，先写入临时文件，完成后再重命名
+func (s *ExportService) runJob(record *exportJobRecord, plan *ExportPlan) {
+	job := &record.Job
+	fields := map[string]interface{}{
+		"job_id":   job.ID,
+		"resource": job.Resource,
+	}
+
+	defer func() {
+		if r := recover(); r != nil {
+			logger.WithFields(fields).Errorf("Export job panicked: %v", r)
+			s.finishJob(record, 0, fmt.Errorf("导出任务异常终止"))
+		}
+	}()
+
+	job.Status = domain.ExportJobRunning
+	if err := s.saveJob(record); err != nil {
+		logger.WithError(err).WithFields(fields).Error("Failed to update export job")
+	}
+
+	path := s.jobFilePath(job)
+	tmp := path + ".tmp"
+	rows, err := s.writeFile(plan, tmp)
+	if err == nil {
+		err = os.Rename(tmp, path)
+	}
+	if err != nil {
+		os.Remove(tmp)
+		logger.WithError(err).WithFields(fields).Error("Export job failed")
+	} else {
+		fields["rows"] = rows
+		logger.WithFields(fields).Info("Export job completed")
+	}
+
+	s.finishJob(record, rows, err)
+}
+
+// writeFile 将导出数据写入文件
+func (s *ExportService) writeFile(plan *ExportPlan, path string) (int64, error) {
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0o640)
+	if err != nil {
+		return 0, err
+	}
+
+	rows, err := s.Stream(plan, f)
+	if closeErr := f.Close(); err == nil {
+		err = closeErr
+	}
+	return rows, err
+}
+
+// finishJob 记录任务结果
+func (s *ExportService) finishJob(record *exportJobRecord, rows int64, err error) {
+	now := time.Now()
+	record.Job.Rows = rows
+	record.Job.FinishedAt = &now
+	if err != nil {
+		record.Job.Status = domain.ExportJobFailed
+		record.Job.Error = err.Error()
+	} else {
+		record.Job.Status = domain.ExportJobCompleted
+	}
+
+	if err := s.saveJob(record); err != nil {
+		logger.WithError(err).WithFields(map[string]interface{}{
+			"job_id": record.Job.ID,
+		}).Error("Failed to update export job")
+	}
+}
+
+// saveJob 保存任务状态，过期时间与导出文件的保留时间一致
+func (s *ExportService) saveJob(record *exportJobRecord) error {
+	data, err := json.Marshal(record)
+	if err != nil {
+		return err
+	}
+
+	ttl := time.Until(record.Job.ExpiresAt)
+	if ttl <= 0 {
+		ttl = time.Minute
+	}
+	return repository.RedisClient.Set(context.Background(), exportJobKey(record.Job.ID), data, ttl).Err()
+}
+
+// loadJob 读取任务状态
+func (s *ExportService) loadJob(id string) (*exportJobRecord, error) {
+	if repository.RedisClient == nil {
+		return nil, fmt.Errorf("Redis未初始化")
+	}
+
+	data, err := repository.RedisClient.Get(context.Background(), exportJobKey(id)).Bytes()
+	if err != nil {
+		return nil, errors.New(errors.ErrCodeNotFound, "导出任务不存在或已过期")
+	}
+
+	var record exportJobRecord
+	if err := json.Unmarshal(data, &record); err != nil {
+		return nil, fmt.Errorf("解析导出任务失败: %w", err)
+	}
+	return &record, nil
+}
+
+// jobFilePath 任务文件路径，使用任务ID命名避免冲突
+func (s *ExportService) jobFilePath(job *domain.ExportJob) string {
+	return filepath.Join(s.config.Dir, job.ID+"."+job.Format)
+}
+
+// jobTTL 后台任务及文件的保留时间
+func (s *ExportService) jobTTL() time.Duration {
+	if s.config.JobTTL > 0 {
+		return s.config.JobTTL
+	}
+	return 24 * time.Hour
+}
+
+// removeExpiredFiles 删除超过保留时间的导出文件
+func (s *ExportService) removeExpiredFiles() {
+	entries, err := os.ReadDir(s.config.Dir)
+	if err != nil {
+		return
+	}
+
+	expireBefore := time.Now().Add(-s.jobTTL())
+	for _, entry := range entries {
+		info, err := entry.Info()
+		if err != nil || entry.IsDir() || info.ModTime().After(expireBefore) {
+			continue
+		}
+		if err := os.Remove(filepath.Join(s.config.Dir, entry.Name())); err != nil {
+			logger.WithError(err).WithFields(map[string]interface{}{
+				"file": entry.Name(),
+			}).Warn("Failed to remove expired export file")
+		}
+	}
+}
+
+// newExportPlan 根据请求选择列并生成导出计划
+func newExportPlan[T any](resource string, all []exportColumn[T], req *domain.ExportRequest, count func() (int64, error), each func(func(*T) error) error) (*ExportPlan, error) {
+	format := req.Format
+	if format == "" {
+		format = spreadsheet.FormatCSV
+	}
+
+	columns, err := selectExportColumns(all, req.Columns)
+	if err != nil {
+		return nil, err
+	}
+
+	keys := make([]string, len(columns))
+	titles := make([]string, len(columns))
+	plan := &ExportPlan{
+		Resource: resource,
+		Format:   format,
+		count:    count,
+	}
+	for i, col := range columns {
+		keys[i] = col.key
+		titles[i] = col.key
+		if req.Headers == domain.ExportHeadersZH {
+			titles[i] = col.title
+		}
+	}
+
+	plan.write = func(w spreadsheet.Writer) (int64, error) {
+		if err := w.WriteHeader(keys, titles); err != nil {
+			return 0, err
+		}
+
+		var rows int64
+		values := make([]interface{}, len(columns))
+		err := each(func(item *T) error {
+			for i, col := range columns {
+				values[i] = col.value(item)
+			}
+			rows++
+			return w.WriteRow(values)
+		})
+		return rows, err
+	}
+
+	return plan, nil
+}
+
+// selectExportColumns 按逗号分隔的字段名选择列，为空时返回全部列
+func selectExportColumns[T any](all []exportColumn[T], selected string) ([]exportColumn[T], error) {
+	if strings.TrimSpace(selected) == "" {
+		return all, nil
+	}
+
+	byKey := make(map[string]exportColumn[T], len(all))
+	for _, col := range all {
+		byKey[col.key] = col
+	}
+
+	var columns []exportColumn[T]
+	seen := make(map[string]bool)
+	for _, key := range strings.Split(selected, ",") {
+		key = strings.TrimSpace(key)
+		if key == "" || seen[key] {
+			continue
+		}
+		col, ok := byKey[key]
+		if !ok {
+			valid := make([]string, len(all))
+			for i, c := range all {
+				valid[i] = c.key
+			}
+			return nil, errors.Newf(errors.ErrCodeInvalidRequest, "未知的导出列: %s，可选: %s", key, strings.Join(valid, ", "))
+		}
+		seen[key] = true
+		columns = append(columns, col)
+	}
+	return columns, nil
+}
+
+// exportJobKey 后台导出任务在Redis中的键
+func exportJobKey(id string) string {
+	return "export_job:" + id
+}
+
+// newExportJobID 生成随机任务ID
+func newExportJobID() (string, error) {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return "", fmt.Errorf("生成导出任务ID失败: %w", err)
+	}
+	return hex.EncodeToString(b), nil
+}
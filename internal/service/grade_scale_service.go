@@ -0,0 +1,350 @@
+package service
+
+import (
+	"fmt"
+	"regexp"
+
+	"student-management-system/internal/config"
+	"student-management-system/internal/domain"
+	"student-management-system/internal/repository"
+	"student-management-system/pkg/errors"
+	"student-management-system/pkg/logger"
+)
+
+// gradeScaleNamePattern 绩点制名称：小写字母开头，由小写字母、数字和下划线组成
+var gradeScaleNamePattern = regexp.MustCompile(`^[a-z][a-z0-9_]*$`)
+
+// GradeScaleService 绩点制服务。绩点制可在配置文件或数据库中定义，同名时以配置文件为准
+type GradeScaleService struct {
+	repo         repository.GradeScaleRepository
+	configScales map[string]*domain.GradeScale
+	configOrder  []string
+	defaultName  string
+}
+
+// NewGradeScaleService 创建绩点制服务实例，校验配置文件中定义的绩点制
+func NewGradeScaleService(cfg config.GradeScaleConfig, repo repository.GradeScaleRepository) (*GradeScaleService, error) {
+	s := &GradeScaleService{
+		repo:         repo,
+		configScales: make(map[string]*domain.GradeScale),
+		defaultName:  cfg.Default,
+	}
+	if s.defaultName == "" {
+		s.defaultName = domain.DefaultGradeScaleName
+	}
+
+	for _, def := range cfg.Scales {
+		scale := &domain.GradeScale{
+			Name:        def.Name,
+			DisplayName: def.DisplayName,
+			Description: def.Description,
+			Formula:     def.Formula,
+			MaxPoint:    def.MaxPoint,
+			Source:      domain.GradeScaleSourceConfig,
+		}
+		for _, band := range def.Bands {
+			scale.Bands = append(scale.Bands, domain.GradeBand{
+				MinScore:   band.MinScore,
+				Letter:     band.Letter,
+				GradePoint: band.GradePoint,
+				Passed:     band.Passed,
+			})
+		}
+		if scale.DisplayName == "" {
+			scale.DisplayName = scale.Name
+		}
+		if err := normalizeGradeScale(scale); err != nil {
+			return nil, fmt.Errorf("invalid grade scale %q in config: %w", def.Name, err)
+		}
+		if _, exists := s.configScales[scale.Name]; exists {
+			return nil, fmt.Errorf("duplicate grade scale %q in config", scale.Name)
+		}
+		s.configScales[scale.Name] = scale
+		s.configOrder = append(s.configOrder, scale.Name)
+	}
+
+	return s, nil
+}
+
+// ListScales 获取全部绩点制，配置文件中的绩点制在前
+func (s *GradeScaleService) ListScales() ([]*domain.GradeScale, error) {
+	scales := make([]*domain.GradeScale, 0, len(s.configOrder))
+	for _, name := range s.configOrder {
+		scales = append(scales, s.configScales[name])
+	}
+
+	stored, err := s.repo.List()
+	if err != nil {
+		logger.WithError(err).Error("Failed to list grade scales")
+		return nil, err
+	}
+	for _, scale := range stored {
+		if _, overridden := s.configScales[scale.Name]; !overridden {
+			scales = append(scales, scale)
+		}
+	}
+	return scales, nil
+}
+
+// GetScale 根据名称获取绩点制
+func (s *GradeScaleService) GetScale(name string) (*domain.GradeScale, error) {
+	scale, err := s.findScale(name)
+	if err != nil {
+		return nil, err
+	}
+	if scale == nil {
+		return nil, errors.Newf(errors.ErrCodeNotFound, "绩点制 %s 不存在", name)
+	}
+	return scale, nil
+}
+
+// CreateScale 创建自定义绩点制
+func (s *GradeScaleService) CreateScale(req domain.CreateGradeScaleRequest) (*domain.GradeScale, error) {
+	logger.WithFields(map[string]interface{}{
+		"name": req.Name,
+	}).Info("Creating grade scale")
+
+	if !gradeScaleNamePattern.MatchString(req.Name) {
+		return nil, errors.New(errors.ErrCodeValidation, "绩点制名称只能包含小写字母、数字和下划线，且以字母开头")
+	}
+
+	existing, err := s.findScale(req.Name)
+	if err != nil {
+		return nil, err
+	}
+	if existing != nil {
+		return nil, errors.Newf(errors.ErrCodeConflict, "绩点制 %s 已存在", req.Name)
+	}
+
+	scale := &domain.GradeScale{
+		Name:        req.Name,
+		DisplayName: req.DisplayName,
+		Description: req.Description,
+		Formula:     req.Formula,
+		MaxPoint:    req.MaxPoint,
+		Bands:       req.Bands,
+		Source:      domain.GradeScaleSourceDatabase,
+	}
+	if err := normalizeGradeScale(scale); err != nil {
+		return nil, err
+	}
+
+	if err := s.repo.Create(scale); err != nil {
+		return nil, err
+	}
+	return scale, nil
+}
+
+// UpdateScale 更新自定义绩点制，内置和配置文件中的绩点制不能修改
+func (s *GradeScaleService) UpdateScale(name string, req domain.UpdateGradeScaleRequest) (*domain.GradeScale, error) {
+	scale, err := s.getEditableScale(name)
+	if err != nil {
+		return nil, err
+	}
+
+	if req.DisplayName != "" {
+		scale.DisplayName = req.DisplayName
+	}
+	if req.Description != "" {
+		scale.Description = req.Description
+	}
+	if req.Formula != "" {
+		scale.Formula = req.Formula
+	}
+	if req.MaxPoint != 0 {
+		scale.MaxPoint = req.MaxPoint
+	}
+	if len(req.Bands) > 0 {
+		scale.Bands = req.Bands
+	}
+	if err := normalizeGradeScale(scale); err != nil {
+		return nil, err
+	}
+
+	if err := s.repo.Update(scale); err != nil {
+		return nil, err
+	}
+	return scale, nil
+}
+
+// DeleteScale 删除自定义绩点制，默认绩点制和仍被专业使用的绩点制不能删除
+func (s *GradeScaleService) DeleteScale(name string) error {
+	scale, err := s.getEditableScale(name)
+	if err != nil {
+		return err
+	}
+	if scale.Name == s.defaultName {
+		return errors.Newf(errors.ErrCodeConflict, "绩点制 %s 是默认绩点制，不能删除", name)
+	}
+
+	count, err := s.repo.MajorsUsingScale(scale.Name)
+	if err != nil {
+		return err
+	}
+	if count > 0 {
+		return errors.Newf(errors.ErrCodeConflict, "绩点制 %s 仍被 %d 个专业使用，不能删除", name, count)
+	}
+
+	return s.repo.Delete(scale.ID)
+}
+
+// ListMajorScales 获取各专业使用的绩点制
+func (s *GradeScaleService) ListMajorScales() ([]*domain.MajorGradeScale, error) {
+	majors, err := s.repo.ListMajorScales()
+	if err != nil {
+		logger.WithError(err).Error("Failed to list major grade scales")
+		return nil, err
+	}
+	if majors == nil {
+		majors = []*domain.MajorGradeScale{}
+	}
+	return majors, nil
+}
+
+// SetMajorScale 设置专业使用的绩点制
+func (s *GradeScaleService) SetMajorScale(major string, req domain.SetMajorGradeScaleRequest) error {
+	logger.WithFields(map[string]interface{}{
+		"major": major,
+		"scale": req.ScaleName,
+	}).Info("Setting major grade scale")
+
+	if major == "" {
+		return errors.New(errors.ErrCodeValidation, "专业名称不能为空")
+	}
+	if _, err := s.GetScale(req.ScaleName); err != nil {
+		return err
+	}
+	return s.repo.SetMajorScale(major, req.ScaleName)
+}
+
+// DeleteMajorScale 取消专业的绩点制设置，恢复使用默认绩点制
+func (s *GradeScaleService) DeleteMajorScale(major string) error {
+	deleted, err := s.repo.DeleteMajorScale(major)
+	if err != nil {
+		return err
+	}
+	if !deleted {
+		return errors.Newf(errors.ErrCodeNotFound, "专业 %s 未设置绩点制", major)
+	}
+	return nil
+}
+
+// ResolveForStudent 确定学生成绩使用的绩点制：请求指定的绩点制优先，其次为专业设置，最后为默认绩点制
+func (s *GradeScaleService) ResolveForStudent(studentID int, requested string) (*domain.GradeScale, error) {
+	if requested != "" {
+		return s.GetScale(requested)
+	}
+
+	name, err := s.repo.GetStudentScaleName(studentID)
+	if err != nil {
+		return nil, err
+	}
+	return s.scaleOrDefault(name, map[string]interface{}{"student_id": studentID})
+}
+
+// ResolveForMajor 确定专业使用的绩点制：请求指定的绩点制优先，其次为专业设置，最后为默认绩点制
+func (s *GradeScaleService) ResolveForMajor(major, requested string) (*domain.GradeScale, error) {
+	if requested != "" {
+		return s.GetScale(requested)
+	}
+
+	name, err := s.repo.GetMajorScaleName(major)
+	if err != nil {
+		return nil, err
+	}
+	return s.scaleOrDefault(name, map[string]interface{}{"major": major})
+}
+
+// scaleOrDefault 获取专业设置的绩点制，未设置或已不存在时使用默认绩点制
+func (s *GradeScaleService) scaleOrDefault(name string, fields map[string]interface{}) (*domain.GradeScale, error) {
+	if name != "" {
+		scale, err := s.findScale(name)
+		if err != nil {
+			return nil, err
+		}
+		if scale != nil {
+			return scale, nil
+		}
+		fields["scale"] = name
+		logger.WithFields(fields).Warn("Major grade scale not found, using default")
+	}
+
+	return s.defaultScale()
+}
+
+// defaultScale 获取默认绩点制，未定义时使用内置标准4.0分制
+func (s *GradeScaleService) defaultScale() (*domain.GradeScale, error) {
+	scale, err := s.findScale(s.defaultName)
+	if err != nil {
+		return nil, err
+	}
+	if scale == nil {
+		logger.WithFields(map[string]interface{}{
+			"scale": s.defaultName,
+		}).Warn("Default grade scale not found, using standard scale")
+		return domain.StandardGradeScale(), nil
+	}
+	return scale, nil
+}
+
+// findScale 根据名称查找绩点制，不存在时返回 nil
+func (s *GradeScaleService) findScale(name string) (*domain.GradeScale, error) {
+	if scale, ok := s.configScales[name]; ok {
+		return scale, nil
+	}
+	return s.repo.GetByName(name)
+}
+
+// getEditableScale 获取可修改的绩点制
+func (s *GradeScaleService) getEditableScale(name string) (*domain.GradeScale, error) {
+	scale, err := s.GetScale(name)
+	if err != nil {
+		return nil, err
+	}
+	if scale.Source == domain.GradeScaleSourceConfig {
+		return nil, errors.Newf(errors.ErrCodeConflict, "绩点制 %s 定义在配置文件中，不能通过接口修改", name)
+	}
+	if scale.IsBuiltin {
+		return nil, errors.Newf(errors.ErrCodeConflict, "绩点制 %s 为内置绩点制，不能修改", name)
+	}
+	return scale, nil
+}
+
+// normalizeGradeScale 补全默认值并校验分数段：最低分不能重复，且必须有一段从0分开始
+func normalizeGradeScale(scale *domain.GradeScale) error {
+	if scale.Formula == "" {
+		scale.Formula = domain.GradeFormulaBands
+	}
+	if scale.MaxPoint == 0 {
+		scale.MaxPoint = 4
+	}
+
+	switch scale.Formula {
+	case domain.GradeFormulaBands, domain.GradeFormulaLinear, domain.GradeFormulaPKU:
+	default:
+		return errors.Newf(errors.ErrCodeValidation, "不支持的绩点计算方式 %s", scale.Formula)
+	}
+	if scale.Formula == domain.GradeFormulaPKU && scale.MaxPoint != 4 {
+		return errors.New(errors.ErrCodeValidation, "北大公式的满绩点为4")
+	}
+	if len(scale.Bands) == 0 {
+		return errors.New(errors.ErrCodeValidation, "绩点制至少需要一个分数段")
+	}
+
+	seen := make(map[float64]bool, len(scale.Bands))
+	for _, band := range scale.Bands {
+		if seen[band.MinScore] {
+			return errors.Newf(errors.ErrCodeValidation, "分数段最低分 %g 重复", band.MinScore)
+		}
+		seen[band.MinScore] = true
+		if band.GradePoint != nil && *band.GradePoint > scale.MaxPoint {
+			return errors.Newf(errors.ErrCodeValidation, "等级 %s 的绩点超过满绩点 %g", band.Letter, scale.MaxPoint)
+		}
+	}
+	if !seen[0] {
+		return errors.New(errors.ErrCodeValidation, "分数段必须包含最低分为0的一段")
+	}
+
+	scale.SortBands()
+	return nil
+}
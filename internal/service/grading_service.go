@@ -0,0 +1,321 @@
+package service
+
+import (
+	"math"
+
+	"student-management-system/internal/domain"
+	"student-management-system/internal/repository"
+	"student-management-system/pkg/errors"
+	"student-management-system/pkg/logger"
+)
+
+// GradingService 评分方案与总评成绩服务
+type GradingService struct {
+	gradingRepo  repository.GradingRepository
+	offeringRepo repository.CourseOfferingRepository
+	termRepo     repository.TermRepository
+}
+
+// NewGradingService 创建评分服务实例
+func NewGradingService(gradingRepo repository.GradingRepository, offeringRepo repository.CourseOfferingRepository, termRepo repository.TermRepository) *GradingService {
+	return &GradingService{
+		gradingRepo:  gradingRepo,
+		offeringRepo: offeringRepo,
+		termRepo:     termRepo,
+	}
+}
+
+// GetSubjectScheme 获取科目的评分方案，未设置时返回默认方案
+func (s *GradingService) GetSubjectScheme(subjectID int) (*domain.GradingScheme, error) {
+	if err := s.checkSubject(subjectID); err != nil {
+		return nil, err
+	}
+
+	scheme, err := s.gradingRepo.GetSchemeBySubject(subjectID)
+	if err != nil {
+		return nil, err
+	}
+	if scheme == nil {
+		return domain.DefaultGradingScheme(), nil
+	}
+	return scheme, nil
+}
+
+// SetSubjectScheme 设置科目的评分方案，并重新计算未结束学期中该科目的总评成绩
+func (s *GradingService) SetSubjectScheme(subjectID int, req domain.GradingSchemeRequest) (*domain.GradingScheme, error) {
+	logger.WithFields(map[string]interface{}{
+		"subject_id": subjectID,
+	}).Info("Setting subject grading scheme")
+
+	if err := s.checkSubject(subjectID); err != nil {
+		return nil, err
+	}
+
+	scheme, err := newGradingScheme(req)
+	if err != nil {
+		return nil, err
+	}
+	scheme.SubjectID = &subjectID
+	scheme.Source = domain.GradingSchemeSourceSubject
+
+	if err := s.gradingRepo.SaveScheme(scheme); err != nil {
+		return nil, err
+	}
+
+	if _, err := s.recomputeOpen(subjectID, 0); err != nil {
+		return nil, err
+	}
+	return scheme, nil
+}
+
+// DeleteSubjectScheme 删除科目的评分方案，恢复使用默认方案
+func (s *GradingService) DeleteSubjectScheme(subjectID int) error {
+	scheme, err := s.gradingRepo.GetSchemeBySubject(subjectID)
+	if err != nil {
+		return err
+	}
+	if scheme == nil {
+		return errors.New(errors.ErrCodeNotFound, "科目未设置评分方案")
+	}
+
+	if err := s.gradingRepo.DeleteScheme(scheme.ID); err != nil {
+		return err
+	}
+
+	_, err = s.recomputeOpen(subjectID, 0)
+	return err
+}
+
+// GetOfferingScheme 获取开课实际使用的评分方案：开课方案优先，其次为科目方案，最后为默认方案
+func (s *GradingService) GetOfferingScheme(offeringID int) (*domain.GradingScheme, error) {
+	offering, err := s.getOffering(offeringID)
+	if err != nil {
+		return nil, err
+	}
+	return s.effectiveScheme(offering.ID, offering.SubjectID)
+}
+
+// SetOfferingScheme 设置开课的评分方案，并重新计算该开课的总评成绩。已结束学期的开课不能修改评分方案
+func (s *GradingService) SetOfferingScheme(offeringID int, req domain.GradingSchemeRequest) (*domain.GradingScheme, error) {
+	logger.WithFields(map[string]interface{}{
+		"offering_id": offeringID,
+	}).Info("Setting offering grading scheme")
+
+	offering, err := s.getWritableOffering(offeringID)
+	if err != nil {
+		return nil, err
+	}
+
+	scheme, err := newGradingScheme(req)
+	if err != nil {
+		return nil, err
+	}
+	scheme.OfferingID = &offering.ID
+	scheme.Source = domain.GradingSchemeSourceOffering
+
+	if err := s.gradingRepo.SaveScheme(scheme); err != nil {
+		return nil, err
+	}
+
+	if _, err := s.recomputeOpen(0, offering.ID); err != nil {
+		return nil, err
+	}
+	return scheme, nil
+}
+
+// DeleteOfferingScheme 删除开课的评分方案，恢复使用科目方案或默认方案
+func (s *GradingService) DeleteOfferingScheme(offeringID int) error {
+	offering, err := s.getWritableOffering(offeringID)
+	if err != nil {
+		return err
+	}
+
+	scheme, err := s.gradingRepo.GetSchemeByOffering(offering.ID)
+	if err != nil {
+		return err
+	}
+	if scheme == nil {
+		return errors.New(errors.ErrCodeNotFound, "开课未设置评分方案")
+	}
+
+	if err := s.gradingRepo.DeleteScheme(scheme.ID); err != nil {
+		return err
+	}
+
+	_, err = s.recomputeOpen(0, offering.ID)
+	return err
+}
+
+// Recompute 重新计算学生某学期某科目的总评成绩，各考试类型成绩均已删除时删除总评成绩
+func (s *GradingService) Recompute(key domain.FinalGradeKey) error {
+	scores, offeringID, err := s.gradingRepo.GetComponentScores(key)
+	if err != nil {
+		return err
+	}
+	if len(scores) == 0 {
+		return s.gradingRepo.DeleteFinalGrade(key)
+	}
+
+	scheme, err := s.effectiveScheme(offeringID, key.SubjectID)
+	if err != nil {
+		return err
+	}
+
+	grade := &domain.FinalGrade{
+		StudentID:  key.StudentID,
+		SubjectID:  key.SubjectID,
+		Semester:   key.Semester,
+		OfferingID: offeringID,
+	}
+	if scheme.ID != 0 {
+		grade.SchemeID = &scheme.ID
+	}
+	scheme.Apply(grade, scores)
+
+	return s.gradingRepo.SaveFinalGrade(grade)
+}
+
+// RecomputeOffering 重新计算开课的全部总评成绩，已结束学期的成绩不重新计算
+func (s *GradingService) RecomputeOffering(offeringID int) (*domain.RecomputeResult, error) {
+	if _, err := s.getWritableOffering(offeringID); err != nil {
+		return nil, err
+	}
+
+	count, err := s.recomputeOpen(0, offeringID)
+	if err != nil {
+		return nil, err
+	}
+	return &domain.RecomputeResult{Recomputed: count}, nil
+}
+
+// ListStudentFinalGrades 获取学生的总评成绩，学生和家长只能看到各项成绩均已发布的总评成绩
+func (s *GradingService) ListStudentFinalGrades(actor *domain.JWTClaims, studentID int, req domain.FinalGradeListRequest) ([]*domain.FinalGrade, error) {
+	if !actor.CanAccessStudent(studentID) {
+		return nil, errors.ErrForbidden
+	}
+	return s.gradingRepo.ListStudentFinalGrades(studentID, domain.NormalizeTermCode(req.Semester), actor.IsStudentScoped())
+}
+
+// ListOfferingFinalGrades 获取开课的总评成绩，学生和家长无权查看整个教学班的成绩
+func (s *GradingService) ListOfferingFinalGrades(actor *domain.JWTClaims, offeringID int) ([]*domain.FinalGrade, error) {
+	if actor.IsStudentScoped() {
+		return nil, errors.ErrForbidden
+	}
+	if _, err := s.getOffering(offeringID); err != nil {
+		return nil, err
+	}
+	return s.gradingRepo.ListOfferingFinalGrades(offeringID)
+}
+
+// studentFinalGrades 获取学生全部学期的总评成绩，publishedOnly 为 true 时只返回各项成绩均已发布的总评成绩
+func (s *GradingService) studentFinalGrades(studentID int, publishedOnly bool) ([]*domain.FinalGrade, error) {
+	return s.gradingRepo.ListStudentFinalGrades(studentID, "", publishedOnly)
+}
+
+// recomputeOpen 重新计算未结束学期中某科目或某开课的总评成绩
+func (s *GradingService) recomputeOpen(subjectID, offeringID int) (int, error) {
+	keys, err := s.gradingRepo.ListOpenGradeKeys(subjectID, offeringID)
+	if err != nil {
+		return 0, err
+	}
+
+	for _, key := range keys {
+		if err := s.Recompute(key); err != nil {
+			logger.WithError(err).WithFields(map[string]interface{}{
+				"student_id": key.StudentID,
+				"subject_id": key.SubjectID,
+				"semester":   key.Semester,
+			}).Error("Failed to recompute final grade")
+			return 0, err
+		}
+	}
+
+	logger.WithFields(map[string]interface{}{
+		"subject_id":  subjectID,
+		"offering_id": offeringID,
+		"count":       len(keys),
+	}).Info("Final grades recomputed")
+
+	return len(keys), nil
+}
+
+// effectiveScheme 确定开课实际使用的评分方案
+func (s *GradingService) effectiveScheme(offeringID, subjectID int) (*domain.GradingScheme, error) {
+	if offeringID != 0 {
+		scheme, err := s.gradingRepo.GetSchemeByOffering(offeringID)
+		if err != nil {
+			return nil, err
+		}
+		if scheme != nil {
+			return scheme, nil
+		}
+	}
+
+	scheme, err := s.gradingRepo.GetSchemeBySubject(subjectID)
+	if err != nil {
+		return nil, err
+	}
+	if scheme != nil {
+		return scheme, nil
+	}
+	return domain.DefaultGradingScheme(), nil
+}
+
+// checkSubject 检查科目是否存在
+func (s *GradingService) checkSubject(subjectID int) error {
+	ok, err := s.offeringRepo.SubjectExists(subjectID)
+	if err != nil {
+		return err
+	}
+	if !ok {
+		return errors.New(errors.ErrCodeNotFound, "科目不存在")
+	}
+	return nil
+}
+
+// getOffering 获取开课
+func (s *GradingService) getOffering(offeringID int) (*domain.CourseOffering, error) {
+	offering, err := s.offeringRepo.GetByID(offeringID)
+	if err != nil {
+		return nil, err
+	}
+	if offering == nil {
+		return nil, errors.New(errors.ErrCodeNotFound, "开课不存在")
+	}
+	return offering, nil
+}
+
+// getWritableOffering 获取开课，已结束学期的总评成绩已冻结
+func (s *GradingService) getWritableOffering(offeringID int) (*domain.CourseOffering, error) {
+	offering, err := s.getOffering(offeringID)
+	if err != nil {
+		return nil, err
+	}
+
+	term, err := resolveTerm(s.termRepo, offering.Semester)
+	if err != nil {
+		return nil, err
+	}
+	if term.Status == domain.TermStatusClosed {
+		return nil, errors.Newf(errors.ErrCodeConflict, "学期 %s 已结束，总评成绩已冻结", term.Code)
+	}
+	return offering, nil
+}
+
+// newGradingScheme 根据请求创建评分方案，权重保留两位小数，合计须为100
+func newGradingScheme(req domain.GradingSchemeRequest) (*domain.GradingScheme, error) {
+	scheme := &domain.GradingScheme{
+		MidtermWeight:    round2(req.MidtermWeight),
+		FinalWeight:      round2(req.FinalWeight),
+		QuizWeight:       round2(req.QuizWeight),
+		AssignmentWeight: round2(req.AssignmentWeight),
+		MissingPolicy:    req.MissingPolicy,
+	}
+	if scheme.MissingPolicy == "" {
+		scheme.MissingPolicy = domain.MissingPolicyRenormalize
+	}
+	if math.Abs(scheme.TotalWeight()-100) > 0.001 {
+		return nil, errors.Newf(errors.ErrCodeValidation, "各考试类型权重合计必须为100，当前为 %g", scheme.TotalWeight())
+	}
+	return scheme, nil
+}
@@ -0,0 +1,385 @@
+package service
+
+import (
+	"fmt"
+	"sort"
+	"time"
+
+	"student-management-system/internal/config"
+	"student-management-system/internal/domain"
+	"student-management-system/internal/repository"
+	"student-management-system/pkg/errors"
+	"student-management-system/pkg/logger"
+)
+
+// LeaveService 请假服务：学生或家长提交请假申请，按请假天数确定的审批链依次由班主任或管理员审批，
+// 批准后请假期间的考勤记为请假
+type LeaveService struct {
+	leaveRepo   repository.LeaveRepository
+	studentRepo repository.StudentRepository
+	maxDays     int
+	chains      []leaveApprovalChain
+}
+
+// leaveApprovalChain 请假天数不超过 maxDays（0 表示不限）时使用的审批链
+type leaveApprovalChain struct {
+	maxDays   int
+	approvers []string
+}
+
+// NewLeaveService 创建请假服务实例，校验审批链配置：每条审批链至少有一个审批人，且所有不超过 max_days 的请假都有审批链
+func NewLeaveService(cfg config.LeaveConfig, leaveRepo repository.LeaveRepository, studentRepo repository.StudentRepository) (*LeaveService, error) {
+	if cfg.MaxDays < 1 {
+		return nil, fmt.Errorf("leave max_days must be at least 1, got %d", cfg.MaxDays)
+	}
+	if len(cfg.ApprovalChains) == 0 {
+		return nil, fmt.Errorf("leave approval_chains must not be empty")
+	}
+
+	chains := make([]leaveApprovalChain, 0, len(cfg.ApprovalChains))
+	for i, def := range cfg.ApprovalChains {
+		if def.MaxDays < 0 {
+			return nil, fmt.Errorf("leave approval chain %d: max_days must not be negative, got %d", i+1, def.MaxDays)
+		}
+		if len(def.Approvers) == 0 {
+			return nil, fmt.Errorf("leave approval chain %d: approvers must not be empty", i+1)
+		}
+		for _, approver := range def.Approvers {
+			if approver != domain.LeaveApproverHomeroom && approver != domain.LeaveApproverAdmin {
+				return nil, fmt.Errorf("leave approval chain %d: unknown approver %q, expected homeroom or admin", i+1, approver)
+			}
+		}
+		chains = append(chains, leaveApprovalChain{maxDays: def.MaxDays, approvers: def.Approvers})
+	}
+
+	// 按天数从短到长匹配，不限天数的审批链排在最后
+	sort.SliceStable(chains, func(i, j int) bool {
+		if chains[i].maxDays == 0 || chains[j].maxDays == 0 {
+			return chains[j].maxDays == 0 && chains[i].maxDays != 0
+		}
+		return chains[i].maxDays < chains[j].maxDays
+	})
+	if last := chains[len(chains)-1]; last.maxDays != 0 && last.maxDays < cfg.MaxDays {
+		return nil, fmt.Errorf("leave approval chains cover up to %d days, but max_days is %d", last.maxDays, cfg.MaxDays)
+	}
+
+	return &LeaveService{
+		leaveRepo:   leaveRepo,
+		studentRepo: studentRepo,
+		maxDays:     cfg.MaxDays,
+		chains:      chains,
+	}, nil
+}
+
+// SubmitLeave 提交请假申请。学生和家长只能为本人（子女）请假，同一学生的请假日期不能与审批中或已批准的请假重叠
+func (s *LeaveService) SubmitLeave(actor *domain.JWTClaims, req domain.CreateLeaveRequest) (*domain.LeaveRequest, error) {
+	studentID := req.StudentID
+	if studentID == 0 {
+		if !actor.IsStudentScoped() || len(actor.StudentIDs) != 1 {
+			return nil, errors.New(errors.ErrCodeValidation, "请指定请假的学生")
+		}
+		studentID = actor.StudentIDs[0]
+	}
+
+	logger.WithFields(map[string]interface{}{
+		"student_id": studentID,
+		"leave_type": req.LeaveType,
+		"admin_id":   actor.AdminID,
+	}).Info("Submitting leave request")
+
+	if !actor.CanAccessStudent(studentID) {
+		return nil, errors.ErrForbidden
+	}
+	student, err := s.studentRepo.GetByID(studentID)
+	if err != nil {
+		return nil, err
+	}
+	if student == nil {
+		return nil, errors.Newf(errors.ErrCodeNotFound, "学生 %d 不存在", studentID)
+	}
+
+	startDate := time.Date(req.StartDate.Year(), req.StartDate.Month(), req.StartDate.Day(), 0, 0, 0, 0, time.UTC)
+	endDate := time.Date(req.EndDate.Year(), req.EndDate.Month(), req.EndDate.Day(), 0, 0, 0, 0, time.UTC)
+	if endDate.Before(startDate) {
+		return nil, errors.New(errors.ErrCodeValidation, "结束日期不能早于开始日期")
+	}
+	days := int(endDate.Sub(startDate).Hours()/24) + 1
+	if days > s.maxDays {
+		return nil, errors.Newf(errors.ErrCodeValidation, "单次请假不能超过 %d 天", s.maxDays)
+	}
+
+	overlap, err := s.leaveRepo.HasOverlap(studentID, startDate, endDate)
+	if err != nil {
+		return nil, err
+	}
+	if overlap {
+		return nil, errors.New(errors.ErrCodeConflict, "该时间段内已有审批中或已批准的请假")
+	}
+
+	leave := &domain.LeaveRequest{
+		StudentID:     studentID,
+		LeaveType:     req.LeaveType,
+		StartDate:     startDate,
+		EndDate:       endDate,
+		Days:          days,
+		Reason:        req.Reason,
+		Attachments:   req.Attachments,
+		Status:        domain.LeaveStatusPending,
+		ApprovalChain: s.chainFor(days),
+	}
+	if actor.AdminID != 0 {
+		submittedBy := actor.AdminID
+		leave.SubmittedBy = &submittedBy
+	}
+
+	if err := s.leaveRepo.Create(leave); err != nil {
+		return nil, err
+	}
+	return s.getLeaveWithApprovals(leave.ID)
+}
+
+// GetLeave 获取请假申请及审批记录：学生和家长只能查看本人（子女）的申请，教师只能查看本班学生的申请
+func (s *LeaveService) GetLeave(actor *domain.JWTClaims, id int) (*domain.LeaveRequest, error) {
+	leave, err := s.getLeaveWithApprovals(id)
+	if err != nil {
+		return nil, err
+	}
+
+	switch {
+	case actor.IsStudentScoped():
+		if !actor.CanAccessStudent(leave.StudentID) {
+			return nil, errors.ErrForbidden
+		}
+	case actor.Role == domain.RoleTeacher:
+		if !isHomeroomTeacher(actor, leave) {
+			return nil, errors.ErrForbidden
+		}
+	}
+	return leave, nil
+}
+
+// ListLeaves 获取请假申请列表：学生和家长只能查看本人（子女）的申请，教师只能查看本班学生的申请
+func (s *LeaveService) ListLeaves(actor *domain.JWTClaims, req domain.LeaveListRequest) ([]*domain.LeaveRequest, int64, error) {
+	switch {
+	case actor.IsStudentScoped():
+		if len(actor.StudentIDs) == 0 || (req.StudentID > 0 && !actor.CanAccessStudent(req.StudentID)) {
+			return nil, 0, errors.ErrForbidden
+		}
+		req.StudentIDs = actor.StudentIDs
+	case actor.Role == domain.RoleTeacher:
+		if actor.TeacherID == 0 {
+			return nil, 0, errors.ErrForbidden
+		}
+		req.HomeroomTeacherID = actor.TeacherID
+	}
+	req.PendingApprover = ""
+	req.Queue = false
+	return s.listLeaves(req)
+}
+
+// ListQueue 获取待当前账号审批的请假申请，按提交时间先后排列。
+// 班主任只能看到本班学生待班主任审批的申请，管理员可以看到全部审批中的申请
+func (s *LeaveService) ListQueue(actor *domain.JWTClaims, req domain.LeaveListRequest) ([]*domain.LeaveRequest, int64, error) {
+	switch actor.Role {
+	case domain.RoleAdmin:
+		req.Status = domain.LeaveStatusPending
+	case domain.RoleTeacher:
+		if actor.TeacherID == 0 {
+			return nil, 0, errors.ErrForbidden
+		}
+		req.Status = ""
+		req.HomeroomTeacherID = actor.TeacherID
+		req.PendingApprover = domain.LeaveApproverHomeroom
+	default:
+		return nil, 0, errors.ErrForbidden
+	}
+	req.Queue = true
+	return s.listLeaves(req)
+}
+
+// ApproveLeave 通过当前步骤的审批，审批链最后一步通过后申请即获批准，请假期间已记录为旷课的考勤改为请假
+func (s *LeaveService) ApproveLeave(actor *domain.JWTClaims, id int, req domain.LeaveDecisionRequest) (*domain.LeaveRequest, error) {
+	return s.decide(actor, id, domain.LeaveDecisionApproved, req.Comment)
+}
+
+// RejectLeave 驳回请假申请，须填写原因
+func (s *LeaveService) RejectLeave(actor *domain.JWTClaims, id int, req domain.LeaveDecisionRequest) (*domain.LeaveRequest, error) {
+	if req.Comment == "" {
+		return nil, errors.New(errors.ErrCodeValidation, "驳回时须填写原因")
+	}
+	return s.decide(actor, id, domain.LeaveDecisionRejected, req.Comment)
+}
+
+// CancelLeave 撤销请假申请：审批中的申请可以随时撤销，已批准的申请只能在请假开始前撤销
+func (s *LeaveService) CancelLeave(actor *domain.JWTClaims, id int) (*domain.LeaveRequest, error) {
+	logger.WithFields(map[string]interface{}{
+		"leave_id": id,
+		"admin_id": actor.AdminID,
+	}).Info("Cancelling leave request")
+
+	leave, err := s.getLeave(id)
+	if err != nil {
+		return nil, err
+	}
+	if !actor.CanAccessStudent(leave.StudentID) {
+		return nil, errors.ErrForbidden
+	}
+
+	statuses := []string{domain.LeaveStatusPending}
+	now := time.Now()
+	if leave.StartDate.After(time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC)) {
+		statuses = append(statuses, domain.LeaveStatusApproved)
+	}
+
+	cancelled, err := s.leaveRepo.Cancel(id, statuses)
+	if err != nil {
+		return nil, err
+	}
+	if !cancelled {
+		if leave.Status == domain.LeaveStatusApproved {
+			return nil, errors.New(errors.ErrCodeConflict, "请假已开始，不能撤销")
+		}
+		return nil, errors.New(errors.ErrCodeConflict, "请假申请已处理，不能撤销")
+	}
+	return s.getLeaveWithApprovals(id)
+}
+
+// decide 保存当前步骤的审批意见
+func (s *LeaveService) decide(actor *domain.JWTClaims, id int, decision, comment string) (*domain.LeaveRequest, error) {
+	logger.WithFields(map[string]interface{}{
+		"leave_id": id,
+		"decision": decision,
+		"admin_id": actor.AdminID,
+	}).Info("Deciding leave request")
+
+	leave, err := s.getLeave(id)
+	if err != nil {
+		return nil, err
+	}
+	approver := leave.PendingApprover()
+	if approver == "" {
+		return nil, errors.New(errors.ErrCodeConflict, "请假申请已处理")
+	}
+	if err := authorizeLeaveApprover(actor, leave, approver); err != nil {
+		return nil, err
+	}
+
+	approval := &domain.LeaveApproval{
+		LeaveID:  leave.ID,
+		Step:     leave.CurrentStep,
+		Approver: approver,
+		Decision: decision,
+		Comment:  comment,
+	}
+	if actor.AdminID != 0 {
+		actorID := actor.AdminID
+		approval.ActorID = &actorID
+	}
+
+	if decision == domain.LeaveDecisionRejected {
+		leave.Status = domain.LeaveStatusRejected
+	} else {
+		leave.CurrentStep++
+		if leave.CurrentStep == len(leave.ApprovalChain) {
+			leave.Status = domain.LeaveStatusApproved
+		}
+	}
+
+	decided, err := s.leaveRepo.Decide(leave, approval)
+	if err != nil {
+		logger.WithError(err).Error("Failed to decide leave request")
+		return nil, err
+	}
+	if !decided {
+		return nil, errors.New(errors.ErrCodeConflict, "请假申请已被处理，请刷新后重试")
+	}
+
+	if leave.Status == domain.LeaveStatusApproved {
+		logger.WithFields(map[string]interface{}{
+			"leave_id":         leave.ID,
+			"student_id":       leave.StudentID,
+			"excused_sessions": leave.ExcusedSessions,
+		}).Info("Leave request approved")
+	}
+	return s.getLeaveWithApprovals(id)
+}
+
+// chainFor 按请假天数匹配审批链
+func (s *LeaveService) chainFor(days int) []string {
+	for _, chain := range s.chains {
+		if chain.maxDays == 0 || days <= chain.maxDays {
+			return chain.approvers
+		}
+	}
+	return s.chains[len(s.chains)-1].approvers
+}
+
+// listLeaves 分页查询请假申请
+func (s *LeaveService) listLeaves(req domain.LeaveListRequest) ([]*domain.LeaveRequest, int64, error) {
+	if req.Page <= 0 {
+		req.Page = 1
+	}
+	if req.Size <= 0 {
+		req.Size = 10
+	}
+
+	leaves, total, err := s.leaveRepo.List(&req)
+	if err != nil {
+		logger.WithError(err).Error("Failed to list leave requests")
+		return nil, 0, err
+	}
+	if leaves == nil {
+		leaves = []*domain.LeaveRequest{}
+	}
+	return leaves, total, nil
+}
+
+// getLeave 获取请假申请，不存在时返回错误
+func (s *LeaveService) getLeave(id int) (*domain.LeaveRequest, error) {
+	leave, err := s.leaveRepo.GetByID(id)
+	if err != nil {
+		return nil, err
+	}
+	if leave == nil {
+		return nil, errors.New(errors.ErrCodeNotFound, "请假申请不存在")
+	}
+	return leave, nil
+}
+
+// getLeaveWithApprovals 获取请假申请并附带审批记录
+func (s *LeaveService) getLeaveWithApprovals(id int) (*domain.LeaveRequest, error) {
+	leave, err := s.getLeave(id)
+	if err != nil {
+		return nil, err
+	}
+
+	approvals, err := s.leaveRepo.ListApprovals(id)
+	if err != nil {
+		return nil, err
+	}
+	leave.Approvals = approvals
+	return leave, nil
+}
+
+// authorizeLeaveApprover 检查当前账号能否审批该步骤：管理员可以审批任何步骤，班主任只能审批本班学生待班主任审批的步骤
+func authorizeLeaveApprover(actor *domain.JWTClaims, leave *domain.LeaveRequest, approver string) error {
+	switch actor.Role {
+	case domain.RoleAdmin:
+		return nil
+	case domain.RoleTeacher:
+		if approver != domain.LeaveApproverHomeroom {
+			return errors.New(errors.ErrCodeForbidden, "该步骤须由管理员审批")
+		}
+		if !isHomeroomTeacher(actor, leave) {
+			return errors.New(errors.ErrCodeForbidden, "只能审批本班学生的请假")
+		}
+		return nil
+	default:
+		return errors.ErrForbidden
+	}
+}
+
+// isHomeroomTeacher 当前教师是否为请假学生所在班级的班主任
+func isHomeroomTeacher(actor *domain.JWTClaims, leave *domain.LeaveRequest) bool {
+	return actor.TeacherID > 0 && leave.HomeroomTeacherID != nil && *leave.HomeroomTeacherID == actor.TeacherID
+}
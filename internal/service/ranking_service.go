@@ -0,0 +1,233 @@
+package service
+
+import (
+	"fmt"
+	"strconv"
+	"time"
+
+	"student-management-system/internal/domain"
+	"student-management-system/internal/repository"
+	"student-management-system/pkg/errors"
+	"student-management-system/pkg/logger"
+)
+
+// RankingService 成绩排名服务。按学期在班级、专业或年级范围内对单科成绩、平均分或平均绩点排名，
+// 只统计已发布的成绩
+type RankingService struct {
+	rankingRepo repository.RankingRepository
+	classRepo   repository.ClassRepository
+	termRepo    repository.TermRepository
+	scales      *GradeScaleService
+}
+
+// NewRankingService 创建成绩排名服务实例
+func NewRankingService(rankingRepo repository.RankingRepository, classRepo repository.ClassRepository, termRepo repository.TermRepository, scales *GradeScaleService) *RankingService {
+	return &RankingService{
+		rankingRepo: rankingRepo,
+		classRepo:   classRepo,
+		termRepo:    termRepo,
+		scales:      scales,
+	}
+}
+
+// GetRanking 获取成绩排名。指定 student_id 时只返回该学生的名次；学生和家长只能查看本人（子女）的名次
+func (s *RankingService) GetRanking(actor *domain.JWTClaims, req domain.RankingRequest) (*domain.RankingResult, error) {
+	if actor.IsStudentScoped() {
+		if req.StudentID == 0 && len(actor.StudentIDs) == 1 {
+			req.StudentID = actor.StudentIDs[0]
+		}
+		if req.StudentID == 0 {
+			return nil, errors.New(errors.ErrCodeValidation, "请指定要查看名次的学生")
+		}
+		if !actor.CanAccessStudent(req.StudentID) {
+			return nil, errors.ErrForbidden
+		}
+	}
+
+	if req.Method == "" {
+		req.Method = domain.RankingMethodStandard
+	}
+	if req.By == domain.RankingBySubject && req.SubjectID == 0 {
+		return nil, errors.New(errors.ErrCodeValidation, "按科目排名时须指定科目")
+	}
+
+	term, err := resolveTerm(s.termRepo, req.Semester)
+	if err != nil {
+		return nil, err
+	}
+
+	// 学生的班级按学期结束时（学期未结束时为当天）所在的班级确定
+	referenceDate := term.EndDate
+	if today := time.Now(); today.Before(referenceDate) {
+		referenceDate = today
+	}
+	filter := &domain.RankingFilter{
+		Semester:      term.Code,
+		ReferenceDate: referenceDate,
+	}
+	if req.By == domain.RankingBySubject {
+		filter.SubjectID = req.SubjectID
+	}
+
+	result := &domain.RankingResult{
+		Semester: term.Code,
+		By:       req.By,
+		Scope:    req.Scope,
+		Method:   req.Method,
+		SelfOnly: req.StudentID > 0,
+	}
+	if err := s.applyScope(req, filter, result); err != nil {
+		return nil, err
+	}
+
+	logger.WithFields(map[string]interface{}{
+		"semester":   term.Code,
+		"by":         req.By,
+		"scope":      req.Scope,
+		"scope_name": result.ScopeName,
+		"admin_id":   actor.AdminID,
+	}).Info("Computing score ranking")
+
+	scores, err := s.rankingRepo.ListCourseScores(filter)
+	if err != nil {
+		logger.WithError(err).Error("Failed to list ranking scores")
+		return nil, err
+	}
+
+	entries, err := s.buildEntries(req, scores)
+	if err != nil {
+		return nil, err
+	}
+	domain.AssignRanks(entries, req.Method)
+
+	result.Count = len(entries)
+	if req.By == domain.RankingBySubject {
+		result.SubjectID = req.SubjectID
+		if len(scores) > 0 {
+			result.SubjectName = scores[0].SubjectName
+		}
+	}
+
+	if req.StudentID == 0 {
+		result.Entries = entries
+		return result, nil
+	}
+	for _, entry := range entries {
+		if entry.StudentID == req.StudentID {
+			result.Entries = []domain.RankingEntry{entry}
+			return result, nil
+		}
+	}
+	return nil, errors.New(errors.ErrCodeNotFound, "该学生在此范围内没有可排名的已发布成绩")
+}
+
+// applyScope 校验排名范围参数并设置查询条件
+func (s *RankingService) applyScope(req domain.RankingRequest, filter *domain.RankingFilter, result *domain.RankingResult) error {
+	switch req.Scope {
+	case domain.RankingScopeClass:
+		if req.ClassID == 0 {
+			return errors.New(errors.ErrCodeValidation, "按班级排名时须指定班级")
+		}
+		class, err := s.classRepo.GetByID(req.ClassID)
+		if err != nil {
+			return err
+		}
+		if class == nil {
+			return errors.New(errors.ErrCodeNotFound, "班级不存在")
+		}
+		filter.ClassID = class.ID
+		result.ScopeName = class.Name
+	case domain.RankingScopeMajor:
+		if req.Major == "" {
+			return errors.New(errors.ErrCodeValidation, "按专业排名时须指定专业")
+		}
+		filter.Major = req.Major
+		filter.GradeYear = req.GradeYear
+		result.ScopeName = req.Major
+		if req.GradeYear > 0 {
+			result.ScopeName = fmt.Sprintf("%d级%s", req.GradeYear, req.Major)
+		}
+	case domain.RankingScopeGrade:
+		if req.GradeYear == 0 {
+			return errors.New(errors.ErrCodeValidation, "按年级排名时须指定年级")
+		}
+		filter.GradeYear = req.GradeYear
+		result.ScopeName = strconv.Itoa(req.GradeYear) + "级"
+	}
+	return nil
+}
+
+// buildEntries 按学生汇总课程成绩，计算参与排名的数值
+func (s *RankingService) buildEntries(req domain.RankingRequest, scores []*domain.RankingCourseScore) ([]domain.RankingEntry, error) {
+	type studentScores struct {
+		entry   domain.RankingEntry
+		courses []*domain.RankingCourseScore
+	}
+
+	students := make(map[int]*studentScores)
+	var order []int
+	for _, sc := range scores {
+		st, ok := students[sc.StudentID]
+		if !ok {
+			st = &studentScores{entry: domain.RankingEntry{
+				StudentID:   sc.StudentID,
+				StudentNo:   sc.StudentNo,
+				StudentName: sc.StudentName,
+				Major:       sc.Major,
+				ClassID:     sc.ClassID,
+				ClassName:   sc.ClassName,
+			}}
+			students[sc.StudentID] = st
+			order = append(order, sc.StudentID)
+		}
+		st.courses = append(st.courses, sc)
+	}
+
+	// 同一专业的学生使用相同的绩点制
+	scaleByMajor := make(map[string]*domain.GradeScale)
+	entries := make([]domain.RankingEntry, 0, len(order))
+	for _, id := range order {
+		st := students[id]
+		entry := st.entry
+		entry.Courses = len(st.courses)
+
+		switch req.By {
+		case domain.RankingBySubject:
+			entry.Value = st.courses[0].Score
+		case domain.RankingByComposite:
+			var total float64
+			for _, c := range st.courses {
+				total += c.Score
+			}
+			total = round2(total)
+			entry.Total = &total
+			entry.Value = round2(total / float64(len(st.courses)))
+		case domain.RankingByGPA:
+			scale, ok := scaleByMajor[entry.Major]
+			if !ok {
+				var err error
+				if scale, err = s.scales.ResolveForMajor(entry.Major, req.Scale); err != nil {
+					return nil, err
+				}
+				scaleByMajor[entry.Major] = scale
+			}
+
+			var gpa domain.GPAAccumulator
+			for _, c := range st.courses {
+				point := scale.Grade(c.Score).GradePoint
+				gpa.Add(c.Credits, point)
+				if point != nil {
+					entry.Credits += c.Credits
+				}
+			}
+			// 所有课程均不计绩点的学生不参与绩点排名
+			if entry.Credits == 0 {
+				continue
+			}
+			entry.Value = gpa.GPA()
+			entry.GradeScale = scale.Name
+		}
+		entries = append(entries, entry)
+	}
+	return entries, nil
+}
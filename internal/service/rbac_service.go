@@ -0,0 +1,128 @@
+package service
+
+import (
+	"fmt"
+	"sync"
+	"time"
+
+	"student-management-system/internal/domain"
+	"student-management-system/internal/repository"
+	"student-management-system/pkg/logger"
+)
+
+// permissionCacheTTL 角色权限缓存时间，修改角色权限后会立即失效
+const permissionCacheTTL = time.Minute
+
+// RBACService 角色权限服务
+type RBACService struct {
+	roleRepo repository.RoleRepository
+
+	mu          sync.RWMutex
+	permissions map[string]map[string]bool
+	loadedAt    time.Time
+}
+
+// NewRBACService 创建角色权限服务实例
+func NewRBACService(roleRepo repository.RoleRepository) *RBACService {
+	return &RBACService{
+		roleRepo: roleRepo,
+	}
+}
+
+// HasPermission 检查角色是否拥有指定权限
+func (s *RBACService) HasPermission(role, permission string) (bool, error) {
+	s.mu.RLock()
+	fresh := s.permissions != nil && time.Since(s.loadedAt) < permissionCacheTTL
+	if fresh {
+		allowed := s.permissions[role][permission]
+		s.mu.RUnlock()
+		return allowed, nil
+	}
+	s.mu.RUnlock()
+
+	if err := s.reload(); err != nil {
+		return false, err
+	}
+
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.permissions[role][permission], nil
+}
+
+// reload 从数据库重新加载角色权限
+func (s *RBACService) reload() error {
+	rolePermissions, err := s.roleRepo.GetRolePermissions()
+	if err != nil {
+		logger.WithError(err).Error("Failed to load role permissions")
+		return fmt.Errorf("加载角色权限失败: %v", err)
+	}
+
+	permissions := make(map[string]map[string]bool, len(rolePermissions))
+	for role, codes := range rolePermissions {
+		permissions[role] = make(map[string]bool, len(codes))
+		for _, code := range codes {
+			permissions[role][code] = true
+		}
+	}
+
+	s.mu.Lock()
+	s.permissions = permissions
+	s.loadedAt = time.Now()
+	s.mu.Unlock()
+
+	logger.WithFields(map[string]interface{}{
+		"roles": len(permissions),
+	}).Debug("Role permissions reloaded")
+	return nil
+}
+
+// ListRoles 获取所有角色及其权限
+func (s *RBACService) ListRoles() ([]*domain.Role, error) {
+	roles, err := s.roleRepo.ListRoles()
+	if err != nil {
+		return nil, fmt.Errorf("获取角色列表失败: %v", err)
+	}
+	return roles, nil
+}
+
+// ListPermissions 获取所有权限
+func (s *RBACService) ListPermissions() ([]*domain.Permission, error) {
+	permissions, err := s.roleRepo.ListPermissions()
+	if err != nil {
+		return nil, fmt.Errorf("获取权限列表失败: %v", err)
+	}
+	return permissions, nil
+}
+
+// UpdateRolePermissions 替换角色的全部权限
+func (s *RBACService) UpdateRolePermissions(role string, req *domain.UpdateRolePermissionsRequest) error {
+	// 去重，避免重复权限代码影响校验
+	seen := make(map[string]bool, len(req.Permissions))
+	permissions := make([]string, 0, len(req.Permissions))
+	for _, code := range req.Permissions {
+		if !seen[code] {
+			seen[code] = true
+			permissions = append(permissions, code)
+		}
+	}
+
+	// 防止管理员误操作导致无人可以管理角色
+	if role == domain.RoleAdmin && !seen[domain.PermAdminsWrite] {
+		return fmt.Errorf("admin 角色必须保留 %s 权限", domain.PermAdminsWrite)
+	}
+
+	if err := s.roleRepo.SetRolePermissions(role, permissions); err != nil {
+		return fmt.Errorf("更新角色权限失败: %v", err)
+	}
+
+	// 使缓存失效
+	s.mu.Lock()
+	s.permissions = nil
+	s.mu.Unlock()
+
+	logger.WithFields(map[string]interface{}{
+		"role":        role,
+		"permissions": permissions,
+	}).Info("Role permissions updated")
+	return nil
+}
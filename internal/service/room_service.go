@@ -0,0 +1,163 @@
+package service
+
+import (
+	"student-management-system/internal/domain"
+	"student-management-system/internal/repository"
+	"student-management-system/pkg/errors"
+	"student-management-system/pkg/logger"
+)
+
+// RoomService 教室服务
+type RoomService struct {
+	roomRepo repository.RoomRepository
+}
+
+// NewRoomService 创建教室服务实例
+func NewRoomService(roomRepo repository.RoomRepository) *RoomService {
+	return &RoomService{roomRepo: roomRepo}
+}
+
+// CreateRoom 创建教室
+func (s *RoomService) CreateRoom(req domain.CreateRoomRequest) (*domain.Room, error) {
+	logger.WithFields(map[string]interface{}{
+		"code": req.Code,
+	}).Info("Creating room")
+
+	room := &domain.Room{
+		Code:     req.Code,
+		Name:     req.Name,
+		Building: req.Building,
+		RoomType: req.RoomType,
+		Capacity: req.Capacity,
+		Status:   req.Status,
+	}
+	if room.Name == "" {
+		room.Name = room.Code
+	}
+	if room.RoomType == "" {
+		room.RoomType = domain.RoomTypeClassroom
+	}
+	if room.Status == "" {
+		room.Status = domain.RoomStatusActive
+	}
+
+	if err := s.checkCode(room); err != nil {
+		return nil, err
+	}
+
+	if err := s.roomRepo.Create(room); err != nil {
+		return nil, err
+	}
+
+	logger.WithFields(map[string]interface{}{
+		"room_id": room.ID,
+		"code":    room.Code,
+	}).Info("Room created successfully")
+
+	return room, nil
+}
+
+// GetRoom 获取教室
+func (s *RoomService) GetRoom(id int) (*domain.Room, error) {
+	room, err := s.roomRepo.GetByID(id)
+	if err != nil {
+		logger.WithError(err).WithFields(map[string]interface{}{
+			"room_id": id,
+		}).Error("Failed to get room")
+		return nil, err
+	}
+	if room == nil {
+		return nil, errors.New(errors.ErrCodeNotFound, "教室不存在")
+	}
+	return room, nil
+}
+
+// ListRooms 获取教室列表（分页）
+func (s *RoomService) ListRooms(req domain.RoomListRequest) ([]*domain.Room, int64, error) {
+	if req.Page <= 0 {
+		req.Page = 1
+	}
+	if req.Size <= 0 {
+		req.Size = 10
+	}
+
+	rooms, total, err := s.roomRepo.List(&req)
+	if err != nil {
+		logger.WithError(err).Error("Failed to list rooms")
+		return nil, 0, err
+	}
+	return rooms, total, nil
+}
+
+// UpdateRoom 更新教室。停用或缩小容量不影响已排的课表，只对之后的排课生效
+func (s *RoomService) UpdateRoom(id int, req domain.UpdateRoomRequest) (*domain.Room, error) {
+	logger.WithFields(map[string]interface{}{
+		"room_id": id,
+	}).Info("Updating room")
+
+	room, err := s.GetRoom(id)
+	if err != nil {
+		return nil, err
+	}
+
+	if req.Code != "" {
+		room.Code = req.Code
+	}
+	if req.Name != "" {
+		room.Name = req.Name
+	}
+	if req.Building != nil {
+		room.Building = *req.Building
+	}
+	if req.RoomType != "" {
+		room.RoomType = req.RoomType
+	}
+	if req.Capacity > 0 {
+		room.Capacity = req.Capacity
+	}
+	if req.Status != "" {
+		room.Status = req.Status
+	}
+
+	if err := s.checkCode(room); err != nil {
+		return nil, err
+	}
+
+	if err := s.roomRepo.Update(room); err != nil {
+		return nil, err
+	}
+	return room, nil
+}
+
+// DeleteRoom 删除教室，已排课的教室只能停用
+func (s *RoomService) DeleteRoom(id int) error {
+	logger.WithFields(map[string]interface{}{
+		"room_id": id,
+	}).Info("Deleting room")
+
+	if _, err := s.GetRoom(id); err != nil {
+		return err
+	}
+
+	used, err := s.roomRepo.HasSlots(id)
+	if err != nil {
+		return err
+	}
+	if used {
+		return errors.New(errors.ErrCodeConflict, "教室已排课，不能删除，请将教室状态改为 inactive")
+	}
+
+	return s.roomRepo.Delete(id)
+}
+
+// checkCode 校验教室编号唯一
+func (s *RoomService) checkCode(room *domain.Room) error {
+	exists, err := s.roomRepo.ExistsByCode(room.Code, room.ID)
+	if err != nil {
+		return err
+	}
+	if exists {
+		return errors.Newf(errors.ErrCodeConflict, "教室编号 %s 已存在", room.Code)
+	}
+	return nil
+}
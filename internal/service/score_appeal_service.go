@@ -0,0 +1,312 @@
+package service
+
+import (
+	"fmt"
+
+	"student-management-system/internal/domain"
+	"student-management-system/internal/repository"
+	"student-management-system/pkg/errors"
+	"student-management-system/pkg/logger"
+)
+
+// ScoreAppealService 成绩复核服务。学生或家长对已发布的成绩提出复核，由负责的教师或管理员受理并给出结论
+type ScoreAppealService struct {
+	appealRepo   repository.ScoreAppealRepository
+	scoreRepo    repository.ScoreRepository
+	offeringRepo repository.CourseOfferingRepository
+	termRepo     repository.TermRepository
+	scores       ScoreService
+}
+
+// NewScoreAppealService 创建成绩复核服务实例
+func NewScoreAppealService(appealRepo repository.ScoreAppealRepository, scoreRepo repository.ScoreRepository, offeringRepo repository.CourseOfferingRepository, termRepo repository.TermRepository, scores ScoreService) *ScoreAppealService {
+	return &ScoreAppealService{
+		appealRepo:   appealRepo,
+		scoreRepo:    scoreRepo,
+		offeringRepo: offeringRepo,
+		termRepo:     termRepo,
+		scores:       scores,
+	}
+}
+
+// SubmitAppeal 为已发布的成绩提交复核申请，同一成绩同时只能有一条未结案的申请
+func (s *ScoreAppealService) SubmitAppeal(actor *domain.JWTClaims, scoreID int, req domain.CreateScoreAppealRequest) (*domain.ScoreAppeal, error) {
+	logger.WithFields(map[string]interface{}{
+		"score_id": scoreID,
+		"admin_id": actor.AdminID,
+	}).Info("Submitting score appeal")
+
+	score, err := s.scoreRepo.GetByID(scoreID)
+	if err != nil {
+		logger.WithError(err).Error("Failed to get score for appeal")
+		return nil, errors.New(errors.ErrCodeNotFound, "成绩不存在")
+	}
+	if !actor.CanAccessStudent(score.StudentID) {
+		return nil, errors.ErrForbidden
+	}
+	if !domain.IsPublishedScoreStatus(score.Status) {
+		if actor.IsStudentScoped() {
+			return nil, errors.New(errors.ErrCodeNotFound, "成绩不存在")
+		}
+		return nil, errors.New(errors.ErrCodeConflict, "只能对已发布的成绩申请复核")
+	}
+
+	term, err := resolveTerm(s.termRepo, score.Semester)
+	if err != nil {
+		return nil, err
+	}
+	if term.Status == domain.TermStatusClosed {
+		return nil, errors.Newf(errors.ErrCodeConflict, "学期 %s 已结束，成绩已冻结，不能申请复核", term.Code)
+	}
+
+	open, err := s.appealRepo.HasOpenAppeal(scoreID)
+	if err != nil {
+		return nil, err
+	}
+	if open {
+		return nil, errors.New(errors.ErrCodeConflict, "该成绩已有正在处理的复核申请")
+	}
+
+	appeal := &domain.ScoreAppeal{
+		ScoreID:       score.ID,
+		StudentID:     score.StudentID,
+		Reason:        req.Reason,
+		Attachments:   req.Attachments,
+		Status:        domain.AppealStatusSubmitted,
+		OriginalScore: score.Score,
+		StudentName:   score.StudentName,
+		SubjectID:     score.SubjectID,
+		SubjectName:   score.SubjectName,
+		Semester:      score.Semester,
+		ExamType:      score.ExamType,
+	}
+	if appeal.AssignedTeacherID, err = s.assignTeacher(score); err != nil {
+		return nil, err
+	}
+	if actor.AdminID != 0 {
+		submittedBy := actor.AdminID
+		appeal.SubmittedBy = &submittedBy
+	}
+
+	if err := s.appealRepo.Create(appeal); err != nil {
+		return nil, err
+	}
+	return appeal, nil
+}
+
+// GetAppeal 获取成绩复核申请
+func (s *ScoreAppealService) GetAppeal(actor *domain.JWTClaims, id int) (*domain.ScoreAppeal, error) {
+	appeal, err := s.getAppeal(id)
+	if err != nil {
+		return nil, err
+	}
+
+	switch {
+	case actor.IsStudentScoped():
+		if !actor.CanAccessStudent(appeal.StudentID) {
+			return nil, errors.ErrForbidden
+		}
+	case actor.Role == domain.RoleTeacher:
+		if !isAssignedTeacher(actor, appeal) {
+			return nil, errors.ErrForbidden
+		}
+	}
+	return appeal, nil
+}
+
+// ListAppeals 获取成绩复核申请列表：学生和家长只能查看本人（子女）的申请，教师只能查看分配给本人的申请
+func (s *ScoreAppealService) ListAppeals(actor *domain.JWTClaims, req domain.ScoreAppealListRequest) ([]*domain.ScoreAppeal, int64, error) {
+	switch {
+	case actor.IsStudentScoped():
+		if len(actor.StudentIDs) == 0 || (req.StudentID > 0 && !actor.CanAccessStudent(req.StudentID)) {
+			return nil, 0, errors.ErrForbidden
+		}
+		req.StudentIDs = actor.StudentIDs
+	case actor.Role == domain.RoleTeacher:
+		req.AssignedTeacherID = actor.TeacherID
+	}
+	return s.listAppeals(req)
+}
+
+// ListQueue 获取待处理的复核申请（已提交和复核中），按提交时间先后排列。教师只能看到分配给本人的申请
+func (s *ScoreAppealService) ListQueue(actor *domain.JWTClaims, req domain.ScoreAppealListRequest) ([]*domain.ScoreAppeal, int64, error) {
+	if actor.IsStudentScoped() {
+		return nil, 0, errors.ErrForbidden
+	}
+	if actor.Role == domain.RoleTeacher {
+		req.AssignedTeacherID = actor.TeacherID
+	}
+	req.Status = ""
+	req.Statuses = domain.OpenAppealStatuses
+	return s.listAppeals(req)
+}
+
+// StartReview 受理复核申请，状态变为复核中
+func (s *ScoreAppealService) StartReview(actor *domain.JWTClaims, id int) (*domain.ScoreAppeal, error) {
+	logger.WithFields(map[string]interface{}{
+		"appeal_id": id,
+		"admin_id":  actor.AdminID,
+	}).Info("Starting score appeal review")
+
+	appeal, err := s.getReviewableAppeal(actor, id)
+	if err != nil {
+		return nil, err
+	}
+	if appeal.Status != domain.AppealStatusSubmitted {
+		return nil, errors.New(errors.ErrCodeConflict, "复核申请已受理或已结案")
+	}
+
+	started, err := s.appealRepo.StartReview(id, actor.AdminID)
+	if err != nil {
+		return nil, err
+	}
+	if !started {
+		return nil, errors.New(errors.ErrCodeConflict, "复核申请已受理或已结案")
+	}
+	return s.getAppeal(id)
+}
+
+// ResolveAppeal 给出复核结论。调整成绩时通过成绩调整流程与结案在同一事务中修改，成绩变更记录中可查
+func (s *ScoreAppealService) ResolveAppeal(actor *domain.JWTClaims, id int, req domain.ResolveScoreAppealRequest, meta domain.ScoreChangeMeta) (*domain.ScoreAppeal, error) {
+	logger.WithFields(map[string]interface{}{
+		"appeal_id": id,
+		"result":    req.Result,
+		"admin_id":  actor.AdminID,
+	}).Info("Resolving score appeal")
+
+	appeal, err := s.getReviewableAppeal(actor, id)
+	if err != nil {
+		return nil, err
+	}
+	if appeal.Status != domain.AppealStatusUnderReview {
+		if appeal.Status == domain.AppealStatusSubmitted {
+			return nil, errors.New(errors.ErrCodeConflict, "复核申请尚未受理")
+		}
+		return nil, errors.New(errors.ErrCodeConflict, "复核申请已结案")
+	}
+
+	appeal.Status = req.Result
+	appeal.Resolution = req.Resolution
+	appeal.AdjustedScore = nil
+	if actor.AdminID != 0 {
+		reviewerID := actor.AdminID
+		appeal.ReviewerID = &reviewerID
+	}
+
+	if req.Result != domain.AppealStatusAdjusted {
+		if req.AdjustedScore != nil {
+			return nil, errors.New(errors.ErrCodeValidation, "维持原成绩时不能填写调整后的分数")
+		}
+		resolved, err := s.appealRepo.Resolve(appeal, nil, nil)
+		if err != nil {
+			logger.WithError(err).Error("Failed to resolve score appeal")
+			return nil, err
+		}
+		if !resolved {
+			return nil, errors.New(errors.ErrCodeConflict, "复核申请已结案")
+		}
+		return s.getAppeal(id)
+	}
+
+	if req.AdjustedScore == nil {
+		return nil, errors.New(errors.ErrCodeValidation, "调整成绩时须填写调整后的分数")
+	}
+	if *req.AdjustedScore == appeal.OriginalScore {
+		return nil, errors.New(errors.ErrCodeValidation, "调整后的分数与原成绩相同，请选择维持原成绩")
+	}
+	appeal.AdjustedScore = req.AdjustedScore
+
+	// 通过成绩调整流程修改成绩，结案与成绩修改在同一事务中提交
+	meta.Reason = fmt.Sprintf("成绩复核 #%d：%s", appeal.ID, req.Resolution)
+	_, err = s.scores.AdjustScore(actor, appeal.ScoreID, *req.AdjustedScore, meta, func(score *domain.Score, history *domain.ScoreHistory) error {
+		resolved, err := s.appealRepo.Resolve(appeal, score, history)
+		if err != nil {
+			return err
+		}
+		if !resolved {
+			return errors.New(errors.ErrCodeConflict, "复核申请已结案或成绩已被修改，请刷新后重试")
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return s.getAppeal(id)
+}
+
+// listAppeals 分页查询复核申请
+func (s *ScoreAppealService) listAppeals(req domain.ScoreAppealListRequest) ([]*domain.ScoreAppeal, int64, error) {
+	if req.Page <= 0 {
+		req.Page = 1
+	}
+	if req.Size <= 0 {
+		req.Size = 10
+	}
+
+	appeals, total, err := s.appealRepo.List(&req)
+	if err != nil {
+		logger.WithError(err).Error("Failed to list score appeals")
+		return nil, 0, err
+	}
+	if appeals == nil {
+		appeals = []*domain.ScoreAppeal{}
+	}
+	return appeals, total, nil
+}
+
+// getAppeal 获取复核申请，不存在时返回错误
+func (s *ScoreAppealService) getAppeal(id int) (*domain.ScoreAppeal, error) {
+	appeal, err := s.appealRepo.GetByID(id)
+	if err != nil {
+		return nil, err
+	}
+	if appeal == nil {
+		return nil, errors.New(errors.ErrCodeNotFound, "复核申请不存在")
+	}
+	return appeal, nil
+}
+
+// getReviewableAppeal 获取当前账号可以审理的复核申请：管理员可以审理全部申请，教师只能审理分配给本人的申请
+func (s *ScoreAppealService) getReviewableAppeal(actor *domain.JWTClaims, id int) (*domain.ScoreAppeal, error) {
+	appeal, err := s.getAppeal(id)
+	if err != nil {
+		return nil, err
+	}
+
+	switch actor.Role {
+	case domain.RoleAdmin:
+		return appeal, nil
+	case domain.RoleTeacher:
+		if isAssignedTeacher(actor, appeal) {
+			return appeal, nil
+		}
+		return nil, errors.New(errors.ErrCodeForbidden, "只能审理分配给本人的复核申请")
+	default:
+		return nil, errors.ErrForbidden
+	}
+}
+
+// assignTeacher 确定负责复核的教师：优先为录入成绩的教师，其次为开课的任课教师
+func (s *ScoreAppealService) assignTeacher(score *domain.Score) (*int, error) {
+	if score.TeacherID > 0 {
+		teacherID := score.TeacherID
+		return &teacherID, nil
+	}
+	if score.OfferingID == 0 {
+		return nil, nil
+	}
+
+	offering, err := s.offeringRepo.GetByID(score.OfferingID)
+	if err != nil {
+		return nil, err
+	}
+	if offering == nil {
+		return nil, nil
+	}
+	return offering.TeacherID, nil
+}
+
+// isAssignedTeacher 复核申请是否分配给当前教师
+func isAssignedTeacher(actor *domain.JWTClaims, appeal *domain.ScoreAppeal) bool {
+	return actor.TeacherID > 0 && appeal.AssignedTeacherID != nil && *appeal.AssignedTeacherID == actor.TeacherID
+}
@@ -0,0 +1,113 @@
+package service
+
+import (
+	"fmt"
+	"strings"
+
+	"student-management-system/internal/domain"
+	"student-management-system/pkg/errors"
+	"student-management-system/pkg/logger"
+)
+
+// GetScoreHistory 获取成绩的变更记录，成绩删除后仍可查询。学生和家长无权查看，教师只能查看本人任教科目的记录
+func (s *scoreService) GetScoreHistory(actor *domain.JWTClaims, scoreID int, req *domain.ScoreHistoryListRequest) ([]*domain.ScoreHistory, int64, error) {
+	logger.Info("Getting score history", "score_id", scoreID)
+
+	if actor.IsStudentScoped() {
+		return nil, 0, errors.ErrForbidden
+	}
+
+	subjectID, err := s.historyRepo.GetScoreSubject(scoreID)
+	if err != nil {
+		return nil, 0, err
+	}
+	if subjectID == 0 {
+		return nil, 0, errors.New(errors.ErrCodeNotFound, "成绩不存在或没有变更记录")
+	}
+	if actor.Role == domain.RoleTeacher {
+		if err := s.authorizeWrite(actor, subjectID); err != nil {
+			return nil, 0, err
+		}
+	}
+
+	req.ScoreID = scoreID
+	req.StudentID = 0
+	return s.listScoreHistory(req)
+}
+
+// GetStudentScoreHistory 获取学生全部成绩的变更记录。学生和家长无权查看，教师只能查看本人任教科目的记录
+func (s *scoreService) GetStudentScoreHistory(actor *domain.JWTClaims, studentID int, req *domain.ScoreHistoryListRequest) ([]*domain.ScoreHistory, int64, error) {
+	logger.Info("Getting student score history", "student_id", studentID)
+
+	if actor.IsStudentScoped() {
+		return nil, 0, errors.ErrForbidden
+	}
+
+	if actor.Role == domain.RoleTeacher {
+		subjectID, err := s.scoreRepo.GetTeacherSubjectID(actor.TeacherID)
+		if err != nil {
+			logger.Error("Failed to get teacher subject", "teacher_id", actor.TeacherID, "error", err)
+			return nil, 0, err
+		}
+		if subjectID == 0 || (req.SubjectID > 0 && req.SubjectID != subjectID) {
+			return nil, 0, errors.New(errors.ErrCodeForbidden, "只能查看本人任教科目的成绩变更记录")
+		}
+		req.SubjectID = subjectID
+	}
+
+	req.StudentID = studentID
+	req.ScoreID = 0
+	req.Semester = domain.NormalizeTermCode(req.Semester)
+	return s.listScoreHistory(req)
+}
+
+// listScoreHistory 分页查询成绩变更记录
+func (s *scoreService) listScoreHistory(req *domain.ScoreHistoryListRequest) ([]*domain.ScoreHistory, int64, error) {
+	if req.Page <= 0 {
+		req.Page = 1
+	}
+	if req.Size <= 0 {
+		req.Size = 10
+	}
+
+	history, total, err := s.historyRepo.List(req)
+	if err != nil {
+		logger.Error("Failed to list score history", "error", err)
+		return nil, 0, err
+	}
+	return history, total, nil
+}
+
+// newScoreHistory 根据登录身份和请求信息生成成绩变更记录
+func newScoreHistory(actor *domain.JWTClaims, meta domain.ScoreChangeMeta, action string, score *domain.Score) *domain.ScoreHistory {
+	history := &domain.ScoreHistory{
+		ScoreID:   score.ID,
+		StudentID: score.StudentID,
+		SubjectID: score.SubjectID,
+		Semester:  score.Semester,
+		ExamType:  score.ExamType,
+		Action:    action,
+		Reason:    meta.Reason,
+		IP:        meta.IP,
+	}
+	if actor.AdminID != 0 {
+		actorID := actor.AdminID
+		history.ActorID = &actorID
+	}
+	return history
+}
+
+// describeScoreChange 描述分数以外的字段变化
+func describeScoreChange(old, updated *domain.Score) string {
+	var changes []string
+	if old.Semester != updated.Semester {
+		changes = append(changes, fmt.Sprintf("学期 %s → %s", old.Semester, updated.Semester))
+	}
+	if old.ExamType != updated.ExamType {
+		changes = append(changes, fmt.Sprintf("考试类型 %s → %s", old.ExamType, updated.ExamType))
+	}
+	if old.Remarks != updated.Remarks {
+		changes = append(changes, "备注已修改")
+	}
+	return strings.Join(changes, "；")
+}
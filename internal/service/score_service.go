@@ -3,54 +3,129 @@ package service
 import (
 	"student-management-system/internal/domain"
 	"student-management-system/internal/repository"
+	"student-management-system/pkg/errors"
 	"student-management-system/pkg/logger"
 )
 
 // ScoreService 成绩服务接口
 type ScoreService interface {
-	CreateScore(req *domain.CreateScoreRequest) (*domain.Score, error)
-	GetScoreByID(id int) (*domain.Score, error)
-	UpdateScore(id int, req *domain.UpdateScoreRequest) (*domain.Score, error)
-	DeleteScore(id int) error
-	ListScores(req *domain.ScoreListRequest) ([]*domain.Score, int64, error)
+	CreateScore(actor *domain.JWTClaims, req *domain.CreateScoreRequest, meta domain.ScoreChangeMeta) (*domain.Score, error)
+	GetScoreByID(actor *domain.JWTClaims, id int) (*domain.Score, error)
+	UpdateScore(actor *domain.JWTClaims, id int, req *domain.UpdateScoreRequest, meta domain.ScoreChangeMeta) (*domain.Score, error)
+	DeleteScore(actor *domain.JWTClaims, id int, meta domain.ScoreChangeMeta) error
+	AdjustScore(actor *domain.JWTClaims, id int, value float64, meta domain.ScoreChangeMeta, save ScoreSaveFunc) (*domain.Score, error)
+	ListScores(actor *domain.JWTClaims, req *domain.ScoreListRequest) ([]*domain.Score, int64, error)
+	CountScores(actor *domain.JWTClaims, req *domain.ScoreListRequest) (int64, error)
+	EachScore(actor *domain.JWTClaims, req *domain.ScoreListRequest, fn func(*domain.Score) error) error
+	GetStudentReport(actor *domain.JWTClaims, studentID int, req *domain.StudentScoreReportRequest) (*domain.StudentScoreReport, error)
+	GetSubjectStatistics(subjectID int, req *domain.ScoreStatisticsRequest) (*domain.SubjectScoreStatistics, error)
+	GetClassStatistics(req *domain.ScoreStatisticsRequest) ([]*domain.ClassScoreStatistics, error)
+
+	// 成绩发布流程
+	TransitionOfferingScores(actor *domain.JWTClaims, offeringID int, action string, req *domain.ScoreWorkflowRequest) (*domain.ScoreWorkflowResult, error)
+	GetOfferingScoreStatus(actor *domain.JWTClaims, offeringID int) (*domain.OfferingScoreStatus, error)
+	RequestGradeChange(actor *domain.JWTClaims, scoreID int, req *domain.CreateGradeChangeRequest) (*domain.GradeChangeRequest, error)
+	ListGradeChangeRequests(actor *domain.JWTClaims, req *domain.GradeChangeListRequest) ([]*domain.GradeChangeRequest, int64, error)
+	ReviewGradeChange(actor *domain.JWTClaims, id int, approve bool, req *domain.ReviewGradeChangeRequest, meta domain.ScoreChangeMeta) (*domain.GradeChangeRequest, error)
+
+	// 成绩变更记录
+	GetScoreHistory(actor *domain.JWTClaims, scoreID int, req *domain.ScoreHistoryListRequest) ([]*domain.ScoreHistory, int64, error)
+	GetStudentScoreHistory(actor *domain.JWTClaims, studentID int, req *domain.ScoreHistoryListRequest) ([]*domain.ScoreHistory, int64, error)
 }
 
+// ScoreSaveFunc 保存修改后的成绩及其变更记录。调整成绩的调用方可借此把成绩与自身的修改放在同一事务中提交
+type ScoreSaveFunc func(score *domain.Score, history *domain.ScoreHistory) error
+
 // scoreService 成绩服务实现
 type scoreService struct {
-	scoreRepo repository.ScoreRepository
+	scoreRepo    repository.ScoreRepository
+	workflowRepo repository.ScoreWorkflowRepository
+	historyRepo  repository.ScoreHistoryRepository
+	offeringRepo repository.CourseOfferingRepository
+	termRepo     repository.TermRepository
+	grading      *GradingService
+	scales       *GradeScaleService
+	attendance   *AttendanceService
+	studentRepo  repository.StudentRepository
 }
 
 // NewScoreService 创建成绩服务实例
-func NewScoreService(scoreRepo repository.ScoreRepository) ScoreService {
+func NewScoreService(scoreRepo repository.ScoreRepository, workflowRepo repository.ScoreWorkflowRepository, historyRepo repository.ScoreHistoryRepository, offeringRepo repository.CourseOfferingRepository, termRepo repository.TermRepository, grading *GradingService, scales *GradeScaleService, attendance *AttendanceService, studentRepo repository.StudentRepository) ScoreService {
 	return &scoreService{
-		scoreRepo: scoreRepo,
+		scoreRepo:    scoreRepo,
+		workflowRepo: workflowRepo,
+		historyRepo:  historyRepo,
+		offeringRepo: offeringRepo,
+		termRepo:     termRepo,
+		grading:      grading,
+		scales:       scales,
+		attendance:   attendance,
+		studentRepo:  studentRepo,
 	}
 }
 
 // CreateScore 创建成绩
-func (s *scoreService) CreateScore(req *domain.CreateScoreRequest) (*domain.Score, error) {
+func (s *scoreService) CreateScore(actor *domain.JWTClaims, req *domain.CreateScoreRequest, meta domain.ScoreChangeMeta) (*domain.Score, error) {
 	logger.Info("Creating score", "student_id", req.StudentID, "subject_id", req.SubjectID)
 
+	if err := s.authorizeWrite(actor, req.SubjectID); err != nil {
+		return nil, err
+	}
+
+	if err := s.checkStudentActive(req.StudentID); err != nil {
+		return nil, err
+	}
+
+	term, err := s.writableTerm(req.Semester)
+	if err != nil {
+		return nil, err
+	}
+
+	offeringID, err := s.resolveOffering(req.StudentID, req.SubjectID, term.Code, req.OfferingID)
+	if err != nil {
+		return nil, err
+	}
+
+	// 缺勤达到取消考试资格标准的学生不能录入期末成绩
+	if req.ExamType == domain.ExamTypeFinal {
+		if err := s.attendance.CheckExamEligibility(req.StudentID, offeringID); err != nil {
+			return nil, err
+		}
+	}
+
 	score := &domain.Score{
-		StudentID: req.StudentID,
-		SubjectID: req.SubjectID,
-		Score:     req.Score,
-		Semester:  req.Semester,
-		ExamType:  req.ExamType,
+		StudentID:  req.StudentID,
+		SubjectID:  req.SubjectID,
+		TeacherID:  req.TeacherID,
+		OfferingID: offeringID,
+		Score:      req.Score,
+		Semester:   term.Code,
+		ExamType:   req.ExamType,
+		Remarks:    req.Remarks,
+		Status:     domain.ScoreStatusDraft,
+	}
+
+	// 教师录入的成绩始终记在本人名下
+	if actor.Role == domain.RoleTeacher {
+		score.TeacherID = actor.TeacherID
 	}
 
-	err := s.scoreRepo.Create(score)
+	history := newScoreHistory(actor, meta, domain.ScoreHistoryCreate, score)
+	history.NewScore = &score.Score
+
+	err = s.scoreRepo.Create(score, history)
 	if err != nil {
 		logger.Error("Failed to create score", "error", err)
 		return nil, err
 	}
 
 	logger.Info("Score created successfully", "score_id", score.ID)
+	s.recomputeFinalGrade(score.StudentID, score.SubjectID, score.Semester)
 	return score, nil
 }
 
 // GetScoreByID 根据ID获取成绩
-func (s *scoreService) GetScoreByID(id int) (*domain.Score, error) {
+func (s *scoreService) GetScoreByID(actor *domain.JWTClaims, id int) (*domain.Score, error) {
 	logger.Info("Getting score by ID", "score_id", id)
 
 	score, err := s.scoreRepo.GetByID(id)
@@ -59,11 +134,21 @@ func (s *scoreService) GetScoreByID(id int) (*domain.Score, error) {
 		return nil, err
 	}
 
+	if !actor.CanAccessStudent(score.StudentID) {
+		logger.Warn("Score access denied", "score_id", id, "admin_id", actor.AdminID)
+		return nil, errors.ErrForbidden
+	}
+
+	// 学生和家长看不到尚未发布的成绩
+	if actor.IsStudentScoped() && !domain.IsPublishedScoreStatus(score.Status) {
+		return nil, errors.New(errors.ErrCodeNotFound, "成绩不存在")
+	}
+
 	return score, nil
 }
 
 // UpdateScore 更新成绩
-func (s *scoreService) UpdateScore(id int, req *domain.UpdateScoreRequest) (*domain.Score, error) {
+func (s *scoreService) UpdateScore(actor *domain.JWTClaims, id int, req *domain.UpdateScoreRequest, meta domain.ScoreChangeMeta) (*domain.Score, error) {
 	logger.Info("Updating score", "score_id", id)
 
 	// 先获取现有成绩
@@ -73,45 +158,163 @@ func (s *scoreService) UpdateScore(id int, req *domain.UpdateScoreRequest) (*dom
 		return nil, err
 	}
 
+	if err := s.authorizeWrite(actor, score.SubjectID); err != nil {
+		return nil, err
+	}
+
+	if err := checkScoreEditable(score); err != nil {
+		return nil, err
+	}
+
+	if _, err := s.writableTerm(score.Semester); err != nil {
+		return nil, err
+	}
+
+	if err := s.checkStudentActive(score.StudentID); err != nil {
+		return nil, err
+	}
+	previous := *score
+
 	// 更新字段
 	if req.Score > 0 {
 		score.Score = req.Score
 	}
-	if req.Semester != "" {
-		score.Semester = req.Semester
+	if semester := domain.NormalizeTermCode(req.Semester); semester != "" && semester != score.Semester {
+		// 改到其他学期时成绩须归入学生在该学期所选的开课
+		term, err := s.writableTerm(semester)
+		if err != nil {
+			return nil, err
+		}
+		offeringID, err := s.resolveOffering(score.StudentID, score.SubjectID, term.Code, 0)
+		if err != nil {
+			return nil, err
+		}
+		score.Semester = term.Code
+		score.OfferingID = offeringID
 	}
 	if req.ExamType != "" {
 		score.ExamType = req.ExamType
 	}
+	if score.ExamType == domain.ExamTypeFinal && (previous.ExamType != domain.ExamTypeFinal || previous.OfferingID != score.OfferingID) {
+		if err := s.attendance.CheckExamEligibility(score.StudentID, score.OfferingID); err != nil {
+			return nil, err
+		}
+	}
+	if req.Remarks != "" {
+		score.Remarks = req.Remarks
+	}
 
-	err = s.scoreRepo.Update(score)
+	if err := s.saveScore(actor, &previous, score, meta, domain.ScoreHistoryUpdate); err != nil {
+		return nil, err
+	}
+	return score, nil
+}
+
+// AdjustScore 按审理结论调整成绩（如成绩复核），不受成绩状态限制，仍写入变更记录并重新计算总评成绩。
+// 成绩及变更记录由 save 保存，调用方负责检查操作人的权限
+func (s *scoreService) AdjustScore(actor *domain.JWTClaims, id int, value float64, meta domain.ScoreChangeMeta, save ScoreSaveFunc) (*domain.Score, error) {
+	logger.Info("Adjusting score", "score_id", id, "score", value)
+
+	score, err := s.scoreRepo.GetByID(id)
 	if err != nil {
-		logger.Error("Failed to update score", "score_id", id, "error", err)
+		logger.Error("Failed to get score for adjustment", "score_id", id, "error", err)
+		return nil, err
+	}
+
+	if _, err := s.writableTerm(score.Semester); err != nil {
 		return nil, err
 	}
 
-	logger.Info("Score updated successfully", "score_id", id)
+	previous := *score
+	score.Score = value
+	if err := s.applyScoreChange(actor, &previous, score, meta, domain.ScoreHistoryAppeal, save); err != nil {
+		return nil, err
+	}
 	return score, nil
 }
 
+// saveScore 保存修改后的成绩，同时写入变更记录并重新计算受影响的总评成绩
+func (s *scoreService) saveScore(actor *domain.JWTClaims, previous, score *domain.Score, meta domain.ScoreChangeMeta, action string) error {
+	return s.applyScoreChange(actor, previous, score, meta, action, func(score *domain.Score, history *domain.ScoreHistory) error {
+		updated, err := s.scoreRepo.Update(score, history)
+		if err != nil {
+			return err
+		}
+		if !updated {
+			return errScoreChanged
+		}
+		return nil
+	})
+}
+
+// applyScoreChange 生成变更记录并通过 save 保存修改后的成绩，保存后重新计算受影响的总评成绩
+func (s *scoreService) applyScoreChange(actor *domain.JWTClaims, previous, score *domain.Score, meta domain.ScoreChangeMeta, action string, save ScoreSaveFunc) error {
+	history := newScoreHistory(actor, meta, action, score)
+	history.OldScore = &previous.Score
+	history.NewScore = &score.Score
+	history.Details = describeScoreChange(previous, score)
+
+	if err := save(score, history); err != nil {
+		logger.Error("Failed to update score", "score_id", score.ID, "error", err)
+		return err
+	}
+
+	logger.Info("Score updated successfully", "score_id", score.ID)
+	s.recomputeFinalGrade(score.StudentID, score.SubjectID, score.Semester)
+	if previous.Semester != score.Semester {
+		s.recomputeFinalGrade(score.StudentID, score.SubjectID, previous.Semester)
+	}
+	return nil
+}
+
 // DeleteScore 删除成绩
-func (s *scoreService) DeleteScore(id int) error {
+func (s *scoreService) DeleteScore(actor *domain.JWTClaims, id int, meta domain.ScoreChangeMeta) error {
 	logger.Info("Deleting score", "score_id", id)
 
-	err := s.scoreRepo.Delete(id)
+	score, err := s.scoreRepo.GetByID(id)
+	if err != nil {
+		logger.Error("Failed to get score for deletion", "score_id", id, "error", err)
+		return err
+	}
+
+	if err := s.authorizeWrite(actor, score.SubjectID); err != nil {
+		return err
+	}
+
+	if err := checkScoreEditable(score); err != nil {
+		return err
+	}
+
+	if _, err := s.writableTerm(score.Semester); err != nil {
+		return err
+	}
+
+	history := newScoreHistory(actor, meta, domain.ScoreHistoryDelete, score)
+	history.OldScore = &score.Score
+
+	deleted, err := s.scoreRepo.Delete(id, score.Status, history)
 	if err != nil {
 		logger.Error("Failed to delete score", "score_id", id, "error", err)
 		return err
 	}
+	if !deleted {
+		return errScoreChanged
+	}
 
 	logger.Info("Score deleted successfully", "score_id", id)
+	s.recomputeFinalGrade(score.StudentID, score.SubjectID, score.Semester)
 	return nil
 }
 
 // ListScores 获取成绩列表
-func (s *scoreService) ListScores(req *domain.ScoreListRequest) ([]*domain.Score, int64, error) {
+func (s *scoreService) ListScores(actor *domain.JWTClaims, req *domain.ScoreListRequest) ([]*domain.Score, int64, error) {
 	logger.Info("Listing scores", "page", req.Page, "size", req.Size)
 
+	req.Semester = domain.NormalizeTermCode(req.Semester)
+	if err := scopeScoreList(actor, req); err != nil {
+		return nil, 0, err
+	}
+
 	scores, total, err := s.scoreRepo.List(req)
 	if err != nil {
 		logger.Error("Failed to list scores", "error", err)
@@ -120,4 +323,234 @@ func (s *scoreService) ListScores(req *domain.ScoreListRequest) ([]*domain.Score
 
 	logger.Info("Scores listed successfully", "total", total, "returned", len(scores))
 	return scores, total, nil
-}
\ No newline at end of file
+}
+
+// CountScores 统计符合列表条件的成绩数量
+func (s *scoreService) CountScores(actor *domain.JWTClaims, req *domain.ScoreListRequest) (int64, error) {
+	req.Semester = domain.NormalizeTermCode(req.Semester)
+	if err := scopeScoreList(actor, req); err != nil {
+		return 0, err
+	}
+	return s.scoreRepo.Count(req)
+}
+
+// EachScore 按列表条件逐行遍历全部成绩，用于导出
+func (s *scoreService) EachScore(actor *domain.JWTClaims, req *domain.ScoreListRequest, fn func(*domain.Score) error) error {
+	req.Semester = domain.NormalizeTermCode(req.Semester)
+	if err := scopeScoreList(actor, req); err != nil {
+		return err
+	}
+	return s.scoreRepo.Each(req, fn)
+}
+
+// scopeScoreList 学生和家长只能查询本人（子女）已发布的成绩
+func scopeScoreList(actor *domain.JWTClaims, req *domain.ScoreListRequest) error {
+	if actor.IsStudentScoped() {
+		if len(actor.StudentIDs) == 0 || (req.StudentID > 0 && !actor.CanAccessStudent(req.StudentID)) {
+			return errors.ErrForbidden
+		}
+		req.StudentIDs = actor.StudentIDs
+		req.Statuses = domain.PublishedScoreStatuses
+	}
+	return nil
+}
+
+// GetStudentReport 获取学生学期成绩报告
+func (s *scoreService) GetStudentReport(actor *domain.JWTClaims, studentID int, req *domain.StudentScoreReportRequest) (*domain.StudentScoreReport, error) {
+	logger.Info("Getting student score report", "student_id", studentID, "semester", req.Semester)
+
+	if !actor.CanAccessStudent(studentID) {
+		return nil, errors.ErrForbidden
+	}
+
+	req.Semester = domain.NormalizeTermCode(req.Semester)
+	req.PublishedOnly = actor.IsStudentScoped()
+
+	scale, err := s.scales.ResolveForStudent(studentID, req.Scale)
+	if err != nil {
+		return nil, err
+	}
+
+	// 默认按期末成绩计算GPA，避免同一科目的多次考试重复计入学分
+	if req.ExamType == "" {
+		req.ExamType = domain.ExamTypeFinal
+	}
+
+	report, err := s.scoreRepo.GetStudentReport(studentID, req)
+	if err != nil {
+		logger.Error("Failed to get student score report", "student_id", studentID, "error", err)
+		return nil, err
+	}
+
+	// 附上各科总评成绩及其组成，有总评成绩时按总评成绩换算绩点
+	grades, err := s.grading.studentFinalGrades(studentID, req.PublishedOnly)
+	if err != nil {
+		logger.Error("Failed to get final grades for report", "student_id", studentID, "error", err)
+		return nil, err
+	}
+	current := make(map[int]*domain.FinalGrade)
+	var cumulative domain.GPAAccumulator
+	for _, grade := range grades {
+		if grade.Semester == req.Semester {
+			current[grade.SubjectID] = grade
+		}
+		if grade.Semester <= req.Semester && grade.CompositeScore != nil {
+			cumulative.Add(grade.Credits, scale.Grade(*grade.CompositeScore).GradePoint)
+		}
+	}
+
+	var semester domain.GPAAccumulator
+	for i := range report.Scores {
+		detail := &report.Scores[i]
+		value := detail.Score
+		if grade, ok := current[detail.SubjectID]; ok {
+			detail.Components = grade.Components
+			detail.CompositeScore = grade.CompositeScore
+			if grade.CompositeScore != nil {
+				value = *grade.CompositeScore
+			}
+		}
+		result := scale.Grade(value)
+		detail.Letter = result.Letter
+		detail.GradePoint = result.GradePoint
+		detail.Passed = result.Passed
+		semester.Add(detail.Credits, result.GradePoint)
+	}
+
+	report.GradeScale = scale.Name
+	report.GPA = semester.GPA()
+	report.CumulativeGPA = cumulative.GPA()
+	return report, nil
+}
+
+// GetSubjectStatistics 获取科目成绩分段统计
+func (s *scoreService) GetSubjectStatistics(subjectID int, req *domain.ScoreStatisticsRequest) (*domain.SubjectScoreStatistics, error) {
+	logger.Info("Getting subject score statistics", "subject_id", subjectID)
+
+	req.Semester = domain.NormalizeTermCode(req.Semester)
+
+	stats, err := s.scoreRepo.GetSubjectStatistics(subjectID, req)
+	if err != nil {
+		logger.Error("Failed to get subject score statistics", "subject_id", subjectID, "error", err)
+		return nil, err
+	}
+
+	return stats, nil
+}
+
+// GetClassStatistics 获取班级成绩统计
+func (s *scoreService) GetClassStatistics(req *domain.ScoreStatisticsRequest) ([]*domain.ClassScoreStatistics, error) {
+	logger.Info("Getting class score statistics", "semester", req.Semester)
+
+	req.Semester = domain.NormalizeTermCode(req.Semester)
+
+	statistics, err := s.scoreRepo.GetClassStatistics(req)
+	if err != nil {
+		logger.Error("Failed to get class score statistics", "error", err)
+		return nil, err
+	}
+
+	return statistics, nil
+}
+
+// recomputeFinalGrade 成绩变动后重新计算总评成绩。成绩已保存，计算失败只记录日志，可通过开课的重新计算接口补算
+func (s *scoreService) recomputeFinalGrade(studentID, subjectID int, semester string) {
+	key := domain.FinalGradeKey{StudentID: studentID, SubjectID: subjectID, Semester: semester}
+	if err := s.grading.Recompute(key); err != nil {
+		logger.Error("Failed to recompute final grade", "student_id", studentID, "subject_id", subjectID,
+			"semester", semester, "error", err)
+	}
+}
+
+// writableTerm 获取允许录入成绩的学期：已结束的学期成绩冻结，筹备中的学期尚不能录入
+func (s *scoreService) writableTerm(semester string) (*domain.Term, error) {
+	term, err := resolveTerm(s.termRepo, semester)
+	if err != nil {
+		return nil, err
+	}
+	if !term.ScoresWritable() {
+		logger.Warn("Score change rejected by term status", "semester", term.Code, "status", term.Status)
+		if term.Status == domain.TermStatusClosed {
+			return nil, errors.Newf(errors.ErrCodeConflict, "学期 %s 已结束，成绩已冻结", term.Code)
+		}
+		return nil, errors.Newf(errors.ErrCodeConflict, "学期 %s 尚未开始教学，不能录入成绩", term.Code)
+	}
+	return term, nil
+}
+
+// checkStudentActive 只有在校（在读或复学）学生可以录入和修改成绩，休学、转出、退学、毕业和开除学籍的学生不能录入。
+// 按审理结论调整成绩不受此限制
+func (s *scoreService) checkStudentActive(studentID int) error {
+	student, err := s.studentRepo.GetByID(studentID)
+	if err != nil {
+		return err
+	}
+	if student == nil {
+		return errors.Newf(errors.ErrCodeValidation, "学生 %d 不存在", studentID)
+	}
+	if !domain.IsActiveStudentStatus(student.Status) {
+		logger.Warn("Score change rejected by student status", "student_id", studentID, "status", student.Status)
+		return errors.Newf(errors.ErrCodeConflict, "学生 %s 学籍状态为%s，不能录入成绩", student.StudentID, studentStatusName(student.Status))
+	}
+	return nil
+}
+
+// resolveOffering 确定成绩所属开课：指定开课时校验其科目、学期和学生的选课状态，
+// 否则按学生在该科目该学期已选上的开课确定。未选课的学生不能录入成绩
+func (s *scoreService) resolveOffering(studentID, subjectID int, semester string, offeringID int) (int, error) {
+	if offeringID > 0 {
+		offering, err := s.offeringRepo.GetByID(offeringID)
+		if err != nil {
+			return 0, err
+		}
+		if offering == nil {
+			return 0, errors.Newf(errors.ErrCodeValidation, "开课 %d 不存在", offeringID)
+		}
+		if offering.SubjectID != subjectID || offering.Semester != semester {
+			return 0, errors.New(errors.ErrCodeValidation, "开课的科目或学期与成绩不一致")
+		}
+
+		enrollment, err := s.offeringRepo.GetEnrollment(offeringID, studentID)
+		if err != nil {
+			return 0, err
+		}
+		if enrollment == nil || enrollment.Status != domain.EnrollmentStatusEnrolled {
+			logger.Warn("Score rejected for student not enrolled", "student_id", studentID, "offering_id", offeringID)
+			return 0, errors.Newf(errors.ErrCodeValidation, "学生 %d 未选修该开课，不能录入成绩", studentID)
+		}
+		return offeringID, nil
+	}
+
+	id, err := s.offeringRepo.FindStudentOffering(studentID, subjectID, semester, []string{domain.EnrollmentStatusEnrolled})
+	if err != nil {
+		logger.Error("Failed to find student offering", "student_id", studentID, "subject_id", subjectID, "error", err)
+		return 0, err
+	}
+	if id == 0 {
+		logger.Warn("Score rejected for student not enrolled", "student_id", studentID, "subject_id", subjectID, "semester", semester)
+		return 0, errors.Newf(errors.ErrCodeValidation, "学生 %d 未选修 %s 学期的该科目，不能录入成绩", studentID, semester)
+	}
+	return id, nil
+}
+
+// authorizeWrite 检查录入或修改成绩的权限：教师只能管理本人任教科目的成绩，学生和家长只读
+func (s *scoreService) authorizeWrite(actor *domain.JWTClaims, subjectID int) error {
+	switch actor.Role {
+	case domain.RoleAdmin:
+		return nil
+	case domain.RoleTeacher:
+		teacherSubjectID, err := s.scoreRepo.GetTeacherSubjectID(actor.TeacherID)
+		if err != nil {
+			logger.Error("Failed to get teacher subject", "teacher_id", actor.TeacherID, "error", err)
+			return err
+		}
+		if teacherSubjectID == 0 || teacherSubjectID != subjectID {
+			logger.Warn("Teacher cannot manage scores of other subjects",
+				"teacher_id", actor.TeacherID, "subject_id", subjectID)
+			return errors.New(errors.ErrCodeForbidden, "只能录入或修改本人任教科目的成绩")
+		}
+		return nil
+	default:
+		return errors.ErrForbidden
+	}
+}
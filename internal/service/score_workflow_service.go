@@ -0,0 +1,303 @@
+package service
+
+import (
+	"fmt"
+
+	"student-management-system/internal/domain"
+	"student-management-system/pkg/errors"
+	"student-management-system/pkg/logger"
+)
+
+// TransitionOfferingScores 按开课整体流转成绩状态：教师提交，审核人审核通过、退回、发布和锁定。
+// 审核通过的人不能是提交的人；退回时须填写原因
+func (s *scoreService) TransitionOfferingScores(actor *domain.JWTClaims, offeringID int, action string, req *domain.ScoreWorkflowRequest) (*domain.ScoreWorkflowResult, error) {
+	logger.Info("Transitioning offering scores", "offering_id", offeringID, "action", action, "admin_id", actor.AdminID)
+
+	transition, ok := domain.ScoreTransitions[action]
+	if !ok {
+		return nil, errors.Newf(errors.ErrCodeValidation, "不支持的成绩流转操作 %s", action)
+	}
+
+	offering, err := s.getOffering(offeringID)
+	if err != nil {
+		return nil, err
+	}
+	if action == domain.ScoreActionSubmit {
+		if err := s.authorizeOffering(actor, offering); err != nil {
+			return nil, err
+		}
+	}
+	if _, err := s.writableTerm(offering.Semester); err != nil {
+		return nil, err
+	}
+
+	switch action {
+	case domain.ScoreActionReject:
+		if req.Comment == "" {
+			return nil, errors.New(errors.ErrCodeValidation, "退回成绩须填写原因")
+		}
+	case domain.ScoreActionApprove:
+		submitted, err := s.workflowRepo.LastEvent(offeringID, domain.ScoreActionSubmit)
+		if err != nil {
+			return nil, err
+		}
+		if submitted != nil && submitted.ActorID != nil && *submitted.ActorID == actor.AdminID {
+			return nil, errors.New(errors.ErrCodeForbidden, "不能审核本人提交的成绩")
+		}
+	}
+
+	event := &domain.ScoreWorkflowEvent{
+		Action:     action,
+		FromStatus: transition.From[0],
+		ToStatus:   transition.To,
+		Comment:    req.Comment,
+	}
+	if actor.AdminID != 0 {
+		actorID := actor.AdminID
+		event.ActorID = &actorID
+	}
+
+	counts, err := s.workflowRepo.CountOfferingScores(offeringID)
+	if err != nil {
+		return nil, err
+	}
+	// 退回时记录实际退回的状态：已审核的成绩优先于已提交的成绩
+	if action == domain.ScoreActionReject && counts[domain.ScoreStatusApproved] > 0 {
+		event.FromStatus = domain.ScoreStatusApproved
+	}
+
+	affected, err := s.workflowRepo.TransitionOffering(offeringID, transition.From, event)
+	if err != nil {
+		logger.Error("Failed to transition offering scores", "offering_id", offeringID, "action", action, "error", err)
+		return nil, err
+	}
+	if affected == 0 {
+		return nil, errors.Newf(errors.ErrCodeConflict, "开课中没有可%s的成绩", scoreActionNames[action])
+	}
+
+	logger.Info("Offering scores transitioned", "offering_id", offeringID, "action", action, "affected", affected)
+	return &domain.ScoreWorkflowResult{
+		OfferingID: offeringID,
+		Action:     action,
+		Status:     transition.To,
+		Affected:   affected,
+	}, nil
+}
+
+// GetOfferingScoreStatus 获取开课成绩的状态汇总及流转记录，学生和家长无权查看
+func (s *scoreService) GetOfferingScoreStatus(actor *domain.JWTClaims, offeringID int) (*domain.OfferingScoreStatus, error) {
+	if actor.IsStudentScoped() {
+		return nil, errors.ErrForbidden
+	}
+	if _, err := s.getOffering(offeringID); err != nil {
+		return nil, err
+	}
+
+	counts, err := s.workflowRepo.CountOfferingScores(offeringID)
+	if err != nil {
+		return nil, err
+	}
+	events, err := s.workflowRepo.ListEvents(offeringID)
+	if err != nil {
+		return nil, err
+	}
+
+	status := &domain.OfferingScoreStatus{OfferingID: offeringID, Counts: counts, Events: events}
+	for _, count := range counts {
+		status.Total += count
+	}
+	return status, nil
+}
+
+// RequestGradeChange 为已发布或已锁定的成绩提交更正申请，审批通过后才会修改成绩
+func (s *scoreService) RequestGradeChange(actor *domain.JWTClaims, scoreID int, req *domain.CreateGradeChangeRequest) (*domain.GradeChangeRequest, error) {
+	logger.Info("Requesting grade change", "score_id", scoreID, "admin_id", actor.AdminID)
+
+	score, err := s.scoreRepo.GetByID(scoreID)
+	if err != nil {
+		logger.Error("Failed to get score for grade change", "score_id", scoreID, "error", err)
+		return nil, err
+	}
+
+	if err := s.authorizeWrite(actor, score.SubjectID); err != nil {
+		return nil, err
+	}
+	if !domain.IsPublishedScoreStatus(score.Status) {
+		return nil, errors.New(errors.ErrCodeConflict, "只有已发布或已锁定的成绩需要提交更正申请，其他成绩请直接修改或退回后修改")
+	}
+	if req.Score == score.Score {
+		return nil, errors.New(errors.ErrCodeValidation, "更正后的成绩与原成绩相同")
+	}
+	if _, err := s.writableTerm(score.Semester); err != nil {
+		return nil, err
+	}
+
+	pending, err := s.workflowRepo.HasPendingChangeRequest(scoreID)
+	if err != nil {
+		return nil, err
+	}
+	if pending {
+		return nil, errors.New(errors.ErrCodeConflict, "该成绩已有待审批的更正申请")
+	}
+
+	change := &domain.GradeChangeRequest{
+		ScoreID:    score.ID,
+		OldScore:   score.Score,
+		NewScore:   req.Score,
+		Reason:     req.Reason,
+		Status:     domain.GradeChangeStatusPending,
+		StudentID:  score.StudentID,
+		SubjectID:  score.SubjectID,
+		OfferingID: score.OfferingID,
+		Semester:   score.Semester,
+		ExamType:   score.ExamType,
+	}
+	if actor.AdminID != 0 {
+		requestedBy := actor.AdminID
+		change.RequestedBy = &requestedBy
+	}
+
+	if err := s.workflowRepo.CreateChangeRequest(change); err != nil {
+		return nil, err
+	}
+	return change, nil
+}
+
+// ListGradeChangeRequests 获取成绩更正申请列表，教师只能查看本人提交的申请
+func (s *scoreService) ListGradeChangeRequests(actor *domain.JWTClaims, req *domain.GradeChangeListRequest) ([]*domain.GradeChangeRequest, int64, error) {
+	if actor.IsStudentScoped() {
+		return nil, 0, errors.ErrForbidden
+	}
+	if actor.Role == domain.RoleTeacher {
+		req.RequestedBy = actor.AdminID
+	}
+	if req.Page <= 0 {
+		req.Page = 1
+	}
+	if req.Size <= 0 {
+		req.Size = 10
+	}
+
+	requests, total, err := s.workflowRepo.ListChangeRequests(req)
+	if err != nil {
+		logger.Error("Failed to list grade change requests", "error", err)
+		return nil, 0, err
+	}
+	return requests, total, nil
+}
+
+// ReviewGradeChange 审批成绩更正申请：批准时修改成绩、写入变更记录并重新计算总评成绩，驳回时须填写意见。
+// 申请人不能审批本人的申请
+func (s *scoreService) ReviewGradeChange(actor *domain.JWTClaims, id int, approve bool, req *domain.ReviewGradeChangeRequest, meta domain.ScoreChangeMeta) (*domain.GradeChangeRequest, error) {
+	logger.Info("Reviewing grade change request", "request_id", id, "approve", approve, "admin_id", actor.AdminID)
+
+	change, err := s.workflowRepo.GetChangeRequest(id)
+	if err != nil {
+		return nil, err
+	}
+	if change == nil {
+		return nil, errors.New(errors.ErrCodeNotFound, "成绩更正申请不存在")
+	}
+	if change.Status != domain.GradeChangeStatusPending {
+		return nil, errors.New(errors.ErrCodeConflict, "成绩更正申请已审批")
+	}
+	if change.RequestedBy != nil && *change.RequestedBy == actor.AdminID {
+		return nil, errors.New(errors.ErrCodeForbidden, "不能审批本人提交的成绩更正申请")
+	}
+	if !approve && req.Comment == "" {
+		return nil, errors.New(errors.ErrCodeValidation, "驳回成绩更正申请须填写意见")
+	}
+
+	change.Status = domain.GradeChangeStatusRejected
+	if approve {
+		if _, err := s.writableTerm(change.Semester); err != nil {
+			return nil, err
+		}
+		change.Status = domain.GradeChangeStatusApproved
+	}
+	change.ReviewComment = req.Comment
+	if actor.AdminID != 0 {
+		reviewedBy := actor.AdminID
+		change.ReviewedBy = &reviewedBy
+	}
+
+	// 变更记录的原因取申请理由，操作人为审批人
+	var history *domain.ScoreHistory
+	if approve {
+		meta.Reason = change.Reason
+		history = newScoreHistory(actor, meta, domain.ScoreHistoryGradeChange, &domain.Score{
+			ID:        change.ScoreID,
+			StudentID: change.StudentID,
+			SubjectID: change.SubjectID,
+			Semester:  change.Semester,
+			ExamType:  change.ExamType,
+		})
+		history.NewScore = &change.NewScore
+		history.Details = fmt.Sprintf("成绩更正申请 #%d", change.ID)
+		if change.RequestedBy != nil {
+			history.Details += fmt.Sprintf("，申请人ID %d", *change.RequestedBy)
+		}
+	}
+
+	reviewed, err := s.workflowRepo.ReviewChangeRequest(change, history)
+	if err != nil {
+		logger.Error("Failed to review grade change request", "request_id", id, "error", err)
+		return nil, err
+	}
+	if !reviewed {
+		if approve {
+			return nil, errors.New(errors.ErrCodeConflict, "成绩更正申请已审批，或成绩已在申请后被修改，请刷新后重试")
+		}
+		return nil, errors.New(errors.ErrCodeConflict, "成绩更正申请已审批")
+	}
+
+	if approve {
+		logger.Info("Grade change applied", "score_id", change.ScoreID, "old_score", change.OldScore, "new_score", change.NewScore)
+		s.recomputeFinalGrade(change.StudentID, change.SubjectID, change.Semester)
+	}
+	return change, nil
+}
+
+// scoreActionNames 成绩流转操作的中文名称
+var scoreActionNames = map[string]string{
+	domain.ScoreActionSubmit:  "提交",
+	domain.ScoreActionApprove: "审核",
+	domain.ScoreActionReject:  "退回",
+	domain.ScoreActionPublish: "发布",
+	domain.ScoreActionLock:    "锁定",
+}
+
+// errScoreChanged 保存时成绩已被删除或状态已变化（如已被提交审核）
+var errScoreChanged = errors.New(errors.ErrCodeConflict, "成绩已被修改，请刷新后重试")
+
+// checkScoreEditable 检查成绩能否直接修改或删除：只有草稿可以，已发布和已锁定的成绩须提交更正申请
+func checkScoreEditable(score *domain.Score) error {
+	switch score.Status {
+	case domain.ScoreStatusDraft, "":
+		return nil
+	case domain.ScoreStatusSubmitted, domain.ScoreStatusApproved:
+		return errors.New(errors.ErrCodeConflict, "成绩已提交审核，须退回后才能修改")
+	default:
+		return errors.New(errors.ErrCodeConflict, "成绩已发布，请通过成绩更正申请修改")
+	}
+}
+
+// getOffering 获取开课
+func (s *scoreService) getOffering(offeringID int) (*domain.CourseOffering, error) {
+	offering, err := s.offeringRepo.GetByID(offeringID)
+	if err != nil {
+		return nil, err
+	}
+	if offering == nil {
+		return nil, errors.New(errors.ErrCodeNotFound, "开课不存在")
+	}
+	return offering, nil
+}
+
+// authorizeOffering 检查提交开课成绩的权限：任课教师或有该科目成绩管理权限的用户
+func (s *scoreService) authorizeOffering(actor *domain.JWTClaims, offering *domain.CourseOffering) error {
+	if actor.Role == domain.RoleTeacher && offering.TeacherID != nil && *offering.TeacherID == actor.TeacherID {
+		return nil
+	}
+	return s.authorizeWrite(actor, offering.SubjectID)
+}
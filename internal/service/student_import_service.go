@@ -0,0 +1,433 @@
+package service
+
+import (
+	"fmt"
+	"io"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+
+	"student-management-system/internal/domain"
+	"student-management-system/internal/repository"
+	"student-management-system/pkg/errors"
+	"student-management-system/pkg/logger"
+	"student-management-system/pkg/spreadsheet"
+	"student-management-system/pkg/validator"
+)
+
+// MaxStudentImportRows 单个文件允许导入的最大数据行数
+const MaxStudentImportRows = 10000
+
+// studentImportField 可导入的学生字段
+type studentImportField struct {
+	key      string   // 字段名，与JSON字段一致
+	name     string   // 结构体字段名，用于匹配校验错误
+	aliases  []string // 默认识别的表头
+	required bool     // 表格中是否必须包含该列
+}
+
+// studentImportFields 学生导入字段及默认表头
+var studentImportFields = []studentImportField{
+	{key: "student_id", name: "StudentID", aliases: []string{"学号", "student_no"}, required: true},
+	{key: "name", name: "Name", aliases: []string{"姓名", "名字"}, required: true},
+	{key: "age", name: "Age", aliases: []string{"年龄"}, required: true},
+	{key: "gender", name: "Gender", aliases: []string{"性别", "sex"}, required: true},
+	{key: "phone", name: "Phone", aliases: []string{"电话", "手机", "手机号", "联系电话", "mobile"}, required: true},
+	{key: "email", name: "Email", aliases: []string{"邮箱", "电子邮箱", "mail"}, required: true},
+	{key: "address", name: "Address", aliases: []string{"地址", "家庭住址"}},
+	{key: "major", name: "Major", aliases: []string{"专业"}, required: true},
+	{key: "enrollment_date", name: "EnrollmentDate", aliases: []string{"入学日期", "入学时间"}},
+	{key: "graduation_date", name: "GraduationDate", aliases: []string{"毕业日期", "毕业时间"}},
+	{key: "status", name: "Status", aliases: []string{"状态", "学籍状态"}},
+}
+
+// studentGenderAliases 性别别名
+var studentGenderAliases = map[string]string{
+	"male":   "男",
+	"m":      "男",
+	"female": "女",
+	"f":      "女",
+}
+
+// studentStatusAliases 学生状态别名，兼容旧版的 active。导入只能使用在读或毕业，
+// 休学、转出等状态须导入后通过学籍异动办理
+var studentStatusAliases = map[string]string{
+	"在读":     domain.StudentStatusEnrolled,
+	"在校":     domain.StudentStatusEnrolled,
+	"active": domain.StudentStatusEnrolled,
+	"毕业":     domain.StudentStatusGraduated,
+	"已毕业":    domain.StudentStatusGraduated,
+}
+
+// studentImportRow 解析后的一行数据
+type studentImportRow struct {
+	row int
+	req domain.CreateStudentRequest
+}
+
+// StudentImportService 学生批量导入服务
+type StudentImportService struct {
+	repo      repository.StudentRepository
+	validator *validator.CustomValidator
+}
+
+// NewStudentImportService 创建学生批量导入服务实例
+func NewStudentImportService(validator *validator.CustomValidator) *StudentImportService {
+	return &StudentImportService{
+		repo:      repository.NewStudentRepository(repository.DB),
+		validator: validator,
+	}
+}
+
+// Import 从CSV/XLSX导入学生。
+// 每行都会单独校验并返回行级错误；DryRun 时只校验不写入。
+// atomic 模式下存在任意无效行则不写入任何数据，skip_invalid 模式下跳过无效行、在同一事务中写入其余行。
+func (s *StudentImportService) Import(r io.Reader, opts domain.StudentImportOptions) (*domain.StudentImportResult, error) {
+	if opts.Mode == "" {
+		opts.Mode = domain.StudentImportModeAtomic
+	}
+	if opts.Mode != domain.StudentImportModeAtomic && opts.Mode != domain.StudentImportModeSkipInvalid {
+		return nil, errors.Newf(errors.ErrCodeInvalidRequest, "不支持的导入模式: %s", opts.Mode)
+	}
+
+	records, err := spreadsheet.ReadAll(r, opts.Format, opts.Sheet)
+	if err != nil {
+		return nil, errors.Newf(errors.ErrCodeInvalidRequest, "读取文件失败: %v", err)
+	}
+
+	headerIndex := -1
+	for i, record := range records {
+		if !isBlankRecord(record) {
+			headerIndex = i
+			break
+		}
+	}
+	if headerIndex < 0 {
+		return nil, errors.New(errors.ErrCodeInvalidRequest, "文件为空")
+	}
+
+	columns, headers, err := resolveImportColumns(records[headerIndex], opts.Mapping)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &domain.StudentImportResult{
+		DryRun:  opts.DryRun,
+		Mode:    opts.Mode,
+		Columns: headers,
+		Errors:  []domain.StudentImportRowError{},
+	}
+
+	var rows []*studentImportRow
+	invalid := make(map[int]bool)
+	for i := headerIndex + 1; i < len(records); i++ {
+		if isBlankRecord(records[i]) {
+			continue
+		}
+		result.Total++
+		if result.Total > MaxStudentImportRows {
+			return nil, errors.Newf(errors.ErrCodeInvalidRequest, "单次最多导入%d行数据", MaxStudentImportRows)
+		}
+
+		row, rowErrors := s.parseRow(i+1, records[i], columns)
+		if len(rowErrors) > 0 {
+			invalid[row.row] = true
+			result.Errors = append(result.Errors, rowErrors...)
+		}
+		rows = append(rows, row)
+	}
+
+	if err := s.checkDuplicates(rows, invalid, result); err != nil {
+		return nil, err
+	}
+
+	var students []*domain.Student
+	for _, row := range rows {
+		if invalid[row.row] {
+			continue
+		}
+		students = append(students, newImportedStudent(row.req))
+	}
+	result.Valid = len(students)
+	result.Invalid = result.Total - result.Valid
+	sort.SliceStable(result.Errors, func(i, j int) bool {
+		return result.Errors[i].Row < result.Errors[j].Row
+	})
+
+	logFields := map[string]interface{}{
+		"mode":    opts.Mode,
+		"dry_run": opts.DryRun,
+		"total":   result.Total,
+		"valid":   result.Valid,
+		"invalid": result.Invalid,
+	}
+
+	if opts.DryRun || len(students) == 0 {
+		logger.WithFields(logFields).Info("Student import validated")
+		return result, nil
+	}
+	if opts.Mode == domain.StudentImportModeAtomic && result.Invalid > 0 {
+		logger.WithFields(logFields).Warn("Student import rejected due to invalid rows")
+		return result, nil
+	}
+
+	if err := s.repo.BatchCreate(students); err != nil {
+		logger.WithError(err).WithFields(logFields).Error("Failed to import students")
+		return nil, fmt.Errorf("导入学生失败: %w", err)
+	}
+
+	result.Imported = len(students)
+	result.Committed = true
+	logger.WithFields(logFields).Info("Students imported successfully")
+	return result, nil
+}
+
+// parseRow 解析并校验一行数据
+func (s *StudentImportService) parseRow(rowNum int, record []string, columns map[string]int) (*studentImportRow, []domain.StudentImportRowError) {
+	row := &studentImportRow{row: rowNum}
+	var rowErrors []domain.StudentImportRowError
+	failed := make(map[string]bool)
+
+	addError := func(field, message string) {
+		failed[field] = true
+		rowErrors = append(rowErrors, domain.StudentImportRowError{
+			Row:       rowNum,
+			StudentID: row.req.StudentID,
+			Field:     field,
+			Message:   message,
+		})
+	}
+
+	cell := func(key string) string {
+		idx, ok := columns[key]
+		if !ok || idx >= len(record) {
+			return ""
+		}
+		return strings.TrimSpace(record[idx])
+	}
+
+	req := &row.req
+	req.StudentID = cell("student_id")
+	req.Name = cell("name")
+	req.Phone = cell("phone")
+	req.Email = cell("email")
+	req.Address = cell("address")
+	req.Major = cell("major")
+
+	req.Gender = cell("gender")
+	if gender, ok := studentGenderAliases[strings.ToLower(req.Gender)]; ok {
+		req.Gender = gender
+	}
+
+	req.Status = strings.ToLower(cell("status"))
+	if status, ok := studentStatusAliases[req.Status]; ok {
+		req.Status = status
+	}
+	if req.Status != "" && !domain.IsInitialStudentStatus(req.Status) {
+		addError("status", "学籍状态只能为在读或毕业，其他状态请导入后通过学籍异动办理")
+	}
+
+	if value := cell("age"); value != "" {
+		age, err := parseImportInt(value)
+		if err != nil {
+			addError("age", "年龄必须是整数")
+		}
+		req.Age = age
+	}
+
+	for _, key := range []string{"enrollment_date", "graduation_date"} {
+		value := cell(key)
+		if value == "" {
+			continue
+		}
+		date, err := spreadsheet.ParseDate(value)
+		if err != nil {
+			addError(key, "日期格式不正确，应为 YYYY-MM-DD")
+			continue
+		}
+		if key == "enrollment_date" {
+			req.EnrollmentDate = &date
+		} else {
+			req.GraduationDate = &date
+		}
+	}
+
+	if err := s.validator.ValidateStruct(req); err != nil {
+		for _, fe := range validator.FormatValidationErrors(err) {
+			key := importFieldKey(fe.Field)
+			if failed[key] {
+				continue
+			}
+			addError(key, fe.Message)
+		}
+	}
+
+	if req.EnrollmentDate != nil && req.GraduationDate != nil && req.GraduationDate.Before(*req.EnrollmentDate) {
+		addError("graduation_date", "毕业日期不能早于入学日期")
+	}
+	if req.Status == domain.StudentStatusGraduated && req.GraduationDate == nil && !failed["graduation_date"] {
+		addError("graduation_date", "毕业学生须填写毕业日期")
+	}
+
+	return row, rowErrors
+}
+
+// checkDuplicates 检查文件内重复学号和数据库中已存在的学号
+func (s *StudentImportService) checkDuplicates(rows []*studentImportRow, invalid map[int]bool, result *domain.StudentImportResult) error {
+	firstRow := make(map[string]int)
+	var candidates []string
+	for _, row := range rows {
+		studentID := row.req.StudentID
+		if studentID == "" {
+			continue
+		}
+		if first, ok := firstRow[studentID]; ok {
+			invalid[row.row] = true
+			result.Errors = append(result.Errors, domain.StudentImportRowError{
+				Row:       row.row,
+				StudentID: studentID,
+				Field:     "student_id",
+				Message:   fmt.Sprintf("学号与第%d行重复", first),
+			})
+			continue
+		}
+		firstRow[studentID] = row.row
+		if !invalid[row.row] {
+			candidates = append(candidates, studentID)
+		}
+	}
+
+	existing, err := s.repo.ExistingStudentIDs(candidates)
+	if err != nil {
+		return fmt.Errorf("检查学号失败: %w", err)
+	}
+	for _, studentID := range existing {
+		rowNum, ok := firstRow[studentID]
+		if !ok {
+			continue
+		}
+		invalid[rowNum] = true
+		result.Errors = append(result.Errors, domain.StudentImportRowError{
+			Row:       rowNum,
+			StudentID: studentID,
+			Field:     "student_id",
+			Message:   "学号已存在",
+		})
+	}
+
+	return nil
+}
+
+// resolveImportColumns 根据表头和自定义映射确定每个字段所在的列。
+// 返回字段到列下标的映射，以及字段到实际表头的映射。
+func resolveImportColumns(header []string, mapping map[string]string) (map[string]int, map[string]string, error) {
+	known := make(map[string]bool, len(studentImportFields))
+	for _, field := range studentImportFields {
+		known[field.key] = true
+	}
+	for key := range mapping {
+		if !known[key] {
+			return nil, nil, errors.Newf(errors.ErrCodeInvalidRequest, "未知的导入字段: %s", key)
+		}
+	}
+
+	index := make(map[string]int, len(header))
+	for i, h := range header {
+		h = normalizeHeader(h)
+		if _, ok := index[h]; !ok && h != "" {
+			index[h] = i
+		}
+	}
+
+	columns := make(map[string]int)
+	headers := make(map[string]string)
+	var missing []string
+	for _, field := range studentImportFields {
+		if custom, ok := mapping[field.key]; ok {
+			idx, found := index[normalizeHeader(custom)]
+			if !found {
+				return nil, nil, errors.Newf(errors.ErrCodeInvalidRequest, "表头中找不到字段 %s 映射的列: %s", field.key, custom)
+			}
+			columns[field.key] = idx
+			headers[field.key] = strings.TrimSpace(header[idx])
+			continue
+		}
+
+		for _, alias := range append([]string{field.key}, field.aliases...) {
+			if idx, found := index[normalizeHeader(alias)]; found {
+				columns[field.key] = idx
+				headers[field.key] = strings.TrimSpace(header[idx])
+				break
+			}
+		}
+		if _, ok := columns[field.key]; !ok && field.required {
+			missing = append(missing, field.key)
+		}
+	}
+
+	if len(missing) > 0 {
+		return nil, nil, errors.Newf(errors.ErrCodeInvalidRequest, "缺少必需的列: %s", strings.Join(missing, ", "))
+	}
+
+	return columns, headers, nil
+}
+
+// newImportedStudent 根据导入行创建学生
+func newImportedStudent(req domain.CreateStudentRequest) *domain.Student {
+	student := &domain.Student{
+		StudentID:      req.StudentID,
+		Name:           req.Name,
+		Age:            req.Age,
+		Gender:         req.Gender,
+		Phone:          req.Phone,
+		Email:          req.Email,
+		Address:        req.Address,
+		Major:          req.Major,
+		EnrollmentDate: req.EnrollmentDate,
+		GraduationDate: req.GraduationDate,
+		Status:         req.Status,
+	}
+	if student.Status == "" {
+		student.Status = domain.StudentStatusEnrolled
+	}
+	return student
+}
+
+// importFieldKey 将结构体字段名转换为导入字段名
+func importFieldKey(name string) string {
+	for _, field := range studentImportFields {
+		if field.name == name {
+			return field.key
+		}
+	}
+	return name
+}
+
+// normalizeHeader 规范化表头，忽略大小写、空格和下划线
+func normalizeHeader(h string) string {
+	h = strings.ToLower(strings.TrimSpace(h))
+	return strings.NewReplacer(" ", "", "_", "", "-", "").Replace(h)
+}
+
+// parseImportInt 解析整数单元格，兼容Excel中的 "18.0"
+func parseImportInt(value string) (int, error) {
+	if n, err := strconv.Atoi(value); err == nil {
+		return n, nil
+	}
+	f, err := strconv.ParseFloat(value, 64)
+	if err != nil || f != math.Trunc(f) {
+		return 0, fmt.Errorf("invalid integer %q", value)
+	}
+	return int(f), nil
+}
+
+// isBlankRecord 是否为空行
+func isBlankRecord(record []string) bool {
+	for _, v := range record {
+		if strings.TrimSpace(v) != "" {
+			return false
+		}
+	}
+	return true
+}
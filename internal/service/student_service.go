@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"student-management-system/internal/domain"
 	"student-management-system/internal/repository"
+	"student-management-system/pkg/errors"
 	"student-management-system/pkg/logger"
 )
 
@@ -43,7 +44,13 @@ func (s *StudentService) CreateStudent(req domain.CreateStudentRequest) (*domain
 
 	// 如果状态为空，设置默认值
 	if student.Status == "" {
-		student.Status = "active"
+		student.Status = domain.StudentStatusEnrolled
+	}
+	if !domain.IsInitialStudentStatus(student.Status) {
+		return nil, errors.New(errors.ErrCodeValidation, "新建学生的学籍状态只能为在读或毕业，其他状态须通过学籍异动接口办理")
+	}
+	if student.Status == domain.StudentStatusGraduated && student.GraduationDate == nil {
+		return nil, errors.New(errors.ErrCodeValidation, "补录毕业学生须填写毕业日期")
 	}
 
 	err := s.repo.Create(student)
@@ -88,25 +95,27 @@ func (s *StudentService) GetStudentByID(id int) (*domain.Student, error) {
 	return student, nil
 }
 
-// GetAllStudents 获取所有学生信息（分页）
-func (s *StudentService) GetAllStudents(page, pageSize int) ([]*domain.Student, int, error) {
+// GetAllStudents 获取所有学生信息（分页），可按班级、年级筛选
+func (s *StudentService) GetAllStudents(page, pageSize int, filter domain.StudentFilter) ([]*domain.Student, int, error) {
 	logger.WithFields(map[string]interface{}{
-		"page":      page,
-		"page_size": pageSize,
+		"page":       page,
+		"page_size":  pageSize,
+		"class_id":   filter.ClassID,
+		"grade_year": filter.GradeYear,
 	}).Info("Getting all students")
 
 	// 计算偏移量
 	offset := (page - 1) * pageSize
 
 	// 获取学生列表
-	students, err := s.repo.List(offset, pageSize)
+	students, err := s.repo.List(offset, pageSize, filter)
 	if err != nil {
 		logger.WithError(err).Error("Failed to get students list")
 		return nil, 0, fmt.Errorf("failed to get students: %v", err)
 	}
 
 	// 获取总数
-	total, err := s.repo.Count()
+	total, err := s.repo.Count(filter)
 	if err != nil {
 		logger.WithError(err).Error("Failed to get students count")
 		return nil, 0, fmt.Errorf("failed to get students count: %v", err)
@@ -120,6 +129,53 @@ func (s *StudentService) GetAllStudents(page, pageSize int) ([]*domain.Student,
 	return students, total, nil
 }
 
+// GetStudentsByIDs 根据ID列表获取学生信息，用于学生和家长账号查询本人（子女），不存在的学生不返回
+func (s *StudentService) GetStudentsByIDs(ids []int) ([]*domain.Student, error) {
+	logger.WithFields(map[string]interface{}{
+		"student_ids": ids,
+	}).Info("Getting students by IDs")
+
+	students := make([]*domain.Student, 0, len(ids))
+	for _, id := range ids {
+		student, err := s.repo.GetByID(id)
+		if err != nil {
+			logger.WithError(err).WithFields(map[string]interface{}{
+				"student_id": id,
+			}).Error("Failed to get student")
+			return nil, fmt.Errorf("failed to get student: %v", err)
+		}
+		// 关联的学生已被删除时跳过
+		if student == nil {
+			continue
+		}
+		students = append(students, student)
+	}
+
+	return students, nil
+}
+
+// CountStudents 按筛选条件统计当前账号可见的学生数量，与学生列表一致，学生和家长只统计本人（子女）且不按条件筛选
+func (s *StudentService) CountStudents(actor *domain.JWTClaims, filter domain.StudentFilter) (int, error) {
+	if actor != nil && actor.IsStudentScoped() {
+		return len(actor.StudentIDs), nil
+	}
+	return s.repo.Count(filter)
+}
+
+// EachStudent 按筛选条件逐个遍历当前账号可见的学生，学生和家长只遍历本人（子女），用于导出
+func (s *StudentService) EachStudent(actor *domain.JWTClaims, filter domain.StudentFilter, fn func(*domain.Student) error) error {
+	var ids []int
+	if actor != nil && actor.IsStudentScoped() {
+		ids = append([]int{}, actor.StudentIDs...)
+		filter = domain.StudentFilter{}
+	}
+
+	if err := s.repo.Each(ids, filter, fn); err != nil {
+		return fmt.Errorf("failed to iterate students: %w", err)
+	}
+	return nil
+}
+
 // UpdateStudent 更新学生信息
 func (s *StudentService) UpdateStudent(id int, req domain.UpdateStudentRequest) (*domain.Student, error) {
 	logger.WithFields(map[string]interface{}{
@@ -171,8 +227,9 @@ func (s *StudentService) UpdateStudent(id int, req domain.UpdateStudentRequest)
 	if req.GraduationDate != nil {
 		student.GraduationDate = req.GraduationDate
 	}
-	if req.Status != "" {
-		student.Status = req.Status
+	// 学籍状态只能通过学籍异动变更，以便校验状态流转并留下异动记录
+	if req.Status != "" && req.Status != student.Status {
+		return nil, errors.New(errors.ErrCodeValidation, "学籍状态须通过学籍异动接口变更")
 	}
 
 	err = s.repo.Update(student)
@@ -0,0 +1,150 @@
+package service
+
+import (
+	"time"
+
+	"student-management-system/internal/domain"
+	"student-management-system/internal/repository"
+	"student-management-system/pkg/errors"
+	"student-management-system/pkg/logger"
+)
+
+// StudentStatusService 学籍异动服务：按状态流转规则变更学生的学籍状态，每次变更写入异动记录
+type StudentStatusService struct {
+	statusRepo  repository.StudentStatusRepository
+	studentRepo repository.StudentRepository
+}
+
+// NewStudentStatusService 创建学籍异动服务实例
+func NewStudentStatusService(statusRepo repository.StudentStatusRepository, studentRepo repository.StudentRepository) *StudentStatusService {
+	return &StudentStatusService{
+		statusRepo:  statusRepo,
+		studentRepo: studentRepo,
+	}
+}
+
+// ChangeStatus 变更学籍状态。须符合状态流转规则，休学、复学、转出、退学和开除学籍须填写批准文件编号；
+// 生效日期不能晚于今天，也不能早于入学日期和上一次异动的生效日期
+func (s *StudentStatusService) ChangeStatus(actor *domain.JWTClaims, studentID int, req domain.ChangeStudentStatusRequest) (*domain.StudentStatusInfo, error) {
+	logger.WithFields(map[string]interface{}{
+		"student_id": studentID,
+		"status":     req.Status,
+		"admin_id":   actor.AdminID,
+	}).Info("Changing student status")
+
+	student, err := s.getStudent(studentID)
+	if err != nil {
+		return nil, err
+	}
+
+	if !domain.CanTransitionStudentStatus(student.Status, req.Status) {
+		return nil, errors.Newf(errors.ErrCodeConflict, "学籍状态不能从%s变更为%s",
+			studentStatusName(student.Status), studentStatusName(req.Status))
+	}
+	if domain.StudentStatusTransitions[req.Status].RequireDocument && req.DocumentNo == "" {
+		return nil, errors.Newf(errors.ErrCodeValidation, "变更为%s须填写批准文件编号", studentStatusName(req.Status))
+	}
+
+	effectiveDate := dateOnly(req.EffectiveDate)
+	if effectiveDate.After(dateOnly(time.Now())) {
+		return nil, errors.New(errors.ErrCodeValidation, "生效日期不能晚于今天")
+	}
+	if student.EnrollmentDate != nil && effectiveDate.Before(dateOnly(*student.EnrollmentDate)) {
+		return nil, errors.New(errors.ErrCodeValidation, "生效日期不能早于入学日期")
+	}
+
+	history, err := s.statusRepo.ListHistory(studentID)
+	if err != nil {
+		return nil, err
+	}
+	if len(history) > 0 {
+		if last := history[len(history)-1]; effectiveDate.Before(dateOnly(last.EffectiveDate)) {
+			return nil, errors.Newf(errors.ErrCodeValidation, "生效日期不能早于上一次学籍异动的生效日期 %s",
+				last.EffectiveDate.Format("2006-01-02"))
+		}
+	}
+
+	change := &domain.StudentStatusChange{
+		StudentID:     studentID,
+		FromStatus:    student.Status,
+		ToStatus:      req.Status,
+		Reason:        req.Reason,
+		DocumentNo:    req.DocumentNo,
+		Attachments:   req.Attachments,
+		EffectiveDate: effectiveDate,
+	}
+	if actor.AdminID != 0 {
+		actorID := actor.AdminID
+		change.ActorID = &actorID
+	}
+
+	changed, err := s.statusRepo.ChangeStatus(change)
+	if err != nil {
+		return nil, err
+	}
+	if !changed {
+		return nil, errors.New(errors.ErrCodeConflict, "学籍状态已被修改，请刷新后重试")
+	}
+
+	logger.WithFields(map[string]interface{}{
+		"student_id":  studentID,
+		"from_status": change.FromStatus,
+		"to_status":   change.ToStatus,
+	}).Info("Student status changed successfully")
+
+	return s.statusInfo(studentID)
+}
+
+// GetStatus 获取学生的学籍状态、可以转入的状态及异动记录，学生和家长只能查看本人（子女）的学籍
+func (s *StudentStatusService) GetStatus(actor *domain.JWTClaims, studentID int) (*domain.StudentStatusInfo, error) {
+	if !actor.CanAccessStudent(studentID) {
+		return nil, errors.ErrForbidden
+	}
+	return s.statusInfo(studentID)
+}
+
+// statusInfo 组装学生的学籍状态信息
+func (s *StudentStatusService) statusInfo(studentID int) (*domain.StudentStatusInfo, error) {
+	student, err := s.getStudent(studentID)
+	if err != nil {
+		return nil, err
+	}
+	history, err := s.statusRepo.ListHistory(studentID)
+	if err != nil {
+		return nil, err
+	}
+	return &domain.StudentStatusInfo{
+		StudentID:          student.ID,
+		StudentNo:          student.StudentID,
+		StudentName:        student.Name,
+		Status:             student.Status,
+		Active:             domain.IsActiveStudentStatus(student.Status),
+		AllowedTransitions: domain.AllowedStudentStatusTransitions(student.Status),
+		History:            history,
+	}, nil
+}
+
+// getStudent 获取学生，不存在时返回未找到错误
+func (s *StudentStatusService) getStudent(studentID int) (*domain.Student, error) {
+	student, err := s.studentRepo.GetByID(studentID)
+	if err != nil {
+		return nil, err
+	}
+	if student == nil {
+		return nil, errors.Newf(errors.ErrCodeNotFound, "学生 %d 不存在", studentID)
+	}
+	return student, nil
+}
+
+// studentStatusName 学籍状态的中文名称，未知状态原样返回
+func studentStatusName(status string) string {
+	if name, ok := domain.StudentStatusNames[status]; ok {
+		return name
+	}
+	return status
+}
+
+// dateOnly 截取日期部分
+func dateOnly(t time.Time) time.Time {
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)
+}
@@ -164,6 +164,54 @@ func (t *TeacherService) GetAllTeachers(page, pageSize int) ([]*domain.Teacher,
 	return teachers, total, nil
 }
 
+// CountTeachers 获取老师总数
+func (t *TeacherService) CountTeachers() (int, error) {
+	var total int
+	if err := t.db.QueryRow("SELECT COUNT(*) FROM teachers").Scan(&total); err != nil {
+		logger.WithError(err).Error("Failed to count teachers")
+		return 0, fmt.Errorf("failed to count teachers: %v", err)
+	}
+	return total, nil
+}
+
+// EachTeacher 逐个遍历全部老师（包含任教科目名称），用于导出
+func (t *TeacherService) EachTeacher(fn func(*domain.Teacher) error) error {
+	query := `
+		SELECT t.id, t.name, t.age, t.gender, t.email, t.phone, COALESCE(t.subject_id, 0), COALESCE(s.name, ''), t.title, t.department, t.created_at, t.updated_at
+		FROM teachers t
+		LEFT JOIN subjects s ON t.subject_id = s.id
+		ORDER BY t.id
+	`
+
+	rows, err := t.db.Query(query)
+	if err != nil {
+		logger.WithError(err).Error("Failed to query teachers for iteration")
+		return fmt.Errorf("failed to query teachers: %v", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		teacher := &domain.Teacher{}
+		err := rows.Scan(
+			&teacher.ID, &teacher.Name, &teacher.Age, &teacher.Gender,
+			&teacher.Email, &teacher.Phone, &teacher.SubjectID, &teacher.SubjectName, &teacher.Title,
+			&teacher.Department, &teacher.CreatedAt, &teacher.UpdatedAt,
+		)
+		if err != nil {
+			logger.WithError(err).Error("Failed to scan teacher row")
+			return fmt.Errorf("failed to scan teacher: %v", err)
+		}
+		if err := fn(teacher); err != nil {
+			return err
+		}
+	}
+
+	if err = rows.Err(); err != nil {
+		return fmt.Errorf("error during rows iteration: %v", err)
+	}
+	return nil
+}
+
 // UpdateTeacher 更新老师信息
 func (t *TeacherService) UpdateTeacher(id int, req domain.UpdateTeacherRequest) (*domain.Teacher, error) {
 	// 构建动态更新查询
@@ -0,0 +1,252 @@
+package service
+
+import (
+	"student-management-system/internal/domain"
+	"student-management-system/internal/repository"
+	"student-management-system/pkg/errors"
+	"student-management-system/pkg/logger"
+)
+
+// TermService 学期服务
+type TermService struct {
+	termRepo repository.TermRepository
+}
+
+// NewTermService 创建学期服务实例
+func NewTermService(termRepo repository.TermRepository) *TermService {
+	return &TermService{termRepo: termRepo}
+}
+
+// CreateTerm 创建学期，学期代码统一保存为 2024-2025-1 格式
+func (s *TermService) CreateTerm(req domain.CreateTermRequest) (*domain.Term, error) {
+	code := domain.NormalizeTermCode(req.Code)
+	logger.WithFields(map[string]interface{}{
+		"code": code,
+	}).Info("Creating term")
+
+	if !domain.IsCanonicalTermCode(code) {
+		return nil, errors.Newf(errors.ErrCodeValidation, "无法识别的学期代码 %s，请使用 2024-2025-1 格式", req.Code)
+	}
+	if req.EndDate.Before(req.StartDate) {
+		return nil, errors.New(errors.ErrCodeValidation, "结束日期不能早于开始日期")
+	}
+
+	existing, err := s.termRepo.GetByCode(code)
+	if err != nil {
+		return nil, err
+	}
+	if existing != nil {
+		return nil, errors.Newf(errors.ErrCodeConflict, "学期 %s 已存在", code)
+	}
+
+	term := &domain.Term{
+		Code:      code,
+		Name:      req.Name,
+		StartDate: req.StartDate,
+		EndDate:   req.EndDate,
+		Status:    req.Status,
+	}
+	if term.Name == "" {
+		term.Name = domain.DefaultTermName(code)
+	}
+	if term.Status == "" {
+		term.Status = domain.TermStatusPlanning
+	}
+
+	if err := s.termRepo.Create(term); err != nil {
+		return nil, err
+	}
+
+	return term, nil
+}
+
+// GetTerm 获取学期
+func (s *TermService) GetTerm(id int) (*domain.Term, error) {
+	term, err := s.termRepo.GetByID(id)
+	if err != nil {
+		logger.WithError(err).WithFields(map[string]interface{}{
+			"term_id": id,
+		}).Error("Failed to get term")
+		return nil, err
+	}
+	if term == nil {
+		return nil, errors.New(errors.ErrCodeNotFound, "学期不存在")
+	}
+	return term, nil
+}
+
+// GetCurrentTerm 获取当前学期
+func (s *TermService) GetCurrentTerm() (*domain.Term, error) {
+	term, err := s.termRepo.GetCurrent()
+	if err != nil {
+		return nil, err
+	}
+	if term == nil {
+		return nil, errors.New(errors.ErrCodeNotFound, "尚未设置当前学期")
+	}
+	return term, nil
+}
+
+// ListTerms 获取学期列表（分页）
+func (s *TermService) ListTerms(req domain.TermListRequest) ([]*domain.Term, int64, error) {
+	if req.Page <= 0 {
+		req.Page = 1
+	}
+	if req.Size <= 0 {
+		req.Size = 10
+	}
+
+	terms, total, err := s.termRepo.List(&req)
+	if err != nil {
+		logger.WithError(err).Error("Failed to list terms")
+		return nil, 0, err
+	}
+	return terms, total, nil
+}
+
+// UpdateTerm 更新学期名称和起止日期，学期代码不可修改
+func (s *TermService) UpdateTerm(id int, req domain.UpdateTermRequest) (*domain.Term, error) {
+	term, err := s.GetTerm(id)
+	if err != nil {
+		return nil, err
+	}
+
+	if req.Name != "" {
+		term.Name = req.Name
+	}
+	if req.StartDate != nil {
+		term.StartDate = *req.StartDate
+	}
+	if req.EndDate != nil {
+		term.EndDate = *req.EndDate
+	}
+	if term.EndDate.Before(term.StartDate) {
+		return nil, errors.New(errors.ErrCodeValidation, "结束日期不能早于开始日期")
+	}
+
+	if err := s.termRepo.Update(term); err != nil {
+		return nil, err
+	}
+	return term, nil
+}
+
+// UpdateTermStatus 推进学期状态。学期结束（closed）后该学期的成绩冻结，不能再录入、修改或删除
+func (s *TermService) UpdateTermStatus(id int, req domain.UpdateTermStatusRequest) (*domain.Term, error) {
+	term, err := s.GetTerm(id)
+	if err != nil {
+		return nil, err
+	}
+
+	if !term.CanTransitionTo(req.Status) {
+		return nil, errors.Newf(errors.ErrCodeConflict, "学期状态不能从 %s 变更为 %s", term.Status, req.Status)
+	}
+
+	if err := s.termRepo.UpdateStatus(id, req.Status); err != nil {
+		return nil, err
+	}
+
+	logger.WithFields(map[string]interface{}{
+		"term_id": id,
+		"code":    term.Code,
+		"from":    term.Status,
+		"to":      req.Status,
+	}).Info("Term status changed")
+
+	return s.GetTerm(id)
+}
+
+// SetCurrentTerm 设为当前学期
+func (s *TermService) SetCurrentTerm(id int) (*domain.Term, error) {
+	term, err := s.GetTerm(id)
+	if err != nil {
+		return nil, err
+	}
+	if term.Status == domain.TermStatusClosed {
+		return nil, errors.New(errors.ErrCodeConflict, "已结束的学期不能设为当前学期")
+	}
+
+	if err := s.termRepo.SetCurrent(id); err != nil {
+		return nil, err
+	}
+	return s.GetTerm(id)
+}
+
+// DeleteTerm 删除没有成绩和开课的学期
+func (s *TermService) DeleteTerm(id int) error {
+	term, err := s.GetTerm(id)
+	if err != nil {
+		return err
+	}
+
+	used, err := s.termRepo.HasReferences(term.Code)
+	if err != nil {
+		return err
+	}
+	if used {
+		return errors.New(errors.ErrCodeConflict, "学期已有成绩或开课，不能删除")
+	}
+
+	return s.termRepo.Delete(id)
+}
+
+// CreateHoliday 添加学期节假日，节假日必须在学期起止日期内
+func (s *TermService) CreateHoliday(termID int, req domain.CreateTermHolidayRequest) (*domain.TermHoliday, error) {
+	term, err := s.GetTerm(termID)
+	if err != nil {
+		return nil, err
+	}
+
+	holiday := &domain.TermHoliday{
+		TermID:    term.ID,
+		Name:      req.Name,
+		StartDate: req.StartDate,
+		EndDate:   req.EndDate,
+	}
+	if holiday.EndDate.IsZero() {
+		holiday.EndDate = holiday.StartDate
+	}
+	if holiday.EndDate.Before(holiday.StartDate) {
+		return nil, errors.New(errors.ErrCodeValidation, "结束日期不能早于开始日期")
+	}
+	if holiday.StartDate.Before(term.StartDate) || holiday.EndDate.After(term.EndDate) {
+		return nil, errors.Newf(errors.ErrCodeValidation, "节假日必须在学期 %s 的起止日期内", term.Code)
+	}
+
+	if err := s.termRepo.CreateHoliday(holiday); err != nil {
+		return nil, err
+	}
+	return holiday, nil
+}
+
+// ListHolidays 获取学期节假日
+func (s *TermService) ListHolidays(termID int) ([]*domain.TermHoliday, error) {
+	if _, err := s.GetTerm(termID); err != nil {
+		return nil, err
+	}
+	return s.termRepo.ListHolidays(termID)
+}
+
+// DeleteHoliday 删除学期节假日
+func (s *TermService) DeleteHoliday(termID, holidayID int) error {
+	holiday, err := s.termRepo.GetHoliday(holidayID)
+	if err != nil {
+		return err
+	}
+	if holiday == nil || holiday.TermID != termID {
+		return errors.New(errors.ErrCodeNotFound, "节假日不存在")
+	}
+	return s.termRepo.DeleteHoliday(holidayID)
+}
+
+// resolveTerm 按规范化后的学期代码查找学期，不存在时返回校验错误
+func resolveTerm(termRepo repository.TermRepository, semester string) (*domain.Term, error) {
+	code := domain.NormalizeTermCode(semester)
+	term, err := termRepo.GetByCode(code)
+	if err != nil {
+		return nil, err
+	}
+	if term == nil {
+		return nil, errors.Newf(errors.ErrCodeValidation, "学期 %s 不存在，请先在学期管理中创建", semester)
+	}
+	return term, nil
+}
@@ -0,0 +1,340 @@
+package service
+
+import (
+	"sort"
+
+	"student-management-system/internal/domain"
+	"student-management-system/pkg/errors"
+	"student-management-system/pkg/logger"
+)
+
+// gridKey 排课占用表的键：某位教师、某间教室或某个行政班在某天某节
+type gridKey struct {
+	kind   string
+	id     int
+	day    int
+	period int
+}
+
+// scheduleGrid 排课占用表
+type scheduleGrid struct {
+	busy    map[gridKey]bool
+	dayLoad map[int]int // 每天已排的节数，用于均衡各天的课量
+}
+
+func newScheduleGrid() *scheduleGrid {
+	return &scheduleGrid{busy: make(map[gridKey]bool), dayLoad: make(map[int]int)}
+}
+
+// occupy 占用时段涉及的教室、教师和行政班
+func (g *scheduleGrid) occupy(slot *domain.TimetableSlot) {
+	for p := slot.StartPeriod; p <= slot.EndPeriod; p++ {
+		g.busy[gridKey{domain.TimetableConflictRoom, slot.RoomID, slot.DayOfWeek, p}] = true
+		if slot.TeacherID != nil {
+			g.busy[gridKey{domain.TimetableConflictTeacher, *slot.TeacherID, slot.DayOfWeek, p}] = true
+		}
+		if slot.ClassID != nil {
+			g.busy[gridKey{domain.TimetableConflictClass, *slot.ClassID, slot.DayOfWeek, p}] = true
+		}
+	}
+	g.dayLoad[slot.DayOfWeek] += slot.EndPeriod - slot.StartPeriod + 1
+}
+
+// free 检查对象在某天的连续节次内是否空闲，id 为空表示不受约束
+func (g *scheduleGrid) free(kind string, id *int, day, start, end int) bool {
+	if id == nil {
+		return true
+	}
+	for p := start; p <= end; p++ {
+		if g.busy[gridKey{kind, *id, day, p}] {
+			return false
+		}
+	}
+	return true
+}
+
+// scheduleItem 一门待排的开课
+type scheduleItem struct {
+	course   domain.AutoScheduleCourse
+	offering *domain.CourseOffering
+	rooms    []*domain.Room // 可用教室，按容量从小到大
+	usedDays map[int]bool   // 已安排的星期，同一门课尽量分散在不同的天
+}
+
+// AutoSchedule 自动排课：在已有课表的基础上，按教师、教室和行政班均不冲突的原则为各开课生成每周时段。
+// 采用贪心策略，先排指定教室和总节数多的开课，同一门课尽量分散到不同的天，教室优先选择容量最小的。
+// 未保存时只返回草稿
+func (s *TimetableService) AutoSchedule(actor *domain.JWTClaims, req domain.AutoScheduleRequest) (*domain.AutoScheduleResult, error) {
+	logger.WithFields(map[string]interface{}{
+		"semester": req.Semester,
+		"courses":  len(req.Courses),
+		"save":     req.Save,
+		"admin_id": actor.AdminID,
+	}).Info("Auto scheduling timetable")
+
+	term, err := s.writableTerm(req.Semester)
+	if err != nil {
+		return nil, err
+	}
+
+	days := uniqueInts(req.Days)
+	if len(days) == 0 {
+		for d := 1; d <= s.daysPerWeek; d++ {
+			days = append(days, d)
+		}
+	}
+	sort.Ints(days)
+	blocked := make(map[int]bool)
+	for _, p := range req.BlockedPeriods {
+		blocked[p] = true
+	}
+
+	existing, err := s.timetableRepo.ListBySemester(term.Code)
+	if err != nil {
+		logger.WithError(err).Error("Failed to list timetable slots")
+		return nil, err
+	}
+	grid := newScheduleGrid()
+	usedDays := make(map[int]map[int]bool)
+	for _, slot := range existing {
+		grid.occupy(slot)
+		if usedDays[slot.OfferingID] == nil {
+			usedDays[slot.OfferingID] = make(map[int]bool)
+		}
+		usedDays[slot.OfferingID][slot.DayOfWeek] = true
+	}
+
+	rooms, err := s.roomRepo.ListActive()
+	if err != nil {
+		return nil, err
+	}
+
+	items, err := s.prepareSchedule(term.Code, req.Courses, rooms)
+	if err != nil {
+		return nil, err
+	}
+	for _, item := range items {
+		item.usedDays = usedDays[item.offering.ID]
+		if item.usedDays == nil {
+			item.usedDays = make(map[int]bool)
+		}
+	}
+
+	result := &domain.AutoScheduleResult{
+		Semester:    term.Code,
+		Slots:       []domain.TimetableSlot{},
+		Unscheduled: []domain.UnscheduledSession{},
+	}
+	var placed []*domain.TimetableSlot
+	for _, item := range items {
+		count := 0
+		for ; count < item.course.SessionsPerWeek; count++ {
+			slot := s.placeSession(grid, item, days, blocked)
+			if slot == nil {
+				break
+			}
+			grid.occupy(slot)
+			item.usedDays[slot.DayOfWeek] = true
+			placed = append(placed, slot)
+		}
+
+		if count < item.course.SessionsPerWeek {
+			reason := "没有满足约束的空闲时间"
+			if len(item.rooms) == 0 {
+				reason = "没有容量和类型满足要求的可用教室"
+			}
+			result.Unscheduled = append(result.Unscheduled, domain.UnscheduledSession{
+				OfferingID:  item.offering.ID,
+				SubjectName: item.offering.SubjectName,
+				Section:     item.offering.Section,
+				Sessions:    item.course.SessionsPerWeek - count,
+				Reason:      reason,
+			})
+		}
+	}
+
+	if req.Save && len(placed) > 0 {
+		if actor.AdminID != 0 {
+			createdBy := actor.AdminID
+			for _, slot := range placed {
+				slot.CreatedBy = &createdBy
+			}
+		}
+		if err := s.save(placed...); err != nil {
+			return nil, err
+		}
+		result.Saved = true
+	}
+
+	for _, slot := range placed {
+		s.fillTimes(slot)
+		result.Slots = append(result.Slots, *slot)
+	}
+
+	logger.WithFields(map[string]interface{}{
+		"semester":    term.Code,
+		"slots":       len(result.Slots),
+		"unscheduled": len(result.Unscheduled),
+		"saved":       result.Saved,
+	}).Info("Auto scheduling completed")
+
+	return result, nil
+}
+
+// prepareSchedule 校验待排的开课并按排课难度排序
+func (s *TimetableService) prepareSchedule(semester string, courses []domain.AutoScheduleCourse, rooms []*domain.Room) ([]*scheduleItem, error) {
+	seen := make(map[int]bool)
+	items := make([]*scheduleItem, 0, len(courses))
+	for _, course := range courses {
+		if seen[course.OfferingID] {
+			return nil, errors.Newf(errors.ErrCodeValidation, "开课 %d 重复", course.OfferingID)
+		}
+		seen[course.OfferingID] = true
+
+		if course.PeriodsPerSession == 0 {
+			course.PeriodsPerSession = 2
+		}
+		if course.PeriodsPerSession > len(s.periods) {
+			return nil, errors.Newf(errors.ErrCodeValidation, "每天只有 %d 节课", len(s.periods))
+		}
+
+		offering, err := s.offeringRepo.GetByID(course.OfferingID)
+		if err != nil {
+			return nil, err
+		}
+		if offering == nil {
+			return nil, errors.Newf(errors.ErrCodeNotFound, "开课 %d 不存在", course.OfferingID)
+		}
+		if offering.Semester != semester {
+			return nil, errors.Newf(errors.ErrCodeValidation, "开课 %d 不属于学期 %s", course.OfferingID, semester)
+		}
+		if offering.Status == domain.OfferingStatusCancelled {
+			return nil, errors.Newf(errors.ErrCodeConflict, "开课 %d 已取消，不能排课", course.OfferingID)
+		}
+
+		if course.ClassID != nil {
+			class, err := s.classRepo.GetByID(*course.ClassID)
+			if err != nil {
+				return nil, err
+			}
+			if class == nil {
+				return nil, errors.Newf(errors.ErrCodeValidation, "班级 %d 不存在", *course.ClassID)
+			}
+		}
+
+		item := &scheduleItem{course: course, offering: offering}
+		if course.RoomID != nil {
+			room, err := s.roomRepo.GetByID(*course.RoomID)
+			if err != nil {
+				return nil, err
+			}
+			if room == nil {
+				return nil, errors.Newf(errors.ErrCodeValidation, "教室 %d 不存在", *course.RoomID)
+			}
+			if room.Status != domain.RoomStatusActive {
+				return nil, errors.Newf(errors.ErrCodeConflict, "教室 %s 已停用", room.Code)
+			}
+			if room.Capacity < offering.Capacity {
+				return nil, errors.Newf(errors.ErrCodeValidation, "教室 %s 容量 %d 小于开课容量 %d", room.Code, room.Capacity, offering.Capacity)
+			}
+			item.rooms = []*domain.Room{room}
+		} else {
+			for _, room := range rooms {
+				if room.Capacity < offering.Capacity {
+					continue
+				}
+				if course.RoomType != "" && room.RoomType != course.RoomType {
+					continue
+				}
+				if course.RoomType == "" && room.RoomType != domain.RoomTypeClassroom && room.RoomType != domain.RoomTypeLectureHall {
+					continue
+				}
+				item.rooms = append(item.rooms, room)
+			}
+		}
+		items = append(items, item)
+	}
+
+	sort.SliceStable(items, func(i, j int) bool {
+		fixedI, fixedJ := items[i].course.RoomID != nil, items[j].course.RoomID != nil
+		if fixedI != fixedJ {
+			return fixedI
+		}
+		totalI := items[i].course.SessionsPerWeek * items[i].course.PeriodsPerSession
+		totalJ := items[j].course.SessionsPerWeek * items[j].course.PeriodsPerSession
+		if totalI != totalJ {
+			return totalI > totalJ
+		}
+		return items[i].offering.ID < items[j].offering.ID
+	})
+
+	return items, nil
+}
+
+// placeSession 为开课安排一次课，找不到空闲时间时返回 nil
+func (s *TimetableService) placeSession(grid *scheduleGrid, item *scheduleItem, days []int, blocked map[int]bool) *domain.TimetableSlot {
+	if len(item.rooms) == 0 {
+		return nil
+	}
+
+	// 优先安排本门课还没有上课的天，其次是课量较少的天
+	ordered := append([]int(nil), days...)
+	sort.SliceStable(ordered, func(i, j int) bool {
+		usedI, usedJ := item.usedDays[ordered[i]], item.usedDays[ordered[j]]
+		if usedI != usedJ {
+			return !usedI
+		}
+		return grid.dayLoad[ordered[i]] < grid.dayLoad[ordered[j]]
+	})
+
+	length := item.course.PeriodsPerSession
+	for _, day := range ordered {
+		for start := 1; start+length-1 <= len(s.periods); start++ {
+			end := start + length - 1
+			if !s.blockAllowed(start, end, blocked) {
+				continue
+			}
+			if !grid.free(domain.TimetableConflictTeacher, item.offering.TeacherID, day, start, end) ||
+				!grid.free(domain.TimetableConflictClass, item.course.ClassID, day, start, end) {
+				continue
+			}
+			for _, room := range item.rooms {
+				roomID := room.ID
+				if !grid.free(domain.TimetableConflictRoom, &roomID, day, start, end) {
+					continue
+				}
+				return &domain.TimetableSlot{
+					OfferingID:  item.offering.ID,
+					RoomID:      room.ID,
+					TeacherID:   item.offering.TeacherID,
+					ClassID:     item.course.ClassID,
+					DayOfWeek:   day,
+					StartPeriod: start,
+					EndPeriod:   end,
+					Semester:    item.offering.Semester,
+					SubjectID:   item.offering.SubjectID,
+					SubjectCode: item.offering.SubjectCode,
+					SubjectName: item.offering.SubjectName,
+					Section:     item.offering.Section,
+					RoomCode:    room.Code,
+					RoomName:    room.Name,
+					TeacherName: item.offering.TeacherName,
+				}
+			}
+		}
+	}
+	return nil
+}
+
+// blockAllowed 连续节次中不能包含不排课的节次，也不能跨越午休等长间隔
+func (s *TimetableService) blockAllowed(start, end int, blocked map[int]bool) bool {
+	for p := start; p <= end; p++ {
+		if blocked[p] {
+			return false
+		}
+		if p < end && s.breakAfter[p] {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,489 @@
+package service
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"student-management-system/internal/config"
+	"student-management-system/internal/domain"
+	"student-management-system/internal/repository"
+	"student-management-system/pkg/errors"
+	"student-management-system/pkg/logger"
+)
+
+// longBreak 两节课之间超过该间隔（如午休）时，连上的课不能跨越
+const longBreak = 30 * time.Minute
+
+// TimetableService 课表服务：维护课表时段并检查教师、教室和行政班的时间冲突，提供各类周课表和自动排课
+type TimetableService struct {
+	timetableRepo repository.TimetableRepository
+	roomRepo      repository.RoomRepository
+	offeringRepo  repository.CourseOfferingRepository
+	classRepo     repository.ClassRepository
+	studentRepo   repository.StudentRepository
+	termRepo      repository.TermRepository
+	periods       []domain.Period
+	breakAfter    map[int]bool // 与下一节之间为长间隔的节次
+	daysPerWeek   int
+}
+
+// NewTimetableService 创建课表服务实例，校验节次配置
+func NewTimetableService(cfg config.TimetableConfig, timetableRepo repository.TimetableRepository, roomRepo repository.RoomRepository, offeringRepo repository.CourseOfferingRepository, classRepo repository.ClassRepository, studentRepo repository.StudentRepository, termRepo repository.TermRepository) (*TimetableService, error) {
+	if cfg.DaysPerWeek < 1 || cfg.DaysPerWeek > 7 {
+		return nil, fmt.Errorf("timetable days_per_week must be between 1 and 7, got %d", cfg.DaysPerWeek)
+	}
+	if len(cfg.Periods) == 0 {
+		return nil, fmt.Errorf("timetable periods must not be empty")
+	}
+
+	s := &TimetableService{
+		timetableRepo: timetableRepo,
+		roomRepo:      roomRepo,
+		offeringRepo:  offeringRepo,
+		classRepo:     classRepo,
+		studentRepo:   studentRepo,
+		termRepo:      termRepo,
+		breakAfter:    make(map[int]bool),
+		daysPerWeek:   cfg.DaysPerWeek,
+	}
+
+	var previousEnd time.Time
+	for i, def := range cfg.Periods {
+		start, err := time.Parse("15:04", def.Start)
+		if err != nil {
+			return nil, fmt.Errorf("invalid start time %q of period %d: %w", def.Start, i+1, err)
+		}
+		end, err := time.Parse("15:04", def.End)
+		if err != nil {
+			return nil, fmt.Errorf("invalid end time %q of period %d: %w", def.End, i+1, err)
+		}
+		if !end.After(start) {
+			return nil, fmt.Errorf("period %d must end after it starts", i+1)
+		}
+		if i > 0 {
+			if start.Before(previousEnd) {
+				return nil, fmt.Errorf("period %d starts before period %d ends", i+1, i)
+			}
+			if start.Sub(previousEnd) > longBreak {
+				s.breakAfter[i] = true
+			}
+		}
+		previousEnd = end
+
+		s.periods = append(s.periods, domain.Period{
+			Number: i + 1,
+			Start:  start.Format("15:04"),
+			End:    end.Format("15:04"),
+		})
+	}
+
+	return s, nil
+}
+
+// GetPeriods 获取每天的节次及上下课时间
+func (s *TimetableService) GetPeriods() []domain.Period {
+	return s.periods
+}
+
+// CreateSlot 创建课表时段，教师默认为开课的任课老师
+func (s *TimetableService) CreateSlot(actor *domain.JWTClaims, req domain.CreateTimetableSlotRequest) (*domain.TimetableSlot, error) {
+	logger.WithFields(map[string]interface{}{
+		"offering_id": req.OfferingID,
+		"room_id":     req.RoomID,
+		"day_of_week": req.DayOfWeek,
+		"admin_id":    actor.AdminID,
+	}).Info("Creating timetable slot")
+
+	offering, err := s.getSchedulableOffering(req.OfferingID)
+	if err != nil {
+		return nil, err
+	}
+
+	slot := &domain.TimetableSlot{
+		OfferingID:  offering.ID,
+		RoomID:      req.RoomID,
+		TeacherID:   req.TeacherID,
+		ClassID:     req.ClassID,
+		DayOfWeek:   req.DayOfWeek,
+		StartPeriod: req.StartPeriod,
+		EndPeriod:   req.EndPeriod,
+		Note:        req.Note,
+	}
+	if slot.TeacherID == nil {
+		slot.TeacherID = offering.TeacherID
+	}
+	if slot.EndPeriod == 0 {
+		slot.EndPeriod = slot.StartPeriod
+	}
+	if actor.AdminID != 0 {
+		createdBy := actor.AdminID
+		slot.CreatedBy = &createdBy
+	}
+
+	if err := s.validateSlot(slot, offering); err != nil {
+		return nil, err
+	}
+	if err := s.save(slot); err != nil {
+		return nil, err
+	}
+
+	logger.WithFields(map[string]interface{}{
+		"slot_id":     slot.ID,
+		"offering_id": slot.OfferingID,
+	}).Info("Timetable slot created successfully")
+
+	return s.GetSlot(slot.ID)
+}
+
+// GetSlot 获取课表时段
+func (s *TimetableService) GetSlot(id int) (*domain.TimetableSlot, error) {
+	slot, err := s.timetableRepo.GetByID(id)
+	if err != nil {
+		logger.WithError(err).WithFields(map[string]interface{}{
+			"slot_id": id,
+		}).Error("Failed to get timetable slot")
+		return nil, err
+	}
+	if slot == nil {
+		return nil, errors.New(errors.ErrCodeNotFound, "课表时段不存在")
+	}
+	s.fillTimes(slot)
+	return slot, nil
+}
+
+// ListSlots 获取课表时段列表（分页）
+func (s *TimetableService) ListSlots(actor *domain.JWTClaims, req domain.TimetableSlotListRequest) ([]*domain.TimetableSlot, int64, error) {
+	if actor.IsStudentScoped() {
+		return nil, 0, errors.ErrForbidden
+	}
+	if req.Page <= 0 {
+		req.Page = 1
+	}
+	if req.Size <= 0 {
+		req.Size = 10
+	}
+	req.Semester = domain.NormalizeTermCode(req.Semester)
+
+	slots, total, err := s.timetableRepo.List(&req)
+	if err != nil {
+		logger.WithError(err).Error("Failed to list timetable slots")
+		return nil, 0, err
+	}
+	for _, slot := range slots {
+		s.fillTimes(slot)
+	}
+	return slots, total, nil
+}
+
+// UpdateSlot 更新课表时段，调整后重新检查时间冲突
+func (s *TimetableService) UpdateSlot(id int, req domain.UpdateTimetableSlotRequest) (*domain.TimetableSlot, error) {
+	logger.WithFields(map[string]interface{}{
+		"slot_id": id,
+	}).Info("Updating timetable slot")
+
+	slot, err := s.GetSlot(id)
+	if err != nil {
+		return nil, err
+	}
+	offering, err := s.getSchedulableOffering(slot.OfferingID)
+	if err != nil {
+		return nil, err
+	}
+
+	if req.RoomID > 0 {
+		slot.RoomID = req.RoomID
+	}
+	if req.TeacherID != nil {
+		if *req.TeacherID == 0 {
+			slot.TeacherID = nil
+		} else {
+			slot.TeacherID = req.TeacherID
+		}
+	}
+	if req.ClassID != nil {
+		if *req.ClassID == 0 {
+			slot.ClassID = nil
+		} else {
+			slot.ClassID = req.ClassID
+		}
+	}
+	if req.DayOfWeek > 0 {
+		slot.DayOfWeek = req.DayOfWeek
+	}
+	// 只修改开始节次时保持原有节数
+	if req.StartPeriod > 0 {
+		length := slot.EndPeriod - slot.StartPeriod
+		slot.StartPeriod = req.StartPeriod
+		slot.EndPeriod = req.StartPeriod + length
+	}
+	if req.EndPeriod > 0 {
+		slot.EndPeriod = req.EndPeriod
+	}
+	if req.Note != nil {
+		slot.Note = *req.Note
+	}
+
+	if err := s.validateSlot(slot, offering); err != nil {
+		return nil, err
+	}
+	if err := s.save(slot); err != nil {
+		return nil, err
+	}
+
+	return s.GetSlot(id)
+}
+
+// DeleteSlot 删除课表时段
+func (s *TimetableService) DeleteSlot(id int) error {
+	logger.WithFields(map[string]interface{}{
+		"slot_id": id,
+	}).Info("Deleting timetable slot")
+
+	slot, err := s.GetSlot(id)
+	if err != nil {
+		return err
+	}
+	if _, err := s.writableTerm(slot.Semester); err != nil {
+		return err
+	}
+	return s.timetableRepo.Delete(id)
+}
+
+// GetTeacherTimetable 获取教师的周课表
+func (s *TimetableService) GetTeacherTimetable(actor *domain.JWTClaims, teacherID int, req domain.TimetableRequest) (*domain.Timetable, error) {
+	if actor.IsStudentScoped() {
+		return nil, errors.ErrForbidden
+	}
+	ok, err := s.offeringRepo.TeacherExists(teacherID)
+	if err != nil {
+		return nil, err
+	}
+	if !ok {
+		return nil, errors.New(errors.ErrCodeNotFound, "教师不存在")
+	}
+
+	timetable, err := s.buildTimetable(domain.TimetableOwnerTeacher, teacherID, req.Semester)
+	if err != nil {
+		return nil, err
+	}
+	if len(timetable.Slots) > 0 {
+		timetable.OwnerName = timetable.Slots[0].TeacherName
+	}
+	return timetable, nil
+}
+
+// GetClassTimetable 获取行政班的周课表
+func (s *TimetableService) GetClassTimetable(actor *domain.JWTClaims, classID int, req domain.TimetableRequest) (*domain.Timetable, error) {
+	if actor.IsStudentScoped() {
+		return nil, errors.ErrForbidden
+	}
+	class, err := s.classRepo.GetByID(classID)
+	if err != nil {
+		return nil, err
+	}
+	if class == nil {
+		return nil, errors.New(errors.ErrCodeNotFound, "班级不存在")
+	}
+
+	timetable, err := s.buildTimetable(domain.TimetableOwnerClass, classID, req.Semester)
+	if err != nil {
+		return nil, err
+	}
+	timetable.OwnerName = class.Name
+	return timetable, nil
+}
+
+// GetStudentTimetable 获取学生的周课表，包括已选上的开课和所在行政班的课程。学生和家长只能查看本人（子女）的课表
+func (s *TimetableService) GetStudentTimetable(actor *domain.JWTClaims, studentID int, req domain.TimetableRequest) (*domain.Timetable, error) {
+	if !actor.CanAccessStudent(studentID) {
+		return nil, errors.ErrForbidden
+	}
+	student, err := s.studentRepo.GetByID(studentID)
+	if err != nil {
+		return nil, err
+	}
+	if student == nil {
+		return nil, errors.Newf(errors.ErrCodeNotFound, "学生 %d 不存在", studentID)
+	}
+
+	timetable, err := s.buildTimetable(domain.TimetableOwnerStudent, studentID, req.Semester)
+	if err != nil {
+		return nil, err
+	}
+	timetable.OwnerName = student.Name
+	return timetable, nil
+}
+
+// GetRoomTimetable 获取教室的周课表
+func (s *TimetableService) GetRoomTimetable(actor *domain.JWTClaims, roomID int, req domain.TimetableRequest) (*domain.Timetable, error) {
+	if actor.IsStudentScoped() {
+		return nil, errors.ErrForbidden
+	}
+	room, err := s.roomRepo.GetByID(roomID)
+	if err != nil {
+		return nil, err
+	}
+	if room == nil {
+		return nil, errors.New(errors.ErrCodeNotFound, "教室不存在")
+	}
+
+	timetable, err := s.buildTimetable(domain.TimetableOwnerRoom, roomID, req.Semester)
+	if err != nil {
+		return nil, err
+	}
+	timetable.OwnerName = room.Code
+	return timetable, nil
+}
+
+// buildTimetable 查询学期内的课表时段，学期为空时使用当前学期
+func (s *TimetableService) buildTimetable(ownerType string, ownerID int, semester string) (*domain.Timetable, error) {
+	var term *domain.Term
+	var err error
+	if semester == "" {
+		term, err = s.termRepo.GetCurrent()
+		if err != nil {
+			return nil, err
+		}
+		if term == nil {
+			return nil, errors.New(errors.ErrCodeValidation, "尚未设置当前学期，请指定学期")
+		}
+	} else if term, err = resolveTerm(s.termRepo, semester); err != nil {
+		return nil, err
+	}
+
+	slots, err := s.timetableRepo.ListForOwner(term.Code, ownerType, ownerID)
+	if err != nil {
+		logger.WithError(err).WithFields(map[string]interface{}{
+			"owner_type": ownerType,
+			"owner_id":   ownerID,
+		}).Error("Failed to list timetable slots")
+		return nil, err
+	}
+
+	timetable := &domain.Timetable{
+		Semester:  term.Code,
+		OwnerType: ownerType,
+		OwnerID:   ownerID,
+		Periods:   s.periods,
+		Slots:     make([]domain.TimetableSlot, 0, len(slots)),
+	}
+	for _, slot := range slots {
+		s.fillTimes(slot)
+		timetable.Slots = append(timetable.Slots, *slot)
+	}
+	return timetable, nil
+}
+
+// save 保存课表时段，存在时间冲突时返回冲突错误
+func (s *TimetableService) save(slots ...*domain.TimetableSlot) error {
+	conflicts, err := s.timetableRepo.Save(slots)
+	if err != nil {
+		return err
+	}
+	if len(conflicts) > 0 {
+		return conflictError(conflicts)
+	}
+	return nil
+}
+
+// validateSlot 校验时段的节次、教室、教师和行政班
+func (s *TimetableService) validateSlot(slot *domain.TimetableSlot, offering *domain.CourseOffering) error {
+	if slot.DayOfWeek > s.daysPerWeek {
+		logger.WithFields(map[string]interface{}{
+			"offering_id": slot.OfferingID,
+			"day_of_week": slot.DayOfWeek,
+		}).Warn("Scheduling outside configured school days")
+	}
+	if slot.EndPeriod < slot.StartPeriod {
+		return errors.New(errors.ErrCodeValidation, "结束节次不能早于开始节次")
+	}
+	if slot.EndPeriod > len(s.periods) {
+		return errors.Newf(errors.ErrCodeValidation, "每天只有 %d 节课", len(s.periods))
+	}
+
+	room, err := s.roomRepo.GetByID(slot.RoomID)
+	if err != nil {
+		return err
+	}
+	if room == nil {
+		return errors.Newf(errors.ErrCodeValidation, "教室 %d 不存在", slot.RoomID)
+	}
+	if room.Status != domain.RoomStatusActive {
+		return errors.Newf(errors.ErrCodeConflict, "教室 %s 已停用", room.Code)
+	}
+	if room.Capacity < offering.Capacity {
+		return errors.Newf(errors.ErrCodeValidation, "教室 %s 容量 %d 小于开课容量 %d", room.Code, room.Capacity, offering.Capacity)
+	}
+
+	if slot.TeacherID != nil {
+		ok, err := s.offeringRepo.TeacherExists(*slot.TeacherID)
+		if err != nil {
+			return err
+		}
+		if !ok {
+			return errors.Newf(errors.ErrCodeValidation, "教师 %d 不存在", *slot.TeacherID)
+		}
+	}
+
+	if slot.ClassID != nil {
+		class, err := s.classRepo.GetByID(*slot.ClassID)
+		if err != nil {
+			return err
+		}
+		if class == nil {
+			return errors.Newf(errors.ErrCodeValidation, "班级 %d 不存在", *slot.ClassID)
+		}
+	}
+
+	return nil
+}
+
+// getSchedulableOffering 获取可以排课的开课：开课未取消且所在学期未结束
+func (s *TimetableService) getSchedulableOffering(offeringID int) (*domain.CourseOffering, error) {
+	offering, err := s.offeringRepo.GetByID(offeringID)
+	if err != nil {
+		return nil, err
+	}
+	if offering == nil {
+		return nil, errors.Newf(errors.ErrCodeNotFound, "开课 %d 不存在", offeringID)
+	}
+	if offering.Status == domain.OfferingStatusCancelled {
+		return nil, errors.Newf(errors.ErrCodeConflict, "开课 %d 已取消，不能排课", offeringID)
+	}
+	if _, err := s.writableTerm(offering.Semester); err != nil {
+		return nil, err
+	}
+	return offering, nil
+}
+
+// writableTerm 获取学期，学期已结束时课表冻结
+func (s *TimetableService) writableTerm(semester string) (*domain.Term, error) {
+	term, err := resolveTerm(s.termRepo, semester)
+	if err != nil {
+		return nil, err
+	}
+	if term.Status == domain.TermStatusClosed {
+		return nil, errors.Newf(errors.ErrCodeConflict, "学期 %s 已结束，课表已冻结", term.Code)
+	}
+	return term, nil
+}
+
+// fillTimes 按节次配置填写上下课时间
+func (s *TimetableService) fillTimes(slot *domain.TimetableSlot) {
+	if slot.StartPeriod >= 1 && slot.StartPeriod <= len(s.periods) {
+		slot.StartTime = s.periods[slot.StartPeriod-1].Start
+	}
+	if slot.EndPeriod >= 1 && slot.EndPeriod <= len(s.periods) {
+		slot.EndTime = s.periods[slot.EndPeriod-1].End
+	}
+}
+
+// conflictError 将时间冲突列表转换为冲突错误
+func conflictError(conflicts []*domain.TimetableConflict) error {
+	messages := make([]string, 0, len(conflicts))
+	for _, c := range conflicts {
+		messages = append(messages, c.Message())
+	}
+	return errors.New(errors.ErrCodeConflict, "时间冲突："+strings.Join(messages, "；"))
+}
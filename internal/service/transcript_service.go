@@ -0,0 +1,516 @@
+package service
+
+import (
+	"bytes"
+	"crypto/rand"
+	"crypto/sha256"
+	"embed"
+	"encoding/hex"
+	"fmt"
+	"math"
+	"math/big"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"text/template"
+	"time"
+
+	"student-management-system/internal/config"
+	"student-management-system/internal/domain"
+	"student-management-system/internal/repository"
+	"student-management-system/pkg/errors"
+	"student-management-system/pkg/logger"
+	"student-management-system/pkg/pdf"
+)
+
+// builtinTranscriptTemplates 内置成绩单模板
+//
+//go:embed templates/*.tmpl
+var builtinTranscriptTemplates embed.FS
+
+// 成绩单模板文件名，自定义模板目录中的同名文件会覆盖内置模板
+var transcriptTemplateFiles = map[string]string{
+	domain.TranscriptKindTranscript: "transcript.tmpl",
+	domain.TranscriptKindReportCard: "report_card.tmpl",
+}
+
+// verificationCodeAlphabet 验证码字符集，去掉了容易混淆的 0、O、1、I、L
+const verificationCodeAlphabet = "23456789ABCDEFGHJKMNPQRSTUVWXYZ"
+
+// TranscriptDocument 生成的成绩单文件
+type TranscriptDocument struct {
+	FileName   string
+	Content    []byte
+	Transcript *domain.Transcript
+}
+
+// TranscriptService 成绩单服务
+type TranscriptService struct {
+	cfg            config.TranscriptConfig
+	transcriptRepo repository.TranscriptRepository
+	studentRepo    repository.StudentRepository
+	gradingRepo    repository.GradingRepository
+	scales         *GradeScaleService
+	templates      map[string]*template.Template
+}
+
+// NewTranscriptService 创建成绩单服务实例，加载内置模板及自定义模板目录中的覆盖模板
+func NewTranscriptService(cfg config.TranscriptConfig, transcriptRepo repository.TranscriptRepository, studentRepo repository.StudentRepository, gradingRepo repository.GradingRepository, scales *GradeScaleService) (*TranscriptService, error) {
+	s := &TranscriptService{
+		cfg:            cfg,
+		transcriptRepo: transcriptRepo,
+		studentRepo:    studentRepo,
+		gradingRepo:    gradingRepo,
+		scales:         scales,
+		templates:      make(map[string]*template.Template),
+	}
+
+	for kind, name := range transcriptTemplateFiles {
+		tmpl, err := loadTranscriptTemplate(cfg.TemplateDir, name)
+		if err != nil {
+			return nil, err
+		}
+		s.templates[kind] = tmpl
+	}
+
+	return s, nil
+}
+
+// GenerateTranscript 生成学生历年成绩单，scale 为空时使用学生专业的绩点制
+func (s *TranscriptService) GenerateTranscript(actor *domain.JWTClaims, studentID int, scale string) (*TranscriptDocument, error) {
+	return s.generate(actor, studentID, domain.TranscriptKindTranscript, "", scale)
+}
+
+// GenerateReportCard 生成学生学期成绩报告单，scale 为空时使用学生专业的绩点制
+func (s *TranscriptService) GenerateReportCard(actor *domain.JWTClaims, studentID int, semester, scale string) (*TranscriptDocument, error) {
+	if semester == "" {
+		return nil, errors.New(errors.ErrCodeValidation, "学期成绩报告单必须指定学期")
+	}
+	return s.generate(actor, studentID, domain.TranscriptKindReportCard, domain.NormalizeTermCode(semester), scale)
+}
+
+// Verify 根据验证码核验成绩单，无需登录
+func (s *TranscriptService) Verify(code string) (*domain.TranscriptVerificationResult, error) {
+	code = normalizeVerificationCode(code)
+	if code == "" {
+		return nil, errors.New(errors.ErrCodeNotFound, "验证码不存在")
+	}
+
+	v, err := s.transcriptRepo.GetVerification(code)
+	if err != nil {
+		logger.WithError(err).WithFields(map[string]interface{}{
+			"code": code,
+		}).Error("Failed to get transcript verification")
+		return nil, fmt.Errorf("failed to verify transcript: %w", err)
+	}
+	if v == nil {
+		return nil, errors.New(errors.ErrCodeNotFound, "验证码不存在")
+	}
+
+	student, err := s.studentRepo.GetByID(v.StudentID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get student: %w", err)
+	}
+	if student == nil {
+		return nil, errors.New(errors.ErrCodeNotFound, "验证码不存在")
+	}
+
+	return &domain.TranscriptVerificationResult{
+		Valid:         true,
+		Code:          v.Code,
+		Kind:          v.Kind,
+		StudentNumber: student.StudentID,
+		StudentName:   student.Name,
+		Major:         student.Major,
+		Semester:      v.Semester,
+		TotalCredits:  v.TotalCredits,
+		CumulativeGPA: v.CumulativeGPA,
+		GradeScale:    v.GradeScale,
+		Checksum:      v.Checksum,
+		IssuedAt:      v.IssuedAt,
+	}, nil
+}
+
+// generate 汇总成绩、登记验证码并渲染PDF
+func (s *TranscriptService) generate(actor *domain.JWTClaims, studentID int, kind, semester, scaleName string) (*TranscriptDocument, error) {
+	logger.WithFields(map[string]interface{}{
+		"student_id": studentID,
+		"kind":       kind,
+		"semester":   semester,
+	}).Info("Generating transcript")
+
+	if !actor.CanAccessStudent(studentID) {
+		return nil, errors.ErrForbidden
+	}
+
+	student, err := s.studentRepo.GetByID(studentID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get student: %w", err)
+	}
+	if student == nil {
+		return nil, errors.Newf(errors.ErrCodeNotFound, "学生 %d 不存在", studentID)
+	}
+
+	scale, err := s.scales.ResolveForStudent(studentID, scaleName)
+	if err != nil {
+		return nil, err
+	}
+
+	scores, err := s.transcriptRepo.ListStudentScores(studentID)
+	if err != nil {
+		logger.WithError(err).WithFields(map[string]interface{}{
+			"student_id": studentID,
+		}).Error("Failed to list transcript scores")
+		return nil, err
+	}
+
+	grades, err := s.gradingRepo.ListStudentFinalGrades(studentID, "", true)
+	if err != nil {
+		logger.WithError(err).WithFields(map[string]interface{}{
+			"student_id": studentID,
+		}).Error("Failed to list transcript final grades")
+		return nil, err
+	}
+
+	code, err := newVerificationCode()
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate verification code: %w", err)
+	}
+
+	transcript := buildTranscript(kind, semester, scale, scores, grades)
+	transcript.School = domain.TranscriptSchool{
+		Name:    s.cfg.SchoolName,
+		NameEN:  s.cfg.SchoolNameEN,
+		Address: s.cfg.Address,
+		Phone:   s.cfg.Phone,
+		Issuer:  s.cfg.Issuer,
+	}
+	transcript.Student = student
+	transcript.VerificationCode = code
+	transcript.VerifyURL = s.cfg.VerifyURL + code
+	transcript.IssuedAt = time.Now().Truncate(time.Second)
+	transcript.Checksum = transcriptChecksum(transcript)
+
+	content, err := s.render(transcript)
+	if err != nil {
+		logger.WithError(err).WithFields(map[string]interface{}{
+			"student_id": studentID,
+			"kind":       kind,
+		}).Error("Failed to render transcript")
+		return nil, fmt.Errorf("failed to render transcript: %w", err)
+	}
+
+	// 渲染成功后再登记，避免留下没有对应文件的验证码
+	verification := &domain.TranscriptVerification{
+		Code:          code,
+		Kind:          kind,
+		StudentID:     studentID,
+		Semester:      semester,
+		TotalCredits:  transcript.TotalCredits,
+		CumulativeGPA: transcript.CumulativeGPA,
+		GradeScale:    transcript.GradeScale,
+		Checksum:      transcript.Checksum,
+		IssuedAt:      transcript.IssuedAt,
+	}
+	if actor.AdminID != 0 {
+		issuedBy := actor.AdminID
+		verification.IssuedBy = &issuedBy
+	}
+	if err := s.transcriptRepo.CreateVerification(verification); err != nil {
+		return nil, err
+	}
+
+	logger.WithFields(map[string]interface{}{
+		"student_id": studentID,
+		"kind":       kind,
+		"code":       code,
+		"admin_id":   actor.AdminID,
+	}).Info("Transcript issued")
+
+	name := "transcript"
+	if kind == domain.TranscriptKindReportCard {
+		name = "report_card_" + semester
+	}
+	return &TranscriptDocument{
+		FileName:   fmt.Sprintf("%s_%s_%s.pdf", name, student.StudentID, transcript.IssuedAt.Format("20060102")),
+		Content:    content,
+		Transcript: transcript,
+	}, nil
+}
+
+// render 执行模板生成排版标记并输出PDF
+func (s *TranscriptService) render(t *domain.Transcript) ([]byte, error) {
+	var markup bytes.Buffer
+	if err := s.templates[t.Kind].Execute(&markup, t); err != nil {
+		return nil, err
+	}
+
+	title := "成绩单"
+	if t.Kind == domain.TranscriptKindReportCard {
+		title = "成绩报告单"
+	}
+
+	var out bytes.Buffer
+	if err := pdf.Render(&out, markup.String(), t.Student.Name+" "+title); err != nil {
+		return nil, err
+	}
+	return out.Bytes(), nil
+}
+
+// buildTranscript 按学期、科目汇总成绩，并按绩点制换算等级和绩点、计算学分加权平均绩点。课程成绩取总评成绩，
+// 没有总评成绩记录时取期末成绩。学期成绩报告单只保留指定学期，累计数据统计到该学期为止。
+func buildTranscript(kind, semester string, scale *domain.GradeScale, scores []*domain.TranscriptScore, grades []*domain.FinalGrade) *domain.Transcript {
+	type courseKey struct {
+		semester  string
+		subjectID int
+	}
+
+	courses := make(map[courseKey]*domain.TranscriptCourse)
+	var order []courseKey
+	for _, sc := range scores {
+		if semester != "" && sc.Semester > semester {
+			continue
+		}
+
+		key := courseKey{sc.Semester, sc.SubjectID}
+		course, ok := courses[key]
+		if !ok {
+			course = &domain.TranscriptCourse{
+				SubjectID:   sc.SubjectID,
+				SubjectCode: sc.SubjectCode,
+				SubjectName: sc.SubjectName,
+				Credits:     sc.Credits,
+			}
+			courses[key] = course
+			order = append(order, key)
+		}
+
+		value := sc.Score
+		switch sc.ExamType {
+		case domain.ExamTypeFinal:
+			course.Final = &value
+			course.Score = &value
+		case domain.ExamTypeMidterm:
+			course.Midterm = &value
+		case domain.ExamTypeQuiz:
+			course.Quiz = &value
+		case domain.ExamTypeAssignment:
+			course.Assignment = &value
+		}
+	}
+
+	for _, g := range grades {
+		if course, ok := courses[courseKey{g.Semester, g.SubjectID}]; ok {
+			course.Score = g.CompositeScore
+		}
+	}
+	for _, course := range courses {
+		if course.Score != nil {
+			result := scale.Grade(*course.Score)
+			course.Letter = result.Letter
+			course.GradePoint = result.GradePoint
+			course.Passed = result.Passed
+		}
+	}
+
+	sort.SliceStable(order, func(i, j int) bool {
+		return order[i].semester < order[j].semester
+	})
+
+	t := &domain.Transcript{Kind: kind, Semester: semester, GradeScale: scale.Name, GradeScaleName: scale.DisplayName}
+	var cumulative, semesterGPA domain.GPAAccumulator
+	var current *domain.TranscriptSemester
+	var semesterScores float64
+	var semesterGraded int
+
+	flush := func() {
+		if current == nil {
+			return
+		}
+		current.GPA = semesterGPA.GPA()
+		if semesterGraded > 0 {
+			current.AverageScore = round2(semesterScores / float64(semesterGraded))
+		}
+		// 学期成绩报告单只展示指定学期
+		if kind != domain.TranscriptKindReportCard || current.Semester == semester {
+			t.Semesters = append(t.Semesters, *current)
+		}
+	}
+
+	for _, key := range order {
+		course := courses[key]
+		// 历年成绩单只列出已有总评成绩的课程
+		if course.Score == nil && kind == domain.TranscriptKindTranscript {
+			continue
+		}
+
+		if current == nil || current.Semester != key.semester {
+			flush()
+			current = &domain.TranscriptSemester{Semester: key.semester}
+			semesterGPA = domain.GPAAccumulator{}
+			semesterScores, semesterGraded = 0, 0
+		}
+		current.Courses = append(current.Courses, *course)
+
+		if course.Score == nil {
+			continue
+		}
+		current.Credits += course.Credits
+		semesterGPA.Add(course.Credits, course.GradePoint)
+		semesterScores += *course.Score
+		semesterGraded++
+		if course.Passed {
+			current.EarnedCredits += course.Credits
+		}
+
+		t.TotalCredits += course.Credits
+		cumulative.Add(course.Credits, course.GradePoint)
+		if course.Passed {
+			t.EarnedCredits += course.Credits
+		}
+	}
+	flush()
+
+	t.CumulativeGPA = cumulative.GPA()
+	return t
+}
+
+// round2 保留两位小数
+func round2(v float64) float64 {
+	return math.Round(v*100) / 100
+}
+
+// transcriptChecksum 计算成绩数据的SHA-256摘要，验证时可与纸质成绩单上的摘要比对
+func transcriptChecksum(t *domain.Transcript) string {
+	h := sha256.New()
+	fmt.Fprintf(h, "%s|%s|%s|%s|%s|%s\n", t.VerificationCode, t.Kind, t.Student.StudentID, t.Semester, t.GradeScale, t.IssuedAt.UTC().Format(time.RFC3339))
+	for _, sem := range t.Semesters {
+		for _, c := range sem.Courses {
+			score, point := "-", "-"
+			if c.Score != nil {
+				score = fmt.Sprintf("%.2f", *c.Score)
+			}
+			if c.GradePoint != nil {
+				point = fmt.Sprintf("%.2f", *c.GradePoint)
+			}
+			fmt.Fprintf(h, "%s|%s|%d|%s|%s|%s\n", sem.Semester, c.SubjectCode, c.Credits, score, c.Letter, point)
+		}
+	}
+	fmt.Fprintf(h, "%d|%d|%.2f\n", t.TotalCredits, t.EarnedCredits, t.CumulativeGPA)
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// newVerificationCode 生成形如 XXXX-XXXX-XXXX-XXXX 的随机验证码
+func newVerificationCode() (string, error) {
+	limit := big.NewInt(int64(len(verificationCodeAlphabet)))
+	var sb strings.Builder
+	for i := 0; i < 16; i++ {
+		if i > 0 && i%4 == 0 {
+			sb.WriteByte('-')
+		}
+		n, err := rand.Int(rand.Reader, limit)
+		if err != nil {
+			return "", err
+		}
+		sb.WriteByte(verificationCodeAlphabet[n.Int64()])
+	}
+	return sb.String(), nil
+}
+
+// normalizeVerificationCode 统一验证码格式：忽略大小写、空格和分隔符
+func normalizeVerificationCode(code string) string {
+	var chars []rune
+	for _, r := range strings.ToUpper(code) {
+		if strings.ContainsRune(verificationCodeAlphabet, r) {
+			chars = append(chars, r)
+		} else if r != '-' && r != ' ' {
+			return ""
+		}
+	}
+	if len(chars) != 16 {
+		return ""
+	}
+
+	var sb strings.Builder
+	for i, r := range chars {
+		if i > 0 && i%4 == 0 {
+			sb.WriteByte('-')
+		}
+		sb.WriteRune(r)
+	}
+	return sb.String()
+}
+
+// loadTranscriptTemplate 加载成绩单模板，自定义模板目录中存在同名文件时优先使用
+func loadTranscriptTemplate(dir, name string) (*template.Template, error) {
+	tmpl := template.New(name).Funcs(transcriptTemplateFuncs)
+
+	if dir != "" {
+		path := filepath.Join(dir, name)
+		if _, err := os.Stat(path); err == nil {
+			parsed, err := tmpl.ParseFiles(path)
+			if err != nil {
+				return nil, fmt.Errorf("failed to parse transcript template %s: %w", path, err)
+			}
+			logger.WithFields(map[string]interface{}{
+				"template": path,
+			}).Info("Using custom transcript template")
+			return parsed, nil
+		}
+	}
+
+	parsed, err := tmpl.ParseFS(builtinTranscriptTemplates, "templates/"+name)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse transcript template %s: %w", name, err)
+	}
+	return parsed, nil
+}
+
+// transcriptTemplateFuncs 成绩单模板可用的函数
+var transcriptTemplateFuncs = template.FuncMap{
+	"esc": pdf.Escape,
+	// score 格式化可能为空的成绩
+	"score": func(v *float64) string {
+		if v == nil {
+			return "-"
+		}
+		return fmt.Sprintf("%.1f", *v)
+	},
+	"number": func(v float64) string {
+		return fmt.Sprintf("%.1f", v)
+	},
+	"gpa": func(v float64) string {
+		return fmt.Sprintf("%.2f", v)
+	},
+	// point 格式化可能为空的绩点
+	"point": func(v *float64) string {
+		if v == nil {
+			return "-"
+		}
+		return fmt.Sprintf("%.2f", *v)
+	},
+	// date 格式化日期，支持 time.Time 和 *time.Time
+	"date": func(v interface{}) string {
+		switch t := v.(type) {
+		case time.Time:
+			return t.Format("2006-01-02")
+		case *time.Time:
+			if t != nil {
+				return t.Format("2006-01-02")
+			}
+		}
+		return "-"
+	},
+	// result 课程结果：通过、不通过或待定
+	"result": func(c domain.TranscriptCourse) string {
+		switch {
+		case c.Score == nil:
+			return "待定"
+		case c.Passed:
+			return "通过"
+		default:
+			return "不通过"
+		}
+	},
+}
@@ -0,0 +1,264 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"student-management-system/internal/config"
+	"student-management-system/internal/domain"
+	"student-management-system/internal/repository"
+	"student-management-system/pkg/errors"
+	"student-management-system/pkg/logger"
+	"student-management-system/pkg/password"
+	"student-management-system/pkg/totp"
+)
+
+const (
+	// recoveryCodeCount 每次生成的恢复码数量
+	recoveryCodeCount = 10
+	// totpSkew 允许的时钟偏差（时间步数）
+	totpSkew = 1
+)
+
+// errInvalidTOTPCode 已登录用户提交的验证码错误
+var errInvalidTOTPCode = errors.New(errors.ErrCodeValidation, "验证码错误")
+
+// TwoFactorService 两步验证（TOTP）服务
+type TwoFactorService struct {
+	config    config.TwoFactorConfig
+	adminRepo *repository.AdminRepository
+	passwords *password.Manager
+}
+
+// NewTwoFactorService 创建两步验证服务实例
+func NewTwoFactorService(cfg config.TwoFactorConfig, adminRepo *repository.AdminRepository, passwords *password.Manager) *TwoFactorService {
+	return &TwoFactorService{
+		config:    cfg,
+		adminRepo: adminRepo,
+		passwords: passwords,
+	}
+}
+
+// IsRequired 角色是否强制要求两步验证
+func (s *TwoFactorService) IsRequired(role string) bool {
+	for _, r := range s.config.RequiredRoles {
+		if r == role {
+			return true
+		}
+	}
+	return false
+}
+
+// challengeTTL 登录第二步的有效期
+func (s *TwoFactorService) challengeTTL() time.Duration {
+	if s.config.ChallengeTTL > 0 {
+		return s.config.ChallengeTTL
+	}
+	return 5 * time.Minute
+}
+
+// GetStatus 获取当前账号的两步验证状态
+func (s *TwoFactorService) GetStatus(claims *domain.JWTClaims) (*domain.TwoFactorStatus, error) {
+	admin, err := s.adminRepo.GetAdminByID(claims.AdminID)
+	if err != nil {
+		return nil, fmt.Errorf("获取账号信息失败: %w", err)
+	}
+
+	status := &domain.TwoFactorStatus{
+		Enabled:  admin.TOTPEnabled,
+		Required: s.IsRequired(admin.Role),
+	}
+
+	if admin.TOTPEnabled {
+		status.RecoveryCodesRemaining, err = s.adminRepo.CountRecoveryCodes(admin.ID)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return status, nil
+}
+
+// Enroll 生成新的待确认密钥，需调用 Confirm 提交验证码后才会启用
+func (s *TwoFactorService) Enroll(claims *domain.JWTClaims) (*domain.TOTPSetup, error) {
+	admin, err := s.adminRepo.GetAdminByID(claims.AdminID)
+	if err != nil {
+		return nil, fmt.Errorf("获取账号信息失败: %w", err)
+	}
+
+	if admin.TOTPEnabled {
+		return nil, errors.New(errors.ErrCodeConflict, "已启用两步验证，请先关闭后再重新绑定")
+	}
+
+	return s.newSetup(admin)
+}
+
+// Confirm 使用认证器验证码确认绑定并启用两步验证，返回一次性恢复码
+func (s *TwoFactorService) Confirm(claims *domain.JWTClaims, code string) ([]string, error) {
+	admin, err := s.adminRepo.GetAdminByID(claims.AdminID)
+	if err != nil {
+		return nil, fmt.Errorf("获取账号信息失败: %w", err)
+	}
+
+	if admin.TOTPEnabled {
+		return nil, errors.New(errors.ErrCodeConflict, "已启用两步验证")
+	}
+	if admin.TOTPSecret == "" {
+		return nil, errors.New(errors.ErrCodeInvalidRequest, "请先获取两步验证密钥")
+	}
+
+	if !s.verifyCode(admin, code) {
+		return nil, errInvalidTOTPCode
+	}
+
+	return s.enable(admin)
+}
+
+// Disable 关闭两步验证，需要密码和当前验证码，强制要求两步验证的角色不能关闭
+func (s *TwoFactorService) Disable(claims *domain.JWTClaims, req *domain.DisableTwoFactorRequest) error {
+	admin, err := s.adminRepo.GetAdminByID(claims.AdminID)
+	if err != nil {
+		return fmt.Errorf("获取账号信息失败: %w", err)
+	}
+
+	if s.IsRequired(admin.Role) {
+		return errors.New(errors.ErrCodeForbidden, "当前角色必须启用两步验证")
+	}
+	if !admin.TOTPEnabled {
+		return errors.New(errors.ErrCodeInvalidRequest, "未启用两步验证")
+	}
+
+	ok, _, err := s.passwords.Verify(req.Password, admin.Password)
+	if err != nil || !ok {
+		return errors.New(errors.ErrCodeValidation, "密码错误")
+	}
+	if !s.verifyCode(admin, req.Code) {
+		return errInvalidTOTPCode
+	}
+
+	if err := s.adminRepo.DisableTOTP(admin.ID); err != nil {
+		return err
+	}
+
+	logger.WithFields(map[string]interface{}{
+		"admin_id": admin.ID,
+	}).Info("Two-factor authentication disabled by user")
+	return nil
+}
+
+// RegenerateRecoveryCodes 重新生成恢复码，旧恢复码全部作废
+func (s *TwoFactorService) RegenerateRecoveryCodes(claims *domain.JWTClaims, code string) ([]string, error) {
+	admin, err := s.adminRepo.GetAdminByID(claims.AdminID)
+	if err != nil {
+		return nil, fmt.Errorf("获取账号信息失败: %w", err)
+	}
+
+	if !admin.TOTPEnabled {
+		return nil, errors.New(errors.ErrCodeInvalidRequest, "未启用两步验证")
+	}
+	if !s.verifyCode(admin, code) {
+		return nil, errInvalidTOTPCode
+	}
+
+	codes, hashes, err := newRecoveryCodes()
+	if err != nil {
+		return nil, err
+	}
+	if err := s.adminRepo.ReplaceRecoveryCodes(admin.ID, hashes); err != nil {
+		return nil, err
+	}
+
+	logger.WithFields(map[string]interface{}{
+		"admin_id": admin.ID,
+	}).Info("Recovery codes regenerated")
+	return codes, nil
+}
+
+// newSetup 生成并保存待确认的密钥
+func (s *TwoFactorService) newSetup(admin *domain.Admin) (*domain.TOTPSetup, error) {
+	secret, err := totp.GenerateSecret()
+	if err != nil {
+		return nil, fmt.Errorf("生成两步验证密钥失败: %w", err)
+	}
+
+	if err := s.adminRepo.SetTOTPSecret(admin.ID, secret); err != nil {
+		return nil, err
+	}
+	admin.TOTPSecret = secret
+
+	return &domain.TOTPSetup{
+		Secret: secret,
+		URI:    totp.URI(s.config.Issuer, admin.Account, secret),
+	}, nil
+}
+
+// enable 启用两步验证并生成恢复码
+func (s *TwoFactorService) enable(admin *domain.Admin) ([]string, error) {
+	codes, hashes, err := newRecoveryCodes()
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.adminRepo.EnableTOTP(admin.ID, hashes); err != nil {
+		return nil, err
+	}
+	admin.TOTPEnabled = true
+
+	return codes, nil
+}
+
+// verifyCode 校验认证器验证码，同一时间步的验证码只能使用一次
+func (s *TwoFactorService) verifyCode(admin *domain.Admin, code string) bool {
+	if admin.TOTPSecret == "" {
+		return false
+	}
+
+	step, ok := totp.Validate(admin.TOTPSecret, code, time.Now(), totpSkew)
+	if !ok {
+		return false
+	}
+
+	if repository.RedisClient == nil {
+		return true
+	}
+
+	// 防止验证码在有效窗口内被重放
+	key := fmt.Sprintf("totp_used:%d:%d", admin.ID, step)
+	ttl := time.Duration((2*totpSkew+1)*totp.Period) * time.Second
+	first, err := repository.RedisClient.SetNX(context.Background(), key, 1, ttl).Result()
+	if err != nil {
+		logger.WithError(err).WithFields(map[string]interface{}{
+			"admin_id": admin.ID,
+		}).Error("Failed to record used totp code")
+		return false
+	}
+	if !first {
+		logger.WithFields(map[string]interface{}{
+			"admin_id": admin.ID,
+		}).Warn("TOTP code reused")
+	}
+	return first
+}
+
+// verifyRecoveryCode 校验并作废一个恢复码
+func (s *TwoFactorService) verifyRecoveryCode(admin *domain.Admin, code string) (bool, error) {
+	if !admin.TOTPEnabled {
+		return false, nil
+	}
+	return s.adminRepo.UseRecoveryCode(admin.ID, totp.HashRecoveryCode(code))
+}
+
+// newRecoveryCodes 生成恢复码及其哈希
+func newRecoveryCodes() ([]string, []string, error) {
+	codes, err := totp.GenerateRecoveryCodes(recoveryCodeCount)
+	if err != nil {
+		return nil, nil, fmt.Errorf("生成恢复码失败: %w", err)
+	}
+
+	hashes := make([]string, len(codes))
+	for i, code := range codes {
+		hashes[i] = totp.HashRecoveryCode(code)
+	}
+	return codes, hashes, nil
+}
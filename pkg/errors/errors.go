@@ -29,6 +29,7 @@ const (
 	ErrCodeInvalidCredentials ErrorCode = "INVALID_CREDENTIALS"
 	ErrCodeTokenExpired       ErrorCode = "TOKEN_EXPIRED"
 	ErrCodeInvalidToken       ErrorCode = "INVALID_TOKEN"
+	ErrCodeTokenReused        ErrorCode = "TOKEN_REUSED"
 
 	// 数据库错误
 	ErrCodeDatabaseError   ErrorCode = "DATABASE_ERROR"
@@ -120,7 +121,7 @@ func getHTTPStatus(code ErrorCode) int {
 	switch code {
 	case ErrCodeInvalidRequest, ErrCodeValidation:
 		return http.StatusBadRequest
-	case ErrCodeUnauthorized, ErrCodeInvalidCredentials, ErrCodeTokenExpired, ErrCodeInvalidToken:
+	case ErrCodeUnauthorized, ErrCodeInvalidCredentials, ErrCodeTokenExpired, ErrCodeInvalidToken, ErrCodeTokenReused:
 		return http.StatusUnauthorized
 	case ErrCodeForbidden:
 		return http.StatusForbidden
@@ -215,6 +216,7 @@ var (
 	ErrInvalidCredentials = New(ErrCodeInvalidCredentials, "Invalid username or password")
 	ErrTokenExpired       = New(ErrCodeTokenExpired, "Token has expired")
 	ErrInvalidToken       = New(ErrCodeInvalidToken, "Invalid token")
+	ErrRefreshTokenReused = New(ErrCodeTokenReused, "Refresh token has already been used")
 
 	ErrDatabaseError   = New(ErrCodeDatabaseError, "Database operation failed")
 	ErrConnectionError = New(ErrCodeConnectionError, "Database connection failed")
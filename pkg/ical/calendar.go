@@ -0,0 +1,252 @@
+// Package ical 生成 iCalendar（RFC 5545）日历文件，供课表和考试安排的日历订阅使用。
+package ical
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+// 重复频率
+const (
+	FreqDaily  = "DAILY"
+	FreqWeekly = "WEEKLY"
+)
+
+const (
+	localFormat = "20060102T150405"
+	utcFormat   = "20060102T150405Z"
+	lineLimit   = 75 // 每行最多75个字节，超出部分折行
+)
+
+// Calendar 日历
+type Calendar struct {
+	ProdID          string         // 生成日历的产品标识
+	Name            string         // 日历名称，显示在日历客户端中
+	Location        *time.Location // 事件时间所在时区，为空或 UTC 时使用 UTC 时间
+	RefreshInterval time.Duration  // 建议客户端刷新订阅的间隔，0 表示不设置
+	Events          []Event
+}
+
+// Event 日历事件
+type Event struct {
+	UID         string // 全局唯一标识，同一事件每次生成时必须相同
+	Summary     string
+	Description string
+	Location    string
+	Categories  []string
+	Start       time.Time
+	End         time.Time
+	Stamp       time.Time   // 事件最后修改时间，为空时使用当前时间
+	Recurrence  *Recurrence // 重复规则，为空表示单次事件
+	ExDates     []time.Time // 重复规则的例外日期，时间与 Start 相同
+}
+
+// Recurrence 重复规则
+type Recurrence struct {
+	Frequency string    // FreqDaily 或 FreqWeekly
+	Interval  int       // 间隔，0 或 1 表示每天（每周）
+	Until     time.Time // 最后一次重复不晚于该时间
+}
+
+// Encode 将日历写为 iCalendar 格式
+func (c *Calendar) Encode(w io.Writer) error {
+	var b bytes.Buffer
+	line := func(s string) { writeLine(&b, s) }
+
+	line("BEGIN:VCALENDAR")
+	line("VERSION:2.0")
+	line("PRODID:" + c.ProdID)
+	line("CALSCALE:GREGORIAN")
+	line("METHOD:PUBLISH")
+	if c.Name != "" {
+		line("X-WR-CALNAME:" + escapeText(c.Name))
+	}
+	tzid := c.tzid()
+	if tzid != "" {
+		line("X-WR-TIMEZONE:" + tzid)
+	}
+	if c.RefreshInterval > 0 {
+		line("REFRESH-INTERVAL;VALUE=DURATION:" + formatDuration(c.RefreshInterval))
+		line("X-PUBLISHED-TTL:" + formatDuration(c.RefreshInterval))
+	}
+
+	if tzid != "" && len(c.Events) > 0 {
+		from, to := c.span()
+		c.writeTimezone(&b, from, to)
+	}
+
+	now := time.Now()
+	for _, e := range c.Events {
+		stamp := e.Stamp
+		if stamp.IsZero() {
+			stamp = now
+		}
+
+		line("BEGIN:VEVENT")
+		line("UID:" + e.UID)
+		line("DTSTAMP:" + stamp.UTC().Format(utcFormat))
+		line("DTSTART" + c.formatTime(e.Start))
+		line("DTEND" + c.formatTime(e.End))
+		if e.Recurrence != nil {
+			line("RRULE:" + e.Recurrence.rule())
+			exDates := append([]time.Time(nil), e.ExDates...)
+			sort.Slice(exDates, func(i, j int) bool { return exDates[i].Before(exDates[j]) })
+			for _, d := range exDates {
+				line("EXDATE" + c.formatTime(d))
+			}
+		}
+		line("SUMMARY:" + escapeText(e.Summary))
+		if e.Location != "" {
+			line("LOCATION:" + escapeText(e.Location))
+		}
+		if e.Description != "" {
+			line("DESCRIPTION:" + escapeText(e.Description))
+		}
+		if len(e.Categories) > 0 {
+			categories := make([]string, len(e.Categories))
+			for i, category := range e.Categories {
+				categories[i] = escapeText(category)
+			}
+			line("CATEGORIES:" + strings.Join(categories, ","))
+		}
+		line("END:VEVENT")
+	}
+
+	line("END:VCALENDAR")
+	_, err := w.Write(b.Bytes())
+	return err
+}
+
+// tzid 日历使用的时区标识，UTC 时返回空
+func (c *Calendar) tzid() string {
+	if c.Location == nil || c.Location == time.UTC || c.Location.String() == "UTC" {
+		return ""
+	}
+	return c.Location.String()
+}
+
+// formatTime 格式化事件时间，返回包括参数和冒号在内的属性值部分
+func (c *Calendar) formatTime(t time.Time) string {
+	if tzid := c.tzid(); tzid != "" {
+		return ";TZID=" + tzid + ":" + t.In(c.Location).Format(localFormat)
+	}
+	return ":" + t.UTC().Format(utcFormat)
+}
+
+// span 全部事件覆盖的时间范围，用于生成时区的变更规则
+func (c *Calendar) span() (time.Time, time.Time) {
+	from, to := c.Events[0].Start, c.Events[0].End
+	for _, e := range c.Events {
+		if e.Start.Before(from) {
+			from = e.Start
+		}
+		end := e.End
+		if e.Recurrence != nil && e.Recurrence.Until.After(end) {
+			end = e.Recurrence.Until
+		}
+		if end.After(to) {
+			to = end
+		}
+	}
+	return from, to
+}
+
+// writeTimezone 按时区在时间范围内的偏移变化生成 VTIMEZONE
+func (c *Calendar) writeTimezone(b *bytes.Buffer, from, to time.Time) {
+	writeLine(b, "BEGIN:VTIMEZONE")
+	writeLine(b, "TZID:"+c.tzid())
+
+	t := from.In(c.Location)
+	for {
+		start, end := t.ZoneBounds()
+		name, offset := t.Zone()
+		prevOffset := offset
+		dtstart := "19700101T000000"
+		if !start.IsZero() {
+			_, prevOffset = start.Add(-time.Second).Zone()
+			dtstart = start.In(time.FixedZone("", prevOffset)).Format(localFormat)
+		}
+
+		kind := "STANDARD"
+		if t.IsDST() {
+			kind = "DAYLIGHT"
+		}
+		writeLine(b, "BEGIN:"+kind)
+		writeLine(b, "DTSTART:"+dtstart)
+		writeLine(b, "TZOFFSETFROM:"+formatOffset(prevOffset))
+		writeLine(b, "TZOFFSETTO:"+formatOffset(offset))
+		writeLine(b, "TZNAME:"+name)
+		writeLine(b, "END:"+kind)
+
+		if end.IsZero() || end.After(to) {
+			break
+		}
+		t = end
+	}
+
+	writeLine(b, "END:VTIMEZONE")
+}
+
+// rule 重复规则的 RRULE 值，UNTIL 使用 UTC 时间
+func (r *Recurrence) rule() string {
+	rule := "FREQ=" + r.Frequency
+	if r.Interval > 1 {
+		rule += fmt.Sprintf(";INTERVAL=%d", r.Interval)
+	}
+	if !r.Until.IsZero() {
+		rule += ";UNTIL=" + r.Until.UTC().Format(utcFormat)
+	}
+	return rule
+}
+
+// formatOffset 格式化时区偏移，如 +0800
+func formatOffset(seconds int) string {
+	sign := '+'
+	if seconds < 0 {
+		sign = '-'
+		seconds = -seconds
+	}
+	return fmt.Sprintf("%c%02d%02d", sign, seconds/3600, seconds%3600/60)
+}
+
+// formatDuration 格式化时长，如 PT6H
+func formatDuration(d time.Duration) string {
+	minutes := int(d.Minutes())
+	if minutes < 1 {
+		minutes = 1
+	}
+	if minutes%60 == 0 {
+		return fmt.Sprintf("PT%dH", minutes/60)
+	}
+	return fmt.Sprintf("PT%dM", minutes)
+}
+
+// textEscaper 文本属性值的转义规则
+var textEscaper = strings.NewReplacer(`\`, `\\`, ";", `\;`, ",", `\,`, "\r\n", `\n`, "\n", `\n`, "\r", `\n`)
+
+// escapeText 转义文本属性值中的反斜杠、分号、逗号和换行
+func escapeText(s string) string {
+	return textEscaper.Replace(s)
+}
+
+// writeLine 写入一行，超过75个字节时折行，不拆分多字节字符
+func writeLine(b *bytes.Buffer, s string) {
+	limit := lineLimit
+	for len(s) > limit {
+		cut := limit
+		for cut > 0 && !utf8.RuneStart(s[cut]) {
+			cut--
+		}
+		b.WriteString(s[:cut])
+		b.WriteString("\r\n ")
+		s = s[cut:]
+		limit = lineLimit - 1 // 续行以空格开头
+	}
+	b.WriteString(s)
+	b.WriteString("\r\n")
+}
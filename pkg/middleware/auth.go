@@ -71,12 +71,14 @@ func JWTAuth() gin.HandlerFunc {
 		logger.WithFields(logger.Fields{
 			"admin_id": claims.AdminID,
 			"account":  claims.Account,
+			"role":     claims.Role,
 		}).Info("JWT认证成功")
 
 		// 将claims存储到上下文中，供后续处理器使用
 		c.Set("claims", claims)
 		c.Set("admin_id", claims.AdminID)
 		c.Set("account", claims.Account)
+		c.Set("role", claims.Role)
 
 		// 继续处理请求
 		c.Next()
@@ -124,6 +126,7 @@ func OptionalJWTAuth() gin.HandlerFunc {
 		c.Set("claims", claims)
 		c.Set("admin_id", claims.AdminID)
 		c.Set("account", claims.Account)
+		c.Set("role", claims.Role)
 		c.Set("authenticated", true)
 
 		// 继续处理请求
@@ -184,6 +187,20 @@ func AdminRequired() gin.HandlerFunc {
 			return
 		}
 
+		// 检查角色
+		if jwtClaims.Role != domain.RoleAdmin {
+			logger.WithFields(logger.Fields{
+				"admin_id": jwtClaims.AdminID,
+				"role":     jwtClaims.Role,
+			}).Warn("非管理员角色")
+			c.JSON(http.StatusForbidden, ErrorResponse{
+				Error:   "Forbidden",
+				Message: "Admin role required",
+			})
+			c.Abort()
+			return
+		}
+
 		logger.WithFields(logger.Fields{
 			"admin_id": jwtClaims.AdminID,
 			"account":  jwtClaims.Account,
@@ -194,6 +211,54 @@ func AdminRequired() gin.HandlerFunc {
 	}
 }
 
+// PermissionChecker 权限检查接口
+type PermissionChecker interface {
+	HasPermission(role, permission string) (bool, error)
+}
+
+// RequirePermission 要求当前角色拥有指定权限的中间件，需在 JWTAuth 之后使用
+func RequirePermission(checker PermissionChecker, permission string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		jwtClaims, ok := GetCurrentAdmin(c)
+		if !ok {
+			logger.Warn("未通过JWT认证")
+			c.JSON(http.StatusUnauthorized, ErrorResponse{
+				Error:   "Unauthorized",
+				Message: "Authentication required",
+			})
+			c.Abort()
+			return
+		}
+
+		allowed, err := checker.HasPermission(jwtClaims.Role, permission)
+		if err != nil {
+			logger.WithError(err).Error("权限检查失败")
+			c.JSON(http.StatusInternalServerError, ErrorResponse{
+				Error:   "Internal error",
+				Message: "Failed to check permission",
+			})
+			c.Abort()
+			return
+		}
+
+		if !allowed {
+			logger.WithFields(logger.Fields{
+				"admin_id":   jwtClaims.AdminID,
+				"role":       jwtClaims.Role,
+				"permission": permission,
+			}).Warn("权限不足")
+			c.JSON(http.StatusForbidden, ErrorResponse{
+				Error:   "Forbidden",
+				Message: "Permission " + permission + " required",
+			})
+			c.Abort()
+			return
+		}
+
+		c.Next()
+	}
+}
+
 // GetCurrentAdmin 从上下文中获取当前管理员信息的辅助函数
 func GetCurrentAdmin(c *gin.Context) (*domain.JWTClaims, bool) {
 	claims, exists := c.Get("claims")
@@ -0,0 +1,117 @@
+// Package password 提供可插拔的密码哈希算法和密码策略。
+//
+// 哈希字符串自带算法标识（argon2id 使用 PHC 格式 $argon2id$...，bcrypt 使用 $2a$/$2b$ 前缀），
+// 历史遗留的无盐 MD5 摘要（32位十六进制）仅用于校验，登录成功后会被重新哈希为首选算法。
+package password
+
+import (
+	"fmt"
+	"strings"
+)
+
+// 支持的算法名称
+const (
+	AlgorithmArgon2id = "argon2id"
+	AlgorithmBcrypt   = "bcrypt"
+	AlgorithmMD5      = "md5" // 仅用于校验历史数据
+)
+
+// Hasher 密码哈希算法
+type Hasher interface {
+	// Algorithm 算法名称
+	Algorithm() string
+	// Hash 生成带算法标识的哈希字符串
+	Hash(password string) (string, error)
+	// Verify 校验密码是否与哈希匹配
+	Verify(password, encoded string) (bool, error)
+	// Matches 判断哈希字符串是否由本算法生成
+	Matches(encoded string) bool
+	// NeedsRehash 判断哈希的参数是否低于当前配置
+	NeedsRehash(encoded string) bool
+}
+
+// Config 密码哈希配置
+type Config struct {
+	Algorithm  string       // 新密码使用的算法: argon2id 或 bcrypt
+	BcryptCost int          // bcrypt 计算成本
+	Argon2     Argon2Params // argon2id 参数
+	Policy     Policy       // 密码策略
+}
+
+// Manager 密码管理器，负责按首选算法生成哈希、识别并校验各种算法的哈希
+type Manager struct {
+	preferred Hasher
+	hashers   []Hasher
+	policy    Policy
+}
+
+// NewManager 创建密码管理器
+func NewManager(cfg Config) (*Manager, error) {
+	argon2id := NewArgon2idHasher(cfg.Argon2)
+	bcryptHasher := NewBcryptHasher(cfg.BcryptCost)
+
+	var preferred Hasher
+	switch strings.ToLower(cfg.Algorithm) {
+	case "", AlgorithmArgon2id:
+		preferred = argon2id
+	case AlgorithmBcrypt:
+		preferred = bcryptHasher
+	default:
+		return nil, fmt.Errorf("unsupported password algorithm: %s", cfg.Algorithm)
+	}
+
+	return &Manager{
+		preferred: preferred,
+		hashers:   []Hasher{argon2id, bcryptHasher, md5Hasher{}},
+		policy:    cfg.Policy,
+	}, nil
+}
+
+// Algorithm 新密码使用的算法
+func (m *Manager) Algorithm() string {
+	return m.preferred.Algorithm()
+}
+
+// Policy 当前密码策略
+func (m *Manager) Policy() Policy {
+	return m.policy
+}
+
+// Hash 使用首选算法哈希密码
+func (m *Manager) Hash(password string) (string, error) {
+	return m.preferred.Hash(password)
+}
+
+// Verify 校验密码，needsRehash 为 true 表示校验通过但哈希应升级为首选算法或参数
+func (m *Manager) Verify(password, encoded string) (ok bool, needsRehash bool, err error) {
+	hasher := m.identify(encoded)
+	if hasher == nil {
+		return false, false, fmt.Errorf("unknown password hash format")
+	}
+
+	ok, err = hasher.Verify(password, encoded)
+	if err != nil || !ok {
+		return false, false, err
+	}
+
+	needsRehash = hasher.Algorithm() != m.preferred.Algorithm() || hasher.NeedsRehash(encoded)
+	return true, needsRehash, nil
+}
+
+// Identify 识别哈希字符串使用的算法，无法识别时返回空字符串
+func (m *Manager) Identify(encoded string) string {
+	if hasher := m.identify(encoded); hasher != nil {
+		return hasher.Algorithm()
+	}
+	return ""
+}
+
+// identify 查找能处理该哈希字符串的算法
+func (m *Manager) identify(encoded string) Hasher {
+	for _, hasher := range m.hashers {
+		if hasher.Matches(encoded) {
+			return hasher
+		}
+	}
+	return nil
+}
@@ -0,0 +1,68 @@
+package password
+
+import (
+	"fmt"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// Policy 密码策略
+type Policy struct {
+	MinLength       int  // 最小长度
+	MaxLength       int  // 最大长度，0 表示不限制
+	RequireUpper    bool // 必须包含大写字母
+	RequireLower    bool // 必须包含小写字母
+	RequireDigit    bool // 必须包含数字
+	RequireSpecial  bool // 必须包含特殊字符
+	DisallowAccount bool // 不能包含账号
+}
+
+// Validate 检查密码是否符合策略，返回的错误信息可直接展示给用户
+func (p Policy) Validate(password, account string) error {
+	length := utf8.RuneCountInString(password)
+	if p.MinLength > 0 && length < p.MinLength {
+		return fmt.Errorf("密码长度不能少于 %d 位", p.MinLength)
+	}
+	if p.MaxLength > 0 && length > p.MaxLength {
+		return fmt.Errorf("密码长度不能超过 %d 位", p.MaxLength)
+	}
+
+	var hasUpper, hasLower, hasDigit, hasSpecial bool
+	for _, r := range password {
+		switch {
+		case unicode.IsUpper(r):
+			hasUpper = true
+		case unicode.IsLower(r):
+			hasLower = true
+		case unicode.IsDigit(r):
+			hasDigit = true
+		case unicode.IsPunct(r) || unicode.IsSymbol(r):
+			hasSpecial = true
+		}
+	}
+
+	var missing []string
+	if p.RequireUpper && !hasUpper {
+		missing = append(missing, "大写字母")
+	}
+	if p.RequireLower && !hasLower {
+		missing = append(missing, "小写字母")
+	}
+	if p.RequireDigit && !hasDigit {
+		missing = append(missing, "数字")
+	}
+	if p.RequireSpecial && !hasSpecial {
+		missing = append(missing, "特殊字符")
+	}
+	if len(missing) > 0 {
+		return fmt.Errorf("密码必须包含%s", strings.Join(missing, "、"))
+	}
+
+	if p.DisallowAccount && account != "" &&
+		strings.Contains(strings.ToLower(password), strings.ToLower(account)) {
+		return fmt.Errorf("密码不能包含账号")
+	}
+
+	return nil
+}
@@ -0,0 +1,212 @@
+// Package pdf 生成只包含文本和线条的简单PDF文档。
+//
+// 文字统一使用 PDF 阅读器内置的 STSong-Light（华文宋体）CJK 字体，
+// 不需要嵌入字体文件即可正确显示中文，适合成绩单等以表格和文字为主的文档。
+package pdf
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+	"unicode/utf16"
+)
+
+// A4 纸张尺寸（单位：点，1点 = 1/72英寸）
+const (
+	A4Width  = 595.28
+	A4Height = 841.89
+)
+
+// Document PDF文档，页面内容保存在内存中，调用 WriteTo 时一次性输出
+type Document struct {
+	width   float64
+	height  float64
+	pages   []*bytes.Buffer
+	current int
+	title   string
+}
+
+// NewDocument 创建指定页面尺寸的文档
+func NewDocument(width, height float64) *Document {
+	return &Document{width: width, height: height}
+}
+
+// SetTitle 设置文档标题（显示在阅读器标题栏）
+func (d *Document) SetTitle(title string) {
+	d.title = title
+}
+
+// Size 页面尺寸
+func (d *Document) Size() (float64, float64) {
+	return d.width, d.height
+}
+
+// AddPage 新增一页，之后的绘制都在该页上进行
+func (d *Document) AddPage() {
+	d.pages = append(d.pages, &bytes.Buffer{})
+	d.current = len(d.pages) - 1
+}
+
+// PageCount 当前页数
+func (d *Document) PageCount() int {
+	return len(d.pages)
+}
+
+// SetPage 切换到指定页（从1开始），用于最后补写页脚
+func (d *Document) SetPage(n int) {
+	if n < 1 || n > len(d.pages) {
+		return
+	}
+	d.current = n - 1
+}
+
+// Text 在 (x, y) 处绘制一行文字，y 为基线距页面底部的距离
+func (d *Document) Text(x, y, size float64, text string, bold bool) {
+	page := d.page()
+	if page == nil || text == "" {
+		return
+	}
+
+	page.WriteString("BT\n")
+	if bold {
+		// 字体没有粗体字形，用描边模拟加粗
+		fmt.Fprintf(page, "2 Tr %.2f w\n", size*0.03)
+	} else {
+		page.WriteString("0 Tr\n")
+	}
+	fmt.Fprintf(page, "/F1 %.2f Tf\n%.2f %.2f Td\n<%s> Tj\nET\n", size, x, y, encodeText(text))
+}
+
+// Line 绘制直线
+func (d *Document) Line(x1, y1, x2, y2, width float64) {
+	page := d.page()
+	if page == nil {
+		return
+	}
+	fmt.Fprintf(page, "%.2f w\n%.2f %.2f m\n%.2f %.2f l\nS\n", width, x1, y1, x2, y2)
+}
+
+// Rect 绘制矩形边框
+func (d *Document) Rect(x, y, w, h, width float64) {
+	page := d.page()
+	if page == nil {
+		return
+	}
+	fmt.Fprintf(page, "%.2f w\n%.2f %.2f %.2f %.2f re\nS\n", width, x, y, w, h)
+}
+
+// TextWidth 计算文字宽度：ASCII字符为半角，其余为全角
+func TextWidth(text string, size float64) float64 {
+	var units float64
+	for _, r := range text {
+		if r >= 0x20 && r <= 0x7E {
+			units += 0.5
+		} else {
+			units++
+		}
+	}
+	return units * size
+}
+
+// WriteTo 输出完整的PDF文件
+func (d *Document) WriteTo(w io.Writer) (int64, error) {
+	if len(d.pages) == 0 {
+		d.AddPage()
+	}
+
+	var buf bytes.Buffer
+	var offsets []int
+
+	// 对象编号：1 目录，2 页面树，3-5 字体，6 文档信息，之后每页两个对象（页面、内容）
+	begin := func() {
+		offsets = append(offsets, buf.Len())
+		fmt.Fprintf(&buf, "%d 0 obj\n", len(offsets))
+	}
+	end := func() {
+		buf.WriteString("endobj\n")
+	}
+
+	buf.WriteString("%PDF-1.4\n%\xE2\xE3\xCF\xD3\n")
+
+	const firstPageObj = 7
+	kids := make([]string, len(d.pages))
+	for i := range d.pages {
+		kids[i] = fmt.Sprintf("%d 0 R", firstPageObj+i*2)
+	}
+
+	begin()
+	buf.WriteString("<< /Type /Catalog /Pages 2 0 R >>\n")
+	end()
+
+	begin()
+	fmt.Fprintf(&buf, "<< /Type /Pages /Kids [%s] /Count %d >>\n", strings.Join(kids, " "), len(d.pages))
+	end()
+
+	begin()
+	buf.WriteString("<< /Type /Font /Subtype /Type0 /BaseFont /STSong-Light /Encoding /UniGB-UTF16-H /DescendantFonts [4 0 R] >>\n")
+	end()
+
+	begin()
+	buf.WriteString("<< /Type /Font /Subtype /CIDFontType0 /BaseFont /STSong-Light " +
+		"/CIDSystemInfo << /Registry (Adobe) /Ordering (GB1) /Supplement 4 >> " +
+		"/FontDescriptor 5 0 R /DW 1000 /W [1 95 500] >>\n")
+	end()
+
+	begin()
+	buf.WriteString("<< /Type /FontDescriptor /FontName /STSong-Light /Flags 6 " +
+		"/FontBBox [-25 -254 1000 880] /ItalicAngle 0 /Ascent 880 /Descent -120 /CapHeight 880 /StemV 93 >>\n")
+	end()
+
+	begin()
+	fmt.Fprintf(&buf, "<< /Producer <%s> /Title <%s> /CreationDate (D:%s) >>\n",
+		encodeInfo("student-management-system"), encodeInfo(d.title), time.Now().Format("20060102150405"))
+	end()
+
+	for i, content := range d.pages {
+		begin()
+		fmt.Fprintf(&buf, "<< /Type /Page /Parent 2 0 R /MediaBox [0 0 %.2f %.2f] "+
+			"/Resources << /Font << /F1 3 0 R >> >> /Contents %d 0 R >>\n",
+			d.width, d.height, firstPageObj+i*2+1)
+		end()
+
+		begin()
+		fmt.Fprintf(&buf, "<< /Length %d >>\nstream\n", content.Len())
+		buf.Write(content.Bytes())
+		buf.WriteString("\nendstream\n")
+		end()
+	}
+
+	xref := buf.Len()
+	fmt.Fprintf(&buf, "xref\n0 %d\n0000000000 65535 f \n", len(offsets)+1)
+	for _, off := range offsets {
+		fmt.Fprintf(&buf, "%010d 00000 n \n", off)
+	}
+	fmt.Fprintf(&buf, "trailer\n<< /Size %d /Root 1 0 R /Info 6 0 R >>\nstartxref\n%d\n%%%%EOF\n", len(offsets)+1, xref)
+
+	n, err := w.Write(buf.Bytes())
+	return int64(n), err
+}
+
+// page 当前绘制的页
+func (d *Document) page() *bytes.Buffer {
+	if len(d.pages) == 0 {
+		return nil
+	}
+	return d.pages[d.current]
+}
+
+// encodeText 将文字编码为 UTF-16BE 十六进制串，对应 UniGB-UTF16-H 编码
+func encodeText(text string) string {
+	var sb strings.Builder
+	for _, u := range utf16.Encode([]rune(text)) {
+		fmt.Fprintf(&sb, "%04X", u)
+	}
+	return sb.String()
+}
+
+// encodeInfo 编码文档信息字符串（带BOM的UTF-16BE）
+func encodeInfo(text string) string {
+	return "FEFF" + encodeText(text)
+}
@@ -0,0 +1,359 @@
+package pdf
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+)
+
+// 排版使用的字号（点）
+const (
+	sizeTitle    = 18
+	sizeSubtitle = 11
+	sizeHeading  = 12
+	sizeText     = 10
+	sizeTable    = 9
+	sizeFooter   = 8
+
+	pageMargin   = 50
+	footerHeight = 24
+	cellPadding  = 4
+)
+
+// Render 按排版标记生成A4纵向PDF。标记每行一条指令，格式为“指令 参数”，多个单元格用 | 分隔：
+//
+//	title 文本                   居中大标题
+//	subtitle 文本                居中副标题
+//	heading 文本                 小节标题
+//	text 文本                    左对齐段落，超长自动换行
+//	center 文本                  居中文字
+//	right 文本                   右对齐文字
+//	fields 标签|值|标签|值...     一行内等分显示多组“标签：值”
+//	table 宽度:表头[:对齐]|...    开始表格（三线表），宽度为相对比例，对齐为 l、c、r
+//	row 单元格|...               表格行
+//	total 单元格|...             加粗的表格行，上方带分隔线
+//	endtable                     结束表格
+//	hr                           分隔线
+//	space 点数                   垂直留白
+//	pagebreak                    换页
+//	footer 文本                  每页底部居中的页脚，{page}、{pages} 替换为页码和总页数
+//
+// 空行和以 # 开头的行会被忽略。表格跨页时会在新页重复表头。
+func Render(w io.Writer, markup, title string) error {
+	l := &layout{doc: NewDocument(A4Width, A4Height)}
+	l.doc.SetTitle(title)
+	l.newPage()
+
+	scanner := bufio.NewScanner(strings.NewReader(markup))
+	lineNo := 0
+	for scanner.Scan() {
+		lineNo++
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		cmd, arg, _ := strings.Cut(line, " ")
+		if err := l.exec(cmd, strings.TrimSpace(arg)); err != nil {
+			return fmt.Errorf("line %d: %w", lineNo, err)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return err
+	}
+	if l.table != nil {
+		l.endTable()
+	}
+
+	l.drawFooters()
+	_, err := l.doc.WriteTo(w)
+	return err
+}
+
+// layout 自上而下的流式排版状态
+type layout struct {
+	doc    *Document
+	y      float64 // 下一行的顶部位置
+	table  *table
+	footer string
+}
+
+// table 正在排版的表格
+type table struct {
+	columns []column
+}
+
+// column 表格列
+type column struct {
+	width float64
+	title string
+	align byte
+}
+
+// exec 执行一条排版指令
+func (l *layout) exec(cmd, arg string) error {
+	switch cmd {
+	case "title":
+		l.centered(arg, sizeTitle, true)
+		l.y -= 4
+	case "subtitle":
+		l.centered(arg, sizeSubtitle, false)
+	case "heading":
+		l.y -= 6
+		l.ensure(lineHeight(sizeHeading))
+		l.y -= lineHeight(sizeHeading)
+		l.doc.Text(pageMargin, l.baseline(sizeHeading), sizeHeading, arg, true)
+	case "text":
+		for _, line := range wrap(arg, sizeText, l.contentWidth()) {
+			l.ensure(lineHeight(sizeText))
+			l.y -= lineHeight(sizeText)
+			l.doc.Text(pageMargin, l.baseline(sizeText), sizeText, line, false)
+		}
+	case "center":
+		l.centered(arg, sizeText, false)
+	case "right":
+		l.ensure(lineHeight(sizeText))
+		l.y -= lineHeight(sizeText)
+		x := A4Width - pageMargin - TextWidth(arg, sizeText)
+		l.doc.Text(x, l.baseline(sizeText), sizeText, arg, false)
+	case "fields":
+		l.fields(splitCells(arg))
+	case "table":
+		return l.startTable(splitCells(arg))
+	case "row", "total":
+		if l.table == nil {
+			return fmt.Errorf("%s outside table", cmd)
+		}
+		l.row(splitCells(arg), cmd == "total")
+	case "endtable":
+		if l.table == nil {
+			return fmt.Errorf("endtable without table")
+		}
+		l.endTable()
+	case "hr":
+		l.ensure(8)
+		l.y -= 4
+		l.doc.Line(pageMargin, l.y, A4Width-pageMargin, l.y, 0.5)
+		l.y -= 4
+	case "space":
+		n, err := strconv.ParseFloat(arg, 64)
+		if err != nil {
+			return fmt.Errorf("invalid space %q", arg)
+		}
+		l.y -= n
+	case "pagebreak":
+		l.newPage()
+	case "footer":
+		l.footer = arg
+	default:
+		return fmt.Errorf("unknown command %q", cmd)
+	}
+	return nil
+}
+
+// newPage 换页，表格未结束时在新页重复表头
+func (l *layout) newPage() {
+	l.doc.AddPage()
+	l.y = A4Height - pageMargin
+	if l.table != nil {
+		l.tableHeader()
+	}
+}
+
+// ensure 剩余空间不足 h 时换页
+func (l *layout) ensure(h float64) {
+	if l.y-h >= pageMargin+footerHeight {
+		return
+	}
+	if l.table != nil {
+		l.doc.Line(pageMargin, l.y, A4Width-pageMargin, l.y, 1)
+	}
+	l.newPage()
+}
+
+// centered 绘制居中的一行文字
+func (l *layout) centered(text string, size float64, bold bool) {
+	l.ensure(lineHeight(size))
+	l.y -= lineHeight(size)
+	x := (A4Width - TextWidth(text, size)) / 2
+	l.doc.Text(x, l.baseline(size), size, text, bold)
+}
+
+// fields 一行内等分显示多组“标签：值”
+func (l *layout) fields(cells []string) {
+	pairs := (len(cells) + 1) / 2
+	if pairs == 0 {
+		return
+	}
+
+	l.ensure(lineHeight(sizeText))
+	l.y -= lineHeight(sizeText)
+	width := l.contentWidth() / float64(pairs)
+	for i := 0; i < pairs; i++ {
+		label := cells[i*2]
+		value := ""
+		if i*2+1 < len(cells) {
+			value = cells[i*2+1]
+		}
+		x := pageMargin + float64(i)*width
+		l.doc.Text(x, l.baseline(sizeText), sizeText, label+"：", true)
+		lw := TextWidth(label+"：", sizeText)
+		l.doc.Text(x+lw, l.baseline(sizeText), sizeText, fit(value, sizeText, width-lw-cellPadding), false)
+	}
+}
+
+// startTable 开始表格
+func (l *layout) startTable(specs []string) error {
+	if l.table != nil {
+		l.endTable()
+	}
+
+	var total float64
+	columns := make([]column, len(specs))
+	for i, spec := range specs {
+		parts := strings.SplitN(spec, ":", 3)
+		weight, err := strconv.ParseFloat(strings.TrimSpace(parts[0]), 64)
+		if err != nil || weight <= 0 || len(parts) < 2 {
+			return fmt.Errorf("invalid column %q, expected width:title[:align]", spec)
+		}
+		columns[i] = column{width: weight, title: parts[1], align: 'l'}
+		if len(parts) == 3 && parts[2] != "" {
+			columns[i].align = parts[2][0]
+		}
+		total += weight
+	}
+	for i := range columns {
+		columns[i].width = columns[i].width / total * l.contentWidth()
+	}
+
+	l.y -= 4
+	l.ensure(lineHeight(sizeTable) * 3)
+	l.table = &table{columns: columns}
+	l.tableHeader()
+	return nil
+}
+
+// tableHeader 绘制表头
+func (l *layout) tableHeader() {
+	l.doc.Line(pageMargin, l.y, A4Width-pageMargin, l.y, 1)
+	titles := make([]string, len(l.table.columns))
+	for i, col := range l.table.columns {
+		titles[i] = col.title
+	}
+	l.cells(titles, true)
+	l.doc.Line(pageMargin, l.y, A4Width-pageMargin, l.y, 0.5)
+}
+
+// row 绘制表格行
+func (l *layout) row(cells []string, bold bool) {
+	l.ensure(lineHeight(sizeTable))
+	if bold {
+		l.doc.Line(pageMargin, l.y, A4Width-pageMargin, l.y, 0.5)
+	}
+	l.cells(cells, bold)
+}
+
+// cells 绘制一行单元格
+func (l *layout) cells(cells []string, bold bool) {
+	l.y -= lineHeight(sizeTable)
+	y := l.baseline(sizeTable)
+	x := float64(pageMargin)
+	for i, col := range l.table.columns {
+		text := ""
+		if i < len(cells) {
+			text = fit(cells[i], sizeTable, col.width-cellPadding*2)
+		}
+
+		tx := x + cellPadding
+		switch col.align {
+		case 'c':
+			tx = x + (col.width-TextWidth(text, sizeTable))/2
+		case 'r':
+			tx = x + col.width - cellPadding - TextWidth(text, sizeTable)
+		}
+		l.doc.Text(tx, y, sizeTable, text, bold)
+		x += col.width
+	}
+}
+
+// endTable 结束表格
+func (l *layout) endTable() {
+	l.doc.Line(pageMargin, l.y, A4Width-pageMargin, l.y, 1)
+	l.y -= 6
+	l.table = nil
+}
+
+// drawFooters 在每页底部绘制页脚
+func (l *layout) drawFooters() {
+	if l.footer == "" {
+		return
+	}
+
+	pages := l.doc.PageCount()
+	for i := 1; i <= pages; i++ {
+		text := strings.NewReplacer("{page}", strconv.Itoa(i), "{pages}", strconv.Itoa(pages)).Replace(l.footer)
+		l.doc.SetPage(i)
+		x := (A4Width - TextWidth(text, sizeFooter)) / 2
+		l.doc.Text(x, pageMargin, sizeFooter, text, false)
+	}
+}
+
+// baseline 当前行的基线位置
+func (l *layout) baseline(size float64) float64 {
+	return l.y + (lineHeight(size)-size)/2 + size*0.15
+}
+
+// contentWidth 正文宽度
+func (l *layout) contentWidth() float64 {
+	return A4Width - pageMargin*2
+}
+
+// lineHeight 行高
+func lineHeight(size float64) float64 {
+	return size * 1.8
+}
+
+// splitCells 拆分单元格
+func splitCells(arg string) []string {
+	cells := strings.Split(arg, "|")
+	for i := range cells {
+		cells[i] = strings.TrimSpace(cells[i])
+	}
+	return cells
+}
+
+// fit 截断超出宽度的文字
+func fit(text string, size, maxWidth float64) string {
+	if TextWidth(text, size) <= maxWidth {
+		return text
+	}
+	runes := []rune(text)
+	for len(runes) > 0 && TextWidth(string(runes)+"…", size) > maxWidth {
+		runes = runes[:len(runes)-1]
+	}
+	return string(runes) + "…"
+}
+
+// wrap 按宽度折行
+func wrap(text string, size, maxWidth float64) []string {
+	var lines []string
+	var current []rune
+	for _, r := range text {
+		if TextWidth(string(append(current, r)), size) > maxWidth && len(current) > 0 {
+			lines = append(lines, string(current))
+			current = current[:0]
+		}
+		current = append(current, r)
+	}
+	if len(current) > 0 {
+		lines = append(lines, string(current))
+	}
+	return lines
+}
+
+// Escape 转义放入排版标记的文字：去掉换行并将 | 替换为全角竖线，供模板使用
+func Escape(text string) string {
+	return strings.NewReplacer("\r", " ", "\n", " ", "|", "｜").Replace(text)
+}
@@ -0,0 +1,133 @@
+// Package spreadsheet 读写CSV和XLSX表格并支持导出NDJSON，供批量导入导出使用。
+package spreadsheet
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/csv"
+	"fmt"
+	"io"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/xuri/excelize/v2"
+	"golang.org/x/text/encoding/simplifiedchinese"
+	"golang.org/x/text/transform"
+)
+
+// 支持的表格格式
+const (
+	FormatCSV  = "csv"
+	FormatXLSX = "xlsx"
+)
+
+// utf8BOM Excel导出CSV时常带的BOM
+var utf8BOM = []byte{0xEF, 0xBB, 0xBF}
+
+// DetectFormat 根据文件名判断表格格式
+func DetectFormat(filename string) (string, error) {
+	switch strings.ToLower(filepath.Ext(filename)) {
+	case ".csv":
+		return FormatCSV, nil
+	case ".xlsx":
+		return FormatXLSX, nil
+	default:
+		return "", fmt.Errorf("unsupported file type %q, expected .csv or .xlsx", filepath.Ext(filename))
+	}
+}
+
+// ReadAll 读取表格的全部行（包含表头行）。
+// XLSX 读取 sheet 指定的工作表，为空时读取第一个工作表；日期单元格返回原始序列号，由调用方解析。
+// CSV 支持 UTF-8（可带BOM）和Excel常用的GBK编码。
+func ReadAll(r io.Reader, format, sheet string) ([][]string, error) {
+	switch format {
+	case FormatCSV:
+		return readCSV(r)
+	case FormatXLSX:
+		return readXLSX(r, sheet)
+	default:
+		return nil, fmt.Errorf("unsupported format %q", format)
+	}
+}
+
+// readCSV 读取CSV
+func readCSV(r io.Reader) ([][]string, error) {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+	data = bytes.TrimPrefix(data, utf8BOM)
+
+	var reader io.Reader = bytes.NewReader(data)
+	if !utf8.Valid(data) {
+		reader = transform.NewReader(reader, simplifiedchinese.GBK.NewDecoder())
+	}
+
+	cr := csv.NewReader(bufio.NewReader(reader))
+	cr.FieldsPerRecord = -1
+	cr.TrimLeadingSpace = true
+
+	rows, err := cr.ReadAll()
+	if err != nil {
+		return nil, fmt.Errorf("parse csv: %w", err)
+	}
+	return rows, nil
+}
+
+// readXLSX 读取XLSX
+func readXLSX(r io.Reader, sheet string) ([][]string, error) {
+	f, err := excelize.OpenReader(r)
+	if err != nil {
+		return nil, fmt.Errorf("open xlsx: %w", err)
+	}
+	defer f.Close()
+
+	if sheet == "" {
+		sheet = f.GetSheetName(0)
+	}
+	if idx, err := f.GetSheetIndex(sheet); err != nil || idx < 0 {
+		return nil, fmt.Errorf("sheet %q not found", sheet)
+	}
+
+	rows, err := f.GetRows(sheet, excelize.Options{RawCellValue: true})
+	if err != nil {
+		return nil, fmt.Errorf("read sheet %q: %w", sheet, err)
+	}
+	return rows, nil
+}
+
+// dateLayouts 支持的日期文本格式
+var dateLayouts = []string{
+	"2006-01-02",
+	"2006/01/02",
+	"2006.01.02",
+	"2006-1-2",
+	"2006/1/2",
+	"2006年1月2日",
+	"20060102",
+	time.RFC3339,
+}
+
+// ParseDate 解析单元格中的日期，支持常见文本格式和Excel日期序列号
+func ParseDate(value string) (time.Time, error) {
+	value = strings.TrimSpace(value)
+
+	for _, layout := range dateLayouts {
+		if t, err := time.ParseInLocation(layout, value, time.Local); err == nil {
+			return t, nil
+		}
+	}
+
+	// Excel日期序列号，如 45170 或 45170.5
+	if serial, err := strconv.ParseFloat(value, 64); err == nil && serial > 0 && serial < 2958466 {
+		t, err := excelize.ExcelDateToTime(serial, false)
+		if err == nil {
+			return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.Local), nil
+		}
+	}
+
+	return time.Time{}, fmt.Errorf("invalid date %q", value)
+}
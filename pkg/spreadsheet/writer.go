@@ -0,0 +1,226 @@
+package spreadsheet
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strconv"
+	"time"
+
+	"github.com/xuri/excelize/v2"
+)
+
+// FormatNDJSON 每行一个JSON对象，只用于导出
+const FormatNDJSON = "ndjson"
+
+// xlsxSheet 导出XLSX时使用的工作表名称
+const xlsxSheet = "Sheet1"
+
+// Writer 逐行写出表格，不在内存中保留已写出的行
+type Writer interface {
+	// WriteHeader 写出表头。keys 为字段名，titles 为显示的列名；NDJSON 使用 keys 作为对象的键
+	WriteHeader(keys, titles []string) error
+	// WriteRow 写出一行，values 与表头一一对应，支持 string、数值、bool、time.Time、*time.Time 和 nil
+	WriteRow(values []interface{}) error
+	// Close 写出缓冲的数据，不会关闭底层的 io.Writer
+	Close() error
+}
+
+// NewWriter 创建指定格式的表格写出器
+func NewWriter(w io.Writer, format string) (Writer, error) {
+	switch format {
+	case FormatCSV:
+		return newCSVWriter(w)
+	case FormatXLSX:
+		return newXLSXWriter(w)
+	case FormatNDJSON:
+		return &ndjsonWriter{w: bufio.NewWriter(w)}, nil
+	default:
+		return nil, fmt.Errorf("unsupported format %q", format)
+	}
+}
+
+// ContentType 返回导出格式对应的 Content-Type
+func ContentType(format string) string {
+	switch format {
+	case FormatCSV:
+		return "text/csv; charset=utf-8"
+	case FormatXLSX:
+		return "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet"
+	case FormatNDJSON:
+		return "application/x-ndjson"
+	default:
+		return "application/octet-stream"
+	}
+}
+
+// csvWriter CSV写出器
+type csvWriter struct {
+	w *csv.Writer
+}
+
+// newCSVWriter 创建CSV写出器，写入BOM以便Excel正确识别UTF-8中文
+func newCSVWriter(w io.Writer) (*csvWriter, error) {
+	if _, err := w.Write(utf8BOM); err != nil {
+		return nil, err
+	}
+	return &csvWriter{w: csv.NewWriter(w)}, nil
+}
+
+func (cw *csvWriter) WriteHeader(keys, titles []string) error {
+	return cw.w.Write(titles)
+}
+
+func (cw *csvWriter) WriteRow(values []interface{}) error {
+	record := make([]string, len(values))
+	for i, v := range values {
+		record[i] = formatCell(v)
+	}
+	return cw.w.Write(record)
+}
+
+func (cw *csvWriter) Close() error {
+	cw.w.Flush()
+	return cw.w.Error()
+}
+
+// xlsxWriter XLSX写出器，基于 excelize 的流式写入，行数据暂存在临时文件中
+type xlsxWriter struct {
+	out    io.Writer
+	file   *excelize.File
+	stream *excelize.StreamWriter
+	row    int
+}
+
+// newXLSXWriter 创建XLSX写出器
+func newXLSXWriter(w io.Writer) (*xlsxWriter, error) {
+	f := excelize.NewFile()
+	stream, err := f.NewStreamWriter(xlsxSheet)
+	if err != nil {
+		f.Close()
+		return nil, err
+	}
+	return &xlsxWriter{out: w, file: f, stream: stream}, nil
+}
+
+func (xw *xlsxWriter) WriteHeader(keys, titles []string) error {
+	values := make([]interface{}, len(titles))
+	for i, t := range titles {
+		values[i] = t
+	}
+	return xw.writeRow(values)
+}
+
+func (xw *xlsxWriter) WriteRow(values []interface{}) error {
+	cells := make([]interface{}, len(values))
+	for i, v := range values {
+		switch v := v.(type) {
+		case time.Time, *time.Time:
+			cells[i] = formatCell(v)
+		default:
+			cells[i] = v
+		}
+	}
+	return xw.writeRow(cells)
+}
+
+func (xw *xlsxWriter) writeRow(values []interface{}) error {
+	xw.row++
+	cell, err := excelize.CoordinatesToCellName(1, xw.row)
+	if err != nil {
+		return err
+	}
+	return xw.stream.SetRow(cell, values)
+}
+
+func (xw *xlsxWriter) Close() error {
+	defer xw.file.Close()
+	if err := xw.stream.Flush(); err != nil {
+		return err
+	}
+	return xw.file.Write(xw.out)
+}
+
+// ndjsonWriter NDJSON写出器
+type ndjsonWriter struct {
+	w    *bufio.Writer
+	keys []string
+}
+
+func (nw *ndjsonWriter) WriteHeader(keys, titles []string) error {
+	nw.keys = keys
+	return nil
+}
+
+func (nw *ndjsonWriter) WriteRow(values []interface{}) error {
+	// 手工拼接以保持列顺序
+	var buf bytes.Buffer
+	buf.WriteByte('{')
+	for i, key := range nw.keys {
+		if i > 0 {
+			buf.WriteByte(',')
+		}
+		k, _ := json.Marshal(key)
+		buf.Write(k)
+		buf.WriteByte(':')
+
+		var v interface{}
+		if i < len(values) {
+			v = values[i]
+		}
+		b, err := json.Marshal(v)
+		if err != nil {
+			return err
+		}
+		buf.Write(b)
+	}
+	buf.WriteString("}\n")
+
+	_, err := nw.w.Write(buf.Bytes())
+	return err
+}
+
+func (nw *ndjsonWriter) Close() error {
+	return nw.w.Flush()
+}
+
+// formatCell 将单元格的值格式化为文本，日期只保留年月日
+func formatCell(v interface{}) string {
+	switch v := v.(type) {
+	case nil:
+		return ""
+	case string:
+		return v
+	case int:
+		return strconv.Itoa(v)
+	case int64:
+		return strconv.FormatInt(v, 10)
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64)
+	case bool:
+		return strconv.FormatBool(v)
+	case time.Time:
+		return formatTime(v)
+	case *time.Time:
+		if v == nil {
+			return ""
+		}
+		return formatTime(*v)
+	default:
+		return fmt.Sprint(v)
+	}
+}
+
+// formatTime 零点的时间按日期输出，其余按日期时间输出
+func formatTime(t time.Time) string {
+	if t.IsZero() {
+		return ""
+	}
+	if t.Hour() == 0 && t.Minute() == 0 && t.Second() == 0 {
+		return t.Format("2006-01-02")
+	}
+	return t.Format("2006-01-02 15:04:05")
+}
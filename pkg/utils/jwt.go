@@ -1,9 +1,6 @@
 package utils
 
 import (
-	"context"
-	"crypto/hmac"
-	"crypto/sha256"
 	"encoding/base64"
 	"encoding/json"
 	"fmt"
@@ -16,32 +13,42 @@ import (
 	"student-management-system/pkg/logger"
 )
 
-// JWTSecret JWT密钥
-var JWTSecret = []byte("your-secret-key-change-this-in-production")
+// GenerateToken 为会话签发访问token，claims.JTI 必须为已创建的会话ID，Exp 和 Iat 由本函数设置
+func GenerateToken(claims domain.JWTClaims, expiresIn int64) (string, time.Time, error) {
+	adminID := claims.AdminID
+	username := claims.Account
 
-// GenerateToken 生成JWT token并存储到Redis
-func GenerateToken(adminID int, username string, expiresIn int64) (string, time.Time, error) {
 	logger.WithFields(logger.Fields{
 		"admin_id":   adminID,
 		"username":   username,
+		"role":       claims.Role,
+		"session_id": claims.JTI,
 		"expires_in": expiresIn,
 	}).Debug("开始生成JWT token")
 
+	if claims.JTI == "" {
+		return "", time.Time{}, fmt.Errorf("session id is required")
+	}
+
+	ks := CurrentKeySet()
+	if ks == nil {
+		return "", time.Time{}, fmt.Errorf("jwt keys not initialized")
+	}
+	key := ks.signing
+
 	now := time.Now()
 	expiresAt := now.Add(time.Duration(expiresIn) * time.Second)
 
-	// 创建JWT声明
-	claims := domain.JWTClaims{
-		AdminID: adminID,
-		Account: username,
-		Exp:     expiresAt.Unix(),
-		Iat:     now.Unix(),
-	}
+	// 设置JWT时间声明
+	claims.Exp = expiresAt.Unix()
+	claims.Iat = now.Unix()
+	claims.Iss = ks.issuer
 
-	// 创建header
+	// 创建header，kid 用于验证方选择公钥
 	header := map[string]interface{}{
-		"alg": "HS256",
+		"alg": key.alg,
 		"typ": "JWT",
+		"kid": key.kid,
 	}
 
 	// 编码header
@@ -62,27 +69,14 @@ func GenerateToken(adminID int, username string, expiresIn int64) (string, time.
 
 	// 创建签名
 	message := headerEncoded + "." + payloadEncoded
-	signature := createSignature(message, JWTSecret)
+	signature, err := key.sign([]byte(message))
+	if err != nil {
+		logger.WithError(err).Error("JWT签名失败")
+		return "", time.Time{}, err
+	}
 
 	// 组合token
-	token := message + "." + signature
-
-	// 将token存储到Redis
-	if repository.RedisClient != nil {
-		ctx := context.Background()
-		tokenKey := fmt.Sprintf("jwt_token:%d", adminID)
-
-		// 存储token到Redis，设置过期时间
-		err = repository.RedisClient.Set(ctx, tokenKey, token, time.Duration(expiresIn)*time.Second).Err()
-		if err != nil {
-			logger.WithError(err).Warn("存储token到Redis失败")
-		} else {
-			logger.WithFields(logger.Fields{
-				"admin_id":  adminID,
-				"token_key": tokenKey,
-			}).Debug("Token已存储到Redis")
-		}
-	}
+	token := message + "." + base64.RawURLEncoding.EncodeToString(signature)
 
 	logger.WithFields(logger.Fields{
 		"admin_id":   adminID,
@@ -93,7 +87,7 @@ func GenerateToken(adminID int, username string, expiresIn int64) (string, time.
 	return token, expiresAt, nil
 }
 
-// ValidateToken 验证JWT token并检查Redis存储
+// ValidateToken 验证JWT token的签名、签发方和有效期，并检查Redis存储
 func ValidateToken(tokenString string) (*domain.JWTClaims, error) {
 	logger.Debug("开始验证JWT token")
 
@@ -108,10 +102,40 @@ func ValidateToken(tokenString string) (*domain.JWTClaims, error) {
 	payloadEncoded := parts[1]
 	signatureEncoded := parts[2]
 
+	ks := CurrentKeySet()
+	if ks == nil {
+		logger.Error("JWT密钥未初始化")
+		return nil, errors.ErrInvalidToken
+	}
+
+	// 解码header，根据kid选择验证密钥
+	headerBytes, err := base64.RawURLEncoding.DecodeString(headerEncoded)
+	if err != nil {
+		logger.WithError(err).Warn("JWT token header解码失败")
+		return nil, errors.ErrInvalidToken
+	}
+
+	var header struct {
+		Alg string `json:"alg"`
+		Kid string `json:"kid"`
+	}
+	if err := json.Unmarshal(headerBytes, &header); err != nil {
+		logger.WithError(err).Warn("JWT token header解析失败")
+		return nil, errors.ErrInvalidToken
+	}
+
+	key, ok := ks.lookup(header.Kid, header.Alg)
+	if !ok {
+		logger.WithFields(logger.Fields{
+			"kid": header.Kid,
+			"alg": header.Alg,
+		}).Warn("JWT token使用了未知的密钥或算法")
+		return nil, errors.ErrInvalidToken
+	}
+
 	// 验证签名
-	message := headerEncoded + "." + payloadEncoded
-	expectedSignature := createSignature(message, JWTSecret)
-	if signatureEncoded != expectedSignature {
+	signature, err := base64.RawURLEncoding.DecodeString(signatureEncoded)
+	if err != nil || !key.verify([]byte(headerEncoded+"."+payloadEncoded), signature) {
 		logger.Warn("JWT token签名验证失败")
 		return nil, errors.ErrInvalidToken
 	}
@@ -130,36 +154,50 @@ func ValidateToken(tokenString string) (*domain.JWTClaims, error) {
 		return nil, errors.ErrInvalidToken
 	}
 
+	// 验证签发方，拒绝共用密钥的其他系统签发的token
+	if ks.issuer != "" && claims.Iss != ks.issuer {
+		logger.WithFields(logger.Fields{
+			"iss": claims.Iss,
+		}).Warn("JWT token签发方不匹配")
+		return nil, errors.ErrInvalidToken
+	}
+
 	// 验证过期时间
 	if err := claims.Valid(); err != nil {
 		logger.WithError(err).Warn("JWT token验证失败")
 		return nil, err
 	}
 
-	// 检查Redis中的token是否存在且匹配
+	// 检查token对应的会话是否仍然有效
 	if repository.RedisClient != nil {
-		ctx := context.Background()
-		tokenKey := fmt.Sprintf("jwt_token:%d", claims.AdminID)
+		if claims.JTI == "" {
+			logger.WithFields(logger.Fields{
+				"admin_id": claims.AdminID,
+			}).Warn("JWT token缺少会话ID")
+			return nil, errors.ErrInvalidToken
+		}
 
-		storedToken, err := repository.RedisClient.Get(ctx, tokenKey).Result()
+		session, err := GetSession(claims.JTI)
 		if err != nil {
 			logger.WithError(err).WithFields(map[string]interface{}{
-				"admin_id":  claims.AdminID,
-				"token_key": tokenKey,
-			}).Warn("从Redis获取token失败或token不存在")
+				"admin_id":   claims.AdminID,
+				"session_id": claims.JTI,
+			}).Warn("从Redis获取会话失败或会话已失效")
 			return nil, errors.ErrTokenExpired
 		}
 
-		if storedToken != tokenString {
+		if session.AdminID != claims.AdminID {
 			logger.WithFields(logger.Fields{
-				"admin_id": claims.AdminID,
-			}).Warn("Redis中的token与提供的token不匹配")
+				"admin_id":   claims.AdminID,
+				"session_id": claims.JTI,
+			}).Warn("会话与token中的账号不匹配")
 			return nil, errors.ErrInvalidToken
 		}
 
 		logger.WithFields(logger.Fields{
-			"admin_id": claims.AdminID,
-		}).Debug("Redis token验证成功")
+			"admin_id":   claims.AdminID,
+			"session_id": claims.JTI,
+		}).Debug("Redis会话验证成功")
 	}
 
 	logger.WithFields(logger.Fields{
@@ -170,14 +208,6 @@ func ValidateToken(tokenString string) (*domain.JWTClaims, error) {
 	return &claims, nil
 }
 
-// createSignature 创建HMAC-SHA256签名
-func createSignature(message string, secret []byte) string {
-	h := hmac.New(sha256.New, secret)
-	h.Write([]byte(message))
-	signature := h.Sum(nil)
-	return base64.RawURLEncoding.EncodeToString(signature)
-}
-
 // ExtractTokenFromHeader 从Authorization header中提取token
 func ExtractTokenFromHeader(authHeader string) (string, error) {
 	logger.Debug("从Authorization header提取token")
@@ -198,29 +228,14 @@ func ExtractTokenFromHeader(authHeader string) (string, error) {
 	return parts[1], nil
 }
 
-// InvalidateToken 使token失效（从Redis中删除）
+// InvalidateToken 使账号的全部会话失效（所有设备登出）
 func InvalidateToken(adminID int) error {
-	if repository.RedisClient == nil {
-		logger.Warn("Redis客户端未初始化，无法使token失效")
-		return nil
-	}
-
-	ctx := context.Background()
-	tokenKey := fmt.Sprintf("jwt_token:%d", adminID)
-
-	err := repository.RedisClient.Del(ctx, tokenKey).Err()
+	_, err := InvalidateOtherSessions(adminID, "")
 	if err != nil {
 		logger.WithError(err).WithFields(map[string]interface{}{
-			"admin_id":  adminID,
-			"token_key": tokenKey,
-		}).Error("从Redis删除token失败")
+			"admin_id": adminID,
+		}).Error("使账号全部会话失效失败")
 		return err
 	}
-
-	logger.WithFields(map[string]interface{}{
-		"admin_id":  adminID,
-		"token_key": tokenKey,
-	}).Info("Token已从Redis中删除")
-
 	return nil
 }
@@ -0,0 +1,288 @@
+package utils
+
+import (
+	"crypto"
+	"crypto/ed25519"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/pem"
+	"fmt"
+	"math/big"
+	"os"
+	"sync/atomic"
+)
+
+// 支持的JWT签名算法
+const (
+	AlgHS256 = "HS256"
+	AlgRS256 = "RS256"
+	AlgEdDSA = "EdDSA"
+)
+
+// hmacKeyID 仅配置共享密钥时使用的kid
+const hmacKeyID = "hs256"
+
+// JWTKeyConfig 单个JWT密钥配置
+type JWTKeyConfig struct {
+	KID            string
+	Algorithm      string // RS256 或 EdDSA
+	PrivateKeyFile string // PEM格式私钥，签名密钥必须提供
+	PublicKeyFile  string // PEM格式公钥，轮换后仅用于验证的旧密钥可只提供公钥
+}
+
+// jwtKey 已加载的JWT密钥
+type jwtKey struct {
+	kid     string
+	alg     string
+	secret  []byte
+	private crypto.Signer
+	public  crypto.PublicKey
+}
+
+// KeySet JWT签名与验证密钥集合，同一时间只有一个签名密钥，可有多个验证密钥
+type KeySet struct {
+	issuer  string
+	signing *jwtKey
+	keys    map[string]*jwtKey
+	order   []string
+}
+
+// keySet 当前使用的密钥集合
+var keySet atomic.Pointer[KeySet]
+
+// SetKeySet 设置当前使用的密钥集合
+func SetKeySet(ks *KeySet) {
+	keySet.Store(ks)
+}
+
+// CurrentKeySet 获取当前使用的密钥集合
+func CurrentKeySet() *KeySet {
+	return keySet.Load()
+}
+
+// NewKeySet 创建密钥集合。未配置密钥文件时使用共享密钥以HS256签名；
+// 配置了密钥文件时 signingKID 指定签名密钥，为空则使用第一个带私钥的密钥
+func NewKeySet(issuer, signingKID string, secret []byte, keys []JWTKeyConfig) (*KeySet, error) {
+	ks := &KeySet{
+		issuer: issuer,
+		keys:   make(map[string]*jwtKey),
+	}
+
+	if len(keys) == 0 {
+		if len(secret) == 0 {
+			return nil, fmt.Errorf("jwt secret or keys must be configured")
+		}
+		key := &jwtKey{kid: hmacKeyID, alg: AlgHS256, secret: secret}
+		ks.keys[key.kid] = key
+		ks.order = append(ks.order, key.kid)
+		ks.signing = key
+		return ks, nil
+	}
+
+	for _, cfg := range keys {
+		if cfg.KID == "" {
+			return nil, fmt.Errorf("jwt key id is required")
+		}
+		if _, exists := ks.keys[cfg.KID]; exists {
+			return nil, fmt.Errorf("duplicate jwt key id %q", cfg.KID)
+		}
+
+		key, err := loadKey(cfg)
+		if err != nil {
+			return nil, fmt.Errorf("load jwt key %q: %w", cfg.KID, err)
+		}
+		ks.keys[key.kid] = key
+		ks.order = append(ks.order, key.kid)
+
+		if ks.signing == nil && key.private != nil && (signingKID == "" || signingKID == key.kid) {
+			ks.signing = key
+		}
+	}
+
+	if ks.signing == nil {
+		if signingKID != "" {
+			return nil, fmt.Errorf("signing key %q not found or has no private key", signingKID)
+		}
+		return nil, fmt.Errorf("no jwt key with a private key configured")
+	}
+
+	return ks, nil
+}
+
+// loadKey 从PEM文件加载密钥
+func loadKey(cfg JWTKeyConfig) (*jwtKey, error) {
+	key := &jwtKey{kid: cfg.KID, alg: cfg.Algorithm}
+	if key.alg != AlgRS256 && key.alg != AlgEdDSA {
+		return nil, fmt.Errorf("unsupported algorithm %q", cfg.Algorithm)
+	}
+
+	switch {
+	case cfg.PrivateKeyFile != "":
+		block, err := readPEM(cfg.PrivateKeyFile)
+		if err != nil {
+			return nil, err
+		}
+		private, err := parsePrivateKey(block)
+		if err != nil {
+			return nil, err
+		}
+		key.private = private
+		key.public = private.Public()
+	case cfg.PublicKeyFile != "":
+		block, err := readPEM(cfg.PublicKeyFile)
+		if err != nil {
+			return nil, err
+		}
+		public, err := parsePublicKey(block)
+		if err != nil {
+			return nil, err
+		}
+		key.public = public
+	default:
+		return nil, fmt.Errorf("private_key_file or public_key_file is required")
+	}
+
+	// 检查密钥类型与算法是否匹配
+	switch key.public.(type) {
+	case *rsa.PublicKey:
+		if key.alg != AlgRS256 {
+			return nil, fmt.Errorf("rsa key cannot be used with %s", key.alg)
+		}
+	case ed25519.PublicKey:
+		if key.alg != AlgEdDSA {
+			return nil, fmt.Errorf("ed25519 key cannot be used with %s", key.alg)
+		}
+	default:
+		return nil, fmt.Errorf("unsupported key type %T", key.public)
+	}
+
+	return key, nil
+}
+
+// readPEM 读取PEM文件的第一个块
+func readPEM(path string) (*pem.Block, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return nil, fmt.Errorf("%s: no PEM data found", path)
+	}
+	return block, nil
+}
+
+// parsePrivateKey 解析PKCS#8或PKCS#1私钥
+func parsePrivateKey(block *pem.Block) (crypto.Signer, error) {
+	if block.Type == "RSA PRIVATE KEY" {
+		return x509.ParsePKCS1PrivateKey(block.Bytes)
+	}
+	key, err := x509.ParsePKCS8PrivateKey(block.Bytes)
+	if err != nil {
+		return nil, err
+	}
+	signer, ok := key.(crypto.Signer)
+	if !ok {
+		return nil, fmt.Errorf("unsupported private key type %T", key)
+	}
+	return signer, nil
+}
+
+// parsePublicKey 解析PKIX或PKCS#1公钥
+func parsePublicKey(block *pem.Block) (crypto.PublicKey, error) {
+	if block.Type == "RSA PUBLIC KEY" {
+		return x509.ParsePKCS1PublicKey(block.Bytes)
+	}
+	return x509.ParsePKIXPublicKey(block.Bytes)
+}
+
+// sign 使用签名密钥对消息签名
+func (k *jwtKey) sign(message []byte) ([]byte, error) {
+	switch k.alg {
+	case AlgHS256:
+		h := hmac.New(sha256.New, k.secret)
+		h.Write(message)
+		return h.Sum(nil), nil
+	case AlgRS256:
+		digest := sha256.Sum256(message)
+		return k.private.Sign(rand.Reader, digest[:], crypto.SHA256)
+	case AlgEdDSA:
+		return k.private.Sign(rand.Reader, message, crypto.Hash(0))
+	}
+	return nil, fmt.Errorf("unsupported algorithm %q", k.alg)
+}
+
+// verify 验证消息签名
+func (k *jwtKey) verify(message, signature []byte) bool {
+	switch k.alg {
+	case AlgHS256:
+		h := hmac.New(sha256.New, k.secret)
+		h.Write(message)
+		return hmac.Equal(signature, h.Sum(nil))
+	case AlgRS256:
+		digest := sha256.Sum256(message)
+		return rsa.VerifyPKCS1v15(k.public.(*rsa.PublicKey), crypto.SHA256, digest[:], signature) == nil
+	case AlgEdDSA:
+		return ed25519.Verify(k.public.(ed25519.PublicKey), message, signature)
+	}
+	return false
+}
+
+// lookup 根据token header中的kid和alg查找验证密钥，alg必须与密钥一致
+func (ks *KeySet) lookup(kid, alg string) (*jwtKey, bool) {
+	key, ok := ks.keys[kid]
+	if !ok || key.alg != alg {
+		return nil, false
+	}
+	return key, true
+}
+
+// JWK JSON Web Key（RFC 7517）
+type JWK struct {
+	Kty string `json:"kty"`
+	Use string `json:"use"`
+	Kid string `json:"kid"`
+	Alg string `json:"alg"`
+	N   string `json:"n,omitempty"`
+	E   string `json:"e,omitempty"`
+	Crv string `json:"crv,omitempty"`
+	X   string `json:"x,omitempty"`
+}
+
+// JWKSet JSON Web Key Set
+type JWKSet struct {
+	Keys []JWK `json:"keys"`
+}
+
+// JWKS 导出全部公钥，HS256共享密钥不会被导出
+func (ks *KeySet) JWKS() JWKSet {
+	set := JWKSet{Keys: []JWK{}}
+	for _, kid := range ks.order {
+		key := ks.keys[kid]
+		switch public := key.public.(type) {
+		case *rsa.PublicKey:
+			set.Keys = append(set.Keys, JWK{
+				Kty: "RSA",
+				Use: "sig",
+				Kid: key.kid,
+				Alg: key.alg,
+				N:   base64.RawURLEncoding.EncodeToString(public.N.Bytes()),
+				E:   base64.RawURLEncoding.EncodeToString(big.NewInt(int64(public.E)).Bytes()),
+			})
+		case ed25519.PublicKey:
+			set.Keys = append(set.Keys, JWK{
+				Kty: "OKP",
+				Use: "sig",
+				Kid: key.kid,
+				Alg: key.alg,
+				Crv: "Ed25519",
+				X:   base64.RawURLEncoding.EncodeToString(public),
+			})
+		}
+	}
+	return set
+}
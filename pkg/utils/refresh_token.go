@@ -0,0 +1,135 @@
+package utils
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"student-management-system/internal/domain"
+	"student-management-system/internal/repository"
+	"student-management-system/pkg/errors"
+	"student-management-system/pkg/logger"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// refreshTokenRecord Redis中保存的刷新token信息，token本身只保存哈希
+type refreshTokenRecord struct {
+	SessionID string    `json:"session_id"`
+	AdminID   int       `json:"admin_id"`
+	ExpiresAt time.Time `json:"expires_at"`
+}
+
+// refreshTokenKey 刷新token记录的Redis键
+func refreshTokenKey(hash string) string {
+	return fmt.Sprintf("refresh_token:%s", hash)
+}
+
+// refreshTokenUsedKey 刷新token已使用标记的Redis键
+func refreshTokenUsedKey(hash string) string {
+	return fmt.Sprintf("refresh_token_used:%s", hash)
+}
+
+// GenerateOpaqueToken 生成随机的不透明token（刷新token、登录挑战等）
+func GenerateOpaqueToken() (string, error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(b), nil
+}
+
+// HashOpaqueToken 计算不透明token的SHA-256哈希，Redis中只保存哈希
+func HashOpaqueToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
+
+// IssueRefreshToken 为会话签发新的不透明刷新token，有效期与会话一致
+func IssueRefreshToken(session *domain.Session) (string, time.Time, error) {
+	if repository.RedisClient == nil {
+		return "", time.Time{}, fmt.Errorf("refresh tokens require redis")
+	}
+
+	token, err := GenerateOpaqueToken()
+	if err != nil {
+		return "", time.Time{}, err
+	}
+
+	record := refreshTokenRecord{
+		SessionID: session.ID,
+		AdminID:   session.AdminID,
+		ExpiresAt: session.ExpiresAt,
+	}
+	data, err := json.Marshal(record)
+	if err != nil {
+		return "", time.Time{}, err
+	}
+
+	ctx := context.Background()
+	err = repository.RedisClient.Set(ctx, refreshTokenKey(HashOpaqueToken(token)), data, time.Until(session.ExpiresAt)).Err()
+	if err != nil {
+		logger.WithError(err).WithFields(map[string]interface{}{
+			"session_id": session.ID,
+		}).Error("存储刷新token失败")
+		return "", time.Time{}, err
+	}
+
+	return token, session.ExpiresAt, nil
+}
+
+// ConsumeRefreshToken 使用刷新token并返回其所属会话，每个刷新token只能使用一次。
+// 已轮换的刷新token被再次使用时视为泄露，整个会话（token家族）会被撤销。
+func ConsumeRefreshToken(token string) (*domain.Session, error) {
+	if repository.RedisClient == nil {
+		return nil, fmt.Errorf("refresh tokens require redis")
+	}
+
+	ctx := context.Background()
+	hash := HashOpaqueToken(token)
+
+	data, err := repository.RedisClient.Get(ctx, refreshTokenKey(hash)).Bytes()
+	if err == redis.Nil {
+		return nil, errors.ErrInvalidToken
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var record refreshTokenRecord
+	if err := json.Unmarshal(data, &record); err != nil {
+		return nil, errors.ErrInvalidToken
+	}
+
+	// 原子地标记为已使用，标记失败说明该token已被轮换过
+	first, err := repository.RedisClient.SetNX(ctx, refreshTokenUsedKey(hash), 1, time.Until(record.ExpiresAt)).Result()
+	if err != nil {
+		return nil, err
+	}
+	if !first {
+		logger.WithFields(logger.Fields{
+			"admin_id":   record.AdminID,
+			"session_id": record.SessionID,
+		}).Warn("检测到刷新token重复使用，撤销整个会话")
+
+		if err := InvalidateSession(record.AdminID, record.SessionID); err != nil && err != ErrSessionNotFound {
+			logger.WithError(err).Error("撤销会话失败")
+		}
+		return nil, errors.ErrRefreshTokenReused
+	}
+
+	session, err := GetSession(record.SessionID)
+	if err != nil {
+		if err == ErrSessionNotFound {
+			return nil, errors.ErrInvalidToken
+		}
+		return nil, err
+	}
+
+	return session, nil
+}
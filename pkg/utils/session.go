@@ -0,0 +1,269 @@
+package utils
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"time"
+
+	"student-management-system/internal/domain"
+	"student-management-system/internal/repository"
+	"student-management-system/pkg/logger"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// ErrSessionNotFound 会话不存在或已失效
+var ErrSessionNotFound = fmt.Errorf("session not found")
+
+// sessionKey 单个会话的Redis键
+func sessionKey(sessionID string) string {
+	return fmt.Sprintf("jwt_session:%s", sessionID)
+}
+
+// adminSessionsKey 账号全部会话ID集合的Redis键
+func adminSessionsKey(adminID int) string {
+	return fmt.Sprintf("jwt_sessions:%d", adminID)
+}
+
+// NewSessionID 生成随机会话ID
+func NewSessionID() (string, error) {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(b), nil
+}
+
+// saveSession 保存会话，并将会话ID加入账号的会话集合
+func saveSession(ctx context.Context, session *domain.Session) error {
+	ttl := time.Until(session.ExpiresAt)
+	data, err := json.Marshal(session)
+	if err != nil {
+		return err
+	}
+
+	setKey := adminSessionsKey(session.AdminID)
+	pipe := repository.RedisClient.TxPipeline()
+	pipe.Set(ctx, sessionKey(session.ID), data, ttl)
+	pipe.SAdd(ctx, setKey, session.ID)
+	if _, err := pipe.Exec(ctx); err != nil {
+		return err
+	}
+
+	// 集合的过期时间不短于其中最晚过期的会话
+	currentTTL, err := repository.RedisClient.TTL(ctx, setKey).Result()
+	if err == nil && currentTTL < ttl {
+		repository.RedisClient.Expire(ctx, setKey, ttl)
+	}
+
+	return nil
+}
+
+// CreateSession 创建会话，会话有效期即刷新token的有效期；Redis未初始化时只生成会话ID
+func CreateSession(adminID int, meta domain.SessionMeta, ttl time.Duration) (*domain.Session, error) {
+	sessionID, err := NewSessionID()
+	if err != nil {
+		logger.WithError(err).Error("生成会话ID失败")
+		return nil, err
+	}
+
+	now := time.Now()
+	session := &domain.Session{
+		ID:        sessionID,
+		AdminID:   adminID,
+		Device:    meta.Device,
+		IP:        meta.IP,
+		UserAgent: meta.UserAgent,
+		CreatedAt: now,
+		ExpiresAt: now.Add(ttl),
+	}
+
+	if repository.RedisClient == nil {
+		logger.Warn("Redis客户端未初始化，会话不会被持久化")
+		return session, nil
+	}
+
+	if err := saveSession(context.Background(), session); err != nil {
+		logger.WithError(err).WithFields(map[string]interface{}{
+			"admin_id": adminID,
+		}).Error("存储会话到Redis失败")
+		return nil, err
+	}
+
+	logger.WithFields(logger.Fields{
+		"admin_id":   adminID,
+		"session_id": sessionID,
+	}).Debug("会话已存储到Redis")
+
+	return session, nil
+}
+
+// ExtendSession 延长会话有效期（刷新token轮换时调用）
+func ExtendSession(session *domain.Session, ttl time.Duration) error {
+	session.ExpiresAt = time.Now().Add(ttl)
+	if repository.RedisClient == nil {
+		return nil
+	}
+	return saveSession(context.Background(), session)
+}
+
+// GetSession 获取会话信息
+func GetSession(sessionID string) (*domain.Session, error) {
+	if repository.RedisClient == nil {
+		return nil, ErrSessionNotFound
+	}
+
+	data, err := repository.RedisClient.Get(context.Background(), sessionKey(sessionID)).Bytes()
+	if err == redis.Nil {
+		return nil, ErrSessionNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var session domain.Session
+	if err := json.Unmarshal(data, &session); err != nil {
+		return nil, err
+	}
+	return &session, nil
+}
+
+// ListSessions 获取账号的全部有效会话，按创建时间倒序
+func ListSessions(adminID int) ([]*domain.Session, error) {
+	sessions := []*domain.Session{}
+	if repository.RedisClient == nil {
+		return sessions, nil
+	}
+
+	ctx := context.Background()
+	setKey := adminSessionsKey(adminID)
+
+	sessionIDs, err := repository.RedisClient.SMembers(ctx, setKey).Result()
+	if err != nil {
+		logger.WithError(err).WithFields(map[string]interface{}{
+			"admin_id": adminID,
+		}).Error("获取会话列表失败")
+		return nil, err
+	}
+	if len(sessionIDs) == 0 {
+		return sessions, nil
+	}
+
+	keys := make([]string, len(sessionIDs))
+	for i, id := range sessionIDs {
+		keys[i] = sessionKey(id)
+	}
+
+	values, err := repository.RedisClient.MGet(ctx, keys...).Result()
+	if err != nil {
+		return nil, err
+	}
+
+	var expired []interface{}
+	for i, value := range values {
+		data, ok := value.(string)
+		if !ok {
+			// 会话已过期，顺便从集合中清理
+			expired = append(expired, sessionIDs[i])
+			continue
+		}
+
+		var session domain.Session
+		if err := json.Unmarshal([]byte(data), &session); err != nil {
+			logger.WithError(err).WithFields(map[string]interface{}{
+				"session_id": sessionIDs[i],
+			}).Warn("会话数据解析失败")
+			continue
+		}
+		sessions = append(sessions, &session)
+	}
+
+	if len(expired) > 0 {
+		repository.RedisClient.SRem(ctx, setKey, expired...)
+	}
+
+	sort.Slice(sessions, func(i, j int) bool {
+		return sessions[i].CreatedAt.After(sessions[j].CreatedAt)
+	})
+
+	return sessions, nil
+}
+
+// InvalidateSession 使账号的单个会话失效
+func InvalidateSession(adminID int, sessionID string) error {
+	if repository.RedisClient == nil {
+		logger.Warn("Redis客户端未初始化，无法使会话失效")
+		return nil
+	}
+
+	ctx := context.Background()
+	removed, err := repository.RedisClient.SRem(ctx, adminSessionsKey(adminID), sessionID).Result()
+	if err != nil {
+		return err
+	}
+	if removed == 0 {
+		return ErrSessionNotFound
+	}
+
+	if err := repository.RedisClient.Del(ctx, sessionKey(sessionID)).Err(); err != nil {
+		logger.WithError(err).WithFields(map[string]interface{}{
+			"admin_id":   adminID,
+			"session_id": sessionID,
+		}).Error("从Redis删除会话失败")
+		return err
+	}
+
+	logger.WithFields(logger.Fields{
+		"admin_id":   adminID,
+		"session_id": sessionID,
+	}).Info("会话已失效")
+
+	return nil
+}
+
+// InvalidateOtherSessions 使账号除 keepSessionID 以外的全部会话失效，返回失效的会话数量
+func InvalidateOtherSessions(adminID int, keepSessionID string) (int, error) {
+	if repository.RedisClient == nil {
+		logger.Warn("Redis客户端未初始化，无法使会话失效")
+		return 0, nil
+	}
+
+	ctx := context.Background()
+	setKey := adminSessionsKey(adminID)
+
+	sessionIDs, err := repository.RedisClient.SMembers(ctx, setKey).Result()
+	if err != nil {
+		return 0, err
+	}
+
+	var keys []string
+	var members []interface{}
+	for _, id := range sessionIDs {
+		if id == keepSessionID {
+			continue
+		}
+		keys = append(keys, sessionKey(id))
+		members = append(members, id)
+	}
+	if len(keys) == 0 {
+		return 0, nil
+	}
+
+	// 只统计仍然有效的会话
+	deleted, err := repository.RedisClient.Del(ctx, keys...).Result()
+	if err != nil {
+		return 0, err
+	}
+	repository.RedisClient.SRem(ctx, setKey, members...)
+
+	logger.WithFields(logger.Fields{
+		"admin_id": adminID,
+		"revoked":  deleted,
+	}).Info("会话已批量失效")
+
+	return int(deleted), nil
+}
@@ -2,13 +2,13 @@
 -- 学生表字段: id, student_id, name, age, gender, phone, email, address, major, enrollment_date, graduation_date, status
 
 INSERT INTO students (student_id, name, age, gender, phone, email, address, major, enrollment_date, graduation_date, status) VALUES
-('S2024001', '张三', 20, '男', '13800138001', 'zhangsan@example.com', '北京市朝阳区学院路1号', '计算机科学与技术', '2024-09-01', '2028-06-30', 'active'),
-('S2024002', '李四', 19, '女', '13800138002', 'lisi@example.com', '上海市浦东新区张江路2号', '软件工程', '2024-09-01', '2028-06-30', 'active'),
-('S2024003', '王五', 21, '男', '13800138003', 'wangwu@example.com', '广州市天河区科技路3号', '数据科学与大数据技术', '2024-09-01', '2028-06-30', 'active'),
-('S2024004', '赵六', 20, '女', '13800138004', 'zhaoliu@example.com', '深圳市南山区高新路4号', '人工智能', '2024-09-01', '2028-06-30', 'active'),
-('S2024005', '钱七', 22, '男', '13800138005', 'qianqi@example.com', '杭州市西湖区文三路5号', '网络工程', '2024-09-01', '2028-06-30', 'active'),
-('S2024006', '孙八', 19, '女', '13800138006', 'sunba@example.com', '南京市鼓楼区中山路6号', '信息安全', '2024-09-01', '2028-06-30', 'active'),
-('S2024007', '周九', 20, '男', '13800138007', 'zhoujiu@example.com', '武汉市洪山区珞喻路7号', '物联网工程', '2024-09-01', '2028-06-30', 'active'),
-('S2024008', '吴十', 21, '女', '13800138008', 'wushi@example.com', '成都市高新区天府大道8号', '电子信息工程', '2024-09-01', '2028-06-30', 'active'),
-('S2024009', '郑一', 19, '男', '13800138009', 'zhengyi@example.com', '西安市雁塔区科技路9号', '通信工程', '2024-09-01', '2028-06-30', 'active'),
-('S2024010', '陈二', 20, '女', '13800138010', 'chener@example.com', '重庆市渝北区龙溪路10号', '自动化', '2024-09-01', '2028-06-30', 'active');
\ No newline at end of file
+('S2024001', '张三', 20, '男', '13800138001', 'zhangsan@example.com', '北京市朝阳区学院路1号', '计算机科学与技术', '2024-09-01', '2028-06-30', 'enrolled'),
+('S2024002', '李四', 19, '女', '13800138002', 'lisi@example.com', '上海市浦东新区张江路2号', '软件工程', '2024-09-01', '2028-06-30', 'enrolled'),
+('S2024003', '王五', 21, '男', '13800138003', 'wangwu@example.com', '广州市天河区科技路3号', '数据科学与大数据技术', '2024-09-01', '2028-06-30', 'enrolled'),
+('S2024004', '赵六', 20, '女', '13800138004', 'zhaoliu@example.com', '深圳市南山区高新路4号', '人工智能', '2024-09-01', '2028-06-30', 'enrolled'),
+('S2024005', '钱七', 22, '男', '13800138005', 'qianqi@example.com', '杭州市西湖区文三路5号', '网络工程', '2024-09-01', '2028-06-30', 'enrolled'),
+('S2024006', '孙八', 19, '女', '13800138006', 'sunba@example.com', '南京市鼓楼区中山路6号', '信息安全', '2024-09-01', '2028-06-30', 'enrolled'),
+('S2024007', '周九', 20, '男', '13800138007', 'zhoujiu@example.com', '武汉市洪山区珞喻路7号', '物联网工程', '2024-09-01', '2028-06-30', 'enrolled'),
+('S2024008', '吴十', 21, '女', '13800138008', 'wushi@example.com', '成都市高新区天府大道8号', '电子信息工程', '2024-09-01', '2028-06-30', 'enrolled'),
+('S2024009', '郑一', 19, '男', '13800138009', 'zhengyi@example.com', '西安市雁塔区科技路9号', '通信工程', '2024-09-01', '2028-06-30', 'enrolled'),
+('S2024010', '陈二', 20, '女', '13800138010', 'chener@example.com', '重庆市渝北区龙溪路10号', '自动化', '2024-09-01', '2028-06-30', 'enrolled');
\ No newline at end of file